@@ -0,0 +1,260 @@
+// Package client is a typed Go client for the Reserve-Park facade API.
+//
+// A client created with credentials logs in on the first call that needs a
+// token and logs in again when the token is about to expire or the facade
+// rejects it, so long-running services don't have to manage JWTs themselves:
+//
+//	c := client.New("http://localhost:3000", client.WithCredentials("jdoe", "secret"))
+//	spots := c.Spots(client.SpotFilter{LotID: "lot-a"}, client.ListOptions{Limit: 100})
+//	for spots.Next(ctx) {
+//		fmt.Println(spots.Value().SpotID)
+//	}
+//	if err := spots.Err(); err != nil {
+//		...
+//	}
+//
+// Failed calls return an *Error carrying the problem details sent by the
+// facade, which can be matched against ErrNotFound, ErrConflict and the other
+// sentinel errors with errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before its expiry a token is replaced
+const tokenRefreshMargin = 30 * time.Second
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	username  string
+	password  string
+	token     string
+	expiresAt time.Time
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for the calls, http.DefaultClient by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithCredentials makes the client log in by itself and refresh the token when it expires
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithToken sets a token obtained elsewhere, it is not refreshed unless credentials are set too
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.setToken(token)
+	}
+}
+
+// New returns a client of the facade listening at baseURL, e.g. "http://localhost:3000"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token returns the token the client currently authenticates with
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+
+	// auth marks calls that need a token
+	auth bool
+}
+
+// do sends the request and decodes a JSON response into out, a created
+// resource's ID into a *string or a document into a *[]byte. A rejected token is refreshed and the call
+// retried once when the client knows the credentials.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return err
+		}
+	}
+
+	var token string
+	if req.auth {
+		var err error
+		token, err = c.validToken(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.send(ctx, req, body, token, out)
+
+	var apiErr *Error
+	if req.auth && errors.As(err, &apiErr) && apiErr.Code == CodeInvalidToken && c.canLogin() {
+		if token, err = c.refreshToken(ctx, token); err != nil {
+			return err
+		}
+		return c.send(ctx, req, body, token, out)
+	}
+
+	return err
+}
+
+func (c *Client) send(ctx context.Context, req request, body []byte, token string, out any) error {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bodyReader)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *string:
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		*out = string(b)
+		return nil
+	case *[]byte:
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		*out = b
+		return nil
+	default:
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
+
+// validToken returns the current token, logging in first when there is none
+// or it is about to expire
+func (c *Client) validToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, expiresAt := c.token, c.expiresAt
+	c.mu.Unlock()
+
+	expiring := !expiresAt.IsZero() && time.Until(expiresAt) < tokenRefreshMargin
+	if (token == "" || expiring) && c.canLogin() {
+		return c.refreshToken(ctx, token)
+	}
+	if token == "" {
+		return "", ErrNoCredentials
+	}
+
+	return token, nil
+}
+
+// refreshToken logs in again unless another call already replaced the stale token
+func (c *Client) refreshToken(ctx context.Context, stale string) (string, error) {
+	c.mu.Lock()
+	username, password, current := c.username, c.password, c.token
+	c.mu.Unlock()
+
+	if current != stale && current != "" {
+		return current, nil
+	}
+
+	return c.login(ctx, username, password)
+}
+
+func (c *Client) canLogin() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.username != ""
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = token
+	c.expiresAt = tokenExpiry(token)
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it,
+// the zero time means the expiry is unknown
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
@@ -0,0 +1,626 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/problempb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/spotpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/userpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/server"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var baseURL string
+var users = &fakeUsers{tokens: map[string]bool{}}
+var reservations = &fakeReservations{}
+
+func TestMain(m *testing.M) {
+	cfg := config.GetConfig()
+	cfg.DownstreamMaxRetries = 0
+
+	var err error
+	cfg.UserAddr, err = serve(func(g *grpc.Server) { userpb.RegisterUserServiceServer(g, users) })
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	cfg.SpotAddr, err = serve(func(g *grpc.Server) { spotpb.RegisterSpotServiceServer(g, &fakeSpots{}) })
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	cfg.ReservationAddr, err = serve(func(g *grpc.Server) {
+		reservationpb.RegisterReservationServiceServer(g, reservations)
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	usr, _ := user.NewUserService(cfg)
+	spt, _ := spot.NewSpotService(cfg)
+	rsrv, _ := reservation.NewReservationService(cfg)
+
+	s := server.NewServer(logger.GetLogger(), cfg, usr, spt, rsrv)
+	ts := httptest.NewServer(s.Handler())
+	baseURL = ts.URL
+
+	code := m.Run()
+	ts.Close()
+	os.Exit(code)
+}
+
+func serve(register func(*grpc.Server)) (string, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+
+	g := grpc.NewServer()
+	register(g)
+	go g.Serve(lis)
+
+	return lis.Addr().String(), nil
+}
+
+func TestLogin(t *testing.T) {
+	c := New(baseURL)
+
+	_, err := c.Login(context.Background(), "jdoe", "wrong")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Login with a wrong password returned %v, want ErrUnauthorized", err)
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeInvalidCredentials {
+		t.Errorf("Login with a wrong password returned %v, want code %s", err, CodeInvalidCredentials)
+	}
+
+	token, err := c.Login(context.Background(), "jdoe", "secret")
+	if err != nil {
+		t.Fatalf("Failed to log in: %v", err)
+	}
+
+	if c.Token() != token {
+		t.Errorf("Token() = %q, want %q", c.Token(), token)
+	}
+}
+
+func TestNoCredentials(t *testing.T) {
+	c := New(baseURL)
+
+	_, err := c.GetSpot(context.Background(), "spot-1")
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("GetSpot without credentials returned %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestTokenRefresh(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	if _, err := c.GetSpot(context.Background(), "spot-1"); err != nil {
+		t.Fatalf("Failed to get spot: %v", err)
+	}
+	first := c.Token()
+
+	// The facade rejects the token, the client logs in again and retries the call
+	users.revoke(first)
+
+	if _, err := c.GetSpot(context.Background(), "spot-1"); err != nil {
+		t.Fatalf("Failed to get spot after the token was revoked: %v", err)
+	}
+
+	if c.Token() == first {
+		t.Errorf("The client kept the revoked token")
+	}
+}
+
+func TestInvalidTokenWithoutCredentials(t *testing.T) {
+	c := New(baseURL, WithToken(users.issue()))
+	users.revoke(c.Token())
+
+	_, err := c.GetSpot(context.Background(), "spot-1")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeInvalidToken || apiErr.Status != 401 {
+		t.Errorf("GetSpot with a revoked token returned %v, want a 401 %s error", err, CodeInvalidToken)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	_, err := c.GetSpot(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetSpot returned %v, want ErrNotFound", err)
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetSpot returned %T, want *Error", err)
+	}
+	if apiErr.Code != CodeSpotNotFound {
+		t.Errorf("Code = %s, want %s", apiErr.Code, CodeSpotNotFound)
+	}
+	if apiErr.RequestID == "" {
+		t.Errorf("RequestID is empty")
+	}
+}
+
+func TestSpotsIterator(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	it := c.Spots(SpotFilter{}, ListOptions{Limit: 2})
+	var spotIDs []string
+	for it.Next(context.Background()) {
+		spotIDs = append(spotIDs, it.Value().SpotID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Failed to iterate over spots: %v", err)
+	}
+
+	if len(spotIDs) != spotCount {
+		t.Fatalf("Iterated over %d spots, want %d", len(spotIDs), spotCount)
+	}
+	for i, spotID := range spotIDs {
+		if spotID != "spot-"+strconv.Itoa(i) {
+			t.Errorf("spotIDs[%d] = %s, want spot-%d", i, spotID, i)
+		}
+	}
+
+	it = c.Spots(SpotFilter{}, ListOptions{Cursor: "bad"})
+	if it.Next(context.Background()) {
+		t.Fatalf("Next with an invalid cursor returned true")
+	}
+	if !errors.Is(it.Err(), ErrBadRequest) {
+		t.Errorf("Err() = %v, want ErrBadRequest", it.Err())
+	}
+}
+
+func TestGetSpotPrice(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	price, err := c.GetSpotPrice(context.Background(), "spot-1", start, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get price: %v", err)
+	}
+
+	if price != 20 {
+		t.Errorf("price = %v, want 20", price)
+	}
+}
+
+func TestGetPromoPrice(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	price, err := c.GetPromoPrice(context.Background(), "spot-1", "save10", start, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get promo price: %v", err)
+	}
+
+	if price.ListPrice != 20 || price.Discount != 2 || price.Price != 18 || price.PromoCode != "SAVE10" {
+		t.Errorf("price = %+v, want 18 after 2 off the list price of 20 with SAVE10", price)
+	}
+
+	_, err = c.GetPromoPrice(context.Background(), "spot-1", "missing", start, start.Add(2*time.Hour))
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodePromoCodeNotFound {
+		t.Errorf("GetPromoPrice with an unknown code returned %v, want code %s", err, CodePromoCodeNotFound)
+	}
+}
+
+func TestQuoteAndBook(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	input := QuoteInput{UserID: "user-1", SpotID: "spot-1", StartTime: start, EndTime: start.Add(2 * time.Hour), PromoCode: "save10"}
+	quote, err := c.CreateQuote(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Failed to create quote: %v", err)
+	}
+
+	if quote.Price != 18 || len(quote.Items) != 2 || quote.Items[0].Amount != 20 || quote.Items[1].Amount != -2 {
+		t.Errorf("quote = %+v, want 20 for parking less 2 with SAVE10", quote)
+	}
+	if quote.QuoteToken == "" || !quote.ExpiresAt.After(time.Now()) {
+		t.Errorf("quote = %+v, want a token that hasn't expired", quote)
+	}
+
+	book := AddReservationInput{UserID: "user-1", SpotID: "spot-1", StartTime: start, EndTime: start.Add(2 * time.Hour), QuoteToken: quote.QuoteToken}
+	if _, err := c.AddReservation(context.Background(), book); err != nil {
+		t.Fatalf("Failed to book with the quote: %v", err)
+	}
+	if added := reservations.added; added.PricePaid != 20 || added.PromoCode != "SAVE10" || added.GetDiscount() != 2 {
+		t.Errorf("booked at %v with %q discounting %v, want the list price of 20 with SAVE10 discounting 2", added.PricePaid, added.PromoCode, added.GetDiscount())
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*AddReservationInput)
+	}{
+		{"tampered", func(in *AddReservationInput) { in.QuoteToken = "x" + in.QuoteToken }},
+		{"another window", func(in *AddReservationInput) { in.EndTime = in.EndTime.Add(time.Hour) }},
+		{"another code", func(in *AddReservationInput) { in.PromoCode = "OTHER" }},
+	}
+	for _, tt := range tests {
+		in := book
+		tt.modify(&in)
+
+		_, err := c.AddReservation(context.Background(), in)
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.Code != CodeInvalidQuote {
+			t.Errorf("%v: AddReservation returned %v, want code %s", tt.name, err, CodeInvalidQuote)
+		}
+	}
+}
+
+func TestReservationPrice(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := c.AddReservation(context.Background(), AddReservationInput{UserID: "user-1", SpotID: "spot-1", StartTime: start, EndTime: start.Add(3 * time.Hour)}); err != nil {
+		t.Fatalf("Failed to add reservation: %v", err)
+	}
+	if added := reservations.added; added.PricePaid != 30 {
+		t.Errorf("booked at %v, want the spot price of 30", added.PricePaid)
+	}
+
+	// The reservation returned by the fake is on spot-1 from 10:00 to 14:00
+	unchanged := start.Add(4 * time.Hour)
+	if err := c.EditReservation(context.Background(), EditReservationInput{ReservationID: "rsrv-1", UserID: "user-1", SpotID: "spot-1", EndTime: &unchanged}); err != nil {
+		t.Fatalf("Failed to edit reservation: %v", err)
+	}
+	if edited := reservations.edited; edited.PricePaid != nil {
+		t.Errorf("edit in the same window priced at %v, want the price kept", *edited.PricePaid)
+	}
+
+	shorter := start.Add(2 * time.Hour)
+	if err := c.EditReservation(context.Background(), EditReservationInput{ReservationID: "rsrv-1", UserID: "user-1", SpotID: "spot-1", EndTime: &shorter}); err != nil {
+		t.Fatalf("Failed to edit reservation: %v", err)
+	}
+	if edited := reservations.edited; edited.PricePaid == nil || *edited.PricePaid != 20 {
+		t.Errorf("edit to a shorter window priced at %v, want the spot price of 20", edited.PricePaid)
+	}
+}
+
+func TestAddReservationWithoutVehicle(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	_, err := c.AddReservation(context.Background(), AddReservationInput{UserID: "user-1", SpotID: "spot-ev", StartTime: start, EndTime: start.Add(time.Hour)})
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeVehicleIncompatible {
+		t.Errorf("AddReservation on an EV-only spot without a vehicle returned %v, want code %s", err, CodeVehicleIncompatible)
+	}
+}
+
+func TestQuoteCancellation(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	quote, err := c.QuoteCancellation(context.Background(), "rsrv-1")
+	if err != nil {
+		t.Fatalf("Failed to quote cancellation: %v", err)
+	}
+
+	if quote.Tier != "late_fee" || quote.Fee != 10 || quote.Refund != 30 {
+		t.Errorf("quote = %+v, want late_fee tier keeping 10 and refunding 30", quote)
+	}
+	if quote.Policy.Scope != "spot" || quote.Policy.TargetID != "spot-1" || quote.Policy.FreeUntilHours != 24 {
+		t.Errorf("policy = %+v, want the policy of spot-1", quote.Policy)
+	}
+
+	_, err = c.QuoteCancellation(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestInvoices(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	// Reading the invoice doesn't issue it
+	_, err := c.GetReservationInvoice(context.Background(), "rsrv-1")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeInvoiceNotFound {
+		t.Fatalf("GetReservationInvoice before issuing returned %v, want code %s", err, CodeInvoiceNotFound)
+	}
+
+	invoice, err := c.IssueReservationInvoice(context.Background(), "rsrv-1")
+	if err != nil {
+		t.Fatalf("Failed to issue reservation invoice: %v", err)
+	}
+	if got, err := c.GetReservationInvoice(context.Background(), "rsrv-1"); err != nil || got.InvoiceID != invoice.InvoiceID {
+		t.Errorf("GetReservationInvoice = %v, %v, want the issued invoice", got.InvoiceID, err)
+	}
+
+	if invoice.Number != "RP-000001" || invoice.Buyer.Name != "Doe Ltd" || invoice.Buyer.TaxID != "PL1234567890" || invoice.Buyer.Email != "jdoe@example.com" {
+		t.Errorf("invoice = %+v, want RP-000001 issued to the billing details of the profile", invoice)
+	}
+	if len(invoice.Lines) != 1 || invoice.Lines[0].Spot.SpotID != "spot-1" || invoice.Lines[0].Spot.Size != "medium" {
+		t.Errorf("lines = %+v, want one line with the details of spot-1", invoice.Lines)
+	}
+
+	doc, err := c.RenderInvoice(context.Background(), invoice.InvoiceID, FormatPDF)
+	if err != nil {
+		t.Fatalf("Failed to render invoice: %v", err)
+	}
+	if !strings.HasPrefix(string(doc), "%PDF-") {
+		t.Errorf("rendered invoice starts with %q, want a PDF", doc[:min(len(doc), 8)])
+	}
+
+	_, err = c.GetInvoice(context.Background(), "missing")
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeInvoiceNotFound {
+		t.Errorf("GetInvoice of a missing invoice returned %v, want code %s", err, CodeInvoiceNotFound)
+	}
+}
+
+func TestGetAvailableSpots(t *testing.T) {
+	c := New(baseURL, WithCredentials("jdoe", "secret"))
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	spotIDs, err := c.GetAvailableSpots(context.Background(), AvailabilityInput{
+		SpotIDs:   []string{"spot-1", "spot-2"},
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Failed to get available spots: %v", err)
+	}
+
+	if len(spotIDs) != 1 || spotIDs[0] != "spot-2" {
+		t.Errorf("spotIDs = %v, want [spot-2]", spotIDs)
+	}
+
+	_, err = c.GetAvailableSpots(context.Background(), AvailabilityInput{
+		SpotIDs:   []string{"missing"},
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+	})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeUnknownSpots {
+		t.Errorf("GetAvailableSpots with an unknown spot returned %v, want code %s", err, CodeUnknownSpots)
+	}
+}
+
+// fakeUsers issues tokens that look like JWTs, so the client can read their expiry
+type fakeUsers struct {
+	userpb.UnimplementedUserServiceServer
+
+	mu     sync.Mutex
+	n      int
+	tokens map[string]bool
+}
+
+func (f *fakeUsers) issue() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.n++
+	claims, _ := json.Marshal(map[string]any{"exp": time.Now().Add(time.Hour).Unix(), "n": f.n})
+	token := "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+	f.tokens[token] = true
+	return token
+}
+
+func (f *fakeUsers) revoke(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.tokens, token)
+}
+
+func (f *fakeUsers) Login(ctx context.Context, req *userpb.LoginRequest) (*userpb.LoginResponse, error) {
+	if req.Username != "jdoe" || req.Password != "secret" {
+		return nil, problemStatus(codes.Unauthenticated, "INVALID_CREDENTIALS", "Invalid username or password")
+	}
+
+	return &userpb.LoginResponse{Jwt: f.issue()}, nil
+}
+
+func (f *fakeUsers) Authorize(ctx context.Context, req *userpb.AuthorizeRequest) (*userpb.AuthorizeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.tokens[req.Token] {
+		return nil, problemStatus(codes.Unauthenticated, "INVALID_TOKEN", "Invalid token")
+	}
+
+	return &userpb.AuthorizeResponse{UserId: "user-1", Role: "user"}, nil
+}
+
+// GetUser returns the caller's profile with private billing details
+func (f *fakeUsers) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.User, error) {
+	return &userpb.User{
+		UserId:   req.UserId,
+		Username: "jdoe",
+		Email:    "jdoe@example.com",
+		Role:     "user",
+		Billing:  &userpb.Billing{Name: "Doe Ltd", TaxId: "PL1234567890"},
+	}, nil
+}
+
+const spotCount = 5
+
+type fakeSpots struct {
+	spotpb.UnimplementedSpotServiceServer
+}
+
+func (f *fakeSpots) GetSpot(ctx context.Context, req *spotpb.GetSpotRequest) (*spotpb.Spot, error) {
+	if req.SpotId == "missing" {
+		return nil, problemStatus(codes.NotFound, "SPOT_NOT_FOUND", "Spot not found")
+	}
+
+	if req.SpotId == "spot-ev" {
+		return &spotpb.Spot{SpotId: req.SpotId, PricePerHour: 10, Size: "medium", Type: "ev"}, nil
+	}
+
+	return &spotpb.Spot{SpotId: req.SpotId, PricePerHour: 10, Size: "medium", Type: "regular"}, nil
+}
+
+// ListSpots uses the index of the next spot as the cursor
+func (f *fakeSpots) ListSpots(ctx context.Context, req *spotpb.ListSpotsRequest) (*spotpb.ListSpotsResponse, error) {
+	start := 0
+	if cursor := req.GetOptions().GetCursor(); cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil {
+			return nil, problemStatus(codes.InvalidArgument, "INVALID_CURSOR", "Invalid cursor")
+		}
+	}
+
+	end := min(start+int(req.GetOptions().GetLimit()), spotCount)
+	resp := &spotpb.ListSpotsResponse{}
+	for i := start; i < end; i++ {
+		resp.Items = append(resp.Items, &spotpb.Spot{SpotId: "spot-" + strconv.Itoa(i), Size: "medium", Type: "regular"})
+	}
+	if end < spotCount {
+		resp.NextCursor = strconv.Itoa(end)
+	}
+
+	return resp, nil
+}
+
+func (f *fakeSpots) GetPrice(ctx context.Context, req *spotpb.GetPriceRequest) (*spotpb.GetPriceResponse, error) {
+	hours := req.EndTime.AsTime().Sub(req.StartTime.AsTime()).Hours()
+	return &spotpb.GetPriceResponse{SpotId: req.SpotId, Price: 10 * hours}, nil
+}
+
+func (f *fakeSpots) CheckSpotsExist(ctx context.Context, req *spotpb.CheckSpotsExistRequest) (*spotpb.CheckSpotsExistResponse, error) {
+	resp := &spotpb.CheckSpotsExistResponse{}
+	for _, spotID := range req.SpotIds {
+		if spotID == "missing" {
+			resp.NotFound = append(resp.NotFound, spotID)
+		}
+	}
+	resp.AllExist = len(resp.NotFound) == 0
+
+	return resp, nil
+}
+
+// GetCancellationPolicy keeps 25% of the price from 24 hours before the start
+func (f *fakeSpots) GetCancellationPolicy(ctx context.Context, req *spotpb.GetCancellationPolicyRequest) (*spotpb.CancellationPolicy, error) {
+	return &spotpb.CancellationPolicy{Scope: "spot", TargetId: req.SpotId, FreeUntilHours: 24, LateFeePercent: 25}, nil
+}
+
+// fakeReservations treats spot-1 as taken, knows a single promo code, remembers the last booking
+// and has a single invoice, issued on the first request
+type fakeReservations struct {
+	reservationpb.UnimplementedReservationServiceServer
+
+	issued *reservationpb.Invoice
+	added  *reservationpb.AddReservationRequest
+	edited *reservationpb.EditReservationRequest
+}
+
+func (f *fakeReservations) AddReservation(ctx context.Context, req *reservationpb.AddReservationRequest) (*reservationpb.AddReservationResponse, error) {
+	f.added = req
+	return &reservationpb.AddReservationResponse{ReservationId: "rsrv-2"}, nil
+}
+
+func (f *fakeReservations) EditReservation(ctx context.Context, req *reservationpb.EditReservationRequest) (*emptypb.Empty, error) {
+	f.edited = req
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeReservations) CheckAvailability(ctx context.Context, req *reservationpb.CheckAvailabilityRequest) (*reservationpb.CheckAvailabilityResponse, error) {
+	resp := &reservationpb.CheckAvailabilityResponse{}
+	for _, spotID := range req.SpotIds {
+		if spotID != "spot-1" {
+			resp.SpotIds = append(resp.SpotIds, spotID)
+		}
+	}
+
+	return resp, nil
+}
+
+func (f *fakeReservations) GetReservation(ctx context.Context, req *reservationpb.GetReservationRequest) (*reservationpb.Reservation, error) {
+	if req.ReservationId == "missing" {
+		return nil, problemStatus(codes.NotFound, "RESERVATION_NOT_FOUND", "Reservation not found")
+	}
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	return &reservationpb.Reservation{ReservationId: req.ReservationId, UserId: "user-1", SpotId: "spot-1", Status: "active", PricePaid: 40,
+		StartTime: timestamppb.New(start), EndTime: timestamppb.New(start.Add(4 * time.Hour))}, nil
+}
+
+// GetCancellationQuote quotes every cancellation in the late fee tier of the policy
+func (f *fakeReservations) GetCancellationQuote(ctx context.Context, req *reservationpb.CancellationRequest) (*reservationpb.CancellationQuote, error) {
+	fee := 40 * req.Policy.LateFeePercent / 100
+	return &reservationpb.CancellationQuote{
+		ReservationId: req.ReservationId,
+		Price:         40,
+		Fee:           fee,
+		Refund:        40 - fee,
+		Tier:          "late_fee",
+		Policy:        req.Policy,
+		QuotedAt:      timestamppb.Now(),
+	}, nil
+}
+
+// QuotePromoCode knows SAVE10 only, taking 10% off
+func (f *fakeReservations) QuotePromoCode(ctx context.Context, req *reservationpb.QuotePromoCodeRequest) (*reservationpb.PromoQuote, error) {
+	if strings.ToUpper(req.Code) != "SAVE10" {
+		return nil, problemStatus(codes.NotFound, "PROMO_CODE_NOT_FOUND", "Promo code not found")
+	}
+
+	discount := req.Price / 10
+	return &reservationpb.PromoQuote{Code: "SAVE10", ListPrice: req.Price, Discount: discount, Price: req.Price - discount}, nil
+}
+
+func (f *fakeReservations) GetReservationInvoice(ctx context.Context, req *reservationpb.GetReservationInvoiceRequest) (*reservationpb.Invoice, error) {
+	if f.issued == nil {
+		return nil, problemStatus(codes.NotFound, "INVOICE_NOT_FOUND", "The reservation has no invoice")
+	}
+	return f.issued, nil
+}
+
+func (f *fakeReservations) IssueInvoice(ctx context.Context, req *reservationpb.IssueInvoiceRequest) (*reservationpb.Invoice, error) {
+	f.issued = &reservationpb.Invoice{
+		InvoiceId: "inv-1",
+		Number:    "RP-000001",
+		Kind:      "single",
+		UserId:    "user-1",
+		Seller:    &reservationpb.Party{Name: "Reserve Park"},
+		Buyer:     req.Buyer,
+		Currency:  "EUR",
+		Lines:     []*reservationpb.InvoiceLine{{ReservationId: req.ReservationId, Description: "Parking", Spot: req.Spot, Total: 40}},
+		Total:     40,
+		IssuedAt:  timestamppb.Now(),
+	}
+	return f.issued, nil
+}
+
+func (f *fakeReservations) GetInvoice(ctx context.Context, req *reservationpb.GetInvoiceRequest) (*reservationpb.Invoice, error) {
+	if f.issued == nil || req.InvoiceId != f.issued.InvoiceId {
+		return nil, problemStatus(codes.NotFound, "INVOICE_NOT_FOUND", "Invoice not found")
+	}
+	return f.issued, nil
+}
+
+func problemStatus(code codes.Code, problemCode, detail string) error {
+	st, _ := status.New(code, detail).WithDetails(&problempb.Problem{Code: problemCode, Detail: detail})
+	return st.Err()
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Code is the stable, machine readable identifier of a facade error
+type Code string
+
+const (
+	CodeBadRequest                 Code = "BAD_REQUEST"
+	CodeMalformedBody              Code = "MALFORMED_BODY"
+	CodeValidationFailed           Code = "VALIDATION_FAILED"
+	CodeInvalidCursor              Code = "INVALID_CURSOR"
+	CodeVehicleIncompatible        Code = "VEHICLE_INCOMPATIBLE"
+	CodeUnknownSpots               Code = "UNKNOWN_SPOTS"
+	CodeInvalidQuote               Code = "INVALID_QUOTE"
+	CodeUnauthorized               Code = "UNAUTHORIZED"
+	CodeInvalidCredentials         Code = "INVALID_CREDENTIALS"
+	CodeInvalidToken               Code = "INVALID_TOKEN"
+	CodeNotFound                   Code = "NOT_FOUND"
+	CodeUserNotFound               Code = "USER_NOT_FOUND"
+	CodeSpotNotFound               Code = "SPOT_NOT_FOUND"
+	CodeReservationNotFound        Code = "RESERVATION_NOT_FOUND"
+	CodeInvoiceNotFound            Code = "INVOICE_NOT_FOUND"
+	CodePromoCodeNotFound          Code = "PROMO_CODE_NOT_FOUND"
+	CodeVehicleNotFound            Code = "VEHICLE_NOT_FOUND"
+	CodeNoMatchingSpot             Code = "NO_MATCHING_SPOT"
+	CodeMethodNotAllowed           Code = "METHOD_NOT_ALLOWED"
+	CodeSpotUnavailable            Code = "SPOT_UNAVAILABLE"
+	CodeUserAlreadyExists          Code = "USER_ALREADY_EXISTS"
+	CodeVehicleAlreadyExists       Code = "VEHICLE_ALREADY_EXISTS"
+	CodeReservationAlreadyCanceled Code = "RESERVATION_ALREADY_CANCELED"
+	CodeNothingToInvoice           Code = "NOTHING_TO_INVOICE"
+	CodePromoCodeAlreadyExists     Code = "PROMO_CODE_ALREADY_EXISTS"
+	CodePromoCodeNotApplicable     Code = "PROMO_CODE_NOT_APPLICABLE"
+	CodeQuoteExpired               Code = "QUOTE_EXPIRED"
+	CodeInternal                   Code = "INTERNAL_ERROR"
+	CodeDownstreamError            Code = "DOWNSTREAM_ERROR"
+	CodeDownstreamUnavailable      Code = "DOWNSTREAM_UNAVAILABLE"
+	CodeDownstreamTimeout          Code = "DOWNSTREAM_TIMEOUT"
+)
+
+// Sentinel errors matched by *Error through errors.Is, one per class of HTTP status
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrUnavailable  = errors.New("service unavailable")
+	ErrServer       = errors.New("server error")
+
+	// ErrNoCredentials is returned by calls that need a token when the client has neither a token nor credentials
+	ErrNoCredentials = errors.New("no token or credentials to authenticate with")
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error is a failed call, built from the problem details returned by the facade
+type Error struct {
+	Status    int          `json:"status"`
+	Code      Code         `json:"code"`
+	Title     string       `json:"title"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("reserve-park: %d %s", e.Status, e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Is matches the sentinel error of the status class
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.Status == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrConflict:
+		return e.Status == http.StatusConflict
+	case ErrUnavailable:
+		return e.Status == http.StatusBadGateway || e.Status == http.StatusServiceUnavailable || e.Status == http.StatusGatewayTimeout
+	case ErrServer:
+		return e.Status >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// decodeError reads the problem details of a failed response. Responses that
+// don't carry problem details, e.g. from a proxy, keep the body as the detail.
+func decodeError(resp *http.Response) error {
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	apiErr := &Error{}
+	if json.Unmarshal(b, apiErr) != nil || apiErr.Code == "" {
+		apiErr = &Error{
+			Title:  http.StatusText(resp.StatusCode),
+			Detail: strings.TrimSpace(string(b)),
+		}
+	}
+	apiErr.Status = resp.StatusCode
+
+	return apiErr
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Invoice is issued once for the amount charged for reservations, prices include the tax.
+// Credit notes take part of an invoice back after a refund, with negative amounts.
+type Invoice struct {
+	InvoiceID string `json:"invoice_id"`
+	Number    string `json:"number"`
+	Kind      string `json:"kind"`
+	UserID    string `json:"user_id"`
+	Period    string `json:"period,omitempty"`
+
+	// CreditedInvoiceID and CreditedNumber are the invoice a credit note corrects
+	CreditedInvoiceID string `json:"credited_invoice_id,omitempty"`
+	CreditedNumber    string `json:"credited_number,omitempty"`
+
+	Seller   Party         `json:"seller"`
+	Buyer    Party         `json:"buyer"`
+	Currency string        `json:"currency"`
+	Lines    []InvoiceLine `json:"lines"`
+	Taxes    []TaxLine     `json:"taxes"`
+	Net      float64       `json:"net"`
+	Tax      float64       `json:"tax"`
+	Total    float64       `json:"total"`
+	IssuedAt time.Time     `json:"issued_at"`
+}
+
+type Party struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	TaxID   string `json:"tax_id,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// InvoiceSpot is the spot as it was when the invoice was issued
+type InvoiceSpot struct {
+	SpotID    string  `json:"spot_id"`
+	LotID     string  `json:"lot_id,omitempty"`
+	Size      string  `json:"size,omitempty"`
+	Type      string  `json:"type,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+}
+
+type InvoiceLine struct {
+	ReservationID string      `json:"reservation_id"`
+	Description   string      `json:"description"`
+	Spot          InvoiceSpot `json:"spot"`
+	StartTime     time.Time   `json:"start_time"`
+	EndTime       time.Time   `json:"end_time"`
+	Net           float64     `json:"net"`
+	Tax           float64     `json:"tax"`
+	Total         float64     `json:"total"`
+}
+
+type TaxLine struct {
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"`
+	Net  float64 `json:"net"`
+	Tax  float64 `json:"tax"`
+}
+
+// InvoiceFilter leaves empty fields out, UserID is for admins only
+type InvoiceFilter struct {
+	UserID string
+	Period string
+	Limit  int
+}
+
+// Formats of a rendered invoice
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+// GetReservationInvoice returns the invoice of the reservation, CodeInvoiceNotFound until it is issued
+func (c *Client) GetReservationInvoice(ctx context.Context, reservationID string) (Invoice, error) {
+	var invoice Invoice
+	err := c.do(ctx, request{method: http.MethodGet, path: "/reservations/" + url.PathEscape(reservationID) + "/invoice", auth: true}, &invoice)
+	return invoice, err
+}
+
+// IssueReservationInvoice invoices what the payment of the reservation captured, issuing it again
+// returns the invoice issued first. Nothing captured yet fails with CodeNothingToInvoice, business
+// accounts are invoiced monthly and get CodeInvoiceNotFound.
+func (c *Client) IssueReservationInvoice(ctx context.Context, reservationID string) (Invoice, error) {
+	var invoice Invoice
+	err := c.do(ctx, request{method: http.MethodPost, path: "/reservations/" + url.PathEscape(reservationID) + "/invoice", auth: true}, &invoice)
+	return invoice, err
+}
+
+// ListInvoices returns the newest invoices first
+func (c *Client) ListInvoices(ctx context.Context, filter InvoiceFilter) ([]Invoice, error) {
+	query := url.Values{}
+	setIfNotEmpty(query, "user_id", filter.UserID)
+	setIfNotEmpty(query, "period", filter.Period)
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var invoices []Invoice
+	err := c.do(ctx, request{method: http.MethodGet, path: "/invoices", query: query, auth: true}, &invoices)
+	return invoices, err
+}
+
+func (c *Client) GetInvoice(ctx context.Context, invoiceID string) (Invoice, error) {
+	var invoice Invoice
+	err := c.do(ctx, request{method: http.MethodGet, path: "/invoices/" + url.PathEscape(invoiceID), auth: true}, &invoice)
+	return invoice, err
+}
+
+// RenderInvoice returns the invoice as a FormatHTML page or a FormatPDF document
+func (c *Client) RenderInvoice(ctx context.Context, invoiceID, format string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("format", format)
+
+	var doc []byte
+	err := c.do(ctx, request{method: http.MethodGet, path: "/invoices/" + url.PathEscape(invoiceID), query: query, auth: true}, &doc)
+	return doc, err
+}
+
+// IssueMonthlyInvoice consolidates the charges of a business account in a month that is over. Admin only.
+func (c *Client) IssueMonthlyInvoice(ctx context.Context, userID, period string) (Invoice, error) {
+	input := struct {
+		UserID string `json:"user_id"`
+		Period string `json:"period"`
+	}{userID, period}
+
+	var invoice Invoice
+	err := c.do(ctx, request{method: http.MethodPost, path: "/invoices/monthly", body: input, auth: true}, &invoice)
+	return invoice, err
+}
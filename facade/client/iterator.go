@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ListOptions are the pagination and sorting options of list calls, zero values pick the server defaults
+type ListOptions struct {
+	Limit        int64
+	Cursor       string
+	Sort         string
+	Order        string
+	IncludeTotal bool
+}
+
+func (o ListOptions) apply(query url.Values) {
+	if o.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(o.Limit, 10))
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	if o.Order != "" {
+		query.Set("order", o.Order)
+	}
+	if o.IncludeTotal {
+		query.Set("include_total", "true")
+	}
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	TotalCount *int64 `json:"total_count,omitempty"`
+}
+
+// Iterator walks all the items of a list call, fetching the next page when the current one runs out
+type Iterator[T any] struct {
+	fetch  func(ctx context.Context, cursor string) (Page[T], error)
+	items  []T
+	cursor string
+	done   bool
+	value  T
+	err    error
+}
+
+func newIterator[T any](cursor string, fetch func(ctx context.Context, cursor string) (Page[T], error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch, cursor: cursor}
+}
+
+// Next advances to the next item, it returns false when there are no more items or a page failed to load
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		page, err := it.fetch(ctx, it.cursor)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.items = page.Items
+		it.cursor = page.NextCursor
+		it.done = page.NextCursor == ""
+	}
+
+	it.value, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Value returns the current item
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
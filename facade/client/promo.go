@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Discount types of a promo code
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// PromoRestrictions limit the reservations a code applies to, empty fields don't restrict
+type PromoRestrictions struct {
+	LotIDs             []string `json:"lot_ids,omitempty"`
+	SpotTypes          []string `json:"spot_types,omitempty"`
+	MinDurationMinutes int      `json:"min_duration_minutes,omitempty"`
+	FirstBookingOnly   bool     `json:"first_booking_only,omitempty"`
+}
+
+// PromoCode discounts reservations by a percent or a fixed amount, zero limits mean no limit
+type PromoCode struct {
+	Code           string            `json:"code"`
+	Description    string            `json:"description,omitempty"`
+	Type           string            `json:"type"`
+	Value          float64           `json:"value"`
+	ValidFrom      *time.Time        `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time        `json:"valid_until,omitempty"`
+	MaxRedemptions int               `json:"max_redemptions,omitempty"`
+	MaxPerUser     int               `json:"max_per_user,omitempty"`
+	Restrictions   PromoRestrictions `json:"restrictions"`
+	Redemptions    int               `json:"redemptions,omitempty"`
+	CreatedAt      time.Time         `json:"created_at,omitempty"`
+}
+
+// Discount is recorded on a reservation booked with a promo code, PricePaid is ListPrice less Amount
+type Discount struct {
+	Code      string  `json:"code"`
+	ListPrice float64 `json:"list_price"`
+	Amount    float64 `json:"amount"`
+}
+
+// PromoPrice is the price of parking on a spot with a promo code applied for the caller
+type PromoPrice struct {
+	SpotID    string  `json:"spot_id"`
+	Price     float64 `json:"price"`
+	ListPrice float64 `json:"list_price"`
+	Discount  float64 `json:"discount"`
+	PromoCode string  `json:"promo_code"`
+}
+
+// GetPromoPrice returns the price of parking on the spot in the timeframe with the code applied.
+// A code that doesn't apply fails with CodePromoCodeNotApplicable.
+func (c *Client) GetPromoPrice(ctx context.Context, spotID, code string, start, end time.Time) (PromoPrice, error) {
+	query := url.Values{}
+	query.Set("spot_id", spotID)
+	query.Set("start_time", start.Format(time.RFC3339))
+	query.Set("end_time", end.Format(time.RFC3339))
+	query.Set("promo_code", code)
+
+	var price PromoPrice
+	err := c.do(ctx, request{method: http.MethodGet, path: "/spots/price", query: query, auth: true}, &price)
+	return price, err
+}
+
+// AddPromoCode ignores the redemptions and creation time of the code. Admin only.
+func (c *Client) AddPromoCode(ctx context.Context, promo PromoCode) (PromoCode, error) {
+	var created PromoCode
+	err := c.do(ctx, request{method: http.MethodPost, path: "/promo-codes", body: promo, auth: true}, &created)
+	return created, err
+}
+
+// ListPromoCodes returns the newest codes first. Admin only.
+func (c *Client) ListPromoCodes(ctx context.Context) ([]PromoCode, error) {
+	var promos []PromoCode
+	err := c.do(ctx, request{method: http.MethodGet, path: "/promo-codes", auth: true}, &promos)
+	return promos, err
+}
+
+// GetPromoCode returns the code, matched case-insensitively. Admin only.
+func (c *Client) GetPromoCode(ctx context.Context, code string) (PromoCode, error) {
+	var promo PromoCode
+	err := c.do(ctx, request{method: http.MethodGet, path: "/promo-codes/" + url.PathEscape(code), auth: true}, &promo)
+	return promo, err
+}
+
+// DeletePromoCode removes a code, reservations booked with it keep their discount. Admin only.
+func (c *Client) DeletePromoCode(ctx context.Context, code string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/promo-codes/" + url.PathEscape(code), auth: true}, nil)
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type QuoteInput struct {
+	UserID    string    `json:"user_id"`
+	SpotID    string    `json:"spot_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	PromoCode string    `json:"promo_code,omitempty"`
+}
+
+// QuoteItem is a line of the itemized price, discounts have a negative amount
+type QuoteItem struct {
+	Kind        string  `json:"kind"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// Quote is the price of booking the spot for the user in the window, guaranteed until ExpiresAt
+type Quote struct {
+	QuoteID    string      `json:"quote_id"`
+	SpotID     string      `json:"spot_id"`
+	UserID     string      `json:"user_id"`
+	StartTime  time.Time   `json:"start_time"`
+	EndTime    time.Time   `json:"end_time"`
+	ListPrice  float64     `json:"list_price"`
+	Discount   float64     `json:"discount,omitempty"`
+	Price      float64     `json:"price"`
+	PromoCode  string      `json:"promo_code,omitempty"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+	Items      []QuoteItem `json:"items"`
+	QuoteToken string      `json:"quote_token"`
+}
+
+// CreateQuote prices a booking. Booking with AddReservationInput.QuoteToken set to the token of
+// the quote costs its Price, an expired quote fails with CodeQuoteExpired.
+func (c *Client) CreateQuote(ctx context.Context, input QuoteInput) (Quote, error) {
+	var quote Quote
+	err := c.do(ctx, request{method: http.MethodPost, path: "/quotes", body: input, auth: true}, &quote)
+	return quote, err
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Reservation struct {
+	ID            string    `json:"id,omitempty"`
+	ReservationID string    `json:"reservation_id"`
+	UserID        string    `json:"user_id"`
+	SpotID        string    `json:"spot_id"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Status        string    `json:"status"`
+	PricePaid     float64   `json:"price_paid"`
+	VehicleID     string    `json:"vehicle_id,omitempty"`
+	LicensePlate  string    `json:"license_plate,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Cancellation is set when the reservation was canceled under a cancellation policy
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+
+	// Discount is set when the reservation was booked with a promo code
+	Discount *Discount `json:"discount,omitempty"`
+}
+
+// CancellationPolicy frees canceling until FreeUntilHours before the start, then keeps
+// LateFeePercent of the price, and refunds nothing within NonRefundableMinutes of the start
+type CancellationPolicy struct {
+	Scope                string  `json:"scope"`
+	TargetID             string  `json:"target_id"`
+	FreeUntilHours       int     `json:"free_until_hours"`
+	LateFeePercent       float64 `json:"late_fee_percent"`
+	NonRefundableMinutes int     `json:"non_refundable_minutes"`
+}
+
+// Cancellation records what was kept and refunded when the reservation was canceled
+type Cancellation struct {
+	CanceledAt time.Time          `json:"canceled_at"`
+	Tier       string             `json:"tier"`
+	Fee        float64            `json:"fee"`
+	Refund     float64            `json:"refund"`
+	Policy     CancellationPolicy `json:"policy"`
+}
+
+// CancellationQuote is what canceling the reservation costs, it holds until ValidUntil
+type CancellationQuote struct {
+	ReservationID string             `json:"reservation_id"`
+	Price         float64            `json:"price"`
+	Fee           float64            `json:"fee"`
+	Refund        float64            `json:"refund"`
+	Tier          string             `json:"tier"`
+	Policy        CancellationPolicy `json:"policy"`
+	QuotedAt      time.Time          `json:"quoted_at"`
+	ValidUntil    *time.Time         `json:"valid_until,omitempty"`
+}
+
+// ReservationFilter selects reservations, From and To select reservations
+// overlapping that range. Without a UserID only admins may list reservations.
+type ReservationFilter struct {
+	UserID string
+	SpotID string
+	Status string
+	From   *time.Time
+	To     *time.Time
+}
+
+type AddReservationInput struct {
+	UserID    string    `json:"user_id"`
+	SpotID    string    `json:"spot_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	VehicleID string    `json:"vehicle_id,omitempty"`
+
+	// PromoCode takes its discount off the price of the spot
+	PromoCode string `json:"promo_code,omitempty"`
+
+	// QuoteToken books at the price of a quote instead of the price of the spot, see CreateQuote
+	QuoteToken string `json:"quote_token,omitempty"`
+}
+
+// EditReservationInput leaves nil and empty fields unchanged. Moving the reservation
+// to another spot or window prices it again.
+type EditReservationInput struct {
+	ReservationID string     `json:"reservation_id"`
+	UserID        string     `json:"user_id"`
+	SpotID        string     `json:"spot_id"`
+	StartTime     *time.Time `json:"start_time,omitempty"`
+	EndTime       *time.Time `json:"end_time,omitempty"`
+	VehicleID     string     `json:"vehicle_id,omitempty"`
+}
+
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+const (
+	StrategyCheapest           = "cheapest"
+	StrategyClosest            = "closest"
+	StrategyLeastFragmentation = "least_fragmentation"
+)
+
+// AutoReserveInput describes the spot to pick, an empty strategy uses the facade's default
+type AutoReserveInput struct {
+	UserID    string       `json:"user_id"`
+	StartTime time.Time    `json:"start_time"`
+	EndTime   time.Time    `json:"end_time"`
+	LotID     string       `json:"lot_id,omitempty"`
+	Size      string       `json:"size,omitempty"`
+	Type      string       `json:"type,omitempty"`
+	MaxPrice  float64      `json:"max_price,omitempty"`
+	Near      *Coordinates `json:"near,omitempty"`
+	VehicleID string       `json:"vehicle_id,omitempty"`
+	Strategy  string       `json:"strategy,omitempty"`
+}
+
+// TimelineInput selects the spots and the window, zero durations use the facade's defaults
+type TimelineInput struct {
+	SpotIDs     []string
+	From        time.Time
+	To          time.Time
+	Granularity time.Duration
+	MinFree     time.Duration
+}
+
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type SpotTimeline struct {
+	SpotID string     `json:"spot_id"`
+	Busy   []Interval `json:"busy"`
+	Free   []Interval `json:"free"`
+}
+
+// ListReservations returns one page of reservations, the reservations of
+// filter.UserID when it is set and all of them otherwise
+func (c *Client) ListReservations(ctx context.Context, filter ReservationFilter, opts ListOptions) (Page[Reservation], error) {
+	path := "/reservations"
+	query := url.Values{}
+	if filter.UserID != "" {
+		path = "/reservations/user/" + url.PathEscape(filter.UserID)
+	}
+	setIfNotEmpty(query, "spot_id", filter.SpotID)
+	setIfNotEmpty(query, "status", filter.Status)
+	if filter.From != nil {
+		query.Set("from", filter.From.Format(time.RFC3339))
+	}
+	if filter.To != nil {
+		query.Set("to", filter.To.Format(time.RFC3339))
+	}
+	opts.apply(query)
+
+	var page Page[Reservation]
+	err := c.do(ctx, request{method: http.MethodGet, path: path, query: query, auth: true}, &page)
+	return page, err
+}
+
+// Reservations iterates over all reservations matching the filter, starting at opts.Cursor
+func (c *Client) Reservations(filter ReservationFilter, opts ListOptions) *Iterator[Reservation] {
+	return newIterator(opts.Cursor, func(ctx context.Context, cursor string) (Page[Reservation], error) {
+		opts.Cursor = cursor
+		return c.ListReservations(ctx, filter, opts)
+	})
+}
+
+func (c *Client) GetReservation(ctx context.Context, reservationID string) (Reservation, error) {
+	var reservation Reservation
+	err := c.do(ctx, request{method: http.MethodGet, path: "/reservations/" + url.PathEscape(reservationID), auth: true}, &reservation)
+	return reservation, err
+}
+
+// AddReservation books the spot and returns the reservation ID, a taken spot fails with CodeSpotUnavailable
+func (c *Client) AddReservation(ctx context.Context, input AddReservationInput) (string, error) {
+	var reservationID string
+	err := c.do(ctx, request{method: http.MethodPost, path: "/reservations", body: input, auth: true}, &reservationID)
+	return reservationID, err
+}
+
+func (c *Client) EditReservation(ctx context.Context, input EditReservationInput) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: "/reservations", body: input, auth: true}, nil)
+}
+
+// QuoteCancellation tells what CancelReservation would keep and refund now
+func (c *Client) QuoteCancellation(ctx context.Context, reservationID string) (CancellationQuote, error) {
+	var quote CancellationQuote
+	err := c.do(ctx, request{method: http.MethodGet, path: "/reservations/" + url.PathEscape(reservationID) + "/cancellation", auth: true}, &quote)
+	return quote, err
+}
+
+// CancelReservation cancels under the cancellation policy of the spot, see QuoteCancellation
+func (c *Client) CancelReservation(ctx context.Context, reservationID string) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: "/reservations/cancel/" + url.PathEscape(reservationID), auth: true}, nil)
+}
+
+// DeleteReservation removes a reservation for good. Admin only.
+func (c *Client) DeleteReservation(ctx context.Context, reservationID string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/reservations/" + url.PathEscape(reservationID), auth: true}, nil)
+}
+
+// AutoReserve lets the facade pick and book a spot matching the input
+func (c *Client) AutoReserve(ctx context.Context, input AutoReserveInput) (Reservation, error) {
+	var reservation Reservation
+	err := c.do(ctx, request{method: http.MethodPost, path: "/reservations/auto", body: input, auth: true}, &reservation)
+	return reservation, err
+}
+
+// GetTimeline returns the busy and free intervals of the spots in the window
+func (c *Client) GetTimeline(ctx context.Context, input TimelineInput) ([]SpotTimeline, error) {
+	query := url.Values{}
+	query.Set("spot_ids", strings.Join(input.SpotIDs, ","))
+	query.Set("from", input.From.Format(time.RFC3339))
+	query.Set("to", input.To.Format(time.RFC3339))
+	if input.Granularity > 0 {
+		query.Set("granularity", input.Granularity.String())
+	}
+	if input.MinFree > 0 {
+		query.Set("min_free", input.MinFree.String())
+	}
+
+	var timelines []SpotTimeline
+	err := c.do(ctx, request{method: http.MethodGet, path: "/reservations/timeline", query: query, auth: true}, &timelines)
+	return timelines, err
+}
+
+// LookupReservation returns the reservation of the plate that is active on the spot right now. Attendants only.
+func (c *Client) LookupReservation(ctx context.Context, spotID, licensePlate string) (Reservation, error) {
+	query := url.Values{}
+	query.Set("spot_id", spotID)
+	query.Set("license_plate", licensePlate)
+
+	var reservation Reservation
+	err := c.do(ctx, request{method: http.MethodGet, path: "/reservations/lookup", query: query, auth: true}, &reservation)
+	return reservation, err
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Spot struct {
+	ID           string    `json:"id,omitempty"`
+	SpotID       string    `json:"spot_id"`
+	LotID        string    `json:"lot_id,omitempty"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	PricePerHour float64   `json:"price_per_hour"`
+	Size         string    `json:"size"`
+	Type         string    `json:"type"`
+	MaxHeightCm  int       `json:"max_height_cm,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type SpotFilter struct {
+	LotID string
+	Size  string
+	Type  string
+}
+
+type AddSpotInput struct {
+	LotID        string  `json:"lot_id,omitempty"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	PricePerHour float64 `json:"price_per_hour"`
+	Size         string  `json:"size"`
+	Type         string  `json:"type"`
+	MaxHeightCm  int     `json:"max_height_cm,omitempty"`
+}
+
+// EditSpotInput leaves nil and empty fields unchanged
+type EditSpotInput struct {
+	SpotID       string   `json:"spot_id"`
+	LotID        *string  `json:"lot_id,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+	PricePerHour *float64 `json:"price_per_hour,omitempty"`
+	Size         string   `json:"size,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	MaxHeightCm  *int     `json:"max_height_cm,omitempty"`
+}
+
+type AvailabilityInput struct {
+	SpotIDs   []string
+	StartTime time.Time
+	EndTime   time.Time
+
+	// VehicleID narrows the result down to the spots the caller's vehicle can use
+	VehicleID string
+}
+
+func (c *Client) ListSpots(ctx context.Context, filter SpotFilter, opts ListOptions) (Page[Spot], error) {
+	query := url.Values{}
+	setIfNotEmpty(query, "lot_id", filter.LotID)
+	setIfNotEmpty(query, "size", filter.Size)
+	setIfNotEmpty(query, "type", filter.Type)
+	opts.apply(query)
+
+	var page Page[Spot]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/spots", query: query, auth: true}, &page)
+	return page, err
+}
+
+// Spots iterates over all spots matching the filter, starting at opts.Cursor
+func (c *Client) Spots(filter SpotFilter, opts ListOptions) *Iterator[Spot] {
+	return newIterator(opts.Cursor, func(ctx context.Context, cursor string) (Page[Spot], error) {
+		opts.Cursor = cursor
+		return c.ListSpots(ctx, filter, opts)
+	})
+}
+
+func (c *Client) GetSpot(ctx context.Context, spotID string) (Spot, error) {
+	var spot Spot
+	err := c.do(ctx, request{method: http.MethodGet, path: "/spots/" + url.PathEscape(spotID), auth: true}, &spot)
+	return spot, err
+}
+
+// AddSpot creates a spot and returns its ID. Admin only.
+func (c *Client) AddSpot(ctx context.Context, input AddSpotInput) (string, error) {
+	var spotID string
+	err := c.do(ctx, request{method: http.MethodPost, path: "/spots", body: input, auth: true}, &spotID)
+	return spotID, err
+}
+
+// EditSpot changes a spot. Admin only.
+func (c *Client) EditSpot(ctx context.Context, input EditSpotInput) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: "/spots", body: input, auth: true}, nil)
+}
+
+// DeleteSpot removes a spot. Admin only.
+func (c *Client) DeleteSpot(ctx context.Context, spotID string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/spots/" + url.PathEscape(spotID), auth: true}, nil)
+}
+
+// GetSpotPrice returns the price of parking on the spot in the timeframe
+func (c *Client) GetSpotPrice(ctx context.Context, spotID string, start, end time.Time) (float64, error) {
+	query := url.Values{}
+	query.Set("spot_id", spotID)
+	query.Set("start_time", start.Format(time.RFC3339))
+	query.Set("end_time", end.Format(time.RFC3339))
+
+	var resp struct {
+		Price float64 `json:"price"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/spots/price", query: query, auth: true}, &resp)
+	return resp.Price, err
+}
+
+// GetAvailableSpots returns the IDs of the spots that are free for the whole timeframe
+func (c *Client) GetAvailableSpots(ctx context.Context, input AvailabilityInput) ([]string, error) {
+	query := url.Values{}
+	query.Set("spot_ids", strings.Join(input.SpotIDs, ","))
+	query.Set("start_time", input.StartTime.Format(time.RFC3339))
+	query.Set("end_time", input.EndTime.Format(time.RFC3339))
+	setIfNotEmpty(query, "vehicle_id", input.VehicleID)
+
+	var spotIDs []string
+	err := c.do(ctx, request{method: http.MethodGet, path: "/spots/available", query: query, auth: true}, &spotIDs)
+	return spotIDs, err
+}
+
+func setIfNotEmpty(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type User struct {
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Vehicles  []Vehicle `json:"vehicles,omitempty"`
+	Billing   *Billing  `json:"billing,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Billing holds the details invoices are issued to. Business accounts need
+// a name and a tax ID and are invoiced monthly.
+type Billing struct {
+	Name     string `json:"name,omitempty"`
+	TaxID    string `json:"tax_id,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Business bool   `json:"business"`
+}
+
+type Vehicle struct {
+	VehicleID    string `json:"vehicle_id"`
+	LicensePlate string `json:"license_plate"`
+	Country      string `json:"country"`
+	Region       string `json:"region,omitempty"`
+	Make         string `json:"make,omitempty"`
+	Size         string `json:"size"`
+	IsEV         bool   `json:"is_ev"`
+	HeightCm     int    `json:"height_cm,omitempty"`
+}
+
+type RegisterInput struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// EditUserInput leaves empty fields unchanged, Billing replaces the billing details as a whole
+type EditUserInput struct {
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Billing  *Billing `json:"billing,omitempty"`
+}
+
+type AddVehicleInput struct {
+	LicensePlate string `json:"license_plate"`
+	Country      string `json:"country"`
+	Region       string `json:"region,omitempty"`
+	Make         string `json:"make,omitempty"`
+	Size         string `json:"size"`
+	IsEV         bool   `json:"is_ev"`
+	HeightCm     int    `json:"height_cm,omitempty"`
+}
+
+// Register creates a user with the user role and returns its ID
+func (c *Client) Register(ctx context.Context, input RegisterInput) (string, error) {
+	var userID string
+	err := c.do(ctx, request{method: http.MethodPost, path: "/users/register", body: input}, &userID)
+	return userID, err
+}
+
+// Login obtains a token for the user. The credentials are kept, so the
+// client logs in again by itself when the token expires.
+func (c *Client) Login(ctx context.Context, username, password string) (string, error) {
+	token, err := c.login(ctx, username, password)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.username, c.password = username, password
+	c.mu.Unlock()
+
+	return token, nil
+}
+
+func (c *Client) login(ctx context.Context, username, password string) (string, error) {
+	input := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{username, password}
+
+	var resp struct {
+		Jwt string `json:"jwt"`
+	}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/users/login", body: input}, &resp); err != nil {
+		return "", err
+	}
+
+	c.setToken(resp.Jwt)
+	return resp.Jwt, nil
+}
+
+// ListUsers returns one page of users, an empty role lists all of them. Admin only.
+func (c *Client) ListUsers(ctx context.Context, role string, opts ListOptions) (Page[User], error) {
+	query := url.Values{}
+	if role != "" {
+		query.Set("role", role)
+	}
+	opts.apply(query)
+
+	var page Page[User]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/users", query: query, auth: true}, &page)
+	return page, err
+}
+
+// Users iterates over all users with the role, starting at opts.Cursor. Admin only.
+func (c *Client) Users(role string, opts ListOptions) *Iterator[User] {
+	return newIterator(opts.Cursor, func(ctx context.Context, cursor string) (Page[User], error) {
+		opts.Cursor = cursor
+		return c.ListUsers(ctx, role, opts)
+	})
+}
+
+func (c *Client) GetUser(ctx context.Context, userID string) (User, error) {
+	var user User
+	err := c.do(ctx, request{method: http.MethodGet, path: "/users/" + url.PathEscape(userID), auth: true}, &user)
+	return user, err
+}
+
+func (c *Client) EditUser(ctx context.Context, input EditUserInput) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: "/users", body: input, auth: true}, nil)
+}
+
+// EditUserRole changes the role of a user. Admin only.
+func (c *Client) EditUserRole(ctx context.Context, userID, role string) error {
+	input := struct {
+		UserID string `json:"user_id"`
+		Role   string `json:"role"`
+	}{userID, role}
+
+	return c.do(ctx, request{method: http.MethodPatch, path: "/users/role", body: input, auth: true}, nil)
+}
+
+func (c *Client) DeleteUser(ctx context.Context, userID string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/users/" + url.PathEscape(userID), auth: true}, nil)
+}
+
+// AddVehicle registers a vehicle of the user and returns its ID
+func (c *Client) AddVehicle(ctx context.Context, userID string, input AddVehicleInput) (string, error) {
+	var vehicleID string
+	err := c.do(ctx, request{method: http.MethodPost, path: "/users/" + url.PathEscape(userID) + "/vehicles", body: input, auth: true}, &vehicleID)
+	return vehicleID, err
+}
+
+func (c *Client) GetVehicles(ctx context.Context, userID string) ([]Vehicle, error) {
+	var vehicles []Vehicle
+	err := c.do(ctx, request{method: http.MethodGet, path: "/users/" + url.PathEscape(userID) + "/vehicles", auth: true}, &vehicles)
+	return vehicles, err
+}
+
+func (c *Client) DeleteVehicle(ctx context.Context, userID, vehicleID string) error {
+	path := "/users/" + url.PathEscape(userID) + "/vehicles/" + url.PathEscape(vehicleID)
+	return c.do(ctx, request{method: http.MethodDelete, path: path, auth: true}, nil)
+}
@@ -1,30 +1,88 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/openapi"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/server"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/services/user"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/tracing"
 )
 
 func main() {
+	// Registered first so that it runs after all the other deferred cleanups
+	exitCode := 0
+	defer func() { os.Exit(exitCode) }()
+
 	// Get logger
 	lgr := logger.GetLogger()
 
 	// Get config
 	cfg := config.GetConfig()
 
+	// Set up tracing
+	shutdownTracing, err := tracing.Init("facade", cfg)
+	if err != nil {
+		lgr.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Get user service
-	usr := user.NewUserService(cfg)
+	usr, err := user.NewUserService(cfg)
+	if err != nil {
+		lgr.Error("Failed to set up user service client", "error", err)
+		exitCode = 1
+		return
+	}
+	defer usr.Close()
 
 	// Get spot service
-	spt := spot.NewSpotService(cfg)
+	spt, err := spot.NewSpotService(cfg)
+	if err != nil {
+		lgr.Error("Failed to set up spot service client", "error", err)
+		exitCode = 1
+		return
+	}
+	defer spt.Close()
 
 	// Get reservation service
-	rsrv := reservation.NewReservationService(cfg)
+	rsrv, err := reservation.NewReservationService(cfg)
+	if err != nil {
+		lgr.Error("Failed to set up reservation service client", "error", err)
+		exitCode = 1
+		return
+	}
+	defer rsrv.Close()
 
 	s := server.NewServer(lgr, cfg, usr, spt, rsrv)
-	s.Start()
+
+	// Validate requests against the OpenAPI document
+	if cfg.OpenAPIValidation {
+		v, err := openapi.NewValidator()
+		if err != nil {
+			lgr.Error("Failed to load OpenAPI document", "error", err)
+			exitCode = 1
+			return
+		}
+		s.RequestValidator = v
+	}
+
+	// Stop gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := s.Start(ctx); err != nil {
+		lgr.Error("Server stopped unexpectedly", "error", err)
+		exitCode = 1
+		return
+	}
+	lgr.Info("Server stopped")
 }
@@ -3,23 +3,65 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	ServerHost     string
-	ServerPort     string
-	SpotURL        string
-	UserURL        string
-	ReservationURL string
+	ServerHost      string
+	ServerPort      string
+	SpotAddr        string
+	UserAddr        string
+	ReservationAddr string
+	AssignStrategy  string
+
+	DownstreamTimeout       time.Duration
+	DownstreamMaxRetries    int
+	DownstreamRetryBackoff  time.Duration
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+
+	TracesExporter string
+	TracesFile     string
+
+	OpenAPIValidation bool
+
+	QuoteSigningKey string
+	QuoteTTL        time.Duration
+
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
 }
 
 func GetConfig() *Config {
 	return &Config{
-		ServerHost:     getEnv("SERVER_HOST", "localhost"),
-		ServerPort:     getEnv("SERVER_PORT", "3004"),
-		SpotURL:        getEnv("SPOT_URL", "http://localhost:3002"),
-		UserURL:        getEnv("USER_URL", "http://localhost:3001"),
-		ReservationURL: getEnv("RESERVATION_URL", "http://localhost:3003"),
+		ServerHost:      getEnv("SERVER_HOST", "localhost"),
+		ServerPort:      getEnv("SERVER_PORT", "3004"),
+		SpotAddr:        getEnv("SPOT_GRPC_ADDR", "localhost:4002"),
+		UserAddr:        getEnv("USER_GRPC_ADDR", "localhost:4001"),
+		ReservationAddr: getEnv("RESERVATION_GRPC_ADDR", "localhost:4003"),
+		AssignStrategy:  getEnv("ASSIGN_STRATEGY", "cheapest"),
+
+		DownstreamTimeout:       getDurationEnv("DOWNSTREAM_TIMEOUT", 5*time.Second),
+		DownstreamMaxRetries:    getIntEnv("DOWNSTREAM_MAX_RETRIES", 2),
+		DownstreamRetryBackoff:  getDurationEnv("DOWNSTREAM_RETRY_BACKOFF", 100*time.Millisecond),
+		BreakerFailureThreshold: getIntEnv("BREAKER_FAILURE_THRESHOLD", 5),
+		BreakerOpenTimeout:      getDurationEnv("BREAKER_OPEN_TIMEOUT", 30*time.Second),
+
+		TracesExporter: getEnv("TRACES_EXPORTER", "none"),
+		TracesFile:     getEnv("TRACES_FILE", "traces.json"),
+
+		OpenAPIValidation: getBoolEnv("OPENAPI_VALIDATION", false),
+
+		QuoteSigningKey: getEnv("QUOTE_SIGNING_KEY", ""),
+		QuoteTTL:        getDurationEnv("QUOTE_TTL", 10*time.Minute),
+
+		ReadTimeout:     getDurationEnv("READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:    getDurationEnv("WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:     getDurationEnv("IDLE_TIMEOUT", 60*time.Second),
+		ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 20*time.Second),
 	}
 }
 
@@ -31,3 +73,48 @@ func getEnv(key, df string) string {
 	}
 	return val
 }
+
+func getDurationEnv(key string, df time.Duration) time.Duration {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		log.Printf("Using default value for %s (%s)", key, df)
+		return df
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid value for %s (%s), using default (%s)", key, val, df)
+		return df
+	}
+	return d
+}
+
+func getIntEnv(key string, df int) int {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		log.Printf("Using default value for %s (%d)", key, df)
+		return df
+	}
+
+	i, err := strconv.Atoi(val)
+	if err != nil || i < 0 {
+		log.Printf("Invalid value for %s (%s), using default (%d)", key, val, df)
+		return df
+	}
+	return i
+}
+
+func getBoolEnv(key string, df bool) bool {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		log.Printf("Using default value for %s (%t)", key, df)
+		return df
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Printf("Invalid value for %s (%s), using default (%t)", key, val, df)
+		return df
+	}
+	return b
+}
@@ -0,0 +1,103 @@
+package grpcclient
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/metrics"
+)
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// breaker opens after failureThreshold consecutive failures and rejects calls
+// for openTimeout. After that a single trial call decides whether it closes again.
+type breaker struct {
+	name             string
+	mu               sync.Mutex
+	state            breakerState
+	failures         int
+	openedAt         time.Time
+	trialInFlight    bool
+	failureThreshold int
+	openTimeout      time.Duration
+}
+
+func newBreaker(name string, failureThreshold int, openTimeout time.Duration) *breaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	metrics.DownstreamCircuitOpen.WithLabelValues(name).Set(0)
+
+	return &breaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+	}
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.setState(stateHalfOpen)
+		b.trialInFlight = true
+		return true
+	case stateHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	}
+	return true
+}
+
+func (b *breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+
+	if success {
+		b.setState(stateClosed)
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.failureThreshold {
+		b.setState(stateOpen)
+		b.openedAt = time.Now()
+	}
+}
+
+// release gives up a call without judging the service, e.g. when the caller canceled it
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+}
+
+// setState has to be called with the lock held
+func (b *breaker) setState(state breakerState) {
+	b.state = state
+
+	open := 0.0
+	if state == stateOpen {
+		open = 1
+	}
+	metrics.DownstreamCircuitOpen.WithLabelValues(b.name).Set(open)
+}
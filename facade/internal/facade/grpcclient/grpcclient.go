@@ -0,0 +1,296 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/metrics"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/problempb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Error is a failed call to a downstream service
+type Error struct {
+	Service string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Service + " service: " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// client bounds every attempt of a call to a single downstream service with
+// a timeout, retries idempotent calls and stops calling the service while it keeps failing.
+type client struct {
+	name         string
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+	breaker      *breaker
+}
+
+// Dial returns the connection to a downstream service. The connection is
+// established lazily, so a service that is down doesn't stop the facade from starting.
+func Dial(name, addr string, cfg *config.Config) (*grpc.ClientConn, error) {
+	c := &client{
+		name:         name,
+		timeout:      cfg.DownstreamTimeout,
+		maxRetries:   cfg.DownstreamMaxRetries,
+		retryBackoff: cfg.DownstreamRetryBackoff,
+		breaker:      newBreaker(name, cfg.BreakerFailureThreshold, cfg.BreakerOpenTimeout),
+	}
+
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(
+			logger.UnaryClientInterceptor,
+			c.intercept,
+		),
+		grpc.WithChainStreamInterceptor(
+			logger.StreamClientInterceptor,
+			c.interceptStream,
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s service: %w", name, err)
+	}
+
+	return conn, nil
+}
+
+func (c *client) intercept(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	name := path.Base(method)
+
+	attempts := 1
+	if isIdempotent(name) {
+		attempts += c.maxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, attempt); err != nil {
+				return &Error{Service: c.name, Err: err}
+			}
+		}
+
+		if !c.breaker.allow() {
+			metrics.DownstreamRequests.WithLabelValues(c.name, name, "circuit_open").Inc()
+			return &Error{Service: c.name, Err: ErrCircuitOpen}
+		}
+
+		start := time.Now()
+		err := c.invoke(ctx, method, req, reply, cc, invoker, opts...)
+		code := status.Code(err)
+		metrics.DownstreamRequests.WithLabelValues(c.name, name, code.String()).Inc()
+		metrics.DownstreamDuration.WithLabelValues(c.name, name).Observe(time.Since(start).Seconds())
+
+		// A caller that went away says nothing about the service's health
+		if ctx.Err() != nil {
+			c.breaker.release()
+			if err != nil {
+				return &Error{Service: c.name, Err: err}
+			}
+			return nil
+		}
+
+		c.breaker.record(!isServerFailure(code))
+		if err == nil {
+			return nil
+		}
+
+		lastErr = &Error{Service: c.name, Err: err}
+		if !isRetryable(code) {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// interceptStream opens a streaming call. Streams last as long as the caller wants,
+// so they get neither the attempt timeout nor retries. The breaker judges the service
+// by whether it sends the headers of the stream within the timeout, the caller should
+// wait for them with Header before receiving.
+func (c *client) interceptStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	name := path.Base(method)
+
+	if !c.breaker.allow() {
+		metrics.DownstreamRequests.WithLabelValues(c.name, name, "circuit_open").Inc()
+		return nil, &Error{Service: c.name, Err: ErrCircuitOpen}
+	}
+
+	// A deadline would bound the whole stream, canceling it bounds only the wait for the headers
+	ctx, cancel := context.WithCancel(ctx)
+	cs := &clientStream{client: c, ctx: ctx, cancel: cancel, method: name, start: time.Now()}
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		cs.opened(err)
+		cancel()
+		return nil, &Error{Service: c.name, Err: err}
+	}
+	cs.ClientStream = stream
+	cs.timer = time.AfterFunc(c.timeout, cancel)
+
+	return cs, nil
+}
+
+// clientStream wraps the errors of a stream like those of unary calls
+type clientStream struct {
+	grpc.ClientStream
+	client *client
+	ctx    context.Context
+	cancel context.CancelFunc
+	method string
+	start  time.Time
+	timer  *time.Timer
+
+	once   sync.Once
+	header metadata.MD
+	err    error
+}
+
+// Header waits for the headers and reports the outcome of the opening to the breaker
+func (s *clientStream) Header() (metadata.MD, error) {
+	s.once.Do(func() {
+		md, err := s.ClientStream.Header()
+		if err == nil && md == nil {
+			// The service ended the call without headers, the status tells why
+			err = s.ClientStream.RecvMsg(new(emptypb.Empty))
+		}
+		if !s.timer.Stop() {
+			err = status.Error(codes.DeadlineExceeded, "no headers within the downstream timeout")
+		}
+
+		s.opened(err)
+		s.header = md
+		if err != nil {
+			s.cancel()
+			s.err = &Error{Service: s.client.name, Err: err}
+		}
+	})
+
+	return s.header, s.err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	if _, err := s.Header(); err != nil {
+		return err
+	}
+
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		return nil
+	}
+
+	// The stream is over either way
+	s.cancel()
+	if err == io.EOF {
+		return err
+	}
+	return &Error{Service: s.client.name, Err: err}
+}
+
+func (s *clientStream) opened(err error) {
+	code := status.Code(err)
+	metrics.DownstreamRequests.WithLabelValues(s.client.name, s.method, code.String()).Inc()
+	metrics.DownstreamDuration.WithLabelValues(s.client.name, s.method).Observe(time.Since(s.start).Seconds())
+
+	// A caller that went away says nothing about the service's health
+	if s.ctx.Err() != nil && code != codes.DeadlineExceeded {
+		s.client.breaker.release()
+		return
+	}
+	s.client.breaker.record(!isServerFailure(code))
+}
+
+// invoke makes a single attempt bounded by the downstream timeout
+func (c *client) invoke(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// wait sleeps for an exponential backoff with full jitter before the given retry
+func (c *client) wait(ctx context.Context, attempt int) error {
+	backoff := c.retryBackoff << (attempt - 1)
+	delay := time.Duration(rand.Int63n(int64(backoff) + 1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IsTimeout reports whether the call failed because the service didn't answer in time
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
+
+// Problem returns the problem details the service attached to the failed call, if any
+func Problem(err error) (*problempb.Problem, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+
+	for _, detail := range st.Details() {
+		if p, ok := detail.(*problempb.Problem); ok && p.GetCode() != "" {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Retrying is only safe for calls that don't change anything. Deletes aren't retried:
+// a delete that succeeded but timed out would be reported as not found on the retry.
+func isIdempotent(method string) bool {
+	for _, prefix := range []string{"Get", "List", "Check", "Search", "Authorize"} {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRetryable(code codes.Code) bool {
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// isServerFailure tells the breaker which codes mean the service itself is in trouble
+func isServerFailure(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DeadlineExceeded, codes.Unimplemented, codes.DataLoss:
+		return true
+	}
+	return false
+}
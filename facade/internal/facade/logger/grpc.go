@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey carries the request ID between services over gRPC
+const RequestIDMetadataKey = "x-request-id"
+
+// UnaryClientInterceptor passes the request ID of the context on to the called service
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, id)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// StreamClientInterceptor does the same for streaming calls
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if id := RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, id)
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
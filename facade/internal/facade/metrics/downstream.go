@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	DownstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "downstream_requests_total",
+		Help: "Number of calls to internal services by outcome, retries included.",
+	}, []string{"service", "method", "status"})
+
+	DownstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "downstream_request_duration_seconds",
+		Help:    "Latency of calls to internal services.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service", "method"})
+
+	DownstreamCircuitOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "downstream_circuit_open",
+		Help: "Whether the circuit breaker of an internal service is open (1) or not (0).",
+	}, []string{"service"})
+)
@@ -0,0 +1,156 @@
+// Package openapi embeds the OpenAPI document of the facade, serves it
+// together with a docs UI and validates incoming requests against it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+func init() {
+	openapi3.DefineStringFormat("email", openapi3.FormatOfStringForEmail)
+	// Keep validation errors in the logs to a single line
+	openapi3.SchemaErrorDetailsDisabled = true
+}
+
+// Load parses and validates the embedded OpenAPI document
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// SpecHandler serves the OpenAPI document
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// DocsHandler serves the docs UI rendering the OpenAPI document
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
+
+// Validator checks requests against the operations of the OpenAPI document
+type Validator struct {
+	router  routers.Router
+	options *openapi3filter.Options
+}
+
+func NewValidator() (*Validator, error) {
+	doc, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{
+		router: router,
+		options: &openapi3filter.Options{
+			MultiError: true,
+			// Tokens are checked by the authorize middleware
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}, nil
+}
+
+// Validate checks the parameters and the body of the request.
+// Requests that match no operation are left for the router to reject.
+func (v *Validator) Validate(r *http.Request) error {
+	route, pathParams, err := v.router.FindRoute(r)
+	if err != nil {
+		return nil
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    v.options,
+	}
+	return openapi3filter.ValidateRequest(context.WithoutCancel(r.Context()), input)
+}
+
+// FieldErrors converts a validation error into per-field errors.
+// Body fields are reported by their JSON path, parameters by their name.
+func FieldErrors(err error) []problem.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var fields []problem.FieldError
+		for _, inner := range e {
+			fields = append(fields, FieldErrors(inner)...)
+		}
+		return fields
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			var fields []problem.FieldError
+			for _, inner := range flatten(e.Err) {
+				rule, message := describe(inner)
+				if message == "" {
+					message = e.Reason
+				}
+				fields = append(fields, problem.FieldError{Field: e.Parameter.Name, Rule: rule, Message: message})
+			}
+			return fields
+		}
+		if fields := FieldErrors(e.Err); fields != nil {
+			return fields
+		}
+		return []problem.FieldError{{Field: "body", Rule: "schema", Message: e.Error()}}
+	case *openapi3.SchemaError:
+		field := strings.Join(e.JSONPointer(), ".")
+		if field == "" {
+			field = "body"
+		}
+		return []problem.FieldError{{Field: field, Rule: e.SchemaField, Message: e.Reason}}
+	}
+	return nil
+}
+
+// flatten unpacks the errors collected for a single parameter
+func flatten(err error) []error {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		return multi
+	}
+	return []error{err}
+}
+
+// describe returns the failed schema keyword and the reason of a parameter error
+func describe(err error) (string, string) {
+	if schemaErr, ok := err.(*openapi3.SchemaError); ok {
+		return schemaErr.SchemaField, schemaErr.Reason
+	}
+	if errors.Is(err, openapi3filter.ErrInvalidRequired) {
+		return "required", err.Error()
+	}
+	if err != nil {
+		return "schema", err.Error()
+	}
+	return "schema", ""
+}
@@ -0,0 +1,55 @@
+package quote
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIssueVerify(t *testing.T) {
+	now := time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+	signer := NewSigner("0123456789abcdef", 10*time.Minute)
+	other := NewSigner("fedcba9876543210", 10*time.Minute)
+
+	issued, token, err := signer.Issue(Quote{SpotID: "s1", UserID: "u1", StartTime: now, EndTime: now.Add(time.Hour), ListPrice: 10, Price: 10}, now)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if issued.QuoteID == "" || !issued.ExpiresAt.Equal(now.Add(10*time.Minute)) {
+		t.Fatalf("issued = %+v, want an ID and an expiry in 10 minutes", issued)
+	}
+
+	// The same quote at a lower price, signed with the original signature
+	encoded, signature, _ := strings.Cut(token, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(encoded)
+	cheaper := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(payload), `"price":10`, `"price":1`, 1)))
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		token  string
+		now    time.Time
+		want   error
+	}{
+		{"valid", signer, token, now.Add(time.Minute), nil},
+		{"expired", signer, token, now.Add(10 * time.Minute), ErrExpired},
+		{"another key", other, token, now, ErrTampered},
+		{"changed price", signer, cheaper + "." + signature, now, ErrTampered},
+		{"no signature", signer, encoded, now, ErrMalformed},
+		{"not base64", signer, encoded + ".!", now, ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := tt.signer.Verify(tt.token, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify = %v, want %v", err, tt.want)
+			}
+			if err == nil && (q.QuoteID != issued.QuoteID || !q.Covers("s1", "u1", now, now.Add(time.Hour))) {
+				t.Errorf("Verify = %+v, want the issued quote", q)
+			}
+		})
+	}
+}
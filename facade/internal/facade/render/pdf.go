@@ -0,0 +1,171 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// A4 in points with a 50pt margin
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+)
+
+// The x offsets of the columns of a table row
+var columns = []float64{0, 190, 350, 400, 450}
+
+// fontEncoding is Windows-1250: WinAnsiEncoding with the glyphs of the Central European
+// letters, e.g. the Polish ł, ś, ż and ę, in place of the codes that differ
+const fontEncoding = "<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [" +
+	"140 /Sacute /Tcaron 143 /Zacute 156 /sacute /tcaron 159 /zacute " +
+	"161 /caron /breve /Lslash 165 /Aogonek 170 /Scedilla 175 /Zdotaccent 178 /ogonek /lslash " +
+	"185 /aogonek /scedilla 188 /Lcaron /hungarumlaut /lcaron /zdotaccent /Racute 195 /Abreve " +
+	"197 /Lacute /Cacute 200 /Ccaron 202 /Eogonek 204 /Ecaron 207 /Dcaron /Dcroat /Nacute /Ncaron " +
+	"213 /Ohungarumlaut 216 /Rcaron /Uring 219 /Uhungarumlaut 222 /Tcommaaccent 224 /racute 227 /abreve " +
+	"229 /lacute /cacute 232 /ccaron 234 /eogonek 236 /ecaron 239 /dcaron /dcroat /nacute /ncaron " +
+	"245 /ohungarumlaut 248 /rcaron /uring 251 /uhungarumlaut 254 /tcommaaccent /dotaccent] >>"
+
+// document lays out lines of text top down in the standard Helvetica fonts, which every
+// PDF reader has, so nothing needs to be embedded. Text is written in fontEncoding, other
+// characters lose their accents or are replaced.
+type document struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newDocument() *document {
+	d := &document{}
+	d.newPage()
+	return d
+}
+
+func (d *document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// advance moves down by the height of the next line, starting a new page when it doesn't fit
+func (d *document) advance(height float64) {
+	if d.y-height < margin {
+		d.newPage()
+	}
+	d.y -= height
+}
+
+func (d *document) space(height float64) {
+	d.y -= height
+}
+
+func (d *document) text(x, size float64, bold bool, s string) {
+	d.advance(size * 1.4)
+	d.write(x, size, bold, s)
+}
+
+func (d *document) row(bold bool, cells ...string) {
+	d.advance(9 * 1.6)
+	for i, cell := range cells {
+		d.write(columns[i], 9, bold, cell)
+	}
+}
+
+func (d *document) party(party reservation.Party) {
+	d.text(0, 10, false, party.Name)
+	if party.TaxID != "" {
+		d.text(0, 10, false, "Tax ID "+party.TaxID)
+	}
+	for _, line := range strings.Split(party.Address, "\n") {
+		if line != "" {
+			d.text(0, 10, false, line)
+		}
+	}
+	if party.Email != "" {
+		d.text(0, 10, false, party.Email)
+	}
+}
+
+func (d *document) write(x, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %g Tf %g %g Td (%s) Tj ET\n", font, size, margin+x, d.y, escape(s))
+}
+
+// bytes writes the catalog, the page tree, the two fonts and a page with its content
+// stream per page, followed by the cross-reference table
+func (d *document) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding " + fontEncoding + " >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding " + fontEncoding + " >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// escape encodes the text as a PDF literal string in fontEncoding
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		default:
+			if c, ok := encodeRune(r); ok {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// encodeRune falls back to the letter without its accents, e.g. å to a
+func encodeRune(r rune) (byte, bool) {
+	if c, ok := charmap.Windows1250.EncodeRune(r); ok {
+		return c, true
+	}
+	base := []rune(norm.NFD.String(string(r)))[0]
+	if base < 0x80 {
+		return byte(base), base >= 0x20
+	}
+	return charmap.Windows1250.EncodeRune(base)
+}
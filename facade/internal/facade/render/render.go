@@ -0,0 +1,92 @@
+// Package render turns invoices into documents for download: HTML to view in the
+// browser and a plain, single-font PDF to print or archive.
+package render
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+)
+
+//go:embed invoice.html
+var invoiceTemplate string
+
+var funcs = template.FuncMap{
+	"amount": amount,
+	"date":   func(t time.Time) string { return t.UTC().Format(dateLayout) },
+	"stamp":  func(t time.Time) string { return t.UTC().Format(stampLayout) },
+	"lines":  func(s string) []string { return strings.Split(s, "\n") },
+}
+
+var invoicePage = template.Must(template.New("invoice").Funcs(funcs).Parse(invoiceTemplate))
+
+const (
+	dateLayout  = "2006-01-02"
+	stampLayout = "2006-01-02 15:04"
+)
+
+// InvoiceHTML renders the invoice as a standalone HTML page
+func InvoiceHTML(invoice reservation.Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := invoicePage.Execute(&buf, invoice); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// InvoicePDF renders the invoice as an A4 PDF, lines that don't fit continue on the next page
+func InvoicePDF(invoice reservation.Invoice) ([]byte, error) {
+	doc := newDocument()
+
+	title := "Invoice " + invoice.Number
+	switch invoice.Kind {
+	case "monthly":
+		title = "Monthly invoice " + invoice.Number
+	case "credit_note":
+		title = "Credit note " + invoice.Number
+	}
+	doc.text(0, 18, true, title)
+	doc.text(0, 10, false, "Issued on "+invoice.IssuedAt.UTC().Format(dateLayout))
+	if invoice.Period != "" {
+		doc.text(0, 10, false, "Period "+invoice.Period)
+	}
+	if invoice.CreditedNumber != "" {
+		doc.text(0, 10, false, "Corrects invoice "+invoice.CreditedNumber)
+	}
+	doc.space(12)
+
+	doc.text(0, 10, true, "Seller")
+	doc.party(invoice.Seller)
+	doc.space(8)
+	doc.text(0, 10, true, "Buyer")
+	doc.party(invoice.Buyer)
+	doc.space(12)
+
+	doc.row(true, "Description", "Time", "Net", "Tax", "Total")
+	for _, line := range invoice.Lines {
+		doc.row(false,
+			line.Description+", spot "+line.Spot.SpotID,
+			line.StartTime.UTC().Format(stampLayout)+" - "+line.EndTime.UTC().Format(stampLayout),
+			amount(line.Net), amount(line.Tax), amount(line.Total),
+		)
+	}
+	doc.space(12)
+
+	for _, tax := range invoice.Taxes {
+		doc.text(0, 10, false, fmt.Sprintf("%s %g%%: net %s, tax %s", tax.Name, tax.Rate, amount(tax.Net), amount(tax.Tax)))
+	}
+	doc.text(0, 10, false, "Net "+amount(invoice.Net)+" "+invoice.Currency)
+	doc.text(0, 10, false, "Tax "+amount(invoice.Tax)+" "+invoice.Currency)
+	doc.text(0, 12, true, "Total "+amount(invoice.Total)+" "+invoice.Currency)
+
+	return doc.bytes(), nil
+}
+
+func amount(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,132 @@
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+)
+
+func testInvoice(lines int) reservation.Invoice {
+	start := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+	invoice := reservation.Invoice{
+		Number:   "RP-000042",
+		Kind:     "single",
+		Seller:   reservation.Party{Name: "Reserve Park", TaxID: "PL1234567890", Address: "1 Main St\nWarsaw"},
+		Buyer:    reservation.Party{Name: "Smith & Sons (Ltd)", Email: "jane@example.com"},
+		Currency: "EUR",
+		Taxes:    []reservation.TaxLine{{Name: "VAT", Rate: 23, Net: 8.13, Tax: 1.87}},
+		Net:      8.13,
+		Tax:      1.87,
+		Total:    10,
+		IssuedAt: start,
+	}
+	for i := 0; i < lines; i++ {
+		invoice.Lines = append(invoice.Lines, reservation.InvoiceLine{
+			ReservationID: "r" + strconv.Itoa(i),
+			Description:   "Parking",
+			Spot:          reservation.SpotDetails{SpotID: "s1", LotID: "lot-a"},
+			StartTime:     start,
+			EndTime:       start.Add(time.Hour),
+			Net:           8.13,
+			Tax:           1.87,
+			Total:         10,
+		})
+	}
+	return invoice
+}
+
+func TestInvoiceHTML(t *testing.T) {
+	page, err := InvoiceHTML(testInvoice(1))
+	if err != nil {
+		t.Fatalf("InvoiceHTML: %v", err)
+	}
+
+	for _, want := range []string{"Invoice RP-000042", "Smith &amp; Sons (Ltd)", "<p>Warsaw</p>", "s1, lot lot-a", "2025-03-03 08:00", "VAT 23%", "10.00 EUR"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("page doesn't contain %q", want)
+		}
+	}
+}
+
+func TestCreditNoteHTML(t *testing.T) {
+	creditNote := testInvoice(1)
+	creditNote.Kind = "credit_note"
+	creditNote.Number = "RP-000043"
+	creditNote.CreditedNumber = "RP-000042"
+
+	page, err := InvoiceHTML(creditNote)
+	if err != nil {
+		t.Fatalf("InvoiceHTML: %v", err)
+	}
+
+	for _, want := range []string{"Credit note RP-000043", "corrects invoice RP-000042"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("page doesn't contain %q", want)
+		}
+	}
+}
+
+func TestInvoicePDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines int
+		pages int
+	}{
+		{"single page", 1, 1},
+		{"continued", 80, 2},
+	}
+
+	for _, tt := range tests {
+		doc, err := InvoicePDF(testInvoice(tt.lines))
+		if err != nil {
+			t.Fatalf("%v: InvoicePDF: %v", tt.name, err)
+		}
+
+		if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+			t.Errorf("%v: not a PDF", tt.name)
+		}
+		if !bytes.Contains(doc, []byte(`(Smith & Sons \(Ltd\)) Tj`)) || !bytes.Contains(doc, []byte("(Total 10.00 EUR) Tj")) {
+			t.Errorf("%v: text missing or not escaped", tt.name)
+		}
+		if got := bytes.Count(doc, []byte("/Type /Page ")); got != tt.pages {
+			t.Errorf("%v: got %d pages, want %d", tt.name, got, tt.pages)
+		}
+
+		// startxref points at the cross-reference table
+		tail := doc[bytes.LastIndex(doc, []byte("startxref\n"))+len("startxref\n"):]
+		offset, err := strconv.Atoi(string(tail[:bytes.IndexByte(tail, '\n')]))
+		if err != nil || !bytes.HasPrefix(doc[offset:], []byte("xref\n")) {
+			t.Errorf("%v: startxref %d doesn't point at the xref table", tt.name, offset)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape(`a\b(c)` + "é€\n"); got != `a\\b\(c\)\351\200 ` {
+		t.Errorf("escape = %q", got)
+	}
+	// Windows-1250 letters, then one without its accent and one that has no fallback
+	if got := escape("Łukasz Żółć å中"); got != `\243ukasz \257\363\263\346 \141?` {
+		t.Errorf("escape = %q", got)
+	}
+}
+
+func TestInvoicePDFPolishBuyer(t *testing.T) {
+	invoice := testInvoice(1)
+	invoice.Buyer.Name = "Łukasz Żółć"
+	invoice.Buyer.Address = "ul. Świętokrzyska 1\nGdańsk"
+
+	doc, err := InvoicePDF(invoice)
+	if err != nil {
+		t.Fatalf("InvoicePDF: %v", err)
+	}
+	for _, want := range []string{`(\243ukasz \257\363\263\346) Tj`, `(ul. \214wi\352tokrzyska 1) Tj`, `(Gda\361sk) Tj`, "/Differences ["} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("PDF doesn't contain %q", want)
+		}
+	}
+}
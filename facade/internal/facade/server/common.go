@@ -1,25 +1,125 @@
 package server
 
 import (
-	"io"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/grpcclient"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services"
 )
 
 // Helper function to handle errors
-func (s *Server) handleError(w http.ResponseWriter, message string, err error, statusCode int) {
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request, code problem.Code, message string, err error) {
+	s.writeProblem(w, r, problem.New(code, message), err)
+}
+
+// Helper function to report validator errors field by field
+func (s *Server) handleValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	p := problem.New(problem.CodeValidationFailed, "The request contains invalid fields")
+	p.Errors = problem.FieldErrors(err)
+	if p.Errors == nil {
+		p.Detail = err.Error()
+	}
+	s.writeProblem(w, r, p, err)
+}
+
+// Helper function to log and write problem details
+func (s *Server) writeProblem(w http.ResponseWriter, r *http.Request, p problem.Problem, err error) {
+	level := slog.LevelError
+	if p.Status < http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+
+	args := []any{"status", p.Status, "code", p.Code}
 	if err != nil {
-		s.Logger.Error.Printf("%s: %v", message, err)
-	} else {
-		s.Logger.Error.Println(message)
+		args = append(args, "error", err)
 	}
-	http.Error(w, message, statusCode)
+	s.Logger.Log(r.Context(), level, p.Detail, args...)
+
+	p.Instance = r.URL.Path
+	p.RequestID = logger.RequestID(r.Context())
+	problem.Write(w, p)
 }
 
-// Helper function to forward the response
-func (s *Server) forwardResponse(w http.ResponseWriter, resp *http.Response) {
-	defer resp.Body.Close()
+// Helper function to map a failed downstream call to problem details.
+// Problems produced by the services keep their code and field errors,
+// transport failures become gateway errors.
+func (s *Server) handleDownstreamError(w http.ResponseWriter, r *http.Request, service string, err error) {
+	if pp, ok := grpcclient.Problem(err); ok {
+		p := problem.New(problem.Code(pp.GetCode()), pp.GetDetail())
+		for _, fe := range pp.GetErrors() {
+			p.Errors = append(p.Errors, problem.FieldError{
+				Field:   fe.GetField(),
+				Rule:    fe.GetRule(),
+				Message: fe.GetMessage(),
+			})
+		}
+		s.writeProblem(w, r, p, err)
+		return
+	}
+
+	name := strings.ToUpper(service[:1]) + service[1:]
+
+	switch {
+	case errors.Is(err, grpcclient.ErrCircuitOpen):
+		s.handleError(w, r, problem.CodeDownstreamUnavailable, name+" service is temporarily unavailable", err)
+	case grpcclient.IsTimeout(err):
+		s.handleError(w, r, problem.CodeDownstreamTimeout, name+" service did not respond in time", err)
+	default:
+		s.handleError(w, r, problem.CodeDownstreamError, "Failed to send request to "+service+" service", err)
+	}
+}
+
+// Helper function to check whether a failed downstream call carries the given problem code
+func hasProblemCode(err error, code problem.Code) bool {
+	pp, ok := grpcclient.Problem(err)
+	return ok && problem.Code(pp.GetCode()) == code
+}
+
+// Helper function to write JSON responses
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, data interface{}, statusCode int) {
+	j, err := json.Marshal(data)
+	if err != nil {
+		s.handleError(w, r, problem.CodeInternal, "Failed to encode response to JSON", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(j)
+}
+
+// Helper function to write the ID of a created resource
+func (s *Server) writeID(w http.ResponseWriter, id string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(id))
+}
+
+// Helper function to read pagination and sorting options from query parameters,
+// the services check the limit's upper bound, the sort field and the order
+func parseListOptions(query url.Values) (services.ListOptions, error) {
+	opts := services.ListOptions{
+		Cursor:       query.Get("cursor"),
+		Sort:         query.Get("sort"),
+		Order:        query.Get("order"),
+		IncludeTotal: query.Get("include_total") == "true",
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit <= 0 {
+			return opts, errors.New("limit must be a positive number")
+		}
+		opts.Limit = limit
+	}
 
-	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
-	w.WriteHeader(resp.StatusCode)
-	io.Copy(w, resp.Body)
+	return opts, nil
 }
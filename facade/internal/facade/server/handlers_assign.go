@@ -0,0 +1,202 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
+)
+
+type StrategyType string
+
+const (
+	StrategyCheapest           StrategyType = "cheapest"
+	StrategyClosest            StrategyType = "closest"
+	StrategyLeastFragmentation StrategyType = "least_fragmentation"
+)
+
+type coordinates struct {
+	Latitude  float64 `json:"latitude" validate:"latitude"`
+	Longitude float64 `json:"longitude" validate:"longitude"`
+}
+
+type autoReserveInput struct {
+	UserID    string       `json:"user_id" validate:"required"`
+	StartTime time.Time    `json:"start_time" validate:"required"`
+	EndTime   time.Time    `json:"end_time" validate:"required,gtfield=StartTime"`
+	LotID     string       `json:"lot_id"`
+	Size      string       `json:"size" validate:"omitempty,oneof=small medium large"`
+	Type      string       `json:"type" validate:"omitempty,oneof=indoor outdoor ev"`
+	MaxPrice  float64      `json:"max_price" validate:"omitempty,gt=0"`
+	Near      *coordinates `json:"near"`
+	VehicleID string       `json:"vehicle_id"`
+	Strategy  StrategyType `json:"strategy" validate:"omitempty,oneof=cheapest closest least_fragmentation"`
+
+	// PaymentMethod is required while the reservation service takes payments
+	PaymentMethod string `json:"payment_method"`
+}
+
+type spotCandidate struct {
+	spot.Spot
+
+	price    float64
+	distance float64
+}
+
+func (s *Server) autoReserve(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Auto-assigning reservation")
+	var input autoReserveInput
+
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	if err := s.Validator.Struct(input); err != nil {
+		s.handleValidationError(w, r, err)
+		return
+	}
+
+	if input.Strategy == "" {
+		input.Strategy = StrategyType(s.Config.AssignStrategy)
+	}
+	if input.Strategy == StrategyClosest && input.Near == nil {
+		s.handleError(w, r, problem.CodeBadRequest, "The closest strategy requires near coordinates", nil)
+		return
+	}
+
+	// Perform authorization check
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return
+	}
+
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != input.UserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	hours := input.EndTime.Sub(input.StartTime).Hours()
+	searchInput := spot.SearchInput{
+		LotID:     input.LotID,
+		Size:      input.Size,
+		Type:      input.Type,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+	}
+	if input.MaxPrice > 0 {
+		searchInput.MaxPricePerHour = input.MaxPrice / hours
+	}
+
+	var licensePlate string
+	if input.VehicleID != "" {
+		vehicle, ok := s.fetchVehicle(w, r, input.UserID, input.VehicleID)
+		if !ok {
+			return
+		}
+		licensePlate = vehicle.LicensePlate
+		searchInput.Vehicle = &spot.VehicleProfile{
+			Size:     vehicle.Size,
+			IsEV:     vehicle.IsEV,
+			HeightCm: vehicle.HeightCm,
+		}
+	}
+
+	found, err := s.SpotService.SearchSpots(r.Context(), searchInput)
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	// Candidates are booked at the price the spot service quoted, drop the ones over budget
+	var affordable []spotCandidate
+	for _, spt := range found.Spots {
+		// Without a vehicle nothing tells whether it may use the spot
+		if input.VehicleID == "" && needsVehicle(spt) {
+			continue
+		}
+
+		price, ok := found.Prices[spt.SpotID]
+		if !ok {
+			continue
+		}
+
+		candidate := spotCandidate{Spot: spt, price: price}
+		if input.MaxPrice > 0 && candidate.price > input.MaxPrice {
+			continue
+		}
+		if input.Near != nil {
+			candidate.distance = haversineKm(input.Near.Latitude, input.Near.Longitude, candidate.Latitude, candidate.Longitude)
+		}
+		affordable = append(affordable, candidate)
+	}
+
+	if len(affordable) == 0 {
+		s.handleError(w, r, problem.CodeNoMatchingSpot, "No spot matches the provided criteria", nil)
+		return
+	}
+
+	rankCandidates(affordable, input.Strategy)
+
+	assignInput := reservation.AssignInput{
+		UserID:       input.UserID,
+		StartTime:    input.StartTime,
+		EndTime:      input.EndTime,
+		Candidates:   make([]reservation.AssignCandidate, 0, len(affordable)),
+		Strategy:     string(input.Strategy),
+		VehicleID:    input.VehicleID,
+		LicensePlate: licensePlate,
+
+		PaymentMethod: input.PaymentMethod,
+	}
+	for _, candidate := range affordable {
+		assignInput.Candidates = append(assignInput.Candidates, reservation.AssignCandidate{
+			SpotID:    candidate.SpotID,
+			PricePaid: candidate.price,
+		})
+	}
+
+	// The reservation service books the first candidate that is still free
+	rsrv, err := s.ReservationService.Assign(r.Context(), assignInput)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, rsrv, http.StatusCreated)
+}
+
+// rankCandidates orders candidates by the strategy. Least fragmentation is decided
+// by the reservation service, so here it only gets the cheapest-first tie-break order.
+func rankCandidates(candidates []spotCandidate, strategy StrategyType) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if strategy == StrategyClosest && a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.price != b.price {
+			return a.price < b.price
+		}
+		return a.distance < b.distance
+	})
+}
+
+// haversineKm returns the great-circle distance between two points in kilometers
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
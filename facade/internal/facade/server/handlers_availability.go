@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
+)
+
+const (
+	// Interval of the comments that keep idle streams from being cut off by proxies
+	streamKeepalive = 15 * time.Second
+
+	// Reconnection delay suggested to the clients, in milliseconds
+	streamRetry = 5000
+
+	// Most spots a single stream may cover, the reservation service refuses more
+	maxStreamedSpots = 1000
+)
+
+// streamAvailability sends the availability changes of the requested spots as
+// Server-Sent Events until the client leaves or the stream ends. A client that
+// reconnects should fetch the current availability again, changes made while it
+// was away are not replayed.
+func (s *Server) streamAvailability(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Streaming availability changes")
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	spotIDs := parseSpotIDs(query.Get("spot_ids"))
+	if lotID := query.Get("lot_id"); lotID != "" {
+		lotSpotIDs, err := s.lotSpotIDs(ctx, lotID)
+		if err != nil {
+			s.handleDownstreamError(w, r, "spot", err)
+			return
+		}
+		if len(lotSpotIDs) == 0 {
+			s.handleError(w, r, problem.CodeNoMatchingSpot, "No spot belongs to lot "+lotID, nil)
+			return
+		}
+		spotIDs = append(spotIDs, lotSpotIDs...)
+	}
+
+	if len(spotIDs) == 0 {
+		s.handleError(w, r, problem.CodeBadRequest, "spot_ids or lot_id is required", nil)
+		return
+	}
+	if len(spotIDs) > maxStreamedSpots {
+		s.handleError(w, r, problem.CodeBadRequest, "At most 1000 spots can be streamed at once", nil)
+		return
+	}
+
+	watch, err := s.ReservationService.WatchAvailability(ctx, spotIDs)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	// The stream outlives the write timeout meant for regular responses
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		s.Logger.WarnContext(ctx, "Failed to lift the write deadline of the stream", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	rc.Flush()
+
+	// Recv blocks, so it gets a goroutine of its own. It returns once the request
+	// context is canceled, which happens when the handler returns.
+	changes := make(chan reservation.AvailabilityChange)
+	ended := make(chan error, 1)
+	go func() {
+		for {
+			change, err := watch.Recv()
+			if err != nil {
+				ended <- err
+				return
+			}
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.InfoContext(ctx, "Availability stream closed by the client")
+			return
+		case <-s.closing:
+			s.writeStreamEnd(w, r, problem.New(problem.CodeDownstreamUnavailable, "The server is shutting down, reconnect to continue"), nil)
+			return
+		case err := <-ended:
+			s.writeStreamEnd(w, r, problem.New(problem.CodeDownstreamUnavailable, "The availability stream ended, reconnect to continue"), err)
+			return
+		case change := <-changes:
+			data, err := json.Marshal(change)
+			if err != nil {
+				s.Logger.ErrorContext(ctx, "Failed to encode availability change", "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: availability\ndata: %s\n\n", data)
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			s.Logger.InfoContext(ctx, "Availability stream closed", "error", err)
+			return
+		}
+	}
+}
+
+// Helper function to end a stream with an error event, its status can't change anymore
+func (s *Server) writeStreamEnd(w http.ResponseWriter, r *http.Request, p problem.Problem, err error) {
+	args := []any{"code", p.Code}
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	s.Logger.WarnContext(r.Context(), p.Detail, args...)
+
+	p.Instance = r.URL.Path
+	p.RequestID = logger.RequestID(r.Context())
+	data, _ := json.Marshal(p)
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+	http.NewResponseController(w).Flush()
+}
+
+// Helper function to list the IDs of the spots of a lot, page by page
+func (s *Server) lotSpotIDs(ctx context.Context, lotID string) ([]string, error) {
+	var spotIDs []string
+	opts := services.ListOptions{Limit: 500}
+
+	for {
+		page, err := s.SpotService.GetAll(ctx, spot.Filter{LotID: lotID}, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, sp := range page.Items {
+			spotIDs = append(spotIDs, sp.SpotID)
+		}
+
+		// Past the limit the request is refused anyway
+		if page.NextCursor == "" || len(spotIDs) > maxStreamedSpots {
+			return spotIDs, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/problempb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/userpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeUsers struct {
+	userpb.UnimplementedUserServiceServer
+}
+
+func (fakeUsers) Authorize(ctx context.Context, req *userpb.AuthorizeRequest) (*userpb.AuthorizeResponse, error) {
+	return &userpb.AuthorizeResponse{UserId: "u1", Role: "user"}, nil
+}
+
+// fakeReservations sends one change of every watched spot, then ends the stream
+type fakeReservations struct {
+	reservationpb.UnimplementedReservationServiceServer
+}
+
+func (fakeReservations) WatchAvailability(req *reservationpb.WatchAvailabilityRequest, stream grpc.ServerStreamingServer[reservationpb.AvailabilityChange]) error {
+	if req.GetSpotIds()[0] == "unknown" {
+		st, _ := status.New(codes.InvalidArgument, "Unknown spot").WithDetails(&problempb.Problem{Code: string(problem.CodeBadRequest), Detail: "Unknown spot"})
+		return st.Err()
+	}
+
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	start := time.Date(2025, 5, 22, 10, 0, 0, 0, time.UTC)
+	for _, spotID := range req.GetSpotIds() {
+		stream.Send(&reservationpb.AvailabilityChange{
+			SpotId:        spotID,
+			ReservationId: "r1",
+			Change:        "booked",
+			StartTime:     timestamppb.New(start),
+			EndTime:       timestamppb.New(start.Add(time.Hour)),
+			EventType:     "ReservationCreated",
+		})
+	}
+	return status.Error(codes.Unavailable, "shutting down")
+}
+
+func newStreamingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	grpcSrv := grpc.NewServer()
+	userpb.RegisterUserServiceServer(grpcSrv, fakeUsers{})
+	reservationpb.RegisterReservationServiceServer(grpcSrv, fakeReservations{})
+	go grpcSrv.Serve(lis)
+	t.Cleanup(grpcSrv.Stop)
+
+	cfg := config.GetConfig()
+	cfg.UserAddr = lis.Addr().String()
+	cfg.ReservationAddr = lis.Addr().String()
+
+	usr, err := user.NewUserService(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create user service: %v", err)
+	}
+	t.Cleanup(func() { usr.Close() })
+	rsrv, err := reservation.NewReservationService(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create reservation service: %v", err)
+	}
+	t.Cleanup(func() { rsrv.Close() })
+
+	srv := httptest.NewServer(NewServer(logger.GetLogger(), cfg, usr, nil, rsrv).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getStream(t *testing.T, srv *httptest.Server, query string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", srv.URL+"/spots/availability/stream?"+query, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer token")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestStreamAvailability(t *testing.T) {
+	srv := newStreamingServer(t)
+	resp := getStream(t, srv, "spot_ids=s1,s2")
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("got Content-Type %q, want text/event-stream", ct)
+	}
+
+	// Events are separated by blank lines, the stream ends after the error event
+	var events []string
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			events = append(events, event)
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+
+	if strings.Join(events, ",") != "availability,availability,error" {
+		t.Fatalf("got events %v, want two availability events and an error", events)
+	}
+
+	var change reservation.AvailabilityChange
+	if err := json.Unmarshal([]byte(data[1]), &change); err != nil {
+		t.Fatalf("Failed to decode change: %v", err)
+	}
+	if change.SpotID != "s2" || change.Change != "booked" || change.EndTime.Sub(change.StartTime) != time.Hour {
+		t.Errorf("got change %+v", change)
+	}
+
+	var p problem.Problem
+	if err := json.Unmarshal([]byte(data[2]), &p); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if p.Code != problem.CodeDownstreamUnavailable {
+		t.Errorf("got problem code %s, want %s", p.Code, problem.CodeDownstreamUnavailable)
+	}
+}
+
+func TestStreamAvailabilityRejected(t *testing.T) {
+	srv := newStreamingServer(t)
+
+	// Refused before the stream starts, the status is still a regular problem
+	resp := getStream(t, srv, "spot_ids=unknown")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	resp = getStream(t, srv, "")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("got status %d without spots, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
+	"github.com/gorilla/mux"
+)
+
+func (s *Server) getCancellationQuote(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Quoting reservation cancellation")
+
+	rsrv, ok := s.fetchCancelableReservation(w, r, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	policy, ok := s.resolveCancellationPolicy(w, r, rsrv.SpotID)
+	if !ok {
+		return
+	}
+
+	quote, err := s.ReservationService.QuoteCancellation(r.Context(), rsrv.ReservationID, policy)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, quote, http.StatusOK)
+}
+
+// Helper function to get a reservation the caller may cancel, it must be their own unless they
+// are an admin and it must not be canceled yet. Writes the error response on failure.
+func (s *Server) fetchCancelableReservation(w http.ResponseWriter, r *http.Request, reservationID string) (reservation.Reservation, bool) {
+	rsrv, err := s.ReservationService.Get(r.Context(), reservationID)
+	if err != nil {
+		if hasProblemCode(err, problem.CodeReservationNotFound) {
+			s.handleError(w, r, problem.CodeReservationNotFound, "Reservation does not exist", err)
+			return rsrv, false
+		}
+		s.handleDownstreamError(w, r, "reservation", err)
+		return rsrv, false
+	}
+
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return rsrv, false
+	}
+
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != rsrv.UserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+		return rsrv, false
+	}
+
+	if rsrv.Status == "canceled" {
+		s.handleError(w, r, problem.CodeReservationAlreadyCanceled, "Reservation already canceled", nil)
+		return rsrv, false
+	}
+
+	return rsrv, true
+}
+
+// Helper function to get the cancellation policy of the spot from the spot service. The
+// reservation outlives a deleted spot, it's then canceled under the default policy.
+// Writes the error response on failure.
+func (s *Server) resolveCancellationPolicy(w http.ResponseWriter, r *http.Request, spotID string) (reservation.CancellationPolicy, bool) {
+	policy, err := s.SpotService.GetCancellationPolicy(r.Context(), spotID)
+	if err != nil {
+		if hasProblemCode(err, problem.CodeSpotNotFound) {
+			return reservation.CancellationPolicy{Scope: "default", TargetID: spotID}, true
+		}
+		s.handleDownstreamError(w, r, "spot", err)
+		return reservation.CancellationPolicy{}, false
+	}
+
+	return reservation.CancellationPolicy{
+		Scope:                policy.Scope,
+		TargetID:             policy.TargetID,
+		FreeUntilHours:       policy.FreeUntilHours,
+		LateFeePercent:       policy.LateFeePercent,
+		NonRefundableMinutes: policy.NonRefundableMinutes,
+	}, true
+}
+
+// The spot service validates the policy
+type cancellationPolicyInput struct {
+	Scope                string  `json:"scope"`
+	TargetID             string  `json:"target_id"`
+	FreeUntilHours       int     `json:"free_until_hours"`
+	LateFeePercent       float64 `json:"late_fee_percent"`
+	NonRefundableMinutes int     `json:"non_refundable_minutes"`
+}
+
+func (s *Server) setCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Setting cancellation policy")
+	var input cancellationPolicyInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	policy, err := s.SpotService.SetCancellationPolicy(r.Context(), spot.CancellationPolicy{
+		Scope:                input.Scope,
+		TargetID:             input.TargetID,
+		FreeUntilHours:       input.FreeUntilHours,
+		LateFeePercent:       input.LateFeePercent,
+		NonRefundableMinutes: input.NonRefundableMinutes,
+	})
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	s.writeJSON(w, r, policy, http.StatusOK)
+}
+
+func (s *Server) getCancellationPolicies(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting cancellation policies")
+
+	policies, err := s.SpotService.GetCancellationPolicies(r.Context())
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	s.writeJSON(w, r, policies, http.StatusOK)
+}
+
+func (s *Server) deleteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting cancellation policy")
+	vars := mux.Vars(r)
+
+	if err := s.SpotService.DeleteCancellationPolicy(r.Context(), vars["scope"], vars["id"]); err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getSpotCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting spot's cancellation policy")
+
+	policy, err := s.SpotService.GetCancellationPolicy(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	s.writeJSON(w, r, policy, http.StatusOK)
+}
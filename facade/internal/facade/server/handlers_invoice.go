@@ -0,0 +1,299 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/render"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/user"
+	"github.com/gorilla/mux"
+)
+
+// getReservationInvoice returns the invoice of the reservation, it is issued by issueReservationInvoice
+func (s *Server) getReservationInvoice(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting reservation invoice")
+
+	rsrv, err := s.ReservationService.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	if !s.ownsOrAdmin(w, r, rsrv.UserID) {
+		return
+	}
+
+	invoice, err := s.ReservationService.GetReservationInvoice(r.Context(), rsrv.ReservationID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeInvoice(w, r, invoice, http.StatusOK)
+}
+
+// issueReservationInvoice invoices what the payment of the reservation captured, issuing it
+// again returns the invoice issued first. Business accounts are invoiced monthly, their
+// reservations get no invoice of their own.
+func (s *Server) issueReservationInvoice(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Issuing reservation invoice")
+
+	rsrv, err := s.ReservationService.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	if !s.ownsOrAdmin(w, r, rsrv.UserID) {
+		return
+	}
+
+	usr, err := s.UserService.GetUser(r.Context(), rsrv.UserID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "user", err)
+		return
+	}
+	if usr.Billing != nil && usr.Billing.Business {
+		s.handleError(w, r, problem.CodeInvoiceNotFound, "Business accounts are invoiced monthly", nil)
+		return
+	}
+
+	spotDetails, err := s.spotDetails(r.Context(), rsrv.SpotID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	invoice, err := s.ReservationService.IssueInvoice(r.Context(), rsrv.ReservationID, buyer(usr), spotDetails)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeInvoice(w, r, invoice, http.StatusCreated)
+}
+
+func (s *Server) getInvoices(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting invoices")
+	query := r.URL.Query()
+
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return
+	}
+
+	// Users list their own invoices, admins anyone's
+	userID := query.Get("user_id")
+	if RoleType(authResp.Role) != RoleAdmin {
+		if userID != "" && userID != authResp.UserID {
+			s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+			return
+		}
+		userID = authResp.UserID
+	}
+
+	var limit int
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			err = errors.New("limit must be a positive number")
+			s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	invoices, err := s.ReservationService.ListInvoices(r.Context(), userID, query.Get("period"), limit)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, invoices, http.StatusOK)
+}
+
+func (s *Server) getInvoiceByID(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting invoice")
+
+	invoice, err := s.ReservationService.GetInvoice(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	if !s.ownsOrAdmin(w, r, invoice.UserID) {
+		return
+	}
+
+	s.writeInvoice(w, r, invoice, http.StatusOK)
+}
+
+type monthlyInvoiceInput struct {
+	UserID string `json:"user_id" validate:"required"`
+	Period string `json:"period" validate:"required,datetime=2006-01"`
+}
+
+func (s *Server) issueMonthlyInvoice(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Issuing monthly invoice")
+	var input monthlyInvoiceInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	if err := s.Validator.Struct(input); err != nil {
+		s.handleValidationError(w, r, err)
+		return
+	}
+
+	usr, err := s.UserService.GetUser(r.Context(), input.UserID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "user", err)
+		return
+	}
+	if usr.Billing == nil || !usr.Billing.Business {
+		s.handleError(w, r, problem.CodeBadRequest, "Only business accounts are invoiced monthly", nil)
+		return
+	}
+
+	spots, err := s.monthSpotDetails(r.Context(), input.UserID, input.Period)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	invoice, err := s.ReservationService.IssueMonthlyInvoice(r.Context(), input.UserID, input.Period, buyer(usr), spots)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, invoice, http.StatusCreated)
+}
+
+// Helper function to check that the resource of the user is the caller's own or the caller is
+// an admin. Writes the error response on failure.
+func (s *Server) ownsOrAdmin(w http.ResponseWriter, r *http.Request, userID string) bool {
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return false
+	}
+
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != userID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+		return false
+	}
+
+	return true
+}
+
+// Helper function to write the invoice in the format of the format query parameter,
+// JSON unless html or pdf is asked for
+func (s *Server) writeInvoice(w http.ResponseWriter, r *http.Request, invoice reservation.Invoice, statusCode int) {
+	var (
+		doc         []byte
+		contentType string
+		err         error
+	)
+
+	switch r.URL.Query().Get("format") {
+	case "html":
+		doc, err = render.InvoiceHTML(invoice)
+		contentType = "text/html; charset=utf-8"
+	case "pdf":
+		doc, err = render.InvoicePDF(invoice)
+		contentType = "application/pdf"
+		w.Header().Set("Content-Disposition", `inline; filename="`+invoice.Number+`.pdf"`)
+	default:
+		s.writeJSON(w, r, invoice, statusCode)
+		return
+	}
+	if err != nil {
+		s.handleError(w, r, problem.CodeInternal, "Failed to render invoice", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	w.Write(doc)
+}
+
+// buyer takes the billing details of the profile, the username stands in for a missing name
+func buyer(usr user.User) reservation.Party {
+	party := reservation.Party{Name: usr.Username, Email: usr.Email}
+	if usr.Billing != nil {
+		if usr.Billing.Name != "" {
+			party.Name = usr.Billing.Name
+		}
+		party.TaxID = usr.Billing.TaxID
+		party.Address = usr.Billing.Address
+	}
+	return party
+}
+
+// Helper function to get the details of the spot for an invoice, a deleted spot is only known by its ID
+func (s *Server) spotDetails(ctx context.Context, spotID string) (reservation.SpotDetails, error) {
+	sp, err := s.SpotService.GetSpot(ctx, spotID)
+	if err != nil {
+		if hasProblemCode(err, problem.CodeSpotNotFound) {
+			return reservation.SpotDetails{SpotID: spotID}, nil
+		}
+		return reservation.SpotDetails{}, err
+	}
+
+	return reservation.SpotDetails{
+		SpotID:    sp.SpotID,
+		LotID:     sp.LotID,
+		Size:      sp.Size,
+		Type:      sp.Type,
+		Latitude:  sp.Latitude,
+		Longitude: sp.Longitude,
+	}, nil
+}
+
+// Helper function to get the details of the spots the user reserved in the month
+func (s *Server) monthSpotDetails(ctx context.Context, userID, period string) ([]reservation.SpotDetails, error) {
+	from, err := time.Parse("2006-01", period)
+	if err != nil {
+		return nil, err
+	}
+	to := from.AddDate(0, 1, 0)
+
+	seen := make(map[string]bool)
+	var spots []reservation.SpotDetails
+	opts := services.ListOptions{Limit: 500}
+
+	for {
+		page, err := s.ReservationService.GetAll(ctx, reservation.Filter{UserID: userID, From: &from, To: &to}, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, rsrv := range page.Items {
+			if seen[rsrv.SpotID] {
+				continue
+			}
+			seen[rsrv.SpotID] = true
+
+			details, err := s.spotDetails(ctx, rsrv.SpotID)
+			if err != nil {
+				return nil, err
+			}
+			spots = append(spots, details)
+		}
+
+		if page.NextCursor == "" {
+			return spots, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
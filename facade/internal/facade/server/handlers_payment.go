@@ -0,0 +1,62 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/gorilla/mux"
+)
+
+// Most bytes of a provider callback passed on to the reservation service
+const maxCallbackSize = 1 << 20
+
+func (s *Server) getReservationPayment(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting reservation payment")
+
+	vars := mux.Vars(r)
+	requestedReservationID := vars["id"]
+
+	// The reservation tells whose payment it is
+	rsrv, err := s.ReservationService.Get(r.Context(), requestedReservationID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return
+	}
+
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != rsrv.UserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	payment, err := s.ReservationService.GetPayment(r.Context(), requestedReservationID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, payment, http.StatusOK)
+}
+
+func (s *Server) paymentCallback(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Passing on payment provider callback")
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackSize))
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to read request body", err)
+		return
+	}
+
+	if err := s.ReservationService.HandlePaymentCallback(r.Context(), r.Header, body); err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/gorilla/mux"
+)
+
+// The reservation service hosts the promo codes and validates the input, the redemptions
+// and the creation time of the body are ignored
+func (s *Server) addPromoCode(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Adding promo code")
+	var input reservation.PromoCode
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	promo, err := s.ReservationService.AddPromoCode(r.Context(), input)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, promo, http.StatusCreated)
+}
+
+func (s *Server) getPromoCodes(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting promo codes")
+
+	codes, err := s.ReservationService.GetPromoCodes(r.Context())
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, codes, http.StatusOK)
+}
+
+func (s *Server) getPromoCodeByCode(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting promo code")
+
+	promo, err := s.ReservationService.GetPromoCode(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, promo, http.StatusOK)
+}
+
+func (s *Server) deletePromoCode(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting promo code")
+
+	if err := s.ReservationService.DeletePromoCode(r.Context(), mux.Vars(r)["code"]); err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/quote"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+)
+
+type quoteInput struct {
+	UserID    string    `json:"user_id" validate:"required"`
+	SpotID    string    `json:"spot_id" validate:"required"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+	PromoCode string    `json:"promo_code"`
+}
+
+// Kinds of the items of a quote
+const (
+	itemParking  = "parking"
+	itemDiscount = "discount"
+)
+
+// quoteItem is a line of the itemized price, discounts have a negative amount
+type quoteItem struct {
+	Kind        string  `json:"kind"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+type quoteResponse struct {
+	quote.Quote
+
+	Items      []quoteItem `json:"items"`
+	QuoteToken string      `json:"quote_token"`
+}
+
+// createQuote prices booking the spot and signs the price into a token that
+// addReservation honors until the quote expires
+func (s *Server) createQuote(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Creating price quote")
+	var input quoteInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	if err := s.Validator.Struct(input); err != nil {
+		s.handleValidationError(w, r, err)
+		return
+	}
+
+	if !s.ownsOrAdmin(w, r, input.UserID) {
+		return
+	}
+
+	spt, ok := s.getExistingSpot(w, r, input.SpotID)
+	if !ok {
+		return
+	}
+
+	price, err := s.SpotService.GetSpotPrice(r.Context(), input.SpotID, input.StartTime, input.EndTime)
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	q := quote.Quote{
+		SpotID:    input.SpotID,
+		UserID:    input.UserID,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+		ListPrice: price,
+		Price:     price,
+	}
+	items := []quoteItem{{
+		Kind:        itemParking,
+		Description: fmt.Sprintf("Parking on spot %s for %s at %.2f per hour", spt.SpotID, formatDuration(input.EndTime.Sub(input.StartTime)), spt.PricePerHour),
+		Amount:      price,
+	}}
+
+	if input.PromoCode != "" {
+		promo, err := s.ReservationService.QuotePromoCode(r.Context(), reservation.PromoQuoteInput{
+			Code:      input.PromoCode,
+			UserID:    input.UserID,
+			LotID:     spt.LotID,
+			SpotType:  spt.Type,
+			StartTime: input.StartTime,
+			EndTime:   input.EndTime,
+			Price:     price,
+		})
+		if err != nil {
+			s.handleDownstreamError(w, r, "reservation", err)
+			return
+		}
+
+		q.Discount = promo.Discount
+		q.Price = promo.Price
+		q.PromoCode = promo.Code
+		items = append(items, quoteItem{Kind: itemDiscount, Description: "Promo code " + promo.Code, Amount: -promo.Discount})
+	}
+
+	q, token, err := s.Quotes.Issue(q, time.Now())
+	if err != nil {
+		s.handleError(w, r, problem.CodeInternal, "Failed to sign quote", err)
+		return
+	}
+
+	s.writeJSON(w, r, quoteResponse{Quote: q, Items: items, QuoteToken: token}, http.StatusCreated)
+}
+
+// Helper function to get the quote of a token for booking the spot for the user in the window.
+// Writes the error response on failure.
+func (s *Server) verifyQuote(w http.ResponseWriter, r *http.Request, token string, input addReservationInput) (quote.Quote, bool) {
+	q, err := s.Quotes.Verify(token, time.Now())
+	if err != nil {
+		if errors.Is(err, quote.ErrExpired) {
+			s.handleError(w, r, problem.CodeQuoteExpired, "The quote expired at "+q.ExpiresAt.Format(time.RFC3339)+", request a new one", err)
+			return q, false
+		}
+		s.handleError(w, r, problem.CodeInvalidQuote, "The quote token is invalid", err)
+		return q, false
+	}
+
+	if !q.Covers(input.SpotID, input.UserID, input.StartTime, input.EndTime) {
+		s.handleError(w, r, problem.CodeInvalidQuote, "The quote is for another spot, user or time window", nil)
+		return q, false
+	}
+	if input.PromoCode != "" && !strings.EqualFold(input.PromoCode, q.PromoCode) {
+		s.handleError(w, r, problem.CodeInvalidQuote, "The promo code is taken from the quote", nil)
+		return q, false
+	}
+
+	return q, true
+}
+
+// formatDuration writes whole hours and minutes, e.g. 2h 30m
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+}
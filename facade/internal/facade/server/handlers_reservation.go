@@ -1,370 +1,478 @@
 package server
 
 import (
-	"bytes"
 	"encoding/json"
-	"io"
+	"errors"
+	"math"
 	"net/http"
+	"net/url"
+	"time"
 
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/quote"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
 	"github.com/gorilla/mux"
 )
 
-func (s *Server) getAllReservations(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting all reservations")
+type addReservationInput struct {
+	UserID    string    `json:"user_id"`
+	SpotID    string    `json:"spot_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	VehicleID string    `json:"vehicle_id"`
 
-	resp, err := s.ReservationService.GetAll(r)
-	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
-		return
-	}
+	// PaymentMethod is required while the reservation service takes payments
+	PaymentMethod string `json:"payment_method"`
 
-	s.forwardResponse(w, resp)
+	// PromoCode takes its discount off the price of the spot
+	PromoCode string `json:"promo_code"`
+
+	// QuoteToken books at the price of the quote instead of the price of the spot, see createQuote
+	QuoteToken string `json:"quote_token"`
+}
+
+type editReservationInput struct {
+	ReservationID string     `json:"reservation_id"`
+	UserID        string     `json:"user_id"`
+	SpotID        string     `json:"spot_id"`
+	StartTime     *time.Time `json:"start_time"`
+	EndTime       *time.Time `json:"end_time"`
+	VehicleID     string     `json:"vehicle_id"`
+}
+
+func (s *Server) getAllReservations(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting all reservations")
+
+	s.listReservations(w, r, reservation.Filter{})
 }
 
 func (s *Server) deleteReservationByID(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Deleting reservation by reservationID")
+	s.Logger.InfoContext(r.Context(), "Deleting reservation by reservationID")
 
 	vars := mux.Vars(r)
 	requestedReservationID := vars["id"]
 
-	resp, err := s.ReservationService.DeleteReservation(requestedReservationID)
+	err := s.ReservationService.Delete(r.Context(), requestedReservationID)
 	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) getReservationsBySpot(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting reservations by spotID")
+	s.Logger.InfoContext(r.Context(), "Getting reservations by spotID")
 
 	vars := mux.Vars(r)
 	spotID := vars["id"]
 
-	resp, err := s.ReservationService.GetReservationsBySpot(spotID)
-	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
-		return
-	}
-
-	s.forwardResponse(w, resp)
+	s.listReservations(w, r, reservation.Filter{SpotID: spotID})
 }
 
 func (s *Server) getReservationsByUser(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting reservations by userID")
+	s.Logger.InfoContext(r.Context(), "Getting reservations by userID")
 
 	vars := mux.Vars(r)
 	userID := vars["id"]
 
 	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
 		return
 	}
 
 	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != userID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
-	resp, err := s.ReservationService.GetReservationsByUser(userID)
-	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
-		return
-	}
-
-	s.forwardResponse(w, resp)
+	s.listReservations(w, r, reservation.Filter{UserID: userID})
 }
 
-func (s *Server) getReservationByID(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting reservation by reservationID")
+// Helper function to list reservations, the IDs in the path take precedence over query filters
+func (s *Server) listReservations(w http.ResponseWriter, r *http.Request, pathFilter reservation.Filter) {
+	query := r.URL.Query()
 
-	vars := mux.Vars(r)
-	requestedReservationID := vars["id"]
+	filter, err := parseReservationFilter(query)
+	if err != nil {
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
+		return
+	}
+	if pathFilter.UserID != "" {
+		filter.UserID = pathFilter.UserID
+	}
+	if pathFilter.SpotID != "" {
+		filter.SpotID = pathFilter.SpotID
+	}
 
-	// Send the request to the reservation service
-	resp, err := s.ReservationService.Get(requestedReservationID)
+	opts, err := parseListOptions(query)
 	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
 		return
 	}
-	defer resp.Body.Close()
 
-	// Perform authorization check early
-	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
-	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+	page, err := s.ReservationService.GetAll(r.Context(), filter, opts)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Early return if the user is an admin
-	if RoleType(authResp.Role) == RoleAdmin {
-		s.Logger.Info.Println("Admin access granted")
+	s.writeJSON(w, r, page, http.StatusOK)
+}
 
-		s.forwardResponse(w, resp)
-		return
+// Helper function to parse reservation filters from query parameters
+func parseReservationFilter(query url.Values) (reservation.Filter, error) {
+	filter := reservation.Filter{
+		UserID: query.Get("user_id"),
+		SpotID: query.Get("spot_id"),
+		Status: query.Get("status"),
 	}
 
-	// Read the response body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		s.handleError(w, "Failed to read response body", err, http.StatusInternalServerError)
-		return
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("from must be an RFC3339 time")
+		}
+		filter.From = &from
+	}
+
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("to must be an RFC3339 time")
+		}
+		filter.To = &to
 	}
 
-	// Parse the response body into a map
-	var responseMap map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &responseMap); err != nil {
-		s.handleError(w, "Failed to parse response body", err, http.StatusInternalServerError)
+	return filter, nil
+}
+
+func (s *Server) getReservationByID(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting reservation by reservationID")
+
+	vars := mux.Vars(r)
+	requestedReservationID := vars["id"]
+
+	// Send the request to the reservation service
+	rsrv, err := s.ReservationService.Get(r.Context(), requestedReservationID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Get the userID from the response
-	userID, ok := responseMap["user_id"].(string)
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
 		return
 	}
 
 	// Check if the user is authorized to access this reservation
-	if authResp.UserID != userID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != rsrv.UserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
-	// Replace the response body so it can be forwarded
-	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-
-	s.forwardResponse(w, resp)
+	s.writeJSON(w, r, rsrv, http.StatusOK)
 }
 
 func (s *Server) addReservation(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Adding reservation")
+	s.Logger.InfoContext(r.Context(), "Adding reservation")
+	var input addReservationInput
 
-	// Read the request body into memory
-	bodyBytes, err := io.ReadAll(r.Body)
+	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to read request body", err, http.StatusBadRequest)
-		return
-	}
-
-	// Parse the JSON body into a map to check the user_id
-	var requestBody map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &requestBody); err != nil {
-		s.handleError(w, "Failed to parse request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	// Extract the user_id from the request body
-	userID, ok := requestBody["user_id"].(string)
-	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusBadRequest)
+	if input.UserID == "" || input.SpotID == "" {
+		s.handleError(w, r, problem.CodeBadRequest, "user_id and spot_id are required", nil)
 		return
 	}
 
 	// Perform authorization check
 	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
 		return
 	}
 
-	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != userID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != input.UserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
-	// Check if spot exists
-	spotID, ok := requestBody["spot_id"].(string)
-	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusBadRequest)
-		return
+	var q quote.Quote
+	if input.QuoteToken != "" {
+		if q, ok = s.verifyQuote(w, r, input.QuoteToken, input); !ok {
+			return
+		}
 	}
-	spotResp, err := s.SpotService.GetSpot(spotID)
-	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+
+	spt, ok := s.getExistingSpot(w, r, input.SpotID)
+	if !ok {
 		return
 	}
-	defer spotResp.Body.Close()
-	if spotResp.StatusCode != http.StatusOK {
-		s.handleError(w, "Spot with provided spotID does not exist", err, http.StatusNotFound)
+
+	// Resolve the vehicle so the reservation records which car will park
+	licensePlate, ok := s.resolveVehiclePlate(w, r, input.UserID, input.VehicleID, spt)
+	if !ok {
 		return
 	}
 
-	// Add valid status to request
-	requestBody["status"] = "valid"
-	newBody, err := json.Marshal(requestBody)
-	if err != nil {
-		s.handleError(w, "Failed to encode request body", err, http.StatusInternalServerError)
-		return
+	add := reservation.AddReservationInput{
+		UserID:       input.UserID,
+		SpotID:       input.SpotID,
+		StartTime:    input.StartTime,
+		EndTime:      input.EndTime,
+		Status:       "valid",
+		VehicleID:    input.VehicleID,
+		LicensePlate: licensePlate,
+
+		PaymentMethod: input.PaymentMethod,
+	}
+
+	// A quote guarantees its price, its promo code is only redeemed with the signed discount.
+	// Otherwise the price is the one of the spot, never one the client sent.
+	if input.QuoteToken != "" {
+		add.PricePaid = q.Price
+		if q.PromoCode != "" {
+			add.PricePaid = q.ListPrice
+			add.PromoCode = q.PromoCode
+			add.Discount = &q.Discount
+		}
+	} else {
+		price, err := s.SpotService.GetSpotPrice(r.Context(), input.SpotID, input.StartTime, input.EndTime)
+		if err != nil {
+			s.handleDownstreamError(w, r, "spot", err)
+			return
+		}
+		add.PricePaid = price
+		if input.PromoCode != "" {
+			add.PromoCode = input.PromoCode
+			add.LotID = spt.LotID
+			add.SpotType = spt.Type
+		}
 	}
 
 	// Send the request to the reservation service
-	resp, err := s.ReservationService.Add(newBody)
+	reservationID, err := s.ReservationService.Add(r.Context(), add)
 	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Forward the response back to the user
-	s.forwardResponse(w, resp)
+	s.writeID(w, reservationID)
 }
 
 func (s *Server) editReservation(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Editing reservation")
+	s.Logger.InfoContext(r.Context(), "Editing reservation")
+	var input editReservationInput
 
-	// Read the request body into memory
-	bodyBytes, err := io.ReadAll(r.Body)
+	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to read request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	// Parse the JSON body into a map to check the user_id
-	var requestBody map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &requestBody); err != nil {
-		s.handleError(w, "Failed to parse request body", err, http.StatusBadRequest)
+	if input.UserID == "" || input.SpotID == "" {
+		s.handleError(w, r, problem.CodeBadRequest, "user_id and spot_id are required", nil)
 		return
 	}
 
-	// Extract the user_id from the request body
-	userID, ok := requestBody["user_id"].(string)
-	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusBadRequest)
+	rsrv, err := s.ReservationService.Get(r.Context(), input.ReservationID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Perform authorization check
-	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
-	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+	// Users edit their own reservations, and neither they nor admins can hand one over
+	if !s.ownsOrAdmin(w, r, rsrv.UserID) {
 		return
 	}
-
-	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != userID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+	authResp, _ := r.Context().Value(authorizeKey).(authorizeResponse)
+	if RoleType(authResp.Role) != RoleAdmin && input.UserID != rsrv.UserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
-	// Check if spot exists
-	spotID, ok := requestBody["spot_id"].(string)
+	spt, ok := s.getExistingSpot(w, r, input.SpotID)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusBadRequest)
 		return
 	}
-	spotResp, err := s.SpotService.GetSpot(spotID)
-	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
-		return
+
+	// Without a vehicle_id the reservation keeps its vehicle, which must still fit the spot
+	vehicleID := input.VehicleID
+	if vehicleID == "" {
+		vehicleID = rsrv.VehicleID
 	}
-	defer spotResp.Body.Close()
-	if spotResp.StatusCode != http.StatusOK {
-		s.handleError(w, "Spot with provided spotID does not exist", err, http.StatusNotFound)
+
+	// Resolve the vehicle so the reservation records which car will park
+	licensePlate, ok := s.resolveVehiclePlate(w, r, rsrv.UserID, vehicleID, spt)
+	if !ok {
 		return
 	}
 
-	// Delete status from request
-	delete(requestBody, "status")
-	bodyWithoutStatus, err := json.Marshal(requestBody)
-	if err != nil {
-		s.handleError(w, "Failed to encode request body", err, http.StatusInternalServerError)
+	price, ok := s.repriceReservation(w, r, rsrv, input)
+	if !ok {
 		return
 	}
 
-	// Forward the request to the reservation service
-	resp, err := s.ReservationService.Edit(bodyWithoutStatus)
+	// The status can only be changed through cancellation
+	err = s.ReservationService.Edit(r.Context(), reservation.EditReservationInput{
+		ReservationID: input.ReservationID,
+		UserID:        rsrv.UserID,
+		SpotID:        input.SpotID,
+		StartTime:     input.StartTime,
+		EndTime:       input.EndTime,
+		PricePaid:     price,
+		VehicleID:     input.VehicleID,
+		LicensePlate:  licensePlate,
+	})
 	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Forward the response back to the user
-	s.forwardResponse(w, resp)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (s *Server) cancelReservation(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Cancel reservation")
-
-	vars := mux.Vars(r)
-	requestedReservationID := vars["id"]
+// Helper function to get the new price of a reservation moved to another spot or window, nil
+// when it keeps both. The discount it was booked with is taken off the price of the spot.
+// Writes the error response on failure.
+func (s *Server) repriceReservation(w http.ResponseWriter, r *http.Request, rsrv reservation.Reservation, input editReservationInput) (*float64, bool) {
+	start, end := rsrv.StartTime, rsrv.EndTime
+	if input.StartTime != nil {
+		start = *input.StartTime
+	}
+	if input.EndTime != nil {
+		end = *input.EndTime
+	}
+	if input.SpotID == rsrv.SpotID && start.Equal(rsrv.StartTime) && end.Equal(rsrv.EndTime) {
+		return nil, true
+	}
 
-	// Send the request to the reservation service
-	resp, err := s.ReservationService.Get(requestedReservationID)
+	price, err := s.SpotService.GetSpotPrice(r.Context(), input.SpotID, start, end)
 	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
-		return
+		s.handleDownstreamError(w, r, "spot", err)
+		return nil, false
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		s.handleError(w, "Reservation does not exist", err, http.StatusNotFound)
-		return
+	if rsrv.Discount != nil {
+		// Like the spot service, no booking is free
+		price = max(math.Round((price-rsrv.Discount.Amount)*100)/100, 0.01)
 	}
 
-	// Read the response body
-	bodyBytes, err := io.ReadAll(resp.Body)
+	return &price, true
+}
+
+// Helper function to get the spot of a reservation. Writes the error response on failure.
+func (s *Server) getExistingSpot(w http.ResponseWriter, r *http.Request, spotID string) (spot.Spot, bool) {
+	spt, err := s.SpotService.GetSpot(r.Context(), spotID)
 	if err != nil {
-		s.handleError(w, "Failed to read response body", err, http.StatusInternalServerError)
-		return
+		if hasProblemCode(err, problem.CodeSpotNotFound) {
+			s.handleError(w, r, problem.CodeSpotNotFound, "Spot with provided spotID does not exist", err)
+			return spt, false
+		}
+		s.handleDownstreamError(w, r, "spot", err)
+		return spt, false
 	}
 
-	// Parse the response body into a map
-	var responseMap map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &responseMap); err != nil {
-		s.handleError(w, "Failed to parse response body", err, http.StatusInternalServerError)
-		return
+	return spt, true
+}
+
+// Helper function to get the plate of the vehicle after checking it fits the spot. An empty
+// vehicle ID gives an empty plate, unless the spot needs a vehicle to be checked against.
+// Writes the error response on failure.
+func (s *Server) resolveVehiclePlate(w http.ResponseWriter, r *http.Request, userID, vehicleID string, spt spot.Spot) (string, bool) {
+	if vehicleID == "" {
+		if needsVehicle(spt) {
+			s.handleError(w, r, problem.CodeVehicleIncompatible, "vehicle_id is required for EV-only and height-limited spots", nil)
+			return "", false
+		}
+		return "", true
 	}
 
-	// Get the userID from the response
-	userID, ok := responseMap["user_id"].(string)
+	vehicle, ok := s.fetchVehicle(w, r, userID, vehicleID)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
-		return
+		return "", false
 	}
 
-	// Perform authorization check early
-	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	compatibility, ok := s.checkCompatibility(w, r, []string{spt.SpotID}, vehicle)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
-		return
+		return "", false
+	}
+	if reason, found := compatibility.Incompatible[spt.SpotID]; found {
+		s.handleError(w, r, problem.CodeVehicleIncompatible, "Vehicle is not compatible with the spot: "+reason, nil)
+		return "", false
 	}
 
-	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != userID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+	return vehicle.LicensePlate, true
+}
+
+// cancelReservation cancels under the policy of the spot, the reservation records the fee and refund
+func (s *Server) cancelReservation(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Cancel reservation")
+
+	rsrv, ok := s.fetchCancelableReservation(w, r, mux.Vars(r)["id"])
+	if !ok {
 		return
 	}
 
-	// Get the status from the response
-	status, ok := responseMap["status"].(string)
+	policy, ok := s.resolveCancellationPolicy(w, r, rsrv.SpotID)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
 		return
 	}
 
-	if status == "canceled" {
-		s.handleError(w, "Reservation already canceled", nil, http.StatusConflict)
+	// Send the request to the reservation service
+	if _, err := s.ReservationService.Cancel(r.Context(), rsrv.ReservationID, policy); err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Create a map with reservation ID and status
-	cancelRequest := map[string]string{
-		"reservation_id": requestedReservationID,
-		"status":         "canceled",
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getTimeline(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting occupancy timeline")
+	query := r.URL.Query()
+
+	input := reservation.TimelineInput{SpotIDs: parseSpotIDs(query.Get("spot_ids"))}
+
+	var err error
+	input.From, err = time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		s.handleError(w, r, problem.CodeBadRequest, "Invalid from query parameter, expected RFC3339", err)
+		return
 	}
-	cancelBytes, err := json.Marshal(cancelRequest)
+	input.To, err = time.Parse(time.RFC3339, query.Get("to"))
 	if err != nil {
-		s.handleError(w, "Failed to encode cancel request body", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeBadRequest, "Invalid to query parameter, expected RFC3339", err)
 		return
 	}
 
-	// Send the request to the reservation service
-	resp, err = s.ReservationService.Edit(cancelBytes)
+	if raw := query.Get("granularity"); raw != "" {
+		input.Granularity, err = time.ParseDuration(raw)
+		if err != nil || input.Granularity <= 0 {
+			s.handleError(w, r, problem.CodeBadRequest, "Invalid granularity query parameter, expected a positive duration", err)
+			return
+		}
+	}
+
+	if raw := query.Get("min_free"); raw != "" {
+		input.MinFree, err = time.ParseDuration(raw)
+		if err != nil || input.MinFree < 0 {
+			s.handleError(w, r, problem.CodeBadRequest, "Invalid min_free query parameter, expected a duration", err)
+			return
+		}
+	}
+
+	timelines, err := s.ReservationService.GetTimeline(r.Context(), input)
 	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Forward the response back to the user
-	s.forwardResponse(w, resp)
+	s.writeJSON(w, r, timelines, http.StatusOK)
 }
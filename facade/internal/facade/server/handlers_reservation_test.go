@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetReservation returns a reservation of u2, the fake users authorize everyone as u1
+func (fakeReservations) GetReservation(ctx context.Context, req *reservationpb.GetReservationRequest) (*reservationpb.Reservation, error) {
+	start := time.Date(2025, 5, 22, 10, 0, 0, 0, time.UTC)
+	return &reservationpb.Reservation{
+		ReservationId: req.GetReservationId(),
+		UserId:        "u2",
+		SpotId:        "s1",
+		StartTime:     timestamppb.New(start),
+		EndTime:       timestamppb.New(start.Add(time.Hour)),
+		Status:        "valid",
+		PricePaid:     10,
+	}, nil
+}
+
+// EditReservation fails, so an edit getting through doesn't answer 401 either
+func (fakeReservations) EditReservation(ctx context.Context, req *reservationpb.EditReservationRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Internal, "the reservation of another user was edited")
+}
+
+func TestEditOtherUsersReservation(t *testing.T) {
+	srv := newStreamingServer(t)
+
+	body := `{"reservation_id": "r1", "user_id": "u1", "spot_id": "s1"}`
+	req, err := http.NewRequest("PATCH", srv.URL+"/reservations", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
@@ -2,162 +2,259 @@ package server
 
 import (
 	"encoding/json"
-	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
 	"github.com/gorilla/mux"
 )
 
+type priceInput struct {
+	SpotID    string    `json:"spot_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	PromoCode string    `json:"promo_code"`
+}
+
+// priceResponse shows the list price and the discount when a promo code was applied
+type priceResponse struct {
+	SpotID    string  `json:"spot_id"`
+	Price     float64 `json:"price"`
+	ListPrice float64 `json:"list_price,omitempty"`
+	Discount  float64 `json:"discount,omitempty"`
+	PromoCode string  `json:"promo_code,omitempty"`
+}
+
 func (s *Server) getSpotPrice(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting spot price")
+	s.Logger.InfoContext(r.Context(), "Getting spot price")
+	var input priceInput
+
+	ok := s.decodeInput(w, r, &input, func(query url.Values) error {
+		input.SpotID = query.Get("spot_id")
+		input.PromoCode = query.Get("promo_code")
+		if err := parseTimeQuery(query, "start_time", &input.StartTime); err != nil {
+			return err
+		}
+		return parseTimeQuery(query, "end_time", &input.EndTime)
+	})
+	if !ok {
+		return
+	}
 
-	resp, err := s.SpotService.GetSpotPrice(r)
+	price, err := s.SpotService.GetSpotPrice(r.Context(), input.SpotID, input.StartTime, input.EndTime)
 	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	if input.PromoCode == "" {
+		s.writeJSON(w, r, priceResponse{SpotID: input.SpotID, Price: price}, http.StatusOK)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return
+	}
+
+	spt, err := s.SpotService.GetSpot(r.Context(), input.SpotID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	// The limits and the first booking restriction are checked for the caller
+	quote, err := s.ReservationService.QuotePromoCode(r.Context(), reservation.PromoQuoteInput{
+		Code:      input.PromoCode,
+		UserID:    authResp.UserID,
+		LotID:     spt.LotID,
+		SpotType:  spt.Type,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+		Price:     price,
+	})
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, priceResponse{
+		SpotID:    input.SpotID,
+		Price:     quote.Price,
+		ListPrice: quote.ListPrice,
+		Discount:  quote.Discount,
+		PromoCode: quote.Code,
+	}, http.StatusOK)
 }
 
 func (s *Server) getAllSpots(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting all spots")
+	s.Logger.InfoContext(r.Context(), "Getting all spots")
 
-	resp, err := s.SpotService.GetAll(r)
+	query := r.URL.Query()
+	opts, err := parseListOptions(query)
 	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	filter := spot.Filter{
+		LotID: query.Get("lot_id"),
+		Size:  query.Get("size"),
+		Type:  query.Get("type"),
+	}
+
+	page, err := s.SpotService.GetAll(r.Context(), filter, opts)
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	s.writeJSON(w, r, page, http.StatusOK)
 }
 
 func (s *Server) getSpotByID(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting spot by ID")
+	s.Logger.InfoContext(r.Context(), "Getting spot by ID")
 
 	vars := mux.Vars(r)
 	requestedSpotID := vars["id"]
 
-	resp, err := s.SpotService.GetSpot(requestedSpotID)
+	spt, err := s.SpotService.GetSpot(r.Context(), requestedSpotID)
 	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "spot", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	s.writeJSON(w, r, spt, http.StatusOK)
 }
 
 func (s *Server) deleteSpotByID(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Deleting spot by ID")
+	s.Logger.InfoContext(r.Context(), "Deleting spot by ID")
 
 	vars := mux.Vars(r)
 	requestedSpotID := vars["id"]
 
-	resp, err := s.SpotService.DeleteSpot(requestedSpotID)
+	err := s.SpotService.DeleteSpot(r.Context(), requestedSpotID)
 	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "spot", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) addSpot(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Adding spot")
+	s.Logger.InfoContext(r.Context(), "Adding spot")
+	var input spot.AddSpotInput
 
-	resp, err := s.SpotService.AddSpot(r)
+	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	spotID, err := s.SpotService.AddSpot(r.Context(), input)
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	s.writeID(w, spotID)
 }
 
 func (s *Server) editSpot(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Editing spot")
+	s.Logger.InfoContext(r.Context(), "Editing spot")
+	var input spot.EditSpotInput
 
-	resp, err := s.SpotService.EditSpot(r)
+	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	err = s.SpotService.EditSpot(r.Context(), input)
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 type availabilityInput struct {
 	SpotIDs   []string  `json:"spot_ids" validate:"required"`
 	StartTime time.Time `json:"start_time" validate:"required"`
 	EndTime   time.Time `json:"end_time" validate:"required"`
+	VehicleID string    `json:"vehicle_id,omitempty"`
 }
 
 func (s *Server) getAvailableSpots(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting available spots")
+	s.Logger.InfoContext(r.Context(), "Getting available spots")
 	var input availabilityInput
 
-	err := json.NewDecoder(r.Body).Decode(&input)
-	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+	ok := s.decodeInput(w, r, &input, func(query url.Values) error {
+		input.SpotIDs = parseSpotIDs(query.Get("spot_ids"))
+		input.VehicleID = query.Get("vehicle_id")
+		if err := parseTimeQuery(query, "start_time", &input.StartTime); err != nil {
+			return err
+		}
+		return parseTimeQuery(query, "end_time", &input.EndTime)
+	})
+	if !ok {
 		return
 	}
 
 	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	checkIfExistInput := map[string]interface{}{
-		"spot_ids": input.SpotIDs,
-	}
-	spotBody, err := json.Marshal(checkIfExistInput)
-	if err != nil {
-		s.handleError(w, "Failed to encode request body", err, http.StatusInternalServerError)
+		s.handleValidationError(w, r, err)
 		return
 	}
 
-	spotResp, err := s.SpotService.CheckIfSpotsExist(spotBody)
+	notFound, err := s.SpotService.CheckIfSpotsExist(r.Context(), input.SpotIDs)
 	if err != nil {
-		s.handleError(w, "Failed to send request to spot service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "spot", err)
 		return
 	}
-	defer spotResp.Body.Close()
 
-	spotBytes, err := io.ReadAll(spotResp.Body)
-	if err != nil {
-		s.handleError(w, "Failed to read response body", err, http.StatusInternalServerError)
+	if len(notFound) > 0 {
+		s.handleError(w, r, problem.CodeUnknownSpots, "Some spots do not exist: "+strings.Join(notFound, ", "), nil)
 		return
 	}
 
-	var result struct {
-		NotFound []string `json:"not_found"`
-		AllExist bool     `json:"all_exist"`
-	}
-	if err := json.Unmarshal(spotBytes, &result); err != nil {
-		s.handleError(w, "Failed to parse response body", err, http.StatusInternalServerError)
-		return
-	}
-
-	if !result.AllExist {
-		s.handleError(w, "Some spots do not exist: "+strings.Join(result.NotFound, ", "), nil, http.StatusBadRequest)
-		return
-	}
-
-	reservationBody, err := json.Marshal(input)
-	if err != nil {
-		s.handleError(w, "Failed to encode request body", err, http.StatusInternalServerError)
-		return
+	// Narrow the search down to spots the vehicle can use
+	if input.VehicleID != "" {
+		authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+		if !ok {
+			s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+			return
+		}
+
+		vehicle, ok := s.fetchVehicle(w, r, authResp.UserID, input.VehicleID)
+		if !ok {
+			return
+		}
+
+		compatibility, ok := s.checkCompatibility(w, r, input.SpotIDs, vehicle)
+		if !ok {
+			return
+		}
+
+		if len(compatibility.Compatible) == 0 {
+			s.Logger.InfoContext(r.Context(), "No compatible spots found")
+			s.writeJSON(w, r, []string{}, http.StatusOK)
+			return
+		}
+		input.SpotIDs = compatibility.Compatible
 	}
 
-	// Forward the request to the reservation service
-	resp, err := s.ReservationService.CheckAvailability(reservationBody)
+	availableSpots, err := s.ReservationService.CheckAvailability(r.Context(), input.SpotIDs, input.StartTime, input.EndTime)
 	if err != nil {
-		s.handleError(w, "Failed to send request to reservation service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "reservation", err)
 		return
 	}
 
-	// Forward the response back to the user
-	s.forwardResponse(w, resp)
+	s.writeJSON(w, r, availableSpots, http.StatusOK)
 }
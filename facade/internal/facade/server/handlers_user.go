@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/user"
 	"github.com/gorilla/mux"
 )
 
@@ -15,47 +17,60 @@ type registerInput struct {
 }
 
 func (s *Server) register(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Registering a new user")
+	s.Logger.InfoContext(r.Context(), "Registering a new user")
 	var input registerInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
 	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
+		s.handleValidationError(w, r, err)
 		return
 	}
 
-	input.Role = "user"
-
-	modifiedBody, err := json.Marshal(input)
+	userID, err := s.UserService.Register(r.Context(), user.AddUserInput{
+		Username: input.Username,
+		Email:    input.Email,
+		Password: input.Password,
+		Role:     "user",
+	})
 	if err != nil {
-		s.handleError(w, "Failed to encode modified request body", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "user", err)
 		return
 	}
 
-	resp, err := s.UserService.Register(modifiedBody)
-	if err != nil {
-		s.handleError(w, "Failed to send request to user service", err, http.StatusInternalServerError)
-		return
-	}
+	s.writeID(w, userID)
+}
+
+type loginInput struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
 
-	s.forwardResponse(w, resp)
+type loginResponse struct {
+	Jwt string `json:"jwt"`
 }
 
 func (s *Server) login(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Login user")
+	s.Logger.InfoContext(r.Context(), "Login user")
+	var input loginInput
 
-	resp, err := s.UserService.Login(r)
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	jwt, err := s.UserService.Login(r.Context(), input.Username, input.Password)
 	if err != nil {
-		s.handleError(w, "Failed to send request to user service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "user", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	s.writeJSON(w, r, loginResponse{Jwt: jwt}, http.StatusOK)
 }
 
 type editInput struct {
@@ -63,144 +78,148 @@ type editInput struct {
 	Username string `json:"username,omitempty" validate:"omitempty,min=3,max=30"`
 	Email    string `json:"email,omitempty" validate:"omitempty,email"`
 	Password string `json:"password,omitempty"`
+
+	// Billing replaces the billing details, the user service validates them
+	Billing *user.Billing `json:"billing,omitempty"`
 }
 
 func (s *Server) editUser(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Editing a user")
+	s.Logger.InfoContext(r.Context(), "Editing a user")
 	var input editInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
 	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
+		s.handleValidationError(w, r, err)
 		return
 	}
 
 	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
 		return
 	}
 
 	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != input.UserID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
-	validatedBody, err := json.Marshal(input)
+	err = s.UserService.Edit(r.Context(), user.EditUserInput{
+		UserID:   input.UserID,
+		Username: input.Username,
+		Email:    input.Email,
+		Password: input.Password,
+		Billing:  input.Billing,
+	})
 	if err != nil {
-		s.handleError(w, "Failed to encode validated request body", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "user", err)
 		return
 	}
 
-	resp, err := s.UserService.Edit(validatedBody)
-	if err != nil {
-		s.handleError(w, "Failed to send request to user service", err, http.StatusInternalServerError)
-		return
-	}
-
-	s.forwardResponse(w, resp)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 type editRoleInput struct {
 	UserID string `json:"user_id" validate:"required"`
-	Role   string `json:"role" validate:"required,oneof=admin user"`
+	Role   string `json:"role" validate:"required,oneof=admin user attendant"`
 }
 
 func (s *Server) editUsersRole(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Editing a user")
+	s.Logger.InfoContext(r.Context(), "Editing a user")
 	var input editRoleInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
 	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	validatedBody, err := json.Marshal(input)
-	if err != nil {
-		s.handleError(w, "Failed to encode validated request body", err, http.StatusInternalServerError)
+		s.handleValidationError(w, r, err)
 		return
 	}
 
-	resp, err := s.UserService.Edit(validatedBody)
+	err = s.UserService.Edit(r.Context(), user.EditUserInput{UserID: input.UserID, Role: input.Role})
 	if err != nil {
-		s.handleError(w, "Failed to send request to user service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "user", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) getAllUsers(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting all users")
+	s.Logger.InfoContext(r.Context(), "Getting all users")
+
+	query := r.URL.Query()
+	opts, err := parseListOptions(query)
+	if err != nil {
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
+		return
+	}
 
-	resp, err := s.UserService.GetAll(r)
+	page, err := s.UserService.GetAll(r.Context(), query.Get("role"), opts)
 	if err != nil {
-		s.handleError(w, "Failed to send request to user service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "user", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	s.writeJSON(w, r, page, http.StatusOK)
 }
 
 func (s *Server) getUserByID(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting user by userID")
+	s.Logger.InfoContext(r.Context(), "Getting user by userID")
 
 	vars := mux.Vars(r)
 	requestedUserID := vars["id"]
 
 	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
 		return
 	}
 
 	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != requestedUserID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
-	resp, err := s.UserService.GetUser(requestedUserID)
+	usr, err := s.UserService.GetUser(r.Context(), requestedUserID)
 	if err != nil {
-		s.handleError(w, "Failed to send request to user service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "user", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	s.writeJSON(w, r, usr, http.StatusOK)
 }
 
 func (s *Server) deleteUserByID(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Deleting user by userID")
+	s.Logger.InfoContext(r.Context(), "Deleting user by userID")
 
 	vars := mux.Vars(r)
 	requestedUserID := vars["id"]
 
 	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
 	if !ok {
-		s.handleError(w, "Unexpected error", nil, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
 		return
 	}
 
 	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != requestedUserID {
-		s.handleError(w, "Unauthorized", nil, http.StatusUnauthorized)
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
-	resp, err := s.UserService.DeleteUser(requestedUserID)
+	err := s.UserService.DeleteUser(r.Context(), requestedUserID)
 	if err != nil {
-		s.handleError(w, "Failed to send request to user service", err, http.StatusInternalServerError)
+		s.handleDownstreamError(w, r, "user", err)
 		return
 	}
 
-	s.forwardResponse(w, resp)
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/user"
+	"github.com/gorilla/mux"
+)
+
+type addVehicleInput struct {
+	LicensePlate string `json:"license_plate" validate:"required,max=16"`
+	Country      string `json:"country" validate:"required,iso3166_1_alpha2"`
+	Region       string `json:"region,omitempty" validate:"omitempty,max=50"`
+	Make         string `json:"make,omitempty" validate:"omitempty,max=50"`
+	Size         string `json:"size" validate:"required,oneof=small medium large"`
+	IsEV         bool   `json:"is_ev"`
+	HeightCm     int    `json:"height_cm,omitempty" validate:"omitempty,gt=0,lte=500"`
+}
+
+func (s *Server) addVehicle(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Adding vehicle")
+
+	vars := mux.Vars(r)
+	requestedUserID := vars["id"]
+
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return
+	}
+
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != requestedUserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	var input addVehicleInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	if err := s.Validator.Struct(input); err != nil {
+		s.handleValidationError(w, r, err)
+		return
+	}
+
+	vehicleID, err := s.UserService.AddVehicle(r.Context(), requestedUserID, user.Vehicle{
+		LicensePlate: input.LicensePlate,
+		Country:      input.Country,
+		Region:       input.Region,
+		Make:         input.Make,
+		Size:         input.Size,
+		IsEV:         input.IsEV,
+		HeightCm:     input.HeightCm,
+	})
+	if err != nil {
+		s.handleDownstreamError(w, r, "user", err)
+		return
+	}
+
+	s.writeID(w, vehicleID)
+}
+
+func (s *Server) getVehicles(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting vehicles by userID")
+
+	vars := mux.Vars(r)
+	requestedUserID := vars["id"]
+
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return
+	}
+
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != requestedUserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	vehicles, err := s.UserService.GetVehicles(r.Context(), requestedUserID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "user", err)
+		return
+	}
+
+	s.writeJSON(w, r, vehicles, http.StatusOK)
+}
+
+func (s *Server) deleteVehicle(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting vehicle")
+
+	vars := mux.Vars(r)
+	requestedUserID := vars["id"]
+	requestedVehicleID := vars["vehicleId"]
+
+	authResp, ok := r.Context().Value(authorizeKey).(authorizeResponse)
+	if !ok {
+		s.handleError(w, r, problem.CodeInternal, "Unexpected error", nil)
+		return
+	}
+
+	if RoleType(authResp.Role) != RoleAdmin && authResp.UserID != requestedUserID {
+		s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	err := s.UserService.DeleteVehicle(r.Context(), requestedUserID, requestedVehicleID)
+	if err != nil {
+		s.handleDownstreamError(w, r, "user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) lookupReservationByPlate(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Looking up reservation by license plate")
+
+	query := r.URL.Query()
+	licensePlate := query.Get("license_plate")
+	spotID := query.Get("spot_id")
+	if licensePlate == "" || spotID == "" {
+		s.handleError(w, r, problem.CodeBadRequest, "license_plate and spot_id query parameters are required", nil)
+		return
+	}
+
+	reservation, err := s.ReservationService.GetActiveReservation(r.Context(), spotID, licensePlate)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, reservation, http.StatusOK)
+}
+
+// Helper function to fetch a user's vehicle, writes the error response on failure
+func (s *Server) fetchVehicle(w http.ResponseWriter, r *http.Request, userID, vehicleID string) (user.Vehicle, bool) {
+	vehicle, err := s.UserService.GetVehicle(r.Context(), userID, vehicleID)
+	if err != nil {
+		if hasProblemCode(err, problem.CodeVehicleNotFound) || hasProblemCode(err, problem.CodeUserNotFound) {
+			s.handleError(w, r, problem.CodeVehicleNotFound, "Vehicle with provided vehicleID does not exist", err)
+			return vehicle, false
+		}
+		s.handleDownstreamError(w, r, "user", err)
+		return vehicle, false
+	}
+
+	return vehicle, true
+}
+
+// Helper function to check which spots the vehicle can use, writes the error response on failure
+func (s *Server) checkCompatibility(w http.ResponseWriter, r *http.Request, spotIDs []string, vehicle user.Vehicle) (spot.CompatibilityResult, bool) {
+	result, err := s.SpotService.CheckCompatibility(r.Context(), spotIDs, spot.VehicleProfile{
+		Size:     vehicle.Size,
+		IsEV:     vehicle.IsEV,
+		HeightCm: vehicle.HeightCm,
+	})
+	if err != nil {
+		s.handleDownstreamError(w, r, "spot", err)
+		return result, false
+	}
+
+	return result, true
+}
+
+// needsVehicle tells whether only some vehicles can use the spot, so it can't be booked without one
+func needsVehicle(spt spot.Spot) bool {
+	return spt.Type == "ev" || spt.MaxHeightCm > 0
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
+	"github.com/gorilla/mux"
+)
+
+// The reservation service hosts the webhooks and validates the input
+type addWebhookInput struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+func (s *Server) addWebhook(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Adding webhook")
+	var input addWebhookInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	webhook, err := s.ReservationService.AddWebhook(r.Context(), reservation.AddWebhookInput{
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		Secret:     input.Secret,
+	})
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, webhook, http.StatusCreated)
+}
+
+func (s *Server) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting webhooks")
+
+	webhooks, err := s.ReservationService.GetWebhooks(r.Context())
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, webhooks, http.StatusOK)
+}
+
+func (s *Server) getWebhookByID(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting webhook by ID")
+
+	webhook, err := s.ReservationService.GetWebhook(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, webhook, http.StatusOK)
+}
+
+func (s *Server) deleteWebhookByID(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting webhook by ID")
+
+	if err := s.ReservationService.DeleteWebhook(r.Context(), mux.Vars(r)["id"]); err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting webhook deliveries")
+	query := r.URL.Query()
+
+	var limit int64
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit <= 0 {
+			err = errors.New("limit must be a positive number")
+			s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	deliveries, err := s.ReservationService.GetDeliveries(r.Context(), mux.Vars(r)["id"], query.Get("status"), limit)
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, deliveries, http.StatusOK)
+}
+
+func (s *Server) redeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Redelivering webhook delivery")
+	vars := mux.Vars(r)
+
+	delivery, err := s.ReservationService.Redeliver(r.Context(), vars["id"], vars["deliveryId"])
+	if err != nil {
+		s.handleDownstreamError(w, r, "reservation", err)
+		return
+	}
+
+	s.writeJSON(w, r, delivery, http.StatusAccepted)
+}
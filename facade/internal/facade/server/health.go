@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// healthz reports that the process is up and serving requests
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, r, healthResponse{Status: "ok"}, http.StatusOK)
+}
+
+// readyz reports whether all internal services can be reached
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	checks := map[string]func(context.Context) (bool, error){
+		"user":        s.UserService.CheckHealth,
+		"spot":        s.SpotService.CheckHealth,
+		"reservation": s.ReservationService.CheckHealth,
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]string, len(checks))
+	ready := true
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) (bool, error)) {
+			defer wg.Done()
+
+			result := "ok"
+			serving, err := check(ctx)
+			if err != nil {
+				s.Logger.WarnContext(r.Context(), "Service is not reachable", "service", name, "error", err)
+				result = "unreachable"
+			} else if !serving {
+				result = "unhealthy"
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = result
+			if result != "ok" {
+				ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	if !ready {
+		s.writeJSON(w, r, healthResponse{Status: "unavailable", Checks: results}, http.StatusServiceUnavailable)
+		return
+	}
+
+	s.writeJSON(w, r, healthResponse{Status: "ok", Checks: results}, http.StatusOK)
+}
@@ -2,16 +2,19 @@ package server
 
 import (
 	"context"
-	"encoding/json"
-	"io"
 	"net/http"
+	"strings"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/openapi"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
 )
 
 type RoleType string
 
 const (
-	RoleAdmin RoleType = "admin"
-	RoleUser  RoleType = "user"
+	RoleAdmin     RoleType = "admin"
+	RoleUser      RoleType = "user"
+	RoleAttendant RoleType = "attendant"
 )
 
 type authorizeResponse struct {
@@ -27,40 +30,41 @@ func (s *Server) authorize(requiredRole RoleType, next http.Handler) http.Handle
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			s.handleError(w, r, problem.CodeUnauthorized, "Missing Authorization header", nil)
 			return
 		}
 
-		resp, err := s.UserService.Authorize(authHeader)
+		claims, err := s.UserService.Authorize(r.Context(), strings.TrimPrefix(authHeader, "Bearer "))
 		if err != nil {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			if hasProblemCode(err, problem.CodeInvalidToken) {
+				s.handleError(w, r, problem.CodeInvalidToken, "Invalid or expired token", err)
+				return
+			}
+			s.handleDownstreamError(w, r, "user", err)
 			return
 		}
-		defer resp.Body.Close()
+		authResp := authorizeResponse{Role: claims.Role, UserID: claims.UserID}
 
-		if resp.StatusCode != http.StatusOK {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		if RoleType(authResp.Role) != requiredRole && RoleType(authResp.Role) != RoleAdmin {
+			s.handleError(w, r, problem.CodeUnauthorized, "Unauthorized", nil)
 			return
 		}
 
-		var authResp authorizeResponse
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-		err = json.Unmarshal(body, &authResp)
-		if err != nil {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+		ctx := context.WithValue(r.Context(), authorizeKey, authResp)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		if RoleType(authResp.Role) != requiredRole && RoleType(authResp.Role) != RoleAdmin {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+// validateRequest rejects requests that do not match the OpenAPI document
+func (s *Server) validateRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.RequestValidator.Validate(r); err != nil {
+			p := problem.New(problem.CodeValidationFailed, "The request does not match the API specification")
+			p.Errors = openapi.FieldErrors(err)
+			s.writeProblem(w, r, p, err)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), authorizeKey, authResp)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
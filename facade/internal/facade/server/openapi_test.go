@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/openapi"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/gorilla/mux"
+)
+
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Failed to load OpenAPI document: %v", err)
+	}
+
+	s := NewServer(logger.GetLogger(), config.GetConfig(), nil, nil, nil)
+	router := s.Handler().(*mux.Router)
+
+	routes := make(map[string]struct{})
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			routes[method+" "+path] = struct{}{}
+
+			item := doc.Paths.Find(path)
+			if item == nil || item.GetOperation(method) == nil {
+				t.Errorf("Route %s %s is missing from the OpenAPI document", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk routes: %v", err)
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if _, ok := routes[method+" "+path]; !ok {
+				t.Errorf("Operation %s %s has no route", method, path)
+			}
+		}
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	v, err := openapi.NewValidator()
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	s := NewServer(logger.GetLogger(), config.GetConfig(), nil, nil, nil)
+	s.RequestValidator = v
+
+	body, _ := json.Marshal(map[string]string{"username": "jd", "email": "not-an-email"})
+	req, err := http.NewRequest("POST", "/users/register", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+
+	var p problem.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if p.Code != problem.CodeValidationFailed {
+		t.Errorf("handler returned wrong code: got %v want %v", p.Code, problem.CodeValidationFailed)
+	}
+
+	fields := make([]string, 0, len(p.Errors))
+	for _, fe := range p.Errors {
+		fields = append(fields, fe.Field)
+	}
+	for _, want := range []string{"username", "email", "password"} {
+		if !strings.Contains(strings.Join(fields, ","), want) {
+			t.Errorf("handler did not report %s: got %v", want, p.Errors)
+		}
+	}
+}
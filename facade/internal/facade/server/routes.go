@@ -20,16 +20,21 @@ func (s *Server) addUserRoutes(r *mux.Router) {
 	userRouter.Handle("", s.authorize(RoleUser, http.HandlerFunc(s.editUser))).Methods("PATCH")
 	userRouter.Handle("/{id}", s.authorize(RoleUser, http.HandlerFunc(s.getUserByID))).Methods("GET")
 	userRouter.Handle("/{id}", s.authorize(RoleUser, http.HandlerFunc(s.deleteUserByID))).Methods("DELETE")
+	userRouter.Handle("/{id}/vehicles", s.authorize(RoleUser, http.HandlerFunc(s.addVehicle))).Methods("POST")
+	userRouter.Handle("/{id}/vehicles", s.authorize(RoleUser, http.HandlerFunc(s.getVehicles))).Methods("GET")
+	userRouter.Handle("/{id}/vehicles/{vehicleId}", s.authorize(RoleUser, http.HandlerFunc(s.deleteVehicle))).Methods("DELETE")
 }
 
 func (s *Server) addSpotRoutes(r *mux.Router) {
 	spotRouter := r.PathPrefix("/spots").Subrouter()
 
 	// User routes
-	spotRouter.Handle("/available", s.authorize(RoleUser, http.HandlerFunc(s.getAvailableSpots))).Methods("GET")
+	spotRouter.Handle("/available", s.authorize(RoleUser, http.HandlerFunc(s.getAvailableSpots))).Methods("GET", "POST")
 	spotRouter.Handle("/price", s.authorize(RoleUser, http.HandlerFunc(s.getSpotPrice))).Methods("GET")
+	spotRouter.Handle("/availability/stream", s.authorize(RoleUser, http.HandlerFunc(s.streamAvailability))).Methods("GET")
 	spotRouter.Handle("", s.authorize(RoleUser, http.HandlerFunc(s.getAllSpots))).Methods("GET")
 	spotRouter.Handle("/{id}", s.authorize(RoleUser, http.HandlerFunc(s.getSpotByID))).Methods("GET")
+	spotRouter.Handle("/{id}/cancellation-policy", s.authorize(RoleUser, http.HandlerFunc(s.getSpotCancellationPolicy))).Methods("GET")
 
 	// Admin routes
 	spotRouter.Handle("/{id}", s.authorize(RoleAdmin, http.HandlerFunc(s.deleteSpotByID))).Methods("DELETE")
@@ -40,15 +45,80 @@ func (s *Server) addSpotRoutes(r *mux.Router) {
 func (s *Server) addReservationRoutes(r *mux.Router) {
 	reservationRouter := r.PathPrefix("/reservations").Subrouter()
 
+	// Attendant routes
+	reservationRouter.Handle("/lookup", s.authorize(RoleAttendant, http.HandlerFunc(s.lookupReservationByPlate))).Methods("GET")
+
 	// Admin routes
 	reservationRouter.Handle("", s.authorize(RoleAdmin, http.HandlerFunc(s.getAllReservations))).Methods("GET")
 	reservationRouter.Handle("/spot/{id}", s.authorize(RoleAdmin, http.HandlerFunc(s.getReservationsBySpot))).Methods("GET")
 	reservationRouter.Handle("/{id}", s.authorize(RoleAdmin, http.HandlerFunc(s.deleteReservationByID))).Methods("DELETE")
 
 	// User routes
+	reservationRouter.Handle("/timeline", s.authorize(RoleUser, http.HandlerFunc(s.getTimeline))).Methods("GET")
 	reservationRouter.Handle("/user/{id}", s.authorize(RoleUser, http.HandlerFunc(s.getReservationsByUser))).Methods("GET")
 	reservationRouter.Handle("/{id}", s.authorize(RoleUser, http.HandlerFunc(s.getReservationByID))).Methods("GET")
+	reservationRouter.Handle("/{id}/payment", s.authorize(RoleUser, http.HandlerFunc(s.getReservationPayment))).Methods("GET")
+	reservationRouter.Handle("/{id}/cancellation", s.authorize(RoleUser, http.HandlerFunc(s.getCancellationQuote))).Methods("GET")
+	reservationRouter.Handle("/{id}/invoice", s.authorize(RoleUser, http.HandlerFunc(s.getReservationInvoice))).Methods("GET")
+	reservationRouter.Handle("/{id}/invoice", s.authorize(RoleUser, http.HandlerFunc(s.issueReservationInvoice))).Methods("POST")
 	reservationRouter.Handle("", s.authorize(RoleUser, http.HandlerFunc(s.addReservation))).Methods("POST")
+	reservationRouter.Handle("/auto", s.authorize(RoleUser, http.HandlerFunc(s.autoReserve))).Methods("POST")
 	reservationRouter.Handle("", s.authorize(RoleUser, http.HandlerFunc(s.editReservation))).Methods("PATCH")
 	reservationRouter.Handle("/cancel/{id}", s.authorize(RoleUser, http.HandlerFunc(s.cancelReservation))).Methods("PATCH")
 }
+
+func (s *Server) addPaymentRoutes(r *mux.Router) {
+	paymentRouter := r.PathPrefix("/payments").Subrouter()
+
+	// The payment provider signs its callbacks, the reservation service checks the signature
+	paymentRouter.HandleFunc("/callback", s.paymentCallback).Methods("POST")
+}
+
+func (s *Server) addCancellationPolicyRoutes(r *mux.Router) {
+	policyRouter := r.PathPrefix("/cancellation-policies").Subrouter()
+
+	// Admin routes
+	policyRouter.Handle("", s.authorize(RoleAdmin, http.HandlerFunc(s.setCancellationPolicy))).Methods("PUT")
+	policyRouter.Handle("", s.authorize(RoleAdmin, http.HandlerFunc(s.getCancellationPolicies))).Methods("GET")
+	policyRouter.Handle("/{scope}/{id}", s.authorize(RoleAdmin, http.HandlerFunc(s.deleteCancellationPolicy))).Methods("DELETE")
+}
+
+func (s *Server) addInvoiceRoutes(r *mux.Router) {
+	invoiceRouter := r.PathPrefix("/invoices").Subrouter()
+
+	// Admin routes
+	invoiceRouter.Handle("/monthly", s.authorize(RoleAdmin, http.HandlerFunc(s.issueMonthlyInvoice))).Methods("POST")
+
+	// User routes
+	invoiceRouter.Handle("", s.authorize(RoleUser, http.HandlerFunc(s.getInvoices))).Methods("GET")
+	invoiceRouter.Handle("/{id}", s.authorize(RoleUser, http.HandlerFunc(s.getInvoiceByID))).Methods("GET")
+}
+
+func (s *Server) addPromoCodeRoutes(r *mux.Router) {
+	promoRouter := r.PathPrefix("/promo-codes").Subrouter()
+
+	// Admin routes
+	promoRouter.Handle("", s.authorize(RoleAdmin, http.HandlerFunc(s.addPromoCode))).Methods("POST")
+	promoRouter.Handle("", s.authorize(RoleAdmin, http.HandlerFunc(s.getPromoCodes))).Methods("GET")
+	promoRouter.Handle("/{code}", s.authorize(RoleAdmin, http.HandlerFunc(s.getPromoCodeByCode))).Methods("GET")
+	promoRouter.Handle("/{code}", s.authorize(RoleAdmin, http.HandlerFunc(s.deletePromoCode))).Methods("DELETE")
+}
+
+func (s *Server) addQuoteRoutes(r *mux.Router) {
+	quoteRouter := r.PathPrefix("/quotes").Subrouter()
+
+	// User routes
+	quoteRouter.Handle("", s.authorize(RoleUser, http.HandlerFunc(s.createQuote))).Methods("POST")
+}
+
+func (s *Server) addWebhookRoutes(r *mux.Router) {
+	webhookRouter := r.PathPrefix("/webhooks").Subrouter()
+
+	// Admin routes
+	webhookRouter.Handle("", s.authorize(RoleAdmin, http.HandlerFunc(s.addWebhook))).Methods("POST")
+	webhookRouter.Handle("", s.authorize(RoleAdmin, http.HandlerFunc(s.getWebhooks))).Methods("GET")
+	webhookRouter.Handle("/{id}", s.authorize(RoleAdmin, http.HandlerFunc(s.getWebhookByID))).Methods("GET")
+	webhookRouter.Handle("/{id}", s.authorize(RoleAdmin, http.HandlerFunc(s.deleteWebhookByID))).Methods("DELETE")
+	webhookRouter.Handle("/{id}/deliveries", s.authorize(RoleAdmin, http.HandlerFunc(s.getWebhookDeliveries))).Methods("GET")
+	webhookRouter.Handle("/{id}/deliveries/{deliveryId}/redeliver", s.authorize(RoleAdmin, http.HandlerFunc(s.redeliverWebhookDelivery))).Methods("POST")
+}
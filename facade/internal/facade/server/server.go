@@ -1,15 +1,21 @@
 package server
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/logger"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/metrics"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/openapi"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/problem"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/quote"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/services/reservation"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/services/spot"
 	"github.com/ciameksw/reserve-park/facade/internal/facade/services/user"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 type Server struct {
@@ -19,6 +25,12 @@ type Server struct {
 	SpotService        *spot.SpotService
 	ReservationService *reservation.ReservationService
 	Validator          *validator.Validate
+	RequestValidator   *openapi.Validator
+	Quotes             *quote.Signer
+
+	// closing is closed when the server starts shutting down, so streams end
+	// instead of holding the shutdown up
+	closing chan struct{}
 }
 
 func NewServer(log *logger.Logger,
@@ -26,27 +38,89 @@ func NewServer(log *logger.Logger,
 	usr *user.UserService,
 	spt *spot.SpotService,
 	rsrv *reservation.ReservationService) *Server {
+	v := validator.New()
+	v.RegisterTagNameFunc(problem.JSONFieldName)
+
+	if cfg.QuoteSigningKey == "" {
+		log.Warn("QUOTE_SIGNING_KEY is not set, quotes are signed with a random key: a restart invalidates them and other replicas can't verify them")
+	}
+
 	return &Server{
 		Logger:             log,
 		Config:             cfg,
 		UserService:        usr,
 		SpotService:        spt,
 		ReservationService: rsrv,
-		Validator:          validator.New(),
+		Validator:          v,
+		Quotes:             quote.NewSigner(cfg.QuoteSigningKey, cfg.QuoteTTL),
+		closing:            make(chan struct{}),
 	}
 }
 
-func (s *Server) Start() {
+// Handler returns the router with all routes and middlewares
+func (s *Server) Handler() http.Handler {
 	r := mux.NewRouter()
+	r.NotFoundHandler = logger.RequestIDMiddleware(http.HandlerFunc(s.notFound))
+	r.MethodNotAllowedHandler = logger.RequestIDMiddleware(http.HandlerFunc(s.methodNotAllowed))
+	r.Use(logger.RequestIDMiddleware, otelmux.Middleware("facade"), metrics.Middleware)
+	if s.RequestValidator != nil {
+		r.Use(s.validateRequest)
+	}
+
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	r.HandleFunc("/healthz", s.healthz).Methods("GET")
+	r.HandleFunc("/readyz", s.readyz).Methods("GET")
+	r.HandleFunc("/openapi.json", openapi.SpecHandler).Methods("GET")
+	r.HandleFunc("/docs", openapi.DocsHandler).Methods("GET")
 
 	s.addUserRoutes(r)
 	s.addSpotRoutes(r)
 	s.addReservationRoutes(r)
+	s.addPaymentRoutes(r)
+	s.addCancellationPolicyRoutes(r)
+	s.addInvoiceRoutes(r)
+	s.addPromoCodeRoutes(r)
+	s.addQuoteRoutes(r)
+	s.addWebhookRoutes(r)
+
+	return r
+}
 
-	addr := s.Config.ServerHost + ":" + s.Config.ServerPort
-	s.Logger.Info.Printf("Server started at %s\n", addr)
-	err := http.ListenAndServe(addr, r)
-	if err != nil {
-		s.Logger.Error.Fatalf("Failed to start server: %v", err)
+// Start serves requests until the context is canceled, then stops accepting
+// new connections and waits for in-flight requests to finish.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:         s.Config.ServerHost + ":" + s.Config.ServerPort,
+		Handler:      s.Handler(),
+		ReadTimeout:  s.Config.ReadTimeout,
+		WriteTimeout: s.Config.WriteTimeout,
+		IdleTimeout:  s.Config.IdleTimeout,
 	}
+	srv.RegisterOnShutdown(func() { close(s.closing) })
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.Logger.Info("Server started", "addr", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.Logger.Info("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.Config.ShutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	s.handleError(w, r, problem.CodeNotFound, "No route matches "+r.URL.Path, nil)
+}
+
+func (s *Server) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	s.handleError(w, r, problem.CodeMethodNotAllowed, "Method "+r.Method+" is not allowed on "+r.URL.Path, nil)
 }
@@ -0,0 +1,60 @@
+package reservation
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+	"google.golang.org/grpc"
+)
+
+// AvailabilityChange tells that a reservation took (booked) or freed (released) a spot for a window
+type AvailabilityChange struct {
+	SpotID        string    `json:"spot_id"`
+	ReservationID string    `json:"reservation_id"`
+	Change        string    `json:"change"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// AvailabilityWatch receives the changes of the watched spots until its context is canceled
+type AvailabilityWatch struct {
+	stream grpc.ServerStreamingClient[reservationpb.AvailabilityChange]
+}
+
+// Recv blocks until the next change, the service may end the watch at any time,
+// e.g. when it shuts down, and the watcher should then watch again
+func (w *AvailabilityWatch) Recv() (AvailabilityChange, error) {
+	change, err := w.stream.Recv()
+	if err != nil {
+		return AvailabilityChange{}, err
+	}
+
+	return AvailabilityChange{
+		SpotID:        change.GetSpotId(),
+		ReservationID: change.GetReservationId(),
+		Change:        change.GetChange(),
+		StartTime:     change.GetStartTime().AsTime(),
+		EndTime:       change.GetEndTime().AsTime(),
+		EventID:       change.GetEventId(),
+		EventType:     change.GetEventType(),
+		OccurredAt:    change.GetOccurredAt().AsTime(),
+	}, nil
+}
+
+// WatchAvailability returns once the service opened the watch, so a rejected
+// request fails here rather than on the first Recv
+func (rs *ReservationService) WatchAvailability(ctx context.Context, spotIDs []string) (*AvailabilityWatch, error) {
+	stream, err := rs.client.WatchAvailability(ctx, &reservationpb.WatchAvailabilityRequest{SpotIds: spotIDs})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stream.Header(); err != nil {
+		return nil, err
+	}
+
+	return &AvailabilityWatch{stream: stream}, nil
+}
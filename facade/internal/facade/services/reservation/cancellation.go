@@ -0,0 +1,110 @@
+package reservation
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+)
+
+// CancellationPolicy is the policy the spot service resolved for the spot of the reservation
+type CancellationPolicy struct {
+	Scope                string  `json:"scope"`
+	TargetID             string  `json:"target_id"`
+	FreeUntilHours       int     `json:"free_until_hours"`
+	LateFeePercent       float64 `json:"late_fee_percent"`
+	NonRefundableMinutes int     `json:"non_refundable_minutes"`
+}
+
+// Cancellation records what was kept and refunded when the reservation was canceled
+type Cancellation struct {
+	CanceledAt time.Time          `json:"canceled_at"`
+	Tier       string             `json:"tier"`
+	Fee        float64            `json:"fee"`
+	Refund     float64            `json:"refund"`
+	Policy     CancellationPolicy `json:"policy"`
+}
+
+// CancellationQuote is what canceling the reservation costs, it holds until ValidUntil
+type CancellationQuote struct {
+	ReservationID string             `json:"reservation_id"`
+	Price         float64            `json:"price"`
+	Fee           float64            `json:"fee"`
+	Refund        float64            `json:"refund"`
+	Tier          string             `json:"tier"`
+	Policy        CancellationPolicy `json:"policy"`
+	QuotedAt      time.Time          `json:"quoted_at"`
+	ValidUntil    *time.Time         `json:"valid_until,omitempty"`
+}
+
+func (rs *ReservationService) QuoteCancellation(ctx context.Context, reservationID string, policy CancellationPolicy) (CancellationQuote, error) {
+	resp, err := rs.client.GetCancellationQuote(ctx, &reservationpb.CancellationRequest{
+		ReservationId: reservationID,
+		Policy:        policyToProto(policy),
+	})
+	if err != nil {
+		return CancellationQuote{}, err
+	}
+
+	quote := CancellationQuote{
+		ReservationID: resp.GetReservationId(),
+		Price:         resp.GetPrice(),
+		Fee:           resp.GetFee(),
+		Refund:        resp.GetRefund(),
+		Tier:          resp.GetTier(),
+		Policy:        policyFromProto(resp.GetPolicy()),
+		QuotedAt:      resp.GetQuotedAt().AsTime(),
+	}
+	if resp.ValidUntil != nil {
+		validUntil := resp.GetValidUntil().AsTime()
+		quote.ValidUntil = &validUntil
+	}
+	return quote, nil
+}
+
+// Cancel cancels the reservation at the price the policy sets for now
+func (rs *ReservationService) Cancel(ctx context.Context, reservationID string, policy CancellationPolicy) (Reservation, error) {
+	resp, err := rs.client.CancelReservation(ctx, &reservationpb.CancellationRequest{
+		ReservationId: reservationID,
+		Policy:        policyToProto(policy),
+	})
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	return reservationFromProto(resp), nil
+}
+
+func policyToProto(policy CancellationPolicy) *reservationpb.CancellationPolicy {
+	return &reservationpb.CancellationPolicy{
+		Scope:                policy.Scope,
+		TargetId:             policy.TargetID,
+		FreeUntilHours:       int32(policy.FreeUntilHours),
+		LateFeePercent:       policy.LateFeePercent,
+		NonRefundableMinutes: int32(policy.NonRefundableMinutes),
+	}
+}
+
+func policyFromProto(policy *reservationpb.CancellationPolicy) CancellationPolicy {
+	return CancellationPolicy{
+		Scope:                policy.GetScope(),
+		TargetID:             policy.GetTargetId(),
+		FreeUntilHours:       int(policy.GetFreeUntilHours()),
+		LateFeePercent:       policy.GetLateFeePercent(),
+		NonRefundableMinutes: int(policy.GetNonRefundableMinutes()),
+	}
+}
+
+func cancellationFromProto(cancellation *reservationpb.Cancellation) *Cancellation {
+	if cancellation == nil {
+		return nil
+	}
+
+	return &Cancellation{
+		CanceledAt: cancellation.GetCanceledAt().AsTime(),
+		Tier:       cancellation.GetTier(),
+		Fee:        cancellation.GetFee(),
+		Refund:     cancellation.GetRefund(),
+		Policy:     policyFromProto(cancellation.GetPolicy()),
+	}
+}
@@ -0,0 +1,204 @@
+package reservation
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+)
+
+// Invoice is issued once for the amount charged for reservations, prices include the tax
+type Invoice struct {
+	InvoiceID string `json:"invoice_id"`
+	Number    string `json:"number"`
+	Kind      string `json:"kind"`
+	UserID    string `json:"user_id"`
+	Period    string `json:"period,omitempty"`
+
+	// CreditedInvoiceID and CreditedNumber are the invoice a credit note corrects
+	CreditedInvoiceID string `json:"credited_invoice_id,omitempty"`
+	CreditedNumber    string `json:"credited_number,omitempty"`
+
+	Seller   Party         `json:"seller"`
+	Buyer    Party         `json:"buyer"`
+	Currency string        `json:"currency"`
+	Lines    []InvoiceLine `json:"lines"`
+	Taxes    []TaxLine     `json:"taxes"`
+	Net      float64       `json:"net"`
+	Tax      float64       `json:"tax"`
+	Total    float64       `json:"total"`
+	IssuedAt time.Time     `json:"issued_at"`
+}
+
+type Party struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	TaxID   string `json:"tax_id,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// SpotDetails describe the spot of an invoice line as it was when the invoice was issued
+type SpotDetails struct {
+	SpotID    string  `json:"spot_id"`
+	LotID     string  `json:"lot_id,omitempty"`
+	Size      string  `json:"size,omitempty"`
+	Type      string  `json:"type,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+}
+
+type InvoiceLine struct {
+	ReservationID string      `json:"reservation_id"`
+	Description   string      `json:"description"`
+	Spot          SpotDetails `json:"spot"`
+	StartTime     time.Time   `json:"start_time"`
+	EndTime       time.Time   `json:"end_time"`
+	Net           float64     `json:"net"`
+	Tax           float64     `json:"tax"`
+	Total         float64     `json:"total"`
+}
+
+type TaxLine struct {
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"`
+	Net  float64 `json:"net"`
+	Tax  float64 `json:"tax"`
+}
+
+// IssueInvoice invoices the reservation, or returns its invoice if it was issued already
+func (rs *ReservationService) IssueInvoice(ctx context.Context, reservationID string, buyer Party, spot SpotDetails) (Invoice, error) {
+	resp, err := rs.client.IssueInvoice(ctx, &reservationpb.IssueInvoiceRequest{
+		ReservationId: reservationID,
+		Buyer:         partyToProto(buyer),
+		Spot:          spotDetailsToProto(spot),
+	})
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return invoiceFromProto(resp), nil
+}
+
+// IssueMonthlyInvoice consolidates the charges of the user in the month that aren't invoiced yet
+func (rs *ReservationService) IssueMonthlyInvoice(ctx context.Context, userID, period string, buyer Party, spots []SpotDetails) (Invoice, error) {
+	req := &reservationpb.IssueMonthlyInvoiceRequest{
+		UserId: userID,
+		Period: period,
+		Buyer:  partyToProto(buyer),
+	}
+	for _, spot := range spots {
+		req.Spots = append(req.Spots, spotDetailsToProto(spot))
+	}
+
+	resp, err := rs.client.IssueMonthlyInvoice(ctx, req)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return invoiceFromProto(resp), nil
+}
+
+func (rs *ReservationService) GetInvoice(ctx context.Context, invoiceID string) (Invoice, error) {
+	resp, err := rs.client.GetInvoice(ctx, &reservationpb.GetInvoiceRequest{InvoiceId: invoiceID})
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return invoiceFromProto(resp), nil
+}
+
+func (rs *ReservationService) GetReservationInvoice(ctx context.Context, reservationID string) (Invoice, error) {
+	resp, err := rs.client.GetReservationInvoice(ctx, &reservationpb.GetReservationInvoiceRequest{ReservationId: reservationID})
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return invoiceFromProto(resp), nil
+}
+
+// ListInvoices returns the invoices newest first, empty filters match any
+func (rs *ReservationService) ListInvoices(ctx context.Context, userID, period string, limit int) ([]Invoice, error) {
+	resp, err := rs.client.ListInvoices(ctx, &reservationpb.ListInvoicesRequest{
+		UserId: userID,
+		Period: period,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]Invoice, 0, len(resp.GetItems()))
+	for _, invoice := range resp.GetItems() {
+		invoices = append(invoices, invoiceFromProto(invoice))
+	}
+	return invoices, nil
+}
+
+func invoiceFromProto(invoice *reservationpb.Invoice) Invoice {
+	resp := Invoice{
+		InvoiceID: invoice.GetInvoiceId(),
+		Number:    invoice.GetNumber(),
+		Kind:      invoice.GetKind(),
+		UserID:    invoice.GetUserId(),
+		Period:    invoice.GetPeriod(),
+		Seller:    partyFromProto(invoice.GetSeller()),
+		Buyer:     partyFromProto(invoice.GetBuyer()),
+		Currency:  invoice.GetCurrency(),
+		Lines:     make([]InvoiceLine, 0, len(invoice.GetLines())),
+		Taxes:     make([]TaxLine, 0, len(invoice.GetTaxes())),
+		Net:       invoice.GetNet(),
+		Tax:       invoice.GetTax(),
+		Total:     invoice.GetTotal(),
+		IssuedAt:  invoice.GetIssuedAt().AsTime(),
+
+		CreditedInvoiceID: invoice.GetCreditedInvoiceId(),
+		CreditedNumber:    invoice.GetCreditedNumber(),
+	}
+	for _, line := range invoice.GetLines() {
+		resp.Lines = append(resp.Lines, InvoiceLine{
+			ReservationID: line.GetReservationId(),
+			Description:   line.GetDescription(),
+			Spot:          spotDetailsFromProto(line.GetSpot()),
+			StartTime:     line.GetStartTime().AsTime(),
+			EndTime:       line.GetEndTime().AsTime(),
+			Net:           line.GetNet(),
+			Tax:           line.GetTax(),
+			Total:         line.GetTotal(),
+		})
+	}
+	for _, tax := range invoice.GetTaxes() {
+		resp.Taxes = append(resp.Taxes, TaxLine{Name: tax.GetName(), Rate: tax.GetRate(), Net: tax.GetNet(), Tax: tax.GetTax()})
+	}
+
+	return resp
+}
+
+func partyToProto(party Party) *reservationpb.Party {
+	return &reservationpb.Party{Name: party.Name, Email: party.Email, TaxId: party.TaxID, Address: party.Address}
+}
+
+func partyFromProto(party *reservationpb.Party) Party {
+	return Party{Name: party.GetName(), Email: party.GetEmail(), TaxID: party.GetTaxId(), Address: party.GetAddress()}
+}
+
+func spotDetailsToProto(spot SpotDetails) *reservationpb.SpotDetails {
+	return &reservationpb.SpotDetails{
+		SpotId:    spot.SpotID,
+		LotId:     spot.LotID,
+		Size:      spot.Size,
+		Type:      spot.Type,
+		Latitude:  spot.Latitude,
+		Longitude: spot.Longitude,
+	}
+}
+
+func spotDetailsFromProto(spot *reservationpb.SpotDetails) SpotDetails {
+	return SpotDetails{
+		SpotID:    spot.GetSpotId(),
+		LotID:     spot.GetLotId(),
+		Size:      spot.GetSize(),
+		Type:      spot.GetType(),
+		Latitude:  spot.GetLatitude(),
+		Longitude: spot.GetLongitude(),
+	}
+}
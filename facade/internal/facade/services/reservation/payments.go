@@ -0,0 +1,65 @@
+package reservation
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+)
+
+// Payment collects the price of a reservation, the amounts are in the minor unit of the currency
+type Payment struct {
+	PaymentID      string    `json:"payment_id"`
+	ReservationID  string    `json:"reservation_id"`
+	Provider       string    `json:"provider"`
+	ProviderRef    string    `json:"provider_ref,omitempty"`
+	Status         string    `json:"status"`
+	Currency       string    `json:"currency"`
+	Amount         int64     `json:"amount"`
+	AmountCaptured int64     `json:"amount_captured"`
+	AmountRefunded int64     `json:"amount_refunded"`
+	ClientSecret   string    `json:"client_secret,omitempty"`
+	FailureReason  string    `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (rs *ReservationService) GetPayment(ctx context.Context, reservationID string) (Payment, error) {
+	resp, err := rs.client.GetReservationPayment(ctx, &reservationpb.GetReservationPaymentRequest{ReservationId: reservationID})
+	if err != nil {
+		return Payment{}, err
+	}
+
+	return paymentFromProto(resp), nil
+}
+
+// HandlePaymentCallback passes a callback of the payment provider on as it was received,
+// the reservation service verifies its signature
+func (rs *ReservationService) HandlePaymentCallback(ctx context.Context, header http.Header, body []byte) error {
+	headers := make(map[string]string, len(header))
+	for name := range header {
+		headers[name] = header.Get(name)
+	}
+
+	_, err := rs.client.HandlePaymentCallback(ctx, &reservationpb.PaymentCallbackRequest{Body: body, Headers: headers})
+	return err
+}
+
+func paymentFromProto(payment *reservationpb.Payment) Payment {
+	return Payment{
+		PaymentID:      payment.GetPaymentId(),
+		ReservationID:  payment.GetReservationId(),
+		Provider:       payment.GetProvider(),
+		ProviderRef:    payment.GetProviderRef(),
+		Status:         payment.GetStatus(),
+		Currency:       payment.GetCurrency(),
+		Amount:         payment.GetAmount(),
+		AmountCaptured: payment.GetAmountCaptured(),
+		AmountRefunded: payment.GetAmountRefunded(),
+		ClientSecret:   payment.GetClientSecret(),
+		FailureReason:  payment.GetFailureReason(),
+		CreatedAt:      payment.GetCreatedAt().AsTime(),
+		UpdatedAt:      payment.GetUpdatedAt().AsTime(),
+	}
+}
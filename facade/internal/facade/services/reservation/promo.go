@@ -0,0 +1,179 @@
+package reservation
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PromoRestrictions limit the reservations a code applies to, empty fields don't restrict
+type PromoRestrictions struct {
+	LotIDs             []string `json:"lot_ids,omitempty"`
+	SpotTypes          []string `json:"spot_types,omitempty"`
+	MinDurationMinutes int      `json:"min_duration_minutes,omitempty"`
+	FirstBookingOnly   bool     `json:"first_booking_only,omitempty"`
+}
+
+// PromoCode discounts reservations by a percent or a fixed amount, zero limits mean no limit
+type PromoCode struct {
+	Code           string            `json:"code"`
+	Description    string            `json:"description,omitempty"`
+	Type           string            `json:"type"`
+	Value          float64           `json:"value"`
+	ValidFrom      *time.Time        `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time        `json:"valid_until,omitempty"`
+	MaxRedemptions int               `json:"max_redemptions,omitempty"`
+	MaxPerUser     int               `json:"max_per_user,omitempty"`
+	Restrictions   PromoRestrictions `json:"restrictions"`
+	Redemptions    int               `json:"redemptions"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// Discount is recorded on a reservation booked with a promo code
+type Discount struct {
+	Code      string  `json:"code"`
+	ListPrice float64 `json:"list_price"`
+	Amount    float64 `json:"amount"`
+}
+
+// PromoQuoteInput is the booking the code is applied to, Price is its list price
+type PromoQuoteInput struct {
+	Code      string
+	UserID    string
+	LotID     string
+	SpotType  string
+	StartTime time.Time
+	EndTime   time.Time
+	Price     float64
+}
+
+type PromoQuote struct {
+	Code      string
+	ListPrice float64
+	Discount  float64
+	Price     float64
+}
+
+// AddPromoCode ignores the redemptions and creation time of the code
+func (rs *ReservationService) AddPromoCode(ctx context.Context, promo PromoCode) (PromoCode, error) {
+	req := &reservationpb.PromoCode{
+		Code:           promo.Code,
+		Description:    promo.Description,
+		Type:           promo.Type,
+		Value:          promo.Value,
+		MaxRedemptions: int32(promo.MaxRedemptions),
+		MaxPerUser:     int32(promo.MaxPerUser),
+		Restrictions: &reservationpb.PromoRestrictions{
+			LotIds:             promo.Restrictions.LotIDs,
+			SpotTypes:          promo.Restrictions.SpotTypes,
+			MinDurationMinutes: int32(promo.Restrictions.MinDurationMinutes),
+			FirstBookingOnly:   promo.Restrictions.FirstBookingOnly,
+		},
+	}
+	if promo.ValidFrom != nil {
+		req.ValidFrom = timestamppb.New(*promo.ValidFrom)
+	}
+	if promo.ValidUntil != nil {
+		req.ValidUntil = timestamppb.New(*promo.ValidUntil)
+	}
+
+	resp, err := rs.client.AddPromoCode(ctx, req)
+	if err != nil {
+		return PromoCode{}, err
+	}
+
+	return promoCodeFromProto(resp), nil
+}
+
+func (rs *ReservationService) GetPromoCodes(ctx context.Context) ([]PromoCode, error) {
+	resp, err := rs.client.ListPromoCodes(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]PromoCode, 0, len(resp.GetItems()))
+	for _, promo := range resp.GetItems() {
+		codes = append(codes, promoCodeFromProto(promo))
+	}
+	return codes, nil
+}
+
+func (rs *ReservationService) GetPromoCode(ctx context.Context, code string) (PromoCode, error) {
+	resp, err := rs.client.GetPromoCode(ctx, &reservationpb.PromoCodeRequest{Code: code})
+	if err != nil {
+		return PromoCode{}, err
+	}
+
+	return promoCodeFromProto(resp), nil
+}
+
+func (rs *ReservationService) DeletePromoCode(ctx context.Context, code string) error {
+	_, err := rs.client.DeletePromoCode(ctx, &reservationpb.PromoCodeRequest{Code: code})
+	return err
+}
+
+// QuotePromoCode prices the booking with the code without redeeming it
+func (rs *ReservationService) QuotePromoCode(ctx context.Context, input PromoQuoteInput) (PromoQuote, error) {
+	resp, err := rs.client.QuotePromoCode(ctx, &reservationpb.QuotePromoCodeRequest{
+		Code:      input.Code,
+		UserId:    input.UserID,
+		LotId:     input.LotID,
+		SpotType:  input.SpotType,
+		StartTime: toTimestamp(input.StartTime),
+		EndTime:   toTimestamp(input.EndTime),
+		Price:     input.Price,
+	})
+	if err != nil {
+		return PromoQuote{}, err
+	}
+
+	return PromoQuote{
+		Code:      resp.GetCode(),
+		ListPrice: resp.GetListPrice(),
+		Discount:  resp.GetDiscount(),
+		Price:     resp.GetPrice(),
+	}, nil
+}
+
+func promoCodeFromProto(promo *reservationpb.PromoCode) PromoCode {
+	resp := PromoCode{
+		Code:           promo.GetCode(),
+		Description:    promo.GetDescription(),
+		Type:           promo.GetType(),
+		Value:          promo.GetValue(),
+		MaxRedemptions: int(promo.GetMaxRedemptions()),
+		MaxPerUser:     int(promo.GetMaxPerUser()),
+		Restrictions: PromoRestrictions{
+			LotIDs:             promo.GetRestrictions().GetLotIds(),
+			SpotTypes:          promo.GetRestrictions().GetSpotTypes(),
+			MinDurationMinutes: int(promo.GetRestrictions().GetMinDurationMinutes()),
+			FirstBookingOnly:   promo.GetRestrictions().GetFirstBookingOnly(),
+		},
+		Redemptions: int(promo.GetRedemptions()),
+		CreatedAt:   promo.GetCreatedAt().AsTime(),
+	}
+	if promo.ValidFrom != nil {
+		validFrom := promo.GetValidFrom().AsTime()
+		resp.ValidFrom = &validFrom
+	}
+	if promo.ValidUntil != nil {
+		validUntil := promo.GetValidUntil().AsTime()
+		resp.ValidUntil = &validUntil
+	}
+	return resp
+}
+
+func discountFromProto(discount *reservationpb.Discount) *Discount {
+	if discount == nil {
+		return nil
+	}
+
+	return &Discount{
+		Code:      discount.GetCode(),
+		ListPrice: discount.GetListPrice(),
+		Amount:    discount.GetAmount(),
+	}
+}
@@ -1,135 +1,360 @@
 package reservation
 
 import (
-	"bytes"
-	"net/http"
+	"context"
+	"time"
 
 	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
-	"github.com/ciameksw/reserve-park/facade/internal/facade/httpclient"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/grpcclient"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+type Reservation struct {
+	ID            string    `json:"id,omitempty"`
+	ReservationID string    `json:"reservation_id"`
+	UserID        string    `json:"user_id"`
+	SpotID        string    `json:"spot_id"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Status        string    `json:"status"`
+	PricePaid     float64   `json:"price_paid"`
+	VehicleID     string    `json:"vehicle_id,omitempty"`
+	LicensePlate  string    `json:"license_plate,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Cancellation is set when the reservation was canceled under a cancellation policy
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+
+	// Discount is set when the reservation was booked with a promo code
+	Discount *Discount `json:"discount,omitempty"`
+}
+
+type AddReservationInput struct {
+	UserID       string
+	SpotID       string
+	StartTime    time.Time
+	EndTime      time.Time
+	Status       string
+	PricePaid    float64
+	VehicleID    string
+	LicensePlate string
+
+	// PaymentMethod is required while the reservation service takes payments
+	PaymentMethod string
+
+	// PromoCode discounts PricePaid, its restrictions apply to the LotID and SpotType of the spot
+	PromoCode string
+	LotID     string
+	SpotType  string
+
+	// Discount is the discount of PromoCode already quoted, the code is only redeemed
+	Discount *float64
+}
+
+// EditReservationInput leaves nil and empty fields unchanged
+type EditReservationInput struct {
+	ReservationID string
+	UserID        string
+	SpotID        string
+	StartTime     *time.Time
+	EndTime       *time.Time
+	Status        string
+	PricePaid     *float64
+	VehicleID     string
+	LicensePlate  string
+}
+
+type Filter struct {
+	UserID string
+	SpotID string
+	Status string
+	From   *time.Time
+	To     *time.Time
+}
+
+type AssignCandidate struct {
+	SpotID    string
+	PricePaid float64
+}
+
+type AssignInput struct {
+	UserID       string
+	StartTime    time.Time
+	EndTime      time.Time
+	Candidates   []AssignCandidate
+	Strategy     string
+	VehicleID    string
+	LicensePlate string
+
+	// PaymentMethod is required while the reservation service takes payments
+	PaymentMethod string
+}
+
+type TimelineInput struct {
+	SpotIDs     []string
+	From        time.Time
+	To          time.Time
+	Granularity time.Duration
+	MinFree     time.Duration
+}
+
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type SpotTimeline struct {
+	SpotID string     `json:"spot_id"`
+	Busy   []Interval `json:"busy"`
+	Free   []Interval `json:"free"`
+}
+
 type ReservationService struct {
-	ReservationURL string
+	conn   *grpc.ClientConn
+	client reservationpb.ReservationServiceClient
+	health healthpb.HealthClient
 }
 
-func NewReservationService(cfg *config.Config) *ReservationService {
-	return &ReservationService{
-		ReservationURL: cfg.ReservationURL,
+func NewReservationService(cfg *config.Config) (*ReservationService, error) {
+	conn, err := grpcclient.Dial("reservation", cfg.ReservationAddr, cfg)
+	if err != nil {
+		return nil, err
 	}
+
+	return &ReservationService{
+		conn:   conn,
+		client: reservationpb.NewReservationServiceClient(conn),
+		health: healthpb.NewHealthClient(conn),
+	}, nil
+}
+
+func (rs *ReservationService) Close() error {
+	return rs.conn.Close()
 }
 
-func (rs *ReservationService) GetAll(r *http.Request) (*http.Response, error) {
-	ct := r.Header.Get("Content-Type")
-	params := httpclient.RequestParams{
-		URL:         rs.ReservationURL + "/reservations",
-		Method:      r.Method,
-		Body:        r.Body,
-		ContentType: &ct,
+func (rs *ReservationService) GetAll(ctx context.Context, filter Filter, opts services.ListOptions) (services.Page[Reservation], error) {
+	req := &reservationpb.ListReservationsRequest{
+		UserId: filter.UserID,
+		SpotId: filter.SpotID,
+		Status: filter.Status,
+		Options: &reservationpb.ListOptions{
+			Limit:        opts.Limit,
+			Cursor:       opts.Cursor,
+			Sort:         opts.Sort,
+			Order:        opts.Order,
+			IncludeTotal: opts.IncludeTotal,
+		},
 	}
-	resp, err := httpclient.SendRequest(params)
+	if filter.From != nil {
+		req.From = timestamppb.New(*filter.From)
+	}
+	if filter.To != nil {
+		req.To = timestamppb.New(*filter.To)
+	}
+
+	resp, err := rs.client.ListReservations(ctx, req)
 	if err != nil {
-		return nil, err
+		return services.Page[Reservation]{}, err
 	}
 
-	return resp, nil
+	page := services.Page[Reservation]{
+		Items:      make([]Reservation, 0, len(resp.GetItems())),
+		NextCursor: resp.GetNextCursor(),
+		TotalCount: resp.TotalCount,
+	}
+	for _, reservation := range resp.GetItems() {
+		page.Items = append(page.Items, reservationFromProto(reservation))
+	}
+
+	return page, nil
 }
 
-func (rs *ReservationService) DeleteReservation(reservationID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    rs.ReservationURL + "/reservations/" + reservationID,
-		Method: http.MethodDelete,
+func (rs *ReservationService) Get(ctx context.Context, reservationID string) (Reservation, error) {
+	resp, err := rs.client.GetReservation(ctx, &reservationpb.GetReservationRequest{ReservationId: reservationID})
+	if err != nil {
+		return Reservation{}, err
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	return reservationFromProto(resp), nil
+}
+
+func (rs *ReservationService) Delete(ctx context.Context, reservationID string) error {
+	_, err := rs.client.DeleteReservation(ctx, &reservationpb.DeleteReservationRequest{ReservationId: reservationID})
+	return err
+}
+
+func (rs *ReservationService) Add(ctx context.Context, input AddReservationInput) (string, error) {
+	resp, err := rs.client.AddReservation(ctx, &reservationpb.AddReservationRequest{
+		UserId:       input.UserID,
+		SpotId:       input.SpotID,
+		StartTime:    toTimestamp(input.StartTime),
+		EndTime:      toTimestamp(input.EndTime),
+		Status:       input.Status,
+		PricePaid:    input.PricePaid,
+		VehicleId:    input.VehicleID,
+		LicensePlate: input.LicensePlate,
+
+		PaymentMethod: input.PaymentMethod,
+
+		PromoCode: input.PromoCode,
+		LotId:     input.LotID,
+		SpotType:  input.SpotType,
+		Discount:  input.Discount,
+	})
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return resp, nil
+	return resp.GetReservationId(), nil
 }
 
-func (rs *ReservationService) GetReservationsBySpot(spotID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    rs.ReservationURL + "/reservations/spot/" + spotID,
-		Method: http.MethodGet,
+func (rs *ReservationService) Edit(ctx context.Context, input EditReservationInput) error {
+	req := &reservationpb.EditReservationRequest{
+		ReservationId: input.ReservationID,
+		UserId:        input.UserID,
+		SpotId:        input.SpotID,
+		Status:        input.Status,
+		PricePaid:     input.PricePaid,
+		VehicleId:     input.VehicleID,
+		LicensePlate:  input.LicensePlate,
+	}
+	if input.StartTime != nil {
+		req.StartTime = timestamppb.New(*input.StartTime)
+	}
+	if input.EndTime != nil {
+		req.EndTime = timestamppb.New(*input.EndTime)
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	_, err := rs.client.EditReservation(ctx, req)
+	return err
+}
+
+// CheckAvailability returns the IDs of the spots that are free for the whole timeframe
+func (rs *ReservationService) CheckAvailability(ctx context.Context, spotIDs []string, start, end time.Time) ([]string, error) {
+	resp, err := rs.client.CheckAvailability(ctx, &reservationpb.CheckAvailabilityRequest{
+		SpotIds:   spotIDs,
+		StartTime: toTimestamp(start),
+		EndTime:   toTimestamp(end),
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return resp, nil
+	available := resp.GetSpotIds()
+	if available == nil {
+		available = []string{}
+	}
+	return available, nil
 }
 
-func (rs *ReservationService) GetReservationsByUser(userID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    rs.ReservationURL + "/reservations/user/" + userID,
-		Method: http.MethodGet,
-	}
-	resp, err := httpclient.SendRequest(params)
+// GetActiveReservation returns the valid reservation of the plate on the spot right now
+func (rs *ReservationService) GetActiveReservation(ctx context.Context, spotID, licensePlate string) (Reservation, error) {
+	resp, err := rs.client.GetActiveReservation(ctx, &reservationpb.GetActiveReservationRequest{
+		SpotId:       spotID,
+		LicensePlate: licensePlate,
+	})
 	if err != nil {
-		return nil, err
+		return Reservation{}, err
 	}
 
-	return resp, nil
+	return reservationFromProto(resp), nil
 }
 
-func (rs *ReservationService) Get(reservationID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    rs.ReservationURL + "/reservations/" + reservationID,
-		Method: http.MethodGet,
+func (rs *ReservationService) Assign(ctx context.Context, input AssignInput) (Reservation, error) {
+	req := &reservationpb.AssignReservationRequest{
+		UserId:       input.UserID,
+		StartTime:    toTimestamp(input.StartTime),
+		EndTime:      toTimestamp(input.EndTime),
+		Strategy:     input.Strategy,
+		VehicleId:    input.VehicleID,
+		LicensePlate: input.LicensePlate,
+
+		PaymentMethod: input.PaymentMethod,
 	}
-	resp, err := httpclient.SendRequest(params)
+	for _, candidate := range input.Candidates {
+		req.Candidates = append(req.Candidates, &reservationpb.AssignCandidate{
+			SpotId:    candidate.SpotID,
+			PricePaid: candidate.PricePaid,
+		})
+	}
+
+	resp, err := rs.client.AssignReservation(ctx, req)
 	if err != nil {
-		return nil, err
+		return Reservation{}, err
 	}
 
-	return resp, nil
+	return reservationFromProto(resp), nil
 }
 
-func (rs *ReservationService) Add(body []byte) (*http.Response, error) {
-	ct := "application/json"
-	params := httpclient.RequestParams{
-		URL:         rs.ReservationURL + "/reservations",
-		Method:      http.MethodPost,
-		Body:        bytes.NewBuffer(body),
-		ContentType: &ct,
-	}
-	resp, err := httpclient.SendRequest(params)
+func (rs *ReservationService) GetTimeline(ctx context.Context, input TimelineInput) ([]SpotTimeline, error) {
+	resp, err := rs.client.GetTimeline(ctx, &reservationpb.GetTimelineRequest{
+		SpotIds:     input.SpotIDs,
+		From:        toTimestamp(input.From),
+		To:          toTimestamp(input.To),
+		Granularity: durationpb.New(input.Granularity),
+		MinFree:     durationpb.New(input.MinFree),
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return resp, nil
+	timelines := make([]SpotTimeline, 0, len(resp.GetTimelines()))
+	for _, timeline := range resp.GetTimelines() {
+		timelines = append(timelines, SpotTimeline{
+			SpotID: timeline.GetSpotId(),
+			Busy:   intervalsFromProto(timeline.GetBusy()),
+			Free:   intervalsFromProto(timeline.GetFree()),
+		})
+	}
+
+	return timelines, nil
 }
 
-func (rs *ReservationService) Edit(body []byte) (*http.Response, error) {
-	ct := "application/json"
-	params := httpclient.RequestParams{
-		URL:         rs.ReservationURL + "/reservations",
-		Method:      http.MethodPatch,
-		Body:        bytes.NewBuffer(body),
-		ContentType: &ct,
-	}
-	resp, err := httpclient.SendRequest(params)
+// CheckHealth asks the standard gRPC health service whether the service is serving
+func (rs *ReservationService) CheckHealth(ctx context.Context) (bool, error) {
+	resp, err := rs.health.Check(ctx, &healthpb.HealthCheckRequest{})
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	return resp, nil
+	return resp.GetStatus() == healthpb.HealthCheckResponse_SERVING, nil
 }
 
-func (rs *ReservationService) CheckAvailability(body []byte) (*http.Response, error) {
-	ct := "application/json"
-	params := httpclient.RequestParams{
-		URL:         rs.ReservationURL + "/reservations/availability/check",
-		Method:      http.MethodGet,
-		Body:        bytes.NewBuffer(body),
-		ContentType: &ct,
+func reservationFromProto(reservation *reservationpb.Reservation) Reservation {
+	return Reservation{
+		ID:            reservation.GetId(),
+		ReservationID: reservation.GetReservationId(),
+		UserID:        reservation.GetUserId(),
+		SpotID:        reservation.GetSpotId(),
+		StartTime:     reservation.GetStartTime().AsTime(),
+		EndTime:       reservation.GetEndTime().AsTime(),
+		Status:        reservation.GetStatus(),
+		PricePaid:     reservation.GetPricePaid(),
+		VehicleID:     reservation.GetVehicleId(),
+		LicensePlate:  reservation.GetLicensePlate(),
+		UpdatedAt:     reservation.GetUpdatedAt().AsTime(),
+		Cancellation:  cancellationFromProto(reservation.GetCancellation()),
+		Discount:      discountFromProto(reservation.GetDiscount()),
 	}
-	resp, err := httpclient.SendRequest(params)
-	if err != nil {
-		return nil, err
+}
+
+func intervalsFromProto(intervals []*reservationpb.Interval) []Interval {
+	resp := make([]Interval, 0, len(intervals))
+	for _, i := range intervals {
+		resp = append(resp, Interval{Start: i.GetStart().AsTime(), End: i.GetEnd().AsTime()})
 	}
+	return resp
+}
 
-	return resp, nil
+// toTimestamp sends a zero time as a missing timestamp, so that the service reports it as required
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
 }
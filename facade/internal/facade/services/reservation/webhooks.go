@@ -0,0 +1,164 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/reservationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Webhook is a subscription of an external system to events, its secret is never returned
+type Webhook struct {
+	WebhookID  string    `json:"webhook_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AddWebhookInput struct {
+	URL        string
+	EventTypes []string
+	Secret     string
+}
+
+type Event struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Source      string          `json:"source"`
+	AggregateID string          `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Delivery is one event on its way to a webhook, a dead delivery is only retried when redelivered
+type Delivery struct {
+	DeliveryID    string            `json:"delivery_id"`
+	WebhookID     string            `json:"webhook_id"`
+	Event         Event             `json:"event"`
+	Status        string            `json:"status"`
+	Attempts      []DeliveryAttempt `json:"attempts"`
+	Failures      int               `json:"failures"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+func (rs *ReservationService) AddWebhook(ctx context.Context, input AddWebhookInput) (Webhook, error) {
+	resp, err := rs.client.AddWebhook(ctx, &reservationpb.AddWebhookRequest{
+		Url:        input.URL,
+		EventTypes: input.EventTypes,
+		Secret:     input.Secret,
+	})
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	return webhookFromProto(resp), nil
+}
+
+func (rs *ReservationService) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	resp, err := rs.client.ListWebhooks(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, 0, len(resp.GetItems()))
+	for _, webhook := range resp.GetItems() {
+		webhooks = append(webhooks, webhookFromProto(webhook))
+	}
+	return webhooks, nil
+}
+
+func (rs *ReservationService) GetWebhook(ctx context.Context, webhookID string) (Webhook, error) {
+	resp, err := rs.client.GetWebhook(ctx, &reservationpb.GetWebhookRequest{WebhookId: webhookID})
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	return webhookFromProto(resp), nil
+}
+
+func (rs *ReservationService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	_, err := rs.client.DeleteWebhook(ctx, &reservationpb.DeleteWebhookRequest{WebhookId: webhookID})
+	return err
+}
+
+// GetDeliveries returns the newest deliveries of the webhook, a zero limit picks the default
+func (rs *ReservationService) GetDeliveries(ctx context.Context, webhookID, status string, limit int64) ([]Delivery, error) {
+	resp, err := rs.client.ListDeliveries(ctx, &reservationpb.ListDeliveriesRequest{
+		WebhookId: webhookID,
+		Status:    status,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]Delivery, 0, len(resp.GetItems()))
+	for _, delivery := range resp.GetItems() {
+		deliveries = append(deliveries, deliveryFromProto(delivery))
+	}
+	return deliveries, nil
+}
+
+// Redeliver makes the delivery due right away with a fresh retry budget
+func (rs *ReservationService) Redeliver(ctx context.Context, webhookID, deliveryID string) (Delivery, error) {
+	resp, err := rs.client.Redeliver(ctx, &reservationpb.RedeliverRequest{
+		WebhookId:  webhookID,
+		DeliveryId: deliveryID,
+	})
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	return deliveryFromProto(resp), nil
+}
+
+func webhookFromProto(webhook *reservationpb.Webhook) Webhook {
+	return Webhook{
+		WebhookID:  webhook.GetWebhookId(),
+		URL:        webhook.GetUrl(),
+		EventTypes: webhook.GetEventTypes(),
+		CreatedAt:  webhook.GetCreatedAt().AsTime(),
+	}
+}
+
+func deliveryFromProto(delivery *reservationpb.Delivery) Delivery {
+	event := delivery.GetEvent()
+	resp := Delivery{
+		DeliveryID: delivery.GetDeliveryId(),
+		WebhookID:  delivery.GetWebhookId(),
+		Event: Event{
+			ID:          event.GetId(),
+			Type:        event.GetType(),
+			Source:      event.GetSource(),
+			AggregateID: event.GetAggregateId(),
+			OccurredAt:  event.GetOccurredAt().AsTime(),
+			Payload:     event.GetPayload(),
+		},
+		Status:        delivery.GetStatus(),
+		Attempts:      make([]DeliveryAttempt, 0, len(delivery.GetAttempts())),
+		Failures:      int(delivery.GetFailures()),
+		NextAttemptAt: delivery.GetNextAttemptAt().AsTime(),
+		CreatedAt:     delivery.GetCreatedAt().AsTime(),
+		UpdatedAt:     delivery.GetUpdatedAt().AsTime(),
+	}
+	for _, attempt := range delivery.GetAttempts() {
+		resp.Attempts = append(resp.Attempts, DeliveryAttempt{
+			At:         attempt.GetAt().AsTime(),
+			StatusCode: int(attempt.GetStatusCode()),
+			Error:      attempt.GetError(),
+			DurationMS: attempt.GetDurationMs(),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,18 @@
+// Package services holds what the typed clients of the internal services share.
+package services
+
+// ListOptions are the pagination and sorting options of list calls, a zero limit picks the default
+type ListOptions struct {
+	Limit        int64
+	Cursor       string
+	Sort         string
+	Order        string
+	IncludeTotal bool
+}
+
+// Page is one page of a list call, encoded the same way the services encode it
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	TotalCount *int64 `json:"total_count,omitempty"`
+}
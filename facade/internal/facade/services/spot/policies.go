@@ -0,0 +1,78 @@
+package spot
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/spotpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CancellationPolicy is scoped to a spot or a lot, the policy resolved for a spot without
+// one of its own or of its lot has the default scope and no update time
+type CancellationPolicy struct {
+	Scope                string     `json:"scope"`
+	TargetID             string     `json:"target_id"`
+	FreeUntilHours       int        `json:"free_until_hours"`
+	LateFeePercent       float64    `json:"late_fee_percent"`
+	NonRefundableMinutes int        `json:"non_refundable_minutes"`
+	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
+}
+
+func (ss *SpotService) SetCancellationPolicy(ctx context.Context, policy CancellationPolicy) (CancellationPolicy, error) {
+	resp, err := ss.client.SetCancellationPolicy(ctx, &spotpb.CancellationPolicy{
+		Scope:                policy.Scope,
+		TargetId:             policy.TargetID,
+		FreeUntilHours:       int32(policy.FreeUntilHours),
+		LateFeePercent:       policy.LateFeePercent,
+		NonRefundableMinutes: int32(policy.NonRefundableMinutes),
+	})
+	if err != nil {
+		return CancellationPolicy{}, err
+	}
+
+	return policyFromProto(resp), nil
+}
+
+func (ss *SpotService) GetCancellationPolicies(ctx context.Context) ([]CancellationPolicy, error) {
+	resp, err := ss.client.ListCancellationPolicies(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]CancellationPolicy, 0, len(resp.GetPolicies()))
+	for _, policy := range resp.GetPolicies() {
+		policies = append(policies, policyFromProto(policy))
+	}
+	return policies, nil
+}
+
+func (ss *SpotService) DeleteCancellationPolicy(ctx context.Context, scope, targetID string) error {
+	_, err := ss.client.DeleteCancellationPolicy(ctx, &spotpb.DeleteCancellationPolicyRequest{Scope: scope, TargetId: targetID})
+	return err
+}
+
+// GetCancellationPolicy returns the policy that applies to the spot: its own, its lot's or the default one
+func (ss *SpotService) GetCancellationPolicy(ctx context.Context, spotID string) (CancellationPolicy, error) {
+	resp, err := ss.client.GetCancellationPolicy(ctx, &spotpb.GetCancellationPolicyRequest{SpotId: spotID})
+	if err != nil {
+		return CancellationPolicy{}, err
+	}
+
+	return policyFromProto(resp), nil
+}
+
+func policyFromProto(policy *spotpb.CancellationPolicy) CancellationPolicy {
+	resp := CancellationPolicy{
+		Scope:                policy.GetScope(),
+		TargetID:             policy.GetTargetId(),
+		FreeUntilHours:       int(policy.GetFreeUntilHours()),
+		LateFeePercent:       policy.GetLateFeePercent(),
+		NonRefundableMinutes: int(policy.GetNonRefundableMinutes()),
+	}
+	if policy.UpdatedAt != nil {
+		updatedAt := policy.GetUpdatedAt().AsTime()
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
@@ -1,125 +1,295 @@
 package spot
 
 import (
-	"bytes"
-	"net/http"
+	"context"
+	"time"
 
 	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
-	"github.com/ciameksw/reserve-park/facade/internal/facade/httpclient"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/grpcclient"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/spotpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+type Spot struct {
+	ID           string    `json:"id,omitempty"`
+	SpotID       string    `json:"spot_id"`
+	LotID        string    `json:"lot_id,omitempty"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	PricePerHour float64   `json:"price_per_hour"`
+	Size         string    `json:"size"`
+	Type         string    `json:"type"`
+	MaxHeightCm  int       `json:"max_height_cm,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type AddSpotInput struct {
+	LotID        string  `json:"lot_id"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	PricePerHour float64 `json:"price_per_hour"`
+	Size         string  `json:"size"`
+	Type         string  `json:"type"`
+	MaxHeightCm  int     `json:"max_height_cm"`
+}
+
+// EditSpotInput leaves nil and empty fields unchanged
+type EditSpotInput struct {
+	SpotID       string   `json:"spot_id"`
+	LotID        *string  `json:"lot_id"`
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	PricePerHour *float64 `json:"price_per_hour"`
+	Size         string   `json:"size"`
+	Type         string   `json:"type"`
+	MaxHeightCm  *int     `json:"max_height_cm"`
+}
+
+type Filter struct {
+	LotID string
+	Size  string
+	Type  string
+}
+
+type VehicleProfile struct {
+	Size     string
+	IsEV     bool
+	HeightCm int
+}
+
+type SearchInput struct {
+	LotID           string
+	Size            string
+	Type            string
+	MaxPricePerHour float64
+	Vehicle         *VehicleProfile
+	// Spots are priced for the timeframe when both times are set
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// SearchResult has the price of every spot by spot ID when the search had a timeframe
+type SearchResult struct {
+	Spots  []Spot
+	Prices map[string]float64
+}
+
+type CompatibilityResult struct {
+	Compatible   []string          `json:"compatible"`
+	Incompatible map[string]string `json:"incompatible"`
+	NotFound     []string          `json:"not_found"`
+}
+
 type SpotService struct {
-	SpotURL string
+	conn   *grpc.ClientConn
+	client spotpb.SpotServiceClient
+	health healthpb.HealthClient
 }
 
-func NewSpotService(cfg *config.Config) *SpotService {
-	return &SpotService{
-		SpotURL: cfg.SpotURL,
+func NewSpotService(cfg *config.Config) (*SpotService, error) {
+	conn, err := grpcclient.Dial("spot", cfg.SpotAddr, cfg)
+	if err != nil {
+		return nil, err
 	}
+
+	return &SpotService{
+		conn:   conn,
+		client: spotpb.NewSpotServiceClient(conn),
+		health: healthpb.NewHealthClient(conn),
+	}, nil
+}
+
+func (ss *SpotService) Close() error {
+	return ss.conn.Close()
 }
 
-func (ss *SpotService) GetSpotPrice(r *http.Request) (*http.Response, error) {
-	ct := r.Header.Get("Content-Type")
-	params := httpclient.RequestParams{
-		URL:         ss.SpotURL + "/spots/price",
-		Method:      r.Method,
-		Body:        r.Body,
-		ContentType: &ct,
+func (ss *SpotService) GetSpotPrice(ctx context.Context, spotID string, start, end time.Time) (float64, error) {
+	resp, err := ss.client.GetPrice(ctx, &spotpb.GetPriceRequest{
+		SpotId:    spotID,
+		StartTime: toTimestamp(start),
+		EndTime:   toTimestamp(end),
+	})
+	if err != nil {
+		return 0, err
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	return resp.GetPrice(), nil
+}
+
+// CheckIfSpotsExist returns the IDs of the spots that don't exist
+func (ss *SpotService) CheckIfSpotsExist(ctx context.Context, spotIDs []string) ([]string, error) {
+	resp, err := ss.client.CheckSpotsExist(ctx, &spotpb.CheckSpotsExistRequest{SpotIds: spotIDs})
 	if err != nil {
 		return nil, err
 	}
 
-	return resp, nil
+	return resp.GetNotFound(), nil
 }
 
-func (ss *SpotService) CheckIfSpotsExist(body []byte) (*http.Response, error) {
-	ct := "application/json"
-	params := httpclient.RequestParams{
-		URL:         ss.SpotURL + "/spots/exist",
-		Method:      http.MethodGet,
-		Body:        bytes.NewBuffer(body),
-		ContentType: &ct,
-	}
-	resp, err := httpclient.SendRequest(params)
+func (ss *SpotService) GetAll(ctx context.Context, filter Filter, opts services.ListOptions) (services.Page[Spot], error) {
+	resp, err := ss.client.ListSpots(ctx, &spotpb.ListSpotsRequest{
+		LotId: filter.LotID,
+		Size:  filter.Size,
+		Type:  filter.Type,
+		Options: &spotpb.ListOptions{
+			Limit:        opts.Limit,
+			Cursor:       opts.Cursor,
+			Sort:         opts.Sort,
+			Order:        opts.Order,
+			IncludeTotal: opts.IncludeTotal,
+		},
+	})
 	if err != nil {
-		return nil, err
+		return services.Page[Spot]{}, err
 	}
 
-	return resp, nil
+	page := services.Page[Spot]{
+		Items:      make([]Spot, 0, len(resp.GetItems())),
+		NextCursor: resp.GetNextCursor(),
+		TotalCount: resp.TotalCount,
+	}
+	for _, spot := range resp.GetItems() {
+		page.Items = append(page.Items, spotFromProto(spot))
+	}
+
+	return page, nil
 }
 
-func (ss *SpotService) GetAll(r *http.Request) (*http.Response, error) {
-	ct := r.Header.Get("Content-Type")
-	params := httpclient.RequestParams{
-		URL:         ss.SpotURL + "/spots",
-		Method:      r.Method,
-		Body:        r.Body,
-		ContentType: &ct,
+func (ss *SpotService) GetSpot(ctx context.Context, spotID string) (Spot, error) {
+	resp, err := ss.client.GetSpot(ctx, &spotpb.GetSpotRequest{SpotId: spotID})
+	if err != nil {
+		return Spot{}, err
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	return spotFromProto(resp), nil
+}
+
+func (ss *SpotService) DeleteSpot(ctx context.Context, spotID string) error {
+	_, err := ss.client.DeleteSpot(ctx, &spotpb.DeleteSpotRequest{SpotId: spotID})
+	return err
+}
+
+func (ss *SpotService) AddSpot(ctx context.Context, input AddSpotInput) (string, error) {
+	resp, err := ss.client.AddSpot(ctx, &spotpb.AddSpotRequest{
+		LotId:        input.LotID,
+		Latitude:     input.Latitude,
+		Longitude:    input.Longitude,
+		PricePerHour: input.PricePerHour,
+		Size:         input.Size,
+		Type:         input.Type,
+		MaxHeightCm:  int32(input.MaxHeightCm),
+	})
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return resp, nil
+	return resp.GetSpotId(), nil
 }
 
-func (ss *SpotService) GetSpot(spotID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    ss.SpotURL + "/spots/" + spotID,
-		Method: http.MethodGet,
+func (ss *SpotService) EditSpot(ctx context.Context, input EditSpotInput) error {
+	req := &spotpb.EditSpotRequest{
+		SpotId:       input.SpotID,
+		LotId:        input.LotID,
+		Latitude:     input.Latitude,
+		Longitude:    input.Longitude,
+		PricePerHour: input.PricePerHour,
+		Size:         input.Size,
+		Type:         input.Type,
 	}
-	resp, err := httpclient.SendRequest(params)
+	if input.MaxHeightCm != nil {
+		maxHeightCm := int32(*input.MaxHeightCm)
+		req.MaxHeightCm = &maxHeightCm
+	}
+
+	_, err := ss.client.EditSpot(ctx, req)
+	return err
+}
+
+func (ss *SpotService) CheckCompatibility(ctx context.Context, spotIDs []string, vehicle VehicleProfile) (CompatibilityResult, error) {
+	resp, err := ss.client.CheckCompatibility(ctx, &spotpb.CheckCompatibilityRequest{
+		SpotIds: spotIDs,
+		Vehicle: vehicleToProto(vehicle),
+	})
 	if err != nil {
-		return nil, err
+		return CompatibilityResult{}, err
 	}
 
-	return resp, nil
+	return CompatibilityResult{
+		Compatible:   resp.GetCompatible(),
+		Incompatible: resp.GetIncompatible(),
+		NotFound:     resp.GetNotFound(),
+	}, nil
 }
 
-func (ss *SpotService) DeleteSpot(spotID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    ss.SpotURL + "/spots/" + spotID,
-		Method: http.MethodDelete,
+func (ss *SpotService) SearchSpots(ctx context.Context, input SearchInput) (SearchResult, error) {
+	req := &spotpb.SearchSpotsRequest{
+		LotId:           input.LotID,
+		Size:            input.Size,
+		Type:            input.Type,
+		MaxPricePerHour: input.MaxPricePerHour,
+		StartTime:       toTimestamp(input.StartTime),
+		EndTime:         toTimestamp(input.EndTime),
 	}
-	resp, err := httpclient.SendRequest(params)
+	if input.Vehicle != nil {
+		req.Vehicle = vehicleToProto(*input.Vehicle)
+	}
+
+	resp, err := ss.client.SearchSpots(ctx, req)
 	if err != nil {
-		return nil, err
+		return SearchResult{}, err
 	}
 
-	return resp, nil
+	result := SearchResult{
+		Spots:  make([]Spot, 0, len(resp.GetSpots())),
+		Prices: resp.GetPrices(),
+	}
+	for _, spot := range resp.GetSpots() {
+		result.Spots = append(result.Spots, spotFromProto(spot))
+	}
+
+	return result, nil
 }
 
-func (ss *SpotService) AddSpot(r *http.Request) (*http.Response, error) {
-	ct := r.Header.Get("Content-Type")
-	params := httpclient.RequestParams{
-		URL:         ss.SpotURL + "/spots",
-		Method:      r.Method,
-		Body:        r.Body,
-		ContentType: &ct,
-	}
-	resp, err := httpclient.SendRequest(params)
+// CheckHealth asks the standard gRPC health service whether the service is serving
+func (ss *SpotService) CheckHealth(ctx context.Context) (bool, error) {
+	resp, err := ss.health.Check(ctx, &healthpb.HealthCheckRequest{})
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	return resp, nil
+	return resp.GetStatus() == healthpb.HealthCheckResponse_SERVING, nil
 }
 
-func (ss *SpotService) EditSpot(r *http.Request) (*http.Response, error) {
-	ct := r.Header.Get("Content-Type")
-	params := httpclient.RequestParams{
-		URL:         ss.SpotURL + "/spots",
-		Method:      r.Method,
-		Body:        r.Body,
-		ContentType: &ct,
+func spotFromProto(spot *spotpb.Spot) Spot {
+	return Spot{
+		ID:           spot.GetId(),
+		SpotID:       spot.GetSpotId(),
+		LotID:        spot.GetLotId(),
+		Latitude:     spot.GetLatitude(),
+		Longitude:    spot.GetLongitude(),
+		PricePerHour: spot.GetPricePerHour(),
+		Size:         spot.GetSize(),
+		Type:         spot.GetType(),
+		MaxHeightCm:  int(spot.GetMaxHeightCm()),
+		UpdatedAt:    spot.GetUpdatedAt().AsTime(),
 	}
-	resp, err := httpclient.SendRequest(params)
-	if err != nil {
-		return nil, err
+}
+
+func vehicleToProto(vehicle VehicleProfile) *spotpb.VehicleProfile {
+	return &spotpb.VehicleProfile{
+		Size:     vehicle.Size,
+		IsEv:     vehicle.IsEV,
+		HeightCm: int32(vehicle.HeightCm),
 	}
+}
 
-	return resp, nil
+// toTimestamp sends a zero time as a missing timestamp, so that the service reports it as required
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
 }
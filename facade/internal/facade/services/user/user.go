@@ -1,123 +1,288 @@
 package user
 
 import (
-	"bytes"
-	"net/http"
+	"context"
+	"time"
 
 	"github.com/ciameksw/reserve-park/facade/internal/facade/config"
-	"github.com/ciameksw/reserve-park/facade/internal/facade/httpclient"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/grpcclient"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/pb/userpb"
+	"github.com/ciameksw/reserve-park/facade/internal/facade/services"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+type User struct {
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Vehicles  []Vehicle `json:"vehicles,omitempty"`
+	Billing   *Billing  `json:"billing,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Billing holds the details invoices are issued to, business accounts are invoiced monthly
+type Billing struct {
+	Name     string `json:"name,omitempty"`
+	TaxID    string `json:"tax_id,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Business bool   `json:"business"`
+}
+
+type Vehicle struct {
+	VehicleID    string `json:"vehicle_id"`
+	LicensePlate string `json:"license_plate"`
+	Country      string `json:"country"`
+	Region       string `json:"region,omitempty"`
+	Make         string `json:"make,omitempty"`
+	Size         string `json:"size"`
+	IsEV         bool   `json:"is_ev"`
+	HeightCm     int    `json:"height_cm,omitempty"`
+}
+
+type AddUserInput struct {
+	Username string
+	Email    string
+	Password string
+	Role     string
+}
+
+// EditUserInput leaves empty fields unchanged, Billing replaces the billing details as a whole
+type EditUserInput struct {
+	UserID   string
+	Username string
+	Email    string
+	Password string
+	Role     string
+	Billing  *Billing
+}
+
+type Claims struct {
+	UserID string
+	Role   string
+}
+
 type UserService struct {
-	UserURL string
+	conn   *grpc.ClientConn
+	client userpb.UserServiceClient
+	health healthpb.HealthClient
 }
 
-func NewUserService(cfg *config.Config) *UserService {
-	return &UserService{
-		UserURL: cfg.UserURL,
+func NewUserService(cfg *config.Config) (*UserService, error) {
+	conn, err := grpcclient.Dial("user", cfg.UserAddr, cfg)
+	if err != nil {
+		return nil, err
 	}
+
+	return &UserService{
+		conn:   conn,
+		client: userpb.NewUserServiceClient(conn),
+		health: healthpb.NewHealthClient(conn),
+	}, nil
 }
 
-func (us *UserService) Register(body []byte) (*http.Response, error) {
-	ct := "application/json"
-	params := httpclient.RequestParams{
-		URL:         us.UserURL + "/users",
-		Method:      http.MethodPost,
-		Body:        bytes.NewBuffer(body),
-		ContentType: &ct,
-	}
-	resp, err := httpclient.SendRequest(params)
+func (us *UserService) Close() error {
+	return us.conn.Close()
+}
+
+func (us *UserService) Register(ctx context.Context, input AddUserInput) (string, error) {
+	resp, err := us.client.AddUser(ctx, &userpb.AddUserRequest{
+		Username: input.Username,
+		Email:    input.Email,
+		Password: input.Password,
+		Role:     input.Role,
+	})
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return resp, nil
+	return resp.GetUserId(), nil
 }
 
-func (us *UserService) Login(r *http.Request) (*http.Response, error) {
-	ct := r.Header.Get("Content-Type")
-	params := httpclient.RequestParams{
-		URL:         us.UserURL + "/users/login",
-		Method:      r.Method,
-		Body:        r.Body,
-		ContentType: &ct,
+func (us *UserService) Login(ctx context.Context, username, password string) (string, error) {
+	resp, err := us.client.Login(ctx, &userpb.LoginRequest{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return "", err
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	return resp.GetJwt(), nil
+}
+
+func (us *UserService) Edit(ctx context.Context, input EditUserInput) error {
+	_, err := us.client.EditUser(ctx, &userpb.EditUserRequest{
+		UserId:   input.UserID,
+		Username: input.Username,
+		Email:    input.Email,
+		Password: input.Password,
+		Role:     input.Role,
+		Billing:  billingToProto(input.Billing),
+	})
+	return err
+}
+
+func (us *UserService) GetAll(ctx context.Context, role string, opts services.ListOptions) (services.Page[User], error) {
+	resp, err := us.client.ListUsers(ctx, &userpb.ListUsersRequest{
+		Role: role,
+		Options: &userpb.ListOptions{
+			Limit:        opts.Limit,
+			Cursor:       opts.Cursor,
+			Sort:         opts.Sort,
+			Order:        opts.Order,
+			IncludeTotal: opts.IncludeTotal,
+		},
+	})
 	if err != nil {
-		return nil, err
+		return services.Page[User]{}, err
 	}
 
-	return resp, nil
+	page := services.Page[User]{
+		Items:      make([]User, 0, len(resp.GetItems())),
+		NextCursor: resp.GetNextCursor(),
+		TotalCount: resp.TotalCount,
+	}
+	for _, user := range resp.GetItems() {
+		page.Items = append(page.Items, userFromProto(user))
+	}
+
+	return page, nil
 }
 
-func (us *UserService) Edit(body []byte) (*http.Response, error) {
-	ct := "application/json"
-	params := httpclient.RequestParams{
-		URL:         us.UserURL + "/users",
-		Method:      http.MethodPatch,
-		Body:        bytes.NewBuffer(body),
-		ContentType: &ct,
+func (us *UserService) GetUser(ctx context.Context, userID string) (User, error) {
+	resp, err := us.client.GetUser(ctx, &userpb.GetUserRequest{UserId: userID})
+	if err != nil {
+		return User{}, err
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	return userFromProto(resp), nil
+}
+
+func (us *UserService) DeleteUser(ctx context.Context, userID string) error {
+	_, err := us.client.DeleteUser(ctx, &userpb.DeleteUserRequest{UserId: userID})
+	return err
+}
+
+func (us *UserService) Authorize(ctx context.Context, token string) (Claims, error) {
+	resp, err := us.client.Authorize(ctx, &userpb.AuthorizeRequest{Token: token})
 	if err != nil {
-		return nil, err
+		return Claims{}, err
 	}
 
-	return resp, nil
+	return Claims{UserID: resp.GetUserId(), Role: resp.GetRole()}, nil
 }
 
-func (us *UserService) GetAll(r *http.Request) (*http.Response, error) {
-	ct := r.Header.Get("Content-Type")
-	params := httpclient.RequestParams{
-		URL:         us.UserURL + "/users",
-		Method:      r.Method,
-		Body:        r.Body,
-		ContentType: &ct,
+func (us *UserService) AddVehicle(ctx context.Context, userID string, vehicle Vehicle) (string, error) {
+	resp, err := us.client.AddVehicle(ctx, &userpb.AddVehicleRequest{
+		UserId:       userID,
+		LicensePlate: vehicle.LicensePlate,
+		Country:      vehicle.Country,
+		Region:       vehicle.Region,
+		Make:         vehicle.Make,
+		Size:         vehicle.Size,
+		IsEv:         vehicle.IsEV,
+		HeightCm:     int32(vehicle.HeightCm),
+	})
+	if err != nil {
+		return "", err
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	return resp.GetVehicleId(), nil
+}
+
+func (us *UserService) GetVehicles(ctx context.Context, userID string) ([]Vehicle, error) {
+	resp, err := us.client.ListVehicles(ctx, &userpb.ListVehiclesRequest{UserId: userID})
 	if err != nil {
 		return nil, err
 	}
 
-	return resp, nil
+	vehicles := make([]Vehicle, 0, len(resp.GetVehicles()))
+	for _, vehicle := range resp.GetVehicles() {
+		vehicles = append(vehicles, vehicleFromProto(vehicle))
+	}
+
+	return vehicles, nil
 }
 
-func (us *UserService) GetUser(userID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    us.UserURL + "/users/" + userID,
-		Method: http.MethodGet,
+func (us *UserService) GetVehicle(ctx context.Context, userID, vehicleID string) (Vehicle, error) {
+	resp, err := us.client.GetVehicle(ctx, &userpb.GetVehicleRequest{UserId: userID, VehicleId: vehicleID})
+	if err != nil {
+		return Vehicle{}, err
 	}
-	resp, err := httpclient.SendRequest(params)
+
+	return vehicleFromProto(resp), nil
+}
+
+func (us *UserService) DeleteVehicle(ctx context.Context, userID, vehicleID string) error {
+	_, err := us.client.DeleteVehicle(ctx, &userpb.DeleteVehicleRequest{UserId: userID, VehicleId: vehicleID})
+	return err
+}
+
+// CheckHealth asks the standard gRPC health service whether the service is serving
+func (us *UserService) CheckHealth(ctx context.Context) (bool, error) {
+	resp, err := us.health.Check(ctx, &healthpb.HealthCheckRequest{})
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	return resp, nil
+	return resp.GetStatus() == healthpb.HealthCheckResponse_SERVING, nil
 }
 
-func (us *UserService) DeleteUser(userID string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:    us.UserURL + "/users/" + userID,
-		Method: http.MethodDelete,
+func userFromProto(user *userpb.User) User {
+	resp := User{
+		ID:        user.GetId(),
+		UserID:    user.GetUserId(),
+		Username:  user.GetUsername(),
+		Email:     user.GetEmail(),
+		Role:      user.GetRole(),
+		Billing:   billingFromProto(user.GetBilling()),
+		UpdatedAt: user.GetUpdatedAt().AsTime(),
 	}
-	resp, err := httpclient.SendRequest(params)
-	if err != nil {
-		return nil, err
+	for _, vehicle := range user.GetVehicles() {
+		resp.Vehicles = append(resp.Vehicles, vehicleFromProto(vehicle))
 	}
 
-	return resp, nil
+	return resp
 }
 
-func (us *UserService) Authorize(authHeader string) (*http.Response, error) {
-	params := httpclient.RequestParams{
-		URL:           us.UserURL + "/users/authorize",
-		Method:        http.MethodGet,
-		Authorization: &authHeader,
+func vehicleFromProto(vehicle *userpb.Vehicle) Vehicle {
+	return Vehicle{
+		VehicleID:    vehicle.GetVehicleId(),
+		LicensePlate: vehicle.GetLicensePlate(),
+		Country:      vehicle.GetCountry(),
+		Region:       vehicle.GetRegion(),
+		Make:         vehicle.GetMake(),
+		Size:         vehicle.GetSize(),
+		IsEV:         vehicle.GetIsEv(),
+		HeightCm:     int(vehicle.GetHeightCm()),
 	}
-	resp, err := httpclient.SendRequest(params)
-	if err != nil {
-		return nil, err
+}
+
+func billingToProto(billing *Billing) *userpb.Billing {
+	if billing == nil {
+		return nil
+	}
+
+	return &userpb.Billing{
+		Name:     billing.Name,
+		TaxId:    billing.TaxID,
+		Address:  billing.Address,
+		Business: billing.Business,
 	}
+}
 
-	return resp, nil
+func billingFromProto(billing *userpb.Billing) *Billing {
+	if billing == nil {
+		return nil
+	}
+
+	return &Billing{
+		Name:     billing.GetName(),
+		TaxID:    billing.GetTaxId(),
+		Address:  billing.GetAddress(),
+		Business: billing.GetBusiness(),
+	}
 }
@@ -0,0 +1,39 @@
+#!/bin/sh
+# Regenerates the Go code of the internal gRPC API from the .proto files.
+# Every module gets its own copy of the packages it needs, so each service
+# keeps building on its own (e.g. inside its Docker image).
+#
+# Requires protoc, protoc-gen-go v1.35 and protoc-gen-go-grpc v1.5 on the PATH.
+set -eu
+
+cd "$(dirname "$0")"
+
+# generate <module directory> <package directory> <proto files...>
+generate() {
+	module="github.com/ciameksw/reserve-park/$1"
+	dir=$2
+	shift 2
+
+	set -- "$@" --go_out="../${module##*/}" --go_opt=module="$module" \
+		--go-grpc_out="../${module##*/}" --go-grpc_opt=module="$module"
+	for file in "$@"; do
+		case $file in
+		*.proto)
+			pkg="$module/$dir/$(basename "$file" .proto)pb"
+			set -- "$@" --go_opt=M"$file=$pkg" --go-grpc_opt=M"$file=$pkg"
+			;;
+		esac
+	done
+
+	protoc -I . "$@"
+}
+
+problem=reservepark/problem/v1/problem.proto
+user=reservepark/user/v1/user.proto
+spot=reservepark/spot/v1/spot.proto
+reservation=reservepark/reservation/v1/reservation.proto
+
+generate user internal/user/pb $problem $user
+generate spot internal/spot/pb $problem $spot
+generate reservation internal/reservation/pb $problem $reservation
+generate facade internal/facade/pb $problem $user $spot $reservation
@@ -1,26 +1,196 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/availability"
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/config"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/events"
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/mongodb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/payment"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/postgres"
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/server"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/tracing"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/webhook"
 )
 
 func main() {
+	// Registered first so that it runs after all the other deferred cleanups
+	exitCode := 0
+	defer func() { os.Exit(exitCode) }()
+
 	// Get logger
 	lgr := logger.GetLogger()
 
 	// Get config
 	cfg := config.GetConfig()
 
-	// Connect to MongoDB
-	db, err := mongodb.Connect(cfg.MongoURI, "reservations")
+	// Set up tracing
+	shutdownTracing, err := tracing.Init("reservation", cfg)
+	if err != nil {
+		lgr.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Connect to the configured database
+	st, disconnect, err := openStore(cfg)
+	if err != nil {
+		lgr.Error("Failed to connect to the database", "backend", cfg.StorageBackend, "error", err)
+		os.Exit(1)
+	}
+	defer disconnect()
+
+	// Webhook deliveries are queued by the relay like publishing to a broker
+	dispatcher := webhook.NewDispatcher(lgr, st, cfg.WebhookPollInterval, cfg.WebhookTimeout, cfg.WebhookBackoff, cfg.WebhookMaxAttempts)
+
+	// Availability watchers get the changes of the reservation events
+	hub := availability.NewHub(lgr)
+
+	// Connect to the event broker
+	broker, err := openBroker(lgr, cfg, dispatcher, hub)
+	if err != nil {
+		lgr.Error("Failed to connect to the event broker", "broker", cfg.EventBroker, "error", err)
+		os.Exit(1)
+	}
+	defer broker.Close()
+
+	// Set up the payment provider, reservations are booked unpaid without one
+	payments, err := openPaymentProvider(cfg)
 	if err != nil {
-		lgr.Error.Fatalf("Failed to connect to MongoDB: %v", err)
+		lgr.Error("Failed to set up the payment provider", "provider", cfg.PaymentProvider, "error", err)
+		exitCode = 1
+		return
 	}
-	defer db.Disconnect()
 
-	s := server.NewServer(lgr, cfg, db)
-	s.Start()
+	s := server.NewServer(lgr, cfg, st)
+	s.Availability = hub
+	s.Payments = payments
+
+	// Stop gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Publish the outbox, send the webhook deliveries and settle the payments until the
+	// server stops, the broker and the store are closed only after all have returned
+	var background sync.WaitGroup
+	background.Add(2)
+	go func() {
+		defer background.Done()
+		events.NewRelay(lgr, st, broker, cfg.OutboxPollInterval).Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		dispatcher.Run(ctx)
+	}()
+	if payments != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			s.RunPayments(ctx)
+		}()
+	}
+	defer background.Wait()
+
+	if err := s.Start(ctx); err != nil {
+		lgr.Error("Server stopped unexpectedly", "error", err)
+		exitCode = 1
+		stop()
+		return
+	}
+	lgr.Info("Server stopped")
+}
+
+// openStore connects to the storage backend picked by STORAGE_BACKEND
+func openStore(cfg *config.Config) (store.ReservationStore, func(), error) {
+	switch cfg.StorageBackend {
+	case "mongodb":
+		db, err := mongodb.Connect(cfg.MongoURI, "reservations", cfg.MongoStandalone)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Disconnect, nil
+	case "postgres":
+		db, err := postgres.Connect(cfg.PostgresURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Disconnect, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+// openPaymentProvider sets up the payment provider picked by PAYMENT_PROVIDER, it's nil for none
+func openPaymentProvider(cfg *config.Config) (payment.Provider, error) {
+	switch cfg.PaymentProvider {
+	case "none":
+		return nil, nil
+	case "fake":
+		return &payment.Fake{Secret: cfg.PaymentWebhookSecret}, nil
+	case "stripe":
+		if cfg.StripeAPIKey == "" || cfg.PaymentWebhookSecret == "" {
+			return nil, fmt.Errorf("STRIPE_API_KEY and PAYMENT_WEBHOOK_SECRET are required")
+		}
+		return payment.NewStripe(cfg.StripeAPIKey, cfg.PaymentWebhookSecret, cfg.StripeAPIURL, cfg.PaymentTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.PaymentProvider)
+	}
+}
+
+// openBroker connects to the event broker picked by EVENT_BROKER. The events also go
+// to the webhook dispatcher and the availability hub, with NATS the dispatcher also
+// gets the spot service's events.
+func openBroker(lgr *logger.Logger, cfg *config.Config, dispatcher *webhook.Dispatcher, hub *availability.Hub) (events.Broker, error) {
+	switch cfg.EventBroker {
+	case "inprocess":
+		// The spot service runs in another process, only the webhooks, the watchers and the log get the events
+		broker := events.NewInProcess()
+		broker.Subscribe(func(event model.Event) {
+			lgr.Debug("Event published", "type", event.Type, "id", event.ID, "aggregate_id", event.AggregateID)
+		})
+		return events.Fanout{broker, dispatcher, hub}, nil
+	case "nats":
+		broker, err := events.NewNATS(cfg.NatsURL)
+		if err != nil {
+			return nil, err
+		}
+
+		err = broker.Subscribe("reservepark.spot.>", "reservation-webhooks", func(event model.Event) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := dispatcher.Publish(ctx, event); err != nil {
+				lgr.Error("Failed to queue webhook deliveries", "type", event.Type, "id", event.ID, "error", err)
+			}
+		})
+		if err != nil {
+			broker.Close()
+			return nil, err
+		}
+
+		// Any instance may relay an event while the watchers are spread over all of
+		// them, so every instance subscribes on its own instead of in a queue group.
+		// The hub gets the events it relays itself that way too.
+		err = broker.Subscribe("reservepark.reservation.>", "", func(event model.Event) {
+			hub.Publish(context.Background(), event)
+		})
+		if err != nil {
+			broker.Close()
+			return nil, err
+		}
+
+		return events.Fanout{broker, dispatcher}, nil
+	default:
+		return nil, fmt.Errorf("unknown event broker %q", cfg.EventBroker)
+	}
 }
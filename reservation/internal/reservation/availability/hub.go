@@ -0,0 +1,159 @@
+// Package availability streams the availability changes of spots to the clients
+// watching them. The Hub receives the reservation events like any broker and
+// passes the changes they cause on to the watchers of the affected spots.
+package availability
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+var (
+	// ErrLagging ends a watch that didn't keep up with the changes, its client should resync
+	ErrLagging = errors.New("watcher fell behind")
+	ErrClosed  = errors.New("availability hub is closed")
+)
+
+// Watch receives the changes of some spots until it's closed, by the watcher or by the hub
+type Watch struct {
+	hub     *Hub
+	spots   map[string]bool
+	changes chan model.AvailabilityChange
+	done    chan struct{}
+	err     error
+}
+
+// Changes is closed when the watch ends, Err tells why
+func (w *Watch) Changes() <-chan model.AvailabilityChange {
+	return w.changes
+}
+
+// Err is nil while the watch is open or when the watcher closed it
+func (w *Watch) Err() error {
+	select {
+	case <-w.done:
+		return w.err
+	default:
+		return nil
+	}
+}
+
+func (w *Watch) Close() {
+	w.hub.remove(w, nil)
+}
+
+// Hub implements events.Broker. It only knows the events published through it, so with
+// several instances every instance needs every reservation event, see cmd/reservation.
+type Hub struct {
+	Logger *logger.Logger
+
+	// Buffer is how many changes a watcher can be behind before its watch is ended
+	Buffer int
+
+	mu      sync.Mutex
+	watches map[*Watch]struct{}
+	closed  bool
+}
+
+func NewHub(log *logger.Logger) *Hub {
+	return &Hub{
+		Logger:  log,
+		Buffer:  64,
+		watches: make(map[*Watch]struct{}),
+	}
+}
+
+// Watch starts watching the spots
+func (h *Hub) Watch(spotIDs []string) (*Watch, error) {
+	w := &Watch{
+		hub:     h,
+		spots:   make(map[string]bool, len(spotIDs)),
+		changes: make(chan model.AvailabilityChange, h.Buffer),
+		done:    make(chan struct{}),
+	}
+	for _, id := range spotIDs {
+		w.spots[id] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+	h.watches[w] = struct{}{}
+	metrics.AvailabilityWatchers.Inc()
+
+	return w, nil
+}
+
+// Publish passes the changes caused by the event on to the watchers of their spots.
+// It never blocks on a watcher: one whose buffer is full is dropped.
+func (h *Hub) Publish(ctx context.Context, event model.Event) error {
+	changes, err := model.AvailabilityChanges(event)
+	if err != nil {
+		// A broken payload must not stop the relay, there's nothing to retry
+		h.Logger.ErrorContext(ctx, "Failed to read the availability changes", "type", event.Type, "id", event.ID, "error", err)
+		return nil
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+watches:
+	for w := range h.watches {
+		for _, change := range changes {
+			if !w.spots[change.SpotID] {
+				continue
+			}
+			select {
+			case w.changes <- change:
+			default:
+				h.removeLocked(w, ErrLagging)
+				continue watches
+			}
+		}
+	}
+	metrics.AvailabilityChanges.Add(float64(len(changes)))
+
+	return nil
+}
+
+// Close ends all the watches, e.g. so that the clients reconnect to another instance
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for w := range h.watches {
+		h.removeLocked(w, ErrClosed)
+	}
+	return nil
+}
+
+func (h *Hub) remove(w *Watch, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeLocked(w, err)
+}
+
+func (h *Hub) removeLocked(w *Watch, err error) {
+	if _, ok := h.watches[w]; !ok {
+		return
+	}
+	delete(h.watches, w)
+	metrics.AvailabilityWatchers.Dec()
+
+	w.err = err
+	close(w.done)
+	close(w.changes)
+}
@@ -0,0 +1,168 @@
+package availability
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+var start = time.Date(2025, 5, 22, 10, 0, 0, 0, time.UTC)
+
+func reservation(spotID string, hours int) model.Reservation {
+	return model.Reservation{
+		ReservationID: "r1",
+		UserID:        "u1",
+		SpotID:        spotID,
+		StartTime:     start,
+		EndTime:       start.Add(time.Duration(hours) * time.Hour),
+		Status:        model.StatusValid,
+		PricePaid:     10,
+	}
+}
+
+func publish(t *testing.T, hub *Hub, eventType string, payload any) {
+	t.Helper()
+
+	event, err := model.NewEvent(eventType, "r1", payload)
+	if err != nil {
+		t.Fatalf("Failed to build event: %v", err)
+	}
+	if err := hub.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+}
+
+// received drains the changes waiting in the watch
+func received(w *Watch) []model.AvailabilityChange {
+	var changes []model.AvailabilityChange
+	for {
+		select {
+		case change, ok := <-w.Changes():
+			if !ok {
+				return changes
+			}
+			changes = append(changes, change)
+		default:
+			return changes
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	hub := NewHub(logger.GetLogger())
+	w, err := hub.Watch([]string{"s1", "s2"})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer w.Close()
+	other, _ := hub.Watch([]string{"s3"})
+	defer other.Close()
+
+	created := reservation("s1", 1)
+	moved := reservation("s2", 2)
+	repriced := moved
+	repriced.PricePaid = 20
+	canceled := repriced
+	canceled.Status = model.StatusCanceled
+
+	publish(t, hub, model.EventReservationCreated, created)
+	publish(t, hub, model.EventReservationUpdated, model.NewReservationChanged(created, moved))
+	publish(t, hub, model.EventReservationUpdated, model.NewReservationChanged(moved, repriced))
+	publish(t, hub, model.EventReservationCanceled, model.NewReservationChanged(repriced, canceled))
+	publish(t, hub, model.EventReservationDeleted, model.ReservationDeleted{ReservationID: "r1", Slot: canceled.Slot()})
+
+	type want struct {
+		spotID string
+		change model.AvailabilityChangeType
+		hours  int
+	}
+	expected := []want{
+		{"s1", model.SlotBooked, 1},
+		{"s1", model.SlotReleased, 1},
+		{"s2", model.SlotBooked, 2},
+		{"s2", model.SlotReleased, 2},
+	}
+
+	changes := received(w)
+	if len(changes) != len(expected) {
+		t.Fatalf("got %d changes %+v, want %d", len(changes), changes, len(expected))
+	}
+	for i, change := range changes {
+		e := expected[i]
+		if change.SpotID != e.spotID || change.Change != e.change || change.ReservationID != "r1" ||
+			!change.StartTime.Equal(start) || !change.EndTime.Equal(start.Add(time.Duration(e.hours)*time.Hour)) {
+			t.Errorf("change %d = %+v, want %+v", i, change, e)
+		}
+	}
+
+	if changes := received(other); len(changes) != 0 {
+		t.Errorf("watcher of another spot got %+v", changes)
+	}
+}
+
+func TestPaymentConfirmation(t *testing.T) {
+	hub := NewHub(logger.GetLogger())
+	w, _ := hub.Watch([]string{"s1"})
+	defer w.Close()
+
+	pending := reservation("s1", 1)
+	pending.Status = model.StatusPending
+	confirmed := reservation("s1", 1)
+
+	// The pending reservation takes the spot, confirming it changes nothing
+	publish(t, hub, model.EventReservationCreated, pending)
+	publish(t, hub, model.EventReservationConfirmed, model.NewReservationChanged(pending, confirmed))
+
+	changes := received(w)
+	if len(changes) != 1 || changes[0].Change != model.SlotBooked || changes[0].EventType != model.EventReservationCreated {
+		t.Errorf("got changes %+v, want the booking of the pending reservation only", changes)
+	}
+}
+
+func TestLaggingWatcher(t *testing.T) {
+	hub := NewHub(logger.GetLogger())
+	hub.Buffer = 1
+	slow, _ := hub.Watch([]string{"s1"})
+	fast, _ := hub.Watch([]string{"s1"})
+	defer fast.Close()
+
+	publish(t, hub, model.EventReservationCreated, reservation("s1", 1))
+	received(fast)
+	publish(t, hub, model.EventReservationCreated, reservation("s1", 2))
+
+	if changes := received(slow); len(changes) != 1 {
+		t.Errorf("slow watcher got %d changes, want the one before it fell behind", len(changes))
+	}
+	if err := slow.Err(); err != ErrLagging {
+		t.Errorf("slow watcher Err() = %v, want %v", err, ErrLagging)
+	}
+	if err := fast.Err(); err != nil {
+		t.Errorf("fast watcher Err() = %v, want nil", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	hub := NewHub(logger.GetLogger())
+	w, _ := hub.Watch([]string{"s1"})
+	closed, _ := hub.Watch([]string{"s1"})
+	closed.Close()
+	closed.Close()
+
+	hub.Close()
+
+	if _, ok := <-w.Changes(); ok {
+		t.Error("watch is still open after the hub closed")
+	}
+	if err := w.Err(); err != ErrClosed {
+		t.Errorf("Err() = %v, want %v", err, ErrClosed)
+	}
+	if err := closed.Err(); err != nil {
+		t.Errorf("Err() of a watch its watcher closed = %v, want nil", err)
+	}
+	if _, err := hub.Watch([]string{"s1"}); err != ErrClosed {
+		t.Errorf("Watch() after Close() error = %v, want %v", err, ErrClosed)
+	}
+}
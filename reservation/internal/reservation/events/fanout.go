@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+// Fanout publishes every event to all its brokers. When one of them fails the
+// relay publishes the event again to all of them, so the others see it twice.
+type Fanout []Broker
+
+func (f Fanout) Publish(ctx context.Context, event model.Event) error {
+	var errs []error
+	for _, broker := range f {
+		if err := broker.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f Fanout) Close() error {
+	var errs []error
+	for _, broker := range f {
+		errs = append(errs, broker.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/nats-io/nats.go"
+)
+
+// NATS publishes events as JSON messages on their Subject. The event ID goes in
+// the Nats-Msg-Id header, so a JetStream stream on reservepark.> drops the copies
+// of an event the relay publishes again, and the aggregate ID in the Key header
+// gives Kafka bridges a partition key that keeps an aggregate's events in order.
+type NATS struct {
+	conn *nats.Conn
+}
+
+func NewNATS(url string) (*NATS, error) {
+	conn, err := nats.Connect(url, nats.Name("reservation"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATS{conn: conn}, nil
+}
+
+func (b *NATS) Publish(ctx context.Context, event model.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(Subject(event))
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Header.Set("Key", event.AggregateID)
+
+	if err := b.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	// Core NATS publishing is fire and forget, the flush round trip makes sure
+	// the server has the message before the relay marks it published
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+	}
+	return b.conn.FlushWithContext(ctx)
+}
+
+// Subscribe calls the handler with the events published on the subject, which may
+// contain wildcards. The instances of the service that share a queue group get each
+// event once between them, with an empty queue every instance gets it. Core NATS doesn't keep events for a subscriber that
+// is down, those are missed. Messages that aren't events are ignored.
+func (b *NATS) Subscribe(subject, queue string, handler func(model.Event)) error {
+	_, err := b.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
+		var event model.Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			return
+		}
+		handler(event)
+	})
+	return err
+}
+
+func (b *NATS) Close() error {
+	return b.conn.Drain()
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/memory"
+)
+
+// failingBroker accepts the first n events, then fails
+type failingBroker struct {
+	n         int
+	published []string
+}
+
+func (b *failingBroker) Publish(ctx context.Context, event model.Event) error {
+	if len(b.published) == b.n {
+		return errors.New("broker unavailable")
+	}
+	b.published = append(b.published, event.ID)
+	return nil
+}
+
+func (b *failingBroker) Close() error {
+	return nil
+}
+
+func addReservations(t *testing.T, st *memory.Store, n int) []string {
+	t.Helper()
+
+	var ids []string
+	for i := 0; i < n; i++ {
+		r := model.Reservation{ReservationID: string(rune('a' + i)), SpotID: "s1", Status: model.StatusValid}
+		event, err := model.NewEvent(model.EventReservationCreated, r.ReservationID, r)
+		if err != nil {
+			t.Fatalf("NewEvent: %v", err)
+		}
+		if err := st.AddReservation(context.Background(), r, event); err != nil {
+			t.Fatalf("AddReservation: %v", err)
+		}
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestRelayFlush(t *testing.T) {
+	st := memory.New()
+	ids := addReservations(t, st, 5)
+
+	broker := NewInProcess()
+	var got []string
+	broker.Subscribe(func(event model.Event) {
+		got = append(got, event.ID)
+	})
+
+	relay := NewRelay(logger.GetLogger(), st, broker, time.Second)
+	relay.BatchSize = 2
+
+	n, err := relay.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n != len(ids) {
+		t.Errorf("Flush published %d events, want %d", n, len(ids))
+	}
+	for i := range ids {
+		if i >= len(got) || got[i] != ids[i] {
+			t.Fatalf("published %v, want %v in order", got, ids)
+		}
+	}
+
+	// Published events are not sent again
+	n, err = relay.Flush(context.Background())
+	if err != nil || n != 0 {
+		t.Errorf("second Flush = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestRelayFlushStopsAtFailure(t *testing.T) {
+	st := memory.New()
+	ids := addReservations(t, st, 3)
+
+	broker := &failingBroker{n: 1}
+	relay := NewRelay(logger.GetLogger(), st, broker, time.Second)
+
+	n, err := relay.Flush(context.Background())
+	if err == nil {
+		t.Fatal("Flush succeeded with a failing broker")
+	}
+	if n != 1 {
+		t.Errorf("Flush published %d events, want 1", n)
+	}
+
+	// The failed event and the ones after it stay pending, in order
+	pending, err := st.PendingEvents(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != ids[1] || pending[1].ID != ids[2] {
+		t.Errorf("pending events = %v, want %v", pending, ids[1:])
+	}
+}
+
+func TestSubject(t *testing.T) {
+	event, err := model.NewEvent(model.EventReservationCanceled, "r1", nil)
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+
+	if got, want := Subject(event), "reservepark.reservation.ReservationCanceled"; got != want {
+		t.Errorf("Subject = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey carries the request ID between services over gRPC
+const RequestIDMetadataKey = "x-request-id"
+
+// UnaryServerInterceptor is the gRPC counterpart of RequestIDMiddleware
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !isValidRequestID(id) {
+		id = uuid.NewString()
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, id))
+	return handler(WithRequestID(ctx, id), req)
+}
+
+// StreamServerInterceptor does the same for streaming calls
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := ss.Context()
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !isValidRequestID(id) {
+		id = uuid.NewString()
+	}
+
+	ss.SetHeader(metadata.Pairs(RequestIDMetadataKey, id))
+	return handler(srv, &serverStream{ServerStream: ss, ctx: WithRequestID(ctx, id)})
+}
+
+// serverStream replaces the context of a stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -1,18 +1,69 @@
 package logger
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"strings"
 )
 
 type Logger struct {
-	Info  *log.Logger
-	Error *log.Logger
+	*slog.Logger
 }
 
+// Attributes that never make it to the output in clear text
+var sensitiveKeys = map[string]struct{}{
+	"password":      {},
+	"password_hash": {},
+	"authorization": {},
+	"token":         {},
+	"jwt":           {},
+	"salt":          {},
+}
+
+// GetLogger returns a JSON logger writing to stdout with the level taken from LOG_LEVEL
+// (debug, info, warn or error; info by default). It also becomes the default logger,
+// so lines written through the standard log package share the same format.
 func GetLogger() *Logger {
-	return &Logger{
-		Info:  log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		Error: log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		level = slog.LevelInfo
 	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})
+
+	l := slog.New(contextHandler{handler})
+	slog.SetDefault(l)
+
+	return &Logger{l}
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if _, ok := sensitiveKeys[strings.ToLower(a.Key)]; ok {
+		a.Value = slog.StringValue("[REDACTED]")
+	}
+	return a
+}
+
+// contextHandler adds the request ID stored in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
 }
@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	AvailabilityWatchers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "availability_watchers",
+		Help: "Number of open availability watches.",
+	})
+
+	AvailabilityChanges = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "availability_changes_total",
+		Help: "Number of availability changes derived from reservation events.",
+	})
+)
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ReservationsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reservations_created_total",
+		Help: "Number of created reservations.",
+	})
+
+	ReservationsCanceled = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reservations_canceled_total",
+		Help: "Number of canceled reservations.",
+	})
+
+	InvoicesIssued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "invoices_issued_total",
+		Help: "Number of issued invoices by kind.",
+	}, []string{"kind"})
+
+	PromoCodesRedeemed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "promo_codes_redeemed_total",
+		Help: "Number of reservations booked with a promo code by code.",
+	}, []string{"code"})
+
+	AvailabilityChecks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "availability_checks_total",
+		Help: "Number of availability checks.",
+	})
+)
@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	EventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_published_total",
+		Help: "Number of domain events published from the outbox.",
+	}, []string{"type"})
+
+	EventPublishErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "event_publish_errors_total",
+		Help: "Number of failed attempts to publish an outbox event.",
+	})
+)
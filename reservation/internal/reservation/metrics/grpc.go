@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Number of handled gRPC calls.",
+	}, []string{"method", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latency of handled gRPC calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+// UnaryServerInterceptor records every call under its full method name and status code
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err).String()
+	grpcRequests.WithLabelValues(info.FullMethod, code).Inc()
+	grpcDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
+// StreamServerInterceptor counts the streaming calls when they end. Their duration is how
+// long the caller stayed, so it isn't recorded with the latency of the unary calls.
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	grpcRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+
+	return err
+}
@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// PaymentCalls counts the provider calls by operation (authorize, capture, refund, void) and outcome (ok, declined, error)
+	PaymentCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_provider_calls_total",
+		Help: "Number of payment provider calls by operation and outcome.",
+	}, []string{"operation", "outcome"})
+
+	// PaymentCallbacks counts the provider callbacks by outcome: applied, ignored or duplicate
+	PaymentCallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_callbacks_total",
+		Help: "Number of payment provider callbacks by outcome.",
+	}, []string{"outcome"})
+)
@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// WebhookAttempts counts the delivery attempts by outcome: succeeded, failed or dead
+	WebhookAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_attempts_total",
+		Help: "Number of webhook delivery attempts by outcome.",
+	}, []string{"outcome"})
+
+	WebhookAttemptDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "webhook_attempt_duration_seconds",
+		Help:    "Duration of the webhook delivery requests.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Slot is the part of a reservation that decides whether its spot is free
+type Slot struct {
+	SpotID    string     `json:"spot_id"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   time.Time  `json:"end_time"`
+	Status    StatusType `json:"status"`
+}
+
+func (r Reservation) Slot() Slot {
+	return Slot{SpotID: r.SpotID, StartTime: r.StartTime, EndTime: r.EndTime, Status: r.Status}
+}
+
+// Equal compares the times as instants, whatever their location
+func (s Slot) Equal(other Slot) bool {
+	return s.SpotID == other.SpotID && s.StartTime.Equal(other.StartTime) &&
+		s.EndTime.Equal(other.EndTime) && s.Status == other.Status
+}
+
+// Holds tells whether the slot keeps its spot taken, pending slots do while they wait for the payment
+func (s Slot) Holds() bool {
+	return s.Status != StatusCanceled && s.SpotID != ""
+}
+
+// sameHold tells whether both slots keep the same spot taken for the same window
+func (s Slot) sameHold(other Slot) bool {
+	return s.Holds() && other.Holds() && s.SpotID == other.SpotID &&
+		s.StartTime.Equal(other.StartTime) && s.EndTime.Equal(other.EndTime)
+}
+
+// ReservationChanged is the payload of the created, updated, confirmed and canceled events: the
+// reservation after the change, and its slot before the change when the slot changed
+type ReservationChanged struct {
+	Reservation
+	Previous *Slot `json:"previous,omitempty"`
+}
+
+// NewReservationChanged describes the change from before to after
+func NewReservationChanged(before, after Reservation) ReservationChanged {
+	changed := ReservationChanged{Reservation: after}
+	if slot := before.Slot(); !slot.Equal(after.Slot()) {
+		changed.Previous = &slot
+	}
+	return changed
+}
+
+// ReservationDeleted is the payload of the deleted event
+type ReservationDeleted struct {
+	ReservationID string `json:"reservation_id"`
+	Slot
+}
+
+type AvailabilityChangeType string
+
+const (
+	SlotBooked   AvailabilityChangeType = "booked"
+	SlotReleased AvailabilityChangeType = "released"
+)
+
+// AvailabilityChange tells that a reservation took or freed a spot for a window
+type AvailabilityChange struct {
+	SpotID        string                 `json:"spot_id"`
+	ReservationID string                 `json:"reservation_id"`
+	Change        AvailabilityChangeType `json:"change"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+}
+
+// AvailabilityChanges derives the availability changes from a reservation event, released
+// slots first. A change that left the slot as it was, e.g. a new price, changes nothing,
+// and neither does a confirmation, the pending slot already held the spot.
+func AvailabilityChanges(event Event) ([]AvailabilityChange, error) {
+	var reservationID string
+	var released, booked *Slot
+
+	switch event.Type {
+	case EventReservationCreated, EventReservationUpdated, EventReservationConfirmed, EventReservationCanceled:
+		var payload ReservationChanged
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, err
+		}
+		reservationID = payload.ReservationID
+
+		slot := payload.Slot()
+		if event.Type == EventReservationCreated {
+			booked = &slot
+		} else if payload.Previous != nil && !payload.Previous.sameHold(slot) {
+			released, booked = payload.Previous, &slot
+		}
+	case EventReservationDeleted:
+		var payload ReservationDeleted
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, err
+		}
+		reservationID = payload.ReservationID
+		released = &payload.Slot
+	default:
+		return nil, nil
+	}
+
+	var changes []AvailabilityChange
+	add := func(slot *Slot, change AvailabilityChangeType) {
+		// Canceled slots don't hold their spot, and events written before the
+		// slots were part of the payload have no spot
+		if slot == nil || !slot.Holds() {
+			return
+		}
+		changes = append(changes, AvailabilityChange{
+			SpotID:        slot.SpotID,
+			ReservationID: reservationID,
+			Change:        change,
+			StartTime:     slot.StartTime,
+			EndTime:       slot.EndTime,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			OccurredAt:    event.OccurredAt,
+		})
+	}
+	add(released, SlotReleased)
+	add(booked, SlotBooked)
+
+	return changes, nil
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+type CancellationTier string
+
+const (
+	CancellationFree          CancellationTier = "free"
+	CancellationLateFee       CancellationTier = "late_fee"
+	CancellationNonRefundable CancellationTier = "non_refundable"
+)
+
+// CancellationPolicy is the policy the spot service resolved for the spot of a reservation,
+// Scope tells whether it's the spot's own, its lot's or the default one
+type CancellationPolicy struct {
+	Scope                string  `json:"scope" bson:"scope" validate:"required"`
+	TargetID             string  `json:"target_id" bson:"target_id"`
+	FreeUntilHours       int     `json:"free_until_hours" bson:"free_until_hours" validate:"gte=0"`
+	LateFeePercent       float64 `json:"late_fee_percent" bson:"late_fee_percent" validate:"gte=0,lte=100"`
+	NonRefundableMinutes int     `json:"non_refundable_minutes" bson:"non_refundable_minutes" validate:"gte=0"`
+}
+
+// CancellationQuote is what canceling a reservation costs at QuotedAt. It holds until
+// ValidUntil, when the next tier of the policy starts; the last tier has no end.
+type CancellationQuote struct {
+	ReservationID string             `json:"reservation_id"`
+	Price         float64            `json:"price"`
+	Fee           float64            `json:"fee"`
+	Refund        float64            `json:"refund"`
+	Tier          CancellationTier   `json:"tier"`
+	Policy        CancellationPolicy `json:"policy"`
+	QuotedAt      time.Time          `json:"quoted_at"`
+	ValidUntil    *time.Time         `json:"valid_until,omitempty"`
+}
+
+// Cancellation records what was kept and refunded when the reservation was canceled
+type Cancellation struct {
+	CanceledAt time.Time          `json:"canceled_at" bson:"canceled_at"`
+	Tier       CancellationTier   `json:"tier" bson:"tier"`
+	Fee        float64            `json:"fee" bson:"fee"`
+	Refund     float64            `json:"refund" bson:"refund"`
+	Policy     CancellationPolicy `json:"policy" bson:"policy"`
+}
+
+// Quote prices canceling a reservation of the price starting at start, at the moment at.
+// Canceling at least FreeUntilHours before the start is free, then LateFeePercent of the
+// price is kept until NonRefundableMinutes before the start, from there on nothing is refunded.
+func (p CancellationPolicy) Quote(price float64, start, at time.Time) CancellationQuote {
+	q := CancellationQuote{Price: price, Policy: p, QuotedAt: at}
+	before := start.Sub(at)
+	free := time.Duration(p.FreeUntilHours) * time.Hour
+	nonRefundable := time.Duration(p.NonRefundableMinutes) * time.Minute
+
+	switch {
+	case before > 0 && before >= free:
+		q.Tier = CancellationFree
+		q.ValidUntil = timePtr(start.Add(-free))
+	case before > 0 && before >= nonRefundable:
+		q.Tier = CancellationLateFee
+		q.Fee = roundCents(price * p.LateFeePercent / 100)
+		q.ValidUntil = timePtr(start.Add(-nonRefundable))
+	default:
+		q.Tier = CancellationNonRefundable
+		q.Fee = price
+	}
+
+	q.Refund = roundCents(price - q.Fee)
+	return q
+}
+
+// RetainedFee is the part of the price kept after the reservation was canceled
+func (r Reservation) RetainedFee() float64 {
+	if r.Status != StatusCanceled || r.Cancellation == nil {
+		return 0
+	}
+	return r.Cancellation.Fee
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,196 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type InvoiceKind string
+
+const (
+	InvoiceSingle InvoiceKind = "single"
+
+	// InvoiceMonthly consolidates the reservations of a business account starting in one month
+	InvoiceMonthly InvoiceKind = "monthly"
+
+	// InvoiceCreditNote takes back part of an invoice after a refund or a cancellation
+	InvoiceCreditNote InvoiceKind = "credit_note"
+)
+
+// PeriodLayout formats the month of a monthly invoice
+const PeriodLayout = "2006-01"
+
+// Invoice is issued once for the amount charged for reservations. The buyer, seller and
+// spot details are copied in when it's issued, so later changes don't alter it. Prices
+// include the tax, the lines and tax lines split them into the net amount and the tax.
+// Credit notes are invoices too, with negative amounts.
+type Invoice struct {
+	InvoiceID string      `json:"invoice_id" bson:"_id"`
+	TenantID  string      `json:"tenant_id" bson:"tenant_id"`
+	Sequence  int64       `json:"-" bson:"sequence"`
+	Number    string      `json:"number" bson:"number"`
+	Kind      InvoiceKind `json:"kind" bson:"kind"`
+	UserID    string      `json:"user_id" bson:"user_id"`
+
+	// Period is the month of a monthly invoice, e.g. 2025-03
+	Period string `json:"period,omitempty" bson:"period,omitempty"`
+
+	// CreditedInvoiceID and CreditedNumber are the invoice a credit note corrects
+	CreditedInvoiceID string `json:"credited_invoice_id,omitempty" bson:"credited_invoice_id,omitempty"`
+	CreditedNumber    string `json:"credited_number,omitempty" bson:"credited_number,omitempty"`
+
+	Seller   Party         `json:"seller" bson:"seller"`
+	Buyer    Party         `json:"buyer" bson:"buyer"`
+	Currency string        `json:"currency" bson:"currency"`
+	Lines    []InvoiceLine `json:"lines" bson:"lines"`
+	Taxes    []TaxLine     `json:"taxes" bson:"taxes"`
+	Net      float64       `json:"net" bson:"net"`
+	Tax      float64       `json:"tax" bson:"tax"`
+	Total    float64       `json:"total" bson:"total"`
+	IssuedAt time.Time     `json:"issued_at" bson:"issued_at"`
+}
+
+// Party is the seller or the buyer of an invoice
+type Party struct {
+	Name    string `json:"name" bson:"name" validate:"required"`
+	Email   string `json:"email,omitempty" bson:"email,omitempty"`
+	TaxID   string `json:"tax_id,omitempty" bson:"tax_id,omitempty"`
+	Address string `json:"address,omitempty" bson:"address,omitempty"`
+}
+
+// SpotDetails describe the spot of an invoice line, only SpotID is known once the spot is gone
+type SpotDetails struct {
+	SpotID    string  `json:"spot_id" bson:"spot_id" validate:"required"`
+	LotID     string  `json:"lot_id,omitempty" bson:"lot_id,omitempty"`
+	Size      string  `json:"size,omitempty" bson:"size,omitempty"`
+	Type      string  `json:"type,omitempty" bson:"type,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty" bson:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty" bson:"longitude,omitempty"`
+}
+
+type InvoiceLine struct {
+	ReservationID string      `json:"reservation_id" bson:"reservation_id"`
+	Description   string      `json:"description" bson:"description"`
+	Spot          SpotDetails `json:"spot" bson:"spot"`
+	StartTime     time.Time   `json:"start_time" bson:"start_time"`
+	EndTime       time.Time   `json:"end_time" bson:"end_time"`
+	Net           float64     `json:"net" bson:"net"`
+	Tax           float64     `json:"tax" bson:"tax"`
+	Total         float64     `json:"total" bson:"total"`
+}
+
+// TaxLine sums the lines taxed at one rate, given in percent
+type TaxLine struct {
+	Name string  `json:"name" bson:"name"`
+	Rate float64 `json:"rate" bson:"rate"`
+	Net  float64 `json:"net" bson:"net"`
+	Tax  float64 `json:"tax" bson:"tax"`
+}
+
+// InvoiceFilter selects invoices, empty fields match any
+type InvoiceFilter struct {
+	TenantID string
+	UserID   string
+	Period   string
+}
+
+// Tax is the single rate the invoices are issued with
+type Tax struct {
+	Name string
+	Rate float64
+}
+
+// Charge is an amount charged for a reservation and what for
+type Charge struct {
+	Amount      float64
+	Description string
+}
+
+// Billable is what the reservation charges without a payment: the price of a valid
+// reservation or the fee kept when it was canceled. Nothing is charged for a pending one.
+func (r Reservation) Billable() Charge {
+	switch r.Status {
+	case StatusValid:
+		return Charge{Amount: r.PricePaid, Description: "Parking"}
+	case StatusCanceled:
+		return Charge{Amount: r.RetainedFee(), Description: "Cancellation fee"}
+	default:
+		return Charge{}
+	}
+}
+
+// Captured is what the payment of the reservation charged: the amount captured less the refunds
+func (r Reservation) Captured(p Payment) Charge {
+	charge := Charge{Amount: float64(p.Captured-p.Refunded) / 100, Description: "Parking"}
+	if r.Status != StatusValid {
+		charge.Description = "Cancellation fee"
+	}
+	return charge
+}
+
+// AddLine bills the charge for the reservation on the invoice
+func (inv *Invoice) AddLine(reservation Reservation, charge Charge, spot SpotDetails, tax Tax) {
+	inv.addLine(InvoiceLine{
+		ReservationID: reservation.ReservationID,
+		Description:   charge.Description,
+		Spot:          spot,
+		StartTime:     reservation.StartTime,
+		EndTime:       reservation.EndTime,
+	}, charge.Amount, tax)
+}
+
+// AddCredit takes the amount back from the line of the invoice the credit note corrects
+func (inv *Invoice) AddCredit(line InvoiceLine, amount float64, tax Tax) {
+	line.Description = "Refund"
+	inv.addLine(line, -amount, tax)
+}
+
+// addLine splits the amount of the line into net and tax
+func (inv *Invoice) addLine(line InvoiceLine, amount float64, tax Tax) {
+	line.Net = roundCents(amount / (1 + tax.Rate/100))
+	line.Tax = roundCents(amount - line.Net)
+	line.Total = amount
+	inv.Lines = append(inv.Lines, line)
+
+	inv.Net = roundCents(inv.Net + line.Net)
+	inv.Total = roundCents(inv.Total + amount)
+	inv.Tax = roundCents(inv.Total - inv.Net)
+	inv.Taxes = []TaxLine{{Name: tax.Name, Rate: tax.Rate, Net: inv.Net, Tax: inv.Tax}}
+}
+
+// Line returns the line of the invoice for the reservation
+func (inv Invoice) Line(reservationID string) (InvoiceLine, bool) {
+	for _, line := range inv.Lines {
+		if line.ReservationID == reservationID {
+			return line, true
+		}
+	}
+	return InvoiceLine{}, false
+}
+
+// TaxRate returns the tax the invoice was issued with
+func (inv Invoice) TaxRate() Tax {
+	if len(inv.Taxes) == 0 {
+		return Tax{}
+	}
+	return Tax{Name: inv.Taxes[0].Name, Rate: inv.Taxes[0].Rate}
+}
+
+// Invoiced sums what the invoices and credit notes bill for the reservation
+func Invoiced(reservationID string, invoices ...Invoice) float64 {
+	var total float64
+	for _, inv := range invoices {
+		for _, line := range inv.Lines {
+			if line.ReservationID == reservationID {
+				total += line.Total
+			}
+		}
+	}
+	return roundCents(total)
+}
+
+// InvoiceNumber formats the sequence number the store assigned within the tenant, e.g. RP-000042
+func InvoiceNumber(tenantID string, sequence int64) string {
+	return fmt.Sprintf("%s-%06d", strings.ToUpper(tenantID), sequence)
+}
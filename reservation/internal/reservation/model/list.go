@@ -0,0 +1,35 @@
+package model
+
+import "errors"
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+var (
+	// ErrNotFound is returned by the stores when the requested document doesn't exist
+	ErrNotFound = errors.New("not found")
+
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrOverlap is returned by stores that reject overlapping valid reservations on the same spot themselves
+	ErrOverlap = errors.New("reservation overlaps another one on the spot")
+
+	// ErrDuplicate is returned by the stores when a write breaks a unique index
+	ErrDuplicate = errors.New("already exists")
+)
+
+type ListOptions struct {
+	Limit        int64
+	Cursor       string
+	SortBy       string
+	Descending   bool
+	IncludeTotal bool
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	TotalCount *int64 `json:"total_count,omitempty"`
+}
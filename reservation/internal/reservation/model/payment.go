@@ -0,0 +1,63 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+type PaymentStatus string
+
+const (
+	// PaymentPending waits for the provider, e.g. for the customer to pass 3-D Secure
+	PaymentPending    PaymentStatus = "pending"
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCaptured   PaymentStatus = "captured"
+	PaymentVoided     PaymentStatus = "voided"
+	PaymentRefunded   PaymentStatus = "refunded"
+	PaymentFailed     PaymentStatus = "failed"
+)
+
+// Payment collects the price of one reservation through the payment provider. The
+// amounts are in the minor unit of the currency, e.g. cents.
+type Payment struct {
+	PaymentID     string        `json:"payment_id" bson:"_id"`
+	ReservationID string        `json:"reservation_id" bson:"reservation_id"`
+	Provider      string        `json:"provider" bson:"provider"`
+	ProviderRef   string        `json:"provider_ref,omitempty" bson:"provider_ref,omitempty"`
+	Status        PaymentStatus `json:"status" bson:"status"`
+	Currency      string        `json:"currency" bson:"currency"`
+	Amount        int64         `json:"amount" bson:"amount"`
+	Captured      int64         `json:"amount_captured" bson:"amount_captured"`
+	Refunded      int64         `json:"amount_refunded" bson:"amount_refunded"`
+
+	// ClientSecret lets the customer finish a pending authorization, it is dropped once the payment moves on
+	ClientSecret  string `json:"client_secret,omitempty" bson:"client_secret,omitempty"`
+	FailureReason string `json:"failure_reason,omitempty" bson:"failure_reason,omitempty"`
+
+	// DueAt is when the settler should look at the payment again: to capture it at the
+	// start of the reservation, to give up a pending authorization, or to release the
+	// money of a canceled reservation. It is nil when there's nothing left to do.
+	DueAt     *time.Time `json:"-" bson:"due_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+// CanMoveTo tells whether the payment may go from its status to the next one. Provider
+// callbacks can arrive late or twice, so a payment never goes back to an earlier status.
+func (p Payment) CanMoveTo(next PaymentStatus) bool {
+	switch p.Status {
+	case PaymentPending:
+		return next == PaymentAuthorized || next == PaymentFailed || next == PaymentVoided || next == PaymentCaptured
+	case PaymentAuthorized:
+		return next == PaymentCaptured || next == PaymentVoided
+	case PaymentCaptured:
+		return next == PaymentRefunded
+	default:
+		return false
+	}
+}
+
+// MinorUnits converts a price to the minor unit of a two decimal currency
+func MinorUnits(price float64) int64 {
+	return int64(math.Round(price * 100))
+}
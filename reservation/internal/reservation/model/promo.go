@@ -0,0 +1,119 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type DiscountType string
+
+const (
+	// DiscountPercent takes Value percent off the price
+	DiscountPercent DiscountType = "percent"
+
+	// DiscountFixed takes Value off the price, in the payment currency
+	DiscountFixed DiscountType = "fixed"
+)
+
+// ErrLimitReached is returned when redeeming a promo code that reached its global or per-user limit
+var ErrLimitReached = errors.New("redemption limit reached")
+
+// PromoCode discounts the price of reservations it applies to. Codes are case-insensitive
+// and stored upper-case. Zero limits mean no limit.
+type PromoCode struct {
+	Code        string       `json:"code" bson:"_id" validate:"required,alphanum,max=32"`
+	Description string       `json:"description,omitempty" bson:"description,omitempty" validate:"max=200"`
+	Type        DiscountType `json:"type" bson:"type" validate:"required,oneof=percent fixed"`
+	Value       float64      `json:"value" bson:"value" validate:"gt=0"`
+	ValidFrom   *time.Time   `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	ValidUntil  *time.Time   `json:"valid_until,omitempty" bson:"valid_until,omitempty"`
+
+	MaxRedemptions int               `json:"max_redemptions,omitempty" bson:"max_redemptions" validate:"gte=0"`
+	MaxPerUser     int               `json:"max_per_user,omitempty" bson:"max_per_user" validate:"gte=0"`
+	Restrictions   PromoRestrictions `json:"restrictions" bson:"restrictions"`
+
+	// Redemptions counts the reservations booked with the code
+	Redemptions int       `json:"redemptions" bson:"redemptions"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// PromoRestrictions limit the reservations a code applies to, empty fields don't restrict
+type PromoRestrictions struct {
+	LotIDs             []string `json:"lot_ids,omitempty" bson:"lot_ids,omitempty"`
+	SpotTypes          []string `json:"spot_types,omitempty" bson:"spot_types,omitempty" validate:"dive,oneof=indoor outdoor ev"`
+	MinDurationMinutes int      `json:"min_duration_minutes,omitempty" bson:"min_duration_minutes,omitempty" validate:"gte=0"`
+
+	// FirstBookingOnly applies the code only to users without a valid or pending reservation
+	FirstBookingOnly bool `json:"first_booking_only,omitempty" bson:"first_booking_only,omitempty"`
+}
+
+// PromoRedemption records that a reservation was booked with a code
+type PromoRedemption struct {
+	ReservationID string    `json:"reservation_id" bson:"_id"`
+	Code          string    `json:"code" bson:"code"`
+	UserID        string    `json:"user_id" bson:"user_id"`
+	Discount      float64   `json:"discount" bson:"discount"`
+	RedeemedAt    time.Time `json:"redeemed_at" bson:"redeemed_at"`
+}
+
+// Discount is recorded on a reservation booked with a promo code, its price is ListPrice less Amount
+type Discount struct {
+	Code      string  `json:"code" bson:"code"`
+	ListPrice float64 `json:"list_price" bson:"list_price"`
+	Amount    float64 `json:"amount" bson:"amount"`
+}
+
+// PromoTarget is the booking a code is applied to, the caller resolves the lot and type of the spot
+type PromoTarget struct {
+	LotID     string
+	SpotType  string
+	StartTime time.Time
+	EndTime   time.Time
+	Price     float64
+}
+
+// NormalizeCode returns the form codes are stored in
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// DiscountFor returns the discount the code gives on the booking at the moment at, or the reason
+// why it doesn't apply. The limits and the first booking restriction are checked by the caller.
+func (p PromoCode) DiscountFor(target PromoTarget, at time.Time) (float64, error) {
+	if p.ValidFrom != nil && at.Before(*p.ValidFrom) {
+		return 0, fmt.Errorf("the code is valid from %s", p.ValidFrom.UTC().Format(time.RFC3339))
+	}
+	if p.ValidUntil != nil && !at.Before(*p.ValidUntil) {
+		return 0, errors.New("the code has expired")
+	}
+
+	r := p.Restrictions
+	if len(r.LotIDs) > 0 && !slices.Contains(r.LotIDs, target.LotID) {
+		return 0, errors.New("the code doesn't apply to the lot of the spot")
+	}
+	if len(r.SpotTypes) > 0 && !slices.Contains(r.SpotTypes, target.SpotType) {
+		return 0, errors.New("the code doesn't apply to the type of the spot")
+	}
+	if minimum := time.Duration(r.MinDurationMinutes) * time.Minute; target.EndTime.Sub(target.StartTime) < minimum {
+		return 0, fmt.Errorf("the code applies to reservations of at least %d minutes", r.MinDurationMinutes)
+	}
+
+	discount := p.Value
+	if p.Type == DiscountPercent {
+		discount = roundCents(target.Price * p.Value / 100)
+	}
+	// A reservation can't be free, the payment provider has nothing to authorize then
+	if discount >= target.Price {
+		return 0, errors.New("the discount would cover the whole price")
+	}
+
+	return discount, nil
+}
+
+// Price is what the reservation costs after the discount
+func (d Discount) Price() float64 {
+	return roundCents(d.ListPrice - d.Amount)
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StatusType string
+
+// A reservation holds its spot unless it's canceled. With payments enabled it is
+// booked as pending and only becomes valid (confirmed) once its payment is authorized.
+const (
+	StatusPending  StatusType = "pending"
+	StatusValid    StatusType = "valid"
+	StatusCanceled StatusType = "canceled"
+)
+
+type Reservation struct {
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ReservationID string             `json:"reservation_id" bson:"reservation_id" validate:"required"`
+	UserID        string             `json:"user_id" bson:"user_id" validate:"required"`
+	SpotID        string             `json:"spot_id" bson:"spot_id" validate:"required"`
+	StartTime     time.Time          `json:"start_time" bson:"start_time" validate:"required"`
+	EndTime       time.Time          `json:"end_time" bson:"end_time" validate:"required"`
+	Status        StatusType         `json:"status" bson:"status" validate:"required,oneof=pending valid canceled"`
+	PricePaid     float64            `json:"price_paid" bson:"price_paid" validate:"required,gt=0"`
+	VehicleID     string             `json:"vehicle_id,omitempty" bson:"vehicle_id,omitempty"`
+	LicensePlate  string             `json:"license_plate,omitempty" bson:"license_plate,omitempty" validate:"omitempty,max=16"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at" validate:"required"`
+
+	// Cancellation is set when the reservation was canceled under a cancellation policy
+	Cancellation *Cancellation `json:"cancellation,omitempty" bson:"cancellation,omitempty"`
+
+	// Discount is set when the reservation was booked with a promo code
+	Discount *Discount `json:"discount,omitempty" bson:"discount,omitempty"`
+}
+
+// ReservationFilter selects reservations, From and To select reservations overlapping that range
+type ReservationFilter struct {
+	UserID string
+	SpotID string
+	Status StatusType
+	From   *time.Time
+	To     *time.Time
+}
+
+type AvailabilityInput struct {
+	SpotIDs   []string  `json:"spot_ids" validate:"required"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required"`
+}
+
+// NormalizePlate brings license plates to the canonical form used by the user service.
+func NormalizePlate(plate string) string {
+	replacer := strings.NewReplacer(" ", "", "-", "", ".", "")
+	return strings.ToUpper(replacer.Replace(plate))
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types of the spot service, webhooks receive them through the broker
+const (
+	EventSpotCreated      = "SpotCreated"
+	EventSpotUpdated      = "SpotUpdated"
+	EventSpotDeleted      = "SpotDeleted"
+	EventSpotPriceChanged = "SpotPriceChanged"
+)
+
+// Webhook is a subscription of an external system to some event types.
+// The secret signs the deliveries and is never returned by the API.
+type Webhook struct {
+	WebhookID  string    `json:"webhook_id" bson:"_id"`
+	URL        string    `json:"url" bson:"url"`
+	EventTypes []string  `json:"event_types" bson:"event_types"`
+	Secret     string    `json:"-" bson:"secret"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Subscribes tells whether the webhook wants events of the type
+func (w Webhook) Subscribes(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead is the dead letter state, the delivery is only retried when redelivered by hand
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// Delivery is one event on its way to one webhook together with the log of its attempts
+type Delivery struct {
+	DeliveryID string         `json:"delivery_id" bson:"_id"`
+	WebhookID  string         `json:"webhook_id" bson:"webhook_id"`
+	Event      Event          `json:"event" bson:"event"`
+	Status     DeliveryStatus `json:"status" bson:"status"`
+	Attempts   []Attempt      `json:"attempts" bson:"attempts"`
+
+	// Failures counts the failed attempts since the delivery was created or redelivered,
+	// the backoff and the dead lettering are based on it
+	Failures      int       `json:"failures" bson:"failures"`
+	NextAttemptAt time.Time `json:"next_attempt_at" bson:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Attempt is one HTTP request of a delivery, StatusCode is zero when no response arrived
+type Attempt struct {
+	At         time.Time `json:"at" bson:"at"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// deliveryNamespace derives the delivery IDs, see NewDelivery
+var deliveryNamespace = uuid.MustParse("0b0e6f4c-4a2e-4c8e-9f57-3d1f6c2b9a10")
+
+// NewDelivery prepares the delivery of the event to the webhook. Its ID is derived
+// from both, so an event published again doesn't create a second delivery.
+func NewDelivery(webhookID string, event Event, now time.Time) Delivery {
+	return Delivery{
+		DeliveryID:    uuid.NewSHA1(deliveryNamespace, []byte(webhookID+"/"+event.ID)).String(),
+		WebhookID:     webhookID,
+		Event:         event,
+		Status:        DeliveryPending,
+		Attempts:      []Attempt{},
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// DeliveryFilter selects deliveries, newest first
+type DeliveryFilter struct {
+	WebhookID string
+	Status    DeliveryStatus
+}
@@ -2,22 +2,57 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
+// MongoDB is the production store.ReservationStore
+var _ store.ReservationStore = (*MongoDB)(nil)
+
 type MongoDB struct {
 	Collection *mongo.Collection
+	Outbox     *mongo.Collection
+	Webhooks   *mongo.Collection
+	Deliveries *mongo.Collection
+	Payments   *mongo.Collection
+	Invoices   *mongo.Collection
+
+	PromoCodes       *mongo.Collection
+	PromoRedemptions *mongo.Collection
+
+	// PromoUserCounters hold how many times each user redeemed each promo code
+	PromoUserCounters *mongo.Collection
+
+	// InvoiceCounters hold the last invoice sequence number of each tenant
+	InvoiceCounters *mongo.Collection
+
+	// InvoicedReservations hold the invoice of each invoiced reservation, so it's invoiced once
+	InvoicedReservations *mongo.Collection
+
+	// ProviderEvents holds the IDs of the handled payment provider callbacks
+	ProviderEvents *mongo.Collection
+
+	// Transactions is set when the deployment supports them. Without them a write and its
+	// outbox events are two writes and a crash in between loses the events or publishes
+	// events of a change that never happened, see withEvents. Connect refuses such a
+	// deployment unless MONGO_STANDALONE allows it.
+	Transactions bool
 }
 
-func Connect(uri string, name string) (*MongoDB, error) {
+// Connect connects to the database and migrates it. A standalone server, which has no
+// transactions, is refused unless standalone is set, e.g. for development.
+func Connect(uri string, name string, standalone bool) (*MongoDB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(metrics.MongoMonitor(otelmongo.NewMonitor())))
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +62,47 @@ func Connect(uri string, name string) (*MongoDB, error) {
 		return nil, err
 	}
 
-	return &MongoDB{Collection: client.Database(name).Collection(name)}, nil
+	db := &MongoDB{
+		Collection: client.Database(name).Collection(name),
+		Outbox:     client.Database(name).Collection(outboxCollection),
+		Webhooks:   client.Database(name).Collection(webhooksCollection),
+		Deliveries: client.Database(name).Collection(deliveriesCollection),
+		Payments:   client.Database(name).Collection(paymentsCollection),
+		Invoices:   client.Database(name).Collection(invoicesCollection),
+
+		InvoiceCounters:      client.Database(name).Collection(invoiceCountersCollection),
+		InvoicedReservations: client.Database(name).Collection(invoicedReservationsCollection),
+		ProviderEvents:       client.Database(name).Collection(providerEventsCollection),
+
+		PromoCodes:        client.Database(name).Collection(promoCodesCollection),
+		PromoRedemptions:  client.Database(name).Collection(promoRedemptionsCollection),
+		PromoUserCounters: client.Database(name).Collection(promoUserCountersCollection),
+	}
+
+	db.Transactions, err = supportsTransactions(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if !db.Transactions {
+		if !standalone {
+			return nil, errors.New("the MongoDB deployment doesn't support transactions, run a replica set or set MONGO_STANDALONE=true")
+		}
+		log.Printf("WARNING: MongoDB runs without transactions, writes and their outbox events are stored separately and a crash in between loses events, promo code counts are given back by hand when a redemption fails and invoices can't be issued")
+	}
+
+	// Index builds can take longer than a request on a populated collection
+	migrateCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := Migrate(migrateCtx, db.Collection); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (m *MongoDB) Ping(ctx context.Context) error {
+	return m.Collection.Database().Client().Ping(ctx, nil)
 }
 
 func (m *MongoDB) Disconnect() {
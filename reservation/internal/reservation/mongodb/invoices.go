@@ -0,0 +1,217 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	invoicesCollection = "invoices"
+
+	// invoiceCountersCollection holds the last sequence number of each tenant, keyed by tenant ID
+	invoiceCountersCollection = "invoice_counters"
+
+	// invoicedReservationsCollection marks the invoiced reservations, keyed by reservation ID
+	invoicedReservationsCollection = "invoiced_reservations"
+)
+
+// codeIndexNotFound is the server error returned when dropping an index that doesn't exist
+const codeIndexNotFound = 27
+
+type invoiceCounterDocument struct {
+	TenantID string `bson:"_id"`
+	Sequence int64  `bson:"sequence"`
+}
+
+type invoicedReservationDocument struct {
+	ReservationID string `bson:"_id"`
+	InvoiceID     string `bson:"invoice_id"`
+}
+
+// errInvoicesNeedTransactions refuses invoices on deployments without transactions, where
+// a failed insert could leave a gap in the numbering
+var errInvoicesNeedTransactions = fmt.Errorf("issuing invoices needs MongoDB transactions, run a replica set: %w", errors.ErrUnsupported)
+
+// AddInvoice marks the reservations, takes the number and inserts the invoice in one
+// transaction, so a failed insert gives the number back
+func (m *MongoDB) AddInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error) {
+	if !m.Transactions {
+		return invoice, errInvoicesNeedTransactions
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	session, err := m.Collection.Database().Client().StartSession()
+	if err != nil {
+		return invoice, err
+	}
+	defer session.EndSession(ctx)
+
+	stored, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return m.insertInvoice(sc, invoice)
+	})
+	if err != nil {
+		return invoice, err
+	}
+	return stored.(model.Invoice), nil
+}
+
+func (m *MongoDB) insertInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error) {
+	// Credit notes repeat the reservations of the invoice they credit
+	if invoice.Kind != model.InvoiceCreditNote {
+		marks := make([]any, len(invoice.Lines))
+		for i, line := range invoice.Lines {
+			marks[i] = invoicedReservationDocument{ReservationID: line.ReservationID, InvoiceID: invoice.InvoiceID}
+		}
+
+		if _, err := m.InvoicedReservations.InsertMany(ctx, marks); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return invoice, model.ErrDuplicate
+			}
+			return invoice, err
+		}
+	}
+
+	var counter invoiceCounterDocument
+	filter := bson.M{"_id": bson.M{"$eq": invoice.TenantID}}
+	update := bson.M{"$inc": bson.M{"sequence": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := m.InvoiceCounters.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter); err != nil {
+		return invoice, err
+	}
+
+	invoice.Sequence = counter.Sequence
+	invoice.Number = model.InvoiceNumber(invoice.TenantID, invoice.Sequence)
+
+	if _, err := m.Invoices.InsertOne(ctx, invoice); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return invoice, model.ErrDuplicate
+		}
+		return invoice, err
+	}
+	return invoice, nil
+}
+
+// markInvoicedReservations marks the reservations of the invoices issued so far and drops the
+// unique index on the invoice lines, which credit notes would break
+func markInvoicedReservations(ctx context.Context, coll *mongo.Collection) error {
+	db := coll.Database()
+	invoices := db.Collection(invoicesCollection)
+
+	cursor, err := invoices.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"lines.reservation_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var invoice model.Invoice
+		if err := cursor.Decode(&invoice); err != nil {
+			return err
+		}
+		for _, line := range invoice.Lines {
+			mark := invoicedReservationDocument{ReservationID: line.ReservationID, InvoiceID: invoice.InvoiceID}
+			_, err := db.Collection(invoicedReservationsCollection).ReplaceOne(ctx, bson.M{"_id": mark.ReservationID}, mark, options.Replace().SetUpsert(true))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if _, err := invoices.Indexes().DropOne(ctx, "lines.reservation_id_1"); err != nil && !hasCode(err, codeIndexNotFound) {
+		return err
+	}
+	_, err = invoices.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "lines.reservation_id", Value: 1}}})
+	return err
+}
+
+func (m *MongoDB) GetInvoice(ctx context.Context, invoiceID string) (model.Invoice, error) {
+	return m.findInvoice(ctx, bson.M{"_id": bson.M{"$eq": invoiceID}})
+}
+
+func (m *MongoDB) GetReservationInvoice(ctx context.Context, reservationID string) (model.Invoice, error) {
+	return m.findInvoice(ctx, bson.M{
+		"lines.reservation_id": bson.M{"$eq": reservationID},
+		"kind":                 bson.M{"$ne": model.InvoiceCreditNote},
+	})
+}
+
+func (m *MongoDB) GetCreditNotes(ctx context.Context, reservationID string) ([]model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"lines.reservation_id": bson.M{"$eq": reservationID},
+		"kind":                 bson.M{"$eq": model.InvoiceCreditNote},
+	}
+	return m.findInvoices(ctx, filter, options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}}))
+}
+
+func (m *MongoDB) GetInvoices(ctx context.Context, filter model.InvoiceFilter, limit int) ([]model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = bson.M{"$eq": filter.TenantID}
+	}
+	if filter.UserID != "" {
+		query["user_id"] = bson.M{"$eq": filter.UserID}
+	}
+	if filter.Period != "" {
+		query["period"] = bson.M{"$eq": filter.Period}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "issued_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	return m.findInvoices(ctx, query, opts)
+}
+
+func (m *MongoDB) findInvoices(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]model.Invoice, error) {
+	cursor, err := m.Invoices.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invoices := []model.Invoice{}
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+	for i := range invoices {
+		invoices[i] = utcInvoice(invoices[i])
+	}
+	return invoices, nil
+}
+
+func (m *MongoDB) findInvoice(ctx context.Context, filter bson.M) (model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var invoice model.Invoice
+	err := m.Invoices.FindOne(ctx, filter).Decode(&invoice)
+	return utcInvoice(invoice), notFound(err)
+}
+
+// utcInvoice brings the times decoded in local time back to UTC
+func utcInvoice(inv model.Invoice) model.Invoice {
+	inv.IssuedAt = inv.IssuedAt.UTC()
+	for i := range inv.Lines {
+		inv.Lines[i].StartTime = inv.Lines[i].StartTime.UTC()
+		inv.Lines[i].EndTime = inv.Lines[i].EndTime.UTC()
+	}
+	return inv
+}
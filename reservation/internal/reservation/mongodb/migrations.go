@@ -0,0 +1,157 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations lists the schema changes of the reservations collection in the order they are applied.
+// Released migrations must not be edited, add a new one instead.
+var migrations = []migration{
+	{
+		Version:     "0001",
+		Description: "unique reservation_id, availability, listing and gate lookup indexes",
+		Up: createIndexes(
+			mongo.IndexModel{Keys: bson.D{{Key: "reservation_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			// Serves the availability and window queries: equality on spot_id, ranges on the times
+			mongo.IndexModel{Keys: bson.D{{Key: "spot_id", Value: 1}, {Key: "start_time", Value: 1}, {Key: "end_time", Value: 1}, {Key: "status", Value: 1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "spot_id", Value: 1}, {Key: "license_plate", Value: 1}}},
+		),
+	},
+	{
+		Version:     "0002",
+		Description: "reservations JSON schema validator",
+		Up: setValidator(bson.M{
+			"bsonType": "object",
+			"required": bson.A{"reservation_id", "user_id", "spot_id", "start_time", "end_time", "status", "price_paid", "updated_at"},
+			"properties": bson.M{
+				"reservation_id": bson.M{"bsonType": "string", "minLength": 1},
+				"user_id":        bson.M{"bsonType": "string", "minLength": 1},
+				"spot_id":        bson.M{"bsonType": "string", "minLength": 1},
+				"start_time":     bson.M{"bsonType": "date"},
+				"end_time":       bson.M{"bsonType": "date"},
+				"status":         bson.M{"enum": bson.A{"valid", "canceled"}},
+				"price_paid":     bson.M{"bsonType": "number", "exclusiveMinimum": true, "minimum": 0},
+				"vehicle_id":     bson.M{"bsonType": "string"},
+				"license_plate":  bson.M{"bsonType": "string", "maxLength": 16},
+				"updated_at":     bson.M{"bsonType": "date"},
+			},
+		}),
+	},
+	{
+		// Creating the collection here also keeps it out of the first transaction,
+		// MongoDB before 4.4 can't create collections inside one
+		Version:     "0003",
+		Description: "outbox pending and retention indexes",
+		Up: createIndexesIn(outboxCollection,
+			mongo.IndexModel{Keys: bson.D{{Key: "published_at", Value: 1}, {Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "published_at", Value: 1}},
+				Options: options.Index().SetName("published_at_ttl").SetExpireAfterSeconds(int32(publishedRetention.Seconds())),
+			},
+		),
+	},
+	{
+		Version:     "0004",
+		Description: "webhook delivery claim and log indexes",
+		Up: createIndexesIn(deliveriesCollection,
+			// Serves ClaimDeliveries
+			mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		),
+	},
+	{
+		Version:     "0005",
+		Description: "pending status in the reservations validator",
+		Up: setValidator(bson.M{
+			"bsonType": "object",
+			"required": bson.A{"reservation_id", "user_id", "spot_id", "start_time", "end_time", "status", "price_paid", "updated_at"},
+			"properties": bson.M{
+				"reservation_id": bson.M{"bsonType": "string", "minLength": 1},
+				"user_id":        bson.M{"bsonType": "string", "minLength": 1},
+				"spot_id":        bson.M{"bsonType": "string", "minLength": 1},
+				"start_time":     bson.M{"bsonType": "date"},
+				"end_time":       bson.M{"bsonType": "date"},
+				"status":         bson.M{"enum": bson.A{"pending", "valid", "canceled"}},
+				"price_paid":     bson.M{"bsonType": "number", "exclusiveMinimum": true, "minimum": 0},
+				"vehicle_id":     bson.M{"bsonType": "string"},
+				"license_plate":  bson.M{"bsonType": "string", "maxLength": 16},
+				"updated_at":     bson.M{"bsonType": "date"},
+			},
+		}),
+	},
+	{
+		Version:     "0006",
+		Description: "payment lookup and claim indexes",
+		Up: createIndexesIn(paymentsCollection,
+			// A reservation has one payment at most
+			mongo.IndexModel{Keys: bson.D{{Key: "reservation_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			mongo.IndexModel{Keys: bson.D{{Key: "provider", Value: 1}, {Key: "provider_ref", Value: 1}}},
+			// Serves ClaimPayments, payments without a due time stay out of the index
+			mongo.IndexModel{Keys: bson.D{{Key: "due_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		),
+	},
+	{
+		Version:     "0007",
+		Description: "provider event retention index",
+		Up: createIndexesIn(providerEventsCollection,
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "received_at", Value: 1}},
+				Options: options.Index().SetName("received_at_ttl").SetExpireAfterSeconds(int32(providerEventRetention.Seconds())),
+			},
+		),
+	},
+	{
+		Version:     "0008",
+		Description: "invoice number, reservation and listing indexes",
+		Up: createIndexesIn(invoicesCollection,
+			mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "sequence", Value: 1}}, Options: options.Index().SetUnique(true)},
+			// A reservation is invoiced once, unique across the lines of all invoices
+			mongo.IndexModel{Keys: bson.D{{Key: "lines.reservation_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "issued_at", Value: -1}, {Key: "_id", Value: -1}}},
+		),
+	},
+	{
+		// The counters are updated inside the invoice transaction,
+		// MongoDB before 4.4 can't create the collection there
+		Version:     "0009",
+		Description: "invoice counters collection",
+		Up:          createCollection(invoiceCountersCollection),
+	},
+	{
+		Version:     "0010",
+		Description: "promo code listing index",
+		Up: createIndexesIn(promoCodesCollection,
+			mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}},
+		),
+	},
+	{
+		// Redemptions and counters are written inside the redemption transaction,
+		// MongoDB before 4.4 can't create the collections there
+		Version:     "0011",
+		Description: "promo redemptions collection",
+		Up:          createCollection(promoRedemptionsCollection),
+	},
+	{
+		Version:     "0012",
+		Description: "promo user counters collection",
+		Up:          createCollection(promoUserCountersCollection),
+	},
+	{
+		// The markers are inserted inside the invoice transaction,
+		// MongoDB before 4.4 can't create the collection there
+		Version:     "0013",
+		Description: "invoiced reservations collection",
+		Up:          createCollection(invoicedReservationsCollection),
+	},
+	{
+		// Credit notes repeat the reservations of the invoice they credit
+		Version:     "0014",
+		Description: "invoiced reservation markers instead of the unique invoice line index",
+		Up:          markInvoicedReservations,
+	},
+}
@@ -25,7 +25,27 @@ func ConnectMock() (*MongoDB, error) {
 		return nil, err
 	}
 
-	collection := client.Database("mock").Collection("mock")
+	db := &MongoDB{
+		Collection: client.Database("mock").Collection("mock"),
+		Outbox:     client.Database("mock").Collection(outboxCollection),
+		Webhooks:   client.Database("mock").Collection(webhooksCollection),
+		Deliveries: client.Database("mock").Collection(deliveriesCollection),
+		Payments:   client.Database("mock").Collection(paymentsCollection),
+		Invoices:   client.Database("mock").Collection(invoicesCollection),
+
+		InvoiceCounters:      client.Database("mock").Collection(invoiceCountersCollection),
+		InvoicedReservations: client.Database("mock").Collection(invoicedReservationsCollection),
+		ProviderEvents:       client.Database("mock").Collection(providerEventsCollection),
+
+		PromoCodes:        client.Database("mock").Collection(promoCodesCollection),
+		PromoRedemptions:  client.Database("mock").Collection(promoRedemptionsCollection),
+		PromoUserCounters: client.Database("mock").Collection(promoUserCountersCollection),
+	}
+
+	db.Transactions, err = supportsTransactions(ctx, client)
+	if err != nil {
+		return nil, err
+	}
 
-	return &MongoDB{Collection: collection}, nil
+	return db, nil
 }
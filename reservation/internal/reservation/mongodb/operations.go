@@ -5,132 +5,141 @@ import (
 	"errors"
 	"time"
 
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type StatusType string
-
-const (
-	StatusValid    StatusType = "valid"
-	StatusCanceled StatusType = "canceled"
-)
-
-type Reservation struct {
-	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	ReservationID string             `json:"reservation_id" bson:"reservation_id" validate:"required"`
-	UserID        string             `json:"user_id" bson:"user_id" validate:"required"`
-	SpotID        string             `json:"spot_id" bson:"spot_id" validate:"required"`
-	StartTime     time.Time          `json:"start_time" bson:"start_time" validate:"required"`
-	EndTime       time.Time          `json:"end_time" bson:"end_time" validate:"required"`
-	Status        StatusType         `json:"status" bson:"status" validate:"required,oneof=valid canceled"`
-	PricePaid     float64            `json:"price_paid" bson:"price_paid" validate:"required,gt=0"`
-	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at" validate:"required"`
-}
-
-func (m *MongoDB) AddReservation(reservation Reservation) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) AddReservation(ctx context.Context, reservation model.Reservation, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	_, err := m.Collection.InsertOne(ctx, reservation)
-	return err
+	return m.withEvents(ctx, events, func(ctx context.Context) error {
+		_, err := m.Collection.InsertOne(ctx, reservation)
+		return err
+	})
 }
 
-func (m *MongoDB) EditReservation(input Reservation) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) EditReservation(ctx context.Context, input model.Reservation, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"reservation_id": bson.M{"$eq": input.ReservationID}}
 
-	res := m.Collection.FindOneAndReplace(ctx, filter, input)
-	return res.Err()
+	return notFound(m.withEvents(ctx, events, func(ctx context.Context) error {
+		return m.Collection.FindOneAndReplace(ctx, filter, input).Err()
+	}))
 }
 
-func (m *MongoDB) DeleteReservation(reservationID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) DeleteReservation(ctx context.Context, reservationID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"reservation_id": bson.M{"$eq": reservationID}}
 
-	res := m.Collection.FindOneAndDelete(ctx, filter)
-	return res.Err()
+	return notFound(m.withEvents(ctx, events, func(ctx context.Context) error {
+		return m.Collection.FindOneAndDelete(ctx, filter).Err()
+	}))
 }
 
-func (m *MongoDB) GetReservation(reservationID string) (Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) GetReservation(ctx context.Context, reservationID string) (model.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"reservation_id": bson.M{"$eq": reservationID}}
 
-	var reservation Reservation
+	var reservation model.Reservation
 	err := m.Collection.FindOne(ctx, filter).Decode(&reservation)
-	return reservation, err
+	return reservation, notFound(err)
 }
 
-func (m *MongoDB) GetAll() ([]Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cursor, err := m.Collection.Find(ctx, bson.M{})
-	if err != nil {
-		return nil, err
+// GetAll returns one page of reservations matching the filter.
+// From and To select reservations overlapping that range.
+func (m *MongoDB) GetAll(ctx context.Context, input model.ReservationFilter, opts model.ListOptions) (model.Page[model.Reservation], error) {
+	filter := bson.M{}
+	if input.UserID != "" {
+		filter["user_id"] = bson.M{"$eq": input.UserID}
+	}
+	if input.SpotID != "" {
+		filter["spot_id"] = bson.M{"$eq": input.SpotID}
+	}
+	if input.Status != "" {
+		filter["status"] = bson.M{"$eq": input.Status}
+	}
+	if input.From != nil {
+		filter["end_time"] = bson.M{"$gt": *input.From}
+	}
+	if input.To != nil {
+		filter["start_time"] = bson.M{"$lt": *input.To}
 	}
-	defer cursor.Close(ctx)
 
-	var reservations []Reservation
-	err = cursor.All(ctx, &reservations)
-	return reservations, err
+	return findPage[model.Reservation](ctx, m.Collection, filter, opts)
 }
 
-type parameterInput string
+// GetActiveReservation returns the valid reservation for the given plate
+// that covers the given moment on the given spot.
+func (m *MongoDB) GetActiveReservation(ctx context.Context, spotID, licensePlate string, at time.Time) (model.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
 
-const (
-	ByUserID parameterInput = "user_id"
-	BySpotID parameterInput = "spot_id"
-)
+	filter := bson.M{
+		"spot_id":       bson.M{"$eq": spotID},
+		"license_plate": bson.M{"$eq": model.NormalizePlate(licensePlate)},
+		"start_time":    bson.M{"$lte": at},
+		"end_time":      bson.M{"$gt": at},
+		"status":        bson.M{"$eq": model.StatusValid},
+	}
 
-func (m *MongoDB) GetReservationsBy(parameter parameterInput, id string) ([]Reservation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	var reservation model.Reservation
+	err := m.Collection.FindOne(ctx, filter).Decode(&reservation)
+	return reservation, notFound(err)
+}
+
+// GetReservationsInWindow returns valid reservations on the given spots that overlap the [from, to) window.
+func (m *MongoDB) GetReservationsInWindow(ctx context.Context, spotIDs []string, from, to time.Time) ([]model.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{string(parameter): bson.M{"$eq": id}}
+	filter := bson.M{
+		"spot_id":    bson.M{"$in": spotIDs},
+		"start_time": bson.M{"$lt": to},
+		"end_time":   bson.M{"$gt": from},
+		"status":     bson.M{"$ne": model.StatusCanceled},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
 
-	cursor, err := m.Collection.Find(ctx, filter)
+	cursor, err := m.Collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	var reservations []Reservation
+	var reservations []model.Reservation
 	err = cursor.All(ctx, &reservations)
 	return reservations, err
 }
 
-type AvailabilityInput struct {
-	SpotIDs   []string  `json:"spot_ids" validate:"required"`
-	StartTime time.Time `json:"start_time" validate:"required"`
-	EndTime   time.Time `json:"end_time" validate:"required"`
-}
-
-func (m *MongoDB) CheckAvailability(input AvailabilityInput) ([]string, error) {
-	return m.checkAvailability(input, "")
+func (m *MongoDB) CheckAvailability(ctx context.Context, input model.AvailabilityInput) ([]string, error) {
+	return m.checkAvailability(ctx, input, "")
 }
 
-func (m *MongoDB) CheckAvailabilityForEdit(input AvailabilityInput, editedReservationID string) ([]string, error) {
+func (m *MongoDB) CheckAvailabilityForEdit(ctx context.Context, input model.AvailabilityInput, editedReservationID string) ([]string, error) {
 	if len(input.SpotIDs) != 1 {
 		return nil, errors.New("edit mode requires exactly one spot ID")
 	}
-	return m.checkAvailability(input, editedReservationID)
+	return m.checkAvailability(ctx, input, editedReservationID)
 }
 
-func (m *MongoDB) checkAvailability(input AvailabilityInput, editedReservationID string) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) checkAvailability(ctx context.Context, input model.AvailabilityInput, editedReservationID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{
 		"spot_id":    bson.M{"$in": input.SpotIDs},
 		"start_time": bson.M{"$lt": input.EndTime},
 		"end_time":   bson.M{"$gt": input.StartTime},
-		"status":     bson.M{"$ne": StatusCanceled},
+		"status":     bson.M{"$ne": model.StatusCanceled},
 	}
 
 	// If we are in edit mode, exclude the edited reservation from the check
@@ -143,7 +152,7 @@ func (m *MongoDB) checkAvailability(input AvailabilityInput, editedReservationID
 		return nil, err
 	}
 
-	var reservations []Reservation
+	var reservations []model.Reservation
 	err = cursor.All(ctx, &reservations)
 	if err != nil {
 		return nil, err
@@ -165,3 +174,11 @@ func (m *MongoDB) checkAvailability(input AvailabilityInput, editedReservationID
 
 	return availableSpots, nil
 }
+
+// notFound translates the driver's missing document error into the store-neutral model.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// outboxCollection holds the events until the relay publishes them
+const outboxCollection = "outbox"
+
+// publishedRetention is how long published events are kept before the TTL index removes them
+const publishedRetention = 7 * 24 * time.Hour
+
+type outboxDocument struct {
+	model.Event `bson:",inline"`
+	PublishedAt *time.Time `bson:"published_at"`
+}
+
+// supportsTransactions tells whether the deployment is a replica set or a sharded cluster,
+// standalone servers reject transactions
+func supportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var reply struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&reply)
+	return reply.SetName != "" || reply.Msg == "isdbgrid", err
+}
+
+// withEvents runs the write and adds the events to the outbox in one transaction.
+// Without transaction support, which MONGO_STANDALONE has to allow, the events are
+// added right after the write and a crash in between loses them.
+func (m *MongoDB) withEvents(ctx context.Context, events []model.Event, write func(ctx context.Context) error) error {
+	if len(events) == 0 {
+		return write(ctx)
+	}
+
+	if !m.Transactions {
+		if err := write(ctx); err != nil {
+			return err
+		}
+		return m.insertEvents(ctx, events)
+	}
+
+	session, err := m.Collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		if err := write(sc); err != nil {
+			return nil, err
+		}
+		return nil, m.insertEvents(sc, events)
+	})
+	return err
+}
+
+func (m *MongoDB) insertEvents(ctx context.Context, events []model.Event) error {
+	docs := make([]any, len(events))
+	for i, event := range events {
+		docs[i] = outboxDocument{Event: event}
+	}
+
+	_, err := m.Outbox.InsertMany(ctx, docs)
+	return err
+}
+
+func (m *MongoDB) PendingEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.Outbox.Find(ctx, bson.M{"published_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []outboxDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	events := make([]model.Event, len(docs))
+	for i, doc := range docs {
+		events[i] = doc.Event
+		events[i].OccurredAt = doc.OccurredAt.UTC()
+	}
+	return events, nil
+}
+
+func (m *MongoDB) MarkPublished(ctx context.Context, ids []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	update := bson.M{"$set": bson.M{"published_at": time.Now()}}
+
+	_, err := m.Outbox.UpdateMany(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,125 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The cursor remembers the sort value and _id of the last item of the page,
+// so the next page starts right after it even if documents share the sort value.
+type pageCursor struct {
+	Value bson.RawValue      `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+func encodeCursor(value bson.RawValue, id primitive.ObjectID) (string, error) {
+	b, err := bson.Marshal(pageCursor{Value: value, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	var c pageCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, model.ErrInvalidCursor
+	}
+	if err := bson.Unmarshal(b, &c); err != nil {
+		return c, model.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+func findPage[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, opts model.ListOptions) (model.Page[T], error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	page := model.Page[T]{Items: []T{}}
+
+	if opts.IncludeTotal {
+		total, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return page, err
+		}
+		page.TotalCount = &total
+	}
+
+	if opts.Limit <= 0 || opts.Limit > model.MaxLimit {
+		opts.Limit = model.DefaultLimit
+	}
+
+	direction, comparison := 1, "$gt"
+	if opts.Descending {
+		direction, comparison = -1, "$lt"
+	}
+
+	pageFilter := filter
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return page, err
+		}
+
+		pageFilter = bson.M{"$and": []bson.M{filter, {
+			"$or": []bson.M{
+				{opts.SortBy: bson.M{comparison: c.Value}},
+				{opts.SortBy: bson.M{"$eq": c.Value}, "_id": bson.M{comparison: c.ID}},
+			},
+		}}}
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: opts.SortBy, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(opts.Limit + 1)
+
+	cursor, err := collection.Find(ctx, pageFilter, findOpts)
+	if err != nil {
+		return page, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.Raw
+	if err := cursor.All(ctx, &docs); err != nil {
+		return page, err
+	}
+
+	hasMore := int64(len(docs)) > opts.Limit
+	if hasMore {
+		docs = docs[:opts.Limit]
+	}
+
+	for _, doc := range docs {
+		var item T
+		if err := bson.Unmarshal(doc, &item); err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, item)
+	}
+
+	if hasMore {
+		last := docs[len(docs)-1]
+		id, ok := last.Lookup("_id").ObjectIDOK()
+		if !ok {
+			return page, errors.New("document without ObjectID")
+		}
+
+		page.NextCursor, err = encodeCursor(last.Lookup(opts.SortBy), id)
+		if err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,118 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	paymentsCollection       = "payments"
+	providerEventsCollection = "payment_provider_events"
+)
+
+// Providers stop retrying a callback long before, so older event IDs can go
+const providerEventRetention = 30 * 24 * time.Hour
+
+type providerEventDocument struct {
+	ID         string    `bson:"_id"`
+	ReceivedAt time.Time `bson:"received_at"`
+}
+
+func (m *MongoDB) AddPayment(ctx context.Context, payment model.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := m.Payments.InsertOne(ctx, payment)
+	return err
+}
+
+func (m *MongoDB) GetPayment(ctx context.Context, paymentID string) (model.Payment, error) {
+	return m.findPayment(ctx, bson.M{"_id": bson.M{"$eq": paymentID}})
+}
+
+func (m *MongoDB) GetReservationPayment(ctx context.Context, reservationID string) (model.Payment, error) {
+	return m.findPayment(ctx, bson.M{"reservation_id": bson.M{"$eq": reservationID}})
+}
+
+func (m *MongoDB) GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (model.Payment, error) {
+	return m.findPayment(ctx, bson.M{"provider": bson.M{"$eq": provider}, "provider_ref": bson.M{"$eq": providerRef}})
+}
+
+func (m *MongoDB) UpdatePayment(ctx context.Context, payment model.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$eq": payment.PaymentID}}
+	return notFound(m.Payments.FindOneAndReplace(ctx, filter, payment).Err())
+}
+
+func (m *MongoDB) ClaimPayments(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"due_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"due_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "due_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	// One at a time, each update claims its payment atomically
+	var claimed []model.Payment
+	for len(claimed) < limit {
+		var payment model.Payment
+		err := m.Payments.FindOneAndUpdate(ctx, filter, update, opts).Decode(&payment)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, utcPayment(payment))
+	}
+	return claimed, nil
+}
+
+func (m *MongoDB) ClaimProviderEvent(ctx context.Context, provider, eventID string, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := m.ProviderEvents.InsertOne(ctx, providerEventDocument{ID: provider + "/" + eventID, ReceivedAt: at})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *MongoDB) ReleaseProviderEvent(ctx context.Context, provider, eventID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := m.ProviderEvents.DeleteOne(ctx, bson.M{"_id": bson.M{"$eq": provider + "/" + eventID}})
+	return err
+}
+
+func (m *MongoDB) findPayment(ctx context.Context, filter bson.M) (model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var payment model.Payment
+	err := m.Payments.FindOne(ctx, filter).Decode(&payment)
+	return utcPayment(payment), notFound(err)
+}
+
+// utcPayment brings the times decoded in local time back to UTC
+func utcPayment(p model.Payment) model.Payment {
+	if p.DueAt != nil {
+		dueAt := p.DueAt.UTC()
+		p.DueAt = &dueAt
+	}
+	p.CreatedAt = p.CreatedAt.UTC()
+	p.UpdatedAt = p.UpdatedAt.UTC()
+	return p
+}
@@ -0,0 +1,246 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	promoCodesCollection       = "promo_codes"
+	promoRedemptionsCollection = "promo_redemptions"
+
+	// promoUserCountersCollection holds how many times each user redeemed each code, keyed by promoUserKey
+	promoUserCountersCollection = "promo_user_counters"
+)
+
+type promoUserCounterDocument struct {
+	Key   string `bson:"_id"`
+	Count int    `bson:"count"`
+}
+
+func promoUserKey(code, userID string) string {
+	return code + "/" + userID
+}
+
+func (m *MongoDB) AddPromoCode(ctx context.Context, promo model.PromoCode) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := m.PromoCodes.InsertOne(ctx, promo)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrDuplicate
+	}
+	return err
+}
+
+func (m *MongoDB) GetPromoCode(ctx context.Context, code string) (model.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var promo model.PromoCode
+	err := m.PromoCodes.FindOne(ctx, bson.M{"_id": bson.M{"$eq": code}}).Decode(&promo)
+	return utcPromoCode(promo), notFound(err)
+}
+
+func (m *MongoDB) GetPromoCodes(ctx context.Context) ([]model.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := m.PromoCodes.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	codes := []model.PromoCode{}
+	if err := cursor.All(ctx, &codes); err != nil {
+		return nil, err
+	}
+	for i := range codes {
+		codes[i] = utcPromoCode(codes[i])
+	}
+	return codes, nil
+}
+
+func (m *MongoDB) DeletePromoCode(ctx context.Context, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return notFound(m.PromoCodes.FindOneAndDelete(ctx, bson.M{"_id": bson.M{"$eq": code}}).Err())
+}
+
+func (m *MongoDB) CountRedemptions(ctx context.Context, code, userID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var counter promoUserCounterDocument
+	err := m.PromoUserCounters.FindOne(ctx, bson.M{"_id": bson.M{"$eq": promoUserKey(code, userID)}}).Decode(&counter)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return counter.Count, err
+}
+
+// RedeemPromoCode takes a redemption of the code, then one of the user, and records it in one
+// transaction. Without transaction support, which MONGO_STANDALONE has to allow, the taken
+// redemptions are given back when a later step fails, see undo.
+func (m *MongoDB) RedeemPromoCode(ctx context.Context, redemption model.PromoRedemption) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if !m.Transactions {
+		return m.redeem(ctx, redemption, true)
+	}
+
+	session, err := m.Collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, m.redeem(sc, redemption, false)
+	})
+	return err
+}
+
+// redeem gives the taken redemptions back on failure when undo is set, a transaction is aborted instead
+func (m *MongoDB) redeem(ctx context.Context, redemption model.PromoRedemption, undo bool) error {
+	// The limit check and the increment are one update, concurrent redemptions can't both pass it
+	var promo model.PromoCode
+	filter := bson.M{
+		"_id": bson.M{"$eq": redemption.Code},
+		"$or": bson.A{
+			bson.M{"max_redemptions": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$redemptions", "$max_redemptions"}}},
+		},
+	}
+	err := m.PromoCodes.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"redemptions": 1}}).Decode(&promo)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if _, err := m.GetPromoCode(ctx, redemption.Code); err != nil {
+			return err
+		}
+		return model.ErrLimitReached
+	}
+	if err != nil {
+		return err
+	}
+
+	// A user at the limit doesn't match the filter, the upsert then hits the existing key
+	key := promoUserKey(redemption.Code, redemption.UserID)
+	filter = bson.M{"_id": bson.M{"$eq": key}}
+	if promo.MaxPerUser > 0 {
+		filter["count"] = bson.M{"$lt": promo.MaxPerUser}
+	}
+	_, err = m.PromoUserCounters.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"count": 1}}, options.Update().SetUpsert(true))
+	if err != nil {
+		if undo {
+			m.undo(ctx, m.PromoCodes, bson.M{"_id": bson.M{"$eq": redemption.Code}}, bson.M{"$inc": bson.M{"redemptions": -1}})
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return model.ErrLimitReached
+		}
+		return err
+	}
+
+	if _, err = m.PromoRedemptions.InsertOne(ctx, redemption); err != nil {
+		if undo {
+			m.undo(ctx, m.PromoCodes, bson.M{"_id": bson.M{"$eq": redemption.Code}}, bson.M{"$inc": bson.M{"redemptions": -1}})
+			m.undo(ctx, m.PromoUserCounters, bson.M{"_id": bson.M{"$eq": key}}, bson.M{"$inc": bson.M{"count": -1}})
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return model.ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *MongoDB) ReleaseRedemption(ctx context.Context, reservationID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if !m.Transactions {
+		return m.release(ctx, reservationID, true)
+	}
+
+	session, err := m.Collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, m.release(sc, reservationID, false)
+	})
+	return err
+}
+
+// release gives the counts back through undo when it's set: once the redemption is deleted,
+// releasing again finds nothing to give back
+func (m *MongoDB) release(ctx context.Context, reservationID string, undo bool) error {
+	var redemption model.PromoRedemption
+	err := m.PromoRedemptions.FindOneAndDelete(ctx, bson.M{"_id": bson.M{"$eq": reservationID}}).Decode(&redemption)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The code may be deleted meanwhile, updating nothing is fine then
+	codeFilter := bson.M{"_id": bson.M{"$eq": redemption.Code}}
+	userFilter := bson.M{"_id": bson.M{"$eq": promoUserKey(redemption.Code, redemption.UserID)}}
+	if undo {
+		m.undo(ctx, m.PromoCodes, codeFilter, bson.M{"$inc": bson.M{"redemptions": -1}})
+		m.undo(ctx, m.PromoUserCounters, userFilter, bson.M{"$inc": bson.M{"count": -1}})
+		return nil
+	}
+
+	if _, err := m.PromoCodes.UpdateOne(ctx, codeFilter, bson.M{"$inc": bson.M{"redemptions": -1}}); err != nil {
+		return err
+	}
+	_, err = m.PromoUserCounters.UpdateOne(ctx, userFilter, bson.M{"$inc": bson.M{"count": -1}})
+	return err
+}
+
+// undoAttempts is how many times undo tries to give a count back
+const undoAttempts = 3
+
+// undo gives a count back without a transaction. It has a context of its own, as the request's
+// may be over, retries and logs a count it couldn't give back, so that it can be fixed by hand.
+func (m *MongoDB) undo(ctx context.Context, coll *mongo.Collection, filter, update bson.M) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	var err error
+	for attempt := 1; attempt <= undoAttempts; attempt++ {
+		if _, err = coll.UpdateOne(ctx, filter, update); err == nil {
+			return
+		}
+		time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+	}
+	log.Printf("ERROR: failed to give a count back in %s, fix it by hand: filter %v, update %v: %v", coll.Name(), filter, update, err)
+}
+
+// utcPromoCode brings the times decoded in local time back to UTC
+func utcPromoCode(p model.PromoCode) model.PromoCode {
+	p.CreatedAt = p.CreatedAt.UTC()
+	if p.ValidFrom != nil {
+		from := p.ValidFrom.UTC()
+		p.ValidFrom = &from
+	}
+	if p.ValidUntil != nil {
+		until := p.ValidUntil.UTC()
+		p.ValidUntil = &until
+	}
+	return p
+}
@@ -0,0 +1,57 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	db, err := ConnectMock()
+	if err != nil {
+		t.Skipf("mock MongoDB is not available: %v", err)
+	}
+	defer db.Disconnect()
+
+	storetest.Run(t, func(t *testing.T) store.ReservationStore {
+		if err := db.Collection.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop collection: %v", err)
+		}
+		if err := db.Outbox.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop outbox: %v", err)
+		}
+		if err := db.Webhooks.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop webhooks: %v", err)
+		}
+		if err := db.Deliveries.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop deliveries: %v", err)
+		}
+		if err := db.Payments.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop payments: %v", err)
+		}
+		if err := db.Invoices.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop invoices: %v", err)
+		}
+		if err := db.InvoiceCounters.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop invoice counters: %v", err)
+		}
+		if err := db.InvoicedReservations.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop invoiced reservations: %v", err)
+		}
+		if err := db.ProviderEvents.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop provider events: %v", err)
+		}
+		if err := db.PromoCodes.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop promo codes: %v", err)
+		}
+		if err := db.PromoRedemptions.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop promo redemptions: %v", err)
+		}
+		if err := db.PromoUserCounters.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop promo user counters: %v", err)
+		}
+		return db
+	})
+}
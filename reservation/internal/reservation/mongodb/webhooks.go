@@ -0,0 +1,186 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	webhooksCollection   = "webhooks"
+	deliveriesCollection = "webhook_deliveries"
+)
+
+// codeDuplicateKey is the server error of an insert that hits a unique index
+const codeDuplicateKey = 11000
+
+func (m *MongoDB) AddWebhook(ctx context.Context, webhook model.Webhook) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := m.Webhooks.InsertOne(ctx, webhook)
+	return err
+}
+
+func (m *MongoDB) GetWebhook(ctx context.Context, webhookID string) (model.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var webhook model.Webhook
+	err := m.Webhooks.FindOne(ctx, bson.M{"_id": bson.M{"$eq": webhookID}}).Decode(&webhook)
+	return webhook, notFound(err)
+}
+
+func (m *MongoDB) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cursor, err := m.Webhooks.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	webhooks := []model.Webhook{}
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (m *MongoDB) DeleteWebhook(ctx context.Context, webhookID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return notFound(m.Webhooks.FindOneAndDelete(ctx, bson.M{"_id": bson.M{"$eq": webhookID}}).Err())
+}
+
+func (m *MongoDB) AddDeliveries(ctx context.Context, deliveries []model.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	docs := make([]any, len(deliveries))
+	for i, d := range deliveries {
+		docs[i] = d
+	}
+
+	// Unordered, so the deliveries after a duplicate are still inserted
+	_, err := m.Deliveries.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if onlyDuplicates(err) {
+		return nil
+	}
+	return err
+}
+
+func (m *MongoDB) GetDelivery(ctx context.Context, deliveryID string) (model.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var delivery model.Delivery
+	err := m.Deliveries.FindOne(ctx, bson.M{"_id": bson.M{"$eq": deliveryID}}).Decode(&delivery)
+	return utcDelivery(delivery), notFound(err)
+}
+
+func (m *MongoDB) GetDeliveries(ctx context.Context, filter model.DeliveryFilter, limit int) ([]model.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if filter.WebhookID != "" {
+		query["webhook_id"] = bson.M{"$eq": filter.WebhookID}
+	}
+	if filter.Status != "" {
+		query["status"] = bson.M{"$eq": filter.Status}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.Deliveries.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []model.Delivery
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	for i := range deliveries {
+		deliveries[i] = utcDelivery(deliveries[i])
+	}
+	return deliveries, nil
+}
+
+func (m *MongoDB) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status":          model.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	// One at a time, each update claims its delivery atomically
+	var claimed []model.Delivery
+	for len(claimed) < limit {
+		var delivery model.Delivery
+		err := m.Deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, utcDelivery(delivery))
+	}
+	return claimed, nil
+}
+
+func (m *MongoDB) UpdateDelivery(ctx context.Context, delivery model.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$eq": delivery.DeliveryID}}
+	return notFound(m.Deliveries.FindOneAndReplace(ctx, filter, delivery).Err())
+}
+
+// onlyDuplicates tells whether every failed write of an insert hit an existing ID
+func onlyDuplicates(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, we := range bulkErr.WriteErrors {
+		if we.Code != codeDuplicateKey {
+			return false
+		}
+	}
+	return true
+}
+
+// utcDelivery brings the times decoded in local time back to UTC
+func utcDelivery(d model.Delivery) model.Delivery {
+	d.Event.OccurredAt = d.Event.OccurredAt.UTC()
+	d.NextAttemptAt = d.NextAttemptAt.UTC()
+	d.CreatedAt = d.CreatedAt.UTC()
+	d.UpdatedAt = d.UpdatedAt.UTC()
+	for i := range d.Attempts {
+		d.Attempts[i].At = d.Attempts[i].At.UTC()
+	}
+	return d
+}
@@ -0,0 +1,84 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/webhook"
+)
+
+// Payment methods the fake provider treats specially, any other one is authorized
+const (
+	FakeMethodDeclined       = "pm_card_declined"
+	FakeMethodActionRequired = "pm_card_authentication_required"
+)
+
+// FakeSignatureHeader carries the signature of a fake callback, see Fake.ParseCallback
+const FakeSignatureHeader = "Fake-Signature"
+
+// Fake is a provider for local runs and tests that never moves money. It keeps no
+// state: the references derive from the idempotency keys and every settlement succeeds.
+type Fake struct {
+	// Secret verifies the callbacks, they are accepted unsigned while it's empty
+	Secret string
+}
+
+// fakeCallback is the body of a fake callback
+type fakeCallback struct {
+	ID            string              `json:"id"`
+	PaymentRef    string              `json:"payment_ref"`
+	Status        model.PaymentStatus `json:"status"`
+	FailureReason string              `json:"failure_reason,omitempty"`
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	result := Result{Ref: "fake_" + req.IdempotencyKey}
+
+	switch req.PaymentMethod {
+	case FakeMethodDeclined:
+		result.Status = model.PaymentFailed
+		result.FailureReason = "card_declined"
+	case FakeMethodActionRequired:
+		result.Status = model.PaymentPending
+		result.ClientSecret = result.Ref + "_secret"
+	default:
+		result.Status = model.PaymentAuthorized
+	}
+	return result, nil
+}
+
+func (f *Fake) Capture(ctx context.Context, ref string, amount int64, idempotencyKey string) (Result, error) {
+	return Result{Ref: ref, Status: model.PaymentCaptured}, nil
+}
+
+func (f *Fake) Refund(ctx context.Context, ref string, amount int64, idempotencyKey string) (Result, error) {
+	return Result{Ref: ref, Status: model.PaymentRefunded}, nil
+}
+
+func (f *Fake) Void(ctx context.Context, ref string, idempotencyKey string) (Result, error) {
+	return Result{Ref: ref, Status: model.PaymentVoided}, nil
+}
+
+// ParseCallback reads a JSON body with the id, payment_ref, status and failure_reason of
+// the change. The Fake-Signature header signs it like the service's own webhook deliveries.
+func (f *Fake) ParseCallback(header http.Header, body []byte) (Callback, error) {
+	if f.Secret != "" {
+		if err := webhook.Verify(f.Secret, header.Get(FakeSignatureHeader), body, 5*time.Minute, time.Now()); err != nil {
+			return Callback{}, ErrInvalidSignature
+		}
+	}
+
+	var cb fakeCallback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return Callback{}, err
+	}
+
+	return Callback{EventID: cb.ID, Ref: cb.PaymentRef, Status: cb.Status, FailureReason: cb.FailureReason}, nil
+}
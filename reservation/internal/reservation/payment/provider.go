@@ -0,0 +1,69 @@
+// Package payment talks to the payment providers. The reservation price is authorized
+// when the reservation is booked, captured when it starts, and voided or refunded when
+// it's canceled. The server drives that lifecycle, a Provider only makes the calls.
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+// ErrInvalidSignature is returned for a callback that wasn't signed by the provider
+var ErrInvalidSignature = errors.New("invalid callback signature")
+
+// Provider authorizes and settles payments. A declined payment is not an error: it comes
+// back as a Result with the failed status, errors are left for calls that can be retried.
+//
+// Every call takes an idempotency key, the provider performs a call sent again with the
+// same key only once, so a retry after a timeout can't charge the customer twice.
+type Provider interface {
+	// Name identifies the provider in the stored payments
+	Name() string
+
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+
+	// Capture collects the amount, at most the authorized one, the rest is released
+	Capture(ctx context.Context, ref string, amount int64, idempotencyKey string) (Result, error)
+
+	// Refund gives back the amount, at most the captured one
+	Refund(ctx context.Context, ref string, amount int64, idempotencyKey string) (Result, error)
+
+	// Void releases an authorization that wasn't captured
+	Void(ctx context.Context, ref string, idempotencyKey string) (Result, error)
+
+	// ParseCallback verifies a callback request of the provider and reads the change it
+	// reports, it returns ErrInvalidSignature when the signature doesn't match the body
+	ParseCallback(header http.Header, body []byte) (Callback, error)
+}
+
+// AuthorizeRequest asks to hold the amount on the payment method until it's captured
+type AuthorizeRequest struct {
+	IdempotencyKey string
+	Amount         int64
+	Currency       string
+	PaymentMethod  string
+	Description    string
+	Metadata       map[string]string
+}
+
+// Result is the state of the payment at the provider after a call
+type Result struct {
+	Ref    string
+	Status model.PaymentStatus
+
+	// ClientSecret is set while the customer has to finish the authorization, e.g. with 3-D Secure
+	ClientSecret  string
+	FailureReason string
+}
+
+// Callback is a payment change reported by the provider. Status is empty for the
+// events that don't change a payment, they are acknowledged and ignored.
+type Callback struct {
+	EventID       string
+	Ref           string
+	Status        model.PaymentStatus
+	FailureReason string
+}
@@ -0,0 +1,262 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/webhook"
+)
+
+// StripeSignatureHeader carries the signature of the Stripe webhook events
+const StripeSignatureHeader = "Stripe-Signature"
+
+// Stripe authorizes with PaymentIntents confirmed right away with a manual capture,
+// so the amount is held on the card until Capture. It talks to the REST API directly.
+type Stripe struct {
+	APIKey        string
+	WebhookSecret string
+	BaseURL       string
+	Client        *http.Client
+
+	// Tolerance bounds the age of a webhook signature
+	Tolerance time.Duration
+
+	now func() time.Time
+}
+
+func NewStripe(apiKey, webhookSecret, baseURL string, timeout time.Duration) *Stripe {
+	return &Stripe{
+		APIKey:        apiKey,
+		WebhookSecret: webhookSecret,
+		BaseURL:       strings.TrimSuffix(baseURL, "/"),
+		Client:        &http.Client{Timeout: timeout},
+		Tolerance:     5 * time.Minute,
+		now:           time.Now,
+	}
+}
+
+// stripeIntent is the part of a PaymentIntent the service reads
+type stripeIntent struct {
+	ID               string `json:"id"`
+	Object           string `json:"object"`
+	Status           string `json:"status"`
+	ClientSecret     string `json:"client_secret"`
+	LastPaymentError *struct {
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+	} `json:"last_payment_error"`
+}
+
+// stripeError is the error body of the API, card errors come with the declined intent
+type stripeError struct {
+	Error struct {
+		Type          string        `json:"type"`
+		Code          string        `json:"code"`
+		DeclineCode   string        `json:"decline_code"`
+		Message       string        `json:"message"`
+		PaymentIntent *stripeIntent `json:"payment_intent"`
+	} `json:"error"`
+}
+
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+func (s *Stripe) Name() string {
+	return "stripe"
+}
+
+func (s *Stripe) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	form.Set("currency", req.Currency)
+	form.Set("payment_method", req.PaymentMethod)
+	form.Set("capture_method", "manual")
+	form.Set("confirm", "true")
+	// Without redirects the customer finishes 3-D Secure in the app with the client secret
+	form.Set("automatic_payment_methods[enabled]", "true")
+	form.Set("automatic_payment_methods[allow_redirects]", "never")
+	if req.Description != "" {
+		form.Set("description", req.Description)
+	}
+	for key, value := range req.Metadata {
+		form.Set("metadata["+key+"]", value)
+	}
+
+	var intent stripeIntent
+	declined, err := s.post(ctx, "/v1/payment_intents", form, req.IdempotencyKey, &intent)
+	if err != nil {
+		return Result{}, err
+	}
+	if declined != nil {
+		return *declined, nil
+	}
+
+	return intentResult(intent), nil
+}
+
+func (s *Stripe) Capture(ctx context.Context, ref string, amount int64, idempotencyKey string) (Result, error) {
+	form := url.Values{}
+	form.Set("amount_to_capture", strconv.FormatInt(amount, 10))
+
+	var intent stripeIntent
+	if _, err := s.post(ctx, "/v1/payment_intents/"+url.PathEscape(ref)+"/capture", form, idempotencyKey, &intent); err != nil {
+		return Result{}, err
+	}
+	return intentResult(intent), nil
+}
+
+func (s *Stripe) Refund(ctx context.Context, ref string, amount int64, idempotencyKey string) (Result, error) {
+	form := url.Values{}
+	form.Set("payment_intent", ref)
+	form.Set("amount", strconv.FormatInt(amount, 10))
+
+	var refund struct {
+		Status        string `json:"status"`
+		FailureReason string `json:"failure_reason"`
+	}
+	if _, err := s.post(ctx, "/v1/refunds", form, idempotencyKey, &refund); err != nil {
+		return Result{}, err
+	}
+
+	// A pending refund is on its way, Stripe doesn't take it back
+	if refund.Status == "failed" || refund.Status == "canceled" {
+		return Result{}, fmt.Errorf("stripe: refund %s: %s", refund.Status, refund.FailureReason)
+	}
+	return Result{Ref: ref, Status: model.PaymentRefunded}, nil
+}
+
+func (s *Stripe) Void(ctx context.Context, ref string, idempotencyKey string) (Result, error) {
+	var intent stripeIntent
+	if _, err := s.post(ctx, "/v1/payment_intents/"+url.PathEscape(ref)+"/cancel", url.Values{}, idempotencyKey, &intent); err != nil {
+		return Result{}, err
+	}
+	return intentResult(intent), nil
+}
+
+// ParseCallback verifies the Stripe-Signature header and reads the PaymentIntent and refund events
+func (s *Stripe) ParseCallback(header http.Header, body []byte) (Callback, error) {
+	if err := webhook.Verify(s.WebhookSecret, header.Get(StripeSignatureHeader), body, s.Tolerance, s.now()); err != nil {
+		return Callback{}, ErrInvalidSignature
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Callback{}, err
+	}
+	cb := Callback{EventID: event.ID}
+
+	switch event.Type {
+	case "payment_intent.amount_capturable_updated", "payment_intent.succeeded",
+		"payment_intent.payment_failed", "payment_intent.canceled":
+		var intent stripeIntent
+		if err := json.Unmarshal(event.Data.Object, &intent); err != nil {
+			return cb, err
+		}
+		result := intentResult(intent)
+		cb.Ref, cb.Status, cb.FailureReason = result.Ref, result.Status, result.FailureReason
+	case "charge.refunded":
+		var charge struct {
+			PaymentIntent  string `json:"payment_intent"`
+			Amount         int64  `json:"amount"`
+			AmountRefunded int64  `json:"amount_refunded"`
+		}
+		if err := json.Unmarshal(event.Data.Object, &charge); err != nil {
+			return cb, err
+		}
+		// A partial refund leaves the payment captured
+		if charge.AmountRefunded >= charge.Amount {
+			cb.Ref, cb.Status = charge.PaymentIntent, model.PaymentRefunded
+		}
+	}
+	return cb, nil
+}
+
+// post sends a form to the API and decodes the response into out. A declined card is
+// returned as a failed result rather than an error.
+func (s *Stripe) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil, json.Unmarshal(body, out)
+	}
+
+	var apiErr stripeError
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		return nil, fmt.Errorf("stripe: unexpected status %d", resp.StatusCode)
+	}
+	if apiErr.Error.Type == "card_error" {
+		declined := Result{Status: model.PaymentFailed, FailureReason: declineReason(apiErr.Error.Code, apiErr.Error.DeclineCode)}
+		if apiErr.Error.PaymentIntent != nil {
+			declined.Ref = apiErr.Error.PaymentIntent.ID
+		}
+		return &declined, nil
+	}
+	return nil, fmt.Errorf("stripe: %s (status %d, code %s)", apiErr.Error.Message, resp.StatusCode, apiErr.Error.Code)
+}
+
+// intentResult maps the PaymentIntent statuses onto the payment ones
+func intentResult(intent stripeIntent) Result {
+	result := Result{Ref: intent.ID}
+
+	switch intent.Status {
+	case "requires_capture":
+		result.Status = model.PaymentAuthorized
+	case "succeeded":
+		result.Status = model.PaymentCaptured
+	case "canceled":
+		result.Status = model.PaymentVoided
+	case "requires_payment_method":
+		// Back to the start after a failed attempt
+		result.Status = model.PaymentFailed
+		if e := intent.LastPaymentError; e != nil {
+			result.FailureReason = declineReason(e.Code, e.DeclineCode)
+		} else {
+			result.FailureReason = "payment_failed"
+		}
+	default:
+		// requires_action, requires_confirmation, processing
+		result.Status = model.PaymentPending
+		result.ClientSecret = intent.ClientSecret
+	}
+	return result
+}
+
+func declineReason(code, declineCode string) string {
+	if declineCode != "" {
+		return declineCode
+	}
+	if code != "" {
+		return code
+	}
+	return "card_declined"
+}
@@ -0,0 +1,210 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/webhook"
+)
+
+const webhookSecret = "whsec_0123456789abcdef"
+
+// stripeAPI answers every request with the status and body of its path
+type stripeAPI struct {
+	t         *testing.T
+	responses map[string]string
+	statuses  map[string]int
+	requests  []*http.Request
+}
+
+func (api *stripeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		api.t.Errorf("Failed to parse the form: %v", err)
+	}
+	api.requests = append(api.requests, r)
+
+	body, ok := api.responses[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if status := api.statuses[r.URL.Path]; status != 0 {
+		w.WriteHeader(status)
+	}
+	w.Write([]byte(body))
+}
+
+func newStripe(t *testing.T, api *stripeAPI) *Stripe {
+	t.Helper()
+	api.t = t
+
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return NewStripe("sk_test_123", webhookSecret, srv.URL, 5*time.Second)
+}
+
+func TestStripeAuthorize(t *testing.T) {
+	api := &stripeAPI{responses: map[string]string{
+		"/v1/payment_intents": `{"id":"pi_1","object":"payment_intent","status":"requires_capture","client_secret":"pi_1_secret"}`,
+	}}
+	st := newStripe(t, api)
+
+	result, err := st.Authorize(context.Background(), AuthorizeRequest{
+		IdempotencyKey: "p1",
+		Amount:         1050,
+		Currency:       "eur",
+		PaymentMethod:  "pm_card_visa",
+		Metadata:       map[string]string{"reservation_id": "r1"},
+	})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if result.Ref != "pi_1" || result.Status != model.PaymentAuthorized || result.ClientSecret != "" {
+		t.Errorf("Authorize() = %+v, want the authorized intent", result)
+	}
+
+	req := api.requests[0]
+	if req.Header.Get("Idempotency-Key") != "p1" || req.Header.Get("Authorization") != "Bearer sk_test_123" {
+		t.Errorf("got headers %v, want the idempotency key and the API key", req.Header)
+	}
+	if req.PostForm.Get("capture_method") != "manual" || req.PostForm.Get("amount") != "1050" ||
+		req.PostForm.Get("metadata[reservation_id]") != "r1" {
+		t.Errorf("got form %v, want a manual capture of the amount", req.PostForm)
+	}
+}
+
+func TestStripeAuthorizeOutcomes(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		want     Result
+		wantsErr bool
+	}{
+		{
+			"action required", http.StatusOK,
+			`{"id":"pi_2","status":"requires_action","client_secret":"pi_2_secret"}`,
+			Result{Ref: "pi_2", Status: model.PaymentPending, ClientSecret: "pi_2_secret"}, false,
+		},
+		{
+			"declined", http.StatusPaymentRequired,
+			`{"error":{"type":"card_error","code":"card_declined","decline_code":"insufficient_funds","payment_intent":{"id":"pi_3"}}}`,
+			Result{Ref: "pi_3", Status: model.PaymentFailed, FailureReason: "insufficient_funds"}, false,
+		},
+		{
+			"API error", http.StatusBadRequest,
+			`{"error":{"type":"invalid_request_error","code":"parameter_missing","message":"Missing amount"}}`,
+			Result{}, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stripeAPI{
+				responses: map[string]string{"/v1/payment_intents": tt.body},
+				statuses:  map[string]int{"/v1/payment_intents": tt.status},
+			}
+			result, err := newStripe(t, api).Authorize(context.Background(), AuthorizeRequest{IdempotencyKey: "p1", Amount: 100, Currency: "eur"})
+			if (err != nil) != tt.wantsErr {
+				t.Fatalf("Authorize() error = %v, wants error %v", err, tt.wantsErr)
+			}
+			if result != tt.want {
+				t.Errorf("Authorize() = %+v, want %+v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripeSettle(t *testing.T) {
+	api := &stripeAPI{responses: map[string]string{
+		"/v1/payment_intents/pi_1/capture": `{"id":"pi_1","status":"succeeded"}`,
+		"/v1/payment_intents/pi_2/cancel":  `{"id":"pi_2","status":"canceled"}`,
+		"/v1/refunds":                      `{"id":"re_1","status":"pending"}`,
+	}}
+	st := newStripe(t, api)
+	ctx := context.Background()
+
+	if result, err := st.Capture(ctx, "pi_1", 800, "p1-capture"); err != nil || result.Status != model.PaymentCaptured {
+		t.Errorf("Capture() = %+v, %v, want captured", result, err)
+	}
+	if got := api.requests[0].PostForm.Get("amount_to_capture"); got != "800" {
+		t.Errorf("got amount_to_capture %q, want 800", got)
+	}
+	if result, err := st.Void(ctx, "pi_2", "p2-void"); err != nil || result.Status != model.PaymentVoided {
+		t.Errorf("Void() = %+v, %v, want voided", result, err)
+	}
+	if result, err := st.Refund(ctx, "pi_1", 800, "p1-refund"); err != nil || result.Status != model.PaymentRefunded {
+		t.Errorf("Refund() = %+v, %v, want refunded", result, err)
+	}
+	if got := api.requests[2].PostForm.Get("payment_intent"); got != "pi_1" {
+		t.Errorf("got refunded intent %q, want pi_1", got)
+	}
+}
+
+func TestStripeParseCallback(t *testing.T) {
+	st := NewStripe("sk_test_123", webhookSecret, "", time.Second)
+	now := time.Now()
+
+	signed := func(body string) http.Header {
+		header := http.Header{}
+		header.Set(StripeSignatureHeader, webhook.Sign(webhookSecret, now, []byte(body)))
+		return header
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want Callback
+	}{
+		{
+			"authorized",
+			`{"id":"evt_1","type":"payment_intent.amount_capturable_updated","data":{"object":{"id":"pi_1","status":"requires_capture"}}}`,
+			Callback{EventID: "evt_1", Ref: "pi_1", Status: model.PaymentAuthorized},
+		},
+		{
+			"failed",
+			`{"id":"evt_2","type":"payment_intent.payment_failed","data":{"object":{"id":"pi_1","status":"requires_payment_method","last_payment_error":{"code":"card_declined","decline_code":"expired_card"}}}}`,
+			Callback{EventID: "evt_2", Ref: "pi_1", Status: model.PaymentFailed, FailureReason: "expired_card"},
+		},
+		{
+			"refunded",
+			`{"id":"evt_3","type":"charge.refunded","data":{"object":{"payment_intent":"pi_1","amount":1050,"amount_refunded":1050}}}`,
+			Callback{EventID: "evt_3", Ref: "pi_1", Status: model.PaymentRefunded},
+		},
+		{
+			"partially refunded",
+			`{"id":"evt_4","type":"charge.refunded","data":{"object":{"payment_intent":"pi_1","amount":1050,"amount_refunded":50}}}`,
+			Callback{EventID: "evt_4"},
+		},
+		{
+			"ignored",
+			`{"id":"evt_5","type":"customer.created","data":{"object":{"id":"cus_1"}}}`,
+			Callback{EventID: "evt_5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := st.ParseCallback(signed(tt.body), []byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParseCallback() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCallback() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	body := tests[0].body
+	if _, err := st.ParseCallback(signed(body), []byte(body+" ")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("ParseCallback() of a changed body error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := st.ParseCallback(http.Header{}, []byte(body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("ParseCallback() without a signature error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
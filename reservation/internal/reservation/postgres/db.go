@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres is the store.ReservationStore for deployments without MongoDB
+var _ store.ReservationStore = (*Postgres)(nil)
+
+type Postgres struct {
+	Pool *pgxpool.Pool
+}
+
+// Connect opens a connection pool and brings the schema up to date
+func Connect(url string) (*Postgres, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConnConfig.Tracer = metrics.PostgresTracer{}
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	if err := Migrate(ctx, pool); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return &Postgres{Pool: pool}, nil
+}
+
+func (p *Postgres) Ping(ctx context.Context) error {
+	return p.Pool.Ping(ctx)
+}
+
+func (p *Postgres) Disconnect() {
+	p.Pool.Close()
+}
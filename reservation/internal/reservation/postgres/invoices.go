@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const invoiceColumns = `id, tenant_id, sequence, number, kind, user_id, period, seller, buyer, currency, lines,
+	taxes, net, tax, total, issued_at, credited_invoice_id, credited_number`
+
+// uniqueViolation is the SQLSTATE of a row breaking a unique constraint
+const uniqueViolation = "23505"
+
+// AddInvoice takes the number and inserts the invoice in one transaction. The counter row
+// stays locked until the commit, so concurrent invoices of the tenant wait for their number.
+func (p *Postgres) AddInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	err := pgx.BeginFunc(ctx, p.Pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `INSERT INTO invoice_counters (tenant_id, last_sequence) VALUES ($1, 1)
+			ON CONFLICT (tenant_id) DO UPDATE SET last_sequence = invoice_counters.last_sequence + 1
+			RETURNING last_sequence`, invoice.TenantID).Scan(&invoice.Sequence)
+		if err != nil {
+			return err
+		}
+		invoice.Number = model.InvoiceNumber(invoice.TenantID, invoice.Sequence)
+
+		_, err = tx.Exec(ctx, `INSERT INTO invoices (`+invoiceColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+			invoice.InvoiceID, invoice.TenantID, invoice.Sequence, invoice.Number, invoice.Kind, invoice.UserID,
+			invoice.Period, invoice.Seller, invoice.Buyer, invoice.Currency, invoice.Lines, invoice.Taxes,
+			invoice.Net, invoice.Tax, invoice.Total, invoice.IssuedAt, invoice.CreditedInvoiceID, invoice.CreditedNumber)
+		if err != nil {
+			return err
+		}
+
+		// Only invoices are unique per reservation, not credit notes
+		table := "invoiced_reservations"
+		if invoice.Kind == model.InvoiceCreditNote {
+			table = "credited_reservations"
+		}
+		for _, line := range invoice.Lines {
+			_, err := tx.Exec(ctx, `INSERT INTO `+table+` (reservation_id, invoice_id) VALUES ($1, $2)`,
+				line.ReservationID, invoice.InvoiceID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return invoice, model.ErrDuplicate
+	}
+	return invoice, err
+}
+
+func (p *Postgres) GetInvoice(ctx context.Context, invoiceID string) (model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanInvoice(p.Pool.QueryRow(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, invoiceID))
+}
+
+func (p *Postgres) GetReservationInvoice(ctx context.Context, reservationID string) (model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanInvoice(p.Pool.QueryRow(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id = (
+		SELECT invoice_id FROM invoiced_reservations WHERE reservation_id = $1)`, reservationID))
+}
+
+func (p *Postgres) GetCreditNotes(ctx context.Context, reservationID string) ([]model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := p.Pool.Query(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id IN (
+		SELECT invoice_id FROM credited_reservations WHERE reservation_id = $1) ORDER BY sequence`, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []model.Invoice{}
+	for rows.Next() {
+		note, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+func (p *Postgres) GetInvoices(ctx context.Context, filter model.InvoiceFilter, limit int) ([]model.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var conditions []string
+	var args []any
+	if filter.TenantID != "" {
+		args = append(args, filter.TenantID)
+		conditions = append(conditions, fmt.Sprintf("tenant_id = $%d", len(args)))
+	}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.Period != "" {
+		args = append(args, filter.Period)
+		conditions = append(conditions, fmt.Sprintf("period = $%d", len(args)))
+	}
+
+	query := `SELECT ` + invoiceColumns + ` FROM invoices`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY issued_at DESC, id DESC LIMIT $%d`, len(args))
+
+	rows, err := p.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []model.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
+
+func scanInvoice(row pgx.Row) (model.Invoice, error) {
+	var inv model.Invoice
+
+	err := row.Scan(&inv.InvoiceID, &inv.TenantID, &inv.Sequence, &inv.Number, &inv.Kind, &inv.UserID,
+		&inv.Period, &inv.Seller, &inv.Buyer, &inv.Currency, &inv.Lines, &inv.Taxes, &inv.Net, &inv.Tax,
+		&inv.Total, &inv.IssuedAt, &inv.CreditedInvoiceID, &inv.CreditedNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return inv, model.ErrNotFound
+		}
+		return inv, err
+	}
+
+	inv.IssuedAt = inv.IssuedAt.UTC()
+	return inv, nil
+}
@@ -0,0 +1,28 @@
+-- btree_gist lets the exclusion constraint compare spot_id with =
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE reservations (
+    id             TEXT COLLATE "C" PRIMARY KEY,
+    reservation_id TEXT NOT NULL UNIQUE,
+    user_id        TEXT NOT NULL,
+    spot_id        TEXT NOT NULL,
+    start_time     TIMESTAMPTZ NOT NULL,
+    end_time       TIMESTAMPTZ NOT NULL,
+    period         TSTZRANGE GENERATED ALWAYS AS (tstzrange(start_time, end_time, '[)')) STORED,
+    status         TEXT NOT NULL CHECK (status IN ('valid', 'canceled')),
+    price_paid     DOUBLE PRECISION NOT NULL,
+    vehicle_id     TEXT NOT NULL DEFAULT '',
+    license_plate  TEXT NOT NULL DEFAULT '',
+    updated_at     TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT reservations_period_check CHECK (start_time < end_time),
+
+    -- Two valid reservations can never share a spot at the same time,
+    -- no matter how many service instances are writing
+    CONSTRAINT reservations_no_overlap EXCLUDE USING gist (spot_id WITH =, period WITH &&)
+        WHERE (status = 'valid')
+);
+
+CREATE INDEX reservations_user_id_idx ON reservations (user_id);
+CREATE INDEX reservations_start_time_idx ON reservations (start_time, id);
+CREATE INDEX reservations_plate_idx ON reservations (spot_id, license_plate);
@@ -0,0 +1,24 @@
+CREATE TABLE webhooks (
+    id          TEXT COLLATE "C" PRIMARY KEY,
+    url         TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret      TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+-- Deliveries outlive their webhook, so the log stays readable after it's deleted
+CREATE TABLE webhook_deliveries (
+    id              TEXT COLLATE "C" PRIMARY KEY,
+    webhook_id      TEXT NOT NULL,
+    event           JSONB NOT NULL,
+    status          TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts        JSONB NOT NULL DEFAULT '[]',
+    failures        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, created_at DESC, id DESC);
+CREATE INDEX webhook_deliveries_created_idx ON webhook_deliveries (created_at DESC, id DESC);
@@ -0,0 +1,35 @@
+-- Pending reservations wait for their payment and hold the spot meanwhile
+ALTER TABLE reservations DROP CONSTRAINT reservations_status_check;
+ALTER TABLE reservations ADD CONSTRAINT reservations_status_check CHECK (status IN ('pending', 'valid', 'canceled'));
+
+ALTER TABLE reservations DROP CONSTRAINT reservations_no_overlap;
+ALTER TABLE reservations ADD CONSTRAINT reservations_no_overlap EXCLUDE USING gist (spot_id WITH =, period WITH &&)
+    WHERE (status <> 'canceled');
+
+CREATE TABLE payments (
+    id              TEXT COLLATE "C" PRIMARY KEY,
+    reservation_id  TEXT NOT NULL UNIQUE,
+    provider        TEXT NOT NULL,
+    provider_ref    TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL CHECK (status IN ('pending', 'authorized', 'captured', 'voided', 'refunded', 'failed')),
+    currency        TEXT NOT NULL,
+    amount          BIGINT NOT NULL,
+    amount_captured BIGINT NOT NULL DEFAULT 0,
+    amount_refunded BIGINT NOT NULL DEFAULT 0,
+    client_secret   TEXT NOT NULL DEFAULT '',
+    failure_reason  TEXT NOT NULL DEFAULT '',
+    due_at          TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX payments_provider_ref_idx ON payments (provider, provider_ref);
+CREATE INDEX payments_due_idx ON payments (due_at) WHERE due_at IS NOT NULL;
+
+-- The IDs of the handled provider callbacks, a callback sent again is acknowledged without effect
+CREATE TABLE payment_provider_events (
+    provider    TEXT NOT NULL,
+    event_id    TEXT NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (provider, event_id)
+);
@@ -0,0 +1,2 @@
+-- What was kept and refunded when a reservation was canceled under a cancellation policy
+ALTER TABLE reservations ADD COLUMN cancellation JSONB;
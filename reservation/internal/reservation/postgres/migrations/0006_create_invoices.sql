@@ -0,0 +1,35 @@
+-- The last invoice sequence number of each tenant, locked while an invoice is issued so numbers have no gaps
+CREATE TABLE invoice_counters (
+    tenant_id     TEXT PRIMARY KEY,
+    last_sequence BIGINT NOT NULL
+);
+
+CREATE TABLE invoices (
+    id        TEXT COLLATE "C" PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    sequence  BIGINT NOT NULL,
+    number    TEXT NOT NULL,
+    kind      TEXT NOT NULL CHECK (kind IN ('single', 'monthly')),
+    user_id   TEXT NOT NULL,
+    period    TEXT NOT NULL DEFAULT '',
+    seller    JSONB NOT NULL,
+    buyer     JSONB NOT NULL,
+    currency  TEXT NOT NULL,
+    lines     JSONB NOT NULL,
+    taxes     JSONB NOT NULL,
+    net       DOUBLE PRECISION NOT NULL,
+    tax       DOUBLE PRECISION NOT NULL,
+    total     DOUBLE PRECISION NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL,
+
+    UNIQUE (tenant_id, sequence)
+);
+
+CREATE INDEX invoices_user_idx ON invoices (user_id, issued_at DESC, id DESC);
+CREATE INDEX invoices_issued_idx ON invoices (issued_at DESC, id DESC);
+
+-- A reservation is invoiced once
+CREATE TABLE invoiced_reservations (
+    reservation_id TEXT PRIMARY KEY,
+    invoice_id     TEXT NOT NULL REFERENCES invoices (id) ON DELETE CASCADE
+);
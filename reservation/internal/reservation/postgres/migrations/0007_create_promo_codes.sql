@@ -0,0 +1,29 @@
+-- The promo code a reservation was booked with, its list price and the amount taken off
+ALTER TABLE reservations ADD COLUMN discount JSONB;
+
+CREATE TABLE promo_codes (
+    code            TEXT COLLATE "C" PRIMARY KEY,
+    description     TEXT NOT NULL DEFAULT '',
+    type            TEXT NOT NULL CHECK (type IN ('percent', 'fixed')),
+    value           DOUBLE PRECISION NOT NULL CHECK (value > 0),
+    valid_from      TIMESTAMPTZ,
+    valid_until     TIMESTAMPTZ,
+    max_redemptions INTEGER NOT NULL DEFAULT 0,
+    max_per_user    INTEGER NOT NULL DEFAULT 0,
+    restrictions    JSONB NOT NULL,
+    redemptions     INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX promo_codes_created_idx ON promo_codes (created_at DESC, code);
+
+-- Redemptions outlive a deleted code, so there is no foreign key
+CREATE TABLE promo_redemptions (
+    reservation_id TEXT PRIMARY KEY,
+    code           TEXT NOT NULL,
+    user_id        TEXT NOT NULL,
+    discount       DOUBLE PRECISION NOT NULL,
+    redeemed_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX promo_redemptions_user_idx ON promo_redemptions (code, user_id);
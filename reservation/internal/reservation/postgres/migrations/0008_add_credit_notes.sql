@@ -0,0 +1,13 @@
+-- Credit notes are invoices taking back part of the invoice they credit
+ALTER TABLE invoices DROP CONSTRAINT invoices_kind_check;
+ALTER TABLE invoices ADD CONSTRAINT invoices_kind_check CHECK (kind IN ('single', 'monthly', 'credit_note'));
+ALTER TABLE invoices ADD COLUMN credited_invoice_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE invoices ADD COLUMN credited_number TEXT NOT NULL DEFAULT '';
+
+-- A reservation can be credited any number of times
+CREATE TABLE credited_reservations (
+    reservation_id TEXT NOT NULL,
+    invoice_id     TEXT NOT NULL REFERENCES invoices (id) ON DELETE CASCADE,
+
+    PRIMARY KEY (reservation_id, invoice_id)
+);
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const columns = `id, reservation_id, user_id, spot_id, start_time, end_time, status, price_paid, vehicle_id, license_plate, updated_at, cancellation, discount`
+
+// exclusionViolation is the SQLSTATE of a row rejected by reservations_no_overlap
+const exclusionViolation = "23P01"
+
+func (p *Postgres) AddReservation(ctx context.Context, reservation model.Reservation, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if reservation.ID.IsZero() {
+		reservation.ID = primitive.NewObjectID()
+	}
+
+	return overlap(p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO reservations (`+columns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+			reservation.ID.Hex(), reservation.ReservationID, reservation.UserID, reservation.SpotID,
+			reservation.StartTime, reservation.EndTime, reservation.Status, reservation.PricePaid,
+			reservation.VehicleID, reservation.LicensePlate, reservation.UpdatedAt, reservation.Cancellation, reservation.Discount)
+		return err
+	}))
+}
+
+func (p *Postgres) EditReservation(ctx context.Context, input model.Reservation, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return overlap(p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE reservations SET user_id = $2, spot_id = $3, start_time = $4, end_time = $5,
+			status = $6, price_paid = $7, vehicle_id = $8, license_plate = $9, updated_at = $10, cancellation = $11,
+			discount = $12
+			WHERE reservation_id = $1`,
+			input.ReservationID, input.UserID, input.SpotID, input.StartTime, input.EndTime,
+			input.Status, input.PricePaid, input.VehicleID, input.LicensePlate, input.UpdatedAt, input.Cancellation,
+			input.Discount)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return model.ErrNotFound
+		}
+
+		return nil
+	}))
+}
+
+func (p *Postgres) DeleteReservation(ctx context.Context, reservationID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM reservations WHERE reservation_id = $1`, reservationID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return model.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *Postgres) GetReservation(ctx context.Context, reservationID string) (model.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	row := p.Pool.QueryRow(ctx, `SELECT `+columns+` FROM reservations WHERE reservation_id = $1`, reservationID)
+	return scanReservation(row)
+}
+
+// GetAll returns one page of reservations matching the filter.
+// From and To select reservations overlapping that range.
+func (p *Postgres) GetAll(ctx context.Context, input model.ReservationFilter, opts model.ListOptions) (model.Page[model.Reservation], error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var filter where
+	if input.UserID != "" {
+		filter.add("user_id = $%d", input.UserID)
+	}
+	if input.SpotID != "" {
+		filter.add("spot_id = $%d", input.SpotID)
+	}
+	if input.Status != "" {
+		filter.add("status = $%d", input.Status)
+	}
+	if input.From != nil {
+		filter.add("end_time > $%d", *input.From)
+	}
+	if input.To != nil {
+		filter.add("start_time < $%d", *input.To)
+	}
+
+	return findPage(ctx, p.Pool, "reservations", columns, filter, opts, scanReservation,
+		func(r model.Reservation) primitive.ObjectID { return r.ID })
+}
+
+// GetActiveReservation returns the valid reservation for the given plate
+// that covers the given moment on the given spot.
+func (p *Postgres) GetActiveReservation(ctx context.Context, spotID, licensePlate string, at time.Time) (model.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	row := p.Pool.QueryRow(ctx, `SELECT `+columns+` FROM reservations
+		WHERE spot_id = $1 AND license_plate = $2 AND period @> $3::timestamptz AND status = $4
+		LIMIT 1`,
+		spotID, model.NormalizePlate(licensePlate), at, model.StatusValid)
+	return scanReservation(row)
+}
+
+// GetReservationsInWindow returns valid reservations on the given spots that overlap the [from, to) window.
+func (p *Postgres) GetReservationsInWindow(ctx context.Context, spotIDs []string, from, to time.Time) ([]model.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := p.Pool.Query(ctx, `SELECT `+columns+` FROM reservations
+		WHERE spot_id = ANY($1) AND period && tstzrange($2, $3, '[)') AND status <> $4
+		ORDER BY start_time, id`,
+		spotIDs, from, to, model.StatusCanceled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []model.Reservation
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+func (p *Postgres) CheckAvailability(ctx context.Context, input model.AvailabilityInput) ([]string, error) {
+	return p.checkAvailability(ctx, input, "")
+}
+
+func (p *Postgres) CheckAvailabilityForEdit(ctx context.Context, input model.AvailabilityInput, editedReservationID string) ([]string, error) {
+	if len(input.SpotIDs) != 1 {
+		return nil, errors.New("edit mode requires exactly one spot ID")
+	}
+	return p.checkAvailability(ctx, input, editedReservationID)
+}
+
+func (p *Postgres) checkAvailability(ctx context.Context, input model.AvailabilityInput, editedReservationID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// In edit mode the edited reservation is excluded from the check, an empty ID matches no reservation
+	rows, err := p.Pool.Query(ctx, `SELECT DISTINCT spot_id FROM reservations
+		WHERE spot_id = ANY($1) AND period && tstzrange($2, $3, '[)') AND status <> $4 AND reservation_id <> $5`,
+		input.SpotIDs, input.StartTime, input.EndTime, model.StatusCanceled, editedReservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	unavailableSpots, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]struct{}, len(unavailableSpots))
+	for _, spotID := range unavailableSpots {
+		taken[spotID] = struct{}{}
+	}
+
+	var availableSpots []string
+	for _, spotID := range input.SpotIDs {
+		if _, found := taken[spotID]; !found {
+			availableSpots = append(availableSpots, spotID)
+		}
+	}
+
+	return availableSpots, nil
+}
+
+func scanReservation(row pgx.Row, extra ...any) (model.Reservation, error) {
+	var r model.Reservation
+	var id string
+
+	dest := append([]any{&id, &r.ReservationID, &r.UserID, &r.SpotID, &r.StartTime, &r.EndTime,
+		&r.Status, &r.PricePaid, &r.VehicleID, &r.LicensePlate, &r.UpdatedAt, &r.Cancellation, &r.Discount}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r, model.ErrNotFound
+		}
+		return r, err
+	}
+
+	var err error
+	r.ID, err = primitive.ObjectIDFromHex(id)
+	r.StartTime, r.EndTime, r.UpdatedAt = r.StartTime.UTC(), r.EndTime.UTC(), r.UpdatedAt.UTC()
+	return r, err
+}
+
+// overlap translates a violation of the exclusion constraint into model.ErrOverlap
+func overlap(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation {
+		return model.ErrOverlap
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/jackc/pgx/v5"
+)
+
+const paymentColumns = `id, reservation_id, provider, provider_ref, status, currency, amount, amount_captured,
+	amount_refunded, client_secret, failure_reason, due_at, created_at, updated_at`
+
+func (p *Postgres) AddPayment(ctx context.Context, payment model.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := p.Pool.Exec(ctx, `INSERT INTO payments (`+paymentColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		payment.PaymentID, payment.ReservationID, payment.Provider, payment.ProviderRef, payment.Status,
+		payment.Currency, payment.Amount, payment.Captured, payment.Refunded, payment.ClientSecret,
+		payment.FailureReason, payment.DueAt, payment.CreatedAt, payment.UpdatedAt)
+	return err
+}
+
+func (p *Postgres) GetPayment(ctx context.Context, paymentID string) (model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanPayment(p.Pool.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE id = $1`, paymentID))
+}
+
+func (p *Postgres) GetReservationPayment(ctx context.Context, reservationID string) (model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanPayment(p.Pool.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE reservation_id = $1`, reservationID))
+}
+
+func (p *Postgres) GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanPayment(p.Pool.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments
+		WHERE provider = $1 AND provider_ref = $2`, provider, providerRef))
+}
+
+func (p *Postgres) UpdatePayment(ctx context.Context, payment model.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tag, err := p.Pool.Exec(ctx, `UPDATE payments SET reservation_id = $2, provider = $3, provider_ref = $4,
+		status = $5, currency = $6, amount = $7, amount_captured = $8, amount_refunded = $9, client_secret = $10,
+		failure_reason = $11, due_at = $12, created_at = $13, updated_at = $14 WHERE id = $1`,
+		payment.PaymentID, payment.ReservationID, payment.Provider, payment.ProviderRef, payment.Status,
+		payment.Currency, payment.Amount, payment.Captured, payment.Refunded, payment.ClientSecret,
+		payment.FailureReason, payment.DueAt, payment.CreatedAt, payment.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) ClaimPayments(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// SKIP LOCKED lets concurrent instances claim different payments instead of waiting
+	rows, err := p.Pool.Query(ctx, `WITH due AS (
+			SELECT id, due_at FROM payments WHERE due_at <= $1
+			ORDER BY due_at LIMIT $3 FOR UPDATE SKIP LOCKED
+		)
+		UPDATE payments p SET due_at = $2 FROM due WHERE p.id = due.id
+		RETURNING p.id, p.reservation_id, p.provider, p.provider_ref, p.status, p.currency, p.amount,
+			p.amount_captured, p.amount_refunded, p.client_secret, p.failure_reason, p.due_at, p.created_at,
+			p.updated_at, due.due_at`, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []model.Payment
+	dueAt := make(map[string]time.Time)
+	for rows.Next() {
+		var due time.Time
+		payment, err := scanPayment(rows, &due)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, payment)
+		dueAt[payment.PaymentID] = due
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the order of the claiming query
+	sort.SliceStable(claimed, func(i, j int) bool {
+		return dueAt[claimed[i].PaymentID].Before(dueAt[claimed[j].PaymentID])
+	})
+	return claimed, nil
+}
+
+func (p *Postgres) ClaimProviderEvent(ctx context.Context, provider, eventID string, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tag, err := p.Pool.Exec(ctx, `INSERT INTO payment_provider_events (provider, event_id, received_at)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, provider, eventID, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (p *Postgres) ReleaseProviderEvent(ctx context.Context, provider, eventID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := p.Pool.Exec(ctx, `DELETE FROM payment_provider_events WHERE provider = $1 AND event_id = $2`, provider, eventID)
+	return err
+}
+
+func scanPayment(row pgx.Row, extra ...any) (model.Payment, error) {
+	var p model.Payment
+
+	dest := append([]any{&p.PaymentID, &p.ReservationID, &p.Provider, &p.ProviderRef, &p.Status, &p.Currency,
+		&p.Amount, &p.Captured, &p.Refunded, &p.ClientSecret, &p.FailureReason, &p.DueAt, &p.CreatedAt,
+		&p.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return p, model.ErrNotFound
+		}
+		return p, err
+	}
+
+	if p.DueAt != nil {
+		dueAt := p.DueAt.UTC()
+		p.DueAt = &dueAt
+	}
+	p.CreatedAt, p.UpdatedAt = p.CreatedAt.UTC(), p.UpdatedAt.UTC()
+	return p, nil
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const promoColumns = `code, description, type, value, valid_from, valid_until, max_redemptions, max_per_user,
+	restrictions, redemptions, created_at`
+
+func (p *Postgres) AddPromoCode(ctx context.Context, promo model.PromoCode) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := p.Pool.Exec(ctx, `INSERT INTO promo_codes (`+promoColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		promo.Code, promo.Description, promo.Type, promo.Value, promo.ValidFrom, promo.ValidUntil,
+		promo.MaxRedemptions, promo.MaxPerUser, promo.Restrictions, promo.Redemptions, promo.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return model.ErrDuplicate
+	}
+	return err
+}
+
+func (p *Postgres) GetPromoCode(ctx context.Context, code string) (model.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanPromoCode(p.Pool.QueryRow(ctx, `SELECT `+promoColumns+` FROM promo_codes WHERE code = $1`, code))
+}
+
+func (p *Postgres) GetPromoCodes(ctx context.Context) ([]model.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := p.Pool.Query(ctx, `SELECT `+promoColumns+` FROM promo_codes ORDER BY created_at DESC, code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []model.PromoCode{}
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, promo)
+	}
+
+	return codes, rows.Err()
+}
+
+func (p *Postgres) DeletePromoCode(ctx context.Context, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tag, err := p.Pool.Exec(ctx, `DELETE FROM promo_codes WHERE code = $1`, code)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Postgres) CountRedemptions(ctx context.Context, code, userID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var count int
+	err := p.Pool.QueryRow(ctx, `SELECT count(*) FROM promo_redemptions WHERE code = $1 AND user_id = $2`,
+		code, userID).Scan(&count)
+	return count, err
+}
+
+// RedeemPromoCode checks the limits and records the redemption in one transaction. The code
+// row stays locked until the commit, so concurrent redemptions of the code wait for the count.
+func (p *Postgres) RedeemPromoCode(ctx context.Context, redemption model.PromoRedemption) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	err := pgx.BeginFunc(ctx, p.Pool, func(tx pgx.Tx) error {
+		var maxRedemptions, maxPerUser, redemptions int
+		err := tx.QueryRow(ctx, `SELECT max_redemptions, max_per_user, redemptions FROM promo_codes
+			WHERE code = $1 FOR UPDATE`, redemption.Code).Scan(&maxRedemptions, &maxPerUser, &redemptions)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if maxRedemptions > 0 && redemptions >= maxRedemptions {
+			return model.ErrLimitReached
+		}
+
+		if maxPerUser > 0 {
+			var count int
+			err := tx.QueryRow(ctx, `SELECT count(*) FROM promo_redemptions WHERE code = $1 AND user_id = $2`,
+				redemption.Code, redemption.UserID).Scan(&count)
+			if err != nil {
+				return err
+			}
+			if count >= maxPerUser {
+				return model.ErrLimitReached
+			}
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO promo_redemptions (reservation_id, code, user_id, discount, redeemed_at)
+			VALUES ($1, $2, $3, $4, $5)`,
+			redemption.ReservationID, redemption.Code, redemption.UserID, redemption.Discount, redemption.RedeemedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE promo_codes SET redemptions = redemptions + 1 WHERE code = $1`, redemption.Code)
+		return err
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return model.ErrDuplicate
+	}
+	return err
+}
+
+func (p *Postgres) ReleaseRedemption(ctx context.Context, reservationID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return pgx.BeginFunc(ctx, p.Pool, func(tx pgx.Tx) error {
+		var code string
+		err := tx.QueryRow(ctx, `DELETE FROM promo_redemptions WHERE reservation_id = $1 RETURNING code`,
+			reservationID).Scan(&code)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// The code may be deleted meanwhile, updating nothing is fine then
+		_, err = tx.Exec(ctx, `UPDATE promo_codes SET redemptions = redemptions - 1 WHERE code = $1`, code)
+		return err
+	})
+}
+
+func scanPromoCode(row pgx.Row) (model.PromoCode, error) {
+	var promo model.PromoCode
+
+	err := row.Scan(&promo.Code, &promo.Description, &promo.Type, &promo.Value, &promo.ValidFrom, &promo.ValidUntil,
+		&promo.MaxRedemptions, &promo.MaxPerUser, &promo.Restrictions, &promo.Redemptions, &promo.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return promo, model.ErrNotFound
+		}
+		return promo, err
+	}
+
+	promo.CreatedAt = promo.CreatedAt.UTC()
+	if promo.ValidFrom != nil {
+		from := promo.ValidFrom.UTC()
+		promo.ValidFrom = &from
+	}
+	if promo.ValidUntil != nil {
+		until := promo.ValidUntil.UTC()
+		promo.ValidUntil = &until
+	}
+	return promo, nil
+}
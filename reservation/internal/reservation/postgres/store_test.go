@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/storetest"
+)
+
+// connect returns an emptied store on the database named by POSTGRES_TEST_URL
+func connect(t *testing.T) *Postgres {
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+
+	db, err := Connect(url)
+	if err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	t.Cleanup(db.Disconnect)
+
+	return db
+}
+
+func truncate(t *testing.T, db *Postgres) {
+	if _, err := db.Pool.Exec(context.Background(), "TRUNCATE reservations, reservations_outbox, webhooks, webhook_deliveries, payments, payment_provider_events, invoices, invoice_counters, invoiced_reservations, promo_codes, promo_redemptions"); err != nil {
+		t.Fatalf("Failed to truncate reservations: %v", err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	db := connect(t)
+
+	storetest.Run(t, func(t *testing.T) store.ReservationStore {
+		truncate(t, db)
+		return db
+	})
+}
+
+func TestOverlapConstraint(t *testing.T) {
+	db := connect(t)
+	truncate(t, db)
+	ctx := context.Background()
+
+	start := time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+	reservation := func(id string, start, end time.Time) model.Reservation {
+		return model.Reservation{
+			ReservationID: id,
+			UserID:        "u1",
+			SpotID:        "s1",
+			StartTime:     start,
+			EndTime:       end,
+			Status:        model.StatusValid,
+			PricePaid:     10,
+			UpdatedAt:     start,
+		}
+	}
+
+	if err := db.AddReservation(ctx, reservation("r1", start, start.Add(2*time.Hour))); err != nil {
+		t.Fatalf("AddReservation: %v", err)
+	}
+
+	err := db.AddReservation(ctx, reservation("r2", start.Add(time.Hour), start.Add(3*time.Hour)))
+	if !errors.Is(err, model.ErrOverlap) {
+		t.Errorf("overlapping reservation: got %v want %v", err, model.ErrOverlap)
+	}
+
+	// The range is half-open, so back-to-back reservations fit
+	if err := db.AddReservation(ctx, reservation("r3", start.Add(2*time.Hour), start.Add(3*time.Hour))); err != nil {
+		t.Errorf("touching reservation: %v", err)
+	}
+
+	// Canceled reservations free the spot
+	canceled := reservation("r1", start, start.Add(2*time.Hour))
+	canceled.Status = model.StatusCanceled
+	if err := db.EditReservation(ctx, canceled); err != nil {
+		t.Fatalf("EditReservation: %v", err)
+	}
+	if err := db.AddReservation(ctx, reservation("r4", start, start.Add(2*time.Hour))); err != nil {
+		t.Errorf("reservation over a canceled one: %v", err)
+	}
+
+	// Moving a reservation onto another one is rejected as well
+	moved := reservation("r3", start.Add(time.Hour), start.Add(3*time.Hour))
+	if err := db.EditReservation(ctx, moved); !errors.Is(err, model.ErrOverlap) {
+		t.Errorf("edit into an overlap: got %v want %v", err, model.ErrOverlap)
+	}
+}
@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	webhookColumns  = `id, url, event_types, secret, created_at`
+	deliveryColumns = `id, webhook_id, event, status, attempts, failures, next_attempt_at, created_at, updated_at`
+)
+
+func (p *Postgres) AddWebhook(ctx context.Context, webhook model.Webhook) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := p.Pool.Exec(ctx, `INSERT INTO webhooks (`+webhookColumns+`) VALUES ($1, $2, $3, $4, $5)`,
+		webhook.WebhookID, webhook.URL, webhook.EventTypes, webhook.Secret, webhook.CreatedAt)
+	return err
+}
+
+func (p *Postgres) GetWebhook(ctx context.Context, webhookID string) (model.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	row := p.Pool.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, webhookID)
+	return scanWebhook(row)
+}
+
+func (p *Postgres) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := p.Pool.Query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []model.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (p *Postgres) DeleteWebhook(ctx context.Context, webhookID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tag, err := p.Pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, webhookID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) AddDeliveries(ctx context.Context, deliveries []model.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(`INSERT INTO webhook_deliveries (`+deliveryColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id) DO NOTHING`,
+			d.DeliveryID, d.WebhookID, d.Event, d.Status, attempts(d.Attempts), d.Failures,
+			d.NextAttemptAt, d.CreatedAt, d.UpdatedAt)
+	}
+
+	return p.Pool.SendBatch(ctx, batch).Close()
+}
+
+func (p *Postgres) GetDelivery(ctx context.Context, deliveryID string) (model.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	row := p.Pool.QueryRow(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, deliveryID)
+	return scanDelivery(row)
+}
+
+func (p *Postgres) GetDeliveries(ctx context.Context, filter model.DeliveryFilter, limit int) ([]model.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var conditions []string
+	var args []any
+	if filter.WebhookID != "" {
+		args = append(args, filter.WebhookID)
+		conditions = append(conditions, fmt.Sprintf("webhook_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+
+	rows, err := p.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []model.Delivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (p *Postgres) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// SKIP LOCKED lets concurrent instances claim different deliveries instead of waiting
+	rows, err := p.Pool.Query(ctx, `WITH due AS (
+			SELECT id, next_attempt_at FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at LIMIT $3 FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET next_attempt_at = $2 FROM due WHERE d.id = due.id
+		RETURNING d.id, d.webhook_id, d.event, d.status, d.attempts, d.failures, d.next_attempt_at, d.created_at, d.updated_at,
+			due.next_attempt_at`, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []model.Delivery
+	dueAt := make(map[string]time.Time)
+	for rows.Next() {
+		var due time.Time
+		delivery, err := scanDelivery(rows, &due)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, delivery)
+		dueAt[delivery.DeliveryID] = due
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the order of the claiming query
+	sort.SliceStable(claimed, func(i, j int) bool {
+		return dueAt[claimed[i].DeliveryID].Before(dueAt[claimed[j].DeliveryID])
+	})
+	return claimed, nil
+}
+
+func (p *Postgres) UpdateDelivery(ctx context.Context, delivery model.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tag, err := p.Pool.Exec(ctx, `UPDATE webhook_deliveries SET webhook_id = $2, event = $3, status = $4, attempts = $5,
+		failures = $6, next_attempt_at = $7, created_at = $8, updated_at = $9 WHERE id = $1`,
+		delivery.DeliveryID, delivery.WebhookID, delivery.Event, delivery.Status, attempts(delivery.Attempts),
+		delivery.Failures, delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanWebhook(row pgx.Row) (model.Webhook, error) {
+	var w model.Webhook
+	if err := row.Scan(&w.WebhookID, &w.URL, &w.EventTypes, &w.Secret, &w.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return w, model.ErrNotFound
+		}
+		return w, err
+	}
+
+	w.CreatedAt = w.CreatedAt.UTC()
+	return w, nil
+}
+
+func scanDelivery(row pgx.Row, extra ...any) (model.Delivery, error) {
+	var d model.Delivery
+
+	dest := append([]any{&d.DeliveryID, &d.WebhookID, &d.Event, &d.Status, &d.Attempts, &d.Failures,
+		&d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return d, model.ErrNotFound
+		}
+		return d, err
+	}
+
+	d.Event.OccurredAt = d.Event.OccurredAt.UTC()
+	d.NextAttemptAt, d.CreatedAt, d.UpdatedAt = d.NextAttemptAt.UTC(), d.CreatedAt.UTC(), d.UpdatedAt.UTC()
+	for i := range d.Attempts {
+		d.Attempts[i].At = d.Attempts[i].At.UTC()
+	}
+	return d, nil
+}
+
+// attempts stores a missing log as an empty JSON array rather than null
+func attempts(log []model.Attempt) []model.Attempt {
+	if log == nil {
+		return []model.Attempt{}
+	}
+	return log
+}
@@ -0,0 +1,182 @@
+// Package problem implements RFC 7807 problem details responses
+// together with the catalog of stable error codes shared by all services.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// Code is a stable, machine readable error identifier.
+// Clients should branch on Code rather than on Detail.
+type Code string
+
+const (
+	CodeBadRequest                 Code = "BAD_REQUEST"
+	CodeMalformedBody              Code = "MALFORMED_BODY"
+	CodeValidationFailed           Code = "VALIDATION_FAILED"
+	CodeInvalidCursor              Code = "INVALID_CURSOR"
+	CodeVehicleIncompatible        Code = "VEHICLE_INCOMPATIBLE"
+	CodeUnknownSpots               Code = "UNKNOWN_SPOTS"
+	CodeInvalidSignature           Code = "INVALID_SIGNATURE"
+	CodeInvalidQuote               Code = "INVALID_QUOTE"
+	CodeUnauthorized               Code = "UNAUTHORIZED"
+	CodeInvalidCredentials         Code = "INVALID_CREDENTIALS"
+	CodeInvalidToken               Code = "INVALID_TOKEN"
+	CodePaymentFailed              Code = "PAYMENT_FAILED"
+	CodeNotFound                   Code = "NOT_FOUND"
+	CodeUserNotFound               Code = "USER_NOT_FOUND"
+	CodeSpotNotFound               Code = "SPOT_NOT_FOUND"
+	CodeReservationNotFound        Code = "RESERVATION_NOT_FOUND"
+	CodeWebhookNotFound            Code = "WEBHOOK_NOT_FOUND"
+	CodeDeliveryNotFound           Code = "DELIVERY_NOT_FOUND"
+	CodePaymentNotFound            Code = "PAYMENT_NOT_FOUND"
+	CodePolicyNotFound             Code = "CANCELLATION_POLICY_NOT_FOUND"
+	CodeInvoiceNotFound            Code = "INVOICE_NOT_FOUND"
+	CodePromoCodeNotFound          Code = "PROMO_CODE_NOT_FOUND"
+	CodeVehicleNotFound            Code = "VEHICLE_NOT_FOUND"
+	CodeNoMatchingSpot             Code = "NO_MATCHING_SPOT"
+	CodeMethodNotAllowed           Code = "METHOD_NOT_ALLOWED"
+	CodeSpotUnavailable            Code = "SPOT_UNAVAILABLE"
+	CodeUserAlreadyExists          Code = "USER_ALREADY_EXISTS"
+	CodeVehicleAlreadyExists       Code = "VEHICLE_ALREADY_EXISTS"
+	CodeReservationAlreadyCanceled Code = "RESERVATION_ALREADY_CANCELED"
+	CodeNothingToInvoice           Code = "NOTHING_TO_INVOICE"
+	CodePromoCodeAlreadyExists     Code = "PROMO_CODE_ALREADY_EXISTS"
+	CodePromoCodeNotApplicable     Code = "PROMO_CODE_NOT_APPLICABLE"
+	CodeQuoteExpired               Code = "QUOTE_EXPIRED"
+	CodeInternal                   Code = "INTERNAL_ERROR"
+	CodeDownstreamError            Code = "DOWNSTREAM_ERROR"
+	CodeDownstreamUnavailable      Code = "DOWNSTREAM_UNAVAILABLE"
+	CodeDownstreamTimeout          Code = "DOWNSTREAM_TIMEOUT"
+)
+
+type entry struct {
+	status int
+	title  string
+}
+
+var catalog = map[Code]entry{
+	CodeBadRequest:                 {http.StatusBadRequest, "Bad request"},
+	CodeMalformedBody:              {http.StatusBadRequest, "Malformed request body"},
+	CodeValidationFailed:           {http.StatusBadRequest, "Validation failed"},
+	CodeInvalidCursor:              {http.StatusBadRequest, "Invalid cursor"},
+	CodeVehicleIncompatible:        {http.StatusBadRequest, "Vehicle is not compatible with the spot"},
+	CodeUnknownSpots:               {http.StatusBadRequest, "Some spots do not exist"},
+	CodeInvalidSignature:           {http.StatusBadRequest, "Invalid signature"},
+	CodeInvalidQuote:               {http.StatusBadRequest, "Invalid quote token"},
+	CodeUnauthorized:               {http.StatusUnauthorized, "Unauthorized"},
+	CodeInvalidCredentials:         {http.StatusUnauthorized, "Invalid credentials"},
+	CodeInvalidToken:               {http.StatusUnauthorized, "Invalid token"},
+	CodePaymentFailed:              {http.StatusPaymentRequired, "Payment failed"},
+	CodeNotFound:                   {http.StatusNotFound, "Not found"},
+	CodeUserNotFound:               {http.StatusNotFound, "User not found"},
+	CodeSpotNotFound:               {http.StatusNotFound, "Spot not found"},
+	CodeReservationNotFound:        {http.StatusNotFound, "Reservation not found"},
+	CodeWebhookNotFound:            {http.StatusNotFound, "Webhook not found"},
+	CodeDeliveryNotFound:           {http.StatusNotFound, "Webhook delivery not found"},
+	CodePaymentNotFound:            {http.StatusNotFound, "Payment not found"},
+	CodePolicyNotFound:             {http.StatusNotFound, "Cancellation policy not found"},
+	CodeInvoiceNotFound:            {http.StatusNotFound, "Invoice not found"},
+	CodePromoCodeNotFound:          {http.StatusNotFound, "Promo code not found"},
+	CodeVehicleNotFound:            {http.StatusNotFound, "Vehicle not found"},
+	CodeNoMatchingSpot:             {http.StatusNotFound, "No matching spot"},
+	CodeMethodNotAllowed:           {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeSpotUnavailable:            {http.StatusConflict, "Spot unavailable"},
+	CodeUserAlreadyExists:          {http.StatusConflict, "User already exists"},
+	CodeVehicleAlreadyExists:       {http.StatusConflict, "Vehicle already exists"},
+	CodeReservationAlreadyCanceled: {http.StatusConflict, "Reservation already canceled"},
+	CodeNothingToInvoice:           {http.StatusConflict, "Nothing to invoice"},
+	CodePromoCodeAlreadyExists:     {http.StatusConflict, "Promo code already exists"},
+	CodePromoCodeNotApplicable:     {http.StatusConflict, "Promo code not applicable"},
+	CodeQuoteExpired:               {http.StatusConflict, "Quote expired"},
+	CodeInternal:                   {http.StatusInternalServerError, "Internal server error"},
+	CodeDownstreamError:            {http.StatusBadGateway, "Downstream service error"},
+	CodeDownstreamUnavailable:      {http.StatusServiceUnavailable, "Downstream service unavailable"},
+	CodeDownstreamTimeout:          {http.StatusGatewayTimeout, "Downstream service timeout"},
+}
+
+// Status returns the HTTP status associated with the code.
+func (c Code) Status() int {
+	if e, ok := catalog[c]; ok {
+		return e.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title returns the short, human readable summary of the code.
+func (c Code) Title() string {
+	if e, ok := catalog[c]; ok {
+		return e.title
+	}
+	return http.StatusText(c.Status())
+}
+
+// Type returns the problem type URI of the code.
+func (c Code) Type() string {
+	return "urn:reserve-park:problem:" + strings.ReplaceAll(strings.ToLower(string(c)), "_", "-")
+}
+
+// CodeForStatus picks the generic code used for a bare HTTP status.
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return CodeUnauthorized
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusServiceUnavailable:
+		return CodeDownstreamUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeDownstreamTimeout
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Problem is an RFC 7807 problem details document extended with
+// a stable error code, the request ID and per-field validation errors.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// New builds a problem for the given code.
+func New(code Code, detail string) Problem {
+	return Problem{
+		Type:   code.Type(),
+		Title:  code.Title(),
+		Status: code.Status(),
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Write encodes the problem as the response body.
+func Write(w http.ResponseWriter, p Problem) {
+	j, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, p.Detail, p.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	w.Write(j)
+}
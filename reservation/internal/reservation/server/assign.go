@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/google/uuid"
+)
+
+type StrategyType string
+
+const (
+	StrategyCheapest           StrategyType = "cheapest"
+	StrategyClosest            StrategyType = "closest"
+	StrategyLeastFragmentation StrategyType = "least_fragmentation"
+)
+
+// How far around the booking to look for neighbouring reservations
+const fragmentationLookaround = 24 * time.Hour
+
+type assignCandidate struct {
+	SpotID    string  `json:"spot_id" validate:"required"`
+	PricePaid float64 `json:"price_paid" validate:"required,gt=0"`
+}
+
+type assignInput struct {
+	UserID       string            `json:"user_id" validate:"required"`
+	StartTime    time.Time         `json:"start_time" validate:"required"`
+	EndTime      time.Time         `json:"end_time" validate:"required,gtfield=StartTime"`
+	Candidates   []assignCandidate `json:"candidates" validate:"required,min=1,dive"`
+	Strategy     StrategyType      `json:"strategy" validate:"omitempty,oneof=cheapest closest least_fragmentation"`
+	VehicleID    string            `json:"vehicle_id"`
+	LicensePlate string            `json:"license_plate" validate:"omitempty,max=16"`
+
+	// PaymentMethod is charged for the reservation, it is required while payments are enabled
+	PaymentMethod string `json:"payment_method"`
+}
+
+func (s *Server) assignReservation(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Assigning reservation")
+	var input assignInput
+
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	reservation, err := s.assignSpot(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, reservation, http.StatusCreated)
+}
+
+// assignSpot books the first available spot out of the ordered candidates.
+// With the least_fragmentation strategy the candidates are reordered so that the
+// booking leaves the smallest gaps next to existing reservations.
+func (s *Server) assignSpot(ctx context.Context, input assignInput) (m.Reservation, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return m.Reservation{}, problem.Invalid(err)
+	}
+
+	if s.Payments != nil && input.PaymentMethod == "" {
+		return m.Reservation{}, problem.NewError(problem.CodeBadRequest, "payment_method is required", nil)
+	}
+
+	data, payment, err := s.bookCandidate(ctx, input)
+	if err != nil {
+		return data, err
+	}
+
+	metrics.ReservationsCreated.Inc()
+	s.Logger.InfoContext(ctx, "Reservation assigned", "reservation_id", data.ReservationID, "spot_id", data.SpotID)
+
+	if payment != nil {
+		return s.authorizePayment(ctx, data, *payment, input.PaymentMethod)
+	}
+	return data, nil
+}
+
+// Helper function to pick a spot and store the reservation while holding the booking lock
+func (s *Server) bookCandidate(ctx context.Context, input assignInput) (m.Reservation, *m.Payment, error) {
+	spotIDs := make([]string, 0, len(input.Candidates))
+	for _, candidate := range input.Candidates {
+		spotIDs = append(spotIDs, candidate.SpotID)
+	}
+
+	s.bookingMu.Lock()
+	defer s.bookingMu.Unlock()
+
+	availableSpots, err := s.Store.CheckAvailability(ctx, m.AvailabilityInput{
+		SpotIDs:   spotIDs,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+	})
+	if err != nil {
+		return m.Reservation{}, nil, problem.NewError(problem.CodeInternal, "Failed to check availability", err)
+	}
+
+	if len(availableSpots) == 0 {
+		return m.Reservation{}, nil, problem.NewError(problem.CodeSpotUnavailable, "No matching spot available in provided timeframe", nil)
+	}
+
+	available := make(map[string]struct{}, len(availableSpots))
+	for _, spotID := range availableSpots {
+		available[spotID] = struct{}{}
+	}
+
+	var candidates []assignCandidate
+	for _, candidate := range input.Candidates {
+		if _, ok := available[candidate.SpotID]; ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if input.Strategy == StrategyLeastFragmentation {
+		candidates, err = s.orderByFragmentation(ctx, candidates, input.StartTime, input.EndTime)
+		if err != nil {
+			return m.Reservation{}, nil, problem.NewError(problem.CodeInternal, "Failed to get reservations", err)
+		}
+	}
+
+	chosen := candidates[0]
+	data := m.Reservation{
+		ReservationID: uuid.NewString(),
+		UserID:        input.UserID,
+		SpotID:        chosen.SpotID,
+		StartTime:     input.StartTime,
+		EndTime:       input.EndTime,
+		Status:        m.StatusValid,
+		PricePaid:     chosen.PricePaid,
+		VehicleID:     input.VehicleID,
+		LicensePlate:  m.NormalizePlate(input.LicensePlate),
+		UpdatedAt:     time.Now(),
+	}
+	if s.Payments != nil {
+		data.Status = m.StatusPending
+	}
+
+	if err := s.Validator.Struct(data); err != nil {
+		return data, nil, problem.Invalid(err)
+	}
+
+	payment, err := s.storeReservation(ctx, data)
+	return data, payment, err
+}
+
+// Helper function to sort candidates by the idle time the booking would leave around it.
+// The sort is stable, so the caller's order (e.g. by price) breaks ties.
+func (s *Server) orderByFragmentation(ctx context.Context, candidates []assignCandidate, start, end time.Time) ([]assignCandidate, error) {
+	spotIDs := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		spotIDs = append(spotIDs, candidate.SpotID)
+	}
+
+	reservations, err := s.Store.GetReservationsInWindow(ctx, spotIDs, start.Add(-fragmentationLookaround), end.Add(fragmentationLookaround))
+	if err != nil {
+		return nil, err
+	}
+
+	bySpot := make(map[string][]m.Reservation)
+	for _, res := range reservations {
+		bySpot[res.SpotID] = append(bySpot[res.SpotID], res)
+	}
+
+	scores := make(map[string]time.Duration, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate.SpotID] = fragmentationScore(bySpot[candidate.SpotID], start, end)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i].SpotID] < scores[candidates[j].SpotID]
+	})
+
+	return candidates, nil
+}
+
+// fragmentationScore sums the gaps between the booking and its nearest neighbours,
+// capped by the lookaround so that an empty spot scores worst.
+func fragmentationScore(reservations []m.Reservation, start, end time.Time) time.Duration {
+	gapBefore := fragmentationLookaround
+	gapAfter := fragmentationLookaround
+
+	for _, res := range reservations {
+		if !res.EndTime.After(start) {
+			if gap := start.Sub(res.EndTime); gap < gapBefore {
+				gapBefore = gap
+			}
+		}
+		if !res.StartTime.Before(end) {
+			if gap := res.StartTime.Sub(end); gap < gapAfter {
+				gapAfter = gap
+			}
+		}
+	}
+
+	return gapBefore + gapAfter
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/availability"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Most spots a single watch may cover
+const maxWatchedSpots = 1000
+
+func (s *Server) watchAvailability(ctx context.Context, spotIDs []string) (*availability.Watch, error) {
+	if len(spotIDs) == 0 {
+		return nil, problem.NewError(problem.CodeBadRequest, "spot_ids are required", nil)
+	}
+	if len(spotIDs) > maxWatchedSpots {
+		return nil, problem.NewError(problem.CodeBadRequest, "At most 1000 spots can be watched at once", nil)
+	}
+	if s.Availability == nil {
+		return nil, problem.NewError(problem.CodeInternal, "Availability changes are not available", nil)
+	}
+
+	watch, err := s.Availability.Watch(spotIDs)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "The server is shutting down")
+	}
+
+	s.Logger.InfoContext(ctx, "Availability watch opened", "spots", len(spotIDs))
+	return watch, nil
+}
+
+func (rpc *reservationRPC) WatchAvailability(req *reservationpb.WatchAvailabilityRequest, stream grpc.ServerStreamingServer[reservationpb.AvailabilityChange]) error {
+	ctx := stream.Context()
+
+	watch, err := rpc.s.watchAvailability(ctx, req.GetSpotIds())
+	if err != nil {
+		return err
+	}
+	defer watch.Close()
+
+	// The caller waits for the headers to know the watch is open
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case change, ok := <-watch.Changes():
+			if !ok {
+				return watchEndStatus(watch.Err())
+			}
+			if err := stream.Send(availabilityChangeToProto(change)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Helper function to tell the caller why the hub ended the watch
+func watchEndStatus(err error) error {
+	if err == availability.ErrLagging {
+		return status.Error(codes.ResourceExhausted, "The watch fell behind the changes, watch again")
+	}
+	return status.Error(codes.Unavailable, "The server is shutting down, watch again")
+}
+
+func availabilityChangeToProto(change m.AvailabilityChange) *reservationpb.AvailabilityChange {
+	return &reservationpb.AvailabilityChange{
+		SpotId:        change.SpotID,
+		ReservationId: change.ReservationID,
+		Change:        string(change.Change),
+		StartTime:     timestamppb.New(change.StartTime),
+		EndTime:       timestamppb.New(change.EndTime),
+		EventId:       change.EventID,
+		EventType:     change.EventType,
+		OccurredAt:    timestamppb.New(change.OccurredAt),
+	}
+}
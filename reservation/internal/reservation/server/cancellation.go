@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The spot service owns the cancellation policies, the caller resolves the one of the
+// spot and passes it on, so that the reservation service doesn't depend on the spot one.
+
+func (s *Server) quoteCancellation(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Quoting reservation cancellation")
+	var policy m.CancellationPolicy
+
+	err := json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	quote, err := s.priceCancellation(r.Context(), mux.Vars(r)["id"], policy)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, quote, http.StatusOK)
+}
+
+func (s *Server) cancelReservation(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Canceling reservation")
+	var policy m.CancellationPolicy
+
+	err := json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	reservation, err := s.cancelWithPolicy(r.Context(), mux.Vars(r)["id"], policy)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, reservation, http.StatusOK)
+}
+
+func (s *Server) priceCancellation(ctx context.Context, reservationID string, policy m.CancellationPolicy) (m.CancellationQuote, error) {
+	if err := s.Validator.Struct(policy); err != nil {
+		return m.CancellationQuote{}, problem.Invalid(err)
+	}
+
+	reservation, err := s.findReservation(ctx, reservationID)
+	if err != nil {
+		return m.CancellationQuote{}, err
+	}
+
+	return quoteFor(reservation, policy, time.Now().UTC())
+}
+
+// cancelWithPolicy cancels the reservation at the price the policy sets for now and records
+// it on the reservation. The payment settler keeps the fee and refunds the rest.
+func (s *Server) cancelWithPolicy(ctx context.Context, reservationID string, policy m.CancellationPolicy) (m.Reservation, error) {
+	if err := s.Validator.Struct(policy); err != nil {
+		return m.Reservation{}, problem.Invalid(err)
+	}
+
+	updatedReservation, err := s.storeCancellation(ctx, reservationID, policy)
+	if err != nil {
+		return updatedReservation, err
+	}
+
+	metrics.ReservationsCanceled.Inc()
+	s.followUpPayment(ctx, updatedReservation, false)
+	s.correctInvoice(ctx, reservationID)
+
+	s.Logger.InfoContext(ctx, "Reservation canceled", "reservation_id", reservationID,
+		"tier", updatedReservation.Cancellation.Tier, "fee", updatedReservation.Cancellation.Fee)
+	return updatedReservation, nil
+}
+
+// storeCancellation holds the booking lock so that the reservation can't change between the quote and the cancel
+func (s *Server) storeCancellation(ctx context.Context, reservationID string, policy m.CancellationPolicy) (m.Reservation, error) {
+	s.bookingMu.Lock()
+	defer s.bookingMu.Unlock()
+
+	reservation, err := s.findReservation(ctx, reservationID)
+	if err != nil {
+		return reservation, err
+	}
+
+	now := time.Now().UTC()
+	quote, err := quoteFor(reservation, policy, now)
+	if err != nil {
+		return reservation, err
+	}
+
+	updatedReservation := reservation
+	updatedReservation.Status = m.StatusCanceled
+	updatedReservation.UpdatedAt = now
+	updatedReservation.Cancellation = &m.Cancellation{
+		CanceledAt: now,
+		Tier:       quote.Tier,
+		Fee:        quote.Fee,
+		Refund:     quote.Refund,
+		Policy:     policy,
+	}
+
+	event, err := m.NewEvent(m.EventReservationCanceled, reservationID, m.NewReservationChanged(reservation, updatedReservation))
+	if err != nil {
+		return reservation, problem.NewError(problem.CodeInternal, "Failed to build the reservation event", err)
+	}
+
+	if err := s.Store.EditReservation(ctx, updatedReservation, event); err != nil {
+		if err == m.ErrNotFound {
+			return reservation, problem.NewError(problem.CodeReservationNotFound, "Reservation not found", err)
+		}
+
+		return reservation, problem.NewError(problem.CodeInternal, "Failed to cancel reservation", err)
+	}
+
+	return updatedReservation, nil
+}
+
+func quoteFor(reservation m.Reservation, policy m.CancellationPolicy, now time.Time) (m.CancellationQuote, error) {
+	if reservation.Status == m.StatusCanceled {
+		return m.CancellationQuote{}, problem.NewError(problem.CodeReservationAlreadyCanceled, "Reservation is already canceled", nil)
+	}
+
+	quote := policy.Quote(reservation.PricePaid, reservation.StartTime, now)
+	quote.ReservationID = reservation.ReservationID
+
+	// The payment of a pending reservation isn't authorized yet, there's nothing to keep
+	if reservation.Status == m.StatusPending {
+		quote.Tier, quote.Fee, quote.Refund, quote.ValidUntil = m.CancellationFree, 0, reservation.PricePaid, nil
+	}
+	return quote, nil
+}
+
+func (rpc *reservationRPC) GetCancellationQuote(ctx context.Context, req *reservationpb.CancellationRequest) (*reservationpb.CancellationQuote, error) {
+	quote, err := rpc.s.priceCancellation(ctx, req.GetReservationId(), policyFromProto(req.GetPolicy()))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &reservationpb.CancellationQuote{
+		ReservationId: quote.ReservationID,
+		Price:         quote.Price,
+		Fee:           quote.Fee,
+		Refund:        quote.Refund,
+		Tier:          string(quote.Tier),
+		Policy:        policyToProto(quote.Policy),
+		QuotedAt:      timestamppb.New(quote.QuotedAt),
+	}
+	if quote.ValidUntil != nil {
+		resp.ValidUntil = timestamppb.New(*quote.ValidUntil)
+	}
+
+	return resp, nil
+}
+
+func (rpc *reservationRPC) CancelReservation(ctx context.Context, req *reservationpb.CancellationRequest) (*reservationpb.Reservation, error) {
+	reservation, err := rpc.s.cancelWithPolicy(ctx, req.GetReservationId(), policyFromProto(req.GetPolicy()))
+	if err != nil {
+		return nil, err
+	}
+
+	return reservationToProto(reservation), nil
+}
+
+func policyFromProto(policy *reservationpb.CancellationPolicy) m.CancellationPolicy {
+	return m.CancellationPolicy{
+		Scope:                policy.GetScope(),
+		TargetID:             policy.GetTargetId(),
+		FreeUntilHours:       int(policy.GetFreeUntilHours()),
+		LateFeePercent:       policy.GetLateFeePercent(),
+		NonRefundableMinutes: int(policy.GetNonRefundableMinutes()),
+	}
+}
+
+func policyToProto(policy m.CancellationPolicy) *reservationpb.CancellationPolicy {
+	return &reservationpb.CancellationPolicy{
+		Scope:                policy.Scope,
+		TargetId:             policy.TargetID,
+		FreeUntilHours:       int32(policy.FreeUntilHours),
+		LateFeePercent:       policy.LateFeePercent,
+		NonRefundableMinutes: int32(policy.NonRefundableMinutes),
+	}
+}
+
+func cancellationToProto(cancellation *m.Cancellation) *reservationpb.Cancellation {
+	if cancellation == nil {
+		return nil
+	}
+
+	return &reservationpb.Cancellation{
+		CanceledAt: timestamppb.New(cancellation.CanceledAt),
+		Tier:       string(cancellation.Tier),
+		Fee:        cancellation.Fee,
+		Refund:     cancellation.Refund,
+		Policy:     policyToProto(cancellation.Policy),
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/config"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/memory"
+)
+
+var lotPolicy = model.CancellationPolicy{Scope: "lot", TargetID: "lot-a", FreeUntilHours: 24, LateFeePercent: 50, NonRefundableMinutes: 30}
+
+func TestCancellationQuote(t *testing.T) {
+	start := time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		policy     model.CancellationPolicy
+		before     time.Duration
+		tier       model.CancellationTier
+		fee        float64
+		refund     float64
+		validUntil time.Time
+	}{
+		{"free", lotPolicy, 25 * time.Hour, model.CancellationFree, 0, 12.33, start.Add(-24 * time.Hour)},
+		{"free at the limit", lotPolicy, 24 * time.Hour, model.CancellationFree, 0, 12.33, start.Add(-24 * time.Hour)},
+		{"late fee", lotPolicy, time.Hour, model.CancellationLateFee, 6.17, 6.16, start.Add(-30 * time.Minute)},
+		{"non-refundable", lotPolicy, 10 * time.Minute, model.CancellationNonRefundable, 12.33, 0, time.Time{}},
+		{"started", lotPolicy, -time.Minute, model.CancellationNonRefundable, 12.33, 0, time.Time{}},
+		{"default before the start", model.CancellationPolicy{Scope: "default"}, time.Minute, model.CancellationFree, 0, 12.33, start},
+		{"default at the start", model.CancellationPolicy{Scope: "default"}, 0, model.CancellationNonRefundable, 12.33, 0, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		q := tt.policy.Quote(12.33, start, start.Add(-tt.before))
+		if q.Tier != tt.tier || q.Fee != tt.fee || q.Refund != tt.refund {
+			t.Errorf("%v: got %s with fee %v and refund %v, want %s with fee %v and refund %v", tt.name, q.Tier, q.Fee, q.Refund, tt.tier, tt.fee, tt.refund)
+		}
+		if (q.ValidUntil == nil) != tt.validUntil.IsZero() || q.ValidUntil != nil && !q.ValidUntil.Equal(tt.validUntil) {
+			t.Errorf("%v: valid until %v, want %v", tt.name, q.ValidUntil, tt.validUntil)
+		}
+	}
+}
+
+func TestCancelWithPolicy(t *testing.T) {
+	cs := NewServer(logger.GetLogger(), config.GetConfig(), memory.New())
+
+	reservation, err := book(t, cs, "s1", "", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+
+	post := func(path string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(lotPolicy)
+		rr := httptest.NewRecorder()
+		cs.Handler().ServeHTTP(rr, httptest.NewRequest("POST", "/reservations/"+reservation.ReservationID+path, bytes.NewReader(body)))
+		return rr
+	}
+
+	rr := post("/cancellation/quote")
+	var quote model.CancellationQuote
+	if err := json.NewDecoder(rr.Body).Decode(&quote); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if rr.Code != http.StatusOK || quote.Tier != model.CancellationLateFee || quote.Fee != 6.25 || quote.Refund != 6.25 || quote.ValidUntil == nil {
+		t.Errorf("got %d with quote %+v, want a late fee of 6.25", rr.Code, quote)
+	}
+
+	rr = post("/cancellation")
+	if err := json.NewDecoder(rr.Body).Decode(&reservation); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	c := reservation.Cancellation
+	if rr.Code != http.StatusOK || reservation.Status != model.StatusCanceled || c == nil || c.Fee != 6.25 || c.Refund != 6.25 || c.Policy != lotPolicy {
+		t.Fatalf("got %d with reservation %+v, want it canceled with the fee recorded", rr.Code, reservation)
+	}
+
+	for _, path := range []string{"/cancellation/quote", "/cancellation"} {
+		if rr := post(path); rr.Code != http.StatusConflict {
+			t.Errorf("%v on a canceled reservation: got %d, want %d", path, rr.Code, http.StatusConflict)
+		}
+	}
+}
+
+func TestCancellationFeeSettlement(t *testing.T) {
+	ps := newPaymentServer()
+	ctx := context.Background()
+
+	started, err := book(t, ps, "s1", "pm_card_visa", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+	upcoming, err := book(t, ps, "s2", "pm_card_visa", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+
+	// The started one is captured in full first
+	if n, err := ps.SettlePayments(ctx); err != nil || n != 1 {
+		t.Fatalf("SettlePayments = %d, %v, want 1 settled", n, err)
+	}
+
+	for _, r := range []model.Reservation{started, upcoming} {
+		if _, err := ps.cancelWithPolicy(ctx, r.ReservationID, lotPolicy); err != nil {
+			t.Fatalf("cancelWithPolicy: %v", err)
+		}
+	}
+	if n, err := ps.SettlePayments(ctx); err != nil || n != 2 {
+		t.Fatalf("SettlePayments = %d, %v, want 2 settled", n, err)
+	}
+
+	if _, p := reservationPayment(t, ps, started.ReservationID); p.Status != model.PaymentCaptured || p.Captured != 1250 || p.Refunded != 0 {
+		t.Errorf("payment canceled after the start = %+v, want kept in full", p)
+	}
+	if _, p := reservationPayment(t, ps, upcoming.ReservationID); p.Status != model.PaymentCaptured || p.Captured != 625 {
+		t.Errorf("payment canceled late = %+v, want the fee of 625 captured", p)
+	}
+
+	if n, err := ps.SettlePayments(ctx); err != nil || n != 1 {
+		t.Fatalf("SettlePayments = %d, %v, want the late fee payment looked at again", n, err)
+	}
+	if n, err := ps.SettlePayments(ctx); err != nil || n != 0 {
+		t.Errorf("SettlePayments = %d, %v, want nothing left", n, err)
+	}
+}
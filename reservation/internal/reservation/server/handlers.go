@@ -2,76 +2,58 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
-	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/mongodb"
-	"github.com/google/uuid"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
 	"github.com/gorilla/mux"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type addInput struct {
-	UserID    string       `json:"user_id"`
-	SpotID    string       `json:"spot_id"`
-	StartTime time.Time    `json:"start_time"`
-	EndTime   time.Time    `json:"end_time"`
-	Status    m.StatusType `json:"status"`
-	PricePaid float64      `json:"price_paid"`
+	UserID       string       `json:"user_id"`
+	SpotID       string       `json:"spot_id"`
+	StartTime    time.Time    `json:"start_time"`
+	EndTime      time.Time    `json:"end_time"`
+	Status       m.StatusType `json:"status"`
+	PricePaid    float64      `json:"price_paid"`
+	VehicleID    string       `json:"vehicle_id"`
+	LicensePlate string       `json:"license_plate"`
+
+	// PaymentMethod is charged for the reservation, it is required while payments are enabled
+	PaymentMethod string `json:"payment_method"`
+
+	// PromoCode discounts PricePaid, its restrictions apply to the LotID and SpotType of the spot
+	PromoCode string `json:"promo_code"`
+	LotID     string `json:"lot_id"`
+	SpotType  string `json:"spot_type"`
+
+	// Discount is the discount of PromoCode already quoted, the code is only redeemed
+	Discount *float64 `json:"discount"`
 }
 
 func (s *Server) addReservation(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Adding reservation")
+	s.Logger.InfoContext(r.Context(), "Adding reservation")
 	var input addInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	data := m.Reservation{
-		ReservationID: uuid.NewString(),
-		UserID:        input.UserID,
-		SpotID:        input.SpotID,
-		StartTime:     input.StartTime,
-		EndTime:       input.EndTime,
-		Status:        input.Status,
-		PricePaid:     input.PricePaid,
-		UpdatedAt:     time.Now(),
-	}
-
-	if err := s.Validator.Struct(data); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	availableInput := m.AvailabilityInput{
-		SpotIDs:   []string{input.SpotID},
-		StartTime: input.StartTime,
-		EndTime:   input.EndTime,
-	}
-
-	availableSpots, err := s.MongoDB.CheckAvailability(availableInput)
+	reservation, err := s.createReservation(r.Context(), input)
 	if err != nil {
-		s.handleError(w, "Failed to check availability", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
 		return
 	}
 
-	if len(availableSpots) == 0 {
-		s.handleError(w, "Spot not available in provided timeframe", nil, http.StatusConflict)
-		return
-	}
-
-	err = s.MongoDB.AddReservation(data)
-	if err != nil {
-		s.handleError(w, "Failed to add reservation to MongoDB", err, http.StatusInternalServerError)
-		return
-	}
-
-	s.Logger.Info.Printf("Reservation added: %v", data.ReservationID)
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(data.ReservationID))
+	w.Write([]byte(reservation.ReservationID))
 }
 
 type editInput struct {
@@ -82,244 +64,207 @@ type editInput struct {
 	EndTime       *time.Time   `json:"end_time"`
 	Status        m.StatusType `json:"status" validate:"omitempty,oneof=valid canceled"`
 	PricePaid     *float64     `json:"price_paid" validate:"omitempty,gt=0"`
+	VehicleID     string       `json:"vehicle_id"`
+	LicensePlate  string       `json:"license_plate" validate:"omitempty,max=16"`
 }
 
 func (s *Server) editReservation(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Editing reservation")
+	s.Logger.InfoContext(r.Context(), "Editing reservation")
 	var input editInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
-		return
-	}
-
-	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	reservation, err := s.MongoDB.GetReservation(input.ReservationID)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Reservation not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to get reservation from MongoDB", err, http.StatusInternalServerError)
-		return
-	}
-
-	updatedReservation, err := updateReservationFields(reservation, input)
-	if err != nil {
-		s.handleError(w, "Failed to process input data", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	availableInput := m.AvailabilityInput{
-		SpotIDs:   []string{updatedReservation.SpotID},
-		StartTime: updatedReservation.StartTime,
-		EndTime:   updatedReservation.EndTime,
-	}
-
-	availableSpots, err := s.MongoDB.CheckAvailabilityForEdit(availableInput, updatedReservation.ReservationID)
-	if err != nil {
-		s.handleError(w, "Failed to check availability", err, http.StatusInternalServerError)
+	if err := s.updateReservation(r.Context(), input); err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	if len(availableSpots) == 0 {
-		s.handleError(w, "Spot not available in provided timeframe", nil, http.StatusConflict)
-		return
-	}
-
-	err = s.MongoDB.EditReservation(updatedReservation)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Reservation not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to edit reservation in MongoDB", err, http.StatusInternalServerError)
-		return
-	}
-
-	s.Logger.Info.Printf("Reservation edited: %v", input.ReservationID)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) deleteReservation(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Deleting reservation")
+	s.Logger.InfoContext(r.Context(), "Deleting reservation")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing reservation ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing reservation ID", nil)
 		return
 	}
 
-	err := s.MongoDB.DeleteReservation(id)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Reservation not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to delete reservation", err, http.StatusInternalServerError)
+	if err := s.removeReservation(r.Context(), id); err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Reservation deleted: %v", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) getReservation(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting reservation")
+	s.Logger.InfoContext(r.Context(), "Getting reservation")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing reservation ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing reservation ID", nil)
 		return
 	}
 
-	reservation, err := s.MongoDB.GetReservation(id)
+	reservation, err := s.findReservation(r.Context(), id)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Reservation not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to get reservation from MongoDB", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Reservation found: %v", reservation.ReservationID)
-	s.writeJSON(w, reservation, http.StatusOK)
+	s.writeJSON(w, r, reservation, http.StatusOK)
 }
 
 func (s *Server) getAllReservations(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting all reservations")
-	reservations, err := s.MongoDB.GetAll()
-	if err != nil {
-		s.handleError(w, "Failed to get reservations", err, http.StatusInternalServerError)
-		return
-	}
-
-	if len(reservations) == 0 {
-		s.Logger.Info.Println("No reservations found")
-		s.writeJSON(w, []m.Reservation{}, http.StatusOK)
-		return
-	}
-
-	s.Logger.Info.Printf("Reservations found: %v documents", len(reservations))
-	s.writeJSON(w, reservations, http.StatusOK)
+	s.Logger.InfoContext(r.Context(), "Getting all reservations")
+	s.listReservations(w, r, m.ReservationFilter{})
 }
 
 func (s *Server) getUserReservations(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting reservations by userID")
+	s.Logger.InfoContext(r.Context(), "Getting reservations by userID")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing user ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing user ID", nil)
 		return
 	}
 
-	reservations, err := s.MongoDB.GetReservationsBy("user_id", id)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Reservations not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to get reservations from MongoDB", err, http.StatusInternalServerError)
-		return
-	}
-
-	if len(reservations) == 0 {
-		s.Logger.Info.Println("No reservations found")
-		s.writeJSON(w, []m.Reservation{}, http.StatusOK)
-		return
-	}
-
-	s.Logger.Info.Printf("Reservations found: %v documents", len(reservations))
-	s.writeJSON(w, reservations, http.StatusOK)
+	s.listReservations(w, r, m.ReservationFilter{UserID: id})
 }
 
 func (s *Server) getSpotReservations(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting reservations by spotID")
+	s.Logger.InfoContext(r.Context(), "Getting reservations by spotID")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing spot ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing spot ID", nil)
 		return
 	}
 
-	reservations, err := s.MongoDB.GetReservationsBy("spot_id", id)
+	s.listReservations(w, r, m.ReservationFilter{SpotID: id})
+}
+
+// Helper function to write one page of reservations, the path filter wins over the query
+func (s *Server) listReservations(w http.ResponseWriter, r *http.Request, pathFilter m.ReservationFilter) {
+	query := r.URL.Query()
+
+	filter, err := parseReservationFilter(query)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Reservations not found", err, http.StatusNotFound)
-			return
-		}
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
+		return
+	}
+	if pathFilter.UserID != "" {
+		filter.UserID = pathFilter.UserID
+	}
+	if pathFilter.SpotID != "" {
+		filter.SpotID = pathFilter.SpotID
+	}
 
-		s.handleError(w, "Failed to get reservations from MongoDB", err, http.StatusInternalServerError)
+	opts, err := parseListOptions(query, reservationSortFields, "start_time")
+	if err != nil {
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
 		return
 	}
 
-	if len(reservations) == 0 {
-		s.Logger.Info.Println("No reservations found")
-		s.writeJSON(w, []m.Reservation{}, http.StatusOK)
+	page, err := s.findReservations(r.Context(), filter, opts)
+	if err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Reservations found: %v documents", len(reservations))
-	s.writeJSON(w, reservations, http.StatusOK)
+	s.writeJSON(w, r, page, http.StatusOK)
 }
 
 func (s *Server) checkAvailability(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Checking availability")
+	s.Logger.InfoContext(r.Context(), "Checking availability")
 	var input m.AvailabilityInput
 
-	err := json.NewDecoder(r.Body).Decode(&input)
-	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+	ok := s.decodeInput(w, r, &input, func(query url.Values) error {
+		input.SpotIDs = parseSpotIDs(query.Get("spot_ids"))
+		if err := parseTimeQuery(query, "start_time", &input.StartTime); err != nil {
+			return err
+		}
+		return parseTimeQuery(query, "end_time", &input.EndTime)
+	})
+	if !ok {
 		return
 	}
 
-	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
+	availableSpots, err := s.availableSpots(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	availableSpots, err := s.MongoDB.CheckAvailability(input)
-	if err != nil {
-		s.handleError(w, "Failed to check availability", err, http.StatusInternalServerError)
-		return
+	s.writeJSON(w, r, availableSpots, http.StatusOK)
+}
+
+func (s *Server) getActiveReservation(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting active reservation by license plate")
+	query := r.URL.Query()
+
+	at := time.Now()
+	if raw := query.Get("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			s.handleError(w, r, problem.CodeBadRequest, "Invalid at query parameter, expected RFC3339", err)
+			return
+		}
+		at = parsed
 	}
 
-	if len(availableSpots) == 0 {
-		s.Logger.Info.Println("No available spots found")
-		s.writeJSON(w, []string{}, http.StatusOK)
+	reservation, err := s.findActiveReservation(r.Context(), query.Get("spot_id"), query.Get("license_plate"), at)
+	if err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Available spots found: %v", len(availableSpots))
-	s.writeJSON(w, availableSpots, http.StatusOK)
+	s.writeJSON(w, r, reservation, http.StatusOK)
 }
 
 // Helper function to handle errors
-func (s *Server) handleError(w http.ResponseWriter, message string, err error, statusCode int) {
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request, code problem.Code, message string, err error) {
+	s.writeProblem(w, r, problem.New(code, message), err)
+}
+
+// Helper function to write an error returned by the shared operations
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var pe *problem.Error
+	if !errors.As(err, &pe) {
+		pe = problem.NewError(problem.CodeInternal, "Unexpected server error", err)
+	}
+	s.writeProblem(w, r, pe.Problem, pe.Err)
+}
+
+// Helper function to log and write problem details
+func (s *Server) writeProblem(w http.ResponseWriter, r *http.Request, p problem.Problem, err error) {
+	level := slog.LevelError
+	if p.Status < http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+
+	args := []any{"status", p.Status, "code", p.Code}
 	if err != nil {
-		s.Logger.Error.Printf("%s: %v", message, err)
-	} else {
-		s.Logger.Error.Println(message)
+		args = append(args, "error", err)
 	}
-	http.Error(w, message, statusCode)
+	s.Logger.Log(r.Context(), level, p.Detail, args...)
+
+	p.Instance = r.URL.Path
+	p.RequestID = logger.RequestID(r.Context())
+	problem.Write(w, p)
 }
 
 // Helper function to write JSON responses
-func (s *Server) writeJSON(w http.ResponseWriter, data interface{}, statusCode int) {
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, data interface{}, statusCode int) {
 	j, err := json.Marshal(data)
 	if err != nil {
-		s.handleError(w, "Failed to encode response to JSON", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Failed to encode response to JSON", err)
 		return
 	}
 
@@ -338,6 +283,10 @@ func updateReservationFields(existingReservation m.Reservation, input editInput)
 	if input.Status != "" {
 		existingReservation.Status = input.Status
 	}
+	// A reservation taken back from canceled owes nothing anymore
+	if existingReservation.Status != m.StatusCanceled {
+		existingReservation.Cancellation = nil
+	}
 
 	if input.StartTime != nil {
 		existingReservation.StartTime = *input.StartTime
@@ -348,6 +297,12 @@ func updateReservationFields(existingReservation m.Reservation, input editInput)
 	if input.PricePaid != nil {
 		existingReservation.PricePaid = *input.PricePaid
 	}
+	if input.VehicleID != "" {
+		existingReservation.VehicleID = input.VehicleID
+	}
+	if input.LicensePlate != "" {
+		existingReservation.LicensePlate = m.NormalizePlate(input.LicensePlate)
+	}
 
 	existingReservation.UpdatedAt = time.Now()
 
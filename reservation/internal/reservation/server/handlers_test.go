@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/config"
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
-	"github.com/ciameksw/reserve-park/reservation/internal/reservation/mongodb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/memory"
 	"github.com/gorilla/mux"
 )
 
@@ -27,26 +30,21 @@ func TestMain(m *testing.M) {
 	// Get config
 	cfg := config.GetConfig()
 
-	// Connect to mock MongoDB
-	db, err := mongodb.ConnectMock()
-	if err != nil {
-		lgr.Error.Fatalf("Failed to connect to mock MongoDB: %v", err)
-	}
-	defer db.Disconnect()
-
-	s = NewServer(lgr, cfg, db)
+	// The store conformance suite covers MongoDB, the handlers run against the in-memory store
+	s = NewServer(lgr, cfg, memory.New())
 
 	os.Exit(m.Run())
 }
 
 func TestAddReservation(t *testing.T) {
 	input := addInput{
-		UserID:    userID,
-		SpotID:    spotID,
-		StartTime: time.Now(),
-		EndTime:   time.Now().Add(time.Hour),
-		Status:    "valid",
-		PricePaid: 10.0,
+		UserID:       userID,
+		SpotID:       spotID,
+		StartTime:    time.Now(),
+		EndTime:      time.Now().Add(time.Hour),
+		Status:       "valid",
+		PricePaid:    10.0,
+		LicensePlate: "wx 1234-a",
 	}
 	body, _ := json.Marshal(input)
 	req, err := http.NewRequest("POST", "/reservations", bytes.NewBuffer(body))
@@ -67,7 +65,7 @@ func TestAddReservation(t *testing.T) {
 }
 
 func TestGetAllReservations(t *testing.T) {
-	req, err := http.NewRequest("GET", "/reservations", nil)
+	req, err := http.NewRequest("GET", "/reservations?status=valid&sort=start_time&order=desc&include_total=true", nil)
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
@@ -81,14 +79,43 @@ func TestGetAllReservations(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var reservations []mongodb.Reservation
-	err = json.NewDecoder(rr.Body).Decode(&reservations)
+	var page model.Page[model.Reservation]
+	err = json.NewDecoder(rr.Body).Decode(&page)
 	if err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
 	}
 
-	if len(reservations) != 1 {
-		t.Errorf("handler returned wrong number of reservations: got %v want %v", len(reservations), 1)
+	if len(page.Items) != 1 {
+		t.Errorf("handler returned wrong number of reservations: got %v want %v", len(page.Items), 1)
+	}
+
+	if page.TotalCount == nil || *page.TotalCount != 1 {
+		t.Errorf("handler returned wrong total count: got %v want %v", page.TotalCount, 1)
+	}
+}
+
+func TestListQueryValidation(t *testing.T) {
+	tests := []string{
+		"/reservations?limit=0",
+		"/reservations?sort=password",
+		"/reservations?order=sideways",
+		"/reservations?status=expired",
+		"/reservations?from=yesterday",
+		"/reservations?cursor=not-a-cursor",
+	}
+
+	for _, target := range tests {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(s.getAllReservations).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", target, status, http.StatusBadRequest)
+		}
 	}
 }
 
@@ -110,6 +137,33 @@ func TestGetReservation(t *testing.T) {
 	}
 }
 
+func TestGetReservationNotFound(t *testing.T) {
+	req, err := http.NewRequest("GET", "/reservations/does-not-exist", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/reservations/{id}", s.getReservation).Methods("GET")
+
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+
+	var p problem.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if p.Code != problem.CodeReservationNotFound || p.Status != http.StatusNotFound {
+		t.Errorf("handler returned wrong problem: got %v/%v want %v/%v", p.Code, p.Status, problem.CodeReservationNotFound, http.StatusNotFound)
+	}
+}
+
 func TestEditReservation(t *testing.T) {
 	startTime := time.Now()
 	endTime := time.Now().Add(2 * time.Hour)
@@ -139,14 +193,39 @@ func TestEditReservation(t *testing.T) {
 	}
 }
 
-func TestCheckAvailabilityOccupied(t *testing.T) {
-	input := mongodb.AvailabilityInput{
-		SpotIDs:   []string{spotID},
-		StartTime: time.Now(),
-		EndTime:   time.Now().Add(2 * time.Hour),
+func TestGetActiveReservation(t *testing.T) {
+	req, err := http.NewRequest("GET", "/reservations/active?spot_id="+spotID+"&license_plate=WX1234A", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
 	}
-	body, _ := json.Marshal(input)
-	req, err := http.NewRequest("GET", "/reservations/availability/check", bytes.NewBuffer(body))
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.getActiveReservation)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var reservation model.Reservation
+	err = json.NewDecoder(rr.Body).Decode(&reservation)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if reservation.ReservationID != reservationID {
+		t.Errorf("handler returned wrong reservation: got %v want %v", reservation.ReservationID, reservationID)
+	}
+}
+
+func TestCheckAvailabilityOccupied(t *testing.T) {
+	query := url.Values{}
+	query.Set("spot_ids", spotID)
+	query.Set("start_time", time.Now().Format(time.RFC3339))
+	query.Set("end_time", time.Now().Add(2*time.Hour).Format(time.RFC3339))
+
+	req, err := http.NewRequest("GET", "/reservations/availability/check?"+query.Encode(), nil)
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
@@ -172,13 +251,13 @@ func TestCheckAvailabilityOccupied(t *testing.T) {
 }
 
 func TestCheckAvailabilityFree(t *testing.T) {
-	input := mongodb.AvailabilityInput{
+	input := model.AvailabilityInput{
 		SpotIDs:   []string{spotID},
 		StartTime: time.Now().Add(2 * time.Hour),
 		EndTime:   time.Now().Add(3 * time.Hour),
 	}
 	body, _ := json.Marshal(input)
-	req, err := http.NewRequest("GET", "/reservations/availability/check", bytes.NewBuffer(body))
+	req, err := http.NewRequest("POST", "/reservations/availability/check", bytes.NewBuffer(body))
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
@@ -220,14 +299,14 @@ func TestGetReservationsByUser(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var reservations []mongodb.Reservation
-	err = json.NewDecoder(rr.Body).Decode(&reservations)
+	var page model.Page[model.Reservation]
+	err = json.NewDecoder(rr.Body).Decode(&page)
 	if err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
 	}
 
-	if len(reservations) != 1 {
-		t.Errorf("handler returned wrong number of reservations: got %v want %v", len(reservations), 1)
+	if len(page.Items) != 1 {
+		t.Errorf("handler returned wrong number of reservations: got %v want %v", len(page.Items), 1)
 	}
 }
 
@@ -248,14 +327,53 @@ func TestGetReservationsBySpot(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var reservations []mongodb.Reservation
-	err = json.NewDecoder(rr.Body).Decode(&reservations)
+	var page model.Page[model.Reservation]
+	err = json.NewDecoder(rr.Body).Decode(&page)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(page.Items) != 1 {
+		t.Errorf("handler returned wrong number of reservations: got %v want %v", len(page.Items), 1)
+	}
+}
+
+func TestAssignReservation(t *testing.T) {
+	freeSpotID := "12837465092"
+	input := assignInput{
+		UserID:    "10293847561",
+		StartTime: time.Now(),
+		EndTime:   time.Now().Add(time.Hour),
+		Candidates: []assignCandidate{
+			{SpotID: spotID, PricePaid: 10.0},
+			{SpotID: freeSpotID, PricePaid: 12.0},
+		},
+		Strategy: StrategyCheapest,
+	}
+	body, _ := json.Marshal(input)
+	req, err := http.NewRequest("POST", "/reservations/assign", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.assignReservation)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+
+	var reservation model.Reservation
+	err = json.NewDecoder(rr.Body).Decode(&reservation)
 	if err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
 	}
 
-	if len(reservations) != 1 {
-		t.Errorf("handler returned wrong number of reservations: got %v want %v", len(reservations), 1)
+	// The first candidate is already taken, so the second one must be booked
+	if reservation.SpotID != freeSpotID {
+		t.Errorf("handler assigned wrong spot: got %v want %v", reservation.SpotID, freeSpotID)
 	}
 }
 
@@ -276,3 +394,88 @@ func TestDeleteReservation(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 }
+
+func TestBuildTimeline(t *testing.T) {
+	day := time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	reservations := []model.Reservation{
+		{SpotID: spotID, StartTime: at(10, 5), EndTime: at(11, 0)},
+		{SpotID: spotID, StartTime: at(8, 0), EndTime: at(9, 0)},
+		{SpotID: spotID, StartTime: at(10, 50), EndTime: at(12, 10)},
+		{SpotID: spotID, StartTime: at(12, 30), EndTime: at(12, 40)},
+	}
+
+	timeline := buildTimeline(spotID, reservations, at(7, 0), at(14, 0), 15*time.Minute, 30*time.Minute)
+
+	wantBusy := []interval{
+		{Start: at(8, 0), End: at(9, 0)},
+		{Start: at(10, 0), End: at(12, 15)},
+		{Start: at(12, 30), End: at(12, 45)},
+	}
+	if len(timeline.Busy) != len(wantBusy) {
+		t.Fatalf("wrong number of busy intervals: got %v want %v", timeline.Busy, wantBusy)
+	}
+	for i := range wantBusy {
+		if !timeline.Busy[i].Start.Equal(wantBusy[i].Start) || !timeline.Busy[i].End.Equal(wantBusy[i].End) {
+			t.Errorf("wrong busy interval %d: got %v want %v", i, timeline.Busy[i], wantBusy[i])
+		}
+	}
+
+	// The 12:15-12:30 gap is shorter than min_free and must be dropped
+	wantFree := []interval{
+		{Start: at(7, 0), End: at(8, 0)},
+		{Start: at(9, 0), End: at(10, 0)},
+		{Start: at(12, 45), End: at(14, 0)},
+	}
+	if len(timeline.Free) != len(wantFree) {
+		t.Fatalf("wrong number of free intervals: got %v want %v", timeline.Free, wantFree)
+	}
+	for i := range wantFree {
+		if !timeline.Free[i].Start.Equal(wantFree[i].Start) || !timeline.Free[i].End.Equal(wantFree[i].End) {
+			t.Errorf("wrong free interval %d: got %v want %v", i, timeline.Free[i], wantFree[i])
+		}
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	req, err := http.NewRequest("GET", "/healthz", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.healthz)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	req, err := http.NewRequest("GET", "/readyz", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.readyz)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var resp healthResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.Checks["mongodb"] != "ok" {
+		t.Errorf("handler returned wrong mongodb check: got %v want %v", resp.Checks["mongodb"], "ok")
+	}
+}
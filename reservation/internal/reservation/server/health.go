@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// healthz reports that the process is up and serving requests
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, r, healthResponse{Status: "ok"}, http.StatusOK)
+}
+
+// readyz reports whether the service can handle traffic, i.e. the store answers
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := s.Store.Ping(ctx); err != nil {
+		s.Logger.WarnContext(r.Context(), "Store is not reachable", "error", err)
+		s.writeJSON(w, r, healthResponse{
+			Status: "unavailable",
+			Checks: map[string]string{s.Config.StorageBackend: "unreachable"},
+		}, http.StatusServiceUnavailable)
+		return
+	}
+
+	s.writeJSON(w, r, healthResponse{
+		Status: "ok",
+		Checks: map[string]string{s.Config.StorageBackend: "ok"},
+	}, http.StatusOK)
+}
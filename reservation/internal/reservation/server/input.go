@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+)
+
+// Helper function to read the input of a lookup endpoint. GET requests take
+// their input from the query via parseQuery, other methods from a JSON body.
+// A GET request that still sends a JSON body is the deprecated form: it is
+// served from the body and flagged with a Deprecation header.
+func (s *Server) decodeInput(w http.ResponseWriter, r *http.Request, input interface{}, parseQuery func(url.Values) error) bool {
+	if r.Method == http.MethodGet && (r.URL.RawQuery != "" || !hasBody(r)) {
+		if err := parseQuery(r.URL.Query()); err != nil {
+			s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
+			return false
+		}
+		return true
+	}
+
+	if r.Method == http.MethodGet {
+		w.Header().Set("Deprecation", "true")
+		s.Logger.WarnContext(r.Context(), "Deprecated GET request with a JSON body", "path", r.URL.Path)
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return false
+	}
+	return true
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// Helper function to split a comma separated list of spot IDs
+func parseSpotIDs(raw string) []string {
+	var spotIDs []string
+	for _, spotID := range strings.Split(raw, ",") {
+		if spotID = strings.TrimSpace(spotID); spotID != "" {
+			spotIDs = append(spotIDs, spotID)
+		}
+	}
+	return spotIDs
+}
+
+// Helper function to parse an optional RFC3339 query parameter into dst
+func parseTimeQuery(query url.Values, name string, dst *time.Time) error {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return errors.New("Invalid " + name + " query parameter, expected RFC3339")
+	}
+	*dst = t
+	return nil
+}
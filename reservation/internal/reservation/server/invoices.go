@@ -0,0 +1,562 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The reservation service keeps neither the buyers nor the spots, the caller takes their
+// details from the user and spot services and they are copied onto the invoice.
+
+type invoiceInput struct {
+	ReservationID string        `json:"reservation_id" validate:"required"`
+	Buyer         m.Party       `json:"buyer"`
+	Spot          m.SpotDetails `json:"spot"`
+}
+
+// monthlyInvoiceInput consolidates the reservations of the user starting in the period.
+// Spots lists the details of their spots, lines of other spots only show the spot ID.
+type monthlyInvoiceInput struct {
+	UserID string          `json:"user_id" validate:"required"`
+	Period string          `json:"period" validate:"required"`
+	Buyer  m.Party         `json:"buyer"`
+	Spots  []m.SpotDetails `json:"spots" validate:"dive"`
+}
+
+// invoiceFilter selects the invoices of this tenant, newest first
+type invoiceFilter struct {
+	UserID string `json:"user_id"`
+	Period string `json:"period"`
+	Limit  int    `json:"limit" validate:"gte=0,lte=500"`
+}
+
+func (s *Server) issueInvoice(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Issuing invoice")
+	var input invoiceInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	invoice, err := s.createInvoice(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, invoice, http.StatusOK)
+}
+
+func (s *Server) issueMonthlyInvoice(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Issuing monthly invoice")
+	var input monthlyInvoiceInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	invoice, err := s.createMonthlyInvoice(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, invoice, http.StatusCreated)
+}
+
+func (s *Server) getInvoices(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting invoices")
+	query := r.URL.Query()
+
+	filter := invoiceFilter{
+		UserID: query.Get("user_id"),
+		Period: query.Get("period"),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			s.handleError(w, r, problem.CodeBadRequest, "Invalid limit query parameter", err)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	invoices, err := s.findInvoices(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, invoices, http.StatusOK)
+}
+
+func (s *Server) getInvoice(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting invoice")
+
+	invoice, err := s.findInvoice(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, invoice, http.StatusOK)
+}
+
+func (s *Server) getReservationInvoice(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting reservation invoice")
+
+	invoice, err := s.findReservationInvoice(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, invoice, http.StatusOK)
+}
+
+// createInvoice invoices the amount charged for the reservation. A reservation is
+// invoiced once, issuing it again returns the invoice issued first.
+func (s *Server) createInvoice(ctx context.Context, input invoiceInput) (m.Invoice, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return m.Invoice{}, problem.Invalid(err)
+	}
+
+	s.invoiceMu.Lock()
+	defer s.invoiceMu.Unlock()
+
+	reservation, err := s.findReservation(ctx, input.ReservationID)
+	if err != nil {
+		return m.Invoice{}, err
+	}
+
+	if invoice, err := s.Store.GetReservationInvoice(ctx, reservation.ReservationID); !errors.Is(err, m.ErrNotFound) {
+		if err != nil {
+			return invoice, problem.NewError(problem.CodeInternal, "Failed to get reservation invoice", err)
+		}
+		return invoice, nil
+	}
+
+	charge, err := s.charge(ctx, reservation)
+	if err != nil {
+		return m.Invoice{}, err
+	}
+	if charge.Amount <= 0 {
+		return m.Invoice{}, problem.NewError(problem.CodeNothingToInvoice, "Nothing was charged for the reservation", nil)
+	}
+	if input.Spot.SpotID != reservation.SpotID {
+		return m.Invoice{}, problem.NewError(problem.CodeBadRequest, "The spot details are not of the reserved spot", nil)
+	}
+
+	invoice := s.newInvoice(m.InvoiceSingle, reservation.UserID, input.Buyer)
+	invoice.AddLine(reservation, charge, input.Spot, s.invoiceTax())
+
+	stored, err := s.Store.AddInvoice(ctx, invoice)
+	if err != nil {
+		// Another request issued it since the check above
+		if errors.Is(err, m.ErrDuplicate) {
+			return s.findReservationInvoice(ctx, reservation.ReservationID)
+		}
+		return m.Invoice{}, problem.NewError(problem.CodeInternal, "Failed to add invoice", err)
+	}
+
+	metrics.InvoicesIssued.WithLabelValues(string(m.InvoiceSingle)).Inc()
+	s.Logger.InfoContext(ctx, "Invoice issued", "number", stored.Number, "reservation_id", reservation.ReservationID)
+	return stored, nil
+}
+
+// createMonthlyInvoice consolidates the charged reservations of the user starting in a past
+// month that aren't invoiced yet
+func (s *Server) createMonthlyInvoice(ctx context.Context, input monthlyInvoiceInput) (m.Invoice, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return m.Invoice{}, problem.Invalid(err)
+	}
+
+	from, err := time.Parse(m.PeriodLayout, input.Period)
+	if err != nil {
+		return m.Invoice{}, problem.NewError(problem.CodeBadRequest, "period must be a month like 2025-03", err)
+	}
+	to := from.AddDate(0, 1, 0)
+	if to.After(time.Now()) {
+		return m.Invoice{}, problem.NewError(problem.CodeBadRequest, "The month is not over yet", nil)
+	}
+
+	s.invoiceMu.Lock()
+	defer s.invoiceMu.Unlock()
+
+	reservations, charges, err := s.uninvoicedReservations(ctx, input.UserID, from, to)
+	if err != nil {
+		return m.Invoice{}, err
+	}
+	if len(reservations) == 0 {
+		return m.Invoice{}, problem.NewError(problem.CodeNothingToInvoice, "The user has no charges left to invoice in "+input.Period, nil)
+	}
+
+	spots := make(map[string]m.SpotDetails, len(input.Spots))
+	for _, spot := range input.Spots {
+		spots[spot.SpotID] = spot
+	}
+
+	invoice := s.newInvoice(m.InvoiceMonthly, input.UserID, input.Buyer)
+	invoice.Period = input.Period
+	for i, reservation := range reservations {
+		spot, ok := spots[reservation.SpotID]
+		if !ok {
+			spot = m.SpotDetails{SpotID: reservation.SpotID}
+		}
+		invoice.AddLine(reservation, charges[i], spot, s.invoiceTax())
+	}
+
+	stored, err := s.Store.AddInvoice(ctx, invoice)
+	if err != nil {
+		if errors.Is(err, m.ErrDuplicate) {
+			return m.Invoice{}, problem.NewError(problem.CodeNothingToInvoice, "Some of the reservations were invoiced meanwhile, try again", err)
+		}
+		return m.Invoice{}, problem.NewError(problem.CodeInternal, "Failed to add invoice", err)
+	}
+
+	metrics.InvoicesIssued.WithLabelValues(string(m.InvoiceMonthly)).Inc()
+	s.Logger.InfoContext(ctx, "Monthly invoice issued", "number", stored.Number, "user_id", input.UserID, "lines", len(stored.Lines))
+	return stored, nil
+}
+
+// Helper function to collect the charged reservations of the user starting in [from, to) without
+// an invoice, along with their charges
+func (s *Server) uninvoicedReservations(ctx context.Context, userID string, from, to time.Time) ([]m.Reservation, []m.Charge, error) {
+	filter := m.ReservationFilter{UserID: userID, From: &from, To: &to}
+	opts := m.ListOptions{Limit: m.MaxLimit, SortBy: "start_time"}
+
+	var reservations []m.Reservation
+	var charges []m.Charge
+	for {
+		page, err := s.Store.GetAll(ctx, filter, opts)
+		if err != nil {
+			return nil, nil, problem.NewError(problem.CodeInternal, "Failed to get reservations", err)
+		}
+
+		for _, reservation := range page.Items {
+			// Reservations overlapping the start of the month belong to the month before
+			if reservation.StartTime.Before(from) {
+				continue
+			}
+			charge, err := s.charge(ctx, reservation)
+			if err != nil {
+				return nil, nil, err
+			}
+			if charge.Amount <= 0 {
+				continue
+			}
+
+			_, err = s.Store.GetReservationInvoice(ctx, reservation.ReservationID)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, m.ErrNotFound) {
+				return nil, nil, problem.NewError(problem.CodeInternal, "Failed to get reservation invoice", err)
+			}
+			reservations = append(reservations, reservation)
+			charges = append(charges, charge)
+		}
+
+		if page.NextCursor == "" {
+			return reservations, charges, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+// charge is what the reservation charged so far: the amount its payment captured and kept,
+// or what it bills when it was booked without a payment. An authorization charges nothing yet.
+func (s *Server) charge(ctx context.Context, reservation m.Reservation) (m.Charge, error) {
+	p, err := s.Store.GetReservationPayment(ctx, reservation.ReservationID)
+	if errors.Is(err, m.ErrNotFound) {
+		return reservation.Billable(), nil
+	}
+	if err != nil {
+		return m.Charge{}, problem.NewError(problem.CodeInternal, "Failed to get reservation payment", err)
+	}
+	return reservation.Captured(p), nil
+}
+
+// correctInvoice issues a credit note when the reservation was invoiced for more than it
+// charges now, after a refund, a cancellation, an edit or a deletion. The change is stored
+// already, so a failure is only logged.
+func (s *Server) correctInvoice(ctx context.Context, reservationID string) {
+	s.invoiceMu.Lock()
+	defer s.invoiceMu.Unlock()
+
+	credited, err := s.creditInvoice(ctx, reservationID)
+	if err != nil {
+		s.Logger.ErrorContext(ctx, "Failed to credit the invoice of the reservation", "reservation_id", reservationID, "error", err)
+		return
+	}
+	if credited != nil {
+		metrics.InvoicesIssued.WithLabelValues(string(m.InvoiceCreditNote)).Inc()
+		s.Logger.InfoContext(ctx, "Credit note issued", "number", credited.Number, "credited_number", credited.CreditedNumber,
+			"reservation_id", reservationID, "total", credited.Total)
+	}
+}
+
+// Helper function to issue the credit note of correctInvoice, it returns nil when nothing is to credit
+func (s *Server) creditInvoice(ctx context.Context, reservationID string) (*m.Invoice, error) {
+	invoice, err := s.Store.GetReservationInvoice(ctx, reservationID)
+	if errors.Is(err, m.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	creditNotes, err := s.Store.GetCreditNotes(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A deleted reservation charges nothing but what its payment kept
+	reservation, err := s.Store.GetReservation(ctx, reservationID)
+	if errors.Is(err, m.ErrNotFound) {
+		reservation = m.Reservation{ReservationID: reservationID}
+	} else if err != nil {
+		return nil, err
+	}
+	charge, err := s.charge(ctx, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	excess := math.Round((m.Invoiced(reservationID, append(creditNotes, invoice)...)-max(charge.Amount, 0))*100) / 100
+	line, ok := invoice.Line(reservationID)
+	if excess <= 0 || !ok {
+		return nil, nil
+	}
+
+	creditNote := s.newInvoice(m.InvoiceCreditNote, invoice.UserID, invoice.Buyer)
+	creditNote.Seller = invoice.Seller
+	creditNote.Currency = invoice.Currency
+	creditNote.CreditedInvoiceID = invoice.InvoiceID
+	creditNote.CreditedNumber = invoice.Number
+	creditNote.AddCredit(line, excess, invoice.TaxRate())
+
+	stored, err := s.Store.AddInvoice(ctx, creditNote)
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (s *Server) newInvoice(kind m.InvoiceKind, userID string, buyer m.Party) m.Invoice {
+	return m.Invoice{
+		InvoiceID: uuid.NewString(),
+		TenantID:  s.Config.InvoiceTenant,
+		Kind:      kind,
+		UserID:    userID,
+		Seller: m.Party{
+			Name:    s.Config.InvoiceSellerName,
+			TaxID:   s.Config.InvoiceSellerTaxID,
+			Address: s.Config.InvoiceSellerAddress,
+		},
+		Buyer:    buyer,
+		Currency: s.Config.PaymentCurrency,
+		IssuedAt: time.Now().UTC(),
+	}
+}
+
+func (s *Server) invoiceTax() m.Tax {
+	return m.Tax{Name: s.Config.InvoiceTaxName, Rate: s.Config.InvoiceTaxRate}
+}
+
+func (s *Server) findInvoice(ctx context.Context, invoiceID string) (m.Invoice, error) {
+	invoice, err := s.Store.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		if errors.Is(err, m.ErrNotFound) {
+			return invoice, problem.NewError(problem.CodeInvoiceNotFound, "Invoice not found", err)
+		}
+		return invoice, problem.NewError(problem.CodeInternal, "Failed to get invoice", err)
+	}
+
+	return invoice, nil
+}
+
+func (s *Server) findReservationInvoice(ctx context.Context, reservationID string) (m.Invoice, error) {
+	invoice, err := s.Store.GetReservationInvoice(ctx, reservationID)
+	if err != nil {
+		if errors.Is(err, m.ErrNotFound) {
+			return invoice, problem.NewError(problem.CodeInvoiceNotFound, "The reservation has no invoice", err)
+		}
+		return invoice, problem.NewError(problem.CodeInternal, "Failed to get reservation invoice", err)
+	}
+
+	return invoice, nil
+}
+
+func (s *Server) findInvoices(ctx context.Context, filter invoiceFilter) ([]m.Invoice, error) {
+	if err := s.Validator.Struct(filter); err != nil {
+		return nil, problem.Invalid(err)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = m.DefaultLimit
+	}
+
+	invoices, err := s.Store.GetInvoices(ctx, m.InvoiceFilter{
+		TenantID: s.Config.InvoiceTenant,
+		UserID:   filter.UserID,
+		Period:   filter.Period,
+	}, filter.Limit)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to get invoices", err)
+	}
+	if invoices == nil {
+		invoices = []m.Invoice{}
+	}
+
+	s.Logger.InfoContext(ctx, "Invoices found", "count", len(invoices))
+	return invoices, nil
+}
+
+func (rpc *reservationRPC) IssueInvoice(ctx context.Context, req *reservationpb.IssueInvoiceRequest) (*reservationpb.Invoice, error) {
+	invoice, err := rpc.s.createInvoice(ctx, invoiceInput{
+		ReservationID: req.GetReservationId(),
+		Buyer:         partyFromProto(req.GetBuyer()),
+		Spot:          spotDetailsFromProto(req.GetSpot()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoiceToProto(invoice), nil
+}
+
+func (rpc *reservationRPC) IssueMonthlyInvoice(ctx context.Context, req *reservationpb.IssueMonthlyInvoiceRequest) (*reservationpb.Invoice, error) {
+	input := monthlyInvoiceInput{
+		UserID: req.GetUserId(),
+		Period: req.GetPeriod(),
+		Buyer:  partyFromProto(req.GetBuyer()),
+	}
+	for _, spot := range req.GetSpots() {
+		input.Spots = append(input.Spots, spotDetailsFromProto(spot))
+	}
+
+	invoice, err := rpc.s.createMonthlyInvoice(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoiceToProto(invoice), nil
+}
+
+func (rpc *reservationRPC) GetInvoice(ctx context.Context, req *reservationpb.GetInvoiceRequest) (*reservationpb.Invoice, error) {
+	invoice, err := rpc.s.findInvoice(ctx, req.GetInvoiceId())
+	if err != nil {
+		return nil, err
+	}
+
+	return invoiceToProto(invoice), nil
+}
+
+func (rpc *reservationRPC) GetReservationInvoice(ctx context.Context, req *reservationpb.GetReservationInvoiceRequest) (*reservationpb.Invoice, error) {
+	invoice, err := rpc.s.findReservationInvoice(ctx, req.GetReservationId())
+	if err != nil {
+		return nil, err
+	}
+
+	return invoiceToProto(invoice), nil
+}
+
+func (rpc *reservationRPC) ListInvoices(ctx context.Context, req *reservationpb.ListInvoicesRequest) (*reservationpb.ListInvoicesResponse, error) {
+	invoices, err := rpc.s.findInvoices(ctx, invoiceFilter{
+		UserID: req.GetUserId(),
+		Period: req.GetPeriod(),
+		Limit:  int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &reservationpb.ListInvoicesResponse{}
+	for _, invoice := range invoices {
+		resp.Items = append(resp.Items, invoiceToProto(invoice))
+	}
+
+	return resp, nil
+}
+
+func invoiceToProto(invoice m.Invoice) *reservationpb.Invoice {
+	resp := &reservationpb.Invoice{
+		InvoiceId: invoice.InvoiceID,
+		TenantId:  invoice.TenantID,
+		Number:    invoice.Number,
+		Kind:      string(invoice.Kind),
+		UserId:    invoice.UserID,
+		Period:    invoice.Period,
+		Seller:    partyToProto(invoice.Seller),
+		Buyer:     partyToProto(invoice.Buyer),
+		Currency:  invoice.Currency,
+		Net:       invoice.Net,
+		Tax:       invoice.Tax,
+		Total:     invoice.Total,
+		IssuedAt:  timestamppb.New(invoice.IssuedAt),
+
+		CreditedInvoiceId: invoice.CreditedInvoiceID,
+		CreditedNumber:    invoice.CreditedNumber,
+	}
+	for _, line := range invoice.Lines {
+		resp.Lines = append(resp.Lines, &reservationpb.InvoiceLine{
+			ReservationId: line.ReservationID,
+			Description:   line.Description,
+			Spot:          spotDetailsToProto(line.Spot),
+			StartTime:     timestamppb.New(line.StartTime),
+			EndTime:       timestamppb.New(line.EndTime),
+			Net:           line.Net,
+			Tax:           line.Tax,
+			Total:         line.Total,
+		})
+	}
+	for _, tax := range invoice.Taxes {
+		resp.Taxes = append(resp.Taxes, &reservationpb.TaxLine{Name: tax.Name, Rate: tax.Rate, Net: tax.Net, Tax: tax.Tax})
+	}
+
+	return resp
+}
+
+func partyToProto(party m.Party) *reservationpb.Party {
+	return &reservationpb.Party{Name: party.Name, Email: party.Email, TaxId: party.TaxID, Address: party.Address}
+}
+
+func partyFromProto(party *reservationpb.Party) m.Party {
+	return m.Party{Name: party.GetName(), Email: party.GetEmail(), TaxID: party.GetTaxId(), Address: party.GetAddress()}
+}
+
+func spotDetailsToProto(spot m.SpotDetails) *reservationpb.SpotDetails {
+	return &reservationpb.SpotDetails{
+		SpotId:    spot.SpotID,
+		LotId:     spot.LotID,
+		Size:      spot.Size,
+		Type:      spot.Type,
+		Latitude:  spot.Latitude,
+		Longitude: spot.Longitude,
+	}
+}
+
+func spotDetailsFromProto(spot *reservationpb.SpotDetails) m.SpotDetails {
+	return m.SpotDetails{
+		SpotID:    spot.GetSpotId(),
+		LotID:     spot.GetLotId(),
+		Size:      spot.GetSize(),
+		Type:      spot.GetType(),
+		Latitude:  spot.GetLatitude(),
+		Longitude: spot.GetLongitude(),
+	}
+}
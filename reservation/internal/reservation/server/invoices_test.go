@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/config"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/memory"
+	"github.com/google/uuid"
+)
+
+var buyer = model.Party{Name: "Jane Doe", Email: "jane@example.com"}
+
+// newInvoiceServer returns a server issuing invoices with 23% VAT
+func newInvoiceServer() *Server {
+	cfg := *config.GetConfig()
+	cfg.InvoiceTaxRate = 23
+	return NewServer(logger.GetLogger(), &cfg, memory.New())
+}
+
+func addReservation(t *testing.T, is *Server, r model.Reservation) model.Reservation {
+	t.Helper()
+
+	r.ReservationID = uuid.NewString()
+	if r.UserID == "" {
+		r.UserID = userID
+	}
+	if r.EndTime.IsZero() {
+		r.EndTime = r.StartTime.Add(time.Hour)
+	}
+	if err := is.Store.AddReservation(context.Background(), r); err != nil {
+		t.Fatalf("AddReservation: %v", err)
+	}
+	return r
+}
+
+func TestIssueInvoice(t *testing.T) {
+	is := newInvoiceServer()
+	ctx := context.Background()
+	start := time.Now().Add(-2 * time.Hour).UTC()
+
+	valid := addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: start, Status: model.StatusValid, PricePaid: 10})
+	input := invoiceInput{ReservationID: valid.ReservationID, Buyer: buyer, Spot: model.SpotDetails{SpotID: "s1", Size: "medium"}}
+
+	invoice, err := is.createInvoice(ctx, input)
+	if err != nil {
+		t.Fatalf("createInvoice: %v", err)
+	}
+	if invoice.Number != "RP-000001" || invoice.Kind != model.InvoiceSingle || invoice.Net != 8.13 || invoice.Tax != 1.87 || invoice.Total != 10 {
+		t.Errorf("got invoice %+v, want RP-000001 of 8.13 net and 1.87 tax", invoice)
+	}
+	if invoice.Seller.Name != "Reserve Park" || invoice.Buyer != buyer || invoice.Lines[0].Spot.Size != "medium" {
+		t.Errorf("got seller %+v, buyer %+v and spot %+v, want the details copied in", invoice.Seller, invoice.Buyer, invoice.Lines[0].Spot)
+	}
+
+	again, err := is.createInvoice(ctx, input)
+	if err != nil || again.InvoiceID != invoice.InvoiceID {
+		t.Errorf("issuing again = %v, %v, want the invoice issued first", again.Number, err)
+	}
+
+	canceled := addReservation(t, is, model.Reservation{
+		SpotID: "s1", StartTime: start, Status: model.StatusCanceled, PricePaid: 10,
+		Cancellation: &model.Cancellation{Fee: 2.5, Refund: 7.5},
+	})
+	fee, err := is.createInvoice(ctx, invoiceInput{ReservationID: canceled.ReservationID, Buyer: buyer, Spot: model.SpotDetails{SpotID: "s1"}})
+	if err != nil || fee.Number != "RP-000002" || fee.Total != 2.5 || fee.Lines[0].Description != "Cancellation fee" {
+		t.Errorf("invoicing a canceled reservation = %+v, %v, want its fee of 2.5 invoiced", fee, err)
+	}
+
+	tests := []struct {
+		name  string
+		input invoiceInput
+		code  problem.Code
+	}{
+		{"pending", invoiceInput{
+			ReservationID: addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: start, Status: model.StatusPending, PricePaid: 10}).ReservationID,
+			Buyer:         buyer, Spot: model.SpotDetails{SpotID: "s1"},
+		}, problem.CodeNothingToInvoice},
+		{"free cancellation", invoiceInput{
+			ReservationID: addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: start, Status: model.StatusCanceled, PricePaid: 10, Cancellation: &model.Cancellation{Refund: 10}}).ReservationID,
+			Buyer:         buyer, Spot: model.SpotDetails{SpotID: "s1"},
+		}, problem.CodeNothingToInvoice},
+		{"other spot", invoiceInput{
+			ReservationID: addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: start, Status: model.StatusValid, PricePaid: 10}).ReservationID,
+			Buyer:         buyer, Spot: model.SpotDetails{SpotID: "s2"},
+		}, problem.CodeBadRequest},
+		{"no buyer", invoiceInput{ReservationID: valid.ReservationID, Spot: model.SpotDetails{SpotID: "s1"}}, problem.CodeValidationFailed},
+		{"unknown reservation", invoiceInput{ReservationID: "missing", Buyer: buyer, Spot: model.SpotDetails{SpotID: "s1"}}, problem.CodeReservationNotFound},
+	}
+
+	for _, tt := range tests {
+		if _, err := is.createInvoice(ctx, tt.input); !hasCode(err, tt.code) {
+			t.Errorf("%v: got %v, want %v", tt.name, err, tt.code)
+		}
+	}
+}
+
+func TestIssueMonthlyInvoice(t *testing.T) {
+	is := newInvoiceServer()
+	ctx := context.Background()
+	month := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	first := addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: month.Add(24 * time.Hour), Status: model.StatusValid, PricePaid: 10})
+	second := addReservation(t, is, model.Reservation{SpotID: "s2", StartTime: month.Add(48 * time.Hour), Status: model.StatusValid, PricePaid: 5})
+	addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: month.Add(-30 * time.Minute), Status: model.StatusValid, PricePaid: 7})
+	addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: month.AddDate(0, 1, 0), Status: model.StatusValid, PricePaid: 7})
+	addReservation(t, is, model.Reservation{UserID: "other", SpotID: "s1", StartTime: month.Add(72 * time.Hour), Status: model.StatusValid, PricePaid: 7})
+
+	// Invoiced on its own already, so it's left out
+	if _, err := is.createInvoice(ctx, invoiceInput{ReservationID: second.ReservationID, Buyer: buyer, Spot: model.SpotDetails{SpotID: "s2"}}); err != nil {
+		t.Fatalf("createInvoice: %v", err)
+	}
+	third := addReservation(t, is, model.Reservation{SpotID: "s2", StartTime: month.Add(96 * time.Hour), Status: model.StatusValid, PricePaid: 20})
+
+	input := monthlyInvoiceInput{UserID: userID, Period: "2025-03", Buyer: buyer, Spots: []model.SpotDetails{{SpotID: "s1", LotID: "lot-a"}}}
+	invoice, err := is.createMonthlyInvoice(ctx, input)
+	if err != nil {
+		t.Fatalf("createMonthlyInvoice: %v", err)
+	}
+	if invoice.Kind != model.InvoiceMonthly || invoice.Period != "2025-03" || len(invoice.Lines) != 2 || invoice.Total != 30 || invoice.Net != 24.39 || invoice.Tax != 5.61 {
+		t.Fatalf("got invoice %+v, want two lines of 30 in total", invoice)
+	}
+	if invoice.Lines[0].ReservationID != first.ReservationID || invoice.Lines[0].Spot.LotID != "lot-a" || invoice.Lines[1].ReservationID != third.ReservationID || invoice.Lines[1].Spot.SpotID != "s2" {
+		t.Errorf("got lines %+v, want the reservations by start time with the spot details given", invoice.Lines)
+	}
+
+	if _, err := is.createMonthlyInvoice(ctx, input); !hasCode(err, problem.CodeNothingToInvoice) {
+		t.Errorf("issuing the month again: got %v, want NOTHING_TO_INVOICE", err)
+	}
+
+	input.Period = time.Now().Format(model.PeriodLayout)
+	if _, err := is.createMonthlyInvoice(ctx, input); !hasCode(err, problem.CodeBadRequest) {
+		t.Errorf("issuing the current month: got %v, want BAD_REQUEST", err)
+	}
+
+	invoices, err := is.findInvoices(ctx, invoiceFilter{UserID: userID, Period: "2025-03"})
+	if err != nil || len(invoices) != 1 || invoices[0].InvoiceID != invoice.InvoiceID {
+		t.Errorf("findInvoices = %d invoices, %v, want the monthly invoice", len(invoices), err)
+	}
+
+	rr := httptest.NewRecorder()
+	is.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/reservations/"+first.ReservationID+"/invoice", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("GET reservation invoice: got %d, want %d", rr.Code, http.StatusOK)
+	}
+}
+
+func TestInvoiceCapturedPayment(t *testing.T) {
+	ps := newPaymentServer()
+	ctx := context.Background()
+
+	reservation, err := book(t, ps, "s1", "pm_card_visa", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+	input := invoiceInput{ReservationID: reservation.ReservationID, Buyer: buyer, Spot: model.SpotDetails{SpotID: "s1"}}
+
+	if _, err := ps.createInvoice(ctx, input); !hasCode(err, problem.CodeNothingToInvoice) {
+		t.Errorf("invoicing an authorization: got %v, want NOTHING_TO_INVOICE", err)
+	}
+
+	if _, err := ps.SettlePayments(ctx); err != nil {
+		t.Fatalf("SettlePayments: %v", err)
+	}
+	invoice, err := ps.createInvoice(ctx, input)
+	if err != nil || invoice.Total != 12.5 {
+		t.Fatalf("invoicing the capture = %+v, %v, want 12.5 invoiced", invoice, err)
+	}
+
+	// Deleting it refunds the capture in full
+	if err := ps.removeReservation(ctx, reservation.ReservationID); err != nil {
+		t.Fatalf("removeReservation: %v", err)
+	}
+	for range 2 {
+		if _, err := ps.SettlePayments(ctx); err != nil {
+			t.Fatalf("SettlePayments: %v", err)
+		}
+	}
+	if p, err := ps.Store.GetReservationPayment(ctx, reservation.ReservationID); err != nil || p.Status != model.PaymentRefunded {
+		t.Fatalf("GetReservationPayment = %s, %v, want refunded", p.Status, err)
+	}
+
+	creditNotes, err := ps.Store.GetCreditNotes(ctx, reservation.ReservationID)
+	if err != nil || len(creditNotes) != 1 {
+		t.Fatalf("GetCreditNotes = %d credit notes, %v, want 1", len(creditNotes), err)
+	}
+	note := creditNotes[0]
+	if note.Kind != model.InvoiceCreditNote || note.Total != -12.5 || note.CreditedNumber != invoice.Number || note.Lines[0].Description != "Refund" {
+		t.Errorf("got credit note %+v, want the invoice %s taken back in full", note, invoice.Number)
+	}
+}
+
+func TestCreditCanceledInvoice(t *testing.T) {
+	is := newInvoiceServer()
+	ctx := context.Background()
+
+	reservation := addReservation(t, is, model.Reservation{SpotID: "s1", StartTime: time.Now().Add(2 * time.Hour).UTC(), Status: model.StatusValid, PricePaid: 10})
+	invoice, err := is.createInvoice(ctx, invoiceInput{ReservationID: reservation.ReservationID, Buyer: buyer, Spot: model.SpotDetails{SpotID: "s1"}})
+	if err != nil {
+		t.Fatalf("createInvoice: %v", err)
+	}
+
+	// The late fee keeps half of the price
+	if _, err := is.cancelWithPolicy(ctx, reservation.ReservationID, lotPolicy); err != nil {
+		t.Fatalf("cancelWithPolicy: %v", err)
+	}
+
+	creditNotes, err := is.Store.GetCreditNotes(ctx, reservation.ReservationID)
+	if err != nil || len(creditNotes) != 1 {
+		t.Fatalf("GetCreditNotes = %d credit notes, %v, want 1", len(creditNotes), err)
+	}
+	if note := creditNotes[0]; note.Number != "RP-000002" || note.CreditedInvoiceID != invoice.InvoiceID || note.Total != -5 || note.Net != -4.07 || note.Tax != -0.93 {
+		t.Errorf("got credit note %+v, want RP-000002 taking back 5 of %s", note, invoice.Number)
+	}
+
+	again, err := is.findReservationInvoice(ctx, reservation.ReservationID)
+	if err != nil || again.InvoiceID != invoice.InvoiceID {
+		t.Errorf("findReservationInvoice = %v, %v, want the invoice, not the credit note", again.Number, err)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+var reservationSortFields = map[string]struct{}{
+	"start_time": {},
+	"end_time":   {},
+	"price_paid": {},
+	"updated_at": {},
+}
+
+// Helper function to parse pagination and sorting query parameters
+func parseListOptions(query url.Values, sortFields map[string]struct{}, defaultSort string) (m.ListOptions, error) {
+	var limit int64
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit <= 0 {
+			return m.ListOptions{}, errInvalidLimit
+		}
+	}
+
+	return newListOptions(limit, query.Get("cursor"), query.Get("sort"), query.Get("order"),
+		query.Get("include_total") == "true", sortFields, defaultSort)
+}
+
+var errInvalidLimit = errors.New("limit must be a number between 1 and " + strconv.Itoa(m.MaxLimit))
+
+// Helper function to check pagination and sorting options, a zero limit picks the default
+func newListOptions(limit int64, cursor, sort, order string, includeTotal bool, sortFields map[string]struct{}, defaultSort string) (m.ListOptions, error) {
+	opts := m.ListOptions{
+		Limit:        limit,
+		Cursor:       cursor,
+		SortBy:       defaultSort,
+		IncludeTotal: includeTotal,
+	}
+
+	if limit < 0 || limit > m.MaxLimit {
+		return opts, errInvalidLimit
+	}
+
+	if sort != "" {
+		if _, ok := sortFields[sort]; !ok {
+			return opts, errors.New("unsupported sort field: " + sort)
+		}
+		opts.SortBy = sort
+	}
+
+	switch order {
+	case "", "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, errors.New("order must be asc or desc")
+	}
+
+	return opts, nil
+}
+
+// Helper function to parse reservation filters from query parameters
+func parseReservationFilter(query url.Values) (m.ReservationFilter, error) {
+	filter := m.ReservationFilter{
+		UserID: query.Get("user_id"),
+		SpotID: query.Get("spot_id"),
+		Status: m.StatusType(query.Get("status")),
+	}
+
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("from must be an RFC3339 time")
+		}
+		filter.From = &from
+	}
+
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("to must be an RFC3339 time")
+		}
+		filter.To = &to
+	}
+
+	return filter, nil
+}
+
+// Helper function to reject filters on unknown statuses
+func validateReservationFilter(filter m.ReservationFilter) error {
+	if filter.Status != "" && filter.Status != m.StatusPending && filter.Status != m.StatusValid && filter.Status != m.StatusCanceled {
+		return errors.New("unsupported status: " + string(filter.Status))
+	}
+
+	return nil
+}
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/google/uuid"
+)
+
+// The operations below are shared by the HTTP handlers and the gRPC API.
+// Anything the caller should see is returned as a *problem.Error.
+
+func (s *Server) createReservation(ctx context.Context, input addInput) (m.Reservation, error) {
+	data := m.Reservation{
+		ReservationID: uuid.NewString(),
+		UserID:        input.UserID,
+		SpotID:        input.SpotID,
+		StartTime:     input.StartTime,
+		EndTime:       input.EndTime,
+		Status:        input.Status,
+		PricePaid:     input.PricePaid,
+		VehicleID:     input.VehicleID,
+		LicensePlate:  m.NormalizePlate(input.LicensePlate),
+		UpdatedAt:     time.Now(),
+	}
+	if s.Payments != nil {
+		// The spot is held until the payment is authorized
+		data.Status = m.StatusPending
+	}
+
+	if err := s.Validator.Struct(data); err != nil {
+		return data, problem.Invalid(err)
+	}
+
+	if s.Payments != nil && input.PaymentMethod == "" {
+		return data, problem.NewError(problem.CodeBadRequest, "payment_method is required", nil)
+	}
+
+	if input.PromoCode != "" {
+		discount, err := s.discountBooking(ctx, data, input)
+		if err != nil {
+			return data, err
+		}
+		data.Discount = &discount
+		data.PricePaid = discount.Price()
+
+		if err := s.redeemPromoCode(ctx, data); err != nil {
+			return data, err
+		}
+	}
+
+	payment, err := s.bookReservation(ctx, data)
+	if err != nil {
+		if data.Discount != nil {
+			s.releasePromoCode(ctx, data.ReservationID)
+		}
+		return data, err
+	}
+
+	metrics.ReservationsCreated.Inc()
+	s.Logger.InfoContext(ctx, "Reservation added", "reservation_id", data.ReservationID)
+
+	if payment != nil {
+		return s.authorizePayment(ctx, data, *payment, input.PaymentMethod)
+	}
+	return data, nil
+}
+
+// discountBooking returns the discount of the promo code of a booking. A discount that was
+// quoted is honored as it is, the redemption still enforces the limits of the code.
+func (s *Server) discountBooking(ctx context.Context, data m.Reservation, input addInput) (m.Discount, error) {
+	if input.Discount == nil {
+		return s.applyPromoCode(ctx, promoQuoteInput{
+			Code:      input.PromoCode,
+			UserID:    data.UserID,
+			LotID:     input.LotID,
+			SpotType:  input.SpotType,
+			StartTime: data.StartTime,
+			EndTime:   data.EndTime,
+			Price:     data.PricePaid,
+		})
+	}
+
+	amount := *input.Discount
+	if amount <= 0 || amount >= data.PricePaid {
+		return m.Discount{}, problem.NewError(problem.CodeBadRequest, "discount must be more than 0 and less than price_paid", nil)
+	}
+	return m.Discount{Code: m.NormalizeCode(input.PromoCode), ListPrice: data.PricePaid, Amount: amount}, nil
+}
+
+// bookReservation stores the reservation if its spot is still free. The lock is released
+// before the caller authorizes the payment, the pending reservation holds the spot meanwhile.
+func (s *Server) bookReservation(ctx context.Context, data m.Reservation) (*m.Payment, error) {
+	s.bookingMu.Lock()
+	defer s.bookingMu.Unlock()
+
+	availableInput := m.AvailabilityInput{
+		SpotIDs:   []string{data.SpotID},
+		StartTime: data.StartTime,
+		EndTime:   data.EndTime,
+	}
+
+	availableSpots, err := s.Store.CheckAvailability(ctx, availableInput)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to check availability", err)
+	}
+
+	if len(availableSpots) == 0 {
+		return nil, problem.NewError(problem.CodeSpotUnavailable, "Spot not available in provided timeframe", nil)
+	}
+
+	return s.storeReservation(ctx, data)
+}
+
+// storeReservation adds a reservation whose spot was found free, together with its pending
+// payment when payments are enabled. The payment goes first: if adding the reservation fails,
+// the settler gives the payment up once its authorization window ends.
+func (s *Server) storeReservation(ctx context.Context, data m.Reservation) (*m.Payment, error) {
+	event, err := m.NewEvent(m.EventReservationCreated, data.ReservationID, data)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to build the reservation event", err)
+	}
+
+	var payment *m.Payment
+	if s.Payments != nil {
+		payment = s.newPayment(data)
+		if err := s.Store.AddPayment(ctx, *payment); err != nil {
+			return nil, problem.NewError(problem.CodeInternal, "Failed to add payment", err)
+		}
+	}
+
+	if err := s.Store.AddReservation(ctx, data, event); err != nil {
+		if err == m.ErrOverlap {
+			return nil, problem.NewError(problem.CodeSpotUnavailable, "Spot not available in provided timeframe", err)
+		}
+
+		return nil, problem.NewError(problem.CodeInternal, "Failed to add reservation", err)
+	}
+
+	return payment, nil
+}
+
+func (s *Server) updateReservation(ctx context.Context, input editInput) error {
+	if err := s.Validator.Struct(input); err != nil {
+		return problem.Invalid(err)
+	}
+
+	reservation, err := s.Store.GetReservation(ctx, input.ReservationID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeReservationNotFound, "Reservation not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to get reservation", err)
+	}
+
+	if s.Payments != nil && input.Status == m.StatusValid && reservation.Status != m.StatusValid {
+		return problem.NewError(problem.CodeBadRequest, "Only its payment makes a reservation valid", nil)
+	}
+
+	updatedReservation, err := updateReservationFields(reservation, input)
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to process input data", err)
+	}
+
+	if s.Payments != nil && updatedReservation.PricePaid != reservation.PricePaid {
+		if err := s.checkRepricing(ctx, updatedReservation); err != nil {
+			return err
+		}
+	}
+
+	s.bookingMu.Lock()
+	defer s.bookingMu.Unlock()
+
+	availableInput := m.AvailabilityInput{
+		SpotIDs:   []string{updatedReservation.SpotID},
+		StartTime: updatedReservation.StartTime,
+		EndTime:   updatedReservation.EndTime,
+	}
+
+	availableSpots, err := s.Store.CheckAvailabilityForEdit(ctx, availableInput, updatedReservation.ReservationID)
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to check availability", err)
+	}
+
+	if len(availableSpots) == 0 {
+		return problem.NewError(problem.CodeSpotUnavailable, "Spot not available in provided timeframe", nil)
+	}
+
+	eventType := m.EventReservationUpdated
+	if reservation.Status != m.StatusCanceled && updatedReservation.Status == m.StatusCanceled {
+		eventType = m.EventReservationCanceled
+	}
+
+	event, err := m.NewEvent(eventType, updatedReservation.ReservationID, m.NewReservationChanged(reservation, updatedReservation))
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to build the reservation event", err)
+	}
+
+	err = s.Store.EditReservation(ctx, updatedReservation, event)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeReservationNotFound, "Reservation not found", err)
+		}
+		if err == m.ErrOverlap {
+			return problem.NewError(problem.CodeSpotUnavailable, "Spot not available in provided timeframe", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to edit reservation", err)
+	}
+
+	if eventType == m.EventReservationCanceled {
+		metrics.ReservationsCanceled.Inc()
+	}
+	if eventType == m.EventReservationCanceled || !updatedReservation.StartTime.Equal(reservation.StartTime) {
+		s.followUpPayment(ctx, updatedReservation, false)
+	}
+	s.correctInvoice(ctx, input.ReservationID)
+
+	s.Logger.InfoContext(ctx, "Reservation edited", "reservation_id", input.ReservationID)
+	return nil
+}
+
+func (s *Server) removeReservation(ctx context.Context, reservationID string) error {
+	reservation, err := s.Store.GetReservation(ctx, reservationID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeReservationNotFound, "Reservation not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to get reservation", err)
+	}
+
+	// The slot lets consumers tell which spot was freed
+	payload := m.ReservationDeleted{ReservationID: reservationID, Slot: reservation.Slot()}
+	event, err := m.NewEvent(m.EventReservationDeleted, reservationID, payload)
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to build the reservation event", err)
+	}
+
+	err = s.Store.DeleteReservation(ctx, reservationID, event)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeReservationNotFound, "Reservation not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to delete reservation", err)
+	}
+	s.followUpPayment(ctx, reservation, true)
+	s.correctInvoice(ctx, reservationID)
+
+	s.Logger.InfoContext(ctx, "Reservation deleted", "reservation_id", reservationID)
+	return nil
+}
+
+func (s *Server) findReservation(ctx context.Context, reservationID string) (m.Reservation, error) {
+	reservation, err := s.Store.GetReservation(ctx, reservationID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return reservation, problem.NewError(problem.CodeReservationNotFound, "Reservation not found", err)
+		}
+
+		return reservation, problem.NewError(problem.CodeInternal, "Failed to get reservation", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Reservation found", "reservation_id", reservation.ReservationID)
+	return reservation, nil
+}
+
+func (s *Server) findReservations(ctx context.Context, filter m.ReservationFilter, opts m.ListOptions) (m.Page[m.Reservation], error) {
+	if err := validateReservationFilter(filter); err != nil {
+		return m.Page[m.Reservation]{}, problem.NewError(problem.CodeBadRequest, err.Error(), err)
+	}
+
+	page, err := s.Store.GetAll(ctx, filter, opts)
+	if err != nil {
+		if err == m.ErrInvalidCursor {
+			return page, problem.NewError(problem.CodeInvalidCursor, "Invalid cursor", err)
+		}
+
+		return page, problem.NewError(problem.CodeInternal, "Failed to get reservations", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Reservations found", "count", len(page.Items))
+	return page, nil
+}
+
+func (s *Server) availableSpots(ctx context.Context, input m.AvailabilityInput) ([]string, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return nil, problem.Invalid(err)
+	}
+
+	metrics.AvailabilityChecks.Inc()
+	availableSpots, err := s.Store.CheckAvailability(ctx, input)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to check availability", err)
+	}
+
+	if len(availableSpots) == 0 {
+		s.Logger.InfoContext(ctx, "No available spots found")
+		return []string{}, nil
+	}
+
+	s.Logger.InfoContext(ctx, "Available spots found", "count", len(availableSpots))
+	return availableSpots, nil
+}
+
+func (s *Server) findActiveReservation(ctx context.Context, spotID, licensePlate string, at time.Time) (m.Reservation, error) {
+	if spotID == "" || licensePlate == "" {
+		return m.Reservation{}, problem.NewError(problem.CodeBadRequest, "spot_id and license_plate are required", nil)
+	}
+
+	reservation, err := s.Store.GetActiveReservation(ctx, spotID, licensePlate, at)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return reservation, problem.NewError(problem.CodeReservationNotFound, "No valid reservation for this plate and spot", err)
+		}
+
+		return reservation, problem.NewError(problem.CodeInternal, "Failed to get reservation", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Active reservation found", "reservation_id", reservation.ReservationID)
+	return reservation, nil
+}
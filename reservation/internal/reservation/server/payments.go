@@ -0,0 +1,571 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/payment"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// With payments enabled a reservation goes through these steps, each one recorded in its
+// payment, so the settler picks up where a crashed instance left off:
+//   - it is booked as pending together with a pending payment, due when the authorization window ends
+//   - the authorization confirms it and makes the payment due at its start, a declined one cancels it
+//   - the settler captures the payment once it starts, or voids or refunds it once it's canceled or deleted
+const (
+	// How long a claimed payment stays hidden from the settlers of other instances
+	paymentLease     = time.Minute
+	paymentBatchSize = 20
+
+	// Most bytes read from a provider callback
+	maxCallbackSize = 1 << 20
+)
+
+func (s *Server) getReservationPayment(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting reservation payment")
+
+	p, err := s.findReservationPayment(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, p, http.StatusOK)
+}
+
+func (s *Server) paymentCallback(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Handling payment provider callback")
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackSize))
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to read request body", err)
+		return
+	}
+
+	if err := s.handlePaymentCallback(r.Context(), r.Header, body); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rpc *reservationRPC) GetReservationPayment(ctx context.Context, req *reservationpb.GetReservationPaymentRequest) (*reservationpb.Payment, error) {
+	p, err := rpc.s.findReservationPayment(ctx, req.GetReservationId())
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentToProto(p), nil
+}
+
+func (rpc *reservationRPC) HandlePaymentCallback(ctx context.Context, req *reservationpb.PaymentCallbackRequest) (*emptypb.Empty, error) {
+	header := make(http.Header, len(req.GetHeaders()))
+	for name, value := range req.GetHeaders() {
+		header.Set(name, value)
+	}
+
+	if err := rpc.s.handlePaymentCallback(ctx, header, req.GetBody()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) findReservationPayment(ctx context.Context, reservationID string) (m.Payment, error) {
+	p, err := s.Store.GetReservationPayment(ctx, reservationID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return p, problem.NewError(problem.CodePaymentNotFound, "Reservation has no payment", err)
+		}
+
+		return p, problem.NewError(problem.CodeInternal, "Failed to get payment", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Payment found", "payment_id", p.PaymentID, "reservation_id", reservationID)
+	return p, nil
+}
+
+// newPayment prepares the pending payment of a reservation booked while payments are enabled
+func (s *Server) newPayment(reservation m.Reservation) *m.Payment {
+	now := time.Now().UTC()
+	dueAt := now.Add(s.Config.PaymentAuthorizationTTL)
+
+	return &m.Payment{
+		PaymentID:     uuid.NewString(),
+		ReservationID: reservation.ReservationID,
+		Provider:      s.Payments.Name(),
+		Status:        m.PaymentPending,
+		Currency:      s.Config.PaymentCurrency,
+		Amount:        m.MinorUnits(reservation.PricePaid),
+		DueAt:         &dueAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// checkRepricing tells whether an edit may change the price of a reservation. Its payment
+// can't be charged more than it authorized, and a captured amount is final.
+func (s *Server) checkRepricing(ctx context.Context, reservation m.Reservation) error {
+	p, err := s.Store.GetReservationPayment(ctx, reservation.ReservationID)
+	if err == m.ErrNotFound {
+		// Booked before payments were enabled
+		return nil
+	}
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to get payment", err)
+	}
+
+	switch {
+	case p.Status == m.PaymentCaptured || p.Status == m.PaymentRefunded:
+		return problem.NewError(problem.CodeBadRequest, "The price of a paid reservation can't change", nil)
+	case m.MinorUnits(reservation.PricePaid) > p.Amount:
+		return problem.NewError(problem.CodeBadRequest, "The new price is more than the payment authorized, cancel and book again", nil)
+	}
+	return nil
+}
+
+// authorizePayment asks the provider to hold the price of a pending reservation and
+// returns the reservation as the outcome left it: confirmed, still pending while the
+// customer finishes the authorization, or canceled with a PAYMENT_FAILED problem.
+func (s *Server) authorizePayment(ctx context.Context, reservation m.Reservation, p m.Payment, paymentMethod string) (m.Reservation, error) {
+	result, err := s.Payments.Authorize(ctx, payment.AuthorizeRequest{
+		IdempotencyKey: p.PaymentID,
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		PaymentMethod:  paymentMethod,
+		Description:    "Parking reservation " + reservation.ReservationID,
+		Metadata:       map[string]string{"reservation_id": reservation.ReservationID, "payment_id": p.PaymentID},
+	})
+	observeCall("authorize", result, err)
+
+	if err != nil {
+		// The outcome is unknown, so the spot is released rather than held for the whole
+		// authorization window. A hold the provider placed anyway expires on its side.
+		s.Logger.ErrorContext(ctx, "Payment authorization failed", "payment_id", p.PaymentID, "error", err)
+		result = payment.Result{Status: m.PaymentFailed, FailureReason: "provider_unavailable"}
+		if _, err := s.advancePayment(ctx, p, result); err != nil {
+			s.Logger.ErrorContext(ctx, "Failed to release the reservation", "reservation_id", reservation.ReservationID, "error", err)
+		}
+		// Not reported as unavailable, the facade's circuit breaker would take that for this service failing
+		return reservation, problem.NewError(problem.CodePaymentFailed, "The payment provider is unavailable, try again later", err)
+	}
+
+	p, err = s.advancePayment(ctx, p, result)
+	if err != nil {
+		return reservation, problem.NewError(problem.CodeInternal, "Failed to store the payment", err)
+	}
+	if p.Status == m.PaymentFailed {
+		return reservation, problem.NewError(problem.CodePaymentFailed, "The payment was declined: "+p.FailureReason, nil)
+	}
+
+	updated, err := s.Store.GetReservation(ctx, reservation.ReservationID)
+	if err != nil {
+		return reservation, problem.NewError(problem.CodeInternal, "Failed to get reservation", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Payment authorization handled", "payment_id", p.PaymentID, "status", p.Status)
+	return updated, nil
+}
+
+// handlePaymentCallback applies a payment change reported by the provider. A callback
+// is applied once: its ID is claimed before it's handled, and a callback claimed already,
+// e.g. by a concurrent delivery, is acknowledged without touching the payment. A callback
+// that couldn't be handled is released, so the provider's next delivery applies it.
+func (s *Server) handlePaymentCallback(ctx context.Context, header http.Header, body []byte) error {
+	if s.Payments == nil {
+		return problem.NewError(problem.CodeNotFound, "Payments are not enabled", nil)
+	}
+	provider := s.Payments.Name()
+
+	cb, err := s.Payments.ParseCallback(header, body)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		return problem.NewError(problem.CodeInvalidSignature, "The callback signature doesn't match its body", err)
+	}
+	if err != nil {
+		return problem.NewError(problem.CodeMalformedBody, "Failed to decode the callback", err)
+	}
+	if cb.EventID == "" {
+		return problem.NewError(problem.CodeBadRequest, "The callback has no event ID", nil)
+	}
+
+	claimed, err := s.Store.ClaimProviderEvent(ctx, provider, cb.EventID, time.Now().UTC())
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to record the callback", err)
+	}
+	if !claimed {
+		metrics.PaymentCallbacks.WithLabelValues("duplicate").Inc()
+		s.Logger.InfoContext(ctx, "Payment callback handled before", "event_id", cb.EventID)
+		return nil
+	}
+
+	outcome, err := s.applyCallback(ctx, provider, cb)
+	if err != nil {
+		if releaseErr := s.Store.ReleaseProviderEvent(ctx, provider, cb.EventID); releaseErr != nil {
+			s.Logger.ErrorContext(ctx, "Failed to release the payment callback, its next delivery is ignored",
+				"event_id", cb.EventID, "error", releaseErr)
+		}
+		return err
+	}
+
+	metrics.PaymentCallbacks.WithLabelValues(outcome).Inc()
+	s.Logger.InfoContext(ctx, "Payment callback handled", "event_id", cb.EventID, "status", cb.Status, "outcome", outcome)
+	return nil
+}
+
+// Helper function to move the payment of a claimed callback, it returns the outcome for the metrics
+func (s *Server) applyCallback(ctx context.Context, provider string, cb payment.Callback) (string, error) {
+	if cb.Status == "" {
+		return "ignored", nil
+	}
+
+	p, err := s.Store.GetPaymentByProviderRef(ctx, provider, cb.Ref)
+	if err != nil {
+		// Released, the provider sends it again in case the authorization that
+		// stores the reference was still on its way
+		if err == m.ErrNotFound {
+			return "", problem.NewError(problem.CodePaymentNotFound, "No payment has the reference "+cb.Ref, err)
+		}
+		return "", problem.NewError(problem.CodeInternal, "Failed to get payment", err)
+	}
+
+	result := payment.Result{Ref: cb.Ref, Status: cb.Status, FailureReason: cb.FailureReason}
+	if _, err := s.advancePayment(ctx, p, result); err != nil {
+		return "", problem.NewError(problem.CodeInternal, "Failed to apply the callback", err)
+	}
+	return "applied", nil
+}
+
+// advancePayment moves the payment to the status the provider reported and brings its
+// reservation along: an authorization confirms a pending reservation, a failure or a void
+// cancels it. A status the payment can't move to anymore arrived late and is ignored.
+func (s *Server) advancePayment(ctx context.Context, p m.Payment, result payment.Result) (m.Payment, error) {
+	now := time.Now().UTC()
+	if result.Ref != "" {
+		p.ProviderRef = result.Ref
+	}
+
+	if result.Status == p.Status {
+		// Still waiting, e.g. for 3-D Secure
+		if result.ClientSecret != "" {
+			p.ClientSecret = result.ClientSecret
+		}
+		p.UpdatedAt = now
+		return p, s.storePayment(ctx, p)
+	}
+	if !p.CanMoveTo(result.Status) {
+		s.Logger.InfoContext(ctx, "Ignoring a late payment status", "payment_id", p.PaymentID, "status", p.Status, "reported", result.Status)
+		return p, nil
+	}
+
+	reservation, err := s.Store.GetReservation(ctx, p.ReservationID)
+	if err != nil && err != m.ErrNotFound {
+		return p, err
+	}
+	gone := err == m.ErrNotFound || reservation.Status == m.StatusCanceled
+
+	p.Status = result.Status
+	p.ClientSecret = ""
+	p.DueAt = nil
+	p.UpdatedAt = now
+
+	switch p.Status {
+	case m.PaymentAuthorized:
+		// Stored before the confirmation, the settler confirms the reservation if that fails
+		dueAt := reservation.StartTime
+		if gone {
+			dueAt = now
+		}
+		p.DueAt = &dueAt
+		if err := s.Store.UpdatePayment(ctx, p); err != nil {
+			return p, err
+		}
+		if !gone {
+			return p, s.moveReservation(ctx, p.ReservationID, m.StatusPending, m.StatusValid, m.EventReservationConfirmed)
+		}
+		return p, nil
+	case m.PaymentCaptured:
+		if p.Captured == 0 {
+			p.Captured = p.Amount
+		}
+		if gone {
+			p.DueAt = &now
+		}
+	case m.PaymentRefunded:
+		if p.Refunded == 0 {
+			p.Refunded = p.Captured
+		}
+	case m.PaymentFailed, m.PaymentVoided:
+		p.FailureReason = result.FailureReason
+
+		// Canceled before the payment is stored, while the payment is still pending the settler voids it again
+		if !gone {
+			if err := s.moveReservation(ctx, p.ReservationID, m.StatusPending, m.StatusCanceled, m.EventReservationCanceled); err != nil {
+				return p, err
+			}
+		}
+	}
+
+	return p, s.storePayment(ctx, p)
+}
+
+// storePayment updates the payment and credits the invoice of its reservation for a refund
+func (s *Server) storePayment(ctx context.Context, p m.Payment) error {
+	if err := s.Store.UpdatePayment(ctx, p); err != nil {
+		return err
+	}
+	if p.Status == m.PaymentRefunded {
+		s.correctInvoice(ctx, p.ReservationID)
+	}
+	return nil
+}
+
+// moveReservation changes the status of a reservation on behalf of its payment. It does
+// nothing when the reservation isn't in the from status anymore, e.g. canceled meanwhile.
+func (s *Server) moveReservation(ctx context.Context, reservationID string, from, to m.StatusType, eventType string) error {
+	s.bookingMu.Lock()
+	defer s.bookingMu.Unlock()
+
+	reservation, err := s.Store.GetReservation(ctx, reservationID)
+	if err == m.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if reservation.Status != from {
+		return nil
+	}
+
+	updated := reservation
+	updated.Status = to
+	updated.UpdatedAt = time.Now()
+
+	event, err := m.NewEvent(eventType, reservationID, m.NewReservationChanged(reservation, updated))
+	if err != nil {
+		return err
+	}
+	if err := s.Store.EditReservation(ctx, updated, event); err != nil {
+		return err
+	}
+
+	if to == m.StatusCanceled {
+		metrics.ReservationsCanceled.Inc()
+
+		// The payment failed, the promo code wasn't used after all
+		if reservation.Discount != nil {
+			s.releasePromoCode(ctx, reservationID)
+		}
+	}
+	s.Logger.InfoContext(ctx, "Reservation status changed by its payment", "reservation_id", reservationID, "status", to)
+	return nil
+}
+
+// followUpPayment makes the settler look at the payment of an edited or deleted reservation:
+// right away to release the money of a canceled one, at the new start to capture a moved one.
+// The change is stored already, so a failure is only logged.
+func (s *Server) followUpPayment(ctx context.Context, reservation m.Reservation, deleted bool) {
+	if s.Payments == nil {
+		return
+	}
+
+	p, err := s.Store.GetReservationPayment(ctx, reservation.ReservationID)
+	if err == m.ErrNotFound {
+		return
+	}
+	if err != nil {
+		s.Logger.ErrorContext(ctx, "Failed to get the payment of the reservation", "reservation_id", reservation.ReservationID, "error", err)
+		return
+	}
+
+	var dueAt time.Time
+	switch {
+	case deleted || reservation.Status == m.StatusCanceled:
+		if p.Status != m.PaymentPending && p.Status != m.PaymentAuthorized && p.Status != m.PaymentCaptured {
+			return
+		}
+		dueAt = time.Now().UTC()
+	case p.Status == m.PaymentAuthorized:
+		dueAt = reservation.StartTime
+	default:
+		return
+	}
+
+	p.DueAt = &dueAt
+	if err := s.Store.UpdatePayment(ctx, p); err != nil {
+		s.Logger.ErrorContext(ctx, "Failed to schedule the payment", "payment_id", p.PaymentID, "error", err)
+	}
+}
+
+// RunPayments settles the due payments until the context is canceled
+func (s *Server) RunPayments(ctx context.Context) {
+	ticker := time.NewTicker(s.Config.PaymentPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SettlePayments(ctx); err != nil && ctx.Err() == nil {
+			s.Logger.ErrorContext(ctx, "Failed to settle payments", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SettlePayments moves the due payments on and returns how many it looked at. A payment
+// that couldn't be settled is due again once its lease ends.
+func (s *Server) SettlePayments(ctx context.Context) (int, error) {
+	settled := 0
+
+	for {
+		payments, err := s.Store.ClaimPayments(ctx, time.Now().UTC(), paymentLease, paymentBatchSize)
+		if err != nil {
+			return settled, err
+		}
+
+		for _, p := range payments {
+			if err := s.settlePayment(ctx, p); err != nil {
+				s.Logger.ErrorContext(ctx, "Failed to settle payment", "payment_id", p.PaymentID, "status", p.Status, "error", err)
+			}
+		}
+		settled += len(payments)
+
+		if len(payments) < paymentBatchSize {
+			return settled, nil
+		}
+	}
+}
+
+// settlePayment does what a due payment waits for, depending on its reservation
+func (s *Server) settlePayment(ctx context.Context, p m.Payment) error {
+	reservation, err := s.Store.GetReservation(ctx, p.ReservationID)
+	if err != nil && err != m.ErrNotFound {
+		return err
+	}
+	gone := err == m.ErrNotFound || reservation.Status == m.StatusCanceled
+	now := time.Now().UTC()
+
+	// The fee of a canceled reservation is kept, a deleted one is refunded in full
+	retained := min(m.MinorUnits(reservation.RetainedFee()), p.Amount)
+
+	switch {
+	case p.Status == m.PaymentAuthorized && gone && retained > 0:
+		// Capturing part of the authorization releases the rest
+		result, err := s.Payments.Capture(ctx, p.ProviderRef, retained, p.PaymentID+"-capture")
+		observeCall("capture", result, err)
+		if err != nil {
+			return err
+		}
+		if result.Status == m.PaymentCaptured {
+			p.Captured = retained
+		}
+		_, err = s.advancePayment(ctx, p, result)
+		return err
+
+	case p.Status == m.PaymentPending, p.Status == m.PaymentAuthorized && gone:
+		// The authorization window ended, or the reservation went before it started
+		result := payment.Result{Status: m.PaymentVoided, FailureReason: "authorization_expired"}
+		if gone {
+			result.FailureReason = "reservation_canceled"
+		}
+		if p.ProviderRef != "" {
+			voided, err := s.Payments.Void(ctx, p.ProviderRef, p.PaymentID+"-void")
+			observeCall("void", voided, err)
+			if err != nil {
+				return err
+			}
+			result.Ref, result.Status = voided.Ref, voided.Status
+		}
+		_, err := s.advancePayment(ctx, p, result)
+		return err
+
+	case p.Status == m.PaymentAuthorized && reservation.Status == m.StatusPending:
+		// The confirmation after the authorization didn't go through
+		if err := s.moveReservation(ctx, p.ReservationID, m.StatusPending, m.StatusValid, m.EventReservationConfirmed); err != nil {
+			return err
+		}
+		p.DueAt = &reservation.StartTime
+		return s.Store.UpdatePayment(ctx, p)
+
+	case p.Status == m.PaymentAuthorized:
+		if now.Before(reservation.StartTime) {
+			// The reservation was moved to a later start
+			p.DueAt = &reservation.StartTime
+			return s.Store.UpdatePayment(ctx, p)
+		}
+
+		// An edit can lower the price, a higher one can't be captured from this authorization
+		amount := min(m.MinorUnits(reservation.PricePaid), p.Amount)
+		result, err := s.Payments.Capture(ctx, p.ProviderRef, amount, p.PaymentID+"-capture")
+		observeCall("capture", result, err)
+		if err != nil {
+			return err
+		}
+		if result.Status == m.PaymentCaptured {
+			p.Captured = amount
+		}
+		_, err = s.advancePayment(ctx, p, result)
+		return err
+
+	case p.Status == m.PaymentCaptured && gone && p.Captured-p.Refunded > retained:
+		amount := p.Captured - p.Refunded - retained
+		result, err := s.Payments.Refund(ctx, p.ProviderRef, amount, p.PaymentID+"-refund")
+		observeCall("refund", result, err)
+		if err != nil {
+			return err
+		}
+		if result.Status == m.PaymentRefunded {
+			p.Refunded += amount
+		}
+		_, err = s.advancePayment(ctx, p, result)
+		return err
+
+	default:
+		// Nothing left to do
+		p.DueAt = nil
+		return s.Store.UpdatePayment(ctx, p)
+	}
+}
+
+func paymentToProto(p m.Payment) *reservationpb.Payment {
+	return &reservationpb.Payment{
+		PaymentId:      p.PaymentID,
+		ReservationId:  p.ReservationID,
+		Provider:       p.Provider,
+		ProviderRef:    p.ProviderRef,
+		Status:         string(p.Status),
+		Currency:       p.Currency,
+		Amount:         p.Amount,
+		AmountCaptured: p.Captured,
+		AmountRefunded: p.Refunded,
+		ClientSecret:   p.ClientSecret,
+		FailureReason:  p.FailureReason,
+		CreatedAt:      timestamppb.New(p.CreatedAt),
+		UpdatedAt:      timestamppb.New(p.UpdatedAt),
+	}
+}
+
+// Helper function to count a provider call by its outcome
+func observeCall(operation string, result payment.Result, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	} else if result.Status == m.PaymentFailed {
+		outcome = "declined"
+	}
+	metrics.PaymentCalls.WithLabelValues(operation, outcome).Inc()
+}
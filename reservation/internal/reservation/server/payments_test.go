@@ -0,0 +1,237 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/config"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/payment"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/memory"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/webhook"
+)
+
+const callbackSecret = "0123456789abcdef"
+
+// newPaymentServer returns a server taking payments through the fake provider
+func newPaymentServer() *Server {
+	ps := NewServer(logger.GetLogger(), config.GetConfig(), memory.New())
+	ps.Payments = &payment.Fake{Secret: callbackSecret}
+	return ps
+}
+
+func book(t *testing.T, ps *Server, spotID, method string, start time.Time) (model.Reservation, error) {
+	t.Helper()
+
+	return ps.createReservation(context.Background(), addInput{
+		UserID:        userID,
+		SpotID:        spotID,
+		StartTime:     start,
+		EndTime:       start.Add(time.Hour),
+		Status:        model.StatusValid,
+		PricePaid:     12.5,
+		PaymentMethod: method,
+	})
+}
+
+func reservationPayment(t *testing.T, ps *Server, reservationID string) (model.Reservation, model.Payment) {
+	t.Helper()
+
+	reservation, err := ps.Store.GetReservation(context.Background(), reservationID)
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	p, err := ps.Store.GetReservationPayment(context.Background(), reservationID)
+	if err != nil {
+		t.Fatalf("GetReservationPayment: %v", err)
+	}
+	return reservation, p
+}
+
+func hasCode(err error, code problem.Code) bool {
+	var perr *problem.Error
+	return errors.As(err, &perr) && perr.Problem.Code == code
+}
+
+func TestPaymentAuthorization(t *testing.T) {
+	ps := newPaymentServer()
+	start := time.Now().Add(time.Hour).UTC()
+
+	if _, err := book(t, ps, "s1", "", start); !hasCode(err, problem.CodeBadRequest) {
+		t.Errorf("booking without a payment method: got %v, want BAD_REQUEST", err)
+	}
+
+	reservation, err := book(t, ps, "s1", "pm_card_visa", start)
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+	reservation, p := reservationPayment(t, ps, reservation.ReservationID)
+	if reservation.Status != model.StatusValid {
+		t.Errorf("reservation status = %s, want valid", reservation.Status)
+	}
+	if p.Status != model.PaymentAuthorized || p.Amount != 1250 || p.DueAt == nil || !p.DueAt.Equal(reservation.StartTime) {
+		t.Errorf("payment = %+v, want authorized for 1250 and due at the start", p)
+	}
+
+	declined, err := book(t, ps, "s2", payment.FakeMethodDeclined, start)
+	if !hasCode(err, problem.CodePaymentFailed) {
+		t.Fatalf("declined booking: got %v, want PAYMENT_FAILED", err)
+	}
+	declined, p = reservationPayment(t, ps, declined.ReservationID)
+	if declined.Status != model.StatusCanceled || p.Status != model.PaymentFailed || p.FailureReason != "card_declined" {
+		t.Errorf("declined reservation %s with payment %+v, want canceled and failed", declined.Status, p)
+	}
+
+	// The declined booking released its spot
+	if _, err := book(t, ps, "s2", "pm_card_visa", start); err != nil {
+		t.Errorf("booking the released spot: %v", err)
+	}
+
+	err = ps.updateReservation(context.Background(), editInput{ReservationID: declined.ReservationID, Status: model.StatusValid})
+	if !hasCode(err, problem.CodeBadRequest) {
+		t.Errorf("validating a reservation by hand: got %v, want BAD_REQUEST", err)
+	}
+}
+
+func TestPaymentCallback(t *testing.T) {
+	ps := newPaymentServer()
+
+	reservation, err := book(t, ps, "s1", payment.FakeMethodActionRequired, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+	if reservation.Status != model.StatusPending {
+		t.Fatalf("reservation status = %s, want pending", reservation.Status)
+	}
+
+	rr := httptest.NewRecorder()
+	ps.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/reservations/"+reservation.ReservationID+"/payment", nil))
+	var p model.Payment
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if rr.Code != http.StatusOK || p.Status != model.PaymentPending || p.ClientSecret == "" {
+		t.Fatalf("got %d with payment %+v, want a pending payment with its client secret", rr.Code, p)
+	}
+
+	callback := func(id, ref string, secret string) int {
+		body, _ := json.Marshal(map[string]string{"id": id, "payment_ref": ref, "status": "authorized"})
+		req := httptest.NewRequest("POST", "/payments/callback", bytes.NewReader(body))
+		req.Header.Set(payment.FakeSignatureHeader, webhook.Sign(secret, time.Now(), body))
+
+		rr := httptest.NewRecorder()
+		ps.Handler().ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if status := callback("evt_1", p.ProviderRef, "wrong-secret"); status != http.StatusBadRequest {
+		t.Errorf("callback with a wrong signature: got %d, want %d", status, http.StatusBadRequest)
+	}
+	if status := callback("evt_2", "fake_unknown", callbackSecret); status != http.StatusNotFound {
+		t.Errorf("callback for an unknown payment: got %d, want %d", status, http.StatusNotFound)
+	}
+	// Not handled, so it isn't taken for a duplicate when it's delivered again
+	if status := callback("evt_2", "fake_unknown", callbackSecret); status != http.StatusNotFound {
+		t.Errorf("callback for an unknown payment again: got %d, want %d", status, http.StatusNotFound)
+	}
+
+	if status := callback("evt_1", p.ProviderRef, callbackSecret); status != http.StatusNoContent {
+		t.Fatalf("callback: got %d, want %d", status, http.StatusNoContent)
+	}
+	reservation, p = reservationPayment(t, ps, reservation.ReservationID)
+	if reservation.Status != model.StatusValid || p.Status != model.PaymentAuthorized || p.ClientSecret != "" {
+		t.Errorf("after the callback: reservation %s, payment %+v, want valid and authorized", reservation.Status, p)
+	}
+
+	// The same event again is acknowledged without another change
+	updatedAt := p.UpdatedAt
+	if status := callback("evt_1", p.ProviderRef, callbackSecret); status != http.StatusNoContent {
+		t.Errorf("duplicate callback: got %d, want %d", status, http.StatusNoContent)
+	}
+	if _, p = reservationPayment(t, ps, reservation.ReservationID); !p.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("duplicate callback changed the payment: %+v", p)
+	}
+}
+
+func TestSettlePayments(t *testing.T) {
+	ps := newPaymentServer()
+	ctx := context.Background()
+
+	started, err := book(t, ps, "s1", "pm_card_visa", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+	upcoming, err := book(t, ps, "s2", "pm_card_visa", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+	if err := ps.updateReservation(ctx, editInput{ReservationID: upcoming.ReservationID, Status: model.StatusCanceled}); err != nil {
+		t.Fatalf("updateReservation: %v", err)
+	}
+
+	if n, err := ps.SettlePayments(ctx); err != nil || n != 2 {
+		t.Fatalf("SettlePayments = %d, %v, want 2 settled", n, err)
+	}
+	if _, p := reservationPayment(t, ps, started.ReservationID); p.Status != model.PaymentCaptured || p.Captured != 1250 || p.DueAt != nil {
+		t.Errorf("started reservation payment = %+v, want captured in full", p)
+	}
+	if _, p := reservationPayment(t, ps, upcoming.ReservationID); p.Status != model.PaymentVoided || p.DueAt != nil {
+		t.Errorf("canceled reservation payment = %+v, want voided", p)
+	}
+
+	// Deleting a reservation that was charged refunds it
+	if err := ps.removeReservation(ctx, started.ReservationID); err != nil {
+		t.Fatalf("removeReservation: %v", err)
+	}
+	if n, err := ps.SettlePayments(ctx); err != nil || n != 1 {
+		t.Fatalf("SettlePayments = %d, %v, want 1 settled", n, err)
+	}
+	p, err := ps.Store.GetReservationPayment(ctx, started.ReservationID)
+	if err != nil {
+		t.Fatalf("GetReservationPayment: %v", err)
+	}
+	if p.Status != model.PaymentRefunded || p.Refunded != 1250 {
+		t.Errorf("deleted reservation payment = %+v, want refunded in full", p)
+	}
+
+	if n, err := ps.SettlePayments(ctx); err != nil || n != 0 {
+		t.Errorf("SettlePayments = %d, %v, want nothing left", n, err)
+	}
+}
+
+func TestEditPrice(t *testing.T) {
+	ps := newPaymentServer()
+	ctx := context.Background()
+
+	reservation, err := book(t, ps, "s1", "pm_card_visa", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+
+	higher, lower := 20.0, 10.0
+	if err := ps.updateReservation(ctx, editInput{ReservationID: reservation.ReservationID, PricePaid: &higher}); !hasCode(err, problem.CodeBadRequest) {
+		t.Errorf("raising the price over the authorization: got %v, want BAD_REQUEST", err)
+	}
+	if err := ps.updateReservation(ctx, editInput{ReservationID: reservation.ReservationID, PricePaid: &lower}); err != nil {
+		t.Fatalf("lowering the price: %v", err)
+	}
+
+	started, err := book(t, ps, "s2", "pm_card_visa", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("createReservation: %v", err)
+	}
+	if _, err := ps.SettlePayments(ctx); err != nil {
+		t.Fatalf("SettlePayments: %v", err)
+	}
+	if err := ps.updateReservation(ctx, editInput{ReservationID: started.ReservationID, PricePaid: &lower}); !hasCode(err, problem.CodeBadRequest) {
+		t.Errorf("changing the price of a captured reservation: got %v, want BAD_REQUEST", err)
+	}
+}
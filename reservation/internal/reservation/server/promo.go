@@ -0,0 +1,425 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The reservation service doesn't keep the spots, the caller resolves the lot and type of the
+// spot for the restrictions of the code. A redemption counts unless the booking or its payment fails.
+
+type promoCodeInput struct {
+	Code           string              `json:"code"`
+	Description    string              `json:"description"`
+	Type           m.DiscountType      `json:"type"`
+	Value          float64             `json:"value"`
+	ValidFrom      *time.Time          `json:"valid_from"`
+	ValidUntil     *time.Time          `json:"valid_until"`
+	MaxRedemptions int                 `json:"max_redemptions"`
+	MaxPerUser     int                 `json:"max_per_user"`
+	Restrictions   m.PromoRestrictions `json:"restrictions"`
+}
+
+// promoQuoteInput is the booking the code is applied to, Price is its list price
+type promoQuoteInput struct {
+	Code      string    `json:"code" validate:"required"`
+	UserID    string    `json:"user_id" validate:"required"`
+	LotID     string    `json:"lot_id"`
+	SpotType  string    `json:"spot_type"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+	Price     float64   `json:"price" validate:"gt=0"`
+}
+
+type promoQuote struct {
+	Code      string  `json:"code"`
+	ListPrice float64 `json:"list_price"`
+	Discount  float64 `json:"discount"`
+	Price     float64 `json:"price"`
+}
+
+func (s *Server) addPromoCode(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Adding promo code")
+	var input promoCodeInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	promo, err := s.createPromoCode(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, promo, http.StatusCreated)
+}
+
+func (s *Server) getPromoCodes(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting promo codes")
+
+	codes, err := s.findPromoCodes(r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, codes, http.StatusOK)
+}
+
+func (s *Server) getPromoCode(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting promo code")
+
+	promo, err := s.findPromoCode(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, promo, http.StatusOK)
+}
+
+func (s *Server) deletePromoCode(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting promo code")
+
+	if err := s.removePromoCode(r.Context(), mux.Vars(r)["code"]); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) quotePromo(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Quoting promo code")
+	var input promoQuoteInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	quote, err := s.quotePromoCode(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, quote, http.StatusOK)
+}
+
+func (s *Server) createPromoCode(ctx context.Context, input promoCodeInput) (m.PromoCode, error) {
+	promo := m.PromoCode{
+		Code:           m.NormalizeCode(input.Code),
+		Description:    input.Description,
+		Type:           input.Type,
+		Value:          input.Value,
+		ValidFrom:      input.ValidFrom,
+		ValidUntil:     input.ValidUntil,
+		MaxRedemptions: input.MaxRedemptions,
+		MaxPerUser:     input.MaxPerUser,
+		Restrictions:   input.Restrictions,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if err := s.Validator.Struct(promo); err != nil {
+		return promo, problem.Invalid(err)
+	}
+	if promo.Type == m.DiscountPercent && promo.Value >= 100 {
+		return promo, problem.NewError(problem.CodeBadRequest, "A percent discount must be below 100", nil)
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && !promo.ValidUntil.After(*promo.ValidFrom) {
+		return promo, problem.NewError(problem.CodeBadRequest, "valid_until must be after valid_from", nil)
+	}
+
+	if err := s.Store.AddPromoCode(ctx, promo); err != nil {
+		if errors.Is(err, m.ErrDuplicate) {
+			return promo, problem.NewError(problem.CodePromoCodeAlreadyExists, "Promo code "+promo.Code+" already exists", err)
+		}
+		return promo, problem.NewError(problem.CodeInternal, "Failed to add promo code", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Promo code added", "code", promo.Code)
+	return promo, nil
+}
+
+func (s *Server) findPromoCode(ctx context.Context, code string) (m.PromoCode, error) {
+	promo, err := s.Store.GetPromoCode(ctx, m.NormalizeCode(code))
+	if err != nil {
+		if errors.Is(err, m.ErrNotFound) {
+			return promo, problem.NewError(problem.CodePromoCodeNotFound, "Promo code not found", err)
+		}
+		return promo, problem.NewError(problem.CodeInternal, "Failed to get promo code", err)
+	}
+
+	return promo, nil
+}
+
+func (s *Server) findPromoCodes(ctx context.Context) ([]m.PromoCode, error) {
+	codes, err := s.Store.GetPromoCodes(ctx)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to get promo codes", err)
+	}
+	if codes == nil {
+		codes = []m.PromoCode{}
+	}
+
+	return codes, nil
+}
+
+func (s *Server) removePromoCode(ctx context.Context, code string) error {
+	if err := s.Store.DeletePromoCode(ctx, m.NormalizeCode(code)); err != nil {
+		if errors.Is(err, m.ErrNotFound) {
+			return problem.NewError(problem.CodePromoCodeNotFound, "Promo code not found", err)
+		}
+		return problem.NewError(problem.CodeInternal, "Failed to delete promo code", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Promo code deleted", "code", m.NormalizeCode(code))
+	return nil
+}
+
+// quotePromoCode prices the booking with the code, nothing is redeemed
+func (s *Server) quotePromoCode(ctx context.Context, input promoQuoteInput) (promoQuote, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return promoQuote{}, problem.Invalid(err)
+	}
+
+	discount, err := s.applyPromoCode(ctx, input)
+	if err != nil {
+		return promoQuote{}, err
+	}
+
+	return promoQuote{
+		Code:      discount.Code,
+		ListPrice: discount.ListPrice,
+		Discount:  discount.Amount,
+		Price:     discount.Price(),
+	}, nil
+}
+
+// applyPromoCode returns the discount the code gives on the booking. The limits are checked
+// here to explain the refusal, the redemption enforces them against concurrent bookings.
+func (s *Server) applyPromoCode(ctx context.Context, input promoQuoteInput) (m.Discount, error) {
+	promo, err := s.findPromoCode(ctx, input.Code)
+	if err != nil {
+		return m.Discount{}, err
+	}
+
+	amount, err := promo.DiscountFor(m.PromoTarget{
+		LotID:     input.LotID,
+		SpotType:  input.SpotType,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+		Price:     input.Price,
+	}, time.Now())
+	if err != nil {
+		return m.Discount{}, problem.NewError(problem.CodePromoCodeNotApplicable, "Promo code not applicable: "+err.Error(), nil)
+	}
+
+	if promo.MaxRedemptions > 0 && promo.Redemptions >= promo.MaxRedemptions {
+		return m.Discount{}, problem.NewError(problem.CodePromoCodeNotApplicable, "The promo code was used up", nil)
+	}
+	if promo.MaxPerUser > 0 {
+		count, err := s.Store.CountRedemptions(ctx, promo.Code, input.UserID)
+		if err != nil {
+			return m.Discount{}, problem.NewError(problem.CodeInternal, "Failed to count promo code redemptions", err)
+		}
+		if count >= promo.MaxPerUser {
+			return m.Discount{}, problem.NewError(problem.CodePromoCodeNotApplicable, "The user already redeemed the promo code", nil)
+		}
+	}
+	if promo.Restrictions.FirstBookingOnly {
+		booked, err := s.hasBooked(ctx, input.UserID)
+		if err != nil {
+			return m.Discount{}, err
+		}
+		if booked {
+			return m.Discount{}, problem.NewError(problem.CodePromoCodeNotApplicable, "The promo code applies to the first booking only", nil)
+		}
+	}
+
+	return m.Discount{Code: promo.Code, ListPrice: input.Price, Amount: amount}, nil
+}
+
+// Helper function to check whether the user has a valid or pending reservation
+func (s *Server) hasBooked(ctx context.Context, userID string) (bool, error) {
+	for _, status := range []m.StatusType{m.StatusValid, m.StatusPending} {
+		page, err := s.Store.GetAll(ctx, m.ReservationFilter{UserID: userID, Status: status}, m.ListOptions{Limit: 1})
+		if err != nil {
+			return false, problem.NewError(problem.CodeInternal, "Failed to get reservations", err)
+		}
+		if len(page.Items) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// redeemPromoCode records the redemption of the code of the reservation before it is booked
+func (s *Server) redeemPromoCode(ctx context.Context, reservation m.Reservation) error {
+	err := s.Store.RedeemPromoCode(ctx, m.PromoRedemption{
+		ReservationID: reservation.ReservationID,
+		Code:          reservation.Discount.Code,
+		UserID:        reservation.UserID,
+		Discount:      reservation.Discount.Amount,
+		RedeemedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, m.ErrLimitReached):
+			return problem.NewError(problem.CodePromoCodeNotApplicable, "The promo code reached its redemption limit", err)
+		case errors.Is(err, m.ErrNotFound):
+			return problem.NewError(problem.CodePromoCodeNotFound, "Promo code not found", err)
+		}
+		return problem.NewError(problem.CodeInternal, "Failed to redeem promo code", err)
+	}
+
+	metrics.PromoCodesRedeemed.WithLabelValues(reservation.Discount.Code).Inc()
+	return nil
+}
+
+// releasePromoCode gives the redemption of a booking that failed back, errors are only logged
+func (s *Server) releasePromoCode(ctx context.Context, reservationID string) {
+	if err := s.Store.ReleaseRedemption(ctx, reservationID); err != nil {
+		s.Logger.ErrorContext(ctx, "Failed to release promo code redemption", "reservation_id", reservationID, "error", err)
+	}
+}
+
+func (rpc *reservationRPC) AddPromoCode(ctx context.Context, req *reservationpb.PromoCode) (*reservationpb.PromoCode, error) {
+	input := promoCodeInput{
+		Code:           req.GetCode(),
+		Description:    req.GetDescription(),
+		Type:           m.DiscountType(req.GetType()),
+		Value:          req.GetValue(),
+		MaxRedemptions: int(req.GetMaxRedemptions()),
+		MaxPerUser:     int(req.GetMaxPerUser()),
+	}
+	if req.ValidFrom != nil {
+		validFrom := req.GetValidFrom().AsTime()
+		input.ValidFrom = &validFrom
+	}
+	if req.ValidUntil != nil {
+		validUntil := req.GetValidUntil().AsTime()
+		input.ValidUntil = &validUntil
+	}
+	if r := req.GetRestrictions(); r != nil {
+		input.Restrictions = m.PromoRestrictions{
+			LotIDs:             r.GetLotIds(),
+			SpotTypes:          r.GetSpotTypes(),
+			MinDurationMinutes: int(r.GetMinDurationMinutes()),
+			FirstBookingOnly:   r.GetFirstBookingOnly(),
+		}
+	}
+
+	promo, err := rpc.s.createPromoCode(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoCodeToProto(promo), nil
+}
+
+func (rpc *reservationRPC) ListPromoCodes(ctx context.Context, req *emptypb.Empty) (*reservationpb.ListPromoCodesResponse, error) {
+	codes, err := rpc.s.findPromoCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &reservationpb.ListPromoCodesResponse{}
+	for _, promo := range codes {
+		resp.Items = append(resp.Items, promoCodeToProto(promo))
+	}
+
+	return resp, nil
+}
+
+func (rpc *reservationRPC) GetPromoCode(ctx context.Context, req *reservationpb.PromoCodeRequest) (*reservationpb.PromoCode, error) {
+	promo, err := rpc.s.findPromoCode(ctx, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	return promoCodeToProto(promo), nil
+}
+
+func (rpc *reservationRPC) DeletePromoCode(ctx context.Context, req *reservationpb.PromoCodeRequest) (*emptypb.Empty, error) {
+	if err := rpc.s.removePromoCode(ctx, req.GetCode()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *reservationRPC) QuotePromoCode(ctx context.Context, req *reservationpb.QuotePromoCodeRequest) (*reservationpb.PromoQuote, error) {
+	quote, err := rpc.s.quotePromoCode(ctx, promoQuoteInput{
+		Code:      req.GetCode(),
+		UserID:    req.GetUserId(),
+		LotID:     req.GetLotId(),
+		SpotType:  req.GetSpotType(),
+		StartTime: fromTimestamp(req.GetStartTime()),
+		EndTime:   fromTimestamp(req.GetEndTime()),
+		Price:     req.GetPrice(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservationpb.PromoQuote{
+		Code:      quote.Code,
+		ListPrice: quote.ListPrice,
+		Discount:  quote.Discount,
+		Price:     quote.Price,
+	}, nil
+}
+
+func promoCodeToProto(promo m.PromoCode) *reservationpb.PromoCode {
+	resp := &reservationpb.PromoCode{
+		Code:           promo.Code,
+		Description:    promo.Description,
+		Type:           string(promo.Type),
+		Value:          promo.Value,
+		MaxRedemptions: int32(promo.MaxRedemptions),
+		MaxPerUser:     int32(promo.MaxPerUser),
+		Restrictions: &reservationpb.PromoRestrictions{
+			LotIds:             promo.Restrictions.LotIDs,
+			SpotTypes:          promo.Restrictions.SpotTypes,
+			MinDurationMinutes: int32(promo.Restrictions.MinDurationMinutes),
+			FirstBookingOnly:   promo.Restrictions.FirstBookingOnly,
+		},
+		Redemptions: int32(promo.Redemptions),
+		CreatedAt:   timestamppb.New(promo.CreatedAt),
+	}
+	if promo.ValidFrom != nil {
+		resp.ValidFrom = timestamppb.New(*promo.ValidFrom)
+	}
+	if promo.ValidUntil != nil {
+		resp.ValidUntil = timestamppb.New(*promo.ValidUntil)
+	}
+
+	return resp
+}
+
+func discountToProto(discount *m.Discount) *reservationpb.Discount {
+	if discount == nil {
+		return nil
+	}
+
+	return &reservationpb.Discount{
+		Code:      discount.Code,
+		ListPrice: discount.ListPrice,
+		Amount:    discount.Amount,
+	}
+}
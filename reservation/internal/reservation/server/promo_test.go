@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/config"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/payment"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/memory"
+)
+
+func bookWithCode(t *testing.T, s *Server, userID, spotID, code string, start time.Time) (model.Reservation, error) {
+	t.Helper()
+
+	return s.createReservation(context.Background(), addInput{
+		UserID:        userID,
+		SpotID:        spotID,
+		StartTime:     start,
+		EndTime:       start.Add(2 * time.Hour),
+		Status:        model.StatusValid,
+		PricePaid:     20,
+		PaymentMethod: "pm_card_visa",
+		PromoCode:     code,
+		LotID:         "lot-a",
+		SpotType:      "ev",
+	})
+}
+
+func TestCreatePromoCode(t *testing.T) {
+	ps := NewServer(logger.GetLogger(), config.GetConfig(), memory.New())
+	ctx := context.Background()
+
+	promo, err := ps.createPromoCode(ctx, promoCodeInput{Code: " spring25 ", Type: model.DiscountPercent, Value: 25})
+	if err != nil {
+		t.Fatalf("createPromoCode: %v", err)
+	}
+	if promo.Code != "SPRING25" || promo.CreatedAt.IsZero() {
+		t.Errorf("got %+v, want the code upper-cased with a creation time", promo)
+	}
+
+	from := time.Now()
+	until := from.Add(-time.Hour)
+	tests := []struct {
+		name  string
+		input promoCodeInput
+		code  problem.Code
+	}{
+		{"existing", promoCodeInput{Code: "Spring25", Type: model.DiscountFixed, Value: 5}, problem.CodePromoCodeAlreadyExists},
+		{"not alphanumeric", promoCodeInput{Code: "SPRING-25", Type: model.DiscountFixed, Value: 5}, problem.CodeValidationFailed},
+		{"unknown type", promoCodeInput{Code: "BOGO", Type: "bogo", Value: 5}, problem.CodeValidationFailed},
+		{"unknown spot type", promoCodeInput{Code: "EV", Type: model.DiscountFixed, Value: 5, Restrictions: model.PromoRestrictions{SpotTypes: []string{"garage"}}}, problem.CodeValidationFailed},
+		{"whole price", promoCodeInput{Code: "FREE", Type: model.DiscountPercent, Value: 100}, problem.CodeBadRequest},
+		{"empty window", promoCodeInput{Code: "PAST", Type: model.DiscountFixed, Value: 5, ValidFrom: &from, ValidUntil: &until}, problem.CodeBadRequest},
+	}
+
+	for _, tt := range tests {
+		if _, err := ps.createPromoCode(ctx, tt.input); !hasCode(err, tt.code) {
+			t.Errorf("%v: got %v, want %v", tt.name, err, tt.code)
+		}
+	}
+}
+
+func TestQuotePromoCode(t *testing.T) {
+	ps := NewServer(logger.GetLogger(), config.GetConfig(), memory.New())
+	ctx := context.Background()
+	start := time.Now().Add(time.Hour).UTC()
+
+	_, err := ps.createPromoCode(ctx, promoCodeInput{
+		Code: "EVLOT", Type: model.DiscountPercent, Value: 15,
+		Restrictions: model.PromoRestrictions{LotIDs: []string{"lot-a"}, SpotTypes: []string{"ev"}, MinDurationMinutes: 60},
+	})
+	if err != nil {
+		t.Fatalf("createPromoCode: %v", err)
+	}
+
+	input := promoQuoteInput{Code: "evlot", UserID: userID, LotID: "lot-a", SpotType: "ev", StartTime: start, EndTime: start.Add(2 * time.Hour), Price: 12.5}
+	quote, err := ps.quotePromoCode(ctx, input)
+	if err != nil {
+		t.Fatalf("quotePromoCode: %v", err)
+	}
+	if quote != (promoQuote{Code: "EVLOT", ListPrice: 12.5, Discount: 1.88, Price: 10.62}) {
+		t.Errorf("got quote %+v, want 1.88 off 12.5", quote)
+	}
+
+	outdoor, short, unknown := input, input, input
+	outdoor.SpotType = "outdoor"
+	short.EndTime = start.Add(30 * time.Minute)
+	unknown.Code = "WINTER"
+	for name, tt := range map[string]struct {
+		input promoQuoteInput
+		code  problem.Code
+	}{
+		"spot type": {outdoor, problem.CodePromoCodeNotApplicable},
+		"duration":  {short, problem.CodePromoCodeNotApplicable},
+		"unknown":   {unknown, problem.CodePromoCodeNotFound},
+	} {
+		if _, err := ps.quotePromoCode(ctx, tt.input); !hasCode(err, tt.code) {
+			t.Errorf("%v: got %v, want %v", name, err, tt.code)
+		}
+	}
+
+	body, _ := json.Marshal(input)
+	rr := httptest.NewRecorder()
+	ps.Handler().ServeHTTP(rr, httptest.NewRequest("POST", "/promo-codes/quote", bytes.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Errorf("POST /promo-codes/quote: got %d, want %d", rr.Code, http.StatusOK)
+	}
+}
+
+func TestBookWithPromoCode(t *testing.T) {
+	ps := newPaymentServer()
+	ctx := context.Background()
+	start := time.Now().Add(time.Hour).UTC()
+
+	_, err := ps.createPromoCode(ctx, promoCodeInput{
+		Code: "WELCOME", Type: model.DiscountFixed, Value: 5, MaxRedemptions: 2,
+		Restrictions: model.PromoRestrictions{FirstBookingOnly: true},
+	})
+	if err != nil {
+		t.Fatalf("createPromoCode: %v", err)
+	}
+
+	reservation, err := bookWithCode(t, ps, userID, "s1", "welcome", start)
+	if err != nil {
+		t.Fatalf("booking with a code: %v", err)
+	}
+	if reservation.PricePaid != 15 || reservation.Discount == nil || *reservation.Discount != (model.Discount{Code: "WELCOME", ListPrice: 20, Amount: 5}) {
+		t.Errorf("got price %v and discount %+v, want 15 after 5 off", reservation.PricePaid, reservation.Discount)
+	}
+	if p, err := ps.Store.GetReservationPayment(ctx, reservation.ReservationID); err != nil || p.Amount != 1500 {
+		t.Errorf("payment amount = %v, %v, want 1500 cents", p.Amount, err)
+	}
+
+	if _, err := bookWithCode(t, ps, userID, "s2", "WELCOME", start); !hasCode(err, problem.CodePromoCodeNotApplicable) {
+		t.Errorf("second booking: got %v, want PROMO_CODE_NOT_APPLICABLE", err)
+	}
+
+	// The spot is taken, the redemption is given back
+	if _, err := bookWithCode(t, ps, "other", "s1", "WELCOME", start); !hasCode(err, problem.CodeSpotUnavailable) {
+		t.Fatalf("booking a taken spot: got %v, want SPOT_UNAVAILABLE", err)
+	}
+	// So is the one of a declined payment
+	_, err = ps.createReservation(ctx, addInput{
+		UserID: "declined", SpotID: "s3", StartTime: start, EndTime: start.Add(time.Hour), Status: model.StatusValid,
+		PricePaid: 20, PaymentMethod: payment.FakeMethodDeclined, PromoCode: "WELCOME",
+	})
+	if !hasCode(err, problem.CodePaymentFailed) {
+		t.Fatalf("booking with a declined card: got %v, want PAYMENT_FAILED", err)
+	}
+	if promo, _ := ps.Store.GetPromoCode(ctx, "WELCOME"); promo.Redemptions != 1 {
+		t.Errorf("got %d redemptions after the failed bookings, want 1", promo.Redemptions)
+	}
+
+	if _, err := bookWithCode(t, ps, "second", "s2", "WELCOME", start); err != nil {
+		t.Fatalf("booking the last redemption: %v", err)
+	}
+	if _, err := bookWithCode(t, ps, "third", "s3", "WELCOME", start); !hasCode(err, problem.CodePromoCodeNotApplicable) {
+		t.Errorf("booking a used up code: got %v, want PROMO_CODE_NOT_APPLICABLE", err)
+	}
+}
+
+func TestBookWithQuotedDiscount(t *testing.T) {
+	ps := newPaymentServer()
+	ctx := context.Background()
+	start := time.Now().Add(time.Hour).UTC()
+
+	// The code no longer applies to the booking, but the discount was quoted before
+	_, err := ps.createPromoCode(ctx, promoCodeInput{
+		Code: "LOTB", Type: model.DiscountFixed, Value: 5, MaxRedemptions: 1,
+		Restrictions: model.PromoRestrictions{LotIDs: []string{"lot-b"}},
+	})
+	if err != nil {
+		t.Fatalf("createPromoCode: %v", err)
+	}
+
+	book := func(spotID string, discount float64) (model.Reservation, error) {
+		return ps.createReservation(ctx, addInput{
+			UserID: userID, SpotID: spotID, StartTime: start, EndTime: start.Add(time.Hour), Status: model.StatusValid,
+			PricePaid: 20, PaymentMethod: "pm_card_visa", PromoCode: "lotb", Discount: &discount,
+		})
+	}
+
+	if _, err := book("s1", 20); !hasCode(err, problem.CodeBadRequest) {
+		t.Errorf("discounting the whole price: got %v, want BAD_REQUEST", err)
+	}
+
+	reservation, err := book("s1", 3)
+	if err != nil {
+		t.Fatalf("booking with a quoted discount: %v", err)
+	}
+	if reservation.PricePaid != 17 || reservation.Discount == nil || *reservation.Discount != (model.Discount{Code: "LOTB", ListPrice: 20, Amount: 3}) {
+		t.Errorf("got price %v and discount %+v, want 17 after the quoted 3 off", reservation.PricePaid, reservation.Discount)
+	}
+
+	// The limits of the code still hold
+	if _, err := book("s2", 3); !hasCode(err, problem.CodePromoCodeNotApplicable) {
+		t.Errorf("booking a used up code: got %v, want PROMO_CODE_NOT_APPLICABLE", err)
+	}
+}
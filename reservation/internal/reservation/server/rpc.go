@@ -0,0 +1,346 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/pb/reservationpb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// reservationRPC serves the internal gRPC API on top of the same operations as the HTTP handlers
+type reservationRPC struct {
+	reservationpb.UnimplementedReservationServiceServer
+	s *Server
+}
+
+func (rpc *reservationRPC) AddReservation(ctx context.Context, req *reservationpb.AddReservationRequest) (*reservationpb.AddReservationResponse, error) {
+	reservation, err := rpc.s.createReservation(ctx, addInput{
+		UserID:       req.GetUserId(),
+		SpotID:       req.GetSpotId(),
+		StartTime:    fromTimestamp(req.GetStartTime()),
+		EndTime:      fromTimestamp(req.GetEndTime()),
+		Status:       m.StatusType(req.GetStatus()),
+		PricePaid:    req.GetPricePaid(),
+		VehicleID:    req.GetVehicleId(),
+		LicensePlate: req.GetLicensePlate(),
+
+		PaymentMethod: req.GetPaymentMethod(),
+
+		PromoCode: req.GetPromoCode(),
+		LotID:     req.GetLotId(),
+		SpotType:  req.GetSpotType(),
+		Discount:  req.Discount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservationpb.AddReservationResponse{ReservationId: reservation.ReservationID}, nil
+}
+
+func (rpc *reservationRPC) EditReservation(ctx context.Context, req *reservationpb.EditReservationRequest) (*emptypb.Empty, error) {
+	input := editInput{
+		ReservationID: req.GetReservationId(),
+		UserID:        req.GetUserId(),
+		SpotID:        req.GetSpotId(),
+		Status:        m.StatusType(req.GetStatus()),
+		PricePaid:     req.PricePaid,
+		VehicleID:     req.GetVehicleId(),
+		LicensePlate:  req.GetLicensePlate(),
+	}
+	if req.StartTime != nil {
+		startTime := req.GetStartTime().AsTime()
+		input.StartTime = &startTime
+	}
+	if req.EndTime != nil {
+		endTime := req.GetEndTime().AsTime()
+		input.EndTime = &endTime
+	}
+
+	if err := rpc.s.updateReservation(ctx, input); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *reservationRPC) DeleteReservation(ctx context.Context, req *reservationpb.DeleteReservationRequest) (*emptypb.Empty, error) {
+	if err := rpc.s.removeReservation(ctx, req.GetReservationId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *reservationRPC) GetReservation(ctx context.Context, req *reservationpb.GetReservationRequest) (*reservationpb.Reservation, error) {
+	reservation, err := rpc.s.findReservation(ctx, req.GetReservationId())
+	if err != nil {
+		return nil, err
+	}
+
+	return reservationToProto(reservation), nil
+}
+
+func (rpc *reservationRPC) ListReservations(ctx context.Context, req *reservationpb.ListReservationsRequest) (*reservationpb.ListReservationsResponse, error) {
+	o := req.GetOptions()
+	opts, err := newListOptions(o.GetLimit(), o.GetCursor(), o.GetSort(), o.GetOrder(), o.GetIncludeTotal(), reservationSortFields, "start_time")
+	if err != nil {
+		return nil, problem.NewError(problem.CodeBadRequest, err.Error(), err)
+	}
+
+	filter := m.ReservationFilter{
+		UserID: req.GetUserId(),
+		SpotID: req.GetSpotId(),
+		Status: m.StatusType(req.GetStatus()),
+	}
+	if req.From != nil {
+		from := req.GetFrom().AsTime()
+		filter.From = &from
+	}
+	if req.To != nil {
+		to := req.GetTo().AsTime()
+		filter.To = &to
+	}
+
+	page, err := rpc.s.findReservations(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &reservationpb.ListReservationsResponse{
+		NextCursor: page.NextCursor,
+		TotalCount: page.TotalCount,
+	}
+	for _, reservation := range page.Items {
+		resp.Items = append(resp.Items, reservationToProto(reservation))
+	}
+
+	return resp, nil
+}
+
+func (rpc *reservationRPC) CheckAvailability(ctx context.Context, req *reservationpb.CheckAvailabilityRequest) (*reservationpb.CheckAvailabilityResponse, error) {
+	spotIDs, err := rpc.s.availableSpots(ctx, m.AvailabilityInput{
+		SpotIDs:   req.GetSpotIds(),
+		StartTime: fromTimestamp(req.GetStartTime()),
+		EndTime:   fromTimestamp(req.GetEndTime()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservationpb.CheckAvailabilityResponse{SpotIds: spotIDs}, nil
+}
+
+func (rpc *reservationRPC) GetActiveReservation(ctx context.Context, req *reservationpb.GetActiveReservationRequest) (*reservationpb.Reservation, error) {
+	at := time.Now()
+	if req.At != nil {
+		at = req.GetAt().AsTime()
+	}
+
+	reservation, err := rpc.s.findActiveReservation(ctx, req.GetSpotId(), req.GetLicensePlate(), at)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservationToProto(reservation), nil
+}
+
+func (rpc *reservationRPC) AssignReservation(ctx context.Context, req *reservationpb.AssignReservationRequest) (*reservationpb.Reservation, error) {
+	input := assignInput{
+		UserID:       req.GetUserId(),
+		StartTime:    fromTimestamp(req.GetStartTime()),
+		EndTime:      fromTimestamp(req.GetEndTime()),
+		Strategy:     StrategyType(req.GetStrategy()),
+		VehicleID:    req.GetVehicleId(),
+		LicensePlate: req.GetLicensePlate(),
+
+		PaymentMethod: req.GetPaymentMethod(),
+	}
+	for _, candidate := range req.GetCandidates() {
+		input.Candidates = append(input.Candidates, assignCandidate{
+			SpotID:    candidate.GetSpotId(),
+			PricePaid: candidate.GetPricePaid(),
+		})
+	}
+
+	reservation, err := rpc.s.assignSpot(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservationToProto(reservation), nil
+}
+
+func (rpc *reservationRPC) GetTimeline(ctx context.Context, req *reservationpb.GetTimelineRequest) (*reservationpb.GetTimelineResponse, error) {
+	timelines, err := rpc.s.buildTimelines(ctx, timelineInput{
+		SpotIDs:     req.GetSpotIds(),
+		From:        fromTimestamp(req.GetFrom()),
+		To:          fromTimestamp(req.GetTo()),
+		Granularity: req.GetGranularity().AsDuration(),
+		MinFree:     req.GetMinFree().AsDuration(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &reservationpb.GetTimelineResponse{}
+	for _, timeline := range timelines {
+		resp.Timelines = append(resp.Timelines, &reservationpb.SpotTimeline{
+			SpotId: timeline.SpotID,
+			Busy:   intervalsToProto(timeline.Busy),
+			Free:   intervalsToProto(timeline.Free),
+		})
+	}
+
+	return resp, nil
+}
+
+func (rpc *reservationRPC) AddWebhook(ctx context.Context, req *reservationpb.AddWebhookRequest) (*reservationpb.Webhook, error) {
+	webhook, err := rpc.s.createWebhook(ctx, webhookInput{
+		URL:        req.GetUrl(),
+		EventTypes: req.GetEventTypes(),
+		Secret:     req.GetSecret(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookToProto(webhook), nil
+}
+
+func (rpc *reservationRPC) ListWebhooks(ctx context.Context, req *emptypb.Empty) (*reservationpb.ListWebhooksResponse, error) {
+	webhooks, err := rpc.s.findWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &reservationpb.ListWebhooksResponse{}
+	for _, webhook := range webhooks {
+		resp.Items = append(resp.Items, webhookToProto(webhook))
+	}
+
+	return resp, nil
+}
+
+func (rpc *reservationRPC) GetWebhook(ctx context.Context, req *reservationpb.GetWebhookRequest) (*reservationpb.Webhook, error) {
+	webhook, err := rpc.s.findWebhook(ctx, req.GetWebhookId())
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookToProto(webhook), nil
+}
+
+func (rpc *reservationRPC) DeleteWebhook(ctx context.Context, req *reservationpb.DeleteWebhookRequest) (*emptypb.Empty, error) {
+	if err := rpc.s.removeWebhook(ctx, req.GetWebhookId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *reservationRPC) ListDeliveries(ctx context.Context, req *reservationpb.ListDeliveriesRequest) (*reservationpb.ListDeliveriesResponse, error) {
+	deliveries, err := rpc.s.findDeliveries(ctx, deliveryFilter{
+		WebhookID: req.GetWebhookId(),
+		Status:    m.DeliveryStatus(req.GetStatus()),
+		Limit:     int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &reservationpb.ListDeliveriesResponse{}
+	for _, delivery := range deliveries {
+		resp.Items = append(resp.Items, deliveryToProto(delivery))
+	}
+
+	return resp, nil
+}
+
+func (rpc *reservationRPC) Redeliver(ctx context.Context, req *reservationpb.RedeliverRequest) (*reservationpb.Delivery, error) {
+	delivery, err := rpc.s.scheduleRedelivery(ctx, req.GetWebhookId(), req.GetDeliveryId())
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveryToProto(delivery), nil
+}
+
+func reservationToProto(reservation m.Reservation) *reservationpb.Reservation {
+	return &reservationpb.Reservation{
+		Id:            reservation.ID.Hex(),
+		ReservationId: reservation.ReservationID,
+		UserId:        reservation.UserID,
+		SpotId:        reservation.SpotID,
+		StartTime:     timestamppb.New(reservation.StartTime),
+		EndTime:       timestamppb.New(reservation.EndTime),
+		Status:        string(reservation.Status),
+		PricePaid:     reservation.PricePaid,
+		VehicleId:     reservation.VehicleID,
+		LicensePlate:  reservation.LicensePlate,
+		UpdatedAt:     timestamppb.New(reservation.UpdatedAt),
+		Cancellation:  cancellationToProto(reservation.Cancellation),
+		Discount:      discountToProto(reservation.Discount),
+	}
+}
+
+func webhookToProto(webhook m.Webhook) *reservationpb.Webhook {
+	return &reservationpb.Webhook{
+		WebhookId:  webhook.WebhookID,
+		Url:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  timestamppb.New(webhook.CreatedAt),
+	}
+}
+
+func deliveryToProto(delivery m.Delivery) *reservationpb.Delivery {
+	resp := &reservationpb.Delivery{
+		DeliveryId: delivery.DeliveryID,
+		WebhookId:  delivery.WebhookID,
+		Event: &reservationpb.Event{
+			Id:          delivery.Event.ID,
+			Type:        delivery.Event.Type,
+			Source:      delivery.Event.Source,
+			AggregateId: delivery.Event.AggregateID,
+			OccurredAt:  timestamppb.New(delivery.Event.OccurredAt),
+			Payload:     delivery.Event.Payload,
+		},
+		Status:        string(delivery.Status),
+		Failures:      int32(delivery.Failures),
+		NextAttemptAt: timestamppb.New(delivery.NextAttemptAt),
+		CreatedAt:     timestamppb.New(delivery.CreatedAt),
+		UpdatedAt:     timestamppb.New(delivery.UpdatedAt),
+	}
+	for _, attempt := range delivery.Attempts {
+		resp.Attempts = append(resp.Attempts, &reservationpb.DeliveryAttempt{
+			At:         timestamppb.New(attempt.At),
+			StatusCode: int32(attempt.StatusCode),
+			Error:      attempt.Error,
+			DurationMs: attempt.DurationMS,
+		})
+	}
+	return resp
+}
+
+func intervalsToProto(intervals []interval) []*reservationpb.Interval {
+	resp := make([]*reservationpb.Interval, 0, len(intervals))
+	for _, i := range intervals {
+		resp = append(resp, &reservationpb.Interval{
+			Start: timestamppb.New(i.Start),
+			End:   timestamppb.New(i.End),
+		})
+	}
+	return resp
+}
+
+// fromTimestamp keeps a missing timestamp as the zero time, so that it fails the required check
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
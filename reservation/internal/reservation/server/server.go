@@ -1,48 +1,175 @@
 package server
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"sync"
 
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/availability"
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/config"
 	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
-	"github.com/ciameksw/reserve-park/reservation/internal/reservation/mongodb"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/payment"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"google.golang.org/grpc/health"
 )
 
 type Server struct {
 	Logger    *logger.Logger
 	Config    *config.Config
-	MongoDB   *mongodb.MongoDB
+	Store     store.ReservationStore
 	Validator *validator.Validate
+
+	// Availability streams the availability changes, watching is refused while it's nil
+	Availability *availability.Hub
+
+	// Payments authorizes and captures the price of new reservations, they're booked unpaid while it's nil
+	Payments payment.Provider
+
+	// bookingMu makes the availability check and the write that follows it atomic
+	bookingMu sync.Mutex
+
+	// invoiceMu keeps a credit note from missing an invoice issued meanwhile
+	invoiceMu sync.Mutex
 }
 
-func NewServer(log *logger.Logger, cfg *config.Config, db *mongodb.MongoDB) *Server {
+func NewServer(log *logger.Logger, cfg *config.Config, st store.ReservationStore) *Server {
+	v := validator.New()
+	v.RegisterTagNameFunc(problem.JSONFieldName)
+
 	return &Server{
 		Logger:    log,
 		Config:    cfg,
-		MongoDB:   db,
-		Validator: validator.New(),
+		Store:     st,
+		Validator: v,
 	}
 }
 
-func (s *Server) Start() {
+// Handler returns the router with all routes and middlewares
+func (s *Server) Handler() http.Handler {
 	r := mux.NewRouter()
+	r.NotFoundHandler = logger.RequestIDMiddleware(http.HandlerFunc(s.notFound))
+	r.MethodNotAllowedHandler = logger.RequestIDMiddleware(http.HandlerFunc(s.methodNotAllowed))
+	r.Use(logger.RequestIDMiddleware, otelmux.Middleware("reservation"), metrics.Middleware)
+
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	r.HandleFunc("/healthz", s.healthz).Methods("GET")
+	r.HandleFunc("/readyz", s.readyz).Methods("GET")
+
+	r.HandleFunc("/reservations/active", s.getActiveReservation).Methods("GET")
+	r.HandleFunc("/reservations/timeline", s.getTimeline).Methods("GET")
+
+	r.HandleFunc("/reservations/assign", s.assignReservation).Methods("POST")
 
 	r.HandleFunc("/reservations", s.addReservation).Methods("POST")
 	r.HandleFunc("/reservations", s.editReservation).Methods("PATCH")
 	r.HandleFunc("/reservations/{id}", s.deleteReservation).Methods("DELETE")
 	r.HandleFunc("/reservations/{id}", s.getReservation).Methods("GET")
+	r.HandleFunc("/reservations/{id}/payment", s.getReservationPayment).Methods("GET")
+	r.HandleFunc("/reservations/{id}/cancellation/quote", s.quoteCancellation).Methods("POST")
+	r.HandleFunc("/reservations/{id}/cancellation", s.cancelReservation).Methods("POST")
+	r.HandleFunc("/reservations/{id}/invoice", s.getReservationInvoice).Methods("GET")
 	r.HandleFunc("/reservations", s.getAllReservations).Methods("GET")
 
 	r.HandleFunc("/reservations/user/{id}", s.getUserReservations).Methods("GET")
 	r.HandleFunc("/reservations/spot/{id}", s.getSpotReservations).Methods("GET")
-	r.HandleFunc("/reservations/availability/check", s.checkAvailability).Methods("GET")
+	r.HandleFunc("/reservations/availability/check", s.checkAvailability).Methods("GET", "POST")
+
+	r.HandleFunc("/payments/callback", s.paymentCallback).Methods("POST")
+
+	r.HandleFunc("/invoices", s.issueInvoice).Methods("POST")
+	r.HandleFunc("/invoices/monthly", s.issueMonthlyInvoice).Methods("POST")
+	r.HandleFunc("/invoices", s.getInvoices).Methods("GET")
+	r.HandleFunc("/invoices/{id}", s.getInvoice).Methods("GET")
+
+	r.HandleFunc("/promo-codes", s.addPromoCode).Methods("POST")
+	r.HandleFunc("/promo-codes", s.getPromoCodes).Methods("GET")
+	r.HandleFunc("/promo-codes/quote", s.quotePromo).Methods("POST")
+	r.HandleFunc("/promo-codes/{code}", s.getPromoCode).Methods("GET")
+	r.HandleFunc("/promo-codes/{code}", s.deletePromoCode).Methods("DELETE")
+
+	r.HandleFunc("/webhooks", s.addWebhook).Methods("POST")
+	r.HandleFunc("/webhooks", s.getWebhooks).Methods("GET")
+	r.HandleFunc("/webhooks/{id}", s.getWebhook).Methods("GET")
+	r.HandleFunc("/webhooks/{id}", s.deleteWebhook).Methods("DELETE")
+	r.HandleFunc("/webhooks/{id}/deliveries", s.getDeliveries).Methods("GET")
+	r.HandleFunc("/webhooks/{id}/deliveries/{deliveryId}/redeliver", s.redeliver).Methods("POST")
+
+	return r
+}
+
+// Start serves HTTP and gRPC requests until the context is canceled, then stops
+// accepting new connections and waits for in-flight requests to finish.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:         s.Config.ServerHost + ":" + s.Config.ServerPort,
+		Handler:      s.Handler(),
+		ReadTimeout:  s.Config.ReadTimeout,
+		WriteTimeout: s.Config.WriteTimeout,
+		IdleTimeout:  s.Config.IdleTimeout,
+	}
 
-	addr := s.Config.ServerHost + ":" + s.Config.ServerPort
-	s.Logger.Info.Printf("Server started at %s\n", addr)
-	err := http.ListenAndServe(addr, r)
+	lis, err := net.Listen("tcp", s.Config.ServerHost+":"+s.Config.GRPCPort)
 	if err != nil {
-		s.Logger.Error.Fatalf("Failed to start server: %v", err)
+		return err
+	}
+	healthServer := health.NewServer()
+	grpcSrv := s.GRPCServer(healthServer)
+
+	errCh := make(chan error, 2)
+	go func() {
+		s.Logger.Info("Server started", "addr", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	go func() {
+		s.Logger.Info("gRPC server started", "addr", lis.Addr().String())
+		errCh <- grpcSrv.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		grpcSrv.Stop()
+		srv.Close()
+		return err
+	case <-ctx.Done():
 	}
+
+	s.Logger.Info("Shutting down server")
+	healthServer.Shutdown()
+
+	// Watches last until the caller leaves, ending them lets the callers move to another instance
+	if s.Availability != nil {
+		s.Availability.Close()
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.Config.ShutdownTimeout)
+	defer cancel()
+
+	// In-flight calls get the same deadline as HTTP requests, then they are cut off
+	stopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+
+	err = srv.Shutdown(shutdownCtx)
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+
+	return err
+}
+
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	s.handleError(w, r, problem.CodeNotFound, "No route matches "+r.URL.Path, nil)
+}
+
+func (s *Server) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	s.handleError(w, r, problem.CodeMethodNotAllowed, "Method "+r.Method+" is not allowed on "+r.URL.Path, nil)
 }
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+)
+
+// Longest range a single timeline request may cover
+const maxTimelineRange = 31 * 24 * time.Hour
+
+type interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type spotTimeline struct {
+	SpotID string     `json:"spot_id"`
+	Busy   []interval `json:"busy"`
+	Free   []interval `json:"free"`
+}
+
+type timelineInput struct {
+	SpotIDs     []string
+	From        time.Time
+	To          time.Time
+	Granularity time.Duration
+	MinFree     time.Duration
+}
+
+func (s *Server) getTimeline(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting occupancy timeline")
+	query := r.URL.Query()
+
+	input := timelineInput{SpotIDs: parseSpotIDs(query.Get("spot_ids"))}
+
+	var err error
+	input.From, err = time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		s.handleError(w, r, problem.CodeBadRequest, "Invalid from query parameter, expected RFC3339", err)
+		return
+	}
+	input.To, err = time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		s.handleError(w, r, problem.CodeBadRequest, "Invalid to query parameter, expected RFC3339", err)
+		return
+	}
+
+	if raw := query.Get("granularity"); raw != "" {
+		input.Granularity, err = time.ParseDuration(raw)
+		if err != nil || input.Granularity <= 0 {
+			s.handleError(w, r, problem.CodeBadRequest, "Invalid granularity query parameter, expected a positive duration", err)
+			return
+		}
+	}
+
+	if raw := query.Get("min_free"); raw != "" {
+		input.MinFree, err = time.ParseDuration(raw)
+		if err != nil || input.MinFree < 0 {
+			s.handleError(w, r, problem.CodeBadRequest, "Invalid min_free query parameter, expected a duration", err)
+			return
+		}
+	}
+
+	timelines, err := s.buildTimelines(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, timelines, http.StatusOK)
+}
+
+// buildTimelines returns one timeline per requested spot, a zero granularity
+// falls back to the configured slot granularity
+func (s *Server) buildTimelines(ctx context.Context, input timelineInput) ([]spotTimeline, error) {
+	if len(input.SpotIDs) == 0 {
+		return nil, problem.NewError(problem.CodeBadRequest, "spot_ids are required", nil)
+	}
+	if !input.To.After(input.From) || input.To.Sub(input.From) > maxTimelineRange {
+		return nil, problem.NewError(problem.CodeBadRequest, "to must be after from and the range can't exceed 31 days", nil)
+	}
+	if input.Granularity < 0 || input.MinFree < 0 {
+		return nil, problem.NewError(problem.CodeBadRequest, "granularity and min_free can't be negative", nil)
+	}
+
+	granularity := input.Granularity
+	if granularity == 0 {
+		granularity = s.Config.SlotGranularity
+	}
+
+	reservations, err := s.Store.GetReservationsInWindow(ctx, input.SpotIDs, input.From, input.To)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to get reservations", err)
+	}
+
+	bySpot := make(map[string][]m.Reservation)
+	for _, res := range reservations {
+		bySpot[res.SpotID] = append(bySpot[res.SpotID], res)
+	}
+
+	timelines := make([]spotTimeline, 0, len(input.SpotIDs))
+	for _, spotID := range input.SpotIDs {
+		timelines = append(timelines, buildTimeline(spotID, bySpot[spotID], input.From, input.To, granularity, input.MinFree))
+	}
+
+	s.Logger.InfoContext(ctx, "Timelines built", "count", len(timelines))
+	return timelines, nil
+}
+
+// buildTimeline merges the reservations into busy intervals snapped outwards to the
+// granularity and returns the free gaps between them that last at least minFree.
+func buildTimeline(spotID string, reservations []m.Reservation, from, to time.Time, granularity, minFree time.Duration) spotTimeline {
+	timeline := spotTimeline{
+		SpotID: spotID,
+		Busy:   []interval{},
+		Free:   []interval{},
+	}
+
+	windowStart := ceilTime(from, granularity)
+	windowEnd := to.Truncate(granularity)
+	if !windowEnd.After(windowStart) {
+		return timeline
+	}
+
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.Before(reservations[j].StartTime)
+	})
+
+	for _, res := range reservations {
+		start := res.StartTime.Truncate(granularity)
+		end := ceilTime(res.EndTime, granularity)
+		if start.Before(windowStart) {
+			start = windowStart
+		}
+		if end.After(windowEnd) {
+			end = windowEnd
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		last := len(timeline.Busy) - 1
+		if last >= 0 && !start.After(timeline.Busy[last].End) {
+			if end.After(timeline.Busy[last].End) {
+				timeline.Busy[last].End = end
+			}
+			continue
+		}
+		timeline.Busy = append(timeline.Busy, interval{Start: start, End: end})
+	}
+
+	cursor := windowStart
+	for _, busy := range timeline.Busy {
+		if busy.Start.Sub(cursor) > 0 && busy.Start.Sub(cursor) >= minFree {
+			timeline.Free = append(timeline.Free, interval{Start: cursor, End: busy.Start})
+		}
+		cursor = busy.End
+	}
+	if windowEnd.Sub(cursor) > 0 && windowEnd.Sub(cursor) >= minFree {
+		timeline.Free = append(timeline.Free, interval{Start: cursor, End: windowEnd})
+	}
+
+	return timeline
+}
+
+// ceilTime rounds t up to a multiple of d
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	truncated := t.Truncate(d)
+	if truncated.Equal(t) {
+		return t
+	}
+	return truncated.Add(d)
+}
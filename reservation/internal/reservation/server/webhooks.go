@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	m "github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type webhookInput struct {
+	URL        string   `json:"url" validate:"required,http_url"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=ReservationCreated ReservationUpdated ReservationConfirmed ReservationCanceled ReservationDeleted SpotCreated SpotUpdated SpotDeleted SpotPriceChanged"`
+	Secret     string   `json:"secret" validate:"required,min=16"`
+}
+
+// deliveryFilter selects the deliveries of one webhook, newest first
+type deliveryFilter struct {
+	WebhookID string           `json:"webhook_id" validate:"required"`
+	Status    m.DeliveryStatus `json:"status" validate:"omitempty,oneof=pending succeeded dead"`
+	Limit     int              `json:"limit" validate:"gte=0,lte=500"`
+}
+
+func (s *Server) addWebhook(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Adding webhook")
+	var input webhookInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	webhook, err := s.createWebhook(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, webhook, http.StatusCreated)
+}
+
+func (s *Server) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting webhooks")
+
+	webhooks, err := s.findWebhooks(r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, webhooks, http.StatusOK)
+}
+
+func (s *Server) getWebhook(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting webhook")
+
+	webhook, err := s.findWebhook(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, webhook, http.StatusOK)
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting webhook")
+
+	if err := s.removeWebhook(r.Context(), mux.Vars(r)["id"]); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getDeliveries(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting webhook deliveries")
+	query := r.URL.Query()
+
+	filter := deliveryFilter{
+		WebhookID: mux.Vars(r)["id"],
+		Status:    m.DeliveryStatus(query.Get("status")),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			s.handleError(w, r, problem.CodeBadRequest, "Invalid limit query parameter", err)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	deliveries, err := s.findDeliveries(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, deliveries, http.StatusOK)
+}
+
+func (s *Server) redeliver(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Redelivering webhook delivery")
+	vars := mux.Vars(r)
+
+	delivery, err := s.scheduleRedelivery(r.Context(), vars["id"], vars["deliveryId"])
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, delivery, http.StatusAccepted)
+}
+
+func (s *Server) createWebhook(ctx context.Context, input webhookInput) (m.Webhook, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return m.Webhook{}, problem.Invalid(err)
+	}
+
+	webhook := m.Webhook{
+		WebhookID:  uuid.NewString(),
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		Secret:     input.Secret,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err := s.Store.AddWebhook(ctx, webhook); err != nil {
+		return webhook, problem.NewError(problem.CodeInternal, "Failed to add webhook", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Webhook added", "webhook_id", webhook.WebhookID, "event_types", webhook.EventTypes)
+	return webhook, nil
+}
+
+func (s *Server) findWebhooks(ctx context.Context) ([]m.Webhook, error) {
+	webhooks, err := s.Store.GetWebhooks(ctx)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to get webhooks", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Webhooks found", "count", len(webhooks))
+	return webhooks, nil
+}
+
+func (s *Server) findWebhook(ctx context.Context, webhookID string) (m.Webhook, error) {
+	webhook, err := s.Store.GetWebhook(ctx, webhookID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return webhook, problem.NewError(problem.CodeWebhookNotFound, "Webhook not found", err)
+		}
+
+		return webhook, problem.NewError(problem.CodeInternal, "Failed to get webhook", err)
+	}
+
+	return webhook, nil
+}
+
+// removeWebhook deletes the subscription, its pending deliveries are dead lettered when they come up
+func (s *Server) removeWebhook(ctx context.Context, webhookID string) error {
+	if err := s.Store.DeleteWebhook(ctx, webhookID); err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeWebhookNotFound, "Webhook not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to delete webhook", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Webhook deleted", "webhook_id", webhookID)
+	return nil
+}
+
+func (s *Server) findDeliveries(ctx context.Context, filter deliveryFilter) ([]m.Delivery, error) {
+	if err := s.Validator.Struct(filter); err != nil {
+		return nil, problem.Invalid(err)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = m.DefaultLimit
+	}
+
+	if _, err := s.findWebhook(ctx, filter.WebhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.Store.GetDeliveries(ctx, m.DeliveryFilter{WebhookID: filter.WebhookID, Status: filter.Status}, filter.Limit)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to get webhook deliveries", err)
+	}
+	if deliveries == nil {
+		deliveries = []m.Delivery{}
+	}
+
+	s.Logger.InfoContext(ctx, "Webhook deliveries found", "webhook_id", filter.WebhookID, "count", len(deliveries))
+	return deliveries, nil
+}
+
+// scheduleRedelivery makes the delivery due right away with a fresh retry budget,
+// whatever its status. The attempts log is kept.
+func (s *Server) scheduleRedelivery(ctx context.Context, webhookID, deliveryID string) (m.Delivery, error) {
+	if _, err := s.findWebhook(ctx, webhookID); err != nil {
+		return m.Delivery{}, err
+	}
+
+	delivery, err := s.Store.GetDelivery(ctx, deliveryID)
+	if err == m.ErrNotFound || (err == nil && delivery.WebhookID != webhookID) {
+		return delivery, problem.NewError(problem.CodeDeliveryNotFound, "Webhook delivery not found", err)
+	}
+	if err != nil {
+		return delivery, problem.NewError(problem.CodeInternal, "Failed to get webhook delivery", err)
+	}
+
+	now := time.Now().UTC()
+	delivery.Status = m.DeliveryPending
+	delivery.Failures = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+
+	if err := s.Store.UpdateDelivery(ctx, delivery); err != nil {
+		if err == m.ErrNotFound {
+			return delivery, problem.NewError(problem.CodeDeliveryNotFound, "Webhook delivery not found", err)
+		}
+
+		return delivery, problem.NewError(problem.CodeInternal, "Failed to redeliver webhook delivery", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Webhook delivery scheduled again", "webhook_id", webhookID, "delivery_id", deliveryID)
+	return delivery, nil
+}
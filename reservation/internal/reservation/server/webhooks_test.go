@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/problem"
+)
+
+func serve(t *testing.T, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, err := http.NewRequest(method, target, &buf)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAddWebhookValidation(t *testing.T) {
+	rr := serve(t, "POST", "/webhooks", webhookInput{
+		URL:        "ftp://example.com",
+		EventTypes: []string{"ReservationExploded"},
+		Secret:     "short",
+	})
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+
+	var p problem.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, fe := range p.Errors {
+		fields[fe.Field] = true
+	}
+	for _, field := range []string{"url", "event_types[0]", "secret"} {
+		if !fields[field] {
+			t.Errorf("problem errors %+v don't mention %s", p.Errors, field)
+		}
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	rr := serve(t, "POST", "/webhooks", webhookInput{
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{model.EventReservationCreated},
+		Secret:     "0123456789abcdef",
+	})
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+	if strings.Contains(rr.Body.String(), "0123456789abcdef") {
+		t.Errorf("response %s leaks the secret", rr.Body.String())
+	}
+
+	var webhook model.Webhook
+	if err := json.NewDecoder(rr.Body).Decode(&webhook); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	event, err := model.NewEvent(model.EventReservationCreated, "r1", map[string]string{"reservation_id": "r1"})
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	delivery := model.NewDelivery(webhook.WebhookID, event, time.Now().UTC())
+	delivery.Status = model.DeliveryDead
+	delivery.Failures = 8
+	if err := s.Store.AddDeliveries(context.Background(), []model.Delivery{delivery}); err != nil {
+		t.Fatalf("AddDeliveries: %v", err)
+	}
+
+	rr = serve(t, "GET", "/webhooks/"+webhook.WebhookID+"/deliveries?status=dead", nil)
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var deliveries []model.Delivery
+	if err := json.NewDecoder(rr.Body).Decode(&deliveries); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].DeliveryID != delivery.DeliveryID {
+		t.Fatalf("got deliveries %+v, want the dead one", deliveries)
+	}
+
+	rr = serve(t, "POST", "/webhooks/"+webhook.WebhookID+"/deliveries/"+delivery.DeliveryID+"/redeliver", nil)
+	if status := rr.Code; status != http.StatusAccepted {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusAccepted)
+	}
+	var redelivered model.Delivery
+	if err := json.NewDecoder(rr.Body).Decode(&redelivered); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if redelivered.Status != model.DeliveryPending || redelivered.Failures != 0 {
+		t.Errorf("redelivered = %+v, want pending with no failures", redelivered)
+	}
+
+	rr = serve(t, "POST", "/webhooks/"+webhook.WebhookID+"/deliveries/does-not-exist/redeliver", nil)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+
+	rr = serve(t, "DELETE", "/webhooks/"+webhook.WebhookID, nil)
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
+	}
+
+	var p problem.Problem
+	rr = serve(t, "GET", "/webhooks/"+webhook.WebhookID+"/deliveries", nil)
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil || p.Code != problem.CodeWebhookNotFound {
+		t.Errorf("deliveries of a deleted webhook: got %v (%v), want %v", p.Code, err, problem.CodeWebhookNotFound)
+	}
+}
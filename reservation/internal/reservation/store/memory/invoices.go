@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+func (s *Store) AddInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, line := range invoice.Lines {
+		if _, err := s.reservationInvoice(line.ReservationID); err == nil && invoice.Kind != model.InvoiceCreditNote {
+			return invoice, model.ErrDuplicate
+		}
+	}
+
+	if s.invoiceSequences == nil {
+		s.invoiceSequences = make(map[string]int64)
+	}
+	s.invoiceSequences[invoice.TenantID]++
+	invoice.Sequence = s.invoiceSequences[invoice.TenantID]
+	invoice.Number = model.InvoiceNumber(invoice.TenantID, invoice.Sequence)
+
+	s.invoices = append(s.invoices, cloneInvoice(invoice))
+	return invoice, nil
+}
+
+func (s *Store) GetInvoice(ctx context.Context, invoiceID string) (model.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, inv := range s.invoices {
+		if inv.InvoiceID == invoiceID {
+			return cloneInvoice(inv), nil
+		}
+	}
+	return model.Invoice{}, model.ErrNotFound
+}
+
+func (s *Store) GetReservationInvoice(ctx context.Context, reservationID string) (model.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.reservationInvoice(reservationID)
+}
+
+func (s *Store) GetCreditNotes(ctx context.Context, reservationID string) ([]model.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var notes []model.Invoice
+	for _, inv := range s.invoices {
+		if _, ok := inv.Line(reservationID); ok && inv.Kind == model.InvoiceCreditNote {
+			notes = append(notes, cloneInvoice(inv))
+		}
+	}
+	return notes, nil
+}
+
+func (s *Store) GetInvoices(ctx context.Context, filter model.InvoiceFilter, limit int) ([]model.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var invoices []model.Invoice
+	for _, inv := range s.invoices {
+		if filter.TenantID != "" && inv.TenantID != filter.TenantID {
+			continue
+		}
+		if filter.UserID != "" && inv.UserID != filter.UserID {
+			continue
+		}
+		if filter.Period != "" && inv.Period != filter.Period {
+			continue
+		}
+		invoices = append(invoices, cloneInvoice(inv))
+	}
+
+	sort.SliceStable(invoices, func(i, j int) bool {
+		if !invoices[i].IssuedAt.Equal(invoices[j].IssuedAt) {
+			return invoices[i].IssuedAt.After(invoices[j].IssuedAt)
+		}
+		return invoices[i].InvoiceID > invoices[j].InvoiceID
+	})
+	if len(invoices) > limit {
+		invoices = invoices[:limit]
+	}
+	return invoices, nil
+}
+
+// reservationInvoice expects the caller to hold the lock
+func (s *Store) reservationInvoice(reservationID string) (model.Invoice, error) {
+	for _, inv := range s.invoices {
+		if _, ok := inv.Line(reservationID); ok && inv.Kind != model.InvoiceCreditNote {
+			return cloneInvoice(inv), nil
+		}
+	}
+	return model.Invoice{}, model.ErrNotFound
+}
+
+// cloneInvoice copies the lines, so callers can't change the stored invoice
+func cloneInvoice(inv model.Invoice) model.Invoice {
+	inv.Lines = slices.Clone(inv.Lines)
+	inv.Taxes = slices.Clone(inv.Taxes)
+	return inv
+}
@@ -0,0 +1,207 @@
+// Package memory is an in-memory implementation of store.ReservationStore.
+// It is meant for tests and local experiments, data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ store.ReservationStore = (*Store)(nil)
+
+type Store struct {
+	mu           sync.RWMutex
+	reservations []model.Reservation
+	outbox       outbox
+	webhooks     []model.Webhook
+	deliveries   []model.Delivery
+	payments     []model.Payment
+	invoices     []model.Invoice
+	promoCodes   []model.PromoCode
+	redemptions  []model.PromoRedemption
+
+	// invoiceSequences holds the last invoice sequence number by tenant
+	invoiceSequences map[string]int64
+
+	// providerEvents holds the handled provider callbacks by provider and event ID
+	providerEvents map[string]struct{}
+}
+
+func New() *Store {
+	return &Store{}
+}
+
+func (s *Store) AddReservation(ctx context.Context, reservation model.Reservation, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reservation.ID.IsZero() {
+		reservation.ID = primitive.NewObjectID()
+	}
+	s.reservations = append(s.reservations, reservation)
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) EditReservation(ctx context.Context, input model.Reservation, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(input.ReservationID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	// Like a document replace, the stored ID survives the edit
+	if input.ID.IsZero() {
+		input.ID = s.reservations[i].ID
+	}
+	s.reservations[i] = input
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) DeleteReservation(ctx context.Context, reservationID string, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(reservationID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	s.reservations = append(s.reservations[:i], s.reservations[i+1:]...)
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) GetReservation(ctx context.Context, reservationID string) (model.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.index(reservationID)
+	if i < 0 {
+		return model.Reservation{}, model.ErrNotFound
+	}
+	return s.reservations[i], nil
+}
+
+func (s *Store) GetAll(ctx context.Context, input model.ReservationFilter, opts model.ListOptions) (model.Page[model.Reservation], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matching []model.Reservation
+	for _, r := range s.reservations {
+		if input.UserID != "" && r.UserID != input.UserID {
+			continue
+		}
+		if input.SpotID != "" && r.SpotID != input.SpotID {
+			continue
+		}
+		if input.Status != "" && r.Status != input.Status {
+			continue
+		}
+		if input.From != nil && !r.EndTime.After(*input.From) {
+			continue
+		}
+		if input.To != nil && !r.StartTime.Before(*input.To) {
+			continue
+		}
+		matching = append(matching, r)
+	}
+
+	return paginate(matching, func(r model.Reservation) primitive.ObjectID { return r.ID }, opts)
+}
+
+func (s *Store) GetActiveReservation(ctx context.Context, spotID, licensePlate string, at time.Time) (model.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	plate := model.NormalizePlate(licensePlate)
+	for _, r := range s.reservations {
+		if r.SpotID == spotID && r.LicensePlate == plate && r.Status == model.StatusValid &&
+			!r.StartTime.After(at) && r.EndTime.After(at) {
+			return r, nil
+		}
+	}
+	return model.Reservation{}, model.ErrNotFound
+}
+
+func (s *Store) GetReservationsInWindow(ctx context.Context, spotIDs []string, from, to time.Time) ([]model.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reservations := s.overlapping(spotIDs, from, to, "")
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.Before(reservations[j].StartTime)
+	})
+	return reservations, nil
+}
+
+func (s *Store) CheckAvailability(ctx context.Context, input model.AvailabilityInput) ([]string, error) {
+	return s.checkAvailability(input, "")
+}
+
+func (s *Store) CheckAvailabilityForEdit(ctx context.Context, input model.AvailabilityInput, editedReservationID string) ([]string, error) {
+	if len(input.SpotIDs) != 1 {
+		return nil, errors.New("edit mode requires exactly one spot ID")
+	}
+	return s.checkAvailability(input, editedReservationID)
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Store) checkAvailability(input model.AvailabilityInput, editedReservationID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	unavailableSpots := make(map[string]struct{})
+	for _, r := range s.overlapping(input.SpotIDs, input.StartTime, input.EndTime, editedReservationID) {
+		unavailableSpots[r.SpotID] = struct{}{}
+	}
+
+	var availableSpots []string
+	for _, spotID := range input.SpotIDs {
+		if _, found := unavailableSpots[spotID]; !found {
+			availableSpots = append(availableSpots, spotID)
+		}
+	}
+	return availableSpots, nil
+}
+
+// overlapping returns the valid reservations on the spots that overlap [from, to), except the given one
+func (s *Store) overlapping(spotIDs []string, from, to time.Time, exceptID string) []model.Reservation {
+	spots := make(map[string]struct{}, len(spotIDs))
+	for _, id := range spotIDs {
+		spots[id] = struct{}{}
+	}
+
+	var reservations []model.Reservation
+	for _, r := range s.reservations {
+		if _, ok := spots[r.SpotID]; !ok || r.Status == model.StatusCanceled || r.ReservationID == exceptID {
+			continue
+		}
+		if r.StartTime.Before(to) && r.EndTime.After(from) {
+			reservations = append(reservations, r)
+		}
+	}
+	return reservations
+}
+
+func (s *Store) index(reservationID string) int {
+	for i, r := range s.reservations {
+		if r.ReservationID == reservationID {
+			return i
+		}
+	}
+	return -1
+}
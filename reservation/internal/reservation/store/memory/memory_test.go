@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.ReservationStore {
+		return New()
+	})
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+func (s *Store) AddPayment(ctx context.Context, payment model.Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.payments = append(s.payments, clonePayment(payment))
+	return nil
+}
+
+func (s *Store) GetPayment(ctx context.Context, paymentID string) (model.Payment, error) {
+	return s.findPayment(func(p model.Payment) bool { return p.PaymentID == paymentID })
+}
+
+func (s *Store) GetReservationPayment(ctx context.Context, reservationID string) (model.Payment, error) {
+	return s.findPayment(func(p model.Payment) bool { return p.ReservationID == reservationID })
+}
+
+func (s *Store) GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (model.Payment, error) {
+	return s.findPayment(func(p model.Payment) bool { return p.Provider == provider && p.ProviderRef == providerRef })
+}
+
+func (s *Store) UpdatePayment(ctx context.Context, payment model.Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, p := range s.payments {
+		if p.PaymentID == payment.PaymentID {
+			s.payments[i] = clonePayment(payment)
+			return nil
+		}
+	}
+	return model.ErrNotFound
+}
+
+func (s *Store) ClaimPayments(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []int
+	for i, p := range s.payments {
+		if p.DueAt != nil && !p.DueAt.After(now) {
+			due = append(due, i)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return s.payments[due[i]].DueAt.Before(*s.payments[due[j]].DueAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]model.Payment, 0, len(due))
+	for _, i := range due {
+		leased := now.Add(lease)
+		s.payments[i].DueAt = &leased
+		claimed = append(claimed, clonePayment(s.payments[i]))
+	}
+	return claimed, nil
+}
+
+func (s *Store) ClaimProviderEvent(ctx context.Context, provider, eventID string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.providerEvents == nil {
+		s.providerEvents = make(map[string]struct{})
+	}
+	if _, ok := s.providerEvents[provider+"/"+eventID]; ok {
+		return false, nil
+	}
+	s.providerEvents[provider+"/"+eventID] = struct{}{}
+	return true, nil
+}
+
+func (s *Store) ReleaseProviderEvent(ctx context.Context, provider, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.providerEvents, provider+"/"+eventID)
+	return nil
+}
+
+func (s *Store) findPayment(match func(model.Payment) bool) (model.Payment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.payments {
+		if match(p) {
+			return clonePayment(p), nil
+		}
+	}
+	return model.Payment{}, model.ErrNotFound
+}
+
+// clonePayment copies the due time, so callers can't change the stored one
+func clonePayment(p model.Payment) model.Payment {
+	if p.DueAt != nil {
+		dueAt := *p.DueAt
+		p.DueAt = &dueAt
+	}
+	return p
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+func (s *Store) AddPromoCode(ctx context.Context, promo model.PromoCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.promoIndex(promo.Code) >= 0 {
+		return model.ErrDuplicate
+	}
+	s.promoCodes = append(s.promoCodes, clonePromoCode(promo))
+	return nil
+}
+
+func (s *Store) GetPromoCode(ctx context.Context, code string) (model.PromoCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.promoIndex(code)
+	if i < 0 {
+		return model.PromoCode{}, model.ErrNotFound
+	}
+	return clonePromoCode(s.promoCodes[i]), nil
+}
+
+func (s *Store) GetPromoCodes(ctx context.Context) ([]model.PromoCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	codes := make([]model.PromoCode, 0, len(s.promoCodes))
+	for _, p := range s.promoCodes {
+		codes = append(codes, clonePromoCode(p))
+	}
+
+	sort.SliceStable(codes, func(i, j int) bool {
+		if !codes[i].CreatedAt.Equal(codes[j].CreatedAt) {
+			return codes[i].CreatedAt.After(codes[j].CreatedAt)
+		}
+		return codes[i].Code < codes[j].Code
+	})
+	return codes, nil
+}
+
+func (s *Store) DeletePromoCode(ctx context.Context, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.promoIndex(code)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+	s.promoCodes = append(s.promoCodes[:i], s.promoCodes[i+1:]...)
+	return nil
+}
+
+func (s *Store) CountRedemptions(ctx context.Context, code, userID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.countRedemptions(code, userID), nil
+}
+
+func (s *Store) RedeemPromoCode(ctx context.Context, redemption model.PromoRedemption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.promoIndex(redemption.Code)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	promo := &s.promoCodes[i]
+	if promo.MaxRedemptions > 0 && promo.Redemptions >= promo.MaxRedemptions {
+		return model.ErrLimitReached
+	}
+	if promo.MaxPerUser > 0 && s.countRedemptions(redemption.Code, redemption.UserID) >= promo.MaxPerUser {
+		return model.ErrLimitReached
+	}
+	if slices.ContainsFunc(s.redemptions, func(r model.PromoRedemption) bool { return r.ReservationID == redemption.ReservationID }) {
+		return model.ErrDuplicate
+	}
+
+	promo.Redemptions++
+	s.redemptions = append(s.redemptions, redemption)
+	return nil
+}
+
+func (s *Store) ReleaseRedemption(ctx context.Context, reservationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.redemptions {
+		if r.ReservationID != reservationID {
+			continue
+		}
+
+		s.redemptions = append(s.redemptions[:i], s.redemptions[i+1:]...)
+		if j := s.promoIndex(r.Code); j >= 0 {
+			s.promoCodes[j].Redemptions--
+		}
+		return nil
+	}
+	return nil
+}
+
+// promoIndex expects the caller to hold the lock
+func (s *Store) promoIndex(code string) int {
+	return slices.IndexFunc(s.promoCodes, func(p model.PromoCode) bool { return p.Code == code })
+}
+
+// countRedemptions expects the caller to hold the lock
+func (s *Store) countRedemptions(code, userID string) int {
+	var count int
+	for _, r := range s.redemptions {
+		if r.Code == code && r.UserID == userID {
+			count++
+		}
+	}
+	return count
+}
+
+// clonePromoCode copies the restrictions, so callers can't change the stored code
+func clonePromoCode(p model.PromoCode) model.PromoCode {
+	p.Restrictions.LotIDs = slices.Clone(p.Restrictions.LotIDs)
+	p.Restrictions.SpotTypes = slices.Clone(p.Restrictions.SpotTypes)
+	return p
+}
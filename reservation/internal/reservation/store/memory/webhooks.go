@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+func (s *Store) AddWebhook(ctx context.Context, webhook model.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhooks = append(s.webhooks, webhook)
+	return nil
+}
+
+func (s *Store) GetWebhook(ctx context.Context, webhookID string) (model.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, w := range s.webhooks {
+		if w.WebhookID == webhookID {
+			return w, nil
+		}
+	}
+	return model.Webhook{}, model.ErrNotFound
+}
+
+func (s *Store) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhooks := make([]model.Webhook, len(s.webhooks))
+	copy(webhooks, s.webhooks)
+	return webhooks, nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, webhookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, w := range s.webhooks {
+		if w.WebhookID == webhookID {
+			s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return model.ErrNotFound
+}
+
+func (s *Store) AddDeliveries(ctx context.Context, deliveries []model.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range deliveries {
+		if s.deliveryIndex(d.DeliveryID) < 0 {
+			s.deliveries = append(s.deliveries, cloneDelivery(d))
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetDelivery(ctx context.Context, deliveryID string) (model.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.deliveryIndex(deliveryID)
+	if i < 0 {
+		return model.Delivery{}, model.ErrNotFound
+	}
+	return cloneDelivery(s.deliveries[i]), nil
+}
+
+func (s *Store) GetDeliveries(ctx context.Context, filter model.DeliveryFilter, limit int) ([]model.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deliveries []model.Delivery
+	for _, d := range s.deliveries {
+		if filter.WebhookID != "" && d.WebhookID != filter.WebhookID {
+			continue
+		}
+		if filter.Status != "" && d.Status != filter.Status {
+			continue
+		}
+		deliveries = append(deliveries, cloneDelivery(d))
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].DeliveryID > deliveries[j].DeliveryID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *Store) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []int
+	for i, d := range s.deliveries {
+		if d.Status == model.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return s.deliveries[due[i]].NextAttemptAt.Before(s.deliveries[due[j]].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]model.Delivery, 0, len(due))
+	for _, i := range due {
+		s.deliveries[i].NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, cloneDelivery(s.deliveries[i]))
+	}
+	return claimed, nil
+}
+
+func (s *Store) UpdateDelivery(ctx context.Context, delivery model.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.deliveryIndex(delivery.DeliveryID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+	s.deliveries[i] = cloneDelivery(delivery)
+	return nil
+}
+
+func (s *Store) deliveryIndex(deliveryID string) int {
+	for i, d := range s.deliveries {
+		if d.DeliveryID == deliveryID {
+			return i
+		}
+	}
+	return -1
+}
+
+// cloneDelivery copies the attempts log, so callers can't change the stored one
+func cloneDelivery(d model.Delivery) model.Delivery {
+	d.Attempts = append([]model.Attempt{}, d.Attempts...)
+	return d
+}
@@ -0,0 +1,154 @@
+// Package store defines the persistence contract of the reservation service.
+// The server depends only on this interface, so the backing database can be
+// swapped (MongoDB or PostgreSQL in production, an in-memory store in tests).
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+)
+
+// ReservationStore returns model.ErrNotFound when the requested reservation
+// doesn't exist and model.ErrInvalidCursor when a page cursor can't be decoded.
+// Stores that enforce non-overlapping reservations themselves (PostgreSQL)
+// reject a conflicting add or edit with model.ErrOverlap.
+//
+// The write methods take the events describing the change and add them to
+// the outbox atomically with it, see Outbox.
+type ReservationStore interface {
+	Outbox
+	WebhookStore
+	PaymentStore
+	InvoiceStore
+	PromoStore
+
+	AddReservation(ctx context.Context, reservation model.Reservation, events ...model.Event) error
+	EditReservation(ctx context.Context, reservation model.Reservation, events ...model.Event) error
+	DeleteReservation(ctx context.Context, reservationID string, events ...model.Event) error
+	GetReservation(ctx context.Context, reservationID string) (model.Reservation, error)
+	GetAll(ctx context.Context, filter model.ReservationFilter, opts model.ListOptions) (model.Page[model.Reservation], error)
+
+	// GetActiveReservation returns the valid reservation for the plate that covers the moment on the spot,
+	// a reservation still waiting for its payment doesn't count
+	GetActiveReservation(ctx context.Context, spotID, licensePlate string, at time.Time) (model.Reservation, error)
+
+	// GetReservationsInWindow returns the reservations that aren't canceled on the spots
+	// that overlap [from, to), sorted by start time
+	GetReservationsInWindow(ctx context.Context, spotIDs []string, from, to time.Time) ([]model.Reservation, error)
+
+	// CheckAvailability returns the spots from the input that have no reservation overlapping the
+	// timeframe, canceled reservations don't count while pending ones do
+	CheckAvailability(ctx context.Context, input model.AvailabilityInput) ([]string, error)
+
+	// CheckAvailabilityForEdit works like CheckAvailability for a single spot, ignoring the edited reservation
+	CheckAvailabilityForEdit(ctx context.Context, input model.AvailabilityInput, editedReservationID string) ([]string, error)
+
+	Ping(ctx context.Context) error
+}
+
+// Outbox holds the events written with the changes until the relay publishes them.
+// MongoDB deployments without transactions (standalone servers) write the events
+// right after the change instead, so a crash in between can lose them.
+type Outbox interface {
+	// PendingEvents returns up to limit unpublished events, oldest first
+	PendingEvents(ctx context.Context, limit int) ([]model.Event, error)
+
+	// MarkPublished flags the events so they aren't returned as pending again
+	MarkPublished(ctx context.Context, ids []string) error
+}
+
+// WebhookStore keeps the webhook subscriptions and the deliveries to them.
+// It returns model.ErrNotFound when the requested webhook or delivery doesn't exist.
+type WebhookStore interface {
+	AddWebhook(ctx context.Context, webhook model.Webhook) error
+	GetWebhook(ctx context.Context, webhookID string) (model.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+
+	// AddDeliveries skips the deliveries whose ID is already stored, so adding the same ones twice is harmless
+	AddDeliveries(ctx context.Context, deliveries []model.Delivery) error
+	GetDelivery(ctx context.Context, deliveryID string) (model.Delivery, error)
+
+	// GetDeliveries returns up to limit deliveries matching the filter, newest first
+	GetDeliveries(ctx context.Context, filter model.DeliveryFilter, limit int) ([]model.Delivery, error)
+
+	// ClaimDeliveries returns up to limit pending deliveries due at now, oldest first, and
+	// moves their next attempt to now+lease so other instances don't pick them up meanwhile
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error)
+
+	// UpdateDelivery replaces the stored delivery
+	UpdateDelivery(ctx context.Context, delivery model.Delivery) error
+}
+
+// PaymentStore keeps the payments of the reservations and the provider callbacks already handled.
+// It returns model.ErrNotFound when the requested payment doesn't exist.
+type PaymentStore interface {
+	AddPayment(ctx context.Context, payment model.Payment) error
+	GetPayment(ctx context.Context, paymentID string) (model.Payment, error)
+	GetReservationPayment(ctx context.Context, reservationID string) (model.Payment, error)
+	GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (model.Payment, error)
+
+	// UpdatePayment replaces the stored payment
+	UpdatePayment(ctx context.Context, payment model.Payment) error
+
+	// ClaimPayments returns up to limit payments due at now, oldest first, and moves
+	// their due time to now+lease so other instances don't pick them up meanwhile
+	ClaimPayments(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Payment, error)
+
+	// ClaimProviderEvent records the ID of a provider callback before it's handled and tells
+	// whether this call recorded it. The ID is a unique key, so of concurrent deliveries of one
+	// callback only one claims it.
+	ClaimProviderEvent(ctx context.Context, provider, eventID string, at time.Time) (bool, error)
+
+	// ReleaseProviderEvent forgets a claimed callback that couldn't be handled, so that the
+	// provider's next delivery is handled
+	ReleaseProviderEvent(ctx context.Context, provider, eventID string) error
+}
+
+// InvoiceStore keeps the invoices. It returns model.ErrNotFound when the requested invoice doesn't exist.
+type InvoiceStore interface {
+	// AddInvoice gives the invoice the next sequence number of its tenant and its number, and
+	// stores it. Numbers are gapless, so MongoDB deployments without transactions (standalone
+	// servers) refuse to issue invoices with an error wrapping errors.ErrUnsupported.
+	// A reservation is invoiced once, a line for an invoiced one fails with model.ErrDuplicate.
+	// Credit notes are numbered the same way, a reservation can be credited any number of times.
+	AddInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error)
+	GetInvoice(ctx context.Context, invoiceID string) (model.Invoice, error)
+
+	// GetReservationInvoice returns the invoice with a line for the reservation, not a credit note
+	GetReservationInvoice(ctx context.Context, reservationID string) (model.Invoice, error)
+
+	// GetCreditNotes returns the credit notes with a line for the reservation, oldest first
+	GetCreditNotes(ctx context.Context, reservationID string) ([]model.Invoice, error)
+
+	// GetInvoices returns up to limit invoices matching the filter, newest first
+	GetInvoices(ctx context.Context, filter model.InvoiceFilter, limit int) ([]model.Invoice, error)
+}
+
+// PromoStore keeps the promo codes and their redemptions. It returns model.ErrNotFound when
+// the requested code doesn't exist. Codes are passed normalized, see model.NormalizeCode.
+type PromoStore interface {
+	// AddPromoCode fails with model.ErrDuplicate when the code exists
+	AddPromoCode(ctx context.Context, promo model.PromoCode) error
+	GetPromoCode(ctx context.Context, code string) (model.PromoCode, error)
+
+	// GetPromoCodes returns every code, newest first
+	GetPromoCodes(ctx context.Context) ([]model.PromoCode, error)
+
+	// DeletePromoCode removes the code, the redemptions stay recorded on the reservations
+	DeletePromoCode(ctx context.Context, code string) error
+
+	// CountRedemptions returns how many times the user redeemed the code
+	CountRedemptions(ctx context.Context, code, userID string) (int, error)
+
+	// RedeemPromoCode records the redemption unless the code reached its MaxRedemptions or the
+	// user its MaxPerUser, which fails with model.ErrLimitReached. The check and the record are
+	// atomic, concurrent redemptions never exceed the limits. A reservation redeems once, redeeming
+	// for it again fails with model.ErrDuplicate.
+	RedeemPromoCode(ctx context.Context, redemption model.PromoRedemption) error
+
+	// ReleaseRedemption undoes the redemption for the reservation, releasing it twice is harmless
+	ReleaseRedemption(ctx context.Context, reservationID string) error
+}
@@ -0,0 +1,996 @@
+// Package storetest is a conformance suite that every store.ReservationStore
+// implementation must pass, so that they behave the same behind the server.
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+)
+
+// Run runs the suite, newStore must return an empty store for every call
+func Run(t *testing.T, newStore func(t *testing.T) store.ReservationStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, st store.ReservationStore)
+	}{
+		{"AddAndGet", testAddAndGet},
+		{"NotFound", testNotFound},
+		{"Edit", testEdit},
+		{"Delete", testDelete},
+		{"GetAllFilter", testGetAllFilter},
+		{"GetAllPagination", testGetAllPagination},
+		{"InvalidCursor", testInvalidCursor},
+		{"GetActiveReservation", testGetActiveReservation},
+		{"GetReservationsInWindow", testGetReservationsInWindow},
+		{"CheckAvailability", testCheckAvailability},
+		{"Outbox", testOutbox},
+		{"Webhooks", testWebhooks},
+		{"Deliveries", testDeliveries},
+		{"Payments", testPayments},
+		{"ProviderEvents", testProviderEvents},
+		{"Invoices", testInvoices},
+		{"PromoCodes", testPromoCodes},
+		{"PromoRedemptions", testPromoRedemptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+var base = time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+
+func at(hour int) time.Time {
+	return base.Add(time.Duration(hour) * time.Hour)
+}
+
+func reservation(id, userID, spotID string, start, end time.Time) model.Reservation {
+	return model.Reservation{
+		ReservationID: id,
+		UserID:        userID,
+		SpotID:        spotID,
+		StartTime:     start,
+		EndTime:       end,
+		Status:        model.StatusValid,
+		PricePaid:     10,
+		LicensePlate:  "WX1234A",
+		UpdatedAt:     base,
+	}
+}
+
+func mustAdd(t *testing.T, st store.ReservationStore, reservations ...model.Reservation) {
+	t.Helper()
+	for _, r := range reservations {
+		if err := st.AddReservation(context.Background(), r); err != nil {
+			t.Fatalf("AddReservation(%v): %v", r.ReservationID, err)
+		}
+	}
+}
+
+func ids(reservations []model.Reservation) []string {
+	resp := make([]string, 0, len(reservations))
+	for _, r := range reservations {
+		resp = append(resp, r.ReservationID)
+	}
+	return resp
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testAddAndGet(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+	mustAdd(t, st, reservation("r1", "u1", "s1", at(0), at(1)))
+
+	got, err := st.GetReservation(ctx, "r1")
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	if got.ID.IsZero() {
+		t.Errorf("stored reservation has no ID")
+	}
+	if got.UserID != "u1" || got.SpotID != "s1" || !got.StartTime.Equal(at(0)) || !got.EndTime.Equal(at(1)) {
+		t.Errorf("wrong reservation: got %+v", got)
+	}
+}
+
+func testNotFound(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	if _, err := st.GetReservation(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetReservation: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.EditReservation(ctx, reservation("missing", "u1", "s1", at(0), at(1))); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("EditReservation: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.DeleteReservation(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("DeleteReservation: got %v want %v", err, model.ErrNotFound)
+	}
+	if _, err := st.GetActiveReservation(ctx, "s1", "WX1234A", at(0)); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetActiveReservation: got %v want %v", err, model.ErrNotFound)
+	}
+}
+
+func testEdit(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+	mustAdd(t, st, reservation("r1", "u1", "s1", at(0), at(1)))
+
+	before, err := st.GetReservation(ctx, "r1")
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+
+	edited := before
+	edited.EndTime = at(2)
+	edited.Status = model.StatusCanceled
+	edited.Cancellation = &model.Cancellation{
+		CanceledAt: at(0).Add(-time.Hour),
+		Tier:       model.CancellationLateFee,
+		Fee:        2.5,
+		Refund:     7.5,
+		Policy:     model.CancellationPolicy{Scope: "lot", TargetID: "lot-a", FreeUntilHours: 24, LateFeePercent: 25},
+	}
+	if err := st.EditReservation(ctx, edited); err != nil {
+		t.Fatalf("EditReservation: %v", err)
+	}
+
+	got, err := st.GetReservation(ctx, "r1")
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	if got.ID != before.ID || !got.EndTime.Equal(at(2)) || got.Status != model.StatusCanceled {
+		t.Errorf("wrong reservation after edit: got %+v", got)
+	}
+	if c := got.Cancellation; c == nil || !c.CanceledAt.Equal(edited.Cancellation.CanceledAt) || c.Fee != 2.5 || c.Refund != 7.5 || c.Policy != edited.Cancellation.Policy {
+		t.Errorf("wrong cancellation after edit: got %+v", got.Cancellation)
+	}
+}
+
+func testDelete(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+	mustAdd(t, st, reservation("r1", "u1", "s1", at(0), at(1)), reservation("r2", "u1", "s1", at(1), at(2)))
+
+	if err := st.DeleteReservation(ctx, "r1"); err != nil {
+		t.Fatalf("DeleteReservation: %v", err)
+	}
+	if _, err := st.GetReservation(ctx, "r1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("deleted reservation still found: %v", err)
+	}
+	if _, err := st.GetReservation(ctx, "r2"); err != nil {
+		t.Errorf("other reservation is gone: %v", err)
+	}
+}
+
+func testGetAllFilter(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+	canceled := reservation("r3", "u2", "s1", at(2), at(3))
+	canceled.Status = model.StatusCanceled
+	mustAdd(t, st,
+		reservation("r1", "u1", "s1", at(0), at(1)),
+		reservation("r2", "u1", "s2", at(1), at(2)),
+		canceled,
+	)
+
+	from, to := at(1), at(3)
+	tests := []struct {
+		name   string
+		filter model.ReservationFilter
+		want   []string
+	}{
+		{"all", model.ReservationFilter{}, []string{"r1", "r2", "r3"}},
+		{"user", model.ReservationFilter{UserID: "u1"}, []string{"r1", "r2"}},
+		{"spot", model.ReservationFilter{SpotID: "s1"}, []string{"r1", "r3"}},
+		{"status", model.ReservationFilter{Status: model.StatusCanceled}, []string{"r3"}},
+		{"range", model.ReservationFilter{From: &from, To: &to}, []string{"r2", "r3"}},
+	}
+
+	for _, tt := range tests {
+		page, err := st.GetAll(ctx, tt.filter, model.ListOptions{SortBy: "start_time", IncludeTotal: true})
+		if err != nil {
+			t.Fatalf("%v: GetAll: %v", tt.name, err)
+		}
+		if got := ids(page.Items); !equal(got, tt.want) {
+			t.Errorf("%v: got %v want %v", tt.name, got, tt.want)
+		}
+		if page.TotalCount == nil || *page.TotalCount != int64(len(tt.want)) {
+			t.Errorf("%v: wrong total count: got %v want %v", tt.name, page.TotalCount, len(tt.want))
+		}
+	}
+}
+
+func testGetAllPagination(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	// r2 and r3 share the sort value, so the cursor must break the tie
+	mustAdd(t, st,
+		reservation("r1", "u1", "s1", at(0), at(1)),
+		reservation("r2", "u1", "s1", at(1), at(2)),
+		reservation("r3", "u1", "s2", at(1), at(2)),
+		reservation("r4", "u1", "s1", at(2), at(3)),
+		reservation("r5", "u1", "s1", at(3), at(4)),
+	)
+
+	for _, descending := range []bool{false, true} {
+		var got []string
+		opts := model.ListOptions{Limit: 2, SortBy: "start_time", Descending: descending}
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatalf("pagination doesn't end")
+			}
+
+			page, err := st.GetAll(ctx, model.ReservationFilter{}, opts)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if len(page.Items) > 2 {
+				t.Fatalf("page exceeds the limit: %v", ids(page.Items))
+			}
+			got = append(got, ids(page.Items)...)
+
+			if page.NextCursor == "" {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+
+		if len(got) != 5 {
+			t.Fatalf("descending=%v: wrong items: got %v", descending, got)
+		}
+		seen := make(map[string]struct{})
+		for _, id := range got {
+			seen[id] = struct{}{}
+		}
+		if len(seen) != 5 {
+			t.Errorf("descending=%v: duplicated items: got %v", descending, got)
+		}
+
+		first, last := "r1", "r5"
+		if descending {
+			first, last = last, first
+		}
+		if got[0] != first || got[4] != last {
+			t.Errorf("descending=%v: wrong order: got %v", descending, got)
+		}
+	}
+}
+
+func testInvalidCursor(t *testing.T, st store.ReservationStore) {
+	_, err := st.GetAll(context.Background(), model.ReservationFilter{}, model.ListOptions{SortBy: "start_time", Cursor: "not-a-cursor"})
+	if !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("got %v want %v", err, model.ErrInvalidCursor)
+	}
+}
+
+func testGetActiveReservation(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+	canceled := reservation("r2", "u1", "s1", at(2), at(3))
+	canceled.Status = model.StatusCanceled
+	pending := reservation("r3", "u1", "s1", at(3), at(4))
+	pending.Status = model.StatusPending
+	mustAdd(t, st, reservation("r1", "u1", "s1", at(0), at(1)), canceled, pending)
+
+	got, err := st.GetActiveReservation(ctx, "s1", "wx 1234-a", at(0).Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("GetActiveReservation: %v", err)
+	}
+	if got.ReservationID != "r1" {
+		t.Errorf("wrong reservation: got %v want %v", got.ReservationID, "r1")
+	}
+
+	// The end is exclusive, and canceled and unpaid reservations don't count
+	for _, moment := range []time.Time{at(1), at(2), at(3)} {
+		if _, err := st.GetActiveReservation(ctx, "s1", "WX1234A", moment); !errors.Is(err, model.ErrNotFound) {
+			t.Errorf("at %v: got %v want %v", moment, err, model.ErrNotFound)
+		}
+	}
+}
+
+func testGetReservationsInWindow(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+	canceled := reservation("r4", "u1", "s1", at(1), at(2))
+	canceled.Status = model.StatusCanceled
+	mustAdd(t, st,
+		reservation("r1", "u1", "s1", at(3), at(4)),
+		reservation("r2", "u1", "s2", at(1), at(2)),
+		reservation("r3", "u1", "s3", at(1), at(2)),
+		canceled,
+		reservation("r5", "u1", "s1", at(5), at(6)),
+	)
+
+	got, err := st.GetReservationsInWindow(ctx, []string{"s1", "s2"}, at(1), at(5))
+	if err != nil {
+		t.Fatalf("GetReservationsInWindow: %v", err)
+	}
+	if want := []string{"r2", "r1"}; !equal(ids(got), want) {
+		t.Errorf("got %v want %v", ids(got), want)
+	}
+}
+
+func testCheckAvailability(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+	canceled := reservation("r2", "u1", "s2", at(0), at(2))
+	canceled.Status = model.StatusCanceled
+	pending := reservation("r3", "u1", "s3", at(2), at(3))
+	pending.Status = model.StatusPending
+	mustAdd(t, st, reservation("r1", "u1", "s1", at(0), at(2)), canceled, pending)
+
+	// A reservation waiting for its payment holds the spot already
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  []string
+	}{
+		{"overlap", at(1), at(3), []string{"s2"}},
+		{"touching", at(2), at(3), []string{"s1", "s2"}},
+		{"before pending", at(1), at(2), []string{"s2", "s3"}},
+	}
+
+	for _, tt := range tests {
+		got, err := st.CheckAvailability(ctx, model.AvailabilityInput{SpotIDs: []string{"s1", "s2", "s3"}, StartTime: tt.start, EndTime: tt.end})
+		if err != nil {
+			t.Fatalf("%v: CheckAvailability: %v", tt.name, err)
+		}
+		if !equal(got, tt.want) {
+			t.Errorf("%v: got %v want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Editing a reservation doesn't collide with itself
+	input := model.AvailabilityInput{SpotIDs: []string{"s1"}, StartTime: at(1), EndTime: at(3)}
+	got, err := st.CheckAvailabilityForEdit(ctx, input, "r1")
+	if err != nil {
+		t.Fatalf("CheckAvailabilityForEdit: %v", err)
+	}
+	if !equal(got, []string{"s1"}) {
+		t.Errorf("edit: got %v want %v", got, []string{"s1"})
+	}
+
+	input.SpotIDs = []string{"s1", "s2"}
+	if _, err := st.CheckAvailabilityForEdit(ctx, input, "r1"); err == nil {
+		t.Errorf("edit with two spots: expected an error")
+	}
+}
+
+func event(t *testing.T, eventType string, r model.Reservation, occurredAt time.Time) model.Event {
+	t.Helper()
+	e, err := model.NewEvent(eventType, r.ReservationID, r)
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	e.OccurredAt = occurredAt
+	return e
+}
+
+func eventIDs(events []model.Event) []string {
+	resp := make([]string, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, e.ID)
+	}
+	return resp
+}
+
+func testOutbox(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	r := reservation("r1", "u1", "s1", at(0), at(1))
+	created := event(t, model.EventReservationCreated, r, at(0))
+	if err := st.AddReservation(ctx, r, created); err != nil {
+		t.Fatalf("AddReservation: %v", err)
+	}
+
+	r.Status = model.StatusCanceled
+	canceled := event(t, model.EventReservationCanceled, r, at(1))
+	if err := st.EditReservation(ctx, r, canceled); err != nil {
+		t.Fatalf("EditReservation: %v", err)
+	}
+
+	// A failed write must not leave its event behind
+	missing := reservation("missing", "u1", "s1", at(0), at(1))
+	if err := st.EditReservation(ctx, missing, event(t, model.EventReservationUpdated, missing, at(2))); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("EditReservation(missing) = %v, want ErrNotFound", err)
+	}
+
+	pending, err := st.PendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{created.ID, canceled.ID}; !equal(eventIDs(pending), want) {
+		t.Fatalf("PendingEvents = %v, want %v", eventIDs(pending), want)
+	}
+
+	got := pending[1]
+	if got.Type != model.EventReservationCanceled || got.Source != model.EventSource || got.AggregateID != "r1" || !got.OccurredAt.Equal(at(1)) {
+		t.Errorf("PendingEvents[1] = %+v, want the canceled event", got)
+	}
+	var payload model.Reservation
+	if err := json.Unmarshal(got.Payload, &payload); err != nil || payload.Status != model.StatusCanceled {
+		t.Errorf("PendingEvents[1] payload = %s (%v), want the canceled reservation", got.Payload, err)
+	}
+
+	limited, err := st.PendingEvents(ctx, 1)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{created.ID}; !equal(eventIDs(limited), want) {
+		t.Errorf("PendingEvents(limit 1) = %v, want %v", eventIDs(limited), want)
+	}
+
+	if err := st.MarkPublished(ctx, []string{created.ID}); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+
+	pending, err = st.PendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{canceled.ID}; !equal(eventIDs(pending), want) {
+		t.Errorf("PendingEvents after MarkPublished = %v, want %v", eventIDs(pending), want)
+	}
+}
+
+func webhook(id string, eventTypes ...string) model.Webhook {
+	return model.Webhook{
+		WebhookID:  id,
+		URL:        "https://example.com/hooks/" + id,
+		EventTypes: eventTypes,
+		Secret:     "0123456789abcdef",
+		CreatedAt:  base,
+	}
+}
+
+func deliveryIDs(deliveries []model.Delivery) []string {
+	resp := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, d.DeliveryID)
+	}
+	return resp
+}
+
+func testWebhooks(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	w1 := webhook("w1", model.EventReservationCreated, model.EventSpotDeleted)
+	w2 := webhook("w2", model.EventReservationCanceled)
+	w2.CreatedAt = at(1)
+	for _, w := range []model.Webhook{w1, w2} {
+		if err := st.AddWebhook(ctx, w); err != nil {
+			t.Fatalf("AddWebhook(%v): %v", w.WebhookID, err)
+		}
+	}
+
+	got, err := st.GetWebhook(ctx, "w1")
+	if err != nil {
+		t.Fatalf("GetWebhook: %v", err)
+	}
+	if got.URL != w1.URL || got.Secret != w1.Secret || !equal(got.EventTypes, w1.EventTypes) || !got.CreatedAt.Equal(base) {
+		t.Errorf("GetWebhook = %+v, want %+v", got, w1)
+	}
+
+	all, err := st.GetWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("GetWebhooks: %v", err)
+	}
+	if len(all) != 2 || all[0].WebhookID != "w1" || all[1].WebhookID != "w2" {
+		t.Errorf("GetWebhooks = %+v, want w1 and w2", all)
+	}
+
+	if err := st.DeleteWebhook(ctx, "w1"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if _, err := st.GetWebhook(ctx, "w1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetWebhook(deleted) = %v, want ErrNotFound", err)
+	}
+	if err := st.DeleteWebhook(ctx, "w1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("DeleteWebhook(deleted) = %v, want ErrNotFound", err)
+	}
+}
+
+func testDeliveries(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	r := reservation("r1", "u1", "s1", at(0), at(1))
+	first := model.NewDelivery("w1", event(t, model.EventReservationCreated, r, at(0)), at(0))
+	second := model.NewDelivery("w2", event(t, model.EventReservationCreated, r, at(0)), at(1))
+	third := model.NewDelivery("w1", event(t, model.EventReservationCanceled, r, at(2)), at(2))
+
+	if err := st.AddDeliveries(ctx, []model.Delivery{first, second, third}); err != nil {
+		t.Fatalf("AddDeliveries: %v", err)
+	}
+
+	// Adding a delivery again keeps the stored one
+	again := first
+	again.Status = model.DeliveryDead
+	if err := st.AddDeliveries(ctx, []model.Delivery{again}); err != nil {
+		t.Fatalf("AddDeliveries(again): %v", err)
+	}
+
+	got, err := st.GetDelivery(ctx, first.DeliveryID)
+	if err != nil {
+		t.Fatalf("GetDelivery: %v", err)
+	}
+	if got.Status != model.DeliveryPending || got.WebhookID != "w1" || got.Event.ID != first.Event.ID || !got.NextAttemptAt.Equal(at(0)) {
+		t.Errorf("GetDelivery = %+v, want the first delivery", got)
+	}
+	if _, err := st.GetDelivery(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetDelivery(missing) = %v, want ErrNotFound", err)
+	}
+
+	byWebhook, err := st.GetDeliveries(ctx, model.DeliveryFilter{WebhookID: "w1"}, 10)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if want := []string{third.DeliveryID, first.DeliveryID}; !equal(deliveryIDs(byWebhook), want) {
+		t.Errorf("GetDeliveries(w1) = %v, want %v", deliveryIDs(byWebhook), want)
+	}
+
+	// Claiming at hour 1 takes the due deliveries oldest first and hides them for the lease
+	claimed, err := st.ClaimDeliveries(ctx, at(1), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("ClaimDeliveries: %v", err)
+	}
+	if want := []string{first.DeliveryID, second.DeliveryID}; !equal(deliveryIDs(claimed), want) {
+		t.Fatalf("ClaimDeliveries = %v, want %v", deliveryIDs(claimed), want)
+	}
+	if !claimed[0].NextAttemptAt.Equal(at(1).Add(time.Minute)) {
+		t.Errorf("claimed NextAttemptAt = %v, want the end of the lease", claimed[0].NextAttemptAt)
+	}
+
+	claimed, err = st.ClaimDeliveries(ctx, at(1), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("ClaimDeliveries: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("ClaimDeliveries during the lease = %v, want none", deliveryIDs(claimed))
+	}
+
+	first.Status = model.DeliveryDead
+	first.Failures = 1
+	first.Attempts = []model.Attempt{{At: at(1), StatusCode: 500, DurationMS: 12}}
+	first.UpdatedAt = at(1)
+	if err := st.UpdateDelivery(ctx, first); err != nil {
+		t.Fatalf("UpdateDelivery: %v", err)
+	}
+
+	dead, err := st.GetDeliveries(ctx, model.DeliveryFilter{Status: model.DeliveryDead}, 10)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(dead) != 1 || dead[0].DeliveryID != first.DeliveryID || len(dead[0].Attempts) != 1 || dead[0].Attempts[0].StatusCode != 500 {
+		t.Errorf("GetDeliveries(dead) = %+v, want the updated first delivery", dead)
+	}
+
+	limited, err := st.GetDeliveries(ctx, model.DeliveryFilter{}, 1)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if want := []string{third.DeliveryID}; !equal(deliveryIDs(limited), want) {
+		t.Errorf("GetDeliveries(limit 1) = %v, want %v", deliveryIDs(limited), want)
+	}
+
+	missing := model.NewDelivery("w1", event(t, model.EventReservationDeleted, r, at(3)), at(3))
+	if err := st.UpdateDelivery(ctx, missing); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("UpdateDelivery(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func payment(id, reservationID string, dueAt *time.Time) model.Payment {
+	return model.Payment{
+		PaymentID:     id,
+		ReservationID: reservationID,
+		Provider:      "fake",
+		ProviderRef:   "ref_" + id,
+		Status:        model.PaymentAuthorized,
+		Currency:      "eur",
+		Amount:        1050,
+		DueAt:         dueAt,
+		CreatedAt:     base,
+		UpdatedAt:     base,
+	}
+}
+
+func paymentIDs(payments []model.Payment) []string {
+	resp := make([]string, 0, len(payments))
+	for _, p := range payments {
+		resp = append(resp, p.PaymentID)
+	}
+	return resp
+}
+
+func testPayments(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	first, second := at(1), at(0)
+	p1 := payment("p1", "r1", &first)
+	p2 := payment("p2", "r2", &second)
+	p3 := payment("p3", "r3", nil)
+	for _, p := range []model.Payment{p1, p2, p3} {
+		if err := st.AddPayment(ctx, p); err != nil {
+			t.Fatalf("AddPayment(%v): %v", p.PaymentID, err)
+		}
+	}
+
+	got, err := st.GetPayment(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetPayment: %v", err)
+	}
+	if got.ReservationID != "r1" || got.Amount != 1050 || got.Status != model.PaymentAuthorized || got.DueAt == nil || !got.DueAt.Equal(first) {
+		t.Errorf("GetPayment = %+v, want %+v", got, p1)
+	}
+
+	got, err = st.GetReservationPayment(ctx, "r2")
+	if err != nil || got.PaymentID != "p2" {
+		t.Errorf("GetReservationPayment = %v, %v, want p2", got.PaymentID, err)
+	}
+	got, err = st.GetPaymentByProviderRef(ctx, "fake", "ref_p3")
+	if err != nil || got.PaymentID != "p3" || got.DueAt != nil {
+		t.Errorf("GetPaymentByProviderRef = %+v, %v, want p3 without a due time", got, err)
+	}
+	if _, err := st.GetPaymentByProviderRef(ctx, "stripe", "ref_p3"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetPaymentByProviderRef(other provider) = %v, want ErrNotFound", err)
+	}
+	if _, err := st.GetPayment(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetPayment(missing) = %v, want ErrNotFound", err)
+	}
+
+	// Claiming at hour 1 takes the due payments oldest first and hides them for the lease
+	claimed, err := st.ClaimPayments(ctx, at(1), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("ClaimPayments: %v", err)
+	}
+	if want := []string{"p2", "p1"}; !equal(paymentIDs(claimed), want) {
+		t.Fatalf("ClaimPayments = %v, want %v", paymentIDs(claimed), want)
+	}
+	if !claimed[0].DueAt.Equal(at(1).Add(time.Minute)) {
+		t.Errorf("claimed DueAt = %v, want the end of the lease", claimed[0].DueAt)
+	}
+	if claimed, _ := st.ClaimPayments(ctx, at(1), time.Minute, 10); len(claimed) != 0 {
+		t.Errorf("ClaimPayments during the lease = %v, want none", paymentIDs(claimed))
+	}
+
+	p1.Status = model.PaymentCaptured
+	p1.Captured = 1050
+	p1.DueAt = nil
+	p1.UpdatedAt = at(1)
+	if err := st.UpdatePayment(ctx, p1); err != nil {
+		t.Fatalf("UpdatePayment: %v", err)
+	}
+	got, err = st.GetPayment(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetPayment: %v", err)
+	}
+	if got.Status != model.PaymentCaptured || got.Captured != 1050 || got.DueAt != nil {
+		t.Errorf("GetPayment after UpdatePayment = %+v, want the captured payment", got)
+	}
+	if claimed, _ := st.ClaimPayments(ctx, at(5), time.Minute, 10); !equal(paymentIDs(claimed), []string{"p2"}) {
+		t.Errorf("ClaimPayments after the lease = %v, want p2", paymentIDs(claimed))
+	}
+
+	if err := st.UpdatePayment(ctx, payment("missing", "r4", nil)); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("UpdatePayment(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func testProviderEvents(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	tests := []struct {
+		provider string
+		eventID  string
+		want     bool
+	}{
+		{"stripe", "evt_1", true},
+		{"stripe", "evt_1", false},
+		{"stripe", "evt_2", true},
+		{"fake", "evt_1", true},
+	}
+
+	for _, tt := range tests {
+		got, err := st.ClaimProviderEvent(ctx, tt.provider, tt.eventID, base)
+		if err != nil {
+			t.Fatalf("ClaimProviderEvent(%v, %v): %v", tt.provider, tt.eventID, err)
+		}
+		if got != tt.want {
+			t.Errorf("ClaimProviderEvent(%v, %v) = %v, want %v", tt.provider, tt.eventID, got, tt.want)
+		}
+	}
+
+	// Released, the next delivery claims it again
+	if err := st.ReleaseProviderEvent(ctx, "stripe", "evt_1"); err != nil {
+		t.Fatalf("ReleaseProviderEvent: %v", err)
+	}
+	if claimed, err := st.ClaimProviderEvent(ctx, "stripe", "evt_1", base); err != nil || !claimed {
+		t.Errorf("ClaimProviderEvent after the release = %v, %v, want claimed", claimed, err)
+	}
+}
+
+func invoice(id, tenantID, userID string, issuedAt time.Time, reservations ...model.Reservation) model.Invoice {
+	inv := model.Invoice{
+		InvoiceID: id,
+		TenantID:  tenantID,
+		Kind:      model.InvoiceSingle,
+		UserID:    userID,
+		Seller:    model.Party{Name: "Reserve Park"},
+		Buyer:     model.Party{Name: "Acme Ltd", TaxID: "PL1234567890"},
+		Currency:  "eur",
+		IssuedAt:  issuedAt,
+	}
+	for _, r := range reservations {
+		inv.AddLine(r, r.Billable(), model.SpotDetails{SpotID: r.SpotID, LotID: "lot-a"}, model.Tax{Name: "VAT", Rate: 23})
+	}
+	return inv
+}
+
+func testInvoices(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	r1 := reservation("r1", "u1", "s1", at(0), at(1))
+	r2 := reservation("r2", "u1", "s1", at(2), at(3))
+	r3 := reservation("r3", "u2", "s2", at(0), at(1))
+
+	// Every tenant numbers its invoices on its own
+	var issued []model.Invoice
+	for _, inv := range []model.Invoice{
+		invoice("i1", "rp", "u1", at(0), r1),
+		invoice("i2", "other", "u2", at(1), r3),
+		invoice("i3", "rp", "u1", at(2), r2),
+	} {
+		stored, err := st.AddInvoice(ctx, inv)
+		if errors.Is(err, errors.ErrUnsupported) {
+			t.Skipf("the store doesn't issue invoices: %v", err)
+		}
+		if err != nil {
+			t.Fatalf("AddInvoice(%v): %v", inv.InvoiceID, err)
+		}
+		issued = append(issued, stored)
+	}
+	if issued[0].Number != "RP-000001" || issued[1].Number != "OTHER-000001" || issued[2].Number != "RP-000002" {
+		t.Errorf("numbers = %v, %v, %v, want RP-000001, OTHER-000001, RP-000002", issued[0].Number, issued[1].Number, issued[2].Number)
+	}
+
+	// A reservation is invoiced once
+	if _, err := st.AddInvoice(ctx, invoice("i4", "rp", "u1", at(3), r1)); !errors.Is(err, model.ErrDuplicate) {
+		t.Errorf("AddInvoice(invoiced reservation) = %v, want ErrDuplicate", err)
+	}
+
+	got, err := st.GetInvoice(ctx, "i1")
+	if err != nil {
+		t.Fatalf("GetInvoice: %v", err)
+	}
+	if got.Number != "RP-000001" || got.Buyer.TaxID != "PL1234567890" || len(got.Lines) != 1 || got.Total != 10 ||
+		got.Net != 8.13 || got.Tax != 1.87 || len(got.Taxes) != 1 || !got.IssuedAt.Equal(at(0)) {
+		t.Errorf("GetInvoice = %+v", got)
+	}
+	if line := got.Lines[0]; line.ReservationID != "r1" || line.Spot.LotID != "lot-a" || !line.StartTime.Equal(at(0)) {
+		t.Errorf("line = %+v", line)
+	}
+	if _, err := st.GetInvoice(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetInvoice(missing) = %v, want ErrNotFound", err)
+	}
+
+	got, err = st.GetReservationInvoice(ctx, "r2")
+	if err != nil || got.InvoiceID != "i3" {
+		t.Errorf("GetReservationInvoice = %v, %v, want i3", got.InvoiceID, err)
+	}
+	if _, err := st.GetReservationInvoice(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetReservationInvoice(missing) = %v, want ErrNotFound", err)
+	}
+
+	invoices, err := st.GetInvoices(ctx, model.InvoiceFilter{TenantID: "rp", UserID: "u1"}, 10)
+	if err != nil {
+		t.Fatalf("GetInvoices: %v", err)
+	}
+	if len(invoices) != 2 || invoices[0].InvoiceID != "i3" || invoices[1].InvoiceID != "i1" {
+		t.Errorf("GetInvoices = %v, want i3, i1", invoiceIDs(invoices))
+	}
+
+	invoices, err = st.GetInvoices(ctx, model.InvoiceFilter{}, 1)
+	if err != nil || len(invoices) != 1 || invoices[0].InvoiceID != "i3" {
+		t.Errorf("GetInvoices(limit 1) = %v, %v, want i3", invoiceIDs(invoices), err)
+	}
+
+	// A reservation can be credited more than once, its invoice stays the one found
+	for i, id := range []string{"c1", "c2"} {
+		note := invoice(id, "rp", "u1", at(4+i))
+		note.Kind = model.InvoiceCreditNote
+		note.CreditedInvoiceID, note.CreditedNumber = "i1", "RP-000001"
+		note.AddCredit(issued[0].Lines[0], 2, model.Tax{Name: "VAT", Rate: 23})
+
+		stored, err := st.AddInvoice(ctx, note)
+		if err != nil {
+			t.Fatalf("AddInvoice(%v): %v", id, err)
+		}
+		if want := fmt.Sprintf("RP-%06d", 3+i); stored.Number != want {
+			t.Errorf("credit note number = %v, want %v", stored.Number, want)
+		}
+	}
+
+	got, err = st.GetReservationInvoice(ctx, "r1")
+	if err != nil || got.InvoiceID != "i1" {
+		t.Errorf("GetReservationInvoice(credited) = %v, %v, want i1", got.InvoiceID, err)
+	}
+	notes, err := st.GetCreditNotes(ctx, "r1")
+	if err != nil || len(notes) != 2 || notes[0].InvoiceID != "c1" || notes[1].InvoiceID != "c2" {
+		t.Fatalf("GetCreditNotes = %v, %v, want c1, c2", invoiceIDs(notes), err)
+	}
+	if notes[0].CreditedInvoiceID != "i1" || notes[0].Total != -2 || model.Invoiced("r1", append(notes, issued[0])...) != 6 {
+		t.Errorf("credit note = %+v, want 2 taken off i1", notes[0])
+	}
+	if notes, err := st.GetCreditNotes(ctx, "r2"); err != nil || len(notes) != 0 {
+		t.Errorf("GetCreditNotes(uncredited) = %v, %v, want none", invoiceIDs(notes), err)
+	}
+}
+
+func invoiceIDs(invoices []model.Invoice) []string {
+	resp := make([]string, 0, len(invoices))
+	for _, inv := range invoices {
+		resp = append(resp, inv.InvoiceID)
+	}
+	return resp
+}
+
+func promoCode(code string, createdAt time.Time) model.PromoCode {
+	return model.PromoCode{
+		Code:         code,
+		Type:         model.DiscountPercent,
+		Value:        20,
+		Restrictions: model.PromoRestrictions{LotIDs: []string{"lot-a"}, SpotTypes: []string{"ev"}},
+		CreatedAt:    createdAt,
+	}
+}
+
+func testPromoCodes(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	until := at(48)
+	first := promoCode("SPRING", at(0))
+	first.ValidUntil = &until
+	first.MaxPerUser = 1
+	for _, p := range []model.PromoCode{first, promoCode("SUMMER", at(1))} {
+		if err := st.AddPromoCode(ctx, p); err != nil {
+			t.Fatalf("AddPromoCode(%v): %v", p.Code, err)
+		}
+	}
+	if err := st.AddPromoCode(ctx, promoCode("SPRING", at(2))); !errors.Is(err, model.ErrDuplicate) {
+		t.Errorf("AddPromoCode(existing) = %v, want ErrDuplicate", err)
+	}
+
+	got, err := st.GetPromoCode(ctx, "SPRING")
+	if err != nil {
+		t.Fatalf("GetPromoCode: %v", err)
+	}
+	if got.Type != model.DiscountPercent || got.Value != 20 || got.MaxPerUser != 1 || got.ValidUntil == nil || !got.ValidUntil.Equal(until) ||
+		!equal(got.Restrictions.LotIDs, []string{"lot-a"}) || !equal(got.Restrictions.SpotTypes, []string{"ev"}) {
+		t.Errorf("GetPromoCode = %+v", got)
+	}
+	if _, err := st.GetPromoCode(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetPromoCode(missing) = %v, want ErrNotFound", err)
+	}
+
+	codes, err := st.GetPromoCodes(ctx)
+	if err != nil || len(codes) != 2 || codes[0].Code != "SUMMER" || codes[1].Code != "SPRING" {
+		t.Errorf("GetPromoCodes = %v, %v, want SUMMER, SPRING", codes, err)
+	}
+
+	if err := st.DeletePromoCode(ctx, "SUMMER"); err != nil {
+		t.Fatalf("DeletePromoCode: %v", err)
+	}
+	if err := st.DeletePromoCode(ctx, "SUMMER"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("DeletePromoCode(deleted) = %v, want ErrNotFound", err)
+	}
+
+	// The discount is kept on the reservation
+	r := reservation("r1", "u1", "s1", at(0), at(1))
+	r.Discount = &model.Discount{Code: "SPRING", ListPrice: 12.5, Amount: 2.5}
+	mustAdd(t, st, r)
+	stored, err := st.GetReservation(ctx, "r1")
+	if err != nil || stored.Discount == nil || *stored.Discount != *r.Discount {
+		t.Errorf("GetReservation discount = %+v, %v, want %+v", stored.Discount, err, r.Discount)
+	}
+}
+
+func redemption(reservationID, code, userID string) model.PromoRedemption {
+	return model.PromoRedemption{ReservationID: reservationID, Code: code, UserID: userID, Discount: 2, RedeemedAt: base}
+}
+
+func testPromoRedemptions(t *testing.T, st store.ReservationStore) {
+	ctx := context.Background()
+
+	limited := promoCode("LIMITED", at(0))
+	limited.MaxRedemptions = 2
+	limited.MaxPerUser = 1
+	if err := st.AddPromoCode(ctx, limited); err != nil {
+		t.Fatalf("AddPromoCode: %v", err)
+	}
+
+	if err := st.RedeemPromoCode(ctx, redemption("r1", "LIMITED", "u1")); err != nil {
+		t.Fatalf("RedeemPromoCode: %v", err)
+	}
+	if err := st.RedeemPromoCode(ctx, redemption("r2", "LIMITED", "u1")); !errors.Is(err, model.ErrLimitReached) {
+		t.Errorf("RedeemPromoCode(user limit) = %v, want ErrLimitReached", err)
+	}
+	if err := st.RedeemPromoCode(ctx, redemption("r3", "LIMITED", "u2")); err != nil {
+		t.Fatalf("RedeemPromoCode: %v", err)
+	}
+	if err := st.RedeemPromoCode(ctx, redemption("r4", "LIMITED", "u3")); !errors.Is(err, model.ErrLimitReached) {
+		t.Errorf("RedeemPromoCode(global limit) = %v, want ErrLimitReached", err)
+	}
+	if err := st.RedeemPromoCode(ctx, redemption("r5", "missing", "u1")); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("RedeemPromoCode(missing) = %v, want ErrNotFound", err)
+	}
+
+	got, err := st.GetPromoCode(ctx, "LIMITED")
+	if err != nil || got.Redemptions != 2 {
+		t.Errorf("Redemptions = %v, %v, want 2", got.Redemptions, err)
+	}
+	if count, err := st.CountRedemptions(ctx, "LIMITED", "u1"); err != nil || count != 1 {
+		t.Errorf("CountRedemptions = %v, %v, want 1", count, err)
+	}
+
+	// Releasing frees both limits, twice is harmless
+	for range 2 {
+		if err := st.ReleaseRedemption(ctx, "r1"); err != nil {
+			t.Fatalf("ReleaseRedemption: %v", err)
+		}
+	}
+	if count, err := st.CountRedemptions(ctx, "LIMITED", "u1"); err != nil || count != 0 {
+		t.Errorf("CountRedemptions(released) = %v, %v, want 0", count, err)
+	}
+	if err := st.RedeemPromoCode(ctx, redemption("r6", "LIMITED", "u1")); err != nil {
+		t.Errorf("RedeemPromoCode(released) = %v", err)
+	}
+
+	// Concurrent redemptions never exceed the limit
+	limited = promoCode("RUSH", at(0))
+	limited.MaxRedemptions = 3
+	if err := st.AddPromoCode(ctx, limited); err != nil {
+		t.Fatalf("AddPromoCode: %v", err)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		redeemed int
+	)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := st.RedeemPromoCode(ctx, redemption(fmt.Sprintf("rush%d", i), "RUSH", fmt.Sprintf("u%d", i)))
+			if err != nil && !errors.Is(err, model.ErrLimitReached) {
+				t.Errorf("RedeemPromoCode: %v", err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				redeemed++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if redeemed != 3 {
+		t.Errorf("redeemed %d times, want 3", redeemed)
+	}
+}
@@ -0,0 +1,239 @@
+// Package webhook delivers domain events to the HTTP endpoints registered as webhooks.
+// The Dispatcher receives the events from the relay like any broker, stores one
+// delivery per subscribed webhook and sends the deliveries in the background,
+// signed with the webhook's secret and retried with a doubling backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/metrics"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store"
+)
+
+// maxLoggedAttempts bounds the attempts log of a delivery that keeps being redelivered
+const maxLoggedAttempts = 25
+
+// Dispatcher implements events.Broker. Delivery is at least once: a receiver can
+// get a delivery again, e.g. when it answered after the timeout, so it should
+// deduplicate on the X-ReservePark-Delivery header.
+type Dispatcher struct {
+	Store  store.WebhookStore
+	Client *http.Client
+	Logger *logger.Logger
+
+	Interval  time.Duration
+	BatchSize int
+
+	// Lease is how long a claimed delivery stays hidden from other instances,
+	// it must be longer than the client timeout
+	Lease time.Duration
+
+	// The n-th failure is retried after Backoff * 2^(n-1), at most MaxBackoff later.
+	// The delivery is dead lettered once it failed MaxAttempts times.
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	MaxAttempts int
+
+	now func() time.Time
+}
+
+func NewDispatcher(log *logger.Logger, st store.WebhookStore, interval, timeout, backoff time.Duration, maxAttempts int) *Dispatcher {
+	return &Dispatcher{
+		Store:       st,
+		Client:      &http.Client{Timeout: timeout},
+		Logger:      log,
+		Interval:    interval,
+		BatchSize:   20,
+		Lease:       timeout + time.Minute,
+		Backoff:     backoff,
+		MaxBackoff:  time.Hour,
+		MaxAttempts: maxAttempts,
+		now:         time.Now,
+	}
+}
+
+// Publish stores a delivery of the event for every webhook subscribed to its type
+func (d *Dispatcher) Publish(ctx context.Context, event model.Event) error {
+	webhooks, err := d.Store.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := d.now().UTC()
+	var deliveries []model.Delivery
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event.Type) {
+			deliveries = append(deliveries, model.NewDelivery(webhook.WebhookID, event, now))
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return d.Store.AddDeliveries(ctx, deliveries)
+}
+
+func (d *Dispatcher) Close() error {
+	return nil
+}
+
+// Run sends the due deliveries until the context is canceled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Flush(ctx); err != nil && ctx.Err() == nil {
+			d.Logger.ErrorContext(ctx, "Failed to send webhook deliveries", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush sends the deliveries that are due, in parallel, and returns how many were attempted
+func (d *Dispatcher) Flush(ctx context.Context) (int, error) {
+	attempted := 0
+
+	for {
+		deliveries, err := d.Store.ClaimDeliveries(ctx, d.now().UTC(), d.Lease, d.BatchSize)
+		if err != nil {
+			return attempted, err
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(deliveries))
+		for i := range deliveries {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = d.attempt(ctx, deliveries[i])
+			}(i)
+		}
+		wg.Wait()
+		attempted += len(deliveries)
+
+		if err := errors.Join(errs...); err != nil {
+			return attempted, err
+		}
+		if len(deliveries) < d.BatchSize {
+			return attempted, nil
+		}
+	}
+}
+
+// attempt sends the delivery once and stores the outcome
+func (d *Dispatcher) attempt(ctx context.Context, delivery model.Delivery) error {
+	webhook, err := d.Store.GetWebhook(ctx, delivery.WebhookID)
+	if errors.Is(err, model.ErrNotFound) {
+		// Nobody can fix the endpoint of a deleted webhook anymore
+		now := d.now().UTC()
+		delivery.Status = model.DeliveryDead
+		delivery.Attempts = appendAttempt(delivery.Attempts, model.Attempt{At: now, Error: "webhook was deleted"})
+		delivery.UpdatedAt = now
+		metrics.WebhookAttempts.WithLabelValues(string(model.DeliveryDead)).Inc()
+		return d.Store.UpdateDelivery(ctx, delivery)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := d.send(ctx, webhook, delivery)
+	if ctx.Err() != nil {
+		// Shutting down isn't the receiver's fault, the delivery is retried once the lease ends
+		return ctx.Err()
+	}
+	delivery.Attempts = appendAttempt(delivery.Attempts, result)
+	delivery.UpdatedAt = result.At
+
+	switch {
+	case result.Error == "":
+		delivery.Status = model.DeliverySucceeded
+		metrics.WebhookAttempts.WithLabelValues(string(model.DeliverySucceeded)).Inc()
+	case delivery.Failures+1 >= d.MaxAttempts:
+		delivery.Failures++
+		delivery.Status = model.DeliveryDead
+		metrics.WebhookAttempts.WithLabelValues(string(model.DeliveryDead)).Inc()
+		d.Logger.WarnContext(ctx, "Webhook delivery dead lettered", "delivery_id", delivery.DeliveryID,
+			"webhook_id", webhook.WebhookID, "attempts", delivery.Failures, "error", result.Error)
+	default:
+		delivery.Failures++
+		delivery.NextAttemptAt = result.At.Add(d.backoff(delivery.Failures))
+		metrics.WebhookAttempts.WithLabelValues("failed").Inc()
+	}
+
+	return d.Store.UpdateDelivery(ctx, delivery)
+}
+
+// send posts the event to the webhook, any status outside 2xx is a failure
+func (d *Dispatcher) send(ctx context.Context, webhook model.Webhook, delivery model.Delivery) model.Attempt {
+	attempt := model.Attempt{At: d.now().UTC()}
+
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ReservePark-Webhooks/1")
+	req.Header.Set(EventHeader, delivery.Event.Type)
+	req.Header.Set(DeliveryHeader, delivery.DeliveryID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, attempt.At, body))
+
+	start := time.Now()
+	resp, err := d.Client.Do(req)
+	elapsed := time.Since(start)
+	attempt.DurationMS = elapsed.Milliseconds()
+	metrics.WebhookAttemptDuration.Observe(elapsed.Seconds())
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	// Reading a bit of the body lets the client reuse the connection
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+func (d *Dispatcher) backoff(failures int) time.Duration {
+	delay := d.Backoff
+	for i := 1; i < failures && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.MaxBackoff)
+}
+
+func appendAttempt(log []model.Attempt, attempt model.Attempt) []model.Attempt {
+	log = append(log, attempt)
+	if len(log) > maxLoggedAttempts {
+		log = log[len(log)-maxLoggedAttempts:]
+	}
+	return log
+}
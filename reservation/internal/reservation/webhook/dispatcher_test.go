@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/logger"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/model"
+	"github.com/ciameksw/reserve-park/reservation/internal/reservation/store/memory"
+)
+
+const secret = "0123456789abcdef"
+
+// receiver is a webhook endpoint answering with the next status of its script, 200 once it runs out
+type receiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	received []model.Event
+	headers  []http.Header
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		rc.t.Errorf("Failed to read the delivery: %v", err)
+	}
+	if err := Verify(secret, r.Header.Get(SignatureHeader), body, 0, time.Now()); err != nil {
+		rc.t.Errorf("Delivery signature: %v", err)
+	}
+
+	var event model.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		rc.t.Errorf("Failed to decode the delivery: %v", err)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.received = append(rc.received, event)
+	rc.headers = append(rc.headers, r.Header.Clone())
+
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// setup returns a dispatcher on a fake clock with a webhook pointing at the receiver
+func setup(t *testing.T, rc *receiver, eventTypes ...string) (*Dispatcher, *memory.Store, *time.Time) {
+	t.Helper()
+
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	st := memory.New()
+	err := st.AddWebhook(context.Background(), model.Webhook{
+		WebhookID:  "w1",
+		URL:        srv.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("AddWebhook: %v", err)
+	}
+
+	clock := time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+	d := NewDispatcher(logger.GetLogger(), st, time.Second, 5*time.Second, time.Minute, 3)
+	d.now = func() time.Time { return clock }
+	return d, st, &clock
+}
+
+func newEvent(t *testing.T, eventType, reservationID string) model.Event {
+	t.Helper()
+	event, err := model.NewEvent(eventType, reservationID, map[string]string{"reservation_id": reservationID})
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	return event
+}
+
+func flush(t *testing.T, d *Dispatcher, want int) {
+	t.Helper()
+	n, err := d.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n != want {
+		t.Fatalf("Flush attempted %d deliveries, want %d", n, want)
+	}
+}
+
+func onlyDelivery(t *testing.T, st *memory.Store) model.Delivery {
+	t.Helper()
+	deliveries, err := st.GetDeliveries(context.Background(), model.DeliveryFilter{}, 10)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+func TestDeliver(t *testing.T) {
+	rc := &receiver{t: t}
+	d, st, _ := setup(t, rc, model.EventReservationCreated)
+	ctx := context.Background()
+
+	created := newEvent(t, model.EventReservationCreated, "r1")
+	for _, event := range []model.Event{created, created, newEvent(t, model.EventReservationDeleted, "r1")} {
+		if err := d.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	// The event published twice and the one nobody subscribed to aren't delivered
+	flush(t, d, 1)
+	flush(t, d, 0)
+
+	if len(rc.received) != 1 || rc.received[0].ID != created.ID {
+		t.Fatalf("received %+v, want the created event once", rc.received)
+	}
+	if got := rc.headers[0].Get(EventHeader); got != model.EventReservationCreated {
+		t.Errorf("%s = %q, want %q", EventHeader, got, model.EventReservationCreated)
+	}
+
+	delivery := onlyDelivery(t, st)
+	if got := rc.headers[0].Get(DeliveryHeader); got != delivery.DeliveryID {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, got, delivery.DeliveryID)
+	}
+	if delivery.Status != model.DeliverySucceeded || len(delivery.Attempts) != 1 || delivery.Attempts[0].StatusCode != http.StatusOK {
+		t.Errorf("delivery = %+v, want succeeded after one attempt", delivery)
+	}
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	rc := &receiver{t: t, statuses: []int{500, 503, 500, 200}}
+	d, st, clock := setup(t, rc, model.EventReservationCanceled)
+	ctx := context.Background()
+
+	if err := d.Publish(ctx, newEvent(t, model.EventReservationCanceled, "r1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// Failures are retried after 1, then 2 minutes
+	flush(t, d, 1)
+	*clock = clock.Add(59 * time.Second)
+	flush(t, d, 0)
+	*clock = clock.Add(time.Second)
+	flush(t, d, 1)
+
+	delivery := onlyDelivery(t, st)
+	if delivery.Status != model.DeliveryPending || delivery.Failures != 2 || !delivery.NextAttemptAt.Equal(clock.Add(2*time.Minute)) {
+		t.Fatalf("delivery = %+v, want pending with the next attempt in 2 minutes", delivery)
+	}
+
+	// The third failure reaches MaxAttempts
+	*clock = clock.Add(2 * time.Minute)
+	flush(t, d, 1)
+	*clock = clock.Add(time.Hour)
+	flush(t, d, 0)
+
+	delivery = onlyDelivery(t, st)
+	if delivery.Status != model.DeliveryDead || len(delivery.Attempts) != 3 {
+		t.Fatalf("delivery = %+v, want dead after three attempts", delivery)
+	}
+	for i, want := range []int{500, 503, 500} {
+		if got := delivery.Attempts[i]; got.StatusCode != want || got.Error == "" {
+			t.Errorf("attempt %d = %+v, want a failure with status %d", i, got, want)
+		}
+	}
+
+	// A manual redelivery starts over and the receiver accepts it this time
+	delivery.Status = model.DeliveryPending
+	delivery.Failures = 0
+	delivery.NextAttemptAt = *clock
+	if err := st.UpdateDelivery(ctx, delivery); err != nil {
+		t.Fatalf("UpdateDelivery: %v", err)
+	}
+	flush(t, d, 1)
+
+	delivery = onlyDelivery(t, st)
+	if delivery.Status != model.DeliverySucceeded || len(delivery.Attempts) != 4 {
+		t.Errorf("delivery = %+v, want succeeded with four logged attempts", delivery)
+	}
+}
+
+func TestDeletedWebhook(t *testing.T) {
+	rc := &receiver{t: t}
+	d, st, _ := setup(t, rc, model.EventSpotDeleted)
+	ctx := context.Background()
+
+	event := newEvent(t, model.EventSpotDeleted, "s1")
+	event.Source = "spot"
+	if err := d.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := st.DeleteWebhook(ctx, "w1"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+
+	flush(t, d, 1)
+
+	if len(rc.received) != 0 {
+		t.Errorf("received %d deliveries, want none", len(rc.received))
+	}
+	if delivery := onlyDelivery(t, st); delivery.Status != model.DeliveryDead {
+		t.Errorf("delivery = %+v, want dead", delivery)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{Backoff: 30 * time.Second, MaxBackoff: 10 * time.Minute}
+
+	for failures, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		6:  10 * time.Minute,
+		50: 10 * time.Minute,
+	} {
+		if got := d.backoff(failures); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
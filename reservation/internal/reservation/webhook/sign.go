@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of a delivery request
+const (
+	SignatureHeader = "X-ReservePark-Signature"
+	EventHeader     = "X-ReservePark-Event"
+	DeliveryHeader  = "X-ReservePark-Delivery"
+)
+
+var (
+	ErrMalformedSignature = errors.New("malformed signature header")
+	ErrSignatureMismatch  = errors.New("signature doesn't match the body")
+	ErrSignatureExpired   = errors.New("signature timestamp is outside the tolerance")
+)
+
+// Sign returns the signature header of a body sent at the moment, t=<unix seconds>,v1=<hex>.
+// The HMAC-SHA256 covers "<unix seconds>.<body>", so a captured request can't be
+// replayed later with a fresh timestamp.
+func Sign(secret string, at time.Time, body []byte) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// Verify checks the signature header of a received body, as a receiver would. A signature
+// older or newer than tolerance is rejected, a zero tolerance skips the check.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrMalformedSignature
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig, err := hex.DecodeString(value)
+			if err != nil {
+				return ErrMalformedSignature
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrMalformedSignature
+	}
+
+	if tolerance > 0 {
+		if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	// One matching v1 value is enough, so the header can carry several
+	expected := mac(secret, ts, body)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	at := time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"ReservationCreated"}`)
+	header := Sign("0123456789abcdef", at, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{"valid", "0123456789abcdef", header, body, at.Add(time.Minute), nil},
+		{"another secret", "fedcba9876543210", header, body, at, ErrSignatureMismatch},
+		{"changed body", "0123456789abcdef", header, []byte(`{"type":"ReservationDeleted"}`), at, ErrSignatureMismatch},
+		{"too old", "0123456789abcdef", header, body, at.Add(10 * time.Minute), ErrSignatureExpired},
+		{"several signatures", "0123456789abcdef", header + ",v1=00ff", body, at, nil},
+		{"no timestamp", "0123456789abcdef", "v1=00ff", body, at, ErrMalformedSignature},
+		{"not hex", "0123456789abcdef", "t=1,v1=zz", body, at, ErrMalformedSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
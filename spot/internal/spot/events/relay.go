@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/logger"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/metrics"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
+)
+
+// Relay moves the events from the outbox to the broker. Delivery is at least
+// once: an event published right before a crash, or by two instances at the
+// same time, is published again, so consumers should deduplicate on its ID.
+type Relay struct {
+	Outbox    store.Outbox
+	Broker    Broker
+	Logger    *logger.Logger
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewRelay(log *logger.Logger, outbox store.Outbox, broker Broker, interval time.Duration) *Relay {
+	return &Relay{
+		Outbox:    outbox,
+		Broker:    broker,
+		Logger:    log,
+		Interval:  interval,
+		BatchSize: 100,
+	}
+}
+
+// Run polls the outbox until the context is canceled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			r.Logger.ErrorContext(ctx, "Failed to publish events", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes the pending events oldest first and returns how many were published.
+// It stops at the first failure so later events don't overtake the failed one.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	published := 0
+
+	for {
+		events, err := r.Outbox.PendingEvents(ctx, r.BatchSize)
+		if err != nil {
+			return published, err
+		}
+
+		var ids []string
+		var publishErr error
+		for _, event := range events {
+			if publishErr = r.Broker.Publish(ctx, event); publishErr != nil {
+				metrics.EventPublishErrors.Inc()
+				break
+			}
+			metrics.EventsPublished.WithLabelValues(event.Type).Inc()
+			ids = append(ids, event.ID)
+		}
+
+		if len(ids) > 0 {
+			if err := r.Outbox.MarkPublished(ctx, ids); err != nil {
+				return published, err
+			}
+			published += len(ids)
+		}
+
+		if publishErr != nil {
+			return published, publishErr
+		}
+		if len(events) < r.BatchSize {
+			return published, nil
+		}
+	}
+}
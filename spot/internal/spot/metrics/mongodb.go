@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var (
+	mongoDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongodb_operation_duration_seconds",
+		Help:    "Latency of MongoDB commands.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"command"})
+
+	mongoErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongodb_operation_errors_total",
+		Help: "Number of failed MongoDB commands.",
+	}, []string{"command"})
+)
+
+// MongoMonitor records command latency and errors and then hands the event
+// over to the next monitor, so it can be chained with the tracing one.
+func MongoMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			if next != nil && next.Started != nil {
+				next.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			mongoDuration.WithLabelValues(evt.CommandName).Observe(evt.Duration.Seconds())
+			if next != nil && next.Succeeded != nil {
+				next.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			mongoDuration.WithLabelValues(evt.CommandName).Observe(evt.Duration.Seconds())
+			mongoErrors.WithLabelValues(evt.CommandName).Inc()
+			if next != nil && next.Failed != nil {
+				next.Failed(ctx, evt)
+			}
+		},
+	}
+}
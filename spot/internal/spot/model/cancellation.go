@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type PolicyScope string
+
+const (
+	PolicyScopeSpot PolicyScope = "spot"
+	PolicyScopeLot  PolicyScope = "lot"
+
+	// PolicyScopeDefault marks the policy of spots that have none, nor their lot
+	PolicyScopeDefault PolicyScope = "default"
+)
+
+// CancellationPolicy decides how much of the price is refunded when a reservation of a
+// spot, or of any spot of a lot, is canceled. Canceling at least FreeUntilHours before the
+// start is free, later LateFeePercent of the price is kept, and within NonRefundableMinutes
+// of the start, or once it started, nothing is refunded.
+type CancellationPolicy struct {
+	Scope                PolicyScope `json:"scope" bson:"scope" validate:"required,oneof=spot lot"`
+	TargetID             string      `json:"target_id" bson:"target_id" validate:"required"`
+	FreeUntilHours       int         `json:"free_until_hours" bson:"free_until_hours" validate:"gte=0"`
+	LateFeePercent       float64     `json:"late_fee_percent" bson:"late_fee_percent" validate:"gte=0,lte=100"`
+	NonRefundableMinutes int         `json:"non_refundable_minutes" bson:"non_refundable_minutes" validate:"gte=0"`
+	UpdatedAt            time.Time   `json:"updated_at" bson:"updated_at"`
+}
+
+// DefaultCancellationPolicy applies to spots without a policy of their own or of their lot:
+// canceling is free until the reservation starts
+func DefaultCancellationPolicy(spotID string) CancellationPolicy {
+	return CancellationPolicy{Scope: PolicyScopeDefault, TargetID: spotID}
+}
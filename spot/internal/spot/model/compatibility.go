@@ -0,0 +1,73 @@
+package model
+
+import "fmt"
+
+type VehicleProfile struct {
+	Size     SizeType `json:"size" validate:"required,oneof=small medium large"`
+	IsEV     bool     `json:"is_ev"`
+	HeightCm int      `json:"height_cm" validate:"omitempty,gt=0"`
+}
+
+var sizeRank = map[SizeType]int{
+	SizeSmall:  1,
+	SizeMedium: 2,
+	SizeLarge:  3,
+}
+
+// Incompatibility returns the reason why the vehicle can't use the spot,
+// or an empty string if the vehicle fits.
+func (s Spot) Incompatibility(vehicle VehicleProfile) string {
+	if sizeRank[vehicle.Size] > sizeRank[s.Size] {
+		return fmt.Sprintf("%s vehicle does not fit a %s spot", vehicle.Size, s.Size)
+	}
+
+	if s.Type == SpotTypeEV && !vehicle.IsEV {
+		return "spot is reserved for electric vehicles"
+	}
+
+	if s.MaxHeightCm > 0 && vehicle.HeightCm == 0 {
+		return fmt.Sprintf("vehicle height is unknown and the spot has a %dcm limit", s.MaxHeightCm)
+	}
+	if s.MaxHeightCm > 0 && vehicle.HeightCm > s.MaxHeightCm {
+		return fmt.Sprintf("vehicle height %dcm exceeds the %dcm limit", vehicle.HeightCm, s.MaxHeightCm)
+	}
+
+	return ""
+}
+
+type CompatibilityResult struct {
+	Compatible   []string          `json:"compatible"`
+	Incompatible map[string]string `json:"incompatible"`
+	NotFound     []string          `json:"not_found"`
+}
+
+// NewCompatibilityResult sorts the requested spots into compatible, incompatible
+// and not found ones, keeping the order of spotIDs. Spots lists the spots that exist.
+func NewCompatibilityResult(spotIDs []string, spots []Spot, vehicle VehicleProfile) CompatibilityResult {
+	result := CompatibilityResult{
+		Compatible:   []string{},
+		Incompatible: map[string]string{},
+		NotFound:     []string{},
+	}
+
+	foundSpots := make(map[string]Spot)
+	for _, spot := range spots {
+		foundSpots[spot.SpotID] = spot
+	}
+
+	for _, spotID := range spotIDs {
+		spot, exists := foundSpots[spotID]
+		if !exists {
+			result.NotFound = append(result.NotFound, spotID)
+			continue
+		}
+
+		if reason := spot.Incompatibility(vehicle); reason != "" {
+			result.Incompatible[spotID] = reason
+			continue
+		}
+		result.Compatible = append(result.Compatible, spotID)
+	}
+
+	return result
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventSource names the spot service in the events it publishes
+const EventSource = "spot"
+
+// Event types published by the spot service
+const (
+	EventSpotCreated = "SpotCreated"
+	EventSpotUpdated = "SpotUpdated"
+	EventSpotDeleted = "SpotDeleted"
+
+	// EventSpotPriceChanged follows SpotUpdated when the hourly price changed
+	EventSpotPriceChanged = "SpotPriceChanged"
+)
+
+// Event is a domain event. It is written to the outbox together with the change
+// it describes and published from there by the events relay.
+type Event struct {
+	ID          string          `json:"id" bson:"_id"`
+	Type        string          `json:"type" bson:"type"`
+	Source      string          `json:"source" bson:"source"`
+	AggregateID string          `json:"aggregate_id" bson:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at" bson:"occurred_at"`
+	Payload     json.RawMessage `json:"payload" bson:"payload"`
+}
+
+// PriceChange is the payload of EventSpotPriceChanged
+type PriceChange struct {
+	SpotID          string  `json:"spot_id"`
+	OldPricePerHour float64 `json:"old_price_per_hour"`
+	NewPricePerHour float64 `json:"new_price_per_hour"`
+}
+
+// NewEvent describes a change of the aggregate, the payload is stored as JSON
+func NewEvent(eventType, aggregateID string, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		Source:      EventSource,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now().UTC(),
+		Payload:     data,
+	}, nil
+}
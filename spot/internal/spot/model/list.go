@@ -0,0 +1,29 @@
+package model
+
+import "errors"
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+var (
+	// ErrNotFound is returned by the stores when the requested document doesn't exist
+	ErrNotFound = errors.New("not found")
+
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+type ListOptions struct {
+	Limit        int64
+	Cursor       string
+	SortBy       string
+	Descending   bool
+	IncludeTotal bool
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	TotalCount *int64 `json:"total_count,omitempty"`
+}
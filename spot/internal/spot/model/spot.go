@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SizeType string
+
+const (
+	SizeSmall  SizeType = "small"
+	SizeMedium SizeType = "medium"
+	SizeLarge  SizeType = "large"
+)
+
+type SpotType string
+
+const (
+	SpotTypeIndoor  SpotType = "indoor"
+	SpotTypeOutdoor SpotType = "outdoor"
+	SpotTypeEV      SpotType = "ev"
+)
+
+type Spot struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	SpotID       string             `json:"spot_id" bson:"spot_id" validate:"required"`
+	LotID        string             `json:"lot_id,omitempty" bson:"lot_id,omitempty"`
+	Latitude     float64            `json:"latitude" bson:"latitude" validate:"required"`
+	Longitude    float64            `json:"longitude" bson:"longitude" validate:"required"`
+	PricePerHour float64            `json:"price_per_hour" bson:"price_per_hour" validate:"required,gt=0"`
+	Size         SizeType           `json:"size" bson:"size" validate:"required,oneof=small medium large"`
+	Type         SpotType           `json:"type" bson:"type" validate:"required,oneof=indoor outdoor ev"`
+	MaxHeightCm  int                `json:"max_height_cm,omitempty" bson:"max_height_cm,omitempty" validate:"omitempty,gt=0"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at" validate:"required"`
+}
+
+// Price returns the cost of parking on the spot in the timeframe, truncated to cents.
+// A timeframe too short to cost a cent still costs one, so no booking is free.
+func (s Spot) Price(start, end time.Time) float64 {
+	diff := end.Sub(start).Hours()
+	price := float64(int(diff*s.PricePerHour*100)) / 100
+	if price == 0 && diff > 0 && s.PricePerHour > 0 {
+		return 0.01
+	}
+	return price
+}
+
+type SpotFilter struct {
+	LotID string
+	Size  SizeType
+	Type  SpotType
+}
+
+type GetPriceInput struct {
+	SpotID    string    `json:"spot_id" bson:"spot_id" validate:"required"`
+	StartTime time.Time `json:"start_time" bson:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" bson:"end_time" validate:"required"`
+}
+
+type SearchInput struct {
+	LotID           string          `json:"lot_id"`
+	Size            SizeType        `json:"size" validate:"omitempty,oneof=small medium large"`
+	Type            SpotType        `json:"type" validate:"omitempty,oneof=indoor outdoor ev"`
+	MaxPricePerHour float64         `json:"max_price_per_hour" validate:"omitempty,gt=0"`
+	Vehicle         *VehicleProfile `json:"vehicle"`
+}
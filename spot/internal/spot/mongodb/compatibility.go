@@ -0,0 +1,29 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (m *MongoDB) CheckCompatibility(ctx context.Context, spotIDs []string, vehicle model.VehicleProfile) (model.CompatibilityResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"spot_id": bson.M{"$in": spotIDs}}
+
+	cursor, err := m.Collection.Find(ctx, filter)
+	if err != nil {
+		return model.CompatibilityResult{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var spots []model.Spot
+	if err := cursor.All(ctx, &spots); err != nil {
+		return model.CompatibilityResult{}, err
+	}
+
+	return model.NewCompatibilityResult(spotIDs, spots, vehicle), nil
+}
@@ -2,22 +2,39 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
+	"github.com/ciameksw/reserve-park/spot/internal/spot/metrics"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
+// MongoDB is the production store.SpotStore
+var _ store.SpotStore = (*MongoDB)(nil)
+
 type MongoDB struct {
 	Collection *mongo.Collection
+	Outbox     *mongo.Collection
+	Policies   *mongo.Collection
+
+	// Transactions is set when the deployment supports them. Without them a write and its
+	// outbox events are two writes and a crash in between loses the events or publishes
+	// events of a change that never happened, see withEvents. Connect refuses such a
+	// deployment unless MONGO_STANDALONE allows it.
+	Transactions bool
 }
 
-func Connect(uri string, name string) (*MongoDB, error) {
+// Connect connects to the database and migrates it. A standalone server, which has no
+// transactions, is refused unless standalone is set, e.g. for development.
+func Connect(uri string, name string, standalone bool) (*MongoDB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(metrics.MongoMonitor(otelmongo.NewMonitor())))
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +44,36 @@ func Connect(uri string, name string) (*MongoDB, error) {
 		return nil, err
 	}
 
-	return &MongoDB{Collection: client.Database(name).Collection(name)}, nil
+	db := &MongoDB{
+		Collection: client.Database(name).Collection(name),
+		Outbox:     client.Database(name).Collection(outboxCollection),
+		Policies:   client.Database(name).Collection(policiesCollection),
+	}
+
+	db.Transactions, err = supportsTransactions(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if !db.Transactions {
+		if !standalone {
+			return nil, errors.New("the MongoDB deployment doesn't support transactions, run a replica set or set MONGO_STANDALONE=true")
+		}
+		log.Printf("WARNING: MongoDB runs without transactions, writes and their outbox events are stored separately and a crash in between loses events")
+	}
+
+	// Index builds can take longer than a request on a populated collection
+	migrateCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := Migrate(migrateCtx, db.Collection); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (m *MongoDB) Ping(ctx context.Context) error {
+	return m.Collection.Database().Client().Ping(ctx, nil)
 }
 
 func (m *MongoDB) Disconnect() {
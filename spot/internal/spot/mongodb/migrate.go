@@ -0,0 +1,110 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationsCollection records the migrations applied to the service database
+const migrationsCollection = "schema_migrations"
+
+// codeNamespaceExists is the server error returned when creating a collection that exists
+const codeNamespaceExists = 48
+
+// migration is one versioned change to the collection. Up must be idempotent:
+// instances starting at the same time may both run it before either records it.
+type migration struct {
+	Version     string
+	Description string
+	Up          func(ctx context.Context, coll *mongo.Collection) error
+}
+
+type migrationRecord struct {
+	Version     string    `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Migrate applies the migrations that haven't run yet in version order and
+// records each one in the schema_migrations collection.
+func Migrate(ctx context.Context, coll *mongo.Collection) error {
+	records := coll.Database().Collection(migrationsCollection)
+
+	cursor, err := records.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	var applied []migrationRecord
+	if err := cursor.All(ctx, &applied); err != nil {
+		return err
+	}
+
+	done := make(map[string]struct{}, len(applied))
+	for _, record := range applied {
+		done[record.Version] = struct{}{}
+	}
+
+	for _, mig := range migrations {
+		if _, ok := done[mig.Version]; ok {
+			continue
+		}
+
+		if err := mig.Up(ctx, coll); err != nil {
+			return fmt.Errorf("migration %s: %w", mig.Version, err)
+		}
+
+		record := migrationRecord{Version: mig.Version, Description: mig.Description, AppliedAt: time.Now()}
+		_, err := records.ReplaceOne(ctx, bson.M{"_id": mig.Version}, record, options.Replace().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", mig.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// createIndexes builds the indexes, an index that already exists with the same options is left alone
+func createIndexes(indexes ...mongo.IndexModel) func(ctx context.Context, coll *mongo.Collection) error {
+	return func(ctx context.Context, coll *mongo.Collection) error {
+		_, err := coll.Indexes().CreateMany(ctx, indexes)
+		return err
+	}
+}
+
+// createIndexesIn works like createIndexes on another collection of the service database
+func createIndexesIn(name string, indexes ...mongo.IndexModel) func(ctx context.Context, coll *mongo.Collection) error {
+	return func(ctx context.Context, coll *mongo.Collection) error {
+		_, err := coll.Database().Collection(name).Indexes().CreateMany(ctx, indexes)
+		return err
+	}
+}
+
+// setValidator sets the collection's JSON schema validator, creating the collection if needed.
+// The moderate level leaves invalid documents written before the validator alone until they're updated.
+func setValidator(schema bson.M) func(ctx context.Context, coll *mongo.Collection) error {
+	return func(ctx context.Context, coll *mongo.Collection) error {
+		err := coll.Database().CreateCollection(ctx, coll.Name())
+		if err != nil && !hasCode(err, codeNamespaceExists) {
+			return err
+		}
+
+		return coll.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: coll.Name()},
+			{Key: "validator", Value: bson.M{"$jsonSchema": schema}},
+			{Key: "validationLevel", Value: "moderate"},
+			{Key: "validationAction", Value: "error"},
+		}).Err()
+	}
+}
+
+func hasCode(err error, code int) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && int(cmdErr.Code) == code
+}
@@ -0,0 +1,38 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := ConnectMock()
+	if err != nil {
+		t.Skipf("mock MongoDB is not available: %v", err)
+	}
+	defer db.Disconnect()
+
+	ctx := context.Background()
+
+	// Running twice must be a no-op the second time
+	for i := 0; i < 2; i++ {
+		if err := Migrate(ctx, db.Collection); err != nil {
+			t.Fatalf("Migrate run %d: %v", i+1, err)
+		}
+	}
+
+	count, err := db.Collection.Database().Collection(migrationsCollection).CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatalf("Failed to count migrations: %v", err)
+	}
+	if count != int64(len(migrations)) {
+		t.Errorf("got %d migration records, want %d", count, len(migrations))
+	}
+
+	// The validator rejects documents the service would never write
+	if _, err := db.Collection.InsertOne(ctx, bson.M{"status": "unknown"}); err == nil {
+		t.Error("InsertOne of an invalid document succeeded")
+	}
+}
@@ -0,0 +1,53 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations lists the schema changes of the spots collection in the order they are applied.
+// Released migrations must not be edited, add a new one instead.
+var migrations = []migration{
+	{
+		Version:     "0001",
+		Description: "unique spot_id, lot and search indexes",
+		Up: createIndexes(
+			mongo.IndexModel{Keys: bson.D{{Key: "spot_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			mongo.IndexModel{Keys: bson.D{{Key: "lot_id", Value: 1}, {Key: "_id", Value: 1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "size", Value: 1}, {Key: "type", Value: 1}, {Key: "price_per_hour", Value: 1}}},
+		),
+	},
+	{
+		Version:     "0002",
+		Description: "spots JSON schema validator",
+		Up: setValidator(bson.M{
+			"bsonType": "object",
+			"required": bson.A{"spot_id", "latitude", "longitude", "price_per_hour", "size", "type", "updated_at"},
+			"properties": bson.M{
+				"spot_id":        bson.M{"bsonType": "string", "minLength": 1},
+				"lot_id":         bson.M{"bsonType": "string"},
+				"latitude":       bson.M{"bsonType": "number", "minimum": -90, "maximum": 90},
+				"longitude":      bson.M{"bsonType": "number", "minimum": -180, "maximum": 180},
+				"price_per_hour": bson.M{"bsonType": "number", "exclusiveMinimum": true, "minimum": 0},
+				"size":           bson.M{"enum": bson.A{"small", "medium", "large"}},
+				"type":           bson.M{"enum": bson.A{"indoor", "outdoor", "ev"}},
+				"max_height_cm":  bson.M{"bsonType": "number", "minimum": 1},
+				"updated_at":     bson.M{"bsonType": "date"},
+			},
+		}),
+	},
+	{
+		// Creating the collection here also keeps it out of the first transaction,
+		// MongoDB before 4.4 can't create collections inside one
+		Version:     "0003",
+		Description: "outbox pending and retention indexes",
+		Up: createIndexesIn(outboxCollection,
+			mongo.IndexModel{Keys: bson.D{{Key: "published_at", Value: 1}, {Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "published_at", Value: 1}},
+				Options: options.Index().SetName("published_at_ttl").SetExpireAfterSeconds(int32(publishedRetention.Seconds())),
+			},
+		),
+	},
+}
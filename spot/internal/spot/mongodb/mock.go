@@ -25,7 +25,16 @@ func ConnectMock() (*MongoDB, error) {
 		return nil, err
 	}
 
-	collection := client.Database("mock").Collection("mock")
+	db := &MongoDB{
+		Collection: client.Database("mock").Collection("mock"),
+		Outbox:     client.Database("mock").Collection(outboxCollection),
+		Policies:   client.Database("mock").Collection(policiesCollection),
+	}
+
+	db.Transactions, err = supportsTransactions(ctx, client)
+	if err != nil {
+		return nil, err
+	}
 
-	return &MongoDB{Collection: collection}, nil
+	return db, nil
 }
@@ -2,120 +2,90 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type SizeType string
-
-const (
-	SizeSmall  SizeType = "small"
-	SizeMedium SizeType = "medium"
-	SizeLarge  SizeType = "large"
-)
-
-type SpotType string
-
-const (
-	SpotTypeIndoor  SpotType = "indoor"
-	SpotTypeOutdoor SpotType = "outdoor"
-	SpotTypeEV      SpotType = "ev"
-)
-
-type Spot struct {
-	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	SpotID       string             `json:"spot_id" bson:"spot_id" validate:"required"`
-	Latitude     float64            `json:"latitude" bson:"latitude" validate:"required"`
-	Longitude    float64            `json:"longitude" bson:"longitude" validate:"required"`
-	PricePerHour float64            `json:"price_per_hour" bson:"price_per_hour" validate:"required,gt=0"`
-	Size         SizeType           `json:"size" bson:"size" validate:"required,oneof=small medium large"`
-	Type         SpotType           `json:"type" bson:"type" validate:"required,oneof=indoor outdoor ev"`
-	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at" validate:"required"`
-}
-
-func (m *MongoDB) AddSpot(spot Spot) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) AddSpot(ctx context.Context, spot model.Spot, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	_, err := m.Collection.InsertOne(ctx, spot)
-	return err
+	return m.withEvents(ctx, events, func(ctx context.Context) error {
+		_, err := m.Collection.InsertOne(ctx, spot)
+		return err
+	})
 }
 
-func (m *MongoDB) EditSpot(input Spot) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) EditSpot(ctx context.Context, input model.Spot, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"spot_id": bson.M{"$eq": input.SpotID}}
 
-	res := m.Collection.FindOneAndReplace(ctx, filter, input)
-	return res.Err()
+	return notFound(m.withEvents(ctx, events, func(ctx context.Context) error {
+		return m.Collection.FindOneAndReplace(ctx, filter, input).Err()
+	}))
 }
 
-func (m *MongoDB) DeleteSpot(spotID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) DeleteSpot(ctx context.Context, spotID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"spot_id": bson.M{"$eq": spotID}}
 
-	res := m.Collection.FindOneAndDelete(ctx, filter)
-	return res.Err()
+	return notFound(m.withEvents(ctx, events, func(ctx context.Context) error {
+		return m.Collection.FindOneAndDelete(ctx, filter).Err()
+	}))
 }
 
-func (m *MongoDB) GetSpot(spotID string) (Spot, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) GetSpot(ctx context.Context, spotID string) (model.Spot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"spot_id": bson.M{"$eq": spotID}}
 
-	var spot Spot
+	var spot model.Spot
 	err := m.Collection.FindOne(ctx, filter).Decode(&spot)
-	return spot, err
+	return spot, notFound(err)
 }
 
-func (m *MongoDB) GetAll() ([]Spot, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cursor, err := m.Collection.Find(ctx, bson.M{})
-	if err != nil {
-		return nil, err
+// GetAll returns one page of spots matching the filter.
+func (m *MongoDB) GetAll(ctx context.Context, input model.SpotFilter, opts model.ListOptions) (model.Page[model.Spot], error) {
+	filter := bson.M{}
+	if input.LotID != "" {
+		filter["lot_id"] = bson.M{"$eq": input.LotID}
+	}
+	if input.Size != "" {
+		filter["size"] = bson.M{"$eq": input.Size}
+	}
+	if input.Type != "" {
+		filter["type"] = bson.M{"$eq": input.Type}
 	}
-	defer cursor.Close(ctx)
-
-	var spots []Spot
-	err = cursor.All(ctx, &spots)
-	return spots, err
-}
 
-type GetPriceInput struct {
-	SpotID    string    `json:"spot_id" bson:"spot_id" validate:"required"`
-	StartTime time.Time `json:"start_time" bson:"start_time" validate:"required"`
-	EndTime   time.Time `json:"end_time" bson:"end_time" validate:"required"`
+	return findPage[model.Spot](ctx, m.Collection, filter, opts)
 }
 
-func (m *MongoDB) GetPrice(input GetPriceInput) (float64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) GetPrice(ctx context.Context, input model.GetPriceInput) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"spot_id": bson.M{"$eq": input.SpotID}}
 
-	var spot Spot
+	var spot model.Spot
 	err := m.Collection.FindOne(ctx, filter).Decode(&spot)
 	if err != nil {
-		return 0, err
+		return 0, notFound(err)
 	}
 
-	diff := input.EndTime.Sub(input.StartTime).Hours()
-	price := diff * spot.PricePerHour
-	price = float64(int(price*100)) / 100
-
-	return price, nil
+	return spot.Price(input.StartTime, input.EndTime), nil
 }
 
-func (m *MongoDB) CheckSpotsExist(spotIDs []string) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) CheckSpotsExist(ctx context.Context, spotIDs []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"spot_id": bson.M{"$in": spotIDs}}
@@ -126,7 +96,7 @@ func (m *MongoDB) CheckSpotsExist(spotIDs []string) ([]string, error) {
 	}
 	defer cursor.Close(ctx)
 
-	var spots []Spot
+	var spots []model.Spot
 	if err := cursor.All(ctx, &spots); err != nil {
 		return nil, err
 	}
@@ -147,3 +117,55 @@ func (m *MongoDB) CheckSpotsExist(spotIDs []string) ([]string, error) {
 
 	return notFound, nil
 }
+
+func (m *MongoDB) SearchSpots(ctx context.Context, input model.SearchInput) ([]model.Spot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if input.LotID != "" {
+		filter["lot_id"] = bson.M{"$eq": input.LotID}
+	}
+	if input.Size != "" {
+		filter["size"] = bson.M{"$eq": input.Size}
+	}
+	if input.Type != "" {
+		filter["type"] = bson.M{"$eq": input.Type}
+	}
+	if input.MaxPricePerHour > 0 {
+		filter["price_per_hour"] = bson.M{"$lte": input.MaxPricePerHour}
+	}
+
+	cursor, err := m.Collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var spots []model.Spot
+	if err := cursor.All(ctx, &spots); err != nil {
+		return nil, err
+	}
+
+	if input.Vehicle == nil {
+		return spots, nil
+	}
+
+	// Drop spots the vehicle can't use
+	var compatible []model.Spot
+	for _, spot := range spots {
+		if spot.Incompatibility(*input.Vehicle) == "" {
+			compatible = append(compatible, spot)
+		}
+	}
+
+	return compatible, nil
+}
+
+// notFound translates the driver's missing document error into the store-neutral model.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const policiesCollection = "cancellation_policies"
+
+// policyDocument keys the policy by its scope and target, so a target can't get two of one scope
+type policyDocument struct {
+	ID                       string `bson:"_id"`
+	model.CancellationPolicy `bson:",inline"`
+}
+
+func policyID(scope model.PolicyScope, targetID string) string {
+	return string(scope) + ":" + targetID
+}
+
+func (m *MongoDB) SetCancellationPolicy(ctx context.Context, policy model.CancellationPolicy) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	doc := policyDocument{ID: policyID(policy.Scope, policy.TargetID), CancellationPolicy: policy}
+	filter := bson.M{"_id": bson.M{"$eq": doc.ID}}
+	_, err := m.Policies.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (m *MongoDB) GetCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) (model.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var doc policyDocument
+	filter := bson.M{"_id": bson.M{"$eq": policyID(scope, targetID)}}
+	if err := m.Policies.FindOne(ctx, filter).Decode(&doc); err != nil {
+		return model.CancellationPolicy{}, notFound(err)
+	}
+	return doc.CancellationPolicy, nil
+}
+
+func (m *MongoDB) DeleteCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := m.Policies.DeleteOne(ctx, bson.M{"_id": bson.M{"$eq": policyID(scope, targetID)}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (m *MongoDB) GetCancellationPolicies(ctx context.Context) ([]model.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "scope", Value: 1}, {Key: "target_id", Value: 1}})
+	cursor, err := m.Policies.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []policyDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	policies := make([]model.CancellationPolicy, len(docs))
+	for i, doc := range docs {
+		policies[i] = doc.CancellationPolicy
+	}
+	return policies, nil
+}
@@ -0,0 +1,30 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	db, err := ConnectMock()
+	if err != nil {
+		t.Skipf("mock MongoDB is not available: %v", err)
+	}
+	defer db.Disconnect()
+
+	storetest.Run(t, func(t *testing.T) store.SpotStore {
+		if err := db.Collection.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop collection: %v", err)
+		}
+		if err := db.Outbox.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop outbox: %v", err)
+		}
+		if err := db.Policies.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop cancellation policies: %v", err)
+		}
+		return db
+	})
+}
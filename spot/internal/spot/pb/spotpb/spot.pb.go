@@ -0,0 +1,1859 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        (unknown)
+// source: reservepark/spot/v1/spot.proto
+
+package spotpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Spot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SpotId       string                 `protobuf:"bytes,2,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+	LotId        string                 `protobuf:"bytes,3,opt,name=lot_id,json=lotId,proto3" json:"lot_id,omitempty"`
+	Latitude     float64                `protobuf:"fixed64,4,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude    float64                `protobuf:"fixed64,5,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	PricePerHour float64                `protobuf:"fixed64,6,opt,name=price_per_hour,json=pricePerHour,proto3" json:"price_per_hour,omitempty"`
+	Size         string                 `protobuf:"bytes,7,opt,name=size,proto3" json:"size,omitempty"`
+	Type         string                 `protobuf:"bytes,8,opt,name=type,proto3" json:"type,omitempty"`
+	MaxHeightCm  int32                  `protobuf:"varint,9,opt,name=max_height_cm,json=maxHeightCm,proto3" json:"max_height_cm,omitempty"`
+	UpdatedAt    *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *Spot) Reset() {
+	*x = Spot{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Spot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Spot) ProtoMessage() {}
+
+func (x *Spot) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Spot.ProtoReflect.Descriptor instead.
+func (*Spot) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Spot) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Spot) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+func (x *Spot) GetLotId() string {
+	if x != nil {
+		return x.LotId
+	}
+	return ""
+}
+
+func (x *Spot) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Spot) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Spot) GetPricePerHour() float64 {
+	if x != nil {
+		return x.PricePerHour
+	}
+	return 0
+}
+
+func (x *Spot) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *Spot) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Spot) GetMaxHeightCm() int32 {
+	if x != nil {
+		return x.MaxHeightCm
+	}
+	return 0
+}
+
+func (x *Spot) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type VehicleProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Size     string `protobuf:"bytes,1,opt,name=size,proto3" json:"size,omitempty"`
+	IsEv     bool   `protobuf:"varint,2,opt,name=is_ev,json=isEv,proto3" json:"is_ev,omitempty"`
+	HeightCm int32  `protobuf:"varint,3,opt,name=height_cm,json=heightCm,proto3" json:"height_cm,omitempty"`
+}
+
+func (x *VehicleProfile) Reset() {
+	*x = VehicleProfile{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VehicleProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VehicleProfile) ProtoMessage() {}
+
+func (x *VehicleProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VehicleProfile.ProtoReflect.Descriptor instead.
+func (*VehicleProfile) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VehicleProfile) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *VehicleProfile) GetIsEv() bool {
+	if x != nil {
+		return x.IsEv
+	}
+	return false
+}
+
+func (x *VehicleProfile) GetHeightCm() int32 {
+	if x != nil {
+		return x.HeightCm
+	}
+	return 0
+}
+
+// ListOptions mirrors the limit, cursor, sort, order and include_total query parameters.
+type ListOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Limit        int64  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor       string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Sort         string `protobuf:"bytes,3,opt,name=sort,proto3" json:"sort,omitempty"`
+	Order        string `protobuf:"bytes,4,opt,name=order,proto3" json:"order,omitempty"`
+	IncludeTotal bool   `protobuf:"varint,5,opt,name=include_total,json=includeTotal,proto3" json:"include_total,omitempty"`
+}
+
+func (x *ListOptions) Reset() {
+	*x = ListOptions{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOptions) ProtoMessage() {}
+
+func (x *ListOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOptions.ProtoReflect.Descriptor instead.
+func (*ListOptions) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListOptions) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListOptions) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListOptions) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListOptions) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *ListOptions) GetIncludeTotal() bool {
+	if x != nil {
+		return x.IncludeTotal
+	}
+	return false
+}
+
+type AddSpotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LotId        string  `protobuf:"bytes,1,opt,name=lot_id,json=lotId,proto3" json:"lot_id,omitempty"`
+	Latitude     float64 `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude    float64 `protobuf:"fixed64,3,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	PricePerHour float64 `protobuf:"fixed64,4,opt,name=price_per_hour,json=pricePerHour,proto3" json:"price_per_hour,omitempty"`
+	Size         string  `protobuf:"bytes,5,opt,name=size,proto3" json:"size,omitempty"`
+	Type         string  `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`
+	MaxHeightCm  int32   `protobuf:"varint,7,opt,name=max_height_cm,json=maxHeightCm,proto3" json:"max_height_cm,omitempty"`
+}
+
+func (x *AddSpotRequest) Reset() {
+	*x = AddSpotRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSpotRequest) ProtoMessage() {}
+
+func (x *AddSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSpotRequest.ProtoReflect.Descriptor instead.
+func (*AddSpotRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AddSpotRequest) GetLotId() string {
+	if x != nil {
+		return x.LotId
+	}
+	return ""
+}
+
+func (x *AddSpotRequest) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *AddSpotRequest) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *AddSpotRequest) GetPricePerHour() float64 {
+	if x != nil {
+		return x.PricePerHour
+	}
+	return 0
+}
+
+func (x *AddSpotRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *AddSpotRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AddSpotRequest) GetMaxHeightCm() int32 {
+	if x != nil {
+		return x.MaxHeightCm
+	}
+	return 0
+}
+
+type AddSpotResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotId string `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+}
+
+func (x *AddSpotResponse) Reset() {
+	*x = AddSpotResponse{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSpotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSpotResponse) ProtoMessage() {}
+
+func (x *AddSpotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSpotResponse.ProtoReflect.Descriptor instead.
+func (*AddSpotResponse) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AddSpotResponse) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+// Unset fields are left unchanged, a max_height_cm of 0 removes the limit.
+type EditSpotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotId       string   `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+	LotId        *string  `protobuf:"bytes,2,opt,name=lot_id,json=lotId,proto3,oneof" json:"lot_id,omitempty"`
+	Latitude     *float64 `protobuf:"fixed64,3,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude    *float64 `protobuf:"fixed64,4,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	PricePerHour *float64 `protobuf:"fixed64,5,opt,name=price_per_hour,json=pricePerHour,proto3,oneof" json:"price_per_hour,omitempty"`
+	Size         string   `protobuf:"bytes,6,opt,name=size,proto3" json:"size,omitempty"`
+	Type         string   `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	MaxHeightCm  *int32   `protobuf:"varint,8,opt,name=max_height_cm,json=maxHeightCm,proto3,oneof" json:"max_height_cm,omitempty"`
+}
+
+func (x *EditSpotRequest) Reset() {
+	*x = EditSpotRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditSpotRequest) ProtoMessage() {}
+
+func (x *EditSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditSpotRequest.ProtoReflect.Descriptor instead.
+func (*EditSpotRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EditSpotRequest) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+func (x *EditSpotRequest) GetLotId() string {
+	if x != nil && x.LotId != nil {
+		return *x.LotId
+	}
+	return ""
+}
+
+func (x *EditSpotRequest) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *EditSpotRequest) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *EditSpotRequest) GetPricePerHour() float64 {
+	if x != nil && x.PricePerHour != nil {
+		return *x.PricePerHour
+	}
+	return 0
+}
+
+func (x *EditSpotRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *EditSpotRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EditSpotRequest) GetMaxHeightCm() int32 {
+	if x != nil && x.MaxHeightCm != nil {
+		return *x.MaxHeightCm
+	}
+	return 0
+}
+
+type DeleteSpotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotId string `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+}
+
+func (x *DeleteSpotRequest) Reset() {
+	*x = DeleteSpotRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSpotRequest) ProtoMessage() {}
+
+func (x *DeleteSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSpotRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSpotRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteSpotRequest) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+type GetSpotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotId string `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+}
+
+func (x *GetSpotRequest) Reset() {
+	*x = GetSpotRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSpotRequest) ProtoMessage() {}
+
+func (x *GetSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSpotRequest.ProtoReflect.Descriptor instead.
+func (*GetSpotRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetSpotRequest) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+type ListSpotsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LotId   string       `protobuf:"bytes,1,opt,name=lot_id,json=lotId,proto3" json:"lot_id,omitempty"`
+	Size    string       `protobuf:"bytes,2,opt,name=size,proto3" json:"size,omitempty"`
+	Type    string       `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Options *ListOptions `protobuf:"bytes,4,opt,name=options,proto3" json:"options,omitempty"`
+}
+
+func (x *ListSpotsRequest) Reset() {
+	*x = ListSpotsRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSpotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSpotsRequest) ProtoMessage() {}
+
+func (x *ListSpotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSpotsRequest.ProtoReflect.Descriptor instead.
+func (*ListSpotsRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListSpotsRequest) GetLotId() string {
+	if x != nil {
+		return x.LotId
+	}
+	return ""
+}
+
+func (x *ListSpotsRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *ListSpotsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListSpotsRequest) GetOptions() *ListOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type ListSpotsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items      []*Spot `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	NextCursor string  `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	TotalCount *int64  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3,oneof" json:"total_count,omitempty"`
+}
+
+func (x *ListSpotsResponse) Reset() {
+	*x = ListSpotsResponse{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSpotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSpotsResponse) ProtoMessage() {}
+
+func (x *ListSpotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSpotsResponse.ProtoReflect.Descriptor instead.
+func (*ListSpotsResponse) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListSpotsResponse) GetItems() []*Spot {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListSpotsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListSpotsResponse) GetTotalCount() int64 {
+	if x != nil && x.TotalCount != nil {
+		return *x.TotalCount
+	}
+	return 0
+}
+
+type GetPriceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotId    string                 `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+}
+
+func (x *GetPriceRequest) Reset() {
+	*x = GetPriceRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPriceRequest) ProtoMessage() {}
+
+func (x *GetPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPriceRequest.ProtoReflect.Descriptor instead.
+func (*GetPriceRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetPriceRequest) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+func (x *GetPriceRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetPriceRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type GetPriceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotId string  `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+	Price  float64 `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+}
+
+func (x *GetPriceResponse) Reset() {
+	*x = GetPriceResponse{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPriceResponse) ProtoMessage() {}
+
+func (x *GetPriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPriceResponse.ProtoReflect.Descriptor instead.
+func (*GetPriceResponse) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetPriceResponse) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+func (x *GetPriceResponse) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+type CheckSpotsExistRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotIds []string `protobuf:"bytes,1,rep,name=spot_ids,json=spotIds,proto3" json:"spot_ids,omitempty"`
+}
+
+func (x *CheckSpotsExistRequest) Reset() {
+	*x = CheckSpotsExistRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckSpotsExistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckSpotsExistRequest) ProtoMessage() {}
+
+func (x *CheckSpotsExistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckSpotsExistRequest.ProtoReflect.Descriptor instead.
+func (*CheckSpotsExistRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CheckSpotsExistRequest) GetSpotIds() []string {
+	if x != nil {
+		return x.SpotIds
+	}
+	return nil
+}
+
+type CheckSpotsExistResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AllExist bool     `protobuf:"varint,1,opt,name=all_exist,json=allExist,proto3" json:"all_exist,omitempty"`
+	NotFound []string `protobuf:"bytes,2,rep,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+}
+
+func (x *CheckSpotsExistResponse) Reset() {
+	*x = CheckSpotsExistResponse{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckSpotsExistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckSpotsExistResponse) ProtoMessage() {}
+
+func (x *CheckSpotsExistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckSpotsExistResponse.ProtoReflect.Descriptor instead.
+func (*CheckSpotsExistResponse) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CheckSpotsExistResponse) GetAllExist() bool {
+	if x != nil {
+		return x.AllExist
+	}
+	return false
+}
+
+func (x *CheckSpotsExistResponse) GetNotFound() []string {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
+type CheckCompatibilityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotIds []string        `protobuf:"bytes,1,rep,name=spot_ids,json=spotIds,proto3" json:"spot_ids,omitempty"`
+	Vehicle *VehicleProfile `protobuf:"bytes,2,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+}
+
+func (x *CheckCompatibilityRequest) Reset() {
+	*x = CheckCompatibilityRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckCompatibilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckCompatibilityRequest) ProtoMessage() {}
+
+func (x *CheckCompatibilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckCompatibilityRequest.ProtoReflect.Descriptor instead.
+func (*CheckCompatibilityRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CheckCompatibilityRequest) GetSpotIds() []string {
+	if x != nil {
+		return x.SpotIds
+	}
+	return nil
+}
+
+func (x *CheckCompatibilityRequest) GetVehicle() *VehicleProfile {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+type CheckCompatibilityResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Compatible []string `protobuf:"bytes,1,rep,name=compatible,proto3" json:"compatible,omitempty"`
+	// Reason why the vehicle can't use the spot, keyed by spot ID
+	Incompatible map[string]string `protobuf:"bytes,2,rep,name=incompatible,proto3" json:"incompatible,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	NotFound     []string          `protobuf:"bytes,3,rep,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+}
+
+func (x *CheckCompatibilityResponse) Reset() {
+	*x = CheckCompatibilityResponse{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckCompatibilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckCompatibilityResponse) ProtoMessage() {}
+
+func (x *CheckCompatibilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckCompatibilityResponse.ProtoReflect.Descriptor instead.
+func (*CheckCompatibilityResponse) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CheckCompatibilityResponse) GetCompatible() []string {
+	if x != nil {
+		return x.Compatible
+	}
+	return nil
+}
+
+func (x *CheckCompatibilityResponse) GetIncompatible() map[string]string {
+	if x != nil {
+		return x.Incompatible
+	}
+	return nil
+}
+
+func (x *CheckCompatibilityResponse) GetNotFound() []string {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
+type SearchSpotsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LotId           string  `protobuf:"bytes,1,opt,name=lot_id,json=lotId,proto3" json:"lot_id,omitempty"`
+	Size            string  `protobuf:"bytes,2,opt,name=size,proto3" json:"size,omitempty"`
+	Type            string  `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	MaxPricePerHour float64 `protobuf:"fixed64,4,opt,name=max_price_per_hour,json=maxPricePerHour,proto3" json:"max_price_per_hour,omitempty"`
+	// Only spots the vehicle fits are returned when set
+	Vehicle *VehicleProfile `protobuf:"bytes,5,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	// The spots are priced for the timeframe when both are set
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+}
+
+func (x *SearchSpotsRequest) Reset() {
+	*x = SearchSpotsRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchSpotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchSpotsRequest) ProtoMessage() {}
+
+func (x *SearchSpotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchSpotsRequest.ProtoReflect.Descriptor instead.
+func (*SearchSpotsRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SearchSpotsRequest) GetLotId() string {
+	if x != nil {
+		return x.LotId
+	}
+	return ""
+}
+
+func (x *SearchSpotsRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *SearchSpotsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SearchSpotsRequest) GetMaxPricePerHour() float64 {
+	if x != nil {
+		return x.MaxPricePerHour
+	}
+	return 0
+}
+
+func (x *SearchSpotsRequest) GetVehicle() *VehicleProfile {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+func (x *SearchSpotsRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *SearchSpotsRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type SearchSpotsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Spots []*Spot `protobuf:"bytes,1,rep,name=spots,proto3" json:"spots,omitempty"`
+	// Price of each spot by spot ID, see GetPrice
+	Prices map[string]float64 `protobuf:"bytes,2,rep,name=prices,proto3" json:"prices,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+}
+
+func (x *SearchSpotsResponse) Reset() {
+	*x = SearchSpotsResponse{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchSpotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchSpotsResponse) ProtoMessage() {}
+
+func (x *SearchSpotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchSpotsResponse.ProtoReflect.Descriptor instead.
+func (*SearchSpotsResponse) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SearchSpotsResponse) GetSpots() []*Spot {
+	if x != nil {
+		return x.Spots
+	}
+	return nil
+}
+
+func (x *SearchSpotsResponse) GetPrices() map[string]float64 {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+// CancellationPolicy is scoped to a "spot" or a "lot", resolved policies can also have the "default" scope.
+type CancellationPolicy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Scope                string                 `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	TargetId             string                 `protobuf:"bytes,2,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	FreeUntilHours       int32                  `protobuf:"varint,3,opt,name=free_until_hours,json=freeUntilHours,proto3" json:"free_until_hours,omitempty"`
+	LateFeePercent       float64                `protobuf:"fixed64,4,opt,name=late_fee_percent,json=lateFeePercent,proto3" json:"late_fee_percent,omitempty"`
+	NonRefundableMinutes int32                  `protobuf:"varint,5,opt,name=non_refundable_minutes,json=nonRefundableMinutes,proto3" json:"non_refundable_minutes,omitempty"`
+	UpdatedAt            *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *CancellationPolicy) Reset() {
+	*x = CancellationPolicy{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancellationPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancellationPolicy) ProtoMessage() {}
+
+func (x *CancellationPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancellationPolicy.ProtoReflect.Descriptor instead.
+func (*CancellationPolicy) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CancellationPolicy) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *CancellationPolicy) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *CancellationPolicy) GetFreeUntilHours() int32 {
+	if x != nil {
+		return x.FreeUntilHours
+	}
+	return 0
+}
+
+func (x *CancellationPolicy) GetLateFeePercent() float64 {
+	if x != nil {
+		return x.LateFeePercent
+	}
+	return 0
+}
+
+func (x *CancellationPolicy) GetNonRefundableMinutes() int32 {
+	if x != nil {
+		return x.NonRefundableMinutes
+	}
+	return 0
+}
+
+func (x *CancellationPolicy) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListCancellationPoliciesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Policies []*CancellationPolicy `protobuf:"bytes,1,rep,name=policies,proto3" json:"policies,omitempty"`
+}
+
+func (x *ListCancellationPoliciesResponse) Reset() {
+	*x = ListCancellationPoliciesResponse{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCancellationPoliciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCancellationPoliciesResponse) ProtoMessage() {}
+
+func (x *ListCancellationPoliciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCancellationPoliciesResponse.ProtoReflect.Descriptor instead.
+func (*ListCancellationPoliciesResponse) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListCancellationPoliciesResponse) GetPolicies() []*CancellationPolicy {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+type DeleteCancellationPolicyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Scope    string `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	TargetId string `protobuf:"bytes,2,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+}
+
+func (x *DeleteCancellationPolicyRequest) Reset() {
+	*x = DeleteCancellationPolicyRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCancellationPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCancellationPolicyRequest) ProtoMessage() {}
+
+func (x *DeleteCancellationPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCancellationPolicyRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCancellationPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeleteCancellationPolicyRequest) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *DeleteCancellationPolicyRequest) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+type GetCancellationPolicyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpotId string `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+}
+
+func (x *GetCancellationPolicyRequest) Reset() {
+	*x = GetCancellationPolicyRequest{}
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCancellationPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCancellationPolicyRequest) ProtoMessage() {}
+
+func (x *GetCancellationPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reservepark_spot_v1_spot_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCancellationPolicyRequest.ProtoReflect.Descriptor instead.
+func (*GetCancellationPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_reservepark_spot_v1_spot_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetCancellationPolicyRequest) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+var File_reservepark_spot_v1_spot_proto protoreflect.FileDescriptor
+
+var file_reservepark_spot_v1_spot_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2f, 0x73, 0x70,
+	0x6f, 0x74, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x13, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70,
+	0x6f, 0x74, 0x2e, 0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xad, 0x02, 0x0a, 0x04, 0x53, 0x70, 0x6f, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07,
+	0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x70, 0x6f, 0x74, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x74, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08,
+	0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67,
+	0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e,
+	0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f,
+	0x70, 0x65, 0x72, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x48, 0x6f, 0x75, 0x72, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x69, 0x7a, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x68, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d, 0x61, 0x78,
+	0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x22, 0x56, 0x0a, 0x0e, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x69, 0x73, 0x5f,
+	0x65, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x69, 0x73, 0x45, 0x76, 0x12, 0x1b,
+	0x0a, 0x09, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x22, 0x8a, 0x01, 0x0a, 0x0b,
+	0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6f, 0x72,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x72,
+	0x64, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x6e, 0x63, 0x6c,
+	0x75, 0x64, 0x65, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0xd3, 0x01, 0x0a, 0x0e, 0x41, 0x64, 0x64,
+	0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6c,
+	0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x74,
+	0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x24, 0x0a, 0x0e,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x70, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x48, 0x6f,
+	0x75, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61,
+	0x78, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x22, 0x2a,
+	0x0a, 0x0f, 0x41, 0x64, 0x64, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x70, 0x6f, 0x74, 0x49, 0x64, 0x22, 0xd1, 0x02, 0x0a, 0x0f, 0x45,
+	0x64, 0x69, 0x74, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x70, 0x6f, 0x74, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x06, 0x6c, 0x6f, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x05, 0x6c, 0x6f, 0x74, 0x49, 0x64,
+	0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69,
+	0x74, 0x75, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0e, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x5f, 0x70, 0x65, 0x72, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x03, 0x52, 0x0c, 0x70, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x48, 0x6f, 0x75, 0x72, 0x88,
+	0x01, 0x01, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x27, 0x0a, 0x0d, 0x6d, 0x61,
+	0x78, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x04, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d,
+	0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x42, 0x0b,
+	0x0a, 0x09, 0x5f, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x42, 0x0c, 0x0a, 0x0a, 0x5f,
+	0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x70, 0x72,
+	0x69, 0x63, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x42, 0x10, 0x0a, 0x0e,
+	0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x22, 0x2c,
+	0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x70, 0x6f, 0x74, 0x49, 0x64, 0x22, 0x29, 0x0a, 0x0e,
+	0x47, 0x65, 0x74, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x70, 0x6f, 0x74, 0x49, 0x64, 0x22, 0x8d, 0x01, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74,
+	0x53, 0x70, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06,
+	0x6c, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f,
+	0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x3a, 0x0a, 0x07, 0x6f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72,
+	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x9b, 0x01, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74,
+	0x53, 0x70, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a,
+	0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x72,
+	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12,
+	0x24, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x9c, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x50, 0x72, 0x69,
+	0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x70, 0x6f,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x70, 0x6f, 0x74,
+	0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a,
+	0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x65, 0x6e, 0x64,
+	0x54, 0x69, 0x6d, 0x65, 0x22, 0x41, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x70, 0x6f, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x70, 0x6f, 0x74, 0x49,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x22, 0x33, 0x0a, 0x16, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x53, 0x70, 0x6f, 0x74, 0x73, 0x45, 0x78, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x70, 0x6f, 0x74, 0x49, 0x64, 0x73, 0x22, 0x53, 0x0a, 0x17,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x45, 0x78, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x61, 0x6c, 0x6c, 0x5f, 0x65,
+	0x78, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x61, 0x6c, 0x6c, 0x45,
+	0x78, 0x69, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x66, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e,
+	0x64, 0x22, 0x75, 0x0a, 0x19, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74,
+	0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19,
+	0x0a, 0x08, 0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x73, 0x70, 0x6f, 0x74, 0x49, 0x64, 0x73, 0x12, 0x3d, 0x0a, 0x07, 0x76, 0x65, 0x68,
+	0x69, 0x63, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x72, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52,
+	0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x22, 0x81, 0x02, 0x0a, 0x1a, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61,
+	0x74, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6d,
+	0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x65, 0x0a, 0x0c, 0x69, 0x6e, 0x63, 0x6f, 0x6d,
+	0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x41, 0x2e,
+	0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x49,
+	0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x0c, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x1b,
+	0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x1a, 0x3f, 0x0a, 0x11, 0x49,
+	0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xb1, 0x02, 0x0a,
+	0x12, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x2b, 0x0a, 0x12, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f,
+	0x70, 0x65, 0x72, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f,
+	0x6d, 0x61, 0x78, 0x50, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x48, 0x6f, 0x75, 0x72, 0x12,
+	0x3d, 0x0a, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x23, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73,
+	0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x39,
+	0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x6e, 0x64,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65,
+	0x22, 0xcf, 0x01, 0x0a, 0x13, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x70, 0x6f, 0x74, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x05, 0x73, 0x70, 0x6f, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x70,
+	0x6f, 0x74, 0x52, 0x05, 0x73, 0x70, 0x6f, 0x74, 0x73, 0x12, 0x4c, 0x0a, 0x06, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x72, 0x65, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x2e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x06, 0x70, 0x72, 0x69, 0x63, 0x65, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x50, 0x72, 0x69, 0x63, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0x8c, 0x02, 0x0a, 0x12, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f,
+	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x10,
+	0x66, 0x72, 0x65, 0x65, 0x5f, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x66, 0x72, 0x65, 0x65, 0x55, 0x6e, 0x74, 0x69,
+	0x6c, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x6c, 0x61, 0x74, 0x65, 0x5f, 0x66,
+	0x65, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0e, 0x6c, 0x61, 0x74, 0x65, 0x46, 0x65, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x12, 0x34, 0x0a, 0x16, 0x6e, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x14, 0x6e, 0x6f, 0x6e, 0x52, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x61, 0x62, 0x6c, 0x65, 0x4d,
+	0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x22, 0x67, 0x0a, 0x20, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x08, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x52, 0x08, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x22, 0x54, 0x0a, 0x1f, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x63,
+	0x6f, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x49, 0x64,
+	0x22, 0x37, 0x0a, 0x1c, 0x47, 0x65, 0x74, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x73, 0x70, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x70, 0x6f, 0x74, 0x49, 0x64, 0x32, 0xf7, 0x09, 0x0a, 0x0b, 0x53, 0x70,
+	0x6f, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x54, 0x0a, 0x07, 0x41, 0x64, 0x64,
+	0x53, 0x70, 0x6f, 0x74, 0x12, 0x23, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61,
+	0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x64, 0x53, 0x70,
+	0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x72, 0x65, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x64, 0x64, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x48, 0x0a, 0x08, 0x45, 0x64, 0x69, 0x74, 0x53, 0x70, 0x6f, 0x74, 0x12, 0x24, 0x2e, 0x72, 0x65,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x64, 0x69, 0x74, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4c, 0x0a, 0x0a, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x53, 0x70, 0x6f, 0x74, 0x12, 0x26, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x53, 0x70, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x49, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x53, 0x70,
+	0x6f, 0x74, 0x12, 0x23, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b,
+	0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x70, 0x6f, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x70,
+	0x6f, 0x74, 0x12, 0x5a, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x12,
+	0x25, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70,
+	0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x57,
+	0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x24, 0x2e, 0x72, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x25, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73,
+	0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6c, 0x0a, 0x0f, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x53, 0x70, 0x6f, 0x74, 0x73, 0x45, 0x78, 0x69, 0x73, 0x74, 0x12, 0x2b, 0x2e, 0x72, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x45, 0x78, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x45, 0x78, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x75, 0x0a, 0x12, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f,
+	0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x2e, 0x2e, 0x72, 0x65,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2f, 0x2e, 0x72, 0x65,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x60, 0x0a, 0x0b,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x12, 0x27, 0x2e, 0x72, 0x65,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61,
+	0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x53, 0x70, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x69,
+	0x0a, 0x15, 0x53, 0x65, 0x74, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x27, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x1a, 0x27, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73,
+	0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x69, 0x0a, 0x18, 0x4c, 0x69, 0x73,
+	0x74, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x69, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x35, 0x2e,
+	0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x68, 0x0a, 0x18, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x12, 0x34, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73,
+	0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x61, 0x6e,
+	0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x73,
+	0x0a, 0x15, 0x47, 0x65, 0x74, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x31, 0x2e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x72, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x70, 0x61, 0x72, 0x6b, 0x2e, 0x73, 0x70, 0x6f, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_reservepark_spot_v1_spot_proto_rawDescOnce sync.Once
+	file_reservepark_spot_v1_spot_proto_rawDescData = file_reservepark_spot_v1_spot_proto_rawDesc
+)
+
+func file_reservepark_spot_v1_spot_proto_rawDescGZIP() []byte {
+	file_reservepark_spot_v1_spot_proto_rawDescOnce.Do(func() {
+		file_reservepark_spot_v1_spot_proto_rawDescData = protoimpl.X.CompressGZIP(file_reservepark_spot_v1_spot_proto_rawDescData)
+	})
+	return file_reservepark_spot_v1_spot_proto_rawDescData
+}
+
+var file_reservepark_spot_v1_spot_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_reservepark_spot_v1_spot_proto_goTypes = []any{
+	(*Spot)(nil),                             // 0: reservepark.spot.v1.Spot
+	(*VehicleProfile)(nil),                   // 1: reservepark.spot.v1.VehicleProfile
+	(*ListOptions)(nil),                      // 2: reservepark.spot.v1.ListOptions
+	(*AddSpotRequest)(nil),                   // 3: reservepark.spot.v1.AddSpotRequest
+	(*AddSpotResponse)(nil),                  // 4: reservepark.spot.v1.AddSpotResponse
+	(*EditSpotRequest)(nil),                  // 5: reservepark.spot.v1.EditSpotRequest
+	(*DeleteSpotRequest)(nil),                // 6: reservepark.spot.v1.DeleteSpotRequest
+	(*GetSpotRequest)(nil),                   // 7: reservepark.spot.v1.GetSpotRequest
+	(*ListSpotsRequest)(nil),                 // 8: reservepark.spot.v1.ListSpotsRequest
+	(*ListSpotsResponse)(nil),                // 9: reservepark.spot.v1.ListSpotsResponse
+	(*GetPriceRequest)(nil),                  // 10: reservepark.spot.v1.GetPriceRequest
+	(*GetPriceResponse)(nil),                 // 11: reservepark.spot.v1.GetPriceResponse
+	(*CheckSpotsExistRequest)(nil),           // 12: reservepark.spot.v1.CheckSpotsExistRequest
+	(*CheckSpotsExistResponse)(nil),          // 13: reservepark.spot.v1.CheckSpotsExistResponse
+	(*CheckCompatibilityRequest)(nil),        // 14: reservepark.spot.v1.CheckCompatibilityRequest
+	(*CheckCompatibilityResponse)(nil),       // 15: reservepark.spot.v1.CheckCompatibilityResponse
+	(*SearchSpotsRequest)(nil),               // 16: reservepark.spot.v1.SearchSpotsRequest
+	(*SearchSpotsResponse)(nil),              // 17: reservepark.spot.v1.SearchSpotsResponse
+	(*CancellationPolicy)(nil),               // 18: reservepark.spot.v1.CancellationPolicy
+	(*ListCancellationPoliciesResponse)(nil), // 19: reservepark.spot.v1.ListCancellationPoliciesResponse
+	(*DeleteCancellationPolicyRequest)(nil),  // 20: reservepark.spot.v1.DeleteCancellationPolicyRequest
+	(*GetCancellationPolicyRequest)(nil),     // 21: reservepark.spot.v1.GetCancellationPolicyRequest
+	nil,                                      // 22: reservepark.spot.v1.CheckCompatibilityResponse.IncompatibleEntry
+	nil,                                      // 23: reservepark.spot.v1.SearchSpotsResponse.PricesEntry
+	(*timestamppb.Timestamp)(nil),            // 24: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),                    // 25: google.protobuf.Empty
+}
+var file_reservepark_spot_v1_spot_proto_depIdxs = []int32{
+	24, // 0: reservepark.spot.v1.Spot.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 1: reservepark.spot.v1.ListSpotsRequest.options:type_name -> reservepark.spot.v1.ListOptions
+	0,  // 2: reservepark.spot.v1.ListSpotsResponse.items:type_name -> reservepark.spot.v1.Spot
+	24, // 3: reservepark.spot.v1.GetPriceRequest.start_time:type_name -> google.protobuf.Timestamp
+	24, // 4: reservepark.spot.v1.GetPriceRequest.end_time:type_name -> google.protobuf.Timestamp
+	1,  // 5: reservepark.spot.v1.CheckCompatibilityRequest.vehicle:type_name -> reservepark.spot.v1.VehicleProfile
+	22, // 6: reservepark.spot.v1.CheckCompatibilityResponse.incompatible:type_name -> reservepark.spot.v1.CheckCompatibilityResponse.IncompatibleEntry
+	1,  // 7: reservepark.spot.v1.SearchSpotsRequest.vehicle:type_name -> reservepark.spot.v1.VehicleProfile
+	24, // 8: reservepark.spot.v1.SearchSpotsRequest.start_time:type_name -> google.protobuf.Timestamp
+	24, // 9: reservepark.spot.v1.SearchSpotsRequest.end_time:type_name -> google.protobuf.Timestamp
+	0,  // 10: reservepark.spot.v1.SearchSpotsResponse.spots:type_name -> reservepark.spot.v1.Spot
+	23, // 11: reservepark.spot.v1.SearchSpotsResponse.prices:type_name -> reservepark.spot.v1.SearchSpotsResponse.PricesEntry
+	24, // 12: reservepark.spot.v1.CancellationPolicy.updated_at:type_name -> google.protobuf.Timestamp
+	18, // 13: reservepark.spot.v1.ListCancellationPoliciesResponse.policies:type_name -> reservepark.spot.v1.CancellationPolicy
+	3,  // 14: reservepark.spot.v1.SpotService.AddSpot:input_type -> reservepark.spot.v1.AddSpotRequest
+	5,  // 15: reservepark.spot.v1.SpotService.EditSpot:input_type -> reservepark.spot.v1.EditSpotRequest
+	6,  // 16: reservepark.spot.v1.SpotService.DeleteSpot:input_type -> reservepark.spot.v1.DeleteSpotRequest
+	7,  // 17: reservepark.spot.v1.SpotService.GetSpot:input_type -> reservepark.spot.v1.GetSpotRequest
+	8,  // 18: reservepark.spot.v1.SpotService.ListSpots:input_type -> reservepark.spot.v1.ListSpotsRequest
+	10, // 19: reservepark.spot.v1.SpotService.GetPrice:input_type -> reservepark.spot.v1.GetPriceRequest
+	12, // 20: reservepark.spot.v1.SpotService.CheckSpotsExist:input_type -> reservepark.spot.v1.CheckSpotsExistRequest
+	14, // 21: reservepark.spot.v1.SpotService.CheckCompatibility:input_type -> reservepark.spot.v1.CheckCompatibilityRequest
+	16, // 22: reservepark.spot.v1.SpotService.SearchSpots:input_type -> reservepark.spot.v1.SearchSpotsRequest
+	18, // 23: reservepark.spot.v1.SpotService.SetCancellationPolicy:input_type -> reservepark.spot.v1.CancellationPolicy
+	25, // 24: reservepark.spot.v1.SpotService.ListCancellationPolicies:input_type -> google.protobuf.Empty
+	20, // 25: reservepark.spot.v1.SpotService.DeleteCancellationPolicy:input_type -> reservepark.spot.v1.DeleteCancellationPolicyRequest
+	21, // 26: reservepark.spot.v1.SpotService.GetCancellationPolicy:input_type -> reservepark.spot.v1.GetCancellationPolicyRequest
+	4,  // 27: reservepark.spot.v1.SpotService.AddSpot:output_type -> reservepark.spot.v1.AddSpotResponse
+	25, // 28: reservepark.spot.v1.SpotService.EditSpot:output_type -> google.protobuf.Empty
+	25, // 29: reservepark.spot.v1.SpotService.DeleteSpot:output_type -> google.protobuf.Empty
+	0,  // 30: reservepark.spot.v1.SpotService.GetSpot:output_type -> reservepark.spot.v1.Spot
+	9,  // 31: reservepark.spot.v1.SpotService.ListSpots:output_type -> reservepark.spot.v1.ListSpotsResponse
+	11, // 32: reservepark.spot.v1.SpotService.GetPrice:output_type -> reservepark.spot.v1.GetPriceResponse
+	13, // 33: reservepark.spot.v1.SpotService.CheckSpotsExist:output_type -> reservepark.spot.v1.CheckSpotsExistResponse
+	15, // 34: reservepark.spot.v1.SpotService.CheckCompatibility:output_type -> reservepark.spot.v1.CheckCompatibilityResponse
+	17, // 35: reservepark.spot.v1.SpotService.SearchSpots:output_type -> reservepark.spot.v1.SearchSpotsResponse
+	18, // 36: reservepark.spot.v1.SpotService.SetCancellationPolicy:output_type -> reservepark.spot.v1.CancellationPolicy
+	19, // 37: reservepark.spot.v1.SpotService.ListCancellationPolicies:output_type -> reservepark.spot.v1.ListCancellationPoliciesResponse
+	25, // 38: reservepark.spot.v1.SpotService.DeleteCancellationPolicy:output_type -> google.protobuf.Empty
+	18, // 39: reservepark.spot.v1.SpotService.GetCancellationPolicy:output_type -> reservepark.spot.v1.CancellationPolicy
+	27, // [27:40] is the sub-list for method output_type
+	14, // [14:27] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_reservepark_spot_v1_spot_proto_init() }
+func file_reservepark_spot_v1_spot_proto_init() {
+	if File_reservepark_spot_v1_spot_proto != nil {
+		return
+	}
+	file_reservepark_spot_v1_spot_proto_msgTypes[5].OneofWrappers = []any{}
+	file_reservepark_spot_v1_spot_proto_msgTypes[9].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_reservepark_spot_v1_spot_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_reservepark_spot_v1_spot_proto_goTypes,
+		DependencyIndexes: file_reservepark_spot_v1_spot_proto_depIdxs,
+		MessageInfos:      file_reservepark_spot_v1_spot_proto_msgTypes,
+	}.Build()
+	File_reservepark_spot_v1_spot_proto = out.File
+	file_reservepark_spot_v1_spot_proto_rawDesc = nil
+	file_reservepark_spot_v1_spot_proto_goTypes = nil
+	file_reservepark_spot_v1_spot_proto_depIdxs = nil
+}
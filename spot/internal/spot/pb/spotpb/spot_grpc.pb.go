@@ -0,0 +1,584 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: reservepark/spot/v1/spot.proto
+
+package spotpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SpotService_AddSpot_FullMethodName                  = "/reservepark.spot.v1.SpotService/AddSpot"
+	SpotService_EditSpot_FullMethodName                 = "/reservepark.spot.v1.SpotService/EditSpot"
+	SpotService_DeleteSpot_FullMethodName               = "/reservepark.spot.v1.SpotService/DeleteSpot"
+	SpotService_GetSpot_FullMethodName                  = "/reservepark.spot.v1.SpotService/GetSpot"
+	SpotService_ListSpots_FullMethodName                = "/reservepark.spot.v1.SpotService/ListSpots"
+	SpotService_GetPrice_FullMethodName                 = "/reservepark.spot.v1.SpotService/GetPrice"
+	SpotService_CheckSpotsExist_FullMethodName          = "/reservepark.spot.v1.SpotService/CheckSpotsExist"
+	SpotService_CheckCompatibility_FullMethodName       = "/reservepark.spot.v1.SpotService/CheckCompatibility"
+	SpotService_SearchSpots_FullMethodName              = "/reservepark.spot.v1.SpotService/SearchSpots"
+	SpotService_SetCancellationPolicy_FullMethodName    = "/reservepark.spot.v1.SpotService/SetCancellationPolicy"
+	SpotService_ListCancellationPolicies_FullMethodName = "/reservepark.spot.v1.SpotService/ListCancellationPolicies"
+	SpotService_DeleteCancellationPolicy_FullMethodName = "/reservepark.spot.v1.SpotService/DeleteCancellationPolicy"
+	SpotService_GetCancellationPolicy_FullMethodName    = "/reservepark.spot.v1.SpotService/GetCancellationPolicy"
+)
+
+// SpotServiceClient is the client API for SpotService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SpotService manages parking spots, their prices and what vehicles fit them.
+type SpotServiceClient interface {
+	AddSpot(ctx context.Context, in *AddSpotRequest, opts ...grpc.CallOption) (*AddSpotResponse, error)
+	EditSpot(ctx context.Context, in *EditSpotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteSpot(ctx context.Context, in *DeleteSpotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetSpot(ctx context.Context, in *GetSpotRequest, opts ...grpc.CallOption) (*Spot, error)
+	ListSpots(ctx context.Context, in *ListSpotsRequest, opts ...grpc.CallOption) (*ListSpotsResponse, error)
+	GetPrice(ctx context.Context, in *GetPriceRequest, opts ...grpc.CallOption) (*GetPriceResponse, error)
+	CheckSpotsExist(ctx context.Context, in *CheckSpotsExistRequest, opts ...grpc.CallOption) (*CheckSpotsExistResponse, error)
+	CheckCompatibility(ctx context.Context, in *CheckCompatibilityRequest, opts ...grpc.CallOption) (*CheckCompatibilityResponse, error)
+	SearchSpots(ctx context.Context, in *SearchSpotsRequest, opts ...grpc.CallOption) (*SearchSpotsResponse, error)
+	SetCancellationPolicy(ctx context.Context, in *CancellationPolicy, opts ...grpc.CallOption) (*CancellationPolicy, error)
+	ListCancellationPolicies(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListCancellationPoliciesResponse, error)
+	DeleteCancellationPolicy(ctx context.Context, in *DeleteCancellationPolicyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetCancellationPolicy returns the policy that applies to the spot: its own, its lot's or the default one.
+	GetCancellationPolicy(ctx context.Context, in *GetCancellationPolicyRequest, opts ...grpc.CallOption) (*CancellationPolicy, error)
+}
+
+type spotServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSpotServiceClient(cc grpc.ClientConnInterface) SpotServiceClient {
+	return &spotServiceClient{cc}
+}
+
+func (c *spotServiceClient) AddSpot(ctx context.Context, in *AddSpotRequest, opts ...grpc.CallOption) (*AddSpotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddSpotResponse)
+	err := c.cc.Invoke(ctx, SpotService_AddSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) EditSpot(ctx context.Context, in *EditSpotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SpotService_EditSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) DeleteSpot(ctx context.Context, in *DeleteSpotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SpotService_DeleteSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) GetSpot(ctx context.Context, in *GetSpotRequest, opts ...grpc.CallOption) (*Spot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Spot)
+	err := c.cc.Invoke(ctx, SpotService_GetSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) ListSpots(ctx context.Context, in *ListSpotsRequest, opts ...grpc.CallOption) (*ListSpotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSpotsResponse)
+	err := c.cc.Invoke(ctx, SpotService_ListSpots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) GetPrice(ctx context.Context, in *GetPriceRequest, opts ...grpc.CallOption) (*GetPriceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPriceResponse)
+	err := c.cc.Invoke(ctx, SpotService_GetPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) CheckSpotsExist(ctx context.Context, in *CheckSpotsExistRequest, opts ...grpc.CallOption) (*CheckSpotsExistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckSpotsExistResponse)
+	err := c.cc.Invoke(ctx, SpotService_CheckSpotsExist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) CheckCompatibility(ctx context.Context, in *CheckCompatibilityRequest, opts ...grpc.CallOption) (*CheckCompatibilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckCompatibilityResponse)
+	err := c.cc.Invoke(ctx, SpotService_CheckCompatibility_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) SearchSpots(ctx context.Context, in *SearchSpotsRequest, opts ...grpc.CallOption) (*SearchSpotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchSpotsResponse)
+	err := c.cc.Invoke(ctx, SpotService_SearchSpots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) SetCancellationPolicy(ctx context.Context, in *CancellationPolicy, opts ...grpc.CallOption) (*CancellationPolicy, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancellationPolicy)
+	err := c.cc.Invoke(ctx, SpotService_SetCancellationPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) ListCancellationPolicies(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListCancellationPoliciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCancellationPoliciesResponse)
+	err := c.cc.Invoke(ctx, SpotService_ListCancellationPolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) DeleteCancellationPolicy(ctx context.Context, in *DeleteCancellationPolicyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SpotService_DeleteCancellationPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spotServiceClient) GetCancellationPolicy(ctx context.Context, in *GetCancellationPolicyRequest, opts ...grpc.CallOption) (*CancellationPolicy, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancellationPolicy)
+	err := c.cc.Invoke(ctx, SpotService_GetCancellationPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SpotServiceServer is the server API for SpotService service.
+// All implementations must embed UnimplementedSpotServiceServer
+// for forward compatibility.
+//
+// SpotService manages parking spots, their prices and what vehicles fit them.
+type SpotServiceServer interface {
+	AddSpot(context.Context, *AddSpotRequest) (*AddSpotResponse, error)
+	EditSpot(context.Context, *EditSpotRequest) (*emptypb.Empty, error)
+	DeleteSpot(context.Context, *DeleteSpotRequest) (*emptypb.Empty, error)
+	GetSpot(context.Context, *GetSpotRequest) (*Spot, error)
+	ListSpots(context.Context, *ListSpotsRequest) (*ListSpotsResponse, error)
+	GetPrice(context.Context, *GetPriceRequest) (*GetPriceResponse, error)
+	CheckSpotsExist(context.Context, *CheckSpotsExistRequest) (*CheckSpotsExistResponse, error)
+	CheckCompatibility(context.Context, *CheckCompatibilityRequest) (*CheckCompatibilityResponse, error)
+	SearchSpots(context.Context, *SearchSpotsRequest) (*SearchSpotsResponse, error)
+	SetCancellationPolicy(context.Context, *CancellationPolicy) (*CancellationPolicy, error)
+	ListCancellationPolicies(context.Context, *emptypb.Empty) (*ListCancellationPoliciesResponse, error)
+	DeleteCancellationPolicy(context.Context, *DeleteCancellationPolicyRequest) (*emptypb.Empty, error)
+	// GetCancellationPolicy returns the policy that applies to the spot: its own, its lot's or the default one.
+	GetCancellationPolicy(context.Context, *GetCancellationPolicyRequest) (*CancellationPolicy, error)
+	mustEmbedUnimplementedSpotServiceServer()
+}
+
+// UnimplementedSpotServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSpotServiceServer struct{}
+
+func (UnimplementedSpotServiceServer) AddSpot(context.Context, *AddSpotRequest) (*AddSpotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSpot not implemented")
+}
+func (UnimplementedSpotServiceServer) EditSpot(context.Context, *EditSpotRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditSpot not implemented")
+}
+func (UnimplementedSpotServiceServer) DeleteSpot(context.Context, *DeleteSpotRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSpot not implemented")
+}
+func (UnimplementedSpotServiceServer) GetSpot(context.Context, *GetSpotRequest) (*Spot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpot not implemented")
+}
+func (UnimplementedSpotServiceServer) ListSpots(context.Context, *ListSpotsRequest) (*ListSpotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSpots not implemented")
+}
+func (UnimplementedSpotServiceServer) GetPrice(context.Context, *GetPriceRequest) (*GetPriceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrice not implemented")
+}
+func (UnimplementedSpotServiceServer) CheckSpotsExist(context.Context, *CheckSpotsExistRequest) (*CheckSpotsExistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckSpotsExist not implemented")
+}
+func (UnimplementedSpotServiceServer) CheckCompatibility(context.Context, *CheckCompatibilityRequest) (*CheckCompatibilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckCompatibility not implemented")
+}
+func (UnimplementedSpotServiceServer) SearchSpots(context.Context, *SearchSpotsRequest) (*SearchSpotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSpots not implemented")
+}
+func (UnimplementedSpotServiceServer) SetCancellationPolicy(context.Context, *CancellationPolicy) (*CancellationPolicy, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCancellationPolicy not implemented")
+}
+func (UnimplementedSpotServiceServer) ListCancellationPolicies(context.Context, *emptypb.Empty) (*ListCancellationPoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCancellationPolicies not implemented")
+}
+func (UnimplementedSpotServiceServer) DeleteCancellationPolicy(context.Context, *DeleteCancellationPolicyRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCancellationPolicy not implemented")
+}
+func (UnimplementedSpotServiceServer) GetCancellationPolicy(context.Context, *GetCancellationPolicyRequest) (*CancellationPolicy, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCancellationPolicy not implemented")
+}
+func (UnimplementedSpotServiceServer) mustEmbedUnimplementedSpotServiceServer() {}
+func (UnimplementedSpotServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeSpotServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SpotServiceServer will
+// result in compilation errors.
+type UnsafeSpotServiceServer interface {
+	mustEmbedUnimplementedSpotServiceServer()
+}
+
+func RegisterSpotServiceServer(s grpc.ServiceRegistrar, srv SpotServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSpotServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SpotService_ServiceDesc, srv)
+}
+
+func _SpotService_AddSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).AddSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_AddSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).AddSpot(ctx, req.(*AddSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_EditSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).EditSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_EditSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).EditSpot(ctx, req.(*EditSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_DeleteSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).DeleteSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_DeleteSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).DeleteSpot(ctx, req.(*DeleteSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_GetSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).GetSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_GetSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).GetSpot(ctx, req.(*GetSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_ListSpots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSpotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).ListSpots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_ListSpots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).ListSpots(ctx, req.(*ListSpotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_GetPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).GetPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_GetPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).GetPrice(ctx, req.(*GetPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_CheckSpotsExist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckSpotsExistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).CheckSpotsExist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_CheckSpotsExist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).CheckSpotsExist(ctx, req.(*CheckSpotsExistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_CheckCompatibility_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckCompatibilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).CheckCompatibility(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_CheckCompatibility_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).CheckCompatibility(ctx, req.(*CheckCompatibilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_SearchSpots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSpotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).SearchSpots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_SearchSpots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).SearchSpots(ctx, req.(*SearchSpotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_SetCancellationPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancellationPolicy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).SetCancellationPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_SetCancellationPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).SetCancellationPolicy(ctx, req.(*CancellationPolicy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_ListCancellationPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).ListCancellationPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_ListCancellationPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).ListCancellationPolicies(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_DeleteCancellationPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCancellationPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).DeleteCancellationPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_DeleteCancellationPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).DeleteCancellationPolicy(ctx, req.(*DeleteCancellationPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SpotService_GetCancellationPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCancellationPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpotServiceServer).GetCancellationPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SpotService_GetCancellationPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpotServiceServer).GetCancellationPolicy(ctx, req.(*GetCancellationPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SpotService_ServiceDesc is the grpc.ServiceDesc for SpotService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SpotService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reservepark.spot.v1.SpotService",
+	HandlerType: (*SpotServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddSpot",
+			Handler:    _SpotService_AddSpot_Handler,
+		},
+		{
+			MethodName: "EditSpot",
+			Handler:    _SpotService_EditSpot_Handler,
+		},
+		{
+			MethodName: "DeleteSpot",
+			Handler:    _SpotService_DeleteSpot_Handler,
+		},
+		{
+			MethodName: "GetSpot",
+			Handler:    _SpotService_GetSpot_Handler,
+		},
+		{
+			MethodName: "ListSpots",
+			Handler:    _SpotService_ListSpots_Handler,
+		},
+		{
+			MethodName: "GetPrice",
+			Handler:    _SpotService_GetPrice_Handler,
+		},
+		{
+			MethodName: "CheckSpotsExist",
+			Handler:    _SpotService_CheckSpotsExist_Handler,
+		},
+		{
+			MethodName: "CheckCompatibility",
+			Handler:    _SpotService_CheckCompatibility_Handler,
+		},
+		{
+			MethodName: "SearchSpots",
+			Handler:    _SpotService_SearchSpots_Handler,
+		},
+		{
+			MethodName: "SetCancellationPolicy",
+			Handler:    _SpotService_SetCancellationPolicy_Handler,
+		},
+		{
+			MethodName: "ListCancellationPolicies",
+			Handler:    _SpotService_ListCancellationPolicies_Handler,
+		},
+		{
+			MethodName: "DeleteCancellationPolicy",
+			Handler:    _SpotService_DeleteCancellationPolicy_Handler,
+		},
+		{
+			MethodName: "GetCancellationPolicy",
+			Handler:    _SpotService_GetCancellationPolicy_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "reservepark/spot/v1/spot.proto",
+}
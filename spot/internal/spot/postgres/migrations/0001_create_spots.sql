@@ -0,0 +1,15 @@
+CREATE TABLE spots (
+    id             TEXT COLLATE "C" PRIMARY KEY,
+    spot_id        TEXT COLLATE "C" NOT NULL UNIQUE,
+    lot_id         TEXT COLLATE "C" NOT NULL DEFAULT '',
+    latitude       DOUBLE PRECISION NOT NULL,
+    longitude      DOUBLE PRECISION NOT NULL,
+    price_per_hour DOUBLE PRECISION NOT NULL CHECK (price_per_hour > 0),
+    size           TEXT NOT NULL CHECK (size IN ('small', 'medium', 'large')),
+    type           TEXT NOT NULL CHECK (type IN ('indoor', 'outdoor', 'ev')),
+    max_height_cm  INTEGER NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX spots_lot_id_idx ON spots (lot_id, id);
+CREATE INDEX spots_price_per_hour_idx ON spots (price_per_hour, id);
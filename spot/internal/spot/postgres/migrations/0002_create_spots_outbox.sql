@@ -0,0 +1,12 @@
+-- The services share the database, so each one gets its own outbox table
+CREATE TABLE spots_outbox (
+    id           TEXT COLLATE "C" PRIMARY KEY,
+    type         TEXT NOT NULL,
+    source       TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    payload      JSONB NOT NULL,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX spots_outbox_pending_idx ON spots_outbox (occurred_at, id) WHERE published_at IS NULL;
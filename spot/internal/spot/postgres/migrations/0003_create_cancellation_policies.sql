@@ -0,0 +1,9 @@
+CREATE TABLE cancellation_policies (
+    scope                  TEXT NOT NULL CHECK (scope IN ('spot', 'lot')),
+    target_id              TEXT COLLATE "C" NOT NULL,
+    free_until_hours       INTEGER NOT NULL CHECK (free_until_hours >= 0),
+    late_fee_percent       DOUBLE PRECISION NOT NULL CHECK (late_fee_percent BETWEEN 0 AND 100),
+    non_refundable_minutes INTEGER NOT NULL CHECK (non_refundable_minutes >= 0),
+    updated_at             TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, target_id)
+);
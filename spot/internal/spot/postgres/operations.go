@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const columns = `id, spot_id, lot_id, latitude, longitude, price_per_hour, size, type, max_height_cm, updated_at`
+
+func (p *Postgres) AddSpot(ctx context.Context, spot model.Spot, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if spot.ID.IsZero() {
+		spot.ID = primitive.NewObjectID()
+	}
+
+	return p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO spots (`+columns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			spot.ID.Hex(), spot.SpotID, spot.LotID, spot.Latitude, spot.Longitude, spot.PricePerHour,
+			spot.Size, spot.Type, spot.MaxHeightCm, spot.UpdatedAt)
+		return err
+	})
+}
+
+func (p *Postgres) EditSpot(ctx context.Context, input model.Spot, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE spots SET lot_id = $2, latitude = $3, longitude = $4, price_per_hour = $5,
+			size = $6, type = $7, max_height_cm = $8, updated_at = $9
+			WHERE spot_id = $1`,
+			input.SpotID, input.LotID, input.Latitude, input.Longitude, input.PricePerHour,
+			input.Size, input.Type, input.MaxHeightCm, input.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return model.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *Postgres) DeleteSpot(ctx context.Context, spotID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM spots WHERE spot_id = $1`, spotID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return model.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *Postgres) GetSpot(ctx context.Context, spotID string) (model.Spot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanSpot(p.Pool.QueryRow(ctx, `SELECT `+columns+` FROM spots WHERE spot_id = $1`, spotID))
+}
+
+// GetAll returns one page of spots matching the filter.
+func (p *Postgres) GetAll(ctx context.Context, input model.SpotFilter, opts model.ListOptions) (model.Page[model.Spot], error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var filter where
+	if input.LotID != "" {
+		filter.add("lot_id = $%d", input.LotID)
+	}
+	if input.Size != "" {
+		filter.add("size = $%d", input.Size)
+	}
+	if input.Type != "" {
+		filter.add("type = $%d", input.Type)
+	}
+
+	return findPage(ctx, p.Pool, "spots", columns, filter, opts, scanSpot,
+		func(s model.Spot) primitive.ObjectID { return s.ID })
+}
+
+func (p *Postgres) GetPrice(ctx context.Context, input model.GetPriceInput) (float64, error) {
+	spot, err := p.GetSpot(ctx, input.SpotID)
+	if err != nil {
+		return 0, err
+	}
+
+	return spot.Price(input.StartTime, input.EndTime), nil
+}
+
+func (p *Postgres) CheckSpotsExist(ctx context.Context, spotIDs []string) ([]string, error) {
+	spots, err := p.findSpots(ctx, spotIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	foundSpots := make(map[string]struct{}, len(spots))
+	for _, spot := range spots {
+		foundSpots[spot.SpotID] = struct{}{}
+	}
+
+	var notFound []string
+	for _, spotID := range spotIDs {
+		if _, exists := foundSpots[spotID]; !exists {
+			notFound = append(notFound, spotID)
+		}
+	}
+
+	return notFound, nil
+}
+
+func (p *Postgres) SearchSpots(ctx context.Context, input model.SearchInput) ([]model.Spot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var filter where
+	if input.LotID != "" {
+		filter.add("lot_id = $%d", input.LotID)
+	}
+	if input.Size != "" {
+		filter.add("size = $%d", input.Size)
+	}
+	if input.Type != "" {
+		filter.add("type = $%d", input.Type)
+	}
+	if input.MaxPricePerHour > 0 {
+		filter.add("price_per_hour <= $%d", input.MaxPricePerHour)
+	}
+
+	spots, err := p.query(ctx, `SELECT `+columns+` FROM spots`+filter.String()+` ORDER BY id`, filter.args...)
+	if err != nil || input.Vehicle == nil {
+		return spots, err
+	}
+
+	// Drop spots the vehicle can't use
+	var compatible []model.Spot
+	for _, spot := range spots {
+		if spot.Incompatibility(*input.Vehicle) == "" {
+			compatible = append(compatible, spot)
+		}
+	}
+
+	return compatible, nil
+}
+
+func (p *Postgres) CheckCompatibility(ctx context.Context, spotIDs []string, vehicle model.VehicleProfile) (model.CompatibilityResult, error) {
+	spots, err := p.findSpots(ctx, spotIDs)
+	if err != nil {
+		return model.CompatibilityResult{}, err
+	}
+
+	return model.NewCompatibilityResult(spotIDs, spots, vehicle), nil
+}
+
+func (p *Postgres) findSpots(ctx context.Context, spotIDs []string) ([]model.Spot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return p.query(ctx, `SELECT `+columns+` FROM spots WHERE spot_id = ANY($1) ORDER BY id`, spotIDs)
+}
+
+func (p *Postgres) query(ctx context.Context, sql string, args ...any) ([]model.Spot, error) {
+	rows, err := p.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var spots []model.Spot
+	for rows.Next() {
+		spot, err := scanSpot(rows)
+		if err != nil {
+			return nil, err
+		}
+		spots = append(spots, spot)
+	}
+
+	return spots, rows.Err()
+}
+
+func scanSpot(row pgx.Row, extra ...any) (model.Spot, error) {
+	var s model.Spot
+	var id string
+
+	dest := append([]any{&id, &s.SpotID, &s.LotID, &s.Latitude, &s.Longitude, &s.PricePerHour,
+		&s.Size, &s.Type, &s.MaxHeightCm, &s.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return s, model.ErrNotFound
+		}
+		return s, err
+	}
+
+	var err error
+	s.ID, err = primitive.ObjectIDFromHex(id)
+	s.UpdatedAt = s.UpdatedAt.UTC()
+	return s, err
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/jackc/pgx/v5"
+)
+
+const outboxColumns = `id, type, source, aggregate_id, occurred_at, payload`
+
+// withEvents runs the write and adds the events to the outbox in one transaction
+func (p *Postgres) withEvents(ctx context.Context, events []model.Event, write func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, p.Pool, func(tx pgx.Tx) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			_, err := tx.Exec(ctx, `INSERT INTO spots_outbox (`+outboxColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+				event.ID, event.Type, event.Source, event.AggregateID, event.OccurredAt, string(event.Payload))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p *Postgres) PendingEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := p.Pool.Query(ctx, `SELECT `+outboxColumns+` FROM spots_outbox
+		WHERE published_at IS NULL ORDER BY occurred_at, id LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []model.Event
+	for rows.Next() {
+		var event model.Event
+		var payload string
+		if err := rows.Scan(&event.ID, &event.Type, &event.Source, &event.AggregateID, &event.OccurredAt, &payload); err != nil {
+			return nil, err
+		}
+		event.OccurredAt = event.OccurredAt.UTC()
+		event.Payload = []byte(payload)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (p *Postgres) MarkPublished(ctx context.Context, ids []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := p.Pool.Exec(ctx, `UPDATE spots_outbox SET published_at = now() WHERE id = ANY($1)`, ids)
+	return err
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/jackc/pgx/v5"
+)
+
+const policyColumns = `scope, target_id, free_until_hours, late_fee_percent, non_refundable_minutes, updated_at`
+
+func (p *Postgres) SetCancellationPolicy(ctx context.Context, policy model.CancellationPolicy) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := p.Pool.Exec(ctx, `INSERT INTO cancellation_policies (`+policyColumns+`) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scope, target_id) DO UPDATE SET free_until_hours = EXCLUDED.free_until_hours,
+			late_fee_percent = EXCLUDED.late_fee_percent, non_refundable_minutes = EXCLUDED.non_refundable_minutes,
+			updated_at = EXCLUDED.updated_at`,
+		policy.Scope, policy.TargetID, policy.FreeUntilHours, policy.LateFeePercent, policy.NonRefundableMinutes, policy.UpdatedAt)
+	return err
+}
+
+func (p *Postgres) GetCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) (model.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return scanPolicy(p.Pool.QueryRow(ctx, `SELECT `+policyColumns+` FROM cancellation_policies
+		WHERE scope = $1 AND target_id = $2`, scope, targetID))
+}
+
+func (p *Postgres) DeleteCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tag, err := p.Pool.Exec(ctx, `DELETE FROM cancellation_policies WHERE scope = $1 AND target_id = $2`, scope, targetID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Postgres) GetCancellationPolicies(ctx context.Context) ([]model.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := p.Pool.Query(ctx, `SELECT `+policyColumns+` FROM cancellation_policies ORDER BY scope, target_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []model.CancellationPolicy{}
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+func scanPolicy(row pgx.Row) (model.CancellationPolicy, error) {
+	var policy model.CancellationPolicy
+	err := row.Scan(&policy.Scope, &policy.TargetID, &policy.FreeUntilHours, &policy.LateFeePercent,
+		&policy.NonRefundableMinutes, &policy.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return policy, model.ErrNotFound
+	}
+
+	policy.UpdatedAt = policy.UpdatedAt.UTC()
+	return policy, err
+}
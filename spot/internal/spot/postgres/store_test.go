@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+
+	db, err := Connect(url)
+	if err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer db.Disconnect()
+
+	storetest.Run(t, func(t *testing.T) store.SpotStore {
+		if _, err := db.Pool.Exec(context.Background(), "TRUNCATE spots, spots_outbox, cancellation_policies"); err != nil {
+			t.Fatalf("Failed to truncate spots: %v", err)
+		}
+		return db
+	})
+}
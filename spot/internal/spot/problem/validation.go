@@ -0,0 +1,121 @@
+package problem
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a single failed validation rule.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// JSONFieldName makes the validator report fields by their JSON names.
+// Register it with validator.Validate.RegisterTagNameFunc.
+func JSONFieldName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return fld.Name
+	}
+	return name
+}
+
+// FieldErrors converts validator errors into per-field errors.
+// It returns nil if err does not come from the validator.
+func FieldErrors(err error) []FieldError {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(ve))
+	for _, fe := range ve {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return fields
+}
+
+// fieldPath drops the name of the top level struct from the namespace.
+func fieldPath(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	isString := fe.Kind() == reflect.String
+	isCollection := fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "email":
+		return "must be a valid email address"
+	case "iso3166_1_alpha2":
+		return "must be a two-letter ISO 3166-1 country code"
+	case "latitude":
+		return "must be a valid latitude"
+	case "longitude":
+		return "must be a valid longitude"
+	case "gtfield":
+		return "must be after " + snakeCase(param)
+	case "gtefield":
+		return "must not be before " + snakeCase(param)
+	case "min", "gte":
+		if isString {
+			return "must be at least " + param + " characters long"
+		}
+		if isCollection {
+			return "must contain at least " + param + " items"
+		}
+		return "must be greater than or equal to " + param
+	case "max", "lte":
+		if isString {
+			return "must be at most " + param + " characters long"
+		}
+		if isCollection {
+			return "must contain at most " + param + " items"
+		}
+		return "must be less than or equal to " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	}
+	if param != "" {
+		return "failed the " + fe.Tag() + "=" + param + " rule"
+	}
+	return "failed the " + fe.Tag() + " rule"
+}
+
+// snakeCase turns a Go field name such as StartTime into start_time.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
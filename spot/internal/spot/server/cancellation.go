@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	m "github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/pb/spotpb"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/problem"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) putCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Setting cancellation policy")
+	var input m.CancellationPolicy
+
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	policy, err := s.setPolicy(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, policy, http.StatusOK)
+}
+
+func (s *Server) getCancellationPolicies(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting cancellation policies")
+
+	policies, err := s.listPolicies(r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, policies, http.StatusOK)
+}
+
+func (s *Server) deleteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting cancellation policy")
+	vars := mux.Vars(r)
+
+	if err := s.removePolicy(r.Context(), m.PolicyScope(vars["scope"]), vars["id"]); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getSpotCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Resolving spot's cancellation policy")
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		s.handleError(w, r, problem.CodeBadRequest, "Missing spot ID", nil)
+		return
+	}
+
+	policy, err := s.resolvePolicy(r.Context(), id)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, policy, http.StatusOK)
+}
+
+func (s *Server) setPolicy(ctx context.Context, input m.CancellationPolicy) (m.CancellationPolicy, error) {
+	input.UpdatedAt = time.Now().UTC()
+	if err := s.Validator.Struct(input); err != nil {
+		return input, problem.Invalid(err)
+	}
+
+	// Otherwise the free tier would hide part of the non-refundable one
+	if input.NonRefundableMinutes > input.FreeUntilHours*60 {
+		return input, problem.NewError(problem.CodeBadRequest, "non_refundable_minutes must not exceed free_until_hours", nil)
+	}
+
+	// Lots have no records of their own, a lot policy waits for its spots
+	if input.Scope == m.PolicyScopeSpot {
+		if _, err := s.findSpot(ctx, input.TargetID); err != nil {
+			return input, err
+		}
+	}
+
+	if err := s.Store.SetCancellationPolicy(ctx, input); err != nil {
+		return input, problem.NewError(problem.CodeInternal, "Failed to set cancellation policy", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Cancellation policy set", "scope", input.Scope, "target_id", input.TargetID)
+	return input, nil
+}
+
+func (s *Server) listPolicies(ctx context.Context) ([]m.CancellationPolicy, error) {
+	policies, err := s.Store.GetCancellationPolicies(ctx)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to get cancellation policies", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Cancellation policies found", "count", len(policies))
+	return policies, nil
+}
+
+func (s *Server) removePolicy(ctx context.Context, scope m.PolicyScope, targetID string) error {
+	if scope != m.PolicyScopeSpot && scope != m.PolicyScopeLot {
+		return problem.NewError(problem.CodeBadRequest, "scope must be spot or lot", nil)
+	}
+
+	err := s.Store.DeleteCancellationPolicy(ctx, scope, targetID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodePolicyNotFound, "Cancellation policy not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to delete cancellation policy", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Cancellation policy deleted", "scope", scope, "target_id", targetID)
+	return nil
+}
+
+// resolvePolicy returns the policy of the spot, else the one of its lot, else the default one
+func (s *Server) resolvePolicy(ctx context.Context, spotID string) (m.CancellationPolicy, error) {
+	spot, err := s.findSpot(ctx, spotID)
+	if err != nil {
+		return m.CancellationPolicy{}, err
+	}
+
+	candidates := []struct {
+		scope    m.PolicyScope
+		targetID string
+	}{{m.PolicyScopeSpot, spot.SpotID}, {m.PolicyScopeLot, spot.LotID}}
+
+	for _, c := range candidates {
+		if c.targetID == "" {
+			continue
+		}
+
+		policy, err := s.Store.GetCancellationPolicy(ctx, c.scope, c.targetID)
+		if err == nil {
+			return policy, nil
+		}
+		if err != m.ErrNotFound {
+			return policy, problem.NewError(problem.CodeInternal, "Failed to get cancellation policy", err)
+		}
+	}
+
+	return m.DefaultCancellationPolicy(spot.SpotID), nil
+}
+
+func (rpc *spotRPC) SetCancellationPolicy(ctx context.Context, req *spotpb.CancellationPolicy) (*spotpb.CancellationPolicy, error) {
+	policy, err := rpc.s.setPolicy(ctx, m.CancellationPolicy{
+		Scope:                m.PolicyScope(req.GetScope()),
+		TargetID:             req.GetTargetId(),
+		FreeUntilHours:       int(req.GetFreeUntilHours()),
+		LateFeePercent:       req.GetLateFeePercent(),
+		NonRefundableMinutes: int(req.GetNonRefundableMinutes()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return policyToProto(policy), nil
+}
+
+func (rpc *spotRPC) ListCancellationPolicies(ctx context.Context, _ *emptypb.Empty) (*spotpb.ListCancellationPoliciesResponse, error) {
+	policies, err := rpc.s.listPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &spotpb.ListCancellationPoliciesResponse{}
+	for _, policy := range policies {
+		resp.Policies = append(resp.Policies, policyToProto(policy))
+	}
+
+	return resp, nil
+}
+
+func (rpc *spotRPC) DeleteCancellationPolicy(ctx context.Context, req *spotpb.DeleteCancellationPolicyRequest) (*emptypb.Empty, error) {
+	if err := rpc.s.removePolicy(ctx, m.PolicyScope(req.GetScope()), req.GetTargetId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *spotRPC) GetCancellationPolicy(ctx context.Context, req *spotpb.GetCancellationPolicyRequest) (*spotpb.CancellationPolicy, error) {
+	policy, err := rpc.s.resolvePolicy(ctx, req.GetSpotId())
+	if err != nil {
+		return nil, err
+	}
+
+	return policyToProto(policy), nil
+}
+
+func policyToProto(policy m.CancellationPolicy) *spotpb.CancellationPolicy {
+	p := &spotpb.CancellationPolicy{
+		Scope:                string(policy.Scope),
+		TargetId:             policy.TargetID,
+		FreeUntilHours:       int32(policy.FreeUntilHours),
+		LateFeePercent:       policy.LateFeePercent,
+		NonRefundableMinutes: int32(policy.NonRefundableMinutes),
+	}
+	if !policy.UpdatedAt.IsZero() {
+		p.UpdatedAt = timestamppb.New(policy.UpdatedAt)
+	}
+	return p
+}
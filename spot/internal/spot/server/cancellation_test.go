@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/config"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/logger"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store/memory"
+)
+
+func TestCancellationPolicies(t *testing.T) {
+	ps := NewServer(logger.GetLogger(), config.GetConfig(), memory.New())
+	ctx := context.Background()
+
+	spot, err := ps.createSpot(ctx, addInput{LotID: "lot-a", Latitude: 52.23, Longitude: 21.01, PricePerHour: 5, Size: model.SizeMedium, Type: model.SpotTypeIndoor})
+	if err != nil {
+		t.Fatalf("createSpot: %v", err)
+	}
+
+	put := func(policy model.CancellationPolicy) int {
+		body, _ := json.Marshal(policy)
+		rr := httptest.NewRecorder()
+		ps.Handler().ServeHTTP(rr, httptest.NewRequest("PUT", "/cancellation-policies", bytes.NewReader(body)))
+		return rr.Code
+	}
+	resolve := func() model.CancellationPolicy {
+		rr := httptest.NewRecorder()
+		ps.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/spots/"+spot.SpotID+"/cancellation-policy", nil))
+
+		var policy model.CancellationPolicy
+		if err := json.NewDecoder(rr.Body).Decode(&policy); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return policy
+	}
+
+	if policy := resolve(); policy.Scope != model.PolicyScopeDefault || policy.FreeUntilHours != 0 {
+		t.Errorf("policy without any set = %+v, want the default one", policy)
+	}
+
+	invalid := []model.CancellationPolicy{
+		{Scope: "garage", TargetID: "g1"},
+		{Scope: model.PolicyScopeLot, TargetID: "lot-a", LateFeePercent: 120},
+		{Scope: model.PolicyScopeLot, TargetID: "lot-a", FreeUntilHours: 1, NonRefundableMinutes: 90},
+	}
+	for _, policy := range invalid {
+		if status := put(policy); status != http.StatusBadRequest {
+			t.Errorf("put %+v: got %d, want %d", policy, status, http.StatusBadRequest)
+		}
+	}
+	if status := put(model.CancellationPolicy{Scope: model.PolicyScopeSpot, TargetID: "missing"}); status != http.StatusNotFound {
+		t.Errorf("put for a missing spot: got %d, want %d", status, http.StatusNotFound)
+	}
+
+	if status := put(model.CancellationPolicy{Scope: model.PolicyScopeLot, TargetID: "lot-a", FreeUntilHours: 24, LateFeePercent: 50, NonRefundableMinutes: 60}); status != http.StatusOK {
+		t.Fatalf("put lot policy: got %d, want %d", status, http.StatusOK)
+	}
+	if policy := resolve(); policy.Scope != model.PolicyScopeLot || policy.LateFeePercent != 50 {
+		t.Errorf("policy with a lot policy = %+v, want the lot one", policy)
+	}
+
+	if status := put(model.CancellationPolicy{Scope: model.PolicyScopeSpot, TargetID: spot.SpotID, FreeUntilHours: 2}); status != http.StatusOK {
+		t.Fatalf("put spot policy: got %d, want %d", status, http.StatusOK)
+	}
+	if policy := resolve(); policy.Scope != model.PolicyScopeSpot || policy.FreeUntilHours != 2 {
+		t.Errorf("policy with a spot policy = %+v, want the spot one", policy)
+	}
+
+	// Deleting the spot drops its own policy
+	if err := ps.removeSpot(ctx, spot.SpotID); err != nil {
+		t.Fatalf("removeSpot: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	ps.Handler().ServeHTTP(rr, httptest.NewRequest("DELETE", "/cancellation-policies/spot/"+spot.SpotID, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("delete the policy of a deleted spot: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+
+	rr = httptest.NewRecorder()
+	ps.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/cancellation-policies", nil))
+	var policies []model.CancellationPolicy
+	if err := json.NewDecoder(rr.Body).Decode(&policies); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(policies) != 1 || policies[0].TargetID != "lot-a" {
+		t.Errorf("policies = %+v, want only the lot one", policies)
+	}
+}
@@ -2,234 +2,179 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
-	m "github.com/ciameksw/reserve-park/spot/internal/spot/mongodb"
-	"github.com/google/uuid"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/logger"
+	m "github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/problem"
 	"github.com/gorilla/mux"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type addInput struct {
+	LotID        string     `json:"lot_id"`
 	Latitude     float64    `json:"latitude"`
 	Longitude    float64    `json:"longitude"`
 	PricePerHour float64    `json:"price_per_hour"`
 	Size         m.SizeType `json:"size"`
 	Type         m.SpotType `json:"type"`
+	MaxHeightCm  int        `json:"max_height_cm"`
 }
 
 func (s *Server) addSpot(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Adding spot")
+	s.Logger.InfoContext(r.Context(), "Adding spot")
 	var input addInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	data := m.Spot{
-		SpotID:       uuid.NewString(),
-		Latitude:     input.Latitude,
-		Longitude:    input.Longitude,
-		PricePerHour: float64(int(input.PricePerHour*100)) / 100,
-		Size:         input.Size,
-		Type:         input.Type,
-		UpdatedAt:    time.Now(),
-	}
-
-	if err := s.Validator.Struct(data); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	err = s.MongoDB.AddSpot(data)
+	spot, err := s.createSpot(r.Context(), input)
 	if err != nil {
-		s.handleError(w, "Failed to add spot to MongoDB", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Spot added: %v", data.SpotID)
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(data.SpotID))
+	w.Write([]byte(spot.SpotID))
 }
 
 type editInput struct {
 	SpotID       string     `json:"spot_id" validate:"required"`
+	LotID        *string    `json:"lot_id"`
 	Latitude     *float64   `json:"latitude"`
 	Longitude    *float64   `json:"longitude"`
 	PricePerHour *float64   `json:"price_per_hour" validate:"omitempty,gt=0"`
 	Size         m.SizeType `json:"size" validate:"omitempty,oneof=small medium large"`
 	Type         m.SpotType `json:"type" validate:"omitempty,oneof=indoor outdoor ev"`
+	MaxHeightCm  *int       `json:"max_height_cm" validate:"omitempty,gte=0"`
 }
 
 func (s *Server) editSpot(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Editing spot")
+	s.Logger.InfoContext(r.Context(), "Editing spot")
 	var input editInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
-		return
-	}
-
-	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	spot, err := s.MongoDB.GetSpot(input.SpotID)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Spot not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to get spot from MongoDB", err, http.StatusInternalServerError)
-		return
-	}
-
-	updatedSpot, err := updateSpotFields(spot, input)
-	if err != nil {
-		s.handleError(w, "Failed to process input data", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	err = s.MongoDB.EditSpot(updatedSpot)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Spot not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to edit spot in MongoDB", err, http.StatusInternalServerError)
+	if err := s.updateSpot(r.Context(), input); err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Spot edited: %v", input.SpotID)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) deleteSpot(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Deleting spot")
+	s.Logger.InfoContext(r.Context(), "Deleting spot")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing spot ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing spot ID", nil)
 		return
 	}
 
-	err := s.MongoDB.DeleteSpot(id)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Spot not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to delete spot", err, http.StatusInternalServerError)
+	if err := s.removeSpot(r.Context(), id); err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Spot deleted: %v", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) getSpot(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting spot")
+	s.Logger.InfoContext(r.Context(), "Getting spot")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing spot ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing spot ID", nil)
 		return
 	}
 
-	spot, err := s.MongoDB.GetSpot(id)
+	spot, err := s.findSpot(r.Context(), id)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Spot not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to get spot from MongoDB", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Spot found: %v", id)
-	s.writeJSON(w, spot, http.StatusOK)
+	s.writeJSON(w, r, spot, http.StatusOK)
 }
 
 func (s *Server) getAllSpots(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting all spots")
+	s.Logger.InfoContext(r.Context(), "Getting all spots")
+	query := r.URL.Query()
+
+	filter := parseSpotFilter(query)
 
-	spots, err := s.MongoDB.GetAll()
+	opts, err := parseListOptions(query, spotSortFields, "spot_id")
 	if err != nil {
-		s.handleError(w, "Failed to get all spots", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
 		return
 	}
 
-	if len(spots) == 0 {
-		s.Logger.Info.Println("No spots found")
-		s.writeJSON(w, []m.Spot{}, http.StatusOK)
+	page, err := s.listSpots(r.Context(), filter, opts)
+	if err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Spots found: %v", len(spots))
-	s.writeJSON(w, spots, http.StatusOK)
+	s.writeJSON(w, r, page, http.StatusOK)
 }
 
 func (s *Server) getPrice(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting spot's price")
+	s.Logger.InfoContext(r.Context(), "Getting spot's price")
 	var input m.GetPriceInput
 
-	err := json.NewDecoder(r.Body).Decode(&input)
-	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
-		return
-	}
-
-	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
+	ok := s.decodeInput(w, r, &input, func(query url.Values) error {
+		input.SpotID = query.Get("spot_id")
+		if err := parseTimeQuery(query, "start_time", &input.StartTime); err != nil {
+			return err
+		}
+		return parseTimeQuery(query, "end_time", &input.EndTime)
+	})
+	if !ok {
 		return
 	}
 
-	if input.StartTime.After(input.EndTime) {
-		s.handleError(w, "Start time must be before end time", err, http.StatusBadRequest)
-	}
-
-	price, err := s.MongoDB.GetPrice(input)
+	price, err := s.calculatePrice(r.Context(), input)
 	if err != nil {
-		s.handleError(w, "Failed to get the price", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
+		return
 	}
 
-	s.Logger.Info.Printf("Price calculated: %v", price)
 	resp := map[string]interface{}{
 		"spot_id": input.SpotID,
 		"price":   price,
 	}
-	s.writeJSON(w, resp, http.StatusOK)
+	s.writeJSON(w, r, resp, http.StatusOK)
 }
 
 func (s *Server) spotsExist(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Checking if spots exist")
+	s.Logger.InfoContext(r.Context(), "Checking if spots exist")
 
 	var input struct {
 		SpotIDs []string `json:"spot_ids"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
-		return
-	}
-
-	if len(input.SpotIDs) == 0 {
-		s.handleError(w, "spot_ids array is required", nil, http.StatusBadRequest)
+	ok := s.decodeInput(w, r, &input, func(query url.Values) error {
+		input.SpotIDs = parseSpotIDs(query.Get("spot_ids"))
+		return nil
+	})
+	if !ok {
 		return
 	}
 
-	notFound, err := s.MongoDB.CheckSpotsExist(input.SpotIDs)
+	notFound, err := s.findMissingSpots(r.Context(), input.SpotIDs)
 	if err != nil {
-		s.handleError(w, "Failed to check spot existence", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
 		return
 	}
 
@@ -238,24 +183,89 @@ func (s *Server) spotsExist(w http.ResponseWriter, r *http.Request) {
 		"all_exist": len(notFound) == 0,
 	}
 
-	s.writeJSON(w, resp, http.StatusOK)
+	s.writeJSON(w, r, resp, http.StatusOK)
+}
+
+type compatibilityInput struct {
+	SpotIDs []string         `json:"spot_ids" validate:"required,min=1"`
+	Vehicle m.VehicleProfile `json:"vehicle" validate:"required"`
+}
+
+func (s *Server) checkCompatibility(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Checking spot compatibility")
+	var input compatibilityInput
+
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	result, err := s.matchVehicle(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, result, http.StatusOK)
+}
+
+func (s *Server) searchSpots(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Searching spots")
+	var input m.SearchInput
+
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	spots, err := s.findSpots(r.Context(), input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, spots, http.StatusOK)
 }
 
 // Helper function to handle errors
-func (s *Server) handleError(w http.ResponseWriter, message string, err error, statusCode int) {
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request, code problem.Code, message string, err error) {
+	s.writeProblem(w, r, problem.New(code, message), err)
+}
+
+// Helper function to write an error returned by the shared operations
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var pe *problem.Error
+	if !errors.As(err, &pe) {
+		pe = problem.NewError(problem.CodeInternal, "Unexpected server error", err)
+	}
+	s.writeProblem(w, r, pe.Problem, pe.Err)
+}
+
+// Helper function to log and write problem details
+func (s *Server) writeProblem(w http.ResponseWriter, r *http.Request, p problem.Problem, err error) {
+	level := slog.LevelError
+	if p.Status < http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+
+	args := []any{"status", p.Status, "code", p.Code}
 	if err != nil {
-		s.Logger.Error.Printf("%s: %v", message, err)
-	} else {
-		s.Logger.Error.Println(message)
+		args = append(args, "error", err)
 	}
-	http.Error(w, message, statusCode)
+	s.Logger.Log(r.Context(), level, p.Detail, args...)
+
+	p.Instance = r.URL.Path
+	p.RequestID = logger.RequestID(r.Context())
+	problem.Write(w, p)
 }
 
 // Helper function to write JSON responses
-func (s *Server) writeJSON(w http.ResponseWriter, data interface{}, statusCode int) {
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, data interface{}, statusCode int) {
 	j, err := json.Marshal(data)
 	if err != nil {
-		s.handleError(w, "Failed to encode response to JSON", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Failed to encode response to JSON", err)
 		return
 	}
 
@@ -265,6 +275,11 @@ func (s *Server) writeJSON(w http.ResponseWriter, data interface{}, statusCode i
 }
 
 func updateSpotFields(existingSpot m.Spot, input editInput) (m.Spot, error) {
+	// Check if LotID is provided
+	if input.LotID != nil {
+		existingSpot.LotID = *input.LotID
+	}
+
 	// Check if Latitude is provided
 	if input.Latitude != nil {
 		existingSpot.Latitude = *input.Latitude
@@ -290,6 +305,11 @@ func updateSpotFields(existingSpot m.Spot, input editInput) (m.Spot, error) {
 		existingSpot.Type = input.Type
 	}
 
+	// Check if MaxHeightCm is provided, 0 removes the limit
+	if input.MaxHeightCm != nil {
+		existingSpot.MaxHeightCm = *input.MaxHeightCm
+	}
+
 	// Always update the UpdatedAt field
 	existingSpot.UpdatedAt = time.Now()
 
@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/ciameksw/reserve-park/spot/internal/spot/config"
 	"github.com/ciameksw/reserve-park/spot/internal/spot/logger"
-	"github.com/ciameksw/reserve-park/spot/internal/spot/mongodb"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/problem"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store/memory"
 	"github.com/gorilla/mux"
 )
 
@@ -26,14 +29,8 @@ func TestMain(m *testing.M) {
 	// Get config
 	cfg := config.GetConfig()
 
-	// Connect to mock MongoDB
-	db, err := mongodb.ConnectMock()
-	if err != nil {
-		lgr.Error.Fatalf("Failed to connect to mock MongoDB: %v", err)
-	}
-	defer db.Disconnect()
-
-	s = NewServer(lgr, cfg, db)
+	// The store conformance suite covers MongoDB, the handlers run against the in-memory store
+	s = NewServer(lgr, cfg, memory.New())
 
 	os.Exit(m.Run())
 }
@@ -43,8 +40,8 @@ func TestAddSpot(t *testing.T) {
 		Latitude:     34.7365,
 		Longitude:    -86.8271,
 		PricePerHour: 5.00,
-		Size:         mongodb.SizeLarge,
-		Type:         mongodb.SpotTypeOutdoor,
+		Size:         model.SizeLarge,
+		Type:         model.SpotTypeOutdoor,
 	}
 	body, _ := json.Marshal(input)
 	req, err := http.NewRequest("POST", "/spots", bytes.NewBuffer(body))
@@ -64,8 +61,52 @@ func TestAddSpot(t *testing.T) {
 	spotID = rr.Body.String()
 }
 
+func TestAddSpotValidationProblem(t *testing.T) {
+	input := addInput{
+		Latitude:     34.7365,
+		Longitude:    -86.8271,
+		PricePerHour: -1,
+		Size:         "huge",
+		Type:         model.SpotTypeOutdoor,
+	}
+	body, _ := json.Marshal(input)
+	req, err := http.NewRequest("POST", "/spots", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.addSpot)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("handler returned wrong content type: got %v want %v", ct, problem.ContentType)
+	}
+
+	var p problem.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if p.Code != problem.CodeValidationFailed {
+		t.Errorf("handler returned wrong code: got %v want %v", p.Code, problem.CodeValidationFailed)
+	}
+
+	fields := make(map[string]string)
+	for _, fe := range p.Errors {
+		fields[fe.Field] = fe.Rule
+	}
+	if fields["price_per_hour"] != "gt" || fields["size"] != "oneof" {
+		t.Errorf("handler returned wrong field errors: got %v", p.Errors)
+	}
+}
+
 func TestGetAllSpots(t *testing.T) {
-	req, err := http.NewRequest("GET", "/spots", nil)
+	req, err := http.NewRequest("GET", "/spots?sort=price_per_hour&order=desc&limit=10&include_total=true", nil)
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
@@ -79,14 +120,18 @@ func TestGetAllSpots(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var spots []mongodb.Spot
-	err = json.NewDecoder(rr.Body).Decode(&spots)
+	var page model.Page[model.Spot]
+	err = json.NewDecoder(rr.Body).Decode(&page)
 	if err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
 	}
 
-	if len(spots) != 1 {
-		t.Errorf("handler returned wrong number of spots: got %v want %v", len(spots), 1)
+	if len(page.Items) != 1 {
+		t.Errorf("handler returned wrong number of spots: got %v want %v", len(page.Items), 1)
+	}
+
+	if page.TotalCount == nil || *page.TotalCount != 1 {
+		t.Errorf("handler returned wrong total count: got %v want %v", page.TotalCount, 1)
 	}
 }
 
@@ -117,8 +162,8 @@ func TestEditUser(t *testing.T) {
 		Latitude:     &latitude,
 		Longitude:    &longitude,
 		PricePerHour: &pricePerHour,
-		Size:         mongodb.SizeSmall,
-		Type:         mongodb.SpotTypeEV,
+		Size:         model.SizeSmall,
+		Type:         model.SpotTypeEV,
 	}
 	body, _ := json.Marshal(input)
 	req, err := http.NewRequest("PUT", "/spots", bytes.NewBuffer(body))
@@ -134,16 +179,68 @@ func TestEditUser(t *testing.T) {
 	if status := rr.Code; status != http.StatusNoContent {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
 	}
+
+	// The price changed, so the update is followed by a price event
+	events, err := s.Store.PendingEvents(req.Context(), 100)
+	if err != nil {
+		t.Fatalf("Failed to get pending events: %v", err)
+	}
+	if len(events) < 2 || events[len(events)-2].Type != model.EventSpotUpdated || events[len(events)-1].Type != model.EventSpotPriceChanged {
+		t.Fatalf("outbox doesn't end with the update and price events: %+v", events)
+	}
+
+	var change model.PriceChange
+	if err := json.Unmarshal(events[len(events)-1].Payload, &change); err != nil {
+		t.Fatalf("Failed to decode price change: %v", err)
+	}
+	if change.SpotID != spotID || change.NewPricePerHour != pricePerHour {
+		t.Errorf("wrong price change: got %+v", change)
+	}
 }
 
-func TestGetPrice(t *testing.T) {
-	input := mongodb.GetPriceInput{
-		SpotID:    spotID,
-		StartTime: time.Now(),
-		EndTime:   time.Now().Add(2 * time.Hour),
+func TestCheckCompatibility(t *testing.T) {
+	input := compatibilityInput{
+		SpotIDs: []string{spotID},
+		Vehicle: model.VehicleProfile{
+			Size: model.SizeSmall,
+			IsEV: false,
+		},
 	}
 	body, _ := json.Marshal(input)
-	req, err := http.NewRequest("GET", "/spots/price", bytes.NewBuffer(body))
+	req, err := http.NewRequest("POST", "/spots/compatible", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.checkCompatibility)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var result model.CompatibilityResult
+	err = json.NewDecoder(rr.Body).Decode(&result)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// The spot was edited to an EV spot, so a non-EV car must be rejected
+	if _, found := result.Incompatible[spotID]; !found {
+		t.Errorf("handler returned wrong compatibility: got %v want spot %v incompatible", result, spotID)
+	}
+}
+
+func TestGetPrice(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	query := url.Values{}
+	query.Set("spot_id", spotID)
+	query.Set("start_time", start.Format(time.RFC3339))
+	query.Set("end_time", start.Add(2*time.Hour).Format(time.RFC3339))
+
+	req, err := http.NewRequest("GET", "/spots/price?"+query.Encode(), nil)
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
@@ -156,6 +253,9 @@ func TestGetPrice(t *testing.T) {
 	if status := rr.Code; status != http.StatusOK {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
+	if rr.Header().Get("Deprecation") != "" {
+		t.Errorf("handler flagged the query form as deprecated")
+	}
 
 	var response map[string]interface{}
 	err = json.NewDecoder(rr.Body).Decode(&response)
@@ -173,6 +273,31 @@ func TestGetPrice(t *testing.T) {
 	}
 }
 
+func TestGetPriceDeprecatedBody(t *testing.T) {
+	input := model.GetPriceInput{
+		SpotID:    spotID,
+		StartTime: time.Now(),
+		EndTime:   time.Now().Add(2 * time.Hour),
+	}
+	body, _ := json.Marshal(input)
+	req, err := http.NewRequest("GET", "/spots/price", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.getPrice)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if rr.Header().Get("Deprecation") != "true" {
+		t.Errorf("handler did not flag the body form as deprecated")
+	}
+}
+
 func TestDeleteUser(t *testing.T) {
 	req, err := http.NewRequest("DELETE", "/spots/"+spotID, nil)
 	if err != nil {
@@ -190,3 +315,44 @@ func TestDeleteUser(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 }
+
+func TestHealthz(t *testing.T) {
+	req, err := http.NewRequest("GET", "/healthz", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.healthz)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	req, err := http.NewRequest("GET", "/readyz", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.readyz)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var resp healthResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.Checks["mongodb"] != "ok" {
+		t.Errorf("handler returned wrong mongodb check: got %v want %v", resp.Checks["mongodb"], "ok")
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	m "github.com/ciameksw/reserve-park/spot/internal/spot/model"
+)
+
+var spotSortFields = map[string]struct{}{
+	"spot_id":        {},
+	"lot_id":         {},
+	"price_per_hour": {},
+	"updated_at":     {},
+}
+
+// Helper function to parse pagination and sorting query parameters
+func parseListOptions(query url.Values, sortFields map[string]struct{}, defaultSort string) (m.ListOptions, error) {
+	var limit int64
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit <= 0 {
+			return m.ListOptions{}, errInvalidLimit
+		}
+	}
+
+	return newListOptions(limit, query.Get("cursor"), query.Get("sort"), query.Get("order"),
+		query.Get("include_total") == "true", sortFields, defaultSort)
+}
+
+var errInvalidLimit = errors.New("limit must be a number between 1 and " + strconv.Itoa(m.MaxLimit))
+
+// Helper function to check pagination and sorting options, a zero limit picks the default
+func newListOptions(limit int64, cursor, sort, order string, includeTotal bool, sortFields map[string]struct{}, defaultSort string) (m.ListOptions, error) {
+	opts := m.ListOptions{
+		Limit:        limit,
+		Cursor:       cursor,
+		SortBy:       defaultSort,
+		IncludeTotal: includeTotal,
+	}
+
+	if limit < 0 || limit > m.MaxLimit {
+		return opts, errInvalidLimit
+	}
+
+	if sort != "" {
+		if _, ok := sortFields[sort]; !ok {
+			return opts, errors.New("unsupported sort field: " + sort)
+		}
+		opts.SortBy = sort
+	}
+
+	switch order {
+	case "", "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, errors.New("order must be asc or desc")
+	}
+
+	return opts, nil
+}
+
+// Helper function to parse spot filters from query parameters
+func parseSpotFilter(query url.Values) m.SpotFilter {
+	return m.SpotFilter{
+		LotID: query.Get("lot_id"),
+		Size:  m.SizeType(query.Get("size")),
+		Type:  m.SpotType(query.Get("type")),
+	}
+}
+
+// Helper function to reject filters on unknown sizes and types
+func validateSpotFilter(filter m.SpotFilter) error {
+	switch filter.Size {
+	case "", m.SizeSmall, m.SizeMedium, m.SizeLarge:
+	default:
+		return errors.New("unsupported size: " + string(filter.Size))
+	}
+
+	switch filter.Type {
+	case "", m.SpotTypeIndoor, m.SpotTypeOutdoor, m.SpotTypeEV:
+	default:
+		return errors.New("unsupported type: " + string(filter.Type))
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	m "github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/problem"
+	"github.com/google/uuid"
+)
+
+// The operations below are shared by the HTTP handlers and the gRPC API.
+// Anything the caller should see is returned as a *problem.Error.
+
+func (s *Server) createSpot(ctx context.Context, input addInput) (m.Spot, error) {
+	data := m.Spot{
+		SpotID:       uuid.NewString(),
+		LotID:        input.LotID,
+		Latitude:     input.Latitude,
+		Longitude:    input.Longitude,
+		PricePerHour: float64(int(input.PricePerHour*100)) / 100,
+		Size:         input.Size,
+		Type:         input.Type,
+		MaxHeightCm:  input.MaxHeightCm,
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := s.Validator.Struct(data); err != nil {
+		return data, problem.Invalid(err)
+	}
+
+	event, err := m.NewEvent(m.EventSpotCreated, data.SpotID, data)
+	if err != nil {
+		return data, problem.NewError(problem.CodeInternal, "Failed to build the spot event", err)
+	}
+
+	if err := s.Store.AddSpot(ctx, data, event); err != nil {
+		return data, problem.NewError(problem.CodeInternal, "Failed to add spot", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Spot added", "spot_id", data.SpotID)
+	return data, nil
+}
+
+func (s *Server) updateSpot(ctx context.Context, input editInput) error {
+	if err := s.Validator.Struct(input); err != nil {
+		return problem.Invalid(err)
+	}
+
+	spot, err := s.Store.GetSpot(ctx, input.SpotID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeSpotNotFound, "Spot not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to get spot", err)
+	}
+
+	updatedSpot, err := updateSpotFields(spot, input)
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to process input data", err)
+	}
+
+	events, err := spotUpdatedEvents(spot, updatedSpot)
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to build the spot events", err)
+	}
+
+	err = s.Store.EditSpot(ctx, updatedSpot, events...)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeSpotNotFound, "Spot not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to edit spot", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Spot edited", "spot_id", input.SpotID)
+	return nil
+}
+
+// spotUpdatedEvents describes an edit, with a separate event for a price change
+// so that consumers interested only in prices don't have to diff the spots
+func spotUpdatedEvents(old, updated m.Spot) ([]m.Event, error) {
+	event, err := m.NewEvent(m.EventSpotUpdated, updated.SpotID, updated)
+	if err != nil {
+		return nil, err
+	}
+	events := []m.Event{event}
+
+	if old.PricePerHour != updated.PricePerHour {
+		event, err := m.NewEvent(m.EventSpotPriceChanged, updated.SpotID, m.PriceChange{
+			SpotID:          updated.SpotID,
+			OldPricePerHour: old.PricePerHour,
+			NewPricePerHour: updated.PricePerHour,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (s *Server) removeSpot(ctx context.Context, spotID string) error {
+	event, err := m.NewEvent(m.EventSpotDeleted, spotID, map[string]string{"spot_id": spotID})
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to build the spot event", err)
+	}
+
+	err = s.Store.DeleteSpot(ctx, spotID, event)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeSpotNotFound, "Spot not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to delete spot", err)
+	}
+
+	// The policy of a deleted spot would only linger, failing to drop it doesn't fail the delete
+	err = s.Store.DeleteCancellationPolicy(ctx, m.PolicyScopeSpot, spotID)
+	if err != nil && err != m.ErrNotFound {
+		s.Logger.WarnContext(ctx, "Failed to delete the spot's cancellation policy", "spot_id", spotID, "error", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Spot deleted", "spot_id", spotID)
+	return nil
+}
+
+func (s *Server) findSpot(ctx context.Context, spotID string) (m.Spot, error) {
+	spot, err := s.Store.GetSpot(ctx, spotID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return spot, problem.NewError(problem.CodeSpotNotFound, "Spot not found", err)
+		}
+
+		return spot, problem.NewError(problem.CodeInternal, "Failed to get spot", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Spot found", "spot_id", spotID)
+	return spot, nil
+}
+
+func (s *Server) listSpots(ctx context.Context, filter m.SpotFilter, opts m.ListOptions) (m.Page[m.Spot], error) {
+	if err := validateSpotFilter(filter); err != nil {
+		return m.Page[m.Spot]{}, problem.NewError(problem.CodeBadRequest, err.Error(), err)
+	}
+
+	page, err := s.Store.GetAll(ctx, filter, opts)
+	if err != nil {
+		if err == m.ErrInvalidCursor {
+			return page, problem.NewError(problem.CodeInvalidCursor, "Invalid cursor", err)
+		}
+
+		return page, problem.NewError(problem.CodeInternal, "Failed to get all spots", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Spots found", "count", len(page.Items))
+	return page, nil
+}
+
+func (s *Server) calculatePrice(ctx context.Context, input m.GetPriceInput) (float64, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return 0, problem.Invalid(err)
+	}
+
+	if input.StartTime.After(input.EndTime) {
+		return 0, problem.NewError(problem.CodeBadRequest, "Start time must be before end time", nil)
+	}
+
+	price, err := s.Store.GetPrice(ctx, input)
+	if err != nil {
+		return 0, problem.NewError(problem.CodeInternal, "Failed to get the price", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Price calculated", "price", price)
+	return price, nil
+}
+
+func (s *Server) findMissingSpots(ctx context.Context, spotIDs []string) ([]string, error) {
+	if len(spotIDs) == 0 {
+		return nil, problem.NewError(problem.CodeBadRequest, "spot_ids are required", nil)
+	}
+
+	notFound, err := s.Store.CheckSpotsExist(ctx, spotIDs)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to check spot existence", err)
+	}
+
+	return notFound, nil
+}
+
+func (s *Server) matchVehicle(ctx context.Context, input compatibilityInput) (m.CompatibilityResult, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return m.CompatibilityResult{}, problem.Invalid(err)
+	}
+
+	result, err := s.Store.CheckCompatibility(ctx, input.SpotIDs, input.Vehicle)
+	if err != nil {
+		return result, problem.NewError(problem.CodeInternal, "Failed to check spot compatibility", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Compatible spots found", "count", len(result.Compatible))
+	return result, nil
+}
+
+func (s *Server) findSpots(ctx context.Context, input m.SearchInput) ([]m.Spot, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return nil, problem.Invalid(err)
+	}
+
+	spots, err := s.Store.SearchSpots(ctx, input)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInternal, "Failed to search spots", err)
+	}
+
+	if len(spots) == 0 {
+		s.Logger.InfoContext(ctx, "No spots found")
+		return []m.Spot{}, nil
+	}
+
+	s.Logger.InfoContext(ctx, "Spots found", "count", len(spots))
+	return spots, nil
+}
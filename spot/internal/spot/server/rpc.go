@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	m "github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/pb/spotpb"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/problem"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// spotRPC serves the internal gRPC API on top of the same operations as the HTTP handlers
+type spotRPC struct {
+	spotpb.UnimplementedSpotServiceServer
+	s *Server
+}
+
+func (rpc *spotRPC) AddSpot(ctx context.Context, req *spotpb.AddSpotRequest) (*spotpb.AddSpotResponse, error) {
+	spot, err := rpc.s.createSpot(ctx, addInput{
+		LotID:        req.GetLotId(),
+		Latitude:     req.GetLatitude(),
+		Longitude:    req.GetLongitude(),
+		PricePerHour: req.GetPricePerHour(),
+		Size:         m.SizeType(req.GetSize()),
+		Type:         m.SpotType(req.GetType()),
+		MaxHeightCm:  int(req.GetMaxHeightCm()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &spotpb.AddSpotResponse{SpotId: spot.SpotID}, nil
+}
+
+func (rpc *spotRPC) EditSpot(ctx context.Context, req *spotpb.EditSpotRequest) (*emptypb.Empty, error) {
+	input := editInput{
+		SpotID:       req.GetSpotId(),
+		LotID:        req.LotId,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		PricePerHour: req.PricePerHour,
+		Size:         m.SizeType(req.GetSize()),
+		Type:         m.SpotType(req.GetType()),
+	}
+	if req.MaxHeightCm != nil {
+		maxHeightCm := int(req.GetMaxHeightCm())
+		input.MaxHeightCm = &maxHeightCm
+	}
+
+	if err := rpc.s.updateSpot(ctx, input); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *spotRPC) DeleteSpot(ctx context.Context, req *spotpb.DeleteSpotRequest) (*emptypb.Empty, error) {
+	if err := rpc.s.removeSpot(ctx, req.GetSpotId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *spotRPC) GetSpot(ctx context.Context, req *spotpb.GetSpotRequest) (*spotpb.Spot, error) {
+	spot, err := rpc.s.findSpot(ctx, req.GetSpotId())
+	if err != nil {
+		return nil, err
+	}
+
+	return spotToProto(spot), nil
+}
+
+func (rpc *spotRPC) ListSpots(ctx context.Context, req *spotpb.ListSpotsRequest) (*spotpb.ListSpotsResponse, error) {
+	o := req.GetOptions()
+	opts, err := newListOptions(o.GetLimit(), o.GetCursor(), o.GetSort(), o.GetOrder(), o.GetIncludeTotal(), spotSortFields, "spot_id")
+	if err != nil {
+		return nil, problem.NewError(problem.CodeBadRequest, err.Error(), err)
+	}
+
+	filter := m.SpotFilter{
+		LotID: req.GetLotId(),
+		Size:  m.SizeType(req.GetSize()),
+		Type:  m.SpotType(req.GetType()),
+	}
+
+	page, err := rpc.s.listSpots(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &spotpb.ListSpotsResponse{
+		NextCursor: page.NextCursor,
+		TotalCount: page.TotalCount,
+	}
+	for _, spot := range page.Items {
+		resp.Items = append(resp.Items, spotToProto(spot))
+	}
+
+	return resp, nil
+}
+
+func (rpc *spotRPC) GetPrice(ctx context.Context, req *spotpb.GetPriceRequest) (*spotpb.GetPriceResponse, error) {
+	price, err := rpc.s.calculatePrice(ctx, m.GetPriceInput{
+		SpotID:    req.GetSpotId(),
+		StartTime: fromTimestamp(req.GetStartTime()),
+		EndTime:   fromTimestamp(req.GetEndTime()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &spotpb.GetPriceResponse{SpotId: req.GetSpotId(), Price: price}, nil
+}
+
+func (rpc *spotRPC) CheckSpotsExist(ctx context.Context, req *spotpb.CheckSpotsExistRequest) (*spotpb.CheckSpotsExistResponse, error) {
+	notFound, err := rpc.s.findMissingSpots(ctx, req.GetSpotIds())
+	if err != nil {
+		return nil, err
+	}
+
+	return &spotpb.CheckSpotsExistResponse{
+		AllExist: len(notFound) == 0,
+		NotFound: notFound,
+	}, nil
+}
+
+func (rpc *spotRPC) CheckCompatibility(ctx context.Context, req *spotpb.CheckCompatibilityRequest) (*spotpb.CheckCompatibilityResponse, error) {
+	result, err := rpc.s.matchVehicle(ctx, compatibilityInput{
+		SpotIDs: req.GetSpotIds(),
+		Vehicle: vehicleFromProto(req.GetVehicle()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &spotpb.CheckCompatibilityResponse{
+		Compatible:   result.Compatible,
+		Incompatible: result.Incompatible,
+		NotFound:     result.NotFound,
+	}, nil
+}
+
+func (rpc *spotRPC) SearchSpots(ctx context.Context, req *spotpb.SearchSpotsRequest) (*spotpb.SearchSpotsResponse, error) {
+	input := m.SearchInput{
+		LotID:           req.GetLotId(),
+		Size:            m.SizeType(req.GetSize()),
+		Type:            m.SpotType(req.GetType()),
+		MaxPricePerHour: req.GetMaxPricePerHour(),
+	}
+	if req.Vehicle != nil {
+		vehicle := vehicleFromProto(req.GetVehicle())
+		input.Vehicle = &vehicle
+	}
+
+	spots, err := rpc.s.findSpots(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Priced the same way as GetPrice, so callers never price spots themselves
+	priced := req.StartTime != nil && req.EndTime != nil
+	if priced && !req.GetEndTime().AsTime().After(req.GetStartTime().AsTime()) {
+		return nil, problem.NewError(problem.CodeBadRequest, "Start time must be before end time", nil)
+	}
+
+	resp := &spotpb.SearchSpotsResponse{}
+	if priced {
+		resp.Prices = make(map[string]float64, len(spots))
+	}
+	for _, spot := range spots {
+		resp.Spots = append(resp.Spots, spotToProto(spot))
+		if priced {
+			resp.Prices[spot.SpotID] = spot.Price(req.GetStartTime().AsTime(), req.GetEndTime().AsTime())
+		}
+	}
+
+	return resp, nil
+}
+
+func spotToProto(spot m.Spot) *spotpb.Spot {
+	return &spotpb.Spot{
+		Id:           spot.ID.Hex(),
+		SpotId:       spot.SpotID,
+		LotId:        spot.LotID,
+		Latitude:     spot.Latitude,
+		Longitude:    spot.Longitude,
+		PricePerHour: spot.PricePerHour,
+		Size:         string(spot.Size),
+		Type:         string(spot.Type),
+		MaxHeightCm:  int32(spot.MaxHeightCm),
+		UpdatedAt:    timestamppb.New(spot.UpdatedAt),
+	}
+}
+
+func vehicleFromProto(vehicle *spotpb.VehicleProfile) m.VehicleProfile {
+	return m.VehicleProfile{
+		Size:     m.SizeType(vehicle.GetSize()),
+		IsEV:     vehicle.GetIsEv(),
+		HeightCm: int(vehicle.GetHeightCm()),
+	}
+}
+
+// fromTimestamp keeps a missing timestamp as the zero time, so that it fails the required check
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/config"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/logger"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/pb/spotpb"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store/memory"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSearchSpotsPrices(t *testing.T) {
+	rpc := &spotRPC{s: NewServer(logger.GetLogger(), config.GetConfig(), memory.New())}
+	ctx := context.Background()
+
+	spot, err := rpc.s.createSpot(ctx, addInput{LotID: "lot-search", Latitude: 52.23, Longitude: 21.01, PricePerHour: 3.333, Size: model.SizeMedium, Type: model.SpotTypeIndoor})
+	if err != nil {
+		t.Fatalf("createSpot: %v", err)
+	}
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		end  time.Time
+		want float64
+	}{
+		{"truncated to cents", start.Add(90 * time.Minute), 4.99},
+		{"shorter than a cent", start.Add(time.Second), 0.01},
+	}
+
+	for _, tt := range tests {
+		resp, err := rpc.SearchSpots(ctx, &spotpb.SearchSpotsRequest{LotId: "lot-search", StartTime: timestamppb.New(start), EndTime: timestamppb.New(tt.end)})
+		if err != nil {
+			t.Fatalf("%v: SearchSpots: %v", tt.name, err)
+		}
+		if got := resp.GetPrices()[spot.SpotID]; got != tt.want {
+			t.Errorf("%v: price = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	resp, err := rpc.SearchSpots(ctx, &spotpb.SearchSpotsRequest{LotId: "lot-search"})
+	if err != nil || len(resp.GetPrices()) != 0 {
+		t.Errorf("SearchSpots without a timeframe = %v, %v, want no prices", resp.GetPrices(), err)
+	}
+}
@@ -1,47 +1,132 @@
 package server
 
 import (
+	"context"
+	"net"
 	"net/http"
 
 	"github.com/ciameksw/reserve-park/spot/internal/spot/config"
 	"github.com/ciameksw/reserve-park/spot/internal/spot/logger"
-	"github.com/ciameksw/reserve-park/spot/internal/spot/mongodb"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/metrics"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/problem"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"google.golang.org/grpc/health"
 )
 
 type Server struct {
 	Logger    *logger.Logger
 	Config    *config.Config
-	MongoDB   *mongodb.MongoDB
+	Store     store.SpotStore
 	Validator *validator.Validate
 }
 
-func NewServer(log *logger.Logger, cfg *config.Config, db *mongodb.MongoDB) *Server {
+func NewServer(log *logger.Logger, cfg *config.Config, st store.SpotStore) *Server {
+	v := validator.New()
+	v.RegisterTagNameFunc(problem.JSONFieldName)
+
 	return &Server{
 		Logger:    log,
 		Config:    cfg,
-		MongoDB:   db,
-		Validator: validator.New(),
+		Store:     st,
+		Validator: v,
 	}
 }
 
-func (s *Server) Start() {
+// Handler returns the router with all routes and middlewares
+func (s *Server) Handler() http.Handler {
 	r := mux.NewRouter()
+	r.NotFoundHandler = logger.RequestIDMiddleware(http.HandlerFunc(s.notFound))
+	r.MethodNotAllowedHandler = logger.RequestIDMiddleware(http.HandlerFunc(s.methodNotAllowed))
+	r.Use(logger.RequestIDMiddleware, otelmux.Middleware("spot"), metrics.Middleware)
+
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	r.HandleFunc("/healthz", s.healthz).Methods("GET")
+	r.HandleFunc("/readyz", s.readyz).Methods("GET")
 
 	r.HandleFunc("/spots/price", s.getPrice).Methods("GET")
-	r.HandleFunc("/spots/exist", s.spotsExist).Methods("GET")
+	r.HandleFunc("/spots/exist", s.spotsExist).Methods("GET", "POST")
+	r.HandleFunc("/spots/compatible", s.checkCompatibility).Methods("POST")
+	r.HandleFunc("/spots/search", s.searchSpots).Methods("POST")
 
 	r.HandleFunc("/spots", s.addSpot).Methods("POST")
 	r.HandleFunc("/spots", s.editSpot).Methods("PATCH")
 	r.HandleFunc("/spots/{id}", s.deleteSpot).Methods("DELETE")
 	r.HandleFunc("/spots/{id}", s.getSpot).Methods("GET")
 	r.HandleFunc("/spots", s.getAllSpots).Methods("GET")
+	r.HandleFunc("/spots/{id}/cancellation-policy", s.getSpotCancellationPolicy).Methods("GET")
+
+	r.HandleFunc("/cancellation-policies", s.putCancellationPolicy).Methods("PUT")
+	r.HandleFunc("/cancellation-policies", s.getCancellationPolicies).Methods("GET")
+	r.HandleFunc("/cancellation-policies/{scope}/{id}", s.deleteCancellationPolicy).Methods("DELETE")
+
+	return r
+}
 
-	addr := s.Config.ServerHost + ":" + s.Config.ServerPort
-	s.Logger.Info.Printf("Server started at %s\n", addr)
-	err := http.ListenAndServe(addr, r)
+// Start serves HTTP and gRPC requests until the context is canceled, then stops
+// accepting new connections and waits for in-flight requests to finish.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:         s.Config.ServerHost + ":" + s.Config.ServerPort,
+		Handler:      s.Handler(),
+		ReadTimeout:  s.Config.ReadTimeout,
+		WriteTimeout: s.Config.WriteTimeout,
+		IdleTimeout:  s.Config.IdleTimeout,
+	}
+
+	lis, err := net.Listen("tcp", s.Config.ServerHost+":"+s.Config.GRPCPort)
 	if err != nil {
-		s.Logger.Error.Fatalf("Failed to start server: %v", err)
+		return err
+	}
+	healthServer := health.NewServer()
+	grpcSrv := s.GRPCServer(healthServer)
+
+	errCh := make(chan error, 2)
+	go func() {
+		s.Logger.Info("Server started", "addr", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	go func() {
+		s.Logger.Info("gRPC server started", "addr", lis.Addr().String())
+		errCh <- grpcSrv.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		grpcSrv.Stop()
+		srv.Close()
+		return err
+	case <-ctx.Done():
 	}
+
+	s.Logger.Info("Shutting down server")
+	healthServer.Shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.Config.ShutdownTimeout)
+	defer cancel()
+
+	// In-flight calls get the same deadline as HTTP requests, then they are cut off
+	stopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+
+	err = srv.Shutdown(shutdownCtx)
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+
+	return err
+}
+
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	s.handleError(w, r, problem.CodeNotFound, "No route matches "+r.URL.Path, nil)
+}
+
+func (s *Server) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	s.handleError(w, r, problem.CodeMethodNotAllowed, "Method "+r.Method+" is not allowed on "+r.URL.Path, nil)
 }
@@ -0,0 +1,168 @@
+// Package memory is an in-memory implementation of store.SpotStore.
+// It is meant for tests and local experiments, data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ store.SpotStore = (*Store)(nil)
+
+type Store struct {
+	mu       sync.RWMutex
+	spots    []model.Spot
+	outbox   outbox
+	policies map[policyKey]model.CancellationPolicy
+}
+
+func New() *Store {
+	return &Store{}
+}
+
+func (s *Store) AddSpot(ctx context.Context, spot model.Spot, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if spot.ID.IsZero() {
+		spot.ID = primitive.NewObjectID()
+	}
+	s.spots = append(s.spots, spot)
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) EditSpot(ctx context.Context, input model.Spot, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(input.SpotID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	// Like a document replace, the stored ID survives the edit
+	if input.ID.IsZero() {
+		input.ID = s.spots[i].ID
+	}
+	s.spots[i] = input
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) DeleteSpot(ctx context.Context, spotID string, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(spotID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	s.spots = append(s.spots[:i], s.spots[i+1:]...)
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) GetSpot(ctx context.Context, spotID string) (model.Spot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.index(spotID)
+	if i < 0 {
+		return model.Spot{}, model.ErrNotFound
+	}
+	return s.spots[i], nil
+}
+
+func (s *Store) GetAll(ctx context.Context, input model.SpotFilter, opts model.ListOptions) (model.Page[model.Spot], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matching []model.Spot
+	for _, spot := range s.spots {
+		if input.LotID != "" && spot.LotID != input.LotID {
+			continue
+		}
+		if input.Size != "" && spot.Size != input.Size {
+			continue
+		}
+		if input.Type != "" && spot.Type != input.Type {
+			continue
+		}
+		matching = append(matching, spot)
+	}
+
+	return paginate(matching, func(spot model.Spot) primitive.ObjectID { return spot.ID }, opts)
+}
+
+func (s *Store) GetPrice(ctx context.Context, input model.GetPriceInput) (float64, error) {
+	spot, err := s.GetSpot(ctx, input.SpotID)
+	if err != nil {
+		return 0, err
+	}
+	return spot.Price(input.StartTime, input.EndTime), nil
+}
+
+func (s *Store) CheckSpotsExist(ctx context.Context, spotIDs []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var notFound []string
+	for _, spotID := range spotIDs {
+		if s.index(spotID) < 0 {
+			notFound = append(notFound, spotID)
+		}
+	}
+	return notFound, nil
+}
+
+func (s *Store) SearchSpots(ctx context.Context, input model.SearchInput) ([]model.Spot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var spots []model.Spot
+	for _, spot := range s.spots {
+		if input.LotID != "" && spot.LotID != input.LotID {
+			continue
+		}
+		if input.Size != "" && spot.Size != input.Size {
+			continue
+		}
+		if input.Type != "" && spot.Type != input.Type {
+			continue
+		}
+		if input.MaxPricePerHour > 0 && spot.PricePerHour > input.MaxPricePerHour {
+			continue
+		}
+		if input.Vehicle != nil && spot.Incompatibility(*input.Vehicle) != "" {
+			continue
+		}
+		spots = append(spots, spot)
+	}
+	return spots, nil
+}
+
+func (s *Store) CheckCompatibility(ctx context.Context, spotIDs []string, vehicle model.VehicleProfile) (model.CompatibilityResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return model.NewCompatibilityResult(spotIDs, s.spots, vehicle), nil
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Store) index(spotID string) int {
+	for i, spot := range s.spots {
+		if spot.SpotID == spotID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.SpotStore {
+		return New()
+	})
+}
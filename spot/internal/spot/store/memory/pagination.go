@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"encoding/base64"
+	"sort"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pageCursor has the same shape as the MongoDB store's cursor: the sort value
+// and the ID of the last item of the page.
+type pageCursor struct {
+	Value bson.RawValue      `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+type sortedItem[T any] struct {
+	item  T
+	value bson.RawValue
+	id    primitive.ObjectID
+}
+
+// paginate sorts the items by the bson field named in opts.SortBy, with the ID
+// as a tiebreaker, and returns the page that follows opts.Cursor.
+func paginate[T any](items []T, idOf func(T) primitive.ObjectID, opts model.ListOptions) (model.Page[T], error) {
+	page := model.Page[T]{Items: []T{}}
+
+	if opts.IncludeTotal {
+		total := int64(len(items))
+		page.TotalCount = &total
+	}
+
+	if opts.Limit <= 0 || opts.Limit > model.MaxLimit {
+		opts.Limit = model.DefaultLimit
+	}
+
+	sorted := make([]sortedItem[T], 0, len(items))
+	for _, item := range items {
+		doc, err := bson.Marshal(item)
+		if err != nil {
+			return page, err
+		}
+		sorted = append(sorted, sortedItem[T]{item: item, value: bson.Raw(doc).Lookup(opts.SortBy), id: idOf(item)})
+	}
+
+	less := func(a, b sortedItem[T]) bool {
+		if c := compareValues(a.value, b.value); c != 0 {
+			return c < 0
+		}
+		return a.id.Hex() < b.id.Hex()
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if opts.Descending {
+			return less(sorted[j], sorted[i])
+		}
+		return less(sorted[i], sorted[j])
+	})
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return page, err
+		}
+
+		after := sortedItem[T]{value: c.Value, id: c.ID}
+		start := len(sorted)
+		for i, item := range sorted {
+			if (!opts.Descending && less(after, item)) || (opts.Descending && less(item, after)) {
+				start = i
+				break
+			}
+		}
+		sorted = sorted[start:]
+	}
+
+	hasMore := int64(len(sorted)) > opts.Limit
+	if hasMore {
+		sorted = sorted[:opts.Limit]
+	}
+
+	for _, item := range sorted {
+		page.Items = append(page.Items, item.item)
+	}
+
+	if hasMore {
+		last := sorted[len(sorted)-1]
+		b, err := bson.Marshal(pageCursor{Value: last.value, ID: last.id})
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	return page, nil
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	var c pageCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, model.ErrInvalidCursor
+	}
+	if err := bson.Unmarshal(b, &c); err != nil {
+		return c, model.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// compareValues orders the bson types the services sort by: strings, numbers and dates
+func compareValues(a, b bson.RawValue) int {
+	switch {
+	case a.Type == bson.TypeString && b.Type == bson.TypeString:
+		return compareOrdered(a.StringValue(), b.StringValue())
+	case a.Type == bson.TypeDateTime && b.Type == bson.TypeDateTime:
+		return compareOrdered(a.DateTime(), b.DateTime())
+	}
+
+	af, aok := asFloat(a)
+	bf, bok := asFloat(b)
+	if aok && bok {
+		return compareOrdered(af, bf)
+	}
+	return 0
+}
+
+func asFloat(v bson.RawValue) (float64, bool) {
+	switch v.Type {
+	case bson.TypeDouble:
+		return v.Double(), true
+	case bson.TypeInt32:
+		return float64(v.Int32()), true
+	case bson.TypeInt64:
+		return float64(v.Int64()), true
+	}
+	return 0, false
+}
+
+func compareOrdered[T ~string | ~int64 | ~float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
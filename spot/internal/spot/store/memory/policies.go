@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+)
+
+type policyKey struct {
+	scope    model.PolicyScope
+	targetID string
+}
+
+func (s *Store) SetCancellationPolicy(ctx context.Context, policy model.CancellationPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.policies == nil {
+		s.policies = make(map[policyKey]model.CancellationPolicy)
+	}
+	s.policies[policyKey{policy.Scope, policy.TargetID}] = policy
+	return nil
+}
+
+func (s *Store) GetCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) (model.CancellationPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policy, ok := s.policies[policyKey{scope, targetID}]
+	if !ok {
+		return model.CancellationPolicy{}, model.ErrNotFound
+	}
+	return policy, nil
+}
+
+func (s *Store) DeleteCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := policyKey{scope, targetID}
+	if _, ok := s.policies[key]; !ok {
+		return model.ErrNotFound
+	}
+	delete(s.policies, key)
+	return nil
+}
+
+func (s *Store) GetCancellationPolicies(ctx context.Context) ([]model.CancellationPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policies := make([]model.CancellationPolicy, 0, len(s.policies))
+	for _, policy := range s.policies {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Scope != policies[j].Scope {
+			return policies[i].Scope < policies[j].Scope
+		}
+		return policies[i].TargetID < policies[j].TargetID
+	})
+	return policies, nil
+}
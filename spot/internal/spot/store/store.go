@@ -0,0 +1,62 @@
+// Package store defines the persistence contract of the spot service.
+// The server depends only on this interface, so the backing database can be
+// swapped (MongoDB or PostgreSQL in production, an in-memory store in tests).
+package store
+
+import (
+	"context"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+)
+
+// SpotStore returns model.ErrNotFound when the requested spot doesn't exist
+// and model.ErrInvalidCursor when a page cursor can't be decoded.
+//
+// The write methods take the events describing the change and add them to
+// the outbox atomically with it, see Outbox.
+type SpotStore interface {
+	Outbox
+	PolicyStore
+
+	AddSpot(ctx context.Context, spot model.Spot, events ...model.Event) error
+	EditSpot(ctx context.Context, spot model.Spot, events ...model.Event) error
+	DeleteSpot(ctx context.Context, spotID string, events ...model.Event) error
+	GetSpot(ctx context.Context, spotID string) (model.Spot, error)
+	GetAll(ctx context.Context, filter model.SpotFilter, opts model.ListOptions) (model.Page[model.Spot], error)
+
+	// GetPrice returns the price of the spot for the timeframe
+	GetPrice(ctx context.Context, input model.GetPriceInput) (float64, error)
+
+	// CheckSpotsExist returns the IDs of the spots that don't exist
+	CheckSpotsExist(ctx context.Context, spotIDs []string) ([]string, error)
+
+	// SearchSpots returns the spots matching the input, leaving out the ones the vehicle can't use
+	SearchSpots(ctx context.Context, input model.SearchInput) ([]model.Spot, error)
+	CheckCompatibility(ctx context.Context, spotIDs []string, vehicle model.VehicleProfile) (model.CompatibilityResult, error)
+
+	Ping(ctx context.Context) error
+}
+
+// Outbox holds the events written with the changes until the relay publishes them.
+// MongoDB deployments without transactions (standalone servers) write the events
+// right after the change instead, so a crash in between can lose them.
+type Outbox interface {
+	// PendingEvents returns up to limit unpublished events, oldest first
+	PendingEvents(ctx context.Context, limit int) ([]model.Event, error)
+
+	// MarkPublished flags the events so they aren't returned as pending again
+	MarkPublished(ctx context.Context, ids []string) error
+}
+
+// PolicyStore keeps the cancellation policies of the spots and lots. A target has at
+// most one policy per scope, GetCancellationPolicy and DeleteCancellationPolicy return
+// model.ErrNotFound when it has none.
+type PolicyStore interface {
+	// SetCancellationPolicy adds the policy or replaces the one of its scope and target
+	SetCancellationPolicy(ctx context.Context, policy model.CancellationPolicy) error
+	GetCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) (model.CancellationPolicy, error)
+	DeleteCancellationPolicy(ctx context.Context, scope model.PolicyScope, targetID string) error
+
+	// GetCancellationPolicies returns all the policies sorted by scope and target
+	GetCancellationPolicies(ctx context.Context) ([]model.CancellationPolicy, error)
+}
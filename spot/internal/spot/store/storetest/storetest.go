@@ -0,0 +1,446 @@
+// Package storetest is a conformance suite that every store.SpotStore
+// implementation must pass, so that they behave the same behind the server.
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/spot/internal/spot/model"
+	"github.com/ciameksw/reserve-park/spot/internal/spot/store"
+)
+
+// Run runs the suite, newStore must return an empty store for every call
+func Run(t *testing.T, newStore func(t *testing.T) store.SpotStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, st store.SpotStore)
+	}{
+		{"AddAndGet", testAddAndGet},
+		{"NotFound", testNotFound},
+		{"Edit", testEdit},
+		{"Delete", testDelete},
+		{"GetAll", testGetAll},
+		{"InvalidCursor", testInvalidCursor},
+		{"GetPrice", testGetPrice},
+		{"CheckSpotsExist", testCheckSpotsExist},
+		{"SearchSpots", testSearchSpots},
+		{"CheckCompatibility", testCheckCompatibility},
+		{"Outbox", testOutbox},
+		{"CancellationPolicies", testCancellationPolicies},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+var updatedAt = time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+
+func spot(id, lotID string, price float64, size model.SizeType, spotType model.SpotType) model.Spot {
+	return model.Spot{
+		SpotID:       id,
+		LotID:        lotID,
+		Latitude:     52.23,
+		Longitude:    21.01,
+		PricePerHour: price,
+		Size:         size,
+		Type:         spotType,
+		UpdatedAt:    updatedAt,
+	}
+}
+
+func mustAdd(t *testing.T, st store.SpotStore, spots ...model.Spot) {
+	t.Helper()
+	for _, s := range spots {
+		if err := st.AddSpot(context.Background(), s); err != nil {
+			t.Fatalf("AddSpot(%v): %v", s.SpotID, err)
+		}
+	}
+}
+
+func ids(spots []model.Spot) []string {
+	resp := make([]string, 0, len(spots))
+	for _, s := range spots {
+		resp = append(resp, s.SpotID)
+	}
+	return resp
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testAddAndGet(t *testing.T, st store.SpotStore) {
+	mustAdd(t, st, spot("s1", "lot-a", 5, model.SizeMedium, model.SpotTypeIndoor))
+
+	got, err := st.GetSpot(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("GetSpot: %v", err)
+	}
+	if got.ID.IsZero() || got.LotID != "lot-a" || got.PricePerHour != 5 || got.Size != model.SizeMedium {
+		t.Errorf("wrong spot: got %+v", got)
+	}
+}
+
+func testNotFound(t *testing.T, st store.SpotStore) {
+	ctx := context.Background()
+
+	if _, err := st.GetSpot(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetSpot: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.EditSpot(ctx, spot("missing", "", 5, model.SizeSmall, model.SpotTypeOutdoor)); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("EditSpot: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.DeleteSpot(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("DeleteSpot: got %v want %v", err, model.ErrNotFound)
+	}
+	if _, err := st.GetPrice(ctx, model.GetPriceInput{SpotID: "missing", StartTime: updatedAt, EndTime: updatedAt.Add(time.Hour)}); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetPrice: got %v want %v", err, model.ErrNotFound)
+	}
+}
+
+func testEdit(t *testing.T, st store.SpotStore) {
+	ctx := context.Background()
+	mustAdd(t, st, spot("s1", "lot-a", 5, model.SizeMedium, model.SpotTypeIndoor))
+
+	before, err := st.GetSpot(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetSpot: %v", err)
+	}
+
+	edited := before
+	edited.PricePerHour = 7.5
+	edited.Type = model.SpotTypeEV
+	if err := st.EditSpot(ctx, edited); err != nil {
+		t.Fatalf("EditSpot: %v", err)
+	}
+
+	got, err := st.GetSpot(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetSpot: %v", err)
+	}
+	if got.ID != before.ID || got.PricePerHour != 7.5 || got.Type != model.SpotTypeEV {
+		t.Errorf("wrong spot after edit: got %+v", got)
+	}
+}
+
+func testDelete(t *testing.T, st store.SpotStore) {
+	ctx := context.Background()
+	mustAdd(t, st,
+		spot("s1", "lot-a", 5, model.SizeMedium, model.SpotTypeIndoor),
+		spot("s2", "lot-a", 5, model.SizeMedium, model.SpotTypeIndoor),
+	)
+
+	if err := st.DeleteSpot(ctx, "s1"); err != nil {
+		t.Fatalf("DeleteSpot: %v", err)
+	}
+	if _, err := st.GetSpot(ctx, "s1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("deleted spot still found: %v", err)
+	}
+	if _, err := st.GetSpot(ctx, "s2"); err != nil {
+		t.Errorf("other spot is gone: %v", err)
+	}
+}
+
+func testGetAll(t *testing.T, st store.SpotStore) {
+	ctx := context.Background()
+
+	// s2 and s4 share the price, so the cursor must break the tie
+	mustAdd(t, st,
+		spot("s1", "lot-a", 4, model.SizeSmall, model.SpotTypeOutdoor),
+		spot("s2", "lot-a", 6, model.SizeMedium, model.SpotTypeIndoor),
+		spot("s3", "lot-b", 8, model.SizeLarge, model.SpotTypeEV),
+		spot("s4", "lot-b", 6, model.SizeMedium, model.SpotTypeOutdoor),
+	)
+
+	tests := []struct {
+		name   string
+		filter model.SpotFilter
+		want   []string
+	}{
+		{"lot", model.SpotFilter{LotID: "lot-b"}, []string{"s4", "s3"}},
+		{"size", model.SpotFilter{Size: model.SizeMedium}, []string{"s2", "s4"}},
+		{"type", model.SpotFilter{Type: model.SpotTypeOutdoor}, []string{"s1", "s4"}},
+	}
+
+	for _, tt := range tests {
+		page, err := st.GetAll(ctx, tt.filter, model.ListOptions{SortBy: "price_per_hour", IncludeTotal: true})
+		if err != nil {
+			t.Fatalf("%v: GetAll: %v", tt.name, err)
+		}
+		if got := ids(page.Items); !equal(got, tt.want) {
+			t.Errorf("%v: got %v want %v", tt.name, got, tt.want)
+		}
+		if page.TotalCount == nil || *page.TotalCount != int64(len(tt.want)) {
+			t.Errorf("%v: wrong total count: got %v want %v", tt.name, page.TotalCount, len(tt.want))
+		}
+	}
+
+	var got []string
+	opts := model.ListOptions{Limit: 1, SortBy: "price_per_hour", Descending: true}
+	for pages := 0; ; pages++ {
+		if pages > 4 {
+			t.Fatalf("pagination doesn't end")
+		}
+
+		page, err := st.GetAll(ctx, model.SpotFilter{}, opts)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		got = append(got, ids(page.Items)...)
+
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if want := []string{"s3", "s4", "s2", "s1"}; !equal(got, want) {
+		t.Errorf("pagination: got %v want %v", got, want)
+	}
+}
+
+func testInvalidCursor(t *testing.T, st store.SpotStore) {
+	_, err := st.GetAll(context.Background(), model.SpotFilter{}, model.ListOptions{SortBy: "spot_id", Cursor: "not-a-cursor"})
+	if !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("got %v want %v", err, model.ErrInvalidCursor)
+	}
+}
+
+func testGetPrice(t *testing.T, st store.SpotStore) {
+	mustAdd(t, st, spot("s1", "", 3.333, model.SizeSmall, model.SpotTypeOutdoor))
+
+	price, err := st.GetPrice(context.Background(), model.GetPriceInput{
+		SpotID:    "s1",
+		StartTime: updatedAt,
+		EndTime:   updatedAt.Add(90 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("GetPrice: %v", err)
+	}
+
+	// 1.5h * 3.333 = 4.9995, truncated to cents
+	if price != 4.99 {
+		t.Errorf("wrong price: got %v want %v", price, 4.99)
+	}
+
+	// A second costs less than a cent, the minimum is charged
+	price, err = st.GetPrice(context.Background(), model.GetPriceInput{
+		SpotID:    "s1",
+		StartTime: updatedAt,
+		EndTime:   updatedAt.Add(time.Second),
+	})
+	if err != nil {
+		t.Fatalf("GetPrice: %v", err)
+	}
+	if price != 0.01 {
+		t.Errorf("wrong price: got %v want %v", price, 0.01)
+	}
+}
+
+func testCheckSpotsExist(t *testing.T, st store.SpotStore) {
+	mustAdd(t, st, spot("s1", "", 5, model.SizeSmall, model.SpotTypeOutdoor))
+
+	notFound, err := st.CheckSpotsExist(context.Background(), []string{"s1", "s2", "s3"})
+	if err != nil {
+		t.Fatalf("CheckSpotsExist: %v", err)
+	}
+	if want := []string{"s2", "s3"}; !equal(notFound, want) {
+		t.Errorf("got %v want %v", notFound, want)
+	}
+}
+
+func testSearchSpots(t *testing.T, st store.SpotStore) {
+	ctx := context.Background()
+	low := spot("s3", "lot-a", 5, model.SizeLarge, model.SpotTypeIndoor)
+	low.MaxHeightCm = 180
+	mustAdd(t, st,
+		spot("s1", "lot-a", 4, model.SizeSmall, model.SpotTypeOutdoor),
+		spot("s2", "lot-a", 9, model.SizeLarge, model.SpotTypeOutdoor),
+		low,
+		spot("s4", "lot-a", 5, model.SizeLarge, model.SpotTypeEV),
+		spot("s5", "lot-b", 5, model.SizeLarge, model.SpotTypeOutdoor),
+	)
+
+	tests := []struct {
+		name  string
+		input model.SearchInput
+		want  []string
+	}{
+		{"price", model.SearchInput{LotID: "lot-a", MaxPricePerHour: 5}, []string{"s1", "s3", "s4"}},
+		{"size and type", model.SearchInput{Size: model.SizeLarge, Type: model.SpotTypeOutdoor}, []string{"s2", "s5"}},
+		{"vehicle", model.SearchInput{
+			LotID:   "lot-a",
+			Vehicle: &model.VehicleProfile{Size: model.SizeMedium, HeightCm: 200},
+		}, []string{"s2"}},
+	}
+
+	for _, tt := range tests {
+		spots, err := st.SearchSpots(ctx, tt.input)
+		if err != nil {
+			t.Fatalf("%v: SearchSpots: %v", tt.name, err)
+		}
+		if got := ids(spots); !equal(got, tt.want) {
+			t.Errorf("%v: got %v want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testCheckCompatibility(t *testing.T, st store.SpotStore) {
+	low := spot("s5", "", 5, model.SizeLarge, model.SpotTypeIndoor)
+	low.MaxHeightCm = 200
+	mustAdd(t, st,
+		spot("s1", "", 5, model.SizeSmall, model.SpotTypeOutdoor),
+		spot("s2", "", 5, model.SizeLarge, model.SpotTypeEV),
+		spot("s3", "", 5, model.SizeLarge, model.SpotTypeOutdoor),
+		low,
+	)
+
+	// The height of the vehicle is unknown, so it can't use the height-limited s5
+	result, err := st.CheckCompatibility(context.Background(), []string{"s3", "s1", "s2", "s4", "s5"}, model.VehicleProfile{Size: model.SizeMedium})
+	if err != nil {
+		t.Fatalf("CheckCompatibility: %v", err)
+	}
+	if !equal(result.Compatible, []string{"s3"}) {
+		t.Errorf("compatible: got %v want %v", result.Compatible, []string{"s3"})
+	}
+	if len(result.Incompatible) != 3 || result.Incompatible["s1"] == "" || result.Incompatible["s2"] == "" || result.Incompatible["s5"] == "" {
+		t.Errorf("incompatible: got %v", result.Incompatible)
+	}
+	if !equal(result.NotFound, []string{"s4"}) {
+		t.Errorf("not found: got %v want %v", result.NotFound, []string{"s4"})
+	}
+}
+
+func event(t *testing.T, eventType string, s model.Spot, occurredAt time.Time) model.Event {
+	t.Helper()
+	e, err := model.NewEvent(eventType, s.SpotID, s)
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	e.OccurredAt = occurredAt
+	return e
+}
+
+func eventIDs(events []model.Event) []string {
+	resp := make([]string, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, e.ID)
+	}
+	return resp
+}
+
+func testOutbox(t *testing.T, st store.SpotStore) {
+	ctx := context.Background()
+
+	s := spot("s1", "lot-a", 5, model.SizeMedium, model.SpotTypeIndoor)
+	created := event(t, model.EventSpotCreated, s, updatedAt)
+	if err := st.AddSpot(ctx, s, created); err != nil {
+		t.Fatalf("AddSpot: %v", err)
+	}
+
+	s.PricePerHour = 7
+	updated := event(t, model.EventSpotUpdated, s, updatedAt.Add(time.Minute))
+	if err := st.EditSpot(ctx, s, updated); err != nil {
+		t.Fatalf("EditSpot: %v", err)
+	}
+
+	// A failed write must not leave its event behind
+	missing := spot("missing", "lot-a", 5, model.SizeMedium, model.SpotTypeIndoor)
+	if err := st.DeleteSpot(ctx, "missing", event(t, model.EventSpotDeleted, missing, updatedAt.Add(2*time.Minute))); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("DeleteSpot(missing) = %v, want ErrNotFound", err)
+	}
+
+	pending, err := st.PendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{created.ID, updated.ID}; !equal(eventIDs(pending), want) {
+		t.Fatalf("PendingEvents = %v, want %v", eventIDs(pending), want)
+	}
+
+	got := pending[1]
+	if got.Type != model.EventSpotUpdated || got.Source != model.EventSource || got.AggregateID != "s1" || !got.OccurredAt.Equal(updatedAt.Add(time.Minute)) {
+		t.Errorf("PendingEvents[1] = %+v, want the updated event", got)
+	}
+	var payload model.Spot
+	if err := json.Unmarshal(got.Payload, &payload); err != nil || payload.PricePerHour != 7 {
+		t.Errorf("PendingEvents[1] payload = %s (%v), want the updated spot", got.Payload, err)
+	}
+
+	limited, err := st.PendingEvents(ctx, 1)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{created.ID}; !equal(eventIDs(limited), want) {
+		t.Errorf("PendingEvents(limit 1) = %v, want %v", eventIDs(limited), want)
+	}
+
+	if err := st.MarkPublished(ctx, []string{created.ID}); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+
+	pending, err = st.PendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{updated.ID}; !equal(eventIDs(pending), want) {
+		t.Errorf("PendingEvents after MarkPublished = %v, want %v", eventIDs(pending), want)
+	}
+}
+
+func testCancellationPolicies(t *testing.T, st store.SpotStore) {
+	ctx := context.Background()
+
+	lot := model.CancellationPolicy{Scope: model.PolicyScopeLot, TargetID: "lot-a", FreeUntilHours: 24, LateFeePercent: 50, NonRefundableMinutes: 60, UpdatedAt: updatedAt}
+	spot := model.CancellationPolicy{Scope: model.PolicyScopeSpot, TargetID: "lot-a", FreeUntilHours: 2, UpdatedAt: updatedAt}
+	for _, policy := range []model.CancellationPolicy{spot, lot} {
+		if err := st.SetCancellationPolicy(ctx, policy); err != nil {
+			t.Fatalf("SetCancellationPolicy: %v", err)
+		}
+	}
+
+	// Setting it again replaces the policy of the target
+	lot.LateFeePercent = 25
+	if err := st.SetCancellationPolicy(ctx, lot); err != nil {
+		t.Fatalf("SetCancellationPolicy: %v", err)
+	}
+	got, err := st.GetCancellationPolicy(ctx, model.PolicyScopeLot, "lot-a")
+	if err != nil {
+		t.Fatalf("GetCancellationPolicy: %v", err)
+	}
+	if got != lot {
+		t.Errorf("GetCancellationPolicy = %+v, want %+v", got, lot)
+	}
+
+	policies, err := st.GetCancellationPolicies(ctx)
+	if err != nil {
+		t.Fatalf("GetCancellationPolicies: %v", err)
+	}
+	if len(policies) != 2 || policies[0] != lot || policies[1] != spot {
+		t.Errorf("GetCancellationPolicies = %+v, want the lot then the spot policy", policies)
+	}
+
+	if err := st.DeleteCancellationPolicy(ctx, model.PolicyScopeSpot, "lot-a"); err != nil {
+		t.Fatalf("DeleteCancellationPolicy: %v", err)
+	}
+	if _, err := st.GetCancellationPolicy(ctx, model.PolicyScopeSpot, "lot-a"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetCancellationPolicy after delete: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.DeleteCancellationPolicy(ctx, model.PolicyScopeSpot, "lot-a"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("DeleteCancellationPolicy twice: got %v want %v", err, model.ErrNotFound)
+	}
+}
@@ -1,26 +1,115 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/ciameksw/reserve-park/user/internal/user/config"
+	"github.com/ciameksw/reserve-park/user/internal/user/events"
 	"github.com/ciameksw/reserve-park/user/internal/user/logger"
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
 	"github.com/ciameksw/reserve-park/user/internal/user/mongodb"
+	"github.com/ciameksw/reserve-park/user/internal/user/postgres"
 	"github.com/ciameksw/reserve-park/user/internal/user/server"
+	"github.com/ciameksw/reserve-park/user/internal/user/store"
+	"github.com/ciameksw/reserve-park/user/internal/user/tracing"
 )
 
 func main() {
+	// Registered first so that it runs after all the other deferred cleanups
+	exitCode := 0
+	defer func() { os.Exit(exitCode) }()
+
 	// Get logger
 	lgr := logger.GetLogger()
 
 	// Get config
 	cfg := config.GetConfig()
 
-	// Connect to MongoDB
-	db, err := mongodb.Connect(cfg.MongoURI, "users")
+	// Set up tracing
+	shutdownTracing, err := tracing.Init("user", cfg)
+	if err != nil {
+		lgr.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Connect to the configured database
+	st, disconnect, err := openStore(cfg)
 	if err != nil {
-		lgr.Error.Fatalf("Failed to connect to MongoDB: %v", err)
+		lgr.Error("Failed to connect to the database", "backend", cfg.StorageBackend, "error", err)
+		os.Exit(1)
+	}
+	defer disconnect()
+
+	// Connect to the event broker
+	broker, err := openBroker(lgr, cfg)
+	if err != nil {
+		lgr.Error("Failed to connect to the event broker", "broker", cfg.EventBroker, "error", err)
+		os.Exit(1)
+	}
+	defer broker.Close()
+
+	s := server.NewServer(lgr, cfg, st)
+
+	// Stop gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Publish the outbox until the server stops, the broker and the store
+	// are closed only after the relay has returned
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		events.NewRelay(lgr, st, broker, cfg.OutboxPollInterval).Run(ctx)
+	}()
+	defer func() { <-relayDone }()
+
+	if err := s.Start(ctx); err != nil {
+		lgr.Error("Server stopped unexpectedly", "error", err)
+		exitCode = 1
+		stop()
+		return
 	}
-	defer db.Disconnect()
+	lgr.Info("Server stopped")
+}
 
-	s := server.NewServer(lgr, cfg, db)
-	s.Start()
+// openStore connects to the storage backend picked by STORAGE_BACKEND
+func openStore(cfg *config.Config) (store.UserStore, func(), error) {
+	switch cfg.StorageBackend {
+	case "mongodb":
+		db, err := mongodb.Connect(cfg.MongoURI, "users", cfg.MongoStandalone)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Disconnect, nil
+	case "postgres":
+		db, err := postgres.Connect(cfg.PostgresURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Disconnect, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+// openBroker connects to the event broker picked by EVENT_BROKER
+func openBroker(lgr *logger.Logger, cfg *config.Config) (events.Broker, error) {
+	switch cfg.EventBroker {
+	case "inprocess":
+		// Nothing else runs in the process yet, the events only reach the log
+		broker := events.NewInProcess()
+		broker.Subscribe(func(event model.Event) {
+			lgr.Debug("Event published", "type", event.Type, "id", event.ID, "aggregate_id", event.AggregateID)
+		})
+		return broker, nil
+	case "nats":
+		return events.NewNATS(cfg.NatsURL)
+	default:
+		return nil, fmt.Errorf("unknown event broker %q", cfg.EventBroker)
+	}
 }
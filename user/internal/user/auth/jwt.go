@@ -3,17 +3,17 @@ package auth
 import (
 	"time"
 
-	"github.com/ciameksw/reserve-park/user/internal/user/mongodb"
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type UserClaims struct {
 	UserID string
-	Role   mongodb.RoleType
+	Role   model.RoleType
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID string, role mongodb.RoleType, key string) (string, error) {
+func GenerateJWT(userID string, role model.RoleType, key string) (string, error) {
 	claims := UserClaims{
 		UserID: userID,
 		Role:   role,
@@ -0,0 +1,21 @@
+// Package events publishes the domain events of the user service.
+// The store writes them to its outbox together with the change they describe,
+// the Relay then hands them to a Broker.
+package events
+
+import (
+	"context"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+)
+
+// Broker delivers published events to the other components
+type Broker interface {
+	Publish(ctx context.Context, event model.Event) error
+	Close() error
+}
+
+// Subject is where an event is published, e.g. reservepark.user.UserDeleted
+func Subject(event model.Event) string {
+	return "reservepark." + event.Source + "." + event.Type
+}
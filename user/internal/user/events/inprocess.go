@@ -0,0 +1,41 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+)
+
+// InProcess delivers events to handlers in the same process, synchronously
+// and in publishing order. It's meant for tests and single-process setups.
+type InProcess struct {
+	mu       sync.RWMutex
+	handlers []func(model.Event)
+}
+
+func NewInProcess() *InProcess {
+	return &InProcess{}
+}
+
+// Subscribe registers a handler called with every published event
+func (b *InProcess) Subscribe(handler func(model.Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *InProcess) Publish(ctx context.Context, event model.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+	return nil
+}
+
+func (b *InProcess) Close() error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey carries the request ID between services over gRPC
+const RequestIDMetadataKey = "x-request-id"
+
+// UnaryServerInterceptor is the gRPC counterpart of RequestIDMiddleware
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !isValidRequestID(id) {
+		id = uuid.NewString()
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, id))
+	return handler(WithRequestID(ctx, id), req)
+}
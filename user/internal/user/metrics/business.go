@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var LoginFailures = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "login_failures_total",
+	Help: "Number of rejected login attempts.",
+})
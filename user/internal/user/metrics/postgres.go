@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	postgresDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "postgres_query_duration_seconds",
+		Help:    "Latency of PostgreSQL queries.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"command"})
+
+	postgresErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "postgres_query_errors_total",
+		Help: "Number of failed PostgreSQL queries.",
+	}, []string{"command"})
+)
+
+type postgresQueryKey struct{}
+
+type postgresQuery struct {
+	command string
+	start   time.Time
+	span    trace.Span
+}
+
+// PostgresTracer records query latency and errors and wraps every query in a span
+type PostgresTracer struct{}
+
+func (PostgresTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	command := "OTHER"
+	if fields := strings.Fields(data.SQL); len(fields) > 0 {
+		command = strings.ToUpper(fields[0])
+	}
+
+	ctx, span := otel.Tracer("postgres").Start(ctx, "postgres "+command, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql"), attribute.String("db.statement", data.SQL)))
+
+	return context.WithValue(ctx, postgresQueryKey{}, postgresQuery{command: command, start: time.Now(), span: span})
+}
+
+func (PostgresTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	query, ok := ctx.Value(postgresQueryKey{}).(postgresQuery)
+	if !ok {
+		return
+	}
+
+	postgresDuration.WithLabelValues(query.command).Observe(time.Since(query.start).Seconds())
+	if data.Err != nil {
+		postgresErrors.WithLabelValues(query.command).Inc()
+		query.span.RecordError(data.Err)
+		query.span.SetStatus(codes.Error, data.Err.Error())
+	}
+	query.span.End()
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventSource names the user service in the events it publishes
+const EventSource = "user"
+
+// Event types published by the user service
+const (
+	EventUserCreated    = "UserCreated"
+	EventUserUpdated    = "UserUpdated"
+	EventUserDeleted    = "UserDeleted"
+	EventVehicleAdded   = "VehicleAdded"
+	EventVehicleDeleted = "VehicleDeleted"
+)
+
+// Event is a domain event. It is written to the outbox together with the change
+// it describes and published from there by the events relay.
+type Event struct {
+	ID          string          `json:"id" bson:"_id"`
+	Type        string          `json:"type" bson:"type"`
+	Source      string          `json:"source" bson:"source"`
+	AggregateID string          `json:"aggregate_id" bson:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at" bson:"occurred_at"`
+	Payload     json.RawMessage `json:"payload" bson:"payload"`
+}
+
+// VehicleChange is the payload of the vehicle events, VehicleDeleted carries only the IDs.
+// Their aggregate is the user owning the vehicle.
+type VehicleChange struct {
+	UserID    string   `json:"user_id"`
+	VehicleID string   `json:"vehicle_id"`
+	Vehicle   *Vehicle `json:"vehicle,omitempty"`
+}
+
+// NewEvent describes a change of the aggregate, the payload is stored as JSON
+func NewEvent(eventType, aggregateID string, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		Source:      EventSource,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now().UTC(),
+		Payload:     data,
+	}, nil
+}
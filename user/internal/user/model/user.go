@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RoleType string
+
+const (
+	RoleAdmin     RoleType = "admin"
+	RoleUser      RoleType = "user"
+	RoleAttendant RoleType = "attendant"
+)
+
+type User struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID       string             `json:"user_id" bson:"user_id" validate:"required"`
+	Username     string             `json:"username" bson:"username" validate:"required,min=3,max=30"`
+	Email        string             `json:"email" bson:"email" validate:"required,email"`
+	PasswordHash string             `json:"password_hash" bson:"password_hash" validate:"required"`
+	Role         RoleType           `json:"role" bson:"role" validate:"required,oneof=admin user attendant"`
+	Vehicles     []Vehicle          `json:"vehicles,omitempty" bson:"vehicles,omitempty" validate:"dive"`
+	Billing      *Billing           `json:"billing,omitempty" bson:"billing,omitempty"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at" validate:"required"`
+}
+
+// Billing holds the buyer details printed on the user's invoices. Business accounts
+// get one consolidated invoice per month instead of one per reservation.
+type Billing struct {
+	Name     string `json:"name,omitempty" bson:"name,omitempty" validate:"required_if=Business true,max=200"`
+	TaxID    string `json:"tax_id,omitempty" bson:"tax_id,omitempty" validate:"required_if=Business true,max=50"`
+	Address  string `json:"address,omitempty" bson:"address,omitempty" validate:"max=500"`
+	Business bool   `json:"business" bson:"business"`
+}
+
+type UserResponse struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID    string             `json:"user_id" bson:"user_id"`
+	Username  string             `json:"username" bson:"username"`
+	Email     string             `json:"email" bson:"email"`
+	Role      RoleType           `json:"role" bson:"role"`
+	Vehicles  []Vehicle          `json:"vehicles,omitempty" bson:"vehicles,omitempty"`
+	Billing   *Billing           `json:"billing,omitempty" bson:"billing,omitempty"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Response strips the password hash, e.g. for event payloads
+func (u User) Response() UserResponse {
+	return UserResponse{
+		ID:        u.ID,
+		UserID:    u.UserID,
+		Username:  u.Username,
+		Email:     u.Email,
+		Role:      u.Role,
+		Vehicles:  u.Vehicles,
+		Billing:   u.Billing,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+type UserFilter struct {
+	Role RoleType
+}
@@ -0,0 +1,29 @@
+package model
+
+import "strings"
+
+type VehicleSizeType string
+
+const (
+	VehicleSizeSmall  VehicleSizeType = "small"
+	VehicleSizeMedium VehicleSizeType = "medium"
+	VehicleSizeLarge  VehicleSizeType = "large"
+)
+
+type Vehicle struct {
+	VehicleID    string          `json:"vehicle_id" bson:"vehicle_id" validate:"required"`
+	LicensePlate string          `json:"license_plate" bson:"license_plate" validate:"required,max=16"`
+	Country      string          `json:"country" bson:"country" validate:"required,iso3166_1_alpha2"`
+	Region       string          `json:"region,omitempty" bson:"region,omitempty" validate:"omitempty,max=50"`
+	Make         string          `json:"make,omitempty" bson:"make,omitempty" validate:"omitempty,max=50"`
+	Size         VehicleSizeType `json:"size" bson:"size" validate:"required,oneof=small medium large"`
+	IsEV         bool            `json:"is_ev" bson:"is_ev"`
+	HeightCm     int             `json:"height_cm,omitempty" bson:"height_cm,omitempty" validate:"omitempty,gt=0,lte=500"`
+}
+
+// NormalizePlate brings license plates to a canonical form so that
+// "wx 1234-a" and "WX1234A" are treated as the same plate.
+func NormalizePlate(plate string) string {
+	replacer := strings.NewReplacer(" ", "", "-", "", ".", "")
+	return strings.ToUpper(replacer.Replace(plate))
+}
@@ -0,0 +1,52 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := ConnectMock()
+	if err != nil {
+		t.Skipf("mock MongoDB is not available: %v", err)
+	}
+	defer db.Disconnect()
+
+	ctx := context.Background()
+
+	// Running twice must be a no-op the second time
+	for i := 0; i < 2; i++ {
+		if err := Migrate(ctx, db.Collection); err != nil {
+			t.Fatalf("Migrate run %d: %v", i+1, err)
+		}
+	}
+
+	count, err := db.Collection.Database().Collection(migrationsCollection).CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatalf("Failed to count migrations: %v", err)
+	}
+	if count != int64(len(migrations)) {
+		t.Errorf("got %d migration records, want %d", count, len(migrations))
+	}
+
+	user := model.User{UserID: "u1", Username: "alice", Email: "alice@example.com", PasswordHash: "hash", Role: model.RoleUser, UpdatedAt: time.Now()}
+	if err := db.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+
+	user.UserID = "u2"
+	user.Email = "other@example.com"
+	if err := db.AddUser(ctx, user); err != model.ErrDuplicate {
+		t.Errorf("AddUser with a taken username: got %v, want %v", err, model.ErrDuplicate)
+	}
+
+	// The validator rejects documents the service would never write
+	_, err = db.Collection.InsertOne(ctx, bson.M{"user_id": "u3", "username": "bob", "role": "owner"})
+	if err == nil {
+		t.Error("InsertOne of an invalid document succeeded")
+	}
+}
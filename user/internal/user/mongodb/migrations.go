@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations lists the schema changes of the users collection in the order they are applied.
+// Released migrations must not be edited, add a new one instead.
+var migrations = []migration{
+	{
+		Version:     "0001",
+		Description: "unique user_id, username and email, role and vehicle indexes",
+		Up: createIndexes(
+			mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			mongo.IndexModel{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+			mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			mongo.IndexModel{Keys: bson.D{{Key: "role", Value: 1}, {Key: "_id", Value: 1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "vehicles.vehicle_id", Value: 1}}},
+		),
+	},
+	{
+		Version:     "0002",
+		Description: "users JSON schema validator",
+		Up: setValidator(bson.M{
+			"bsonType": "object",
+			"required": bson.A{"user_id", "username", "email", "password_hash", "role", "updated_at"},
+			"properties": bson.M{
+				"user_id":       bson.M{"bsonType": "string", "minLength": 1},
+				"username":      bson.M{"bsonType": "string", "minLength": 3, "maxLength": 30},
+				"email":         bson.M{"bsonType": "string", "minLength": 3},
+				"password_hash": bson.M{"bsonType": "string", "minLength": 1},
+				"role":          bson.M{"enum": bson.A{"admin", "user", "attendant"}},
+				"updated_at":    bson.M{"bsonType": "date"},
+				"vehicles": bson.M{
+					"bsonType": "array",
+					"items": bson.M{
+						"bsonType": "object",
+						"required": bson.A{"vehicle_id", "license_plate", "country", "size"},
+						"properties": bson.M{
+							"vehicle_id":    bson.M{"bsonType": "string", "minLength": 1},
+							"license_plate": bson.M{"bsonType": "string", "minLength": 1, "maxLength": 16},
+							"country":       bson.M{"bsonType": "string", "minLength": 2, "maxLength": 2},
+							"size":          bson.M{"enum": bson.A{"small", "medium", "large"}},
+							"is_ev":         bson.M{"bsonType": "bool"},
+							"height_cm":     bson.M{"bsonType": "number", "minimum": 1},
+						},
+					},
+				},
+			},
+		}),
+	},
+	{
+		// Creating the collection here also keeps it out of the first transaction,
+		// MongoDB before 4.4 can't create collections inside one
+		Version:     "0003",
+		Description: "outbox pending and retention indexes",
+		Up: createIndexesIn(outboxCollection,
+			mongo.IndexModel{Keys: bson.D{{Key: "published_at", Value: 1}, {Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "published_at", Value: 1}},
+				Options: options.Index().SetName("published_at_ttl").SetExpireAfterSeconds(int32(publishedRetention.Seconds())),
+			},
+		),
+	},
+}
@@ -2,48 +2,34 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type RoleType string
-
-const (
-	RoleAdmin RoleType = "admin"
-	RoleUser  RoleType = "user"
-)
-
-type User struct {
-	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	UserID       string             `json:"user_id" bson:"user_id" validate:"required"`
-	Username     string             `json:"username" bson:"username" validate:"required,min=3,max=30"`
-	Email        string             `json:"email" bson:"email" validate:"required,email"`
-	PasswordHash string             `json:"password_hash" bson:"password_hash" validate:"required"`
-	Role         RoleType           `json:"role" bson:"role" validate:"required,oneof=admin user"`
-	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at" validate:"required"`
-}
-
-func (m *MongoDB) AddUser(user User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) AddUser(ctx context.Context, user model.User, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	_, err := m.Collection.InsertOne(ctx, user)
-	return err
+	return duplicate(m.withEvents(ctx, events, func(ctx context.Context) error {
+		_, err := m.Collection.InsertOne(ctx, user)
+		return err
+	}))
 }
 
-func (m *MongoDB) GetUserByUsernameOrEmailForEdit(username, email, editUserID string) (*User, error) {
-	return m.getUserByUsernameOrEmail(username, email, editUserID)
+func (m *MongoDB) GetUserByUsernameOrEmailForEdit(ctx context.Context, username, email, editUserID string) (*model.User, error) {
+	return m.getUserByUsernameOrEmail(ctx, username, email, editUserID)
 }
 
-func (m *MongoDB) GetUserByUsernameOrEmail(username, email string) (*User, error) {
-	return m.getUserByUsernameOrEmail(username, email, "")
+func (m *MongoDB) GetUserByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error) {
+	return m.getUserByUsernameOrEmail(ctx, username, email, "")
 }
 
-func (m *MongoDB) getUserByUsernameOrEmail(username, email, editUserID string) (*User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) getUserByUsernameOrEmail(ctx context.Context, username, email, editUserID string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{
@@ -58,7 +44,7 @@ func (m *MongoDB) getUserByUsernameOrEmail(username, email, editUserID string) (
 		filter["user_id"] = bson.M{"$ne": editUserID}
 	}
 
-	var user User
+	var user model.User
 	err := m.Collection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -70,68 +56,72 @@ func (m *MongoDB) getUserByUsernameOrEmail(username, email, editUserID string) (
 	return &user, nil
 }
 
-func (m *MongoDB) EditUser(input User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) EditUser(ctx context.Context, input model.User, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"user_id": bson.M{"$eq": input.UserID}}
 
-	res := m.Collection.FindOneAndReplace(ctx, filter, input)
-	return res.Err()
+	return duplicate(notFound(m.withEvents(ctx, events, func(ctx context.Context) error {
+		return m.Collection.FindOneAndReplace(ctx, filter, input).Err()
+	})))
 }
 
-func (m *MongoDB) DeleteUser(userID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) DeleteUser(ctx context.Context, userID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"user_id": bson.M{"$eq": userID}}
 
-	res := m.Collection.FindOneAndDelete(ctx, filter)
-	return res.Err()
+	return notFound(m.withEvents(ctx, events, func(ctx context.Context) error {
+		return m.Collection.FindOneAndDelete(ctx, filter).Err()
+	}))
 }
 
-func (m *MongoDB) GetFullUser(userID string) (User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) GetFullUser(ctx context.Context, userID string) (model.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"user_id": bson.M{"$eq": userID}}
 
-	var user User
+	var user model.User
 	err := m.Collection.FindOne(ctx, filter).Decode(&user)
-	return user, err
-}
-
-type UserResponse struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	UserID    string             `json:"user_id" bson:"user_id"`
-	Username  string             `json:"username" bson:"username"`
-	Email     string             `json:"email" bson:"email"`
-	Role      RoleType           `json:"role" bson:"role"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	return user, notFound(err)
 }
 
-func (m *MongoDB) GetUser(userID string) (UserResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MongoDB) GetUser(ctx context.Context, userID string) (model.UserResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"user_id": bson.M{"$eq": userID}}
 
-	var user UserResponse
+	var user model.UserResponse
 	err := m.Collection.FindOne(ctx, filter).Decode(&user)
-	return user, err
+	return user, notFound(err)
 }
 
-func (m *MongoDB) GetAll() ([]UserResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// GetAll returns one page of users matching the filter.
+func (m *MongoDB) GetAll(ctx context.Context, input model.UserFilter, opts model.ListOptions) (model.Page[model.UserResponse], error) {
+	filter := bson.M{}
+	if input.Role != "" {
+		filter["role"] = bson.M{"$eq": input.Role}
+	}
 
-	cursor, err := m.Collection.Find(ctx, bson.M{})
-	if err != nil {
-		return nil, err
+	return findPage[model.UserResponse](ctx, m.Collection, filter, opts)
+}
+
+// notFound translates the driver's missing document error into the store-neutral model.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.ErrNotFound
 	}
-	defer cursor.Close(ctx)
+	return err
+}
 
-	var users []UserResponse
-	err = cursor.All(ctx, &users)
-	return users, err
+// duplicate translates a unique index violation into the store-neutral model.ErrDuplicate
+func duplicate(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrDuplicate
+	}
+	return err
 }
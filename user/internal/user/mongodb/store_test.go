@@ -0,0 +1,27 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/store"
+	"github.com/ciameksw/reserve-park/user/internal/user/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	db, err := ConnectMock()
+	if err != nil {
+		t.Skipf("mock MongoDB is not available: %v", err)
+	}
+	defer db.Disconnect()
+
+	storetest.Run(t, func(t *testing.T) store.UserStore {
+		if err := db.Collection.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop collection: %v", err)
+		}
+		if err := db.Outbox.Drop(context.Background()); err != nil {
+			t.Fatalf("Failed to drop outbox: %v", err)
+		}
+		return db
+	})
+}
@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddVehicle pushes the vehicle only while the user has none with the same plate in the
+// same country, so concurrent adds of one plate can't both succeed
+func (m *MongoDB) AddVehicle(ctx context.Context, userID string, vehicle model.Vehicle, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": bson.M{"$eq": userID},
+		"vehicles": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"license_plate": bson.M{"$eq": vehicle.LicensePlate},
+			"country":       bson.M{"$eq": vehicle.Country},
+		}}},
+	}
+	update := bson.M{"$push": bson.M{"vehicles": vehicle}}
+
+	return m.withEvents(ctx, events, func(ctx context.Context) error {
+		err := m.updateOne(ctx, filter, update)
+		if err != model.ErrNotFound {
+			return err
+		}
+
+		// Nothing matched, either the user is missing or the plate is taken
+		n, err := m.Collection.CountDocuments(ctx, bson.M{"user_id": bson.M{"$eq": userID}})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return model.ErrNotFound
+		}
+		return model.ErrDuplicate
+	})
+}
+
+func (m *MongoDB) DeleteVehicle(ctx context.Context, userID, vehicleID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":             bson.M{"$eq": userID},
+		"vehicles.vehicle_id": bson.M{"$eq": vehicleID},
+	}
+	update := bson.M{"$pull": bson.M{"vehicles": bson.M{"vehicle_id": vehicleID}}}
+
+	return m.withEvents(ctx, events, func(ctx context.Context) error {
+		return m.updateOne(ctx, filter, update)
+	})
+}
+
+// updateOne applies the update to the matching user, model.ErrNotFound when there's none
+func (m *MongoDB) updateOne(ctx context.Context, filter, update bson.M) error {
+	res, err := m.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) GetVehicles(ctx context.Context, userID string) ([]model.Vehicle, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": bson.M{"$eq": userID}}
+	opts := options.FindOne().SetProjection(bson.M{"vehicles": 1})
+
+	var user model.User
+	err := m.Collection.FindOne(ctx, filter, opts).Decode(&user)
+	return user.Vehicles, notFound(err)
+}
+
+func (m *MongoDB) GetVehicle(ctx context.Context, userID, vehicleID string) (model.Vehicle, error) {
+	vehicles, err := m.GetVehicles(ctx, userID)
+	if err != nil {
+		return model.Vehicle{}, notFound(err)
+	}
+
+	for _, vehicle := range vehicles {
+		if vehicle.VehicleID == vehicleID {
+			return vehicle, nil
+		}
+	}
+
+	return model.Vehicle{}, model.ErrNotFound
+}
@@ -0,0 +1,27 @@
+CREATE TABLE users (
+    id            TEXT COLLATE "C" PRIMARY KEY,
+    user_id       TEXT NOT NULL UNIQUE,
+    username      TEXT COLLATE "C" NOT NULL UNIQUE,
+    email         TEXT COLLATE "C" NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    role          TEXT NOT NULL CHECK (role IN ('admin', 'user', 'attendant')),
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX users_role_idx ON users (role);
+
+CREATE TABLE vehicles (
+    -- seq keeps the vehicles of a user in the order they were added
+    seq           BIGSERIAL PRIMARY KEY,
+    user_id       TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    vehicle_id    TEXT NOT NULL,
+    license_plate TEXT NOT NULL,
+    country       TEXT NOT NULL,
+    region        TEXT NOT NULL DEFAULT '',
+    make          TEXT NOT NULL DEFAULT '',
+    size          TEXT NOT NULL CHECK (size IN ('small', 'medium', 'large')),
+    is_ev         BOOLEAN NOT NULL DEFAULT false,
+    height_cm     INTEGER NOT NULL DEFAULT 0,
+
+    UNIQUE (user_id, vehicle_id)
+);
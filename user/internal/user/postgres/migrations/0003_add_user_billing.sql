@@ -0,0 +1,2 @@
+-- The buyer details printed on invoices, NULL until the user sets them
+ALTER TABLE users ADD COLUMN billing JSONB;
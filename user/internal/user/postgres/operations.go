@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	columns         = `id, user_id, username, email, role, billing, updated_at`
+	fullUserColumns = `id, user_id, username, email, role, billing, updated_at, password_hash`
+)
+
+func (p *Postgres) AddUser(ctx context.Context, user model.User, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	return duplicate(p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO users (`+fullUserColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			user.ID.Hex(), user.UserID, user.Username, user.Email, user.Role, user.Billing, user.UpdatedAt, user.PasswordHash)
+		if err != nil {
+			return err
+		}
+
+		return insertVehicles(ctx, tx, user.UserID, user.Vehicles)
+	}))
+}
+
+func (p *Postgres) GetUserByUsernameOrEmailForEdit(ctx context.Context, username, email, editUserID string) (*model.User, error) {
+	return p.getUserByUsernameOrEmail(ctx, username, email, editUserID)
+}
+
+func (p *Postgres) GetUserByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error) {
+	return p.getUserByUsernameOrEmail(ctx, username, email, "")
+}
+
+func (p *Postgres) getUserByUsernameOrEmail(ctx context.Context, username, email, editUserID string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// In edit mode the edited user is excluded from the check, an empty ID matches no user
+	row := p.Pool.QueryRow(ctx, `SELECT `+fullUserColumns+` FROM users
+		WHERE (username = $1 OR email = $2) AND user_id <> $3
+		LIMIT 1`, username, email, editUserID)
+
+	user, err := p.scanFullUser(ctx, row)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// EditUser replaces the user together with its vehicles, like a document replace
+func (p *Postgres) EditUser(ctx context.Context, input model.User, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return duplicate(p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE users SET username = $2, email = $3, password_hash = $4, role = $5, billing = $6,
+			updated_at = $7 WHERE user_id = $1`,
+			input.UserID, input.Username, input.Email, input.PasswordHash, input.Role, input.Billing, input.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return model.ErrNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM vehicles WHERE user_id = $1`, input.UserID); err != nil {
+			return err
+		}
+
+		return insertVehicles(ctx, tx, input.UserID, input.Vehicles)
+	}))
+}
+
+func (p *Postgres) DeleteUser(ctx context.Context, userID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		// The user's vehicles go with it (ON DELETE CASCADE)
+		tag, err := tx.Exec(ctx, `DELETE FROM users WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return model.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *Postgres) GetFullUser(ctx context.Context, userID string) (model.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	row := p.Pool.QueryRow(ctx, `SELECT `+fullUserColumns+` FROM users WHERE user_id = $1`, userID)
+	return p.scanFullUser(ctx, row)
+}
+
+func (p *Postgres) GetUser(ctx context.Context, userID string) (model.UserResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	user, err := scanUser(p.Pool.QueryRow(ctx, `SELECT `+columns+` FROM users WHERE user_id = $1`, userID))
+	if err != nil {
+		return user, err
+	}
+
+	vehicles, err := p.vehiclesOf(ctx, user.UserID)
+	user.Vehicles = vehicles[user.UserID]
+	return user, err
+}
+
+// GetAll returns one page of users matching the filter.
+func (p *Postgres) GetAll(ctx context.Context, input model.UserFilter, opts model.ListOptions) (model.Page[model.UserResponse], error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var filter where
+	if input.Role != "" {
+		filter.add("role = $%d", input.Role)
+	}
+
+	page, err := findPage(ctx, p.Pool, "users", columns, filter, opts, scanUser,
+		func(u model.UserResponse) primitive.ObjectID { return u.ID })
+	if err != nil || len(page.Items) == 0 {
+		return page, err
+	}
+
+	userIDs := make([]string, 0, len(page.Items))
+	for _, user := range page.Items {
+		userIDs = append(userIDs, user.UserID)
+	}
+
+	vehicles, err := p.vehiclesOf(ctx, userIDs...)
+	if err != nil {
+		return page, err
+	}
+	for i := range page.Items {
+		page.Items[i].Vehicles = vehicles[page.Items[i].UserID]
+	}
+
+	return page, nil
+}
+
+func scanUser(row pgx.Row, extra ...any) (model.UserResponse, error) {
+	var u model.UserResponse
+	var id string
+
+	dest := append([]any{&id, &u.UserID, &u.Username, &u.Email, &u.Role, &u.Billing, &u.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return u, model.ErrNotFound
+		}
+		return u, err
+	}
+
+	var err error
+	u.ID, err = primitive.ObjectIDFromHex(id)
+	u.UpdatedAt = u.UpdatedAt.UTC()
+	return u, err
+}
+
+// scanFullUser scans a row of fullUserColumns and loads the user's vehicles
+func (p *Postgres) scanFullUser(ctx context.Context, row pgx.Row) (model.User, error) {
+	var passwordHash string
+	resp, err := scanUser(row, &passwordHash)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	vehicles, err := p.vehiclesOf(ctx, resp.UserID)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return model.User{
+		ID:           resp.ID,
+		UserID:       resp.UserID,
+		Username:     resp.Username,
+		Email:        resp.Email,
+		PasswordHash: passwordHash,
+		Role:         resp.Role,
+		Vehicles:     vehicles[resp.UserID],
+		Billing:      resp.Billing,
+		UpdatedAt:    resp.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The cursor remembers the sort value and id of the last row of the page,
+// so the next page starts right after it even if rows share the sort value.
+type pageCursor struct {
+	Value any    `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(value any, id primitive.ObjectID) (string, error) {
+	b, err := json.Marshal(pageCursor{Value: value, ID: id.Hex()})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	var c pageCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, model.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Value == nil {
+		return c, model.ErrInvalidCursor
+	}
+	if _, err := primitive.ObjectIDFromHex(c.ID); err != nil {
+		return c, model.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// where collects the conditions of a query and numbers their parameters
+type where struct {
+	conds []string
+	args  []any
+}
+
+// add appends a condition, %d in it is replaced by the number of the argument's parameter
+func (w *where) add(cond string, arg any) {
+	w.args = append(w.args, arg)
+	w.conds = append(w.conds, fmt.Sprintf(cond, len(w.args)))
+}
+
+func (w where) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// rowScanner scans the columns of a row followed by extra destinations
+type rowScanner[T any] func(row pgx.Row, extra ...any) (T, error)
+
+func findPage[T any](ctx context.Context, pool *pgxpool.Pool, table, columns string, filter where, opts model.ListOptions,
+	scan rowScanner[T], idOf func(T) primitive.ObjectID) (model.Page[T], error) {
+	page := model.Page[T]{Items: []T{}}
+
+	if opts.IncludeTotal {
+		var total int64
+		if err := pool.QueryRow(ctx, "SELECT count(*) FROM "+table+filter.String(), filter.args...).Scan(&total); err != nil {
+			return page, err
+		}
+		page.TotalCount = &total
+	}
+
+	if opts.Limit <= 0 || opts.Limit > model.MaxLimit {
+		opts.Limit = model.DefaultLimit
+	}
+
+	sortBy := "id"
+	if opts.SortBy != "" {
+		sortBy = pgx.Identifier{opts.SortBy}.Sanitize()
+	}
+
+	direction, comparison := "ASC", ">"
+	if opts.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	pageFilter := filter
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return page, err
+		}
+
+		pageFilter.conds = append([]string(nil), filter.conds...)
+		pageFilter.args = append(append([]any(nil), filter.args...), c.Value, c.ID)
+		pageFilter.conds = append(pageFilter.conds,
+			fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortBy, comparison, len(pageFilter.args)-1, len(pageFilter.args)))
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s FROM %s%s ORDER BY %s %s, id %s LIMIT %d",
+		columns, sortBy, table, pageFilter.String(), sortBy, direction, direction, opts.Limit+1)
+
+	rows, err := pool.Query(ctx, query, pageFilter.args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var lastValue any
+	hasMore := false
+	for rows.Next() {
+		if int64(len(page.Items)) == opts.Limit {
+			hasMore = true
+			break
+		}
+
+		item, err := scan(rows, &lastValue)
+		if err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if hasMore {
+		page.NextCursor, err = encodeCursor(lastValue, idOf(page.Items[len(page.Items)-1]))
+		if err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
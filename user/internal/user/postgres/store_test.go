@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/store"
+	"github.com/ciameksw/reserve-park/user/internal/user/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+
+	db, err := Connect(url)
+	if err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer db.Disconnect()
+
+	storetest.Run(t, func(t *testing.T) store.UserStore {
+		if _, err := db.Pool.Exec(context.Background(), "TRUNCATE users, users_outbox CASCADE"); err != nil {
+			t.Fatalf("Failed to truncate users: %v", err)
+		}
+		return db
+	})
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const vehicleColumns = `vehicle_id, license_plate, country, region, make, size, is_ev, height_cm`
+
+// SQLSTATEs mapped to store errors
+const (
+	// foreignKeyViolation is raised by a vehicle added to a missing user
+	foreignKeyViolation = "23503"
+	// uniqueViolation is raised by a taken username or email
+	uniqueViolation = "23505"
+)
+
+func (p *Postgres) AddVehicle(ctx context.Context, userID string, vehicle model.Vehicle, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	err := p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		// Locking the user makes concurrent adds of one plate wait for each other's check
+		var taken bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM vehicles WHERE user_id = $1 AND license_plate = $2 AND country = $3)
+			FROM users WHERE user_id = $1 FOR UPDATE`, userID, vehicle.LicensePlate, vehicle.Country).Scan(&taken)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if taken {
+			return model.ErrDuplicate
+		}
+
+		return insertVehicles(ctx, tx, userID, []model.Vehicle{vehicle})
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return model.ErrNotFound
+	}
+	return err
+}
+
+func (p *Postgres) DeleteVehicle(ctx context.Context, userID, vehicleID string, events ...model.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return p.withEvents(ctx, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM vehicles WHERE user_id = $1 AND vehicle_id = $2`, userID, vehicleID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return model.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *Postgres) GetVehicles(ctx context.Context, userID string) ([]model.Vehicle, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := p.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, model.ErrNotFound
+	}
+
+	vehicles, err := p.vehiclesOf(ctx, userID)
+	return vehicles[userID], err
+}
+
+func (p *Postgres) GetVehicle(ctx context.Context, userID, vehicleID string) (model.Vehicle, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	row := p.Pool.QueryRow(ctx, `SELECT `+vehicleColumns+` FROM vehicles WHERE user_id = $1 AND vehicle_id = $2`, userID, vehicleID)
+
+	vehicle, err := scanVehicle(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return vehicle, model.ErrNotFound
+	}
+	return vehicle, err
+}
+
+// vehiclesOf returns the vehicles of the users by user ID, users without vehicles are left out
+func (p *Postgres) vehiclesOf(ctx context.Context, userIDs ...string) (map[string][]model.Vehicle, error) {
+	rows, err := p.Pool.Query(ctx, `SELECT user_id, `+vehicleColumns+` FROM vehicles
+		WHERE user_id = ANY($1)
+		ORDER BY seq`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vehicles := make(map[string][]model.Vehicle)
+	for rows.Next() {
+		var userID string
+		vehicle, err := scanVehicle(rows, &userID)
+		if err != nil {
+			return nil, err
+		}
+		vehicles[userID] = append(vehicles[userID], vehicle)
+	}
+
+	return vehicles, rows.Err()
+}
+
+// execer is implemented by both the pool and a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func insertVehicles(ctx context.Context, db execer, userID string, vehicles []model.Vehicle) error {
+	for _, v := range vehicles {
+		_, err := db.Exec(ctx, `INSERT INTO vehicles (user_id, `+vehicleColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			userID, v.VehicleID, v.LicensePlate, v.Country, v.Region, v.Make, v.Size, v.IsEV, v.HeightCm)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanVehicle scans extra destinations before the vehicle columns
+func scanVehicle(row pgx.Row, extra ...any) (model.Vehicle, error) {
+	var v model.Vehicle
+	dest := append(extra, &v.VehicleID, &v.LicensePlate, &v.Country, &v.Region, &v.Make, &v.Size, &v.IsEV, &v.HeightCm)
+	err := row.Scan(dest...)
+	return v, err
+}
+
+// duplicate translates a unique constraint violation into the store-neutral model.ErrDuplicate
+func duplicate(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return model.ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package problem
+
+// Error carries a problem through code that is shared by the HTTP and gRPC APIs.
+// Err is the underlying cause, it is logged but never sent to clients.
+type Error struct {
+	Problem Problem
+	Err     error
+}
+
+func NewError(code Code, detail string, err error) *Error {
+	return &Error{Problem: New(code, detail), Err: err}
+}
+
+// Invalid reports validator errors field by field.
+func Invalid(err error) *Error {
+	p := New(CodeValidationFailed, "The request contains invalid fields")
+	p.Errors = FieldErrors(err)
+	if p.Errors == nil {
+		p.Detail = err.Error()
+	}
+	return &Error{Problem: p, Err: err}
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Problem.Code) + ": " + e.Problem.Detail
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/logger"
+	"github.com/ciameksw/reserve-park/user/internal/user/metrics"
+	"github.com/ciameksw/reserve-park/user/internal/user/pb/problempb"
+	"github.com/ciameksw/reserve-park/user/internal/user/pb/userpb"
+	"github.com/ciameksw/reserve-park/user/internal/user/problem"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServer returns the gRPC server with the internal API and the standard health service
+func (s *Server) GRPCServer(healthServer *health.Server) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			logger.UnaryServerInterceptor,
+			metrics.UnaryServerInterceptor,
+			s.problemInterceptor,
+		),
+	)
+
+	userpb.RegisterUserServiceServer(srv, &userRPC{s: s})
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	return srv
+}
+
+// problemInterceptor turns the errors of the shared operations into statuses carrying
+// the problem and logs them the same way writeProblem does for HTTP requests
+func (s *Server) problemInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	// The caller canceled the call or ran out of time, the failure is a consequence of that
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	var pe *problem.Error
+	if !errors.As(err, &pe) {
+		pe = problem.NewError(problem.CodeInternal, "Unexpected server error", err)
+	}
+	p := pe.Problem
+
+	level := slog.LevelError
+	if p.Status < http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+
+	args := []any{"method", info.FullMethod, "status", p.Status, "code", p.Code}
+	if pe.Err != nil {
+		args = append(args, "error", pe.Err)
+	}
+	s.Logger.Log(ctx, level, p.Detail, args...)
+
+	return nil, problemStatus(p).Err()
+}
+
+// Helper function to build the status of a problem, with the problem attached as a detail
+func problemStatus(p problem.Problem) *status.Status {
+	detail := &problempb.Problem{
+		Code:   string(p.Code),
+		Detail: p.Detail,
+	}
+	for _, fe := range p.Errors {
+		detail.Errors = append(detail.Errors, &problempb.FieldError{
+			Field:   fe.Field,
+			Rule:    fe.Rule,
+			Message: fe.Message,
+		})
+	}
+
+	st := status.New(grpcCode(p.Status), p.Detail)
+	if withDetail, err := st.WithDetails(detail); err == nil {
+		return withDetail
+	}
+	return st
+}
+
+// grpcCode maps the HTTP status of a problem to the closest gRPC code
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	if httpStatus < http.StatusInternalServerError {
+		return codes.InvalidArgument
+	}
+	return codes.Internal
+}
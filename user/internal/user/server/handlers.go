@@ -2,73 +2,44 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/ciameksw/reserve-park/user/internal/user/auth"
-	m "github.com/ciameksw/reserve-park/user/internal/user/mongodb"
-	"github.com/google/uuid"
+	"github.com/ciameksw/reserve-park/user/internal/user/logger"
+	m "github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/ciameksw/reserve-park/user/internal/user/problem"
 	"github.com/gorilla/mux"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type addInput struct {
 	Username string     `json:"username" validate:"required,min=3,max=30"`
 	Email    string     `json:"email" validate:"required,email"`
 	Password string     `json:"password" validate:"required"`
-	Role     m.RoleType `json:"role" validate:"required,oneof=admin user"`
+	Role     m.RoleType `json:"role" validate:"required,oneof=admin user attendant"`
 }
 
 func (s *Server) addUser(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Adding user")
+	s.Logger.InfoContext(r.Context(), "Adding user")
 	var input addInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	existingUser, err := s.MongoDB.GetUserByUsernameOrEmail(input.Username, input.Email)
-	if err != nil && err != mongo.ErrNoDocuments {
-		s.handleError(w, "Failed to check for existing user", err, http.StatusInternalServerError)
-		return
-	}
-	if existingUser != nil {
-		s.handleError(w, "Username or email already exists", nil, http.StatusConflict)
-		return
-	}
-
-	hashedPassword, err := auth.HashPassword(input.Password)
+	user, err := s.createUser(r.Context(), input)
 	if err != nil {
-		s.handleError(w, "Failed to hash the password", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
 		return
 	}
 
-	data := m.User{
-		UserID:       uuid.NewString(),
-		Username:     input.Username,
-		Email:        input.Email,
-		PasswordHash: hashedPassword,
-		Role:         input.Role,
-		UpdatedAt:    time.Now(),
-	}
-
-	err = s.MongoDB.AddUser(data)
-	if err != nil {
-		s.handleError(w, "Failed to add user to MongoDB", err, http.StatusInternalServerError)
-		return
-	}
-
-	s.Logger.Info.Printf("User added: %v", data.Username)
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(data.UserID))
+	w.Write([]byte(user.UserID))
 }
 
 type editInput struct {
@@ -76,129 +47,82 @@ type editInput struct {
 	Username string     `json:"username,omitempty" validate:"omitempty,min=3,max=30"`
 	Email    string     `json:"email,omitempty" validate:"omitempty,email"`
 	Password string     `json:"password,omitempty"`
-	Role     m.RoleType `json:"role,omitempty" validate:"omitempty,oneof=admin user"`
+	Role     m.RoleType `json:"role,omitempty" validate:"omitempty,oneof=admin user attendant"`
+
+	// Billing replaces the billing details as a whole, nil leaves them unchanged
+	Billing *m.Billing `json:"billing,omitempty"`
 }
 
 func (s *Server) editUser(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Editing user")
+	s.Logger.InfoContext(r.Context(), "Editing user")
 	var input editInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, err.Error(), err, http.StatusBadRequest)
-		return
-	}
-
-	user, err := s.MongoDB.GetFullUser(input.UserID)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "User not found", err, http.StatusNotFound)
-			return
-		}
-		s.handleError(w, "Failed to fetch user from MongoDB", err, http.StatusInternalServerError)
-		return
-	}
-
-	updatedUser, err := updateUserFields(user, input)
-	if err != nil {
-		s.handleError(w, "Failed to process input data", err, http.StatusInternalServerError)
-		return
-	}
-
-	existingUser, err := s.MongoDB.GetUserByUsernameOrEmailForEdit(updatedUser.Username, updatedUser.Email, updatedUser.UserID)
-	if err != nil && err != mongo.ErrNoDocuments {
-		s.handleError(w, "Failed to check for existing user", err, http.StatusInternalServerError)
-		return
-	}
-	if existingUser != nil {
-		s.handleError(w, "Username or email already exists", nil, http.StatusConflict)
-		return
-	}
-
-	err = s.MongoDB.EditUser(updatedUser)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "User not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to edit user in MongoDB", err, http.StatusInternalServerError)
+	if err := s.updateUser(r.Context(), input); err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("User edited: %v", updatedUser.Username)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Deleting user")
+	s.Logger.InfoContext(r.Context(), "Deleting user")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing user ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing user ID", nil)
 		return
 	}
 
-	err := s.MongoDB.DeleteUser(id)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "User not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to delete user", err, http.StatusInternalServerError)
+	if err := s.removeUser(r.Context(), id); err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("User deleted: %v", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting user")
+	s.Logger.InfoContext(r.Context(), "Getting user")
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
-		s.handleError(w, "Missing user ID", nil, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeBadRequest, "Missing user ID", nil)
 		return
 	}
 
-	user, err := s.MongoDB.GetUser(id)
+	user, err := s.findUser(r.Context(), id)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "User not found", err, http.StatusNotFound)
-			return
-		}
-
-		s.handleError(w, "Failed to get user", err, http.StatusInternalServerError)
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("User found: %v", user.Username)
-	s.writeJSON(w, user, http.StatusOK)
+	s.writeJSON(w, r, user, http.StatusOK)
 }
 
 func (s *Server) getAllUsers(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Getting all users")
-	users, err := s.MongoDB.GetAll()
+	s.Logger.InfoContext(r.Context(), "Getting all users")
+	query := r.URL.Query()
+
+	opts, err := parseListOptions(query, userSortFields, "username")
 	if err != nil {
-		s.handleError(w, "Failed to get users", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeBadRequest, err.Error(), err)
 		return
 	}
 
-	if len(users) == 0 {
-		s.Logger.Info.Println("No users found")
-		s.writeJSON(w, []m.User{}, http.StatusOK)
+	page, err := s.listUsers(r.Context(), m.UserFilter{Role: m.RoleType(query.Get("role"))}, opts)
+	if err != nil {
+		s.writeError(w, r, err)
 		return
 	}
 
-	s.Logger.Info.Printf("Users found: %v", len(users))
-	s.writeJSON(w, users, http.StatusOK)
+	s.writeJSON(w, r, page, http.StatusOK)
 }
 
 type loginInput struct {
@@ -211,66 +135,37 @@ type loginResponse struct {
 }
 
 func (s *Server) login(w http.ResponseWriter, r *http.Request) {
-	s.Logger.Info.Println("Logging user")
+	s.Logger.InfoContext(r.Context(), "Logging user")
 	var input loginInput
 
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		s.handleError(w, "Failed to decode request body", err, http.StatusBadRequest)
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
 		return
 	}
 
-	if err := s.Validator.Struct(input); err != nil {
-		s.handleError(w, "Invalid input data", err, http.StatusBadRequest)
-		return
-	}
-
-	user, err := s.MongoDB.GetUserByUsernameOrEmail(input.Username, "")
+	jwt, err := s.authenticate(r.Context(), input)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			s.handleError(w, "Invalid username or password", err, http.StatusUnauthorized)
-			return
-		}
-
-		s.handleError(w, "Unexpected server error", err, http.StatusInternalServerError)
-		return
-	}
-
-	if user == nil {
-		s.handleError(w, "Invalid username or password", err, http.StatusUnauthorized)
+		s.writeError(w, r, err)
 		return
 	}
 
-	match := auth.VerifyPassword(input.Password, user.PasswordHash)
-	if !match {
-		s.handleError(w, "Invalid username or password", err, http.StatusUnauthorized)
-		return
-	}
-
-	jwt, err := auth.GenerateJWT(user.UserID, user.Role, s.Config.Salt)
-	if err != nil {
-		s.handleError(w, "Failed to generate JWT", err, http.StatusInternalServerError)
-		return
-	}
-
-	s.Logger.Info.Printf("User logged in: %v", user.Username)
 	resp := loginResponse{
 		Jwt: jwt,
 	}
-	s.writeJSON(w, resp, http.StatusOK)
+	s.writeJSON(w, r, resp, http.StatusOK)
 }
 
 func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		s.handleError(w, "Missing Authorization header", nil, http.StatusUnauthorized)
+		s.handleError(w, r, problem.CodeInvalidToken, "Missing Authorization header", nil)
 		return
 	}
 
-	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-	claims, err := auth.ValidateJWT(tokenString, s.Config.Salt)
+	claims, err := s.validateToken(strings.TrimPrefix(authHeader, "Bearer "))
 	if err != nil {
-		s.handleError(w, "Invalid or expired token", err, http.StatusUnauthorized)
+		s.writeError(w, r, err)
 		return
 	}
 
@@ -278,24 +173,46 @@ func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
 		"role":    string(claims.Role),
 		"user_id": string(claims.UserID),
 	}
-	s.writeJSON(w, resp, http.StatusOK)
+	s.writeJSON(w, r, resp, http.StatusOK)
 }
 
 // Helper function to handle errors
-func (s *Server) handleError(w http.ResponseWriter, message string, err error, statusCode int) {
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request, code problem.Code, message string, err error) {
+	s.writeProblem(w, r, problem.New(code, message), err)
+}
+
+// Helper function to write an error returned by the shared operations
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var pe *problem.Error
+	if !errors.As(err, &pe) {
+		pe = problem.NewError(problem.CodeInternal, "Unexpected server error", err)
+	}
+	s.writeProblem(w, r, pe.Problem, pe.Err)
+}
+
+// Helper function to log and write problem details
+func (s *Server) writeProblem(w http.ResponseWriter, r *http.Request, p problem.Problem, err error) {
+	level := slog.LevelError
+	if p.Status < http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+
+	args := []any{"status", p.Status, "code", p.Code}
 	if err != nil {
-		s.Logger.Error.Printf("%s: %v", message, err)
-	} else {
-		s.Logger.Error.Println(message)
+		args = append(args, "error", err)
 	}
-	http.Error(w, message, statusCode)
+	s.Logger.Log(r.Context(), level, p.Detail, args...)
+
+	p.Instance = r.URL.Path
+	p.RequestID = logger.RequestID(r.Context())
+	problem.Write(w, p)
 }
 
 // Helper function to write JSON responses
-func (s *Server) writeJSON(w http.ResponseWriter, data interface{}, statusCode int) {
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, data interface{}, statusCode int) {
 	j, err := json.Marshal(data)
 	if err != nil {
-		s.handleError(w, "Failed to encode response to JSON", err, http.StatusInternalServerError)
+		s.handleError(w, r, problem.CodeInternal, "Failed to encode response to JSON", err)
 		return
 	}
 
@@ -314,6 +231,9 @@ func updateUserFields(existingUser m.User, input editInput) (m.User, error) {
 	if input.Role != "" {
 		existingUser.Role = input.Role
 	}
+	if input.Billing != nil {
+		existingUser.Billing = input.Billing
+	}
 
 	if input.Password != "" {
 		hashedPassword, err := auth.HashPassword(input.Password)
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,7 +12,8 @@ import (
 
 	"github.com/ciameksw/reserve-park/user/internal/user/config"
 	"github.com/ciameksw/reserve-park/user/internal/user/logger"
-	"github.com/ciameksw/reserve-park/user/internal/user/mongodb"
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/ciameksw/reserve-park/user/internal/user/store/memory"
 	"github.com/gorilla/mux"
 )
 
@@ -20,6 +22,7 @@ var userID string
 var jwt string
 var newUsername = "NewUsername"
 var newPassword = "NewPassword"
+var vehicleID string
 
 func TestMain(m *testing.M) {
 	// Get logger
@@ -28,14 +31,8 @@ func TestMain(m *testing.M) {
 	// Get config
 	cfg := config.GetConfig()
 
-	// Connect to mock MongoDB
-	db, err := mongodb.ConnectMock()
-	if err != nil {
-		lgr.Error.Fatalf("Failed to connect to mock MongoDB: %v", err)
-	}
-	defer db.Disconnect()
-
-	s = NewServer(lgr, cfg, db)
+	// The store conformance suite covers MongoDB, the handlers run against the in-memory store
+	s = NewServer(lgr, cfg, memory.New())
 
 	os.Exit(m.Run())
 }
@@ -66,7 +63,7 @@ func TestAddUser(t *testing.T) {
 }
 
 func TestGetAllUsers(t *testing.T) {
-	req, err := http.NewRequest("GET", "/users", nil)
+	req, err := http.NewRequest("GET", "/users?sort=updated_at&order=desc&include_total=true", nil)
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
@@ -80,14 +77,18 @@ func TestGetAllUsers(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var users []mongodb.User
-	err = json.NewDecoder(rr.Body).Decode(&users)
+	var page model.Page[model.UserResponse]
+	err = json.NewDecoder(rr.Body).Decode(&page)
 	if err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
 	}
 
-	if len(users) != 1 {
-		t.Errorf("handler returned wrong number of users: got %v want %v", len(users), 1)
+	if len(page.Items) != 1 {
+		t.Errorf("handler returned wrong number of users: got %v want %v", len(page.Items), 1)
+	}
+
+	if page.TotalCount == nil || *page.TotalCount != 1 {
+		t.Errorf("handler returned wrong total count: got %v want %v", page.TotalCount, 1)
 	}
 }
 
@@ -132,6 +133,35 @@ func TestEditUser(t *testing.T) {
 	}
 }
 
+func TestEditBilling(t *testing.T) {
+	tests := []struct {
+		name    string
+		billing model.Billing
+		want    int
+	}{
+		{"business account", model.Billing{Name: "Acme Ltd", TaxID: "PL1234567890", Address: "1 Main St", Business: true}, http.StatusNoContent},
+		{"business account without tax ID", model.Billing{Name: "Acme Ltd", Business: true}, http.StatusBadRequest},
+		{"private buyer", model.Billing{Name: "John Doe"}, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(editInput{UserID: userID, Billing: &tt.billing})
+			req, err := http.NewRequest("PATCH", "/users", bytes.NewBuffer(body))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(s.editUser).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.want)
+			}
+		})
+	}
+}
+
 func TestLogin(t *testing.T) {
 	input := loginInput{
 		Username: newUsername,
@@ -191,6 +221,95 @@ func TestAuthorize(t *testing.T) {
 	}
 }
 
+func TestAddVehicle(t *testing.T) {
+	input := addVehicleInput{
+		LicensePlate: "wx 1234-a",
+		Country:      "PL",
+		Make:         "Skoda",
+		Size:         model.VehicleSizeMedium,
+		IsEV:         true,
+	}
+	body, _ := json.Marshal(input)
+	req, err := http.NewRequest("POST", "/users/"+userID+"/vehicles", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}/vehicles", s.addVehicle).Methods("POST")
+
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+
+	vehicleID = rr.Body.String()
+
+	// The same plate again, written differently
+	input.LicensePlate = "WX1234A"
+	body, _ = json.Marshal(input)
+	req, err = http.NewRequest("POST", "/users/"+userID+"/vehicles", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("handler returned wrong status code for a taken plate: got %v want %v", status, http.StatusConflict)
+	}
+}
+
+func TestGetVehicle(t *testing.T) {
+	req, err := http.NewRequest("GET", "/users/"+userID+"/vehicles/"+vehicleID, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}/vehicles/{vehicleId}", s.getVehicle).Methods("GET")
+
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var vehicle model.Vehicle
+	err = json.NewDecoder(rr.Body).Decode(&vehicle)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if vehicle.LicensePlate != "WX1234A" {
+		t.Errorf("handler returned wrong license plate: got %v want %v", vehicle.LicensePlate, "WX1234A")
+	}
+}
+
+func TestDeleteVehicle(t *testing.T) {
+	req, err := http.NewRequest("DELETE", "/users/"+userID+"/vehicles/"+vehicleID, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}/vehicles/{vehicleId}", s.deleteVehicle).Methods("DELETE")
+
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
+	}
+}
+
 func TestDeleteUser(t *testing.T) {
 	req, err := http.NewRequest("DELETE", "/users/"+userID, nil)
 	if err != nil {
@@ -208,3 +327,75 @@ func TestDeleteUser(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 }
+
+func TestHealthz(t *testing.T) {
+	req, err := http.NewRequest("GET", "/healthz", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.healthz)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	req, err := http.NewRequest("GET", "/readyz", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(s.readyz)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var resp healthResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.Checks["mongodb"] != "ok" {
+		t.Errorf("handler returned wrong mongodb check: got %v want %v", resp.Checks["mongodb"], "ok")
+	}
+}
+
+// racingStore misses the existing user in the lookup, like a concurrent request would,
+// so only the unique index catches the duplicate
+type racingStore struct {
+	*memory.Store
+}
+
+func (racingStore) GetUserByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error) {
+	return nil, nil
+}
+
+func (racingStore) AddUser(ctx context.Context, user model.User, events ...model.Event) error {
+	return model.ErrDuplicate
+}
+
+func TestAddUserDuplicate(t *testing.T) {
+	srv := NewServer(s.Logger, s.Config, racingStore{memory.New()})
+
+	body, _ := json.Marshal(addInput{Username: "racer", Email: "racer@example.com", Password: "testpassword", Role: "user"})
+	req, err := http.NewRequest("POST", "/users", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.addUser).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusConflict)
+	}
+}
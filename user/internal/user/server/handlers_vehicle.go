@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	m "github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/ciameksw/reserve-park/user/internal/user/problem"
+	"github.com/gorilla/mux"
+)
+
+type addVehicleInput struct {
+	LicensePlate string            `json:"license_plate"`
+	Country      string            `json:"country"`
+	Region       string            `json:"region"`
+	Make         string            `json:"make"`
+	Size         m.VehicleSizeType `json:"size"`
+	IsEV         bool              `json:"is_ev"`
+	HeightCm     int               `json:"height_cm"`
+}
+
+func (s *Server) addVehicle(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Adding vehicle")
+	vars := mux.Vars(r)
+	userID, ok := vars["id"]
+	if !ok {
+		s.handleError(w, r, problem.CodeBadRequest, "Missing user ID", nil)
+		return
+	}
+
+	var input addVehicleInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		s.handleError(w, r, problem.CodeMalformedBody, "Failed to decode request body", err)
+		return
+	}
+
+	vehicle, err := s.createVehicle(r.Context(), userID, input)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(vehicle.VehicleID))
+}
+
+func (s *Server) deleteVehicle(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Deleting vehicle")
+	vars := mux.Vars(r)
+	userID, ok := vars["id"]
+	if !ok {
+		s.handleError(w, r, problem.CodeBadRequest, "Missing user ID", nil)
+		return
+	}
+	vehicleID, ok := vars["vehicleId"]
+	if !ok {
+		s.handleError(w, r, problem.CodeBadRequest, "Missing vehicle ID", nil)
+		return
+	}
+
+	if err := s.removeVehicle(r.Context(), userID, vehicleID); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getVehicles(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting vehicles")
+	vars := mux.Vars(r)
+	userID, ok := vars["id"]
+	if !ok {
+		s.handleError(w, r, problem.CodeBadRequest, "Missing user ID", nil)
+		return
+	}
+
+	vehicles, err := s.listVehicles(r.Context(), userID)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.Logger.InfoContext(r.Context(), "Vehicles found", "count", len(vehicles))
+	s.writeJSON(w, r, vehicles, http.StatusOK)
+}
+
+func (s *Server) getVehicle(w http.ResponseWriter, r *http.Request) {
+	s.Logger.InfoContext(r.Context(), "Getting vehicle")
+	vars := mux.Vars(r)
+	userID, ok := vars["id"]
+	if !ok {
+		s.handleError(w, r, problem.CodeBadRequest, "Missing user ID", nil)
+		return
+	}
+	vehicleID, ok := vars["vehicleId"]
+	if !ok {
+		s.handleError(w, r, problem.CodeBadRequest, "Missing vehicle ID", nil)
+		return
+	}
+
+	vehicle, err := s.findVehicle(r.Context(), userID, vehicleID)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, r, vehicle, http.StatusOK)
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/auth"
+	"github.com/ciameksw/reserve-park/user/internal/user/metrics"
+	m "github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/ciameksw/reserve-park/user/internal/user/problem"
+	"github.com/google/uuid"
+)
+
+// The operations below are shared by the HTTP handlers and the gRPC API.
+// Anything the caller should see is returned as a *problem.Error.
+
+func (s *Server) createUser(ctx context.Context, input addInput) (m.User, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return m.User{}, problem.Invalid(err)
+	}
+
+	existingUser, err := s.Store.GetUserByUsernameOrEmail(ctx, input.Username, input.Email)
+	if err != nil && err != m.ErrNotFound {
+		return m.User{}, problem.NewError(problem.CodeInternal, "Failed to check for existing user", err)
+	}
+	if existingUser != nil {
+		return m.User{}, problem.NewError(problem.CodeUserAlreadyExists, "Username or email already exists", nil)
+	}
+
+	hashedPassword, err := auth.HashPassword(input.Password)
+	if err != nil {
+		return m.User{}, problem.NewError(problem.CodeInternal, "Failed to hash the password", err)
+	}
+
+	data := m.User{
+		UserID:       uuid.NewString(),
+		Username:     input.Username,
+		Email:        input.Email,
+		PasswordHash: hashedPassword,
+		Role:         input.Role,
+		UpdatedAt:    time.Now(),
+	}
+
+	event, err := m.NewEvent(m.EventUserCreated, data.UserID, data.Response())
+	if err != nil {
+		return data, problem.NewError(problem.CodeInternal, "Failed to build the user event", err)
+	}
+
+	if err := s.Store.AddUser(ctx, data, event); err != nil {
+		// Another request took the username or email since the check above
+		if err == m.ErrDuplicate {
+			return data, problem.NewError(problem.CodeUserAlreadyExists, "Username or email already exists", err)
+		}
+		return data, problem.NewError(problem.CodeInternal, "Failed to add user", err)
+	}
+
+	s.Logger.InfoContext(ctx, "User added", "username", data.Username)
+	return data, nil
+}
+
+func (s *Server) updateUser(ctx context.Context, input editInput) error {
+	if err := s.Validator.Struct(input); err != nil {
+		return problem.Invalid(err)
+	}
+
+	user, err := s.Store.GetFullUser(ctx, input.UserID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeUserNotFound, "User not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to fetch user", err)
+	}
+
+	updatedUser, err := updateUserFields(user, input)
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to process input data", err)
+	}
+
+	existingUser, err := s.Store.GetUserByUsernameOrEmailForEdit(ctx, updatedUser.Username, updatedUser.Email, updatedUser.UserID)
+	if err != nil && err != m.ErrNotFound {
+		return problem.NewError(problem.CodeInternal, "Failed to check for existing user", err)
+	}
+	if existingUser != nil {
+		return problem.NewError(problem.CodeUserAlreadyExists, "Username or email already exists", nil)
+	}
+
+	event, err := m.NewEvent(m.EventUserUpdated, updatedUser.UserID, updatedUser.Response())
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to build the user event", err)
+	}
+
+	err = s.Store.EditUser(ctx, updatedUser, event)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeUserNotFound, "User not found", err)
+		}
+		if err == m.ErrDuplicate {
+			return problem.NewError(problem.CodeUserAlreadyExists, "Username or email already exists", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to edit user", err)
+	}
+
+	s.Logger.InfoContext(ctx, "User edited", "username", updatedUser.Username)
+	return nil
+}
+
+func (s *Server) removeUser(ctx context.Context, userID string) error {
+	event, err := m.NewEvent(m.EventUserDeleted, userID, map[string]string{"user_id": userID})
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to build the user event", err)
+	}
+
+	err = s.Store.DeleteUser(ctx, userID, event)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeUserNotFound, "User not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to delete user", err)
+	}
+
+	s.Logger.InfoContext(ctx, "User deleted", "user_id", userID)
+	return nil
+}
+
+func (s *Server) findUser(ctx context.Context, userID string) (m.UserResponse, error) {
+	user, err := s.Store.GetUser(ctx, userID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return user, problem.NewError(problem.CodeUserNotFound, "User not found", err)
+		}
+
+		return user, problem.NewError(problem.CodeInternal, "Failed to get user", err)
+	}
+
+	s.Logger.InfoContext(ctx, "User found", "username", user.Username)
+	return user, nil
+}
+
+func (s *Server) listUsers(ctx context.Context, filter m.UserFilter, opts m.ListOptions) (m.Page[m.UserResponse], error) {
+	switch filter.Role {
+	case "", m.RoleAdmin, m.RoleUser, m.RoleAttendant:
+	default:
+		return m.Page[m.UserResponse]{}, problem.NewError(problem.CodeBadRequest, "unsupported role: "+string(filter.Role), nil)
+	}
+
+	page, err := s.Store.GetAll(ctx, filter, opts)
+	if err != nil {
+		if err == m.ErrInvalidCursor {
+			return page, problem.NewError(problem.CodeInvalidCursor, "Invalid cursor", err)
+		}
+
+		return page, problem.NewError(problem.CodeInternal, "Failed to get users", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Users found", "count", len(page.Items))
+	return page, nil
+}
+
+// authenticate checks the credentials and returns a fresh JWT
+func (s *Server) authenticate(ctx context.Context, input loginInput) (string, error) {
+	if err := s.Validator.Struct(input); err != nil {
+		return "", problem.Invalid(err)
+	}
+
+	user, err := s.Store.GetUserByUsernameOrEmail(ctx, input.Username, "")
+	if err != nil && err != m.ErrNotFound {
+		return "", problem.NewError(problem.CodeInternal, "Unexpected server error", err)
+	}
+
+	if user == nil || !auth.VerifyPassword(input.Password, user.PasswordHash) {
+		metrics.LoginFailures.Inc()
+		return "", problem.NewError(problem.CodeInvalidCredentials, "Invalid username or password", nil)
+	}
+
+	jwt, err := auth.GenerateJWT(user.UserID, user.Role, s.Config.Salt)
+	if err != nil {
+		return "", problem.NewError(problem.CodeInternal, "Failed to generate JWT", err)
+	}
+
+	s.Logger.InfoContext(ctx, "User logged in", "username", user.Username)
+	return jwt, nil
+}
+
+// validateToken returns the claims of a valid, unexpired JWT
+func (s *Server) validateToken(token string) (*auth.UserClaims, error) {
+	if token == "" {
+		return nil, problem.NewError(problem.CodeInvalidToken, "Missing token", nil)
+	}
+
+	claims, err := auth.ValidateJWT(token, s.Config.Salt)
+	if err != nil {
+		return nil, problem.NewError(problem.CodeInvalidToken, "Invalid or expired token", err)
+	}
+
+	return claims, nil
+}
+
+func (s *Server) createVehicle(ctx context.Context, userID string, input addVehicleInput) (m.Vehicle, error) {
+	data := m.Vehicle{
+		VehicleID:    uuid.NewString(),
+		LicensePlate: m.NormalizePlate(input.LicensePlate),
+		Country:      input.Country,
+		Region:       input.Region,
+		Make:         input.Make,
+		Size:         input.Size,
+		IsEV:         input.IsEV,
+		HeightCm:     input.HeightCm,
+	}
+
+	if err := s.Validator.Struct(data); err != nil {
+		return data, problem.Invalid(err)
+	}
+
+	event, err := m.NewEvent(m.EventVehicleAdded, userID, m.VehicleChange{UserID: userID, VehicleID: data.VehicleID, Vehicle: &data})
+	if err != nil {
+		return data, problem.NewError(problem.CodeInternal, "Failed to build the vehicle event", err)
+	}
+
+	err = s.Store.AddVehicle(ctx, userID, data, event)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return data, problem.NewError(problem.CodeUserNotFound, "User not found", err)
+		}
+		if err == m.ErrDuplicate {
+			return data, problem.NewError(problem.CodeVehicleAlreadyExists, "Vehicle with this license plate already exists", err)
+		}
+
+		return data, problem.NewError(problem.CodeInternal, "Failed to add vehicle", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Vehicle added", "vehicle_id", data.VehicleID)
+	return data, nil
+}
+
+func (s *Server) removeVehicle(ctx context.Context, userID, vehicleID string) error {
+	event, err := m.NewEvent(m.EventVehicleDeleted, userID, m.VehicleChange{UserID: userID, VehicleID: vehicleID})
+	if err != nil {
+		return problem.NewError(problem.CodeInternal, "Failed to build the vehicle event", err)
+	}
+
+	err = s.Store.DeleteVehicle(ctx, userID, vehicleID, event)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return problem.NewError(problem.CodeVehicleNotFound, "Vehicle not found", err)
+		}
+
+		return problem.NewError(problem.CodeInternal, "Failed to delete vehicle", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Vehicle deleted", "vehicle_id", vehicleID)
+	return nil
+}
+
+func (s *Server) listVehicles(ctx context.Context, userID string) ([]m.Vehicle, error) {
+	vehicles, err := s.Store.GetVehicles(ctx, userID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return nil, problem.NewError(problem.CodeUserNotFound, "User not found", err)
+		}
+
+		return nil, problem.NewError(problem.CodeInternal, "Failed to get vehicles", err)
+	}
+
+	if len(vehicles) == 0 {
+		return []m.Vehicle{}, nil
+	}
+
+	return vehicles, nil
+}
+
+func (s *Server) findVehicle(ctx context.Context, userID, vehicleID string) (m.Vehicle, error) {
+	vehicle, err := s.Store.GetVehicle(ctx, userID, vehicleID)
+	if err != nil {
+		if err == m.ErrNotFound {
+			return vehicle, problem.NewError(problem.CodeVehicleNotFound, "Vehicle not found", err)
+		}
+
+		return vehicle, problem.NewError(problem.CodeInternal, "Failed to get vehicle", err)
+	}
+
+	s.Logger.InfoContext(ctx, "Vehicle found", "vehicle_id", vehicle.VehicleID)
+	return vehicle, nil
+}
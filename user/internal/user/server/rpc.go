@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+
+	m "github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/ciameksw/reserve-park/user/internal/user/pb/userpb"
+	"github.com/ciameksw/reserve-park/user/internal/user/problem"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// userRPC serves the internal gRPC API on top of the same operations as the HTTP handlers
+type userRPC struct {
+	userpb.UnimplementedUserServiceServer
+	s *Server
+}
+
+func (rpc *userRPC) AddUser(ctx context.Context, req *userpb.AddUserRequest) (*userpb.AddUserResponse, error) {
+	user, err := rpc.s.createUser(ctx, addInput{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Role:     m.RoleType(req.GetRole()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &userpb.AddUserResponse{UserId: user.UserID}, nil
+}
+
+func (rpc *userRPC) EditUser(ctx context.Context, req *userpb.EditUserRequest) (*emptypb.Empty, error) {
+	err := rpc.s.updateUser(ctx, editInput{
+		UserID:   req.GetUserId(),
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Role:     m.RoleType(req.GetRole()),
+		Billing:  billingFromProto(req.GetBilling()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *userRPC) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := rpc.s.removeUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *userRPC) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.User, error) {
+	user, err := rpc.s.findUser(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	return userToProto(user), nil
+}
+
+func (rpc *userRPC) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	o := req.GetOptions()
+	opts, err := newListOptions(o.GetLimit(), o.GetCursor(), o.GetSort(), o.GetOrder(), o.GetIncludeTotal(), userSortFields, "username")
+	if err != nil {
+		return nil, problem.NewError(problem.CodeBadRequest, err.Error(), err)
+	}
+
+	page, err := rpc.s.listUsers(ctx, m.UserFilter{Role: m.RoleType(req.GetRole())}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userpb.ListUsersResponse{
+		NextCursor: page.NextCursor,
+		TotalCount: page.TotalCount,
+	}
+	for _, user := range page.Items {
+		resp.Items = append(resp.Items, userToProto(user))
+	}
+
+	return resp, nil
+}
+
+func (rpc *userRPC) Login(ctx context.Context, req *userpb.LoginRequest) (*userpb.LoginResponse, error) {
+	jwt, err := rpc.s.authenticate(ctx, loginInput{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &userpb.LoginResponse{Jwt: jwt}, nil
+}
+
+func (rpc *userRPC) Authorize(ctx context.Context, req *userpb.AuthorizeRequest) (*userpb.AuthorizeResponse, error) {
+	claims, err := rpc.s.validateToken(req.GetToken())
+	if err != nil {
+		return nil, err
+	}
+
+	return &userpb.AuthorizeResponse{UserId: claims.UserID, Role: string(claims.Role)}, nil
+}
+
+func (rpc *userRPC) AddVehicle(ctx context.Context, req *userpb.AddVehicleRequest) (*userpb.AddVehicleResponse, error) {
+	vehicle, err := rpc.s.createVehicle(ctx, req.GetUserId(), addVehicleInput{
+		LicensePlate: req.GetLicensePlate(),
+		Country:      req.GetCountry(),
+		Region:       req.GetRegion(),
+		Make:         req.GetMake(),
+		Size:         m.VehicleSizeType(req.GetSize()),
+		IsEV:         req.GetIsEv(),
+		HeightCm:     int(req.GetHeightCm()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &userpb.AddVehicleResponse{VehicleId: vehicle.VehicleID}, nil
+}
+
+func (rpc *userRPC) DeleteVehicle(ctx context.Context, req *userpb.DeleteVehicleRequest) (*emptypb.Empty, error) {
+	if err := rpc.s.removeVehicle(ctx, req.GetUserId(), req.GetVehicleId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (rpc *userRPC) GetVehicle(ctx context.Context, req *userpb.GetVehicleRequest) (*userpb.Vehicle, error) {
+	vehicle, err := rpc.s.findVehicle(ctx, req.GetUserId(), req.GetVehicleId())
+	if err != nil {
+		return nil, err
+	}
+
+	return vehicleToProto(vehicle), nil
+}
+
+func (rpc *userRPC) ListVehicles(ctx context.Context, req *userpb.ListVehiclesRequest) (*userpb.ListVehiclesResponse, error) {
+	vehicles, err := rpc.s.listVehicles(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userpb.ListVehiclesResponse{}
+	for _, vehicle := range vehicles {
+		resp.Vehicles = append(resp.Vehicles, vehicleToProto(vehicle))
+	}
+
+	return resp, nil
+}
+
+func userToProto(user m.UserResponse) *userpb.User {
+	resp := &userpb.User{
+		Id:        user.ID.Hex(),
+		UserId:    user.UserID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      string(user.Role),
+		Billing:   billingToProto(user.Billing),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+	for _, vehicle := range user.Vehicles {
+		resp.Vehicles = append(resp.Vehicles, vehicleToProto(vehicle))
+	}
+
+	return resp
+}
+
+func billingToProto(billing *m.Billing) *userpb.Billing {
+	if billing == nil {
+		return nil
+	}
+
+	return &userpb.Billing{
+		Name:     billing.Name,
+		TaxId:    billing.TaxID,
+		Address:  billing.Address,
+		Business: billing.Business,
+	}
+}
+
+func billingFromProto(billing *userpb.Billing) *m.Billing {
+	if billing == nil {
+		return nil
+	}
+
+	return &m.Billing{
+		Name:     billing.GetName(),
+		TaxID:    billing.GetTaxId(),
+		Address:  billing.GetAddress(),
+		Business: billing.GetBusiness(),
+	}
+}
+
+func vehicleToProto(vehicle m.Vehicle) *userpb.Vehicle {
+	return &userpb.Vehicle{
+		VehicleId:    vehicle.VehicleID,
+		LicensePlate: vehicle.LicensePlate,
+		Country:      vehicle.Country,
+		Region:       vehicle.Region,
+		Make:         vehicle.Make,
+		Size:         string(vehicle.Size),
+		IsEv:         vehicle.IsEV,
+		HeightCm:     int32(vehicle.HeightCm),
+	}
+}
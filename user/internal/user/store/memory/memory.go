@@ -0,0 +1,208 @@
+// Package memory is an in-memory implementation of store.UserStore.
+// It is meant for tests and local experiments, data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/ciameksw/reserve-park/user/internal/user/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ store.UserStore = (*Store)(nil)
+
+type Store struct {
+	mu     sync.RWMutex
+	users  []model.User
+	outbox outbox
+}
+
+func New() *Store {
+	return &Store{}
+}
+
+func (s *Store) AddUser(ctx context.Context, user model.User, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	s.users = append(s.users, copyUser(user))
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) GetUserByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error) {
+	return s.getUserByUsernameOrEmail(username, email, "")
+}
+
+func (s *Store) GetUserByUsernameOrEmailForEdit(ctx context.Context, username, email, editUserID string) (*model.User, error) {
+	return s.getUserByUsernameOrEmail(username, email, editUserID)
+}
+
+func (s *Store) getUserByUsernameOrEmail(username, email, editUserID string) (*model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.UserID == editUserID && editUserID != "" {
+			continue
+		}
+		if u.Username == username || u.Email == email {
+			user := copyUser(u)
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Store) EditUser(ctx context.Context, input model.User, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(input.UserID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	// Like a document replace, the stored ID survives the edit
+	if input.ID.IsZero() {
+		input.ID = s.users[i].ID
+	}
+	s.users[i] = copyUser(input)
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) DeleteUser(ctx context.Context, userID string, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(userID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	s.users = append(s.users[:i], s.users[i+1:]...)
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) GetFullUser(ctx context.Context, userID string) (model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.index(userID)
+	if i < 0 {
+		return model.User{}, model.ErrNotFound
+	}
+	return copyUser(s.users[i]), nil
+}
+
+func (s *Store) GetUser(ctx context.Context, userID string) (model.UserResponse, error) {
+	user, err := s.GetFullUser(ctx, userID)
+	if err != nil {
+		return model.UserResponse{}, err
+	}
+	return user.Response(), nil
+}
+
+func (s *Store) GetAll(ctx context.Context, input model.UserFilter, opts model.ListOptions) (model.Page[model.UserResponse], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matching []model.UserResponse
+	for _, u := range s.users {
+		if input.Role != "" && u.Role != input.Role {
+			continue
+		}
+		matching = append(matching, copyUser(u).Response())
+	}
+
+	return paginate(matching, func(u model.UserResponse) primitive.ObjectID { return u.ID }, opts)
+}
+
+func (s *Store) AddVehicle(ctx context.Context, userID string, vehicle model.Vehicle, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(userID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+	for _, v := range s.users[i].Vehicles {
+		if v.LicensePlate == vehicle.LicensePlate && v.Country == vehicle.Country {
+			return model.ErrDuplicate
+		}
+	}
+
+	s.users[i].Vehicles = append(s.users[i].Vehicles, vehicle)
+	s.outbox.add(events)
+	return nil
+}
+
+func (s *Store) DeleteVehicle(ctx context.Context, userID, vehicleID string, events ...model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(userID)
+	if i < 0 {
+		return model.ErrNotFound
+	}
+
+	vehicles := s.users[i].Vehicles
+	for j, vehicle := range vehicles {
+		if vehicle.VehicleID == vehicleID {
+			s.users[i].Vehicles = append(vehicles[:j:j], vehicles[j+1:]...)
+			s.outbox.add(events)
+			return nil
+		}
+	}
+	return model.ErrNotFound
+}
+
+func (s *Store) GetVehicles(ctx context.Context, userID string) ([]model.Vehicle, error) {
+	user, err := s.GetFullUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.Vehicles, nil
+}
+
+func (s *Store) GetVehicle(ctx context.Context, userID, vehicleID string) (model.Vehicle, error) {
+	vehicles, err := s.GetVehicles(ctx, userID)
+	if err != nil {
+		return model.Vehicle{}, err
+	}
+
+	for _, vehicle := range vehicles {
+		if vehicle.VehicleID == vehicleID {
+			return vehicle, nil
+		}
+	}
+	return model.Vehicle{}, model.ErrNotFound
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Store) index(userID string) int {
+	for i, u := range s.users {
+		if u.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyUser keeps callers from sharing the vehicles slice with the store
+func copyUser(user model.User) model.User {
+	if user.Vehicles != nil {
+		user.Vehicles = append([]model.Vehicle(nil), user.Vehicles...)
+	}
+	return user
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/store"
+	"github.com/ciameksw/reserve-park/user/internal/user/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.UserStore {
+		return New()
+	})
+}
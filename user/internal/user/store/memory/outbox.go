@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+)
+
+// outbox keeps the events in the order they were written, it's guarded by Store.mu
+type outbox struct {
+	events    []model.Event
+	published map[string]struct{}
+}
+
+func (o *outbox) add(events []model.Event) {
+	o.events = append(o.events, events...)
+}
+
+func (s *Store) PendingEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var pending []model.Event
+	for _, event := range s.outbox.events {
+		if len(pending) == limit {
+			break
+		}
+		if _, ok := s.outbox.published[event.ID]; !ok {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+func (s *Store) MarkPublished(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.outbox.published == nil {
+		s.outbox.published = make(map[string]struct{})
+	}
+	for _, id := range ids {
+		s.outbox.published[id] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Package store defines the persistence contract of the user service.
+// The server depends only on this interface, so the backing database can be
+// swapped (MongoDB or PostgreSQL in production, an in-memory store in tests).
+package store
+
+import (
+	"context"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+)
+
+// UserStore returns model.ErrNotFound when the requested user or vehicle
+// doesn't exist and model.ErrInvalidCursor when a page cursor can't be decoded.
+//
+// The write methods take the events describing the change and add them to
+// the outbox atomically with it, see Outbox.
+type UserStore interface {
+	Outbox
+
+	// AddUser and EditUser return model.ErrDuplicate when the username or email is taken
+	// by another user, for databases enforcing it with a unique index
+	AddUser(ctx context.Context, user model.User, events ...model.Event) error
+	EditUser(ctx context.Context, user model.User, events ...model.Event) error
+	DeleteUser(ctx context.Context, userID string, events ...model.Event) error
+
+	// GetFullUser returns the user including the password hash
+	GetFullUser(ctx context.Context, userID string) (model.User, error)
+	GetUser(ctx context.Context, userID string) (model.UserResponse, error)
+	GetAll(ctx context.Context, filter model.UserFilter, opts model.ListOptions) (model.Page[model.UserResponse], error)
+
+	// GetUserByUsernameOrEmail returns nil without an error when no user has the username or the email
+	GetUserByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error)
+
+	// GetUserByUsernameOrEmailForEdit works like GetUserByUsernameOrEmail, ignoring the edited user
+	GetUserByUsernameOrEmailForEdit(ctx context.Context, username, email, editUserID string) (*model.User, error)
+
+	// AddVehicle returns model.ErrDuplicate when the user has a vehicle with the same plate in the same country
+	AddVehicle(ctx context.Context, userID string, vehicle model.Vehicle, events ...model.Event) error
+	DeleteVehicle(ctx context.Context, userID, vehicleID string, events ...model.Event) error
+	GetVehicles(ctx context.Context, userID string) ([]model.Vehicle, error)
+	GetVehicle(ctx context.Context, userID, vehicleID string) (model.Vehicle, error)
+
+	Ping(ctx context.Context) error
+}
+
+// Outbox holds the events written with the changes until the relay publishes them.
+// MongoDB deployments without transactions (standalone servers) write the events
+// right after the change instead, so a crash in between can lose them.
+type Outbox interface {
+	// PendingEvents returns up to limit unpublished events, oldest first
+	PendingEvents(ctx context.Context, limit int) ([]model.Event, error)
+
+	// MarkPublished flags the events so they aren't returned as pending again
+	MarkPublished(ctx context.Context, ids []string) error
+}
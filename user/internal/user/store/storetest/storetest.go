@@ -0,0 +1,383 @@
+// Package storetest is a conformance suite that every store.UserStore
+// implementation must pass, so that they behave the same behind the server.
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ciameksw/reserve-park/user/internal/user/model"
+	"github.com/ciameksw/reserve-park/user/internal/user/store"
+)
+
+// Run runs the suite, newStore must return an empty store for every call
+func Run(t *testing.T, newStore func(t *testing.T) store.UserStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, st store.UserStore)
+	}{
+		{"AddAndGet", testAddAndGet},
+		{"NotFound", testNotFound},
+		{"UsernameOrEmail", testUsernameOrEmail},
+		{"Edit", testEdit},
+		{"Delete", testDelete},
+		{"GetAll", testGetAll},
+		{"InvalidCursor", testInvalidCursor},
+		{"Vehicles", testVehicles},
+		{"Outbox", testOutbox},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+var updatedAt = time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+
+func user(id, username string, role model.RoleType) model.User {
+	return model.User{
+		UserID:       id,
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "hash",
+		Role:         role,
+		UpdatedAt:    updatedAt,
+	}
+}
+
+func vehicle(id, plate string) model.Vehicle {
+	return model.Vehicle{
+		VehicleID:    id,
+		LicensePlate: plate,
+		Country:      "PL",
+		Size:         model.VehicleSizeMedium,
+	}
+}
+
+func mustAdd(t *testing.T, st store.UserStore, users ...model.User) {
+	t.Helper()
+	for _, u := range users {
+		if err := st.AddUser(context.Background(), u); err != nil {
+			t.Fatalf("AddUser(%v): %v", u.UserID, err)
+		}
+	}
+}
+
+func ids(users []model.UserResponse) []string {
+	resp := make([]string, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, u.UserID)
+	}
+	return resp
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testAddAndGet(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+	mustAdd(t, st, user("u1", "alice", model.RoleUser))
+
+	full, err := st.GetFullUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetFullUser: %v", err)
+	}
+	if full.ID.IsZero() || full.Username != "alice" || full.PasswordHash != "hash" {
+		t.Errorf("wrong user: got %+v", full)
+	}
+
+	resp, err := st.GetUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if resp.ID != full.ID || resp.Username != "alice" || resp.Role != model.RoleUser {
+		t.Errorf("wrong user response: got %+v", resp)
+	}
+}
+
+func testNotFound(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+
+	if _, err := st.GetFullUser(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetFullUser: got %v want %v", err, model.ErrNotFound)
+	}
+	if _, err := st.GetUser(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetUser: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.EditUser(ctx, user("missing", "bob", model.RoleUser)); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("EditUser: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.DeleteUser(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("DeleteUser: got %v want %v", err, model.ErrNotFound)
+	}
+	if err := st.AddVehicle(ctx, "missing", vehicle("v1", "WX1234A")); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("AddVehicle: got %v want %v", err, model.ErrNotFound)
+	}
+	if _, err := st.GetVehicles(ctx, "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("GetVehicles: got %v want %v", err, model.ErrNotFound)
+	}
+}
+
+func testUsernameOrEmail(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+	mustAdd(t, st, user("u1", "alice", model.RoleUser))
+
+	got, err := st.GetUserByUsernameOrEmail(ctx, "alice", "other@example.com")
+	if err != nil || got == nil || got.UserID != "u1" {
+		t.Errorf("by username: got %v, %v", got, err)
+	}
+
+	got, err = st.GetUserByUsernameOrEmail(ctx, "other", "alice@example.com")
+	if err != nil || got == nil || got.UserID != "u1" {
+		t.Errorf("by email: got %v, %v", got, err)
+	}
+
+	got, err = st.GetUserByUsernameOrEmail(ctx, "other", "other@example.com")
+	if err != nil || got != nil {
+		t.Errorf("no match: got %v, %v want nil, nil", got, err)
+	}
+
+	// The edited user may keep its own username
+	got, err = st.GetUserByUsernameOrEmailForEdit(ctx, "alice", "alice@example.com", "u1")
+	if err != nil || got != nil {
+		t.Errorf("edit: got %v, %v want nil, nil", got, err)
+	}
+}
+
+func testEdit(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+	mustAdd(t, st, user("u1", "alice", model.RoleUser))
+
+	before, err := st.GetFullUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetFullUser: %v", err)
+	}
+
+	edited := before
+	edited.Username = "alicia"
+	edited.Role = model.RoleAdmin
+	edited.Billing = &model.Billing{Name: "Acme Ltd", TaxID: "PL1234567890", Business: true}
+	if err := st.EditUser(ctx, edited); err != nil {
+		t.Fatalf("EditUser: %v", err)
+	}
+
+	got, err := st.GetFullUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetFullUser: %v", err)
+	}
+	if got.ID != before.ID || got.Username != "alicia" || got.Role != model.RoleAdmin {
+		t.Errorf("wrong user after edit: got %+v", got)
+	}
+
+	resp, err := st.GetUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if resp.Billing == nil || *resp.Billing != *edited.Billing {
+		t.Errorf("wrong billing after edit: got %+v want %+v", resp.Billing, edited.Billing)
+	}
+}
+
+func testDelete(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+	mustAdd(t, st, user("u1", "alice", model.RoleUser), user("u2", "bob", model.RoleUser))
+
+	if err := st.DeleteUser(ctx, "u1"); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := st.GetUser(ctx, "u1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("deleted user still found: %v", err)
+	}
+	if _, err := st.GetUser(ctx, "u2"); err != nil {
+		t.Errorf("other user is gone: %v", err)
+	}
+}
+
+func testGetAll(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+	mustAdd(t, st,
+		user("u1", "carol", model.RoleUser),
+		user("u2", "alice", model.RoleAdmin),
+		user("u3", "dave", model.RoleUser),
+		user("u4", "bob", model.RoleAttendant),
+	)
+
+	page, err := st.GetAll(ctx, model.UserFilter{Role: model.RoleUser}, model.ListOptions{SortBy: "username", IncludeTotal: true})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if got, want := ids(page.Items), []string{"u1", "u3"}; !equal(got, want) {
+		t.Errorf("filter: got %v want %v", got, want)
+	}
+	if page.TotalCount == nil || *page.TotalCount != 2 {
+		t.Errorf("wrong total count: got %v want %v", page.TotalCount, 2)
+	}
+
+	var got []string
+	opts := model.ListOptions{Limit: 3, SortBy: "username", Descending: true}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("pagination doesn't end")
+		}
+
+		page, err := st.GetAll(ctx, model.UserFilter{}, opts)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		got = append(got, ids(page.Items)...)
+
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if want := []string{"u3", "u1", "u4", "u2"}; !equal(got, want) {
+		t.Errorf("pagination: got %v want %v", got, want)
+	}
+}
+
+func testInvalidCursor(t *testing.T, st store.UserStore) {
+	_, err := st.GetAll(context.Background(), model.UserFilter{}, model.ListOptions{SortBy: "username", Cursor: "not-a-cursor"})
+	if !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("got %v want %v", err, model.ErrInvalidCursor)
+	}
+}
+
+func testVehicles(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+	mustAdd(t, st, user("u1", "alice", model.RoleUser))
+
+	for _, v := range []model.Vehicle{vehicle("v1", "WX1234A"), vehicle("v2", "KR55555")} {
+		if err := st.AddVehicle(ctx, "u1", v); err != nil {
+			t.Fatalf("AddVehicle(%v): %v", v.VehicleID, err)
+		}
+	}
+
+	// A plate is added once per country
+	if err := st.AddVehicle(ctx, "u1", vehicle("v3", "WX1234A")); !errors.Is(err, model.ErrDuplicate) {
+		t.Errorf("AddVehicle(taken plate): got %v want %v", err, model.ErrDuplicate)
+	}
+
+	vehicles, err := st.GetVehicles(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetVehicles: %v", err)
+	}
+	if len(vehicles) != 2 {
+		t.Fatalf("wrong number of vehicles: got %v want %v", len(vehicles), 2)
+	}
+
+	got, err := st.GetVehicle(ctx, "u1", "v2")
+	if err != nil {
+		t.Fatalf("GetVehicle: %v", err)
+	}
+	if got.LicensePlate != "KR55555" || got.Size != model.VehicleSizeMedium {
+		t.Errorf("wrong vehicle: got %+v", got)
+	}
+
+	if err := st.DeleteVehicle(ctx, "u1", "v1"); err != nil {
+		t.Fatalf("DeleteVehicle: %v", err)
+	}
+	if _, err := st.GetVehicle(ctx, "u1", "v1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("deleted vehicle still found: %v", err)
+	}
+	if err := st.DeleteVehicle(ctx, "u1", "v1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("deleting twice: got %v want %v", err, model.ErrNotFound)
+	}
+	if _, err := st.GetVehicle(ctx, "u1", "v2"); err != nil {
+		t.Errorf("other vehicle is gone: %v", err)
+	}
+	if err := st.AddVehicle(ctx, "u1", vehicle("v3", "WX1234A")); err != nil {
+		t.Errorf("AddVehicle(plate of the deleted vehicle): %v", err)
+	}
+}
+
+func event(t *testing.T, eventType, userID string, payload any, occurredAt time.Time) model.Event {
+	t.Helper()
+	e, err := model.NewEvent(eventType, userID, payload)
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	e.OccurredAt = occurredAt
+	return e
+}
+
+func eventIDs(events []model.Event) []string {
+	resp := make([]string, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, e.ID)
+	}
+	return resp
+}
+
+func testOutbox(t *testing.T, st store.UserStore) {
+	ctx := context.Background()
+
+	u := user("u1", "alice", model.RoleUser)
+	created := event(t, model.EventUserCreated, "u1", u.Response(), updatedAt)
+	if err := st.AddUser(ctx, u, created); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+
+	v := vehicle("v1", "WX1234A")
+	added := event(t, model.EventVehicleAdded, "u1", model.VehicleChange{UserID: "u1", VehicleID: "v1", Vehicle: &v}, updatedAt.Add(time.Minute))
+	if err := st.AddVehicle(ctx, "u1", v, added); err != nil {
+		t.Fatalf("AddVehicle: %v", err)
+	}
+
+	// A failed write must not leave its event behind
+	deleted := event(t, model.EventVehicleDeleted, "u1", model.VehicleChange{UserID: "u1", VehicleID: "missing"}, updatedAt.Add(2*time.Minute))
+	if err := st.DeleteVehicle(ctx, "u1", "missing", deleted); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("DeleteVehicle(missing) = %v, want ErrNotFound", err)
+	}
+
+	pending, err := st.PendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{created.ID, added.ID}; !equal(eventIDs(pending), want) {
+		t.Fatalf("PendingEvents = %v, want %v", eventIDs(pending), want)
+	}
+
+	got := pending[1]
+	if got.Type != model.EventVehicleAdded || got.Source != model.EventSource || got.AggregateID != "u1" || !got.OccurredAt.Equal(updatedAt.Add(time.Minute)) {
+		t.Errorf("PendingEvents[1] = %+v, want the vehicle event", got)
+	}
+	var payload model.VehicleChange
+	if err := json.Unmarshal(got.Payload, &payload); err != nil || payload.Vehicle == nil || payload.Vehicle.LicensePlate != "WX1234A" {
+		t.Errorf("PendingEvents[1] payload = %s (%v), want the added vehicle", got.Payload, err)
+	}
+
+	limited, err := st.PendingEvents(ctx, 1)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{created.ID}; !equal(eventIDs(limited), want) {
+		t.Errorf("PendingEvents(limit 1) = %v, want %v", eventIDs(limited), want)
+	}
+
+	if err := st.MarkPublished(ctx, []string{created.ID}); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+
+	pending, err = st.PendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if want := []string{added.ID}; !equal(eventIDs(pending), want) {
+		t.Errorf("PendingEvents after MarkPublished = %v, want %v", eventIDs(pending), want)
+	}
+}